@@ -0,0 +1,64 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors
+
+import (
+	"sync"
+)
+
+// Collector collects errors, possibly from multiple goroutines, in order to group them
+// into a single error. The zero value is ready to use. A Collector must not be copied
+// after first use.
+//
+// It is the concurrency-safe counterpart of Append.
+type Collector struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+// Add appends err to the collected errors. A nil err is ignored.
+// A stack trace is recorded, if enabled and err does not already contain one,
+// at the point Add is called.
+// It is safe for concurrent use.
+func (c *Collector) Add(err error) {
+	if err == nil {
+		return
+	}
+
+	if !hasStackTrace(err) {
+		err = &withStack{
+			error: err,
+			stack: callers(),
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errs = append(c.errs, err)
+}
+
+// Err returns an error grouping all the errors collected so far, in the order they were added,
+// formatted the same way as errors returned by Append. It returns nil if no error has been collected.
+// It is safe for concurrent use.
+func (c *Collector) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.errs) == 0 {
+		return nil
+	}
+
+	errs := make([]error, len(c.errs))
+	copy(errs, c.errs)
+	return &withSlice{errs: errs}
+}
+
+// Len returns the number of errors collected so far.
+// It is safe for concurrent use.
+func (c *Collector) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.errs)
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors_test
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/jlourenc/xgo/xerrors"
+)
+
+func TestCollector(t *testing.T) {
+	testCases := []struct {
+		name     string
+		errs     []error
+		expected string
+	}{
+		{
+			name:     "no error",
+			errs:     nil,
+			expected: "",
+		},
+		{
+			name:     "nil errors",
+			errs:     []error{nil, nil},
+			expected: "",
+		},
+		{
+			name:     "single error",
+			errs:     []error{errors.New("err0")},
+			expected: "1 error occurred:\n\t* err0\n",
+		},
+		{
+			name:     "multiple errors including nil",
+			errs:     []error{errors.New("err0"), nil, xerrors.New("err1")},
+			expected: "2 errors occurred:\n\t* err0\n\t* err1\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var c xerrors.Collector
+			for _, err := range tc.errs {
+				c.Add(err)
+			}
+
+			got := c.Err()
+
+			if tc.expected == "" {
+				if got != nil {
+					t.Errorf("expected no error, got %v", got)
+				}
+				return
+			}
+
+			if got == nil || got.Error() != tc.expected {
+				t.Errorf("expected %q, got %v", tc.expected, got)
+			}
+			for _, err := range tc.errs {
+				if err != nil && !errors.Is(got, err) {
+					t.Errorf("expected %v in chain, got %v", err, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCollector_Concurrent(t *testing.T) {
+	const n = 100
+
+	var (
+		c  xerrors.Collector
+		wg sync.WaitGroup
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c.Add(errors.New(strconv.Itoa(i)))
+			_ = c.Err()
+		}(i)
+	}
+	wg.Wait()
+
+	if got := c.Len(); got != n {
+		t.Errorf("expected %d errors, got %d", n, got)
+	}
+}
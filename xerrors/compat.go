@@ -0,0 +1,82 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors
+
+import (
+	"reflect"
+)
+
+// Cause returns the underlying cause of err, if possible, by repeatedly calling
+// the Cause() error method of the errors in err's chain, the same way as the
+// github.com/pkg/errors package does. All the wrapping errors of this package
+// implement the Cause method.
+// If err is nil, Cause returns nil.
+//
+// It is the drop-in replacement for github.com/pkg/errors.Cause.
+// Prefer Unwrap, Is, As or Root which follow the Go standard library conventions.
+func Cause(err error) error {
+	type causer interface {
+		Cause() error
+	}
+
+	for err != nil {
+		c, ok := err.(causer)
+		if !ok {
+			break
+		}
+		err = c.Cause()
+	}
+	return err
+}
+
+// hasStackTrace reports whether err already carries a stack trace, either by implementing
+// StackTracer or the stackTracer interface of the github.com/pkg/errors package.
+func hasStackTrace(err error) bool {
+	_, ok := stackTracerOf(err)
+	return ok
+}
+
+// stackTraceOf returns the stack trace carried by err, if any.
+func stackTraceOf(err error) StackTrace {
+	st, _ := stackTracerOf(err)
+	return st
+}
+
+func stackTracerOf(err error) (StackTrace, bool) {
+	if st, ok := err.(StackTracer); ok {
+		return st.StackTrace(), true
+	}
+	return pkgErrorsStackTrace(err)
+}
+
+// pkgErrorsStackTrace returns the stack trace of err if it implements the stackTracer interface
+// of the github.com/pkg/errors package, without depending on it, i.e. a method StackTrace returning
+// a slice of program counters + 1, such as errors.StackTrace ([]errors.Frame with Frame a uintptr).
+func pkgErrorsStackTrace(err error) (StackTrace, bool) {
+	if err == nil {
+		return nil, false
+	}
+
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() {
+		return nil, false
+	}
+
+	t := m.Type()
+	if t.NumIn() != 0 || t.NumOut() != 1 || t.Out(0).Kind() != reflect.Slice || t.Out(0).Elem().Kind() != reflect.Uintptr {
+		return nil, false
+	}
+
+	v := m.Call(nil)[0]
+	if v.IsNil() {
+		return nil, true
+	}
+
+	st := make(StackTrace, v.Len())
+	for i := range st {
+		st[i] = Frame(v.Index(i).Uint())
+	}
+	return st, true
+}
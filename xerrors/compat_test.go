@@ -0,0 +1,120 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jlourenc/xgo/xerrors"
+)
+
+// pkgStackError mimics an error created by the github.com/pkg/errors package.
+type (
+	pkgFrame      uintptr
+	pkgStackTrace []pkgFrame
+	pkgStackError struct{}
+)
+
+func (pkgStackError) Error() string             { return "pkg stack error" }
+func (pkgStackError) StackTrace() pkgStackTrace { return pkgStackTrace{1, 2} }
+
+func TestCause(t *testing.T) {
+	cause := errors.New("cause")
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{
+			name:     "nil",
+			err:      nil,
+			expected: nil,
+		},
+		{
+			name:     "no causer",
+			err:      cause,
+			expected: cause,
+		},
+		{
+			name:     "with stack",
+			err:      xerrors.WithStack(cause),
+			expected: cause,
+		},
+		{
+			name: "wrappers",
+			err: xerrors.WrapContext(context.Background(),
+				xerrors.Redact(
+					xerrors.WithHTTPStatus(
+						xerrors.Wrap(cause, "wrap"),
+						http.StatusNotFound),
+					"secret"),
+				"context"),
+			expected: cause,
+		},
+		{
+			name:     "stops at non causer",
+			err:      xerrors.Wrap(fmt.Errorf("fmt: %w", cause), "wrap"),
+			expected: errors.Unwrap(xerrors.Wrap(fmt.Errorf("fmt: %w", cause), "wrap")),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xerrors.Cause(tc.err)
+
+			if tc.expected == nil || got == nil {
+				if tc.expected != got {
+					t.Errorf("expected %v, got %v", tc.expected, got)
+				}
+				return
+			}
+			if tc.expected.Error() != got.Error() {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestPkgErrorsStackTrace(t *testing.T) {
+	xerrors.EnableStackTrace(true)
+	defer xerrors.EnableStackTrace(false)
+
+	if got := xerrors.WithStack(pkgStackError{}); got != (pkgStackError{}) {
+		t.Errorf("expected pkg error not to be annotated, got %#v", got)
+	}
+
+	testCases := []struct {
+		name string
+		err  error
+	}{
+		{
+			name: "wrap",
+			err:  xerrors.Wrap(pkgStackError{}, "wrap"),
+		},
+		{
+			name: "join",
+			err:  xerrors.Join(pkgStackError{}, errors.New("error")),
+		},
+		{
+			name: "append",
+			err:  xerrors.Append(pkgStackError{}, errors.New("error")),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.err.(xerrors.StackTracer).StackTrace()
+
+			if len(got) != 2 || got[0] != 1 || got[1] != 2 {
+				t.Errorf("expected stack trace of pkg error, got %v", got)
+			}
+		})
+	}
+}
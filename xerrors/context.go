@@ -0,0 +1,161 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	contextKeysMu sync.RWMutex
+	contextKeys   = map[string]any{}
+)
+
+// RegisterContextKey registers a context key whose value, if present in a context,
+// is captured under name by WrapContext. Registering a name twice replaces the
+// previously registered key.
+// It is safe for concurrent use.
+func RegisterContextKey(name string, key any) {
+	contextKeysMu.Lock()
+	defer contextKeysMu.Unlock()
+	contextKeys[name] = key
+}
+
+// WrapContext returns an error annotating err with the supplied message, the deadline of ctx, if any,
+// and the values of registered context keys (see RegisterContextKey) present in ctx, as well as a stack
+// trace, if enabled and err does not already contain one, at the point WrapContext is called.
+// An empty message leaves the error message of err untouched.
+// If err is nil, WrapContext returns nil.
+//
+// Captured data can be retrieved with ContextDeadline and ContextValues.
+func WrapContext(ctx context.Context, err error, message string) error {
+	if err == nil {
+		return nil
+	}
+
+	if !hasStackTrace(err) {
+		err = &withStack{
+			error: err,
+			stack: callers(),
+		}
+	}
+
+	e := &withContext{
+		err: err,
+		msg: message,
+	}
+	e.deadline, e.hasDeadline = ctx.Deadline()
+
+	contextKeysMu.RLock()
+	defer contextKeysMu.RUnlock()
+	for name, key := range contextKeys {
+		if v := ctx.Value(key); v != nil {
+			if e.values == nil {
+				e.values = make(map[string]any, len(contextKeys))
+			}
+			e.values[name] = v
+		}
+	}
+
+	return e
+}
+
+// ContextDeadline returns the context deadline captured by the first error in err's tree
+// created by WrapContext with a context that had one.
+// The tree is traversed with Walk.
+func ContextDeadline(err error) (deadline time.Time, ok bool) {
+	Walk(err, func(err error) bool {
+		if e, isCtx := err.(*withContext); isCtx && e.hasDeadline {
+			deadline, ok = e.deadline, true
+			return false
+		}
+		return true
+	})
+	return deadline, ok
+}
+
+// ContextValues returns the context values captured by all the errors in err's tree
+// created by WrapContext, keyed by their registered names. When the same name has been
+// captured several times, the value closest to the root of the tree wins.
+// It returns nil if no value has been captured.
+func ContextValues(err error) map[string]any {
+	var values map[string]any
+	Walk(err, func(err error) bool {
+		e, ok := err.(*withContext)
+		if !ok {
+			return true
+		}
+		for k, v := range e.values {
+			if values == nil {
+				values = make(map[string]any, len(e.values))
+			}
+			if _, exist := values[k]; !exist {
+				values[k] = v
+			}
+		}
+		return true
+	})
+	return values
+}
+
+type withContext struct {
+	err         error
+	msg         string
+	deadline    time.Time
+	hasDeadline bool
+	values      map[string]any
+}
+
+// Cause makes withContext implement the github.com/pkg/errors causer interface.
+func (e *withContext) Cause() error { return e.err }
+
+// Error makes withContext implement the error interface.
+func (e *withContext) Error() string {
+	if e.msg == "" {
+		return e.err.Error()
+	}
+	return e.msg + ": " + e.err.Error()
+}
+
+// Format makes withContext implement the fmt.Formatter interface.
+func (e *withContext) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			if e.msg != "" {
+				fmt.Fprint(s, e.msg, ": ")
+			}
+			fmt.Fprintf(s, "%+v", e.Unwrap())
+			return
+		}
+		if s.Flag('#') {
+			fmt.Fprintf(s, "%T{msg:%q, deadline:%q, values:%v, err:(%T)(%p)}", e, e.msg, e.formatDeadline(), e.values, e.err, &e.err)
+			return
+		}
+		fallthrough
+	case 's':
+		fmt.Fprint(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+func (e *withContext) formatDeadline() string {
+	if !e.hasDeadline {
+		return ""
+	}
+	return e.deadline.Format(time.RFC3339Nano)
+}
+
+// StackTrace makes withContext implement the StackTracer interface.
+func (e *withContext) StackTrace() StackTrace {
+	return stackTraceOf(e.err)
+}
+
+// Unwrap makes withContext implement the errors.Unwrapper interface.
+func (e *withContext) Unwrap() error { return e.err }
@@ -0,0 +1,174 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xerrors"
+)
+
+type (
+	requestIDContextKey struct{}
+	tenantContextKey    struct{}
+)
+
+func init() {
+	xerrors.RegisterContextKey("request_id", requestIDContextKey{})
+	xerrors.RegisterContextKey("tenant", tenantContextKey{})
+}
+
+func TestWrapContext(t *testing.T) {
+	deadline := time.Date(2024, time.April, 21, 10, 0, 0, 0, time.UTC)
+	deadlineCtx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	valuesCtx := context.WithValue(context.Background(), requestIDContextKey{}, "req-1")
+	valuesCtx = context.WithValue(valuesCtx, tenantContextKey{}, "acme")
+
+	testCases := []struct {
+		name             string
+		ctx              context.Context //nolint:containedctx // ctx passed to WrapContext
+		err              error
+		msg              string
+		expectedMsg      string
+		expectedDeadline time.Time
+		expectedValues   map[string]any
+	}{
+		{
+			name: "nil",
+			ctx:  context.Background(),
+			err:  nil,
+		},
+		{
+			name:        "no context data",
+			ctx:         context.Background(),
+			err:         errors.New("error"),
+			msg:         "wrap",
+			expectedMsg: "wrap: error",
+		},
+		{
+			name:        "empty message",
+			ctx:         context.Background(),
+			err:         errors.New("error"),
+			msg:         "",
+			expectedMsg: "error",
+		},
+		{
+			name:             "deadline",
+			ctx:              deadlineCtx,
+			err:              errors.New("error"),
+			msg:              "wrap",
+			expectedMsg:      "wrap: error",
+			expectedDeadline: deadline,
+		},
+		{
+			name:           "registered values",
+			ctx:            valuesCtx,
+			err:            errors.New("error"),
+			msg:            "wrap",
+			expectedMsg:    "wrap: error",
+			expectedValues: map[string]any{"request_id": "req-1", "tenant": "acme"},
+		},
+		{
+			name: "outermost values win",
+			ctx:  context.WithValue(context.Background(), requestIDContextKey{}, "req-2"),
+			err: xerrors.Join(
+				errors.New("other"),
+				xerrors.WrapContext(valuesCtx, errors.New("error"), "inner"),
+			),
+			msg:            "outer",
+			expectedMsg:    "outer: 2 errors occurred:\n\t* other\n\t* inner: error\n",
+			expectedValues: map[string]any{"request_id": "req-2", "tenant": "acme"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xerrors.WrapContext(tc.ctx, tc.err, tc.msg)
+
+			if tc.err == nil {
+				if got != nil {
+					t.Errorf("expected nil, got %#v", got)
+				}
+				return
+			}
+
+			if got.Error() != tc.expectedMsg {
+				t.Errorf("expected %q, got %q", tc.expectedMsg, got.Error())
+			}
+
+			gotDeadline, ok := xerrors.ContextDeadline(got)
+			if ok != !tc.expectedDeadline.IsZero() || !gotDeadline.Equal(tc.expectedDeadline) {
+				t.Errorf("expected deadline %v, got %v (%t)", tc.expectedDeadline, gotDeadline, ok)
+			}
+
+			if gotValues := xerrors.ContextValues(got); !maps.Equal(gotValues, tc.expectedValues) {
+				t.Errorf("expected values %v, got %v", tc.expectedValues, gotValues)
+			}
+		})
+	}
+}
+
+func TestWithContext_Format(t *testing.T) {
+	ctx := context.WithValue(context.Background(), requestIDContextKey{}, "req-1")
+
+	testCases := []struct {
+		name             string
+		enableStackTrace bool
+		format           string
+		expected         string
+	}{
+		{
+			name:     "default format",
+			format:   "%v",
+			expected: `^wrapped: error message$`,
+		},
+		{
+			name:             "default format plus extra with stack trace enabled",
+			enableStackTrace: true,
+			format:           "%+v",
+			expected:         `^wrapped: error message(\n(\t)?[0-9a-zA-Z.\/_:-]+)+$`,
+		},
+		{
+			name:     "Go-syntax representation of the value",
+			format:   "%#v",
+			expected: `^\*xerrors\.withContext\{msg:"wrapped", deadline:"", values:map\[request_id:req-1\], err:\(\*xerrors\.withStack\)\(0x[a-f0-9]+\)\}$`,
+		},
+		{
+			name:     "string format",
+			format:   "%s",
+			expected: `^wrapped: error message$`,
+		},
+		{
+			name:     "double-quoted string format",
+			format:   "%q",
+			expected: `^"wrapped: error message"$`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			xerrors.EnableStackTrace(tc.enableStackTrace)
+			defer xerrors.EnableStackTrace(false)
+
+			got := fmt.Sprintf(tc.format, xerrors.WrapContext(ctx, errors.New("error message"), "wrapped"))
+
+			re, err := regexp.Compile(tc.expected)
+			if err != nil {
+				t.Fatalf("invalid regex: %s", tc.expected)
+			}
+			if !re.MatchString(got) {
+				t.Errorf("expected pattern %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
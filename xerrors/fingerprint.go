@@ -0,0 +1,131 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+const fingerprintDefaultFrames = 3
+
+// Fingerprint returns a stable hash identifying err, so that identical failures can be
+// aggregated by monitoring pipelines even if their messages contain variable parts such
+// as identifiers or durations.
+//
+// By default, the hash is computed from:
+// 1) the type of each error in err's tree (see Walk),
+// 2) the message of each error in err's tree normalized with StripDigits,
+// 3) the function names of the top 3 frames of err's stack trace, if any.
+// Line numbers are deliberately left out so that fingerprints survive unrelated code changes.
+//
+// Optional FingerprintOption parameters may be passed in to configure these rules.
+// If err is nil, Fingerprint returns an empty string.
+func Fingerprint(err error, options ...FingerprintOption) string {
+	if err == nil {
+		return ""
+	}
+
+	cfg := fingerprintConfig{
+		frames:    fingerprintDefaultFrames,
+		messages:  true,
+		normalize: StripDigits,
+	}
+	for _, opt := range options {
+		opt.apply(&cfg)
+	}
+
+	h := fnv.New64a()
+
+	Walk(err, func(err error) bool {
+		fmt.Fprintf(h, "%T\n", err)
+		if cfg.messages {
+			fmt.Fprint(h, cfg.normalize(err.Error()), "\n")
+		}
+		return true
+	})
+
+	var st StackTracer
+	if cfg.frames > 0 && As(err, &st) {
+		for i, f := range st.StackTrace() {
+			if i == cfg.frames {
+				break
+			}
+			fmt.Fprint(h, f.name(), "\n")
+		}
+	}
+
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// StripDigits returns s without any of its decimal digits.
+// It is the default message normalization function of Fingerprint.
+func StripDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+type fingerprintConfig struct {
+	frames    int
+	messages  bool
+	normalize func(string) string
+}
+
+type (
+	// FingerprintOption configures the rules used by Fingerprint.
+	FingerprintOption interface {
+		apply(c *fingerprintConfig)
+	}
+
+	funcFingerprintOption struct {
+		fn func(*fingerprintConfig)
+	}
+)
+
+func newFuncFingerprintOption(fn func(*fingerprintConfig)) funcFingerprintOption {
+	return funcFingerprintOption{
+		fn: fn,
+	}
+}
+
+func (o funcFingerprintOption) apply(c *fingerprintConfig) {
+	o.fn(c)
+}
+
+// FingerprintFrames returns a FingerprintOption that configures the number of top stack frames
+// taken into account. A value of 0 excludes stack traces. Value must be >= 0, otherwise it panics.
+func FingerprintFrames(n int) FingerprintOption {
+	if n < 0 {
+		panic("invalid number of frames")
+	}
+	return newFuncFingerprintOption(func(c *fingerprintConfig) {
+		c.frames = n
+	})
+}
+
+// FingerprintMessages returns a FingerprintOption that configures whether error messages
+// are taken into account. When disabled, only error types and stack frames are.
+func FingerprintMessages(enable bool) FingerprintOption {
+	return newFuncFingerprintOption(func(c *fingerprintConfig) {
+		c.messages = enable
+	})
+}
+
+// FingerprintNormalizeFunc returns a FingerprintOption that configures the function used to
+// normalize error messages, e.g. to strip identifiers out. Value must not be nil, otherwise it panics.
+func FingerprintNormalizeFunc(fn func(string) string) FingerprintOption {
+	if fn == nil {
+		panic("normalize function is nil")
+	}
+	return newFuncFingerprintOption(func(c *fingerprintConfig) {
+		c.normalize = fn
+	})
+}
@@ -0,0 +1,176 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors_test
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jlourenc/xgo/xerrors"
+)
+
+func newFingerprintError(id int) error {
+	return xerrors.Newf("user %d not found", id)
+}
+
+func newOtherFingerprintError(id int) error {
+	return xerrors.Newf("user %d not found", id)
+}
+
+func TestFingerprint(t *testing.T) {
+	testCases := []struct {
+		name             string
+		errs             func() (error, error)
+		options          []xerrors.FingerprintOption
+		enableStackTrace bool
+		expectedEqual    bool
+	}{
+		{
+			name:          "same message",
+			errs:          func() (error, error) { return errors.New("error"), errors.New("error") },
+			expectedEqual: true,
+		},
+		{
+			name:          "different messages",
+			errs:          func() (error, error) { return errors.New("error 1"), errors.New("error 2") },
+			options:       []xerrors.FingerprintOption{xerrors.FingerprintNormalizeFunc(strings.TrimSpace)},
+			expectedEqual: false,
+		},
+		{
+			name:          "messages differing by digits",
+			errs:          func() (error, error) { return errors.New("user 1 not found"), errors.New("user 42 not found") },
+			expectedEqual: true,
+		},
+		{
+			name:          "different messages excluded",
+			errs:          func() (error, error) { return errors.New("error 1"), errors.New("error 2") },
+			options:       []xerrors.FingerprintOption{xerrors.FingerprintMessages(false), xerrors.FingerprintNormalizeFunc(strings.TrimSpace)},
+			expectedEqual: true,
+		},
+		{
+			name:          "different types",
+			errs:          func() (error, error) { return errors.New("error"), unstackError{} },
+			options:       []xerrors.FingerprintOption{xerrors.FingerprintMessages(false)},
+			expectedEqual: false,
+		},
+		{
+			name: "different trees",
+			errs: func() (error, error) {
+				return xerrors.Wrap(errors.New("error"), "wrap"), xerrors.Join(errors.New("wrap"), errors.New("error"))
+			},
+			options:       []xerrors.FingerprintOption{xerrors.FingerprintMessages(false)},
+			expectedEqual: false,
+		},
+		{
+			name:             "same call site",
+			errs:             func() (error, error) { return newFingerprintError(1), newFingerprintError(2) },
+			enableStackTrace: true,
+			expectedEqual:    true,
+		},
+		{
+			name:             "different call sites",
+			errs:             func() (error, error) { return newFingerprintError(1), newOtherFingerprintError(2) },
+			enableStackTrace: true,
+			expectedEqual:    false,
+		},
+		{
+			name:             "different call sites with frames excluded",
+			errs:             func() (error, error) { return newFingerprintError(1), newOtherFingerprintError(2) },
+			options:          []xerrors.FingerprintOption{xerrors.FingerprintFrames(0)},
+			enableStackTrace: true,
+			expectedEqual:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			xerrors.EnableStackTrace(tc.enableStackTrace)
+			defer xerrors.EnableStackTrace(false)
+
+			err1, err2 := tc.errs()
+			got1 := xerrors.Fingerprint(err1, tc.options...)
+			got2 := xerrors.Fingerprint(err2, tc.options...)
+
+			if !regexp.MustCompile(`^[0-9a-f]{16}$`).MatchString(got1) {
+				t.Errorf("unexpected fingerprint format: %s", got1)
+			}
+			if tc.expectedEqual != (got1 == got2) {
+				t.Errorf("expected equality %t, got %s and %s", tc.expectedEqual, got1, got2)
+			}
+		})
+	}
+}
+
+func TestFingerprint_Nil(t *testing.T) {
+	if got := xerrors.Fingerprint(nil); got != "" {
+		t.Errorf("expected empty fingerprint, got %s", got)
+	}
+}
+
+func TestStripDigits(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{"no digits", "no digits"},
+		{"user 42 not found after 1.5s", "user  not found after .s"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			got := xerrors.StripDigits(tc.input)
+
+			if tc.expected != got {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestFingerprintOptions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func() xerrors.FingerprintOption
+		panic bool
+	}{
+		{
+			name:  "negative frames",
+			fn:    func() xerrors.FingerprintOption { return xerrors.FingerprintFrames(-1) },
+			panic: true,
+		},
+		{
+			name:  "valid frames",
+			fn:    func() xerrors.FingerprintOption { return xerrors.FingerprintFrames(1) },
+			panic: false,
+		},
+		{
+			name:  "nil normalize function",
+			fn:    func() xerrors.FingerprintOption { return xerrors.FingerprintNormalizeFunc(nil) },
+			panic: true,
+		},
+		{
+			name:  "valid normalize function",
+			fn:    func() xerrors.FingerprintOption { return xerrors.FingerprintNormalizeFunc(strings.ToLower) },
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tc.panic {
+					t.Errorf("expected panic %t, got %v", tc.panic, r)
+				}
+			}()
+
+			if got := tc.fn(); got == nil {
+				t.Error("option expected; got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// ListFormatFunc formats a list of errors aggregated by Join or Append into a string.
+type ListFormatFunc func(errs []error) string
+
+var listFormatFunc ListFormatFunc
+
+// SetListFormatFunc permits setting programmatically the function used to format
+// the message of errors returned by Join and Append. A nil fn restores the default
+// format of each of them. The %+v verb is not affected as it is meant to output
+// detailed information, such as stack traces.
+// It is NOT thread-safe.
+func SetListFormatFunc(fn ListFormatFunc) {
+	listFormatFunc = fn
+}
+
+// BulletListFormat formats errs as a bulleted list, indenting multi-line messages:
+//
+//	2 errors occurred:
+//		* error 0
+//		* error 1
+//
+// A single error is formatted as its message only.
+// It is the default format of errors returned by Join.
+func BulletListFormat(errs []error) string {
+	switch len(errs) {
+	case 0:
+		return ""
+	case 1:
+		return errs[0].Error()
+	}
+
+	b := []byte(strconv.Itoa(len(errs)) + " errors occurred:\n")
+	for _, err := range errs {
+		b = append(b, '\t', '*', ' ')
+
+		lines := strings.Split(strings.TrimSuffix(err.Error(), "\n"), "\n")
+		b = append(b, lines[0]...)
+		b = append(b, '\n')
+
+		for _, line := range lines[1:] {
+			b = append(b, '\t')
+			b = append(b, line...)
+			b = append(b, '\n')
+		}
+	}
+	return unsafe.String(&b[0], len(b))
+}
+
+// JSONListFormat formats errs as a JSON array of strings, each being an error message.
+func JSONListFormat(errs []error) string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+
+	b, err := json.Marshal(msgs)
+	if err != nil { // cannot happen when marshaling strings.
+		return ""
+	}
+	return string(b)
+}
+
+// SingleLineListFormat formats errs on a single line, separating error messages with
+// semicolons. Multi-line error messages are flattened the same way.
+func SingleLineListFormat(errs []error) string {
+	var sb strings.Builder
+	for i, err := range errs {
+		if i > 0 {
+			sb.WriteString("; ")
+		}
+		lines := strings.Split(strings.TrimSuffix(err.Error(), "\n"), "\n")
+		for j, line := range lines {
+			if j > 0 {
+				sb.WriteString("; ")
+			}
+			sb.WriteString(strings.TrimSpace(line))
+		}
+	}
+	return sb.String()
+}
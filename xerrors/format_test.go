@@ -0,0 +1,147 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jlourenc/xgo/xerrors"
+)
+
+func TestListFormatFuncs(t *testing.T) {
+	errs := []error{
+		errors.New("err0"),
+		errors.New("err1.0\nerr1.1\n"),
+		errors.New(`err"2"`),
+	}
+
+	testCases := []struct {
+		name     string
+		fn       xerrors.ListFormatFunc
+		errs     []error
+		expected string
+	}{
+		{
+			name:     "bullet list with no error",
+			fn:       xerrors.BulletListFormat,
+			errs:     nil,
+			expected: "",
+		},
+		{
+			name:     "bullet list with single error",
+			fn:       xerrors.BulletListFormat,
+			errs:     errs[:1],
+			expected: "err0",
+		},
+		{
+			name:     "bullet list with multiple errors",
+			fn:       xerrors.BulletListFormat,
+			errs:     errs,
+			expected: "3 errors occurred:\n\t* err0\n\t* err1.0\n\terr1.1\n\t* err\"2\"\n",
+		},
+		{
+			name:     "JSON list with no error",
+			fn:       xerrors.JSONListFormat,
+			errs:     nil,
+			expected: "[]",
+		},
+		{
+			name:     "JSON list with multiple errors",
+			fn:       xerrors.JSONListFormat,
+			errs:     errs,
+			expected: `["err0","err1.0\nerr1.1\n","err\"2\""]`,
+		},
+		{
+			name:     "single line with no error",
+			fn:       xerrors.SingleLineListFormat,
+			errs:     nil,
+			expected: "",
+		},
+		{
+			name:     "single line with multiple errors",
+			fn:       xerrors.SingleLineListFormat,
+			errs:     errs,
+			expected: `err0; err1.0; err1.1; err"2"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.fn(tc.errs)
+
+			if tc.expected != got {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestSetListFormatFunc(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fn       xerrors.ListFormatFunc
+		err      error
+		format   string
+		expected string
+	}{
+		{
+			name:     "default join format",
+			fn:       nil,
+			err:      xerrors.Join(xerrors.New("err0"), xerrors.New("err1")),
+			format:   "%v",
+			expected: "2 errors occurred:\n\t* err0\n\t* err1\n",
+		},
+		{
+			name:     "default append format",
+			fn:       nil,
+			err:      xerrors.Append(xerrors.New("err0")),
+			format:   "%v",
+			expected: "1 error occurred:\n\t* err0\n",
+		},
+		{
+			name:     "single line join format",
+			fn:       xerrors.SingleLineListFormat,
+			err:      xerrors.Join(xerrors.New("err0"), xerrors.Join(xerrors.New("err1.0"), xerrors.New("err1.1"))),
+			format:   "%s",
+			expected: "err0; err1.0; err1.1",
+		},
+		{
+			name:     "single line append format",
+			fn:       xerrors.SingleLineListFormat,
+			err:      xerrors.Append(xerrors.New("err0"), xerrors.New("err1")),
+			format:   "%q",
+			expected: `"err0; err1"`,
+		},
+		{
+			name:     "JSON join format",
+			fn:       xerrors.JSONListFormat,
+			err:      xerrors.Wrap(xerrors.Join(xerrors.New("err0"), xerrors.New("err1")), "wrap"),
+			format:   "%v",
+			expected: `wrap: ["err0","err1"]`,
+		},
+		{
+			name:     "detailed format not affected",
+			fn:       xerrors.JSONListFormat,
+			err:      xerrors.Join(xerrors.New("err0"), xerrors.New("err1")),
+			format:   "%+v",
+			expected: "2 errors occurred:\n\t* err0\n\t* err1\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			xerrors.SetListFormatFunc(tc.fn)
+			defer xerrors.SetListFormatFunc(nil)
+
+			got := fmt.Sprintf(tc.format, tc.err)
+
+			if tc.expected != got {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
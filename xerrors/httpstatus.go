@@ -0,0 +1,96 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// WithHTTPStatus annotates err with an HTTP status code and a stack trace,
+// if enabled and err does not already contain one, at the point WithHTTPStatus is called.
+// If err is nil, WithHTTPStatus returns nil.
+func WithHTTPStatus(err error, code int) error {
+	if err == nil {
+		return nil
+	}
+
+	if !hasStackTrace(err) {
+		err = &withStack{
+			error: err,
+			stack: callers(),
+		}
+	}
+
+	return &withHTTPStatus{
+		err:  err,
+		code: code,
+	}
+}
+
+// HTTPStatus returns the HTTP status code attached to the first error in err's tree
+// that has one, either via WithHTTPStatus or by implementing a method HTTPStatus() int.
+// The tree is traversed following both Unwrap() error and Unwrap() []error methods.
+//
+// If err is nil, HTTPStatus returns http.StatusOK.
+// If no error in the tree carries an HTTP status code, it returns http.StatusInternalServerError.
+func HTTPStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	var hs httpStatuser
+	if As(err, &hs) {
+		return hs.HTTPStatus()
+	}
+
+	return http.StatusInternalServerError
+}
+
+type httpStatuser interface {
+	HTTPStatus() int
+}
+
+type withHTTPStatus struct {
+	err  error
+	code int
+}
+
+// Cause makes withHTTPStatus implement the github.com/pkg/errors causer interface.
+func (e *withHTTPStatus) Cause() error { return e.err }
+
+// Error makes withHTTPStatus implement the error interface.
+func (e *withHTTPStatus) Error() string { return e.err.Error() }
+
+// Format makes withHTTPStatus implement the fmt.Formatter interface.
+func (e *withHTTPStatus) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%+v", e.Unwrap())
+			return
+		}
+		if s.Flag('#') {
+			fmt.Fprintf(s, "%T{code:%d, err:(%T)(%p)}", e, e.code, e.err, &e.err)
+			return
+		}
+		fallthrough
+	case 's':
+		fmt.Fprint(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+// HTTPStatus returns the HTTP status code attached to the error.
+func (e *withHTTPStatus) HTTPStatus() int { return e.code }
+
+// StackTrace makes withHTTPStatus implement the StackTracer interface.
+func (e *withHTTPStatus) StackTrace() StackTrace {
+	return stackTraceOf(e.err)
+}
+
+// Unwrap makes withHTTPStatus implement the errors.Unwrapper interface.
+func (e *withHTTPStatus) Unwrap() error { return e.err }
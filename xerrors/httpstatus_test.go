@@ -0,0 +1,219 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/jlourenc/xgo/xerrors"
+)
+
+type httpStatusError struct{}
+
+func (httpStatusError) Error() string   { return "http status error" }
+func (httpStatusError) HTTPStatus() int { return http.StatusTeapot }
+
+func TestWithHTTPStatus(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name: "nil",
+			err:  nil,
+		},
+		{
+			name:     "stack error",
+			err:      stackError{},
+			expected: "stack error",
+		},
+		{
+			name:     "unstack error",
+			err:      unstackError{},
+			expected: "unstack error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xerrors.WithHTTPStatus(tc.err, http.StatusNotFound)
+
+			if tc.err == nil {
+				if got != nil {
+					t.Errorf("expected nil, got %#v", got)
+				}
+				return
+			}
+
+			if got.Error() != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+			if !errors.Is(got, tc.err) {
+				t.Errorf("expected %#v in chain, got %#v", tc.err, got)
+			}
+		})
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{
+			name:     "nil",
+			err:      nil,
+			expected: http.StatusOK,
+		},
+		{
+			name:     "no status",
+			err:      xerrors.New("error"),
+			expected: http.StatusInternalServerError,
+		},
+		{
+			name:     "status",
+			err:      xerrors.WithHTTPStatus(xerrors.New("error"), http.StatusNotFound),
+			expected: http.StatusNotFound,
+		},
+		{
+			name:     "outermost status wins",
+			err:      xerrors.WithHTTPStatus(xerrors.WithHTTPStatus(xerrors.New("error"), http.StatusNotFound), http.StatusConflict),
+			expected: http.StatusConflict,
+		},
+		{
+			name:     "wrapped status",
+			err:      xerrors.Wrap(fmt.Errorf("fmt: %w", xerrors.WithHTTPStatus(xerrors.New("error"), http.StatusBadRequest)), "wrap"),
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "joined status",
+			err:      xerrors.Join(xerrors.New("error"), xerrors.WithHTTPStatus(xerrors.New("error"), http.StatusForbidden)),
+			expected: http.StatusForbidden,
+		},
+		{
+			name:     "appended status",
+			err:      xerrors.Append(xerrors.New("error"), xerrors.WithHTTPStatus(xerrors.New("error"), http.StatusUnauthorized)),
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:     "custom error with status",
+			err:      xerrors.Wrap(httpStatusError{}, "wrap"),
+			expected: http.StatusTeapot,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xerrors.HTTPStatus(tc.err)
+
+			if tc.expected != got {
+				t.Errorf("expected %d, got %d", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestWithHTTPStatus_Format(t *testing.T) {
+	testCases := []struct {
+		name             string
+		enableStackTrace bool
+		format           string
+		expected         string
+	}{
+		{
+			name:     "default format",
+			format:   "%v",
+			expected: `^error message$`,
+		},
+		{
+			name:     "default format plus extra with stack trace disabled",
+			format:   "%+v",
+			expected: `^error message$`,
+		},
+		{
+			name:             "default format plus extra with stack trace enabled",
+			enableStackTrace: true,
+			format:           "%+v",
+			expected:         `^error message(\n(\t)?[0-9a-zA-Z.\/_:-]+)+$`,
+		},
+		{
+			name:     "Go-syntax representation of the value",
+			format:   "%#v",
+			expected: `^\*xerrors\.withHTTPStatus\{code:404, err:\(\*xerrors\.withStack\)\(0x[a-f0-9]+\)\}$`,
+		},
+		{
+			name:     "string format",
+			format:   "%s",
+			expected: `^error message$`,
+		},
+		{
+			name:     "double-quoted string format",
+			format:   "%q",
+			expected: `^"error message"$`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			xerrors.EnableStackTrace(tc.enableStackTrace)
+			defer xerrors.EnableStackTrace(false)
+
+			got := fmt.Sprintf(tc.format, xerrors.WithHTTPStatus(errors.New("error message"), http.StatusNotFound))
+
+			re, err := regexp.Compile(tc.expected)
+			if err != nil {
+				t.Fatalf("invalid regex: %s", tc.expected)
+			}
+			if !re.MatchString(got) {
+				t.Errorf("expected pattern %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestWithHTTPStatus_StackTrace(t *testing.T) {
+	testCases := []struct {
+		name             string
+		err              error
+		enableStackTrace bool
+		expectedSize     int
+	}{
+		{
+			name:         "stack error",
+			err:          &stackError{},
+			expectedSize: 4,
+		},
+		{
+			name:         "unstack error with stack trace disabled",
+			err:          &unstackError{},
+			expectedSize: 0,
+		},
+		{
+			name:             "unstack error with stack trace enabled",
+			err:              &unstackError{},
+			enableStackTrace: true,
+			expectedSize:     3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			xerrors.EnableStackTrace(tc.enableStackTrace)
+			defer xerrors.EnableStackTrace(false)
+
+			got := xerrors.WithHTTPStatus(tc.err, http.StatusNotFound).(xerrors.StackTracer).StackTrace()
+
+			if len(got) != tc.expectedSize {
+				t.Errorf("expected stack trace of size %d, got %v", tc.expectedSize, got)
+			}
+		})
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
-	"unsafe"
 )
 
 // Join returns an error that wraps the given errors.
@@ -38,7 +37,7 @@ func Join(errs ...error) error {
 		if err == nil {
 			continue
 		}
-		if _, ok := err.(StackTracer); !ok {
+		if !hasStackTrace(err) {
 			err = &withStack{
 				error: err,
 				stack: callers(),
@@ -49,33 +48,64 @@ func Join(errs ...error) error {
 	return e
 }
 
+// JoinFlat is like Join, except that errors grouping multiple errors created by Join,
+// JoinFlat or Append are replaced by the errors they group, recursively, so that the
+// returned error holds a single level of errors. The order of errors is preserved:
+// the errors of a group take the place of the group itself.
+// Errors wrapping a group, e.g. with Wrap, are not flattened since they carry their own message.
+func JoinFlat(errs ...error) error {
+	var flat []error
+	for _, err := range errs {
+		flat = appendFlat(flat, err)
+	}
+	if len(flat) == 0 {
+		return nil
+	}
+	e := &joinError{
+		errs: make([]error, 0, len(flat)),
+	}
+	for _, err := range flat {
+		if !hasStackTrace(err) {
+			err = &withStack{
+				error: err,
+				stack: callers(),
+			}
+		}
+		e.errs = append(e.errs, err)
+	}
+	return e
+}
+
+func appendFlat(flat []error, err error) []error {
+	var errs []error
+	switch e := err.(type) {
+	case nil:
+		return flat
+	case *joinError:
+		errs = e.errs
+	case *withSlice:
+		errs = e.errs
+	default:
+		return append(flat, err)
+	}
+	for _, err := range errs {
+		flat = appendFlat(flat, err)
+	}
+	return flat
+}
+
 type joinError struct {
 	errs []error
 }
 
 // Error makes joinError implement the error interface.
+// The output is formatted by the function set with SetListFormatFunc, if any,
+// and by BulletListFormat otherwise.
 func (e *joinError) Error() string {
-	// Since Join returns nil if every value in errs is nil,
-	// e.errs cannot be empty.
-	if len(e.errs) == 1 {
-		return e.errs[0].Error()
-	}
-
-	b := []byte(strconv.Itoa(len(e.errs)) + " errors occurred:\n")
-	for _, err := range e.errs {
-		b = append(b, '\t', '*', ' ')
-
-		lines := strings.Split(strings.TrimSuffix(err.Error(), "\n"), "\n")
-		b = append(b, lines[0]...)
-		b = append(b, '\n')
-
-		for _, line := range lines[1:] {
-			b = append(b, '\t')
-			b = append(b, line...)
-			b = append(b, '\n')
-		}
+	if listFormatFunc != nil {
+		return listFormatFunc(e.errs)
 	}
-	return unsafe.String(&b[0], len(b))
+	return BulletListFormat(e.errs)
 }
 
 // Format makes joinError implement the fmt.Formatter interface.
@@ -112,7 +142,7 @@ func (e *joinError) Format(s fmt.State, verb rune) {
 
 // StackTrace makes joinError implement the StackTracer interface.
 func (e *joinError) StackTrace() StackTrace {
-	return e.errs[0].(StackTracer).StackTrace()
+	return stackTraceOf(e.errs[0])
 }
 
 // Unwrap makes joinError implement the errors.Unwrapper interface.
@@ -82,6 +82,82 @@ func TestJoin(t *testing.T) {
 	}
 }
 
+func TestJoinFlat(t *testing.T) {
+	err0 := xerrors.New("err0")
+	err1 := xerrors.New("err1")
+	err2 := xerrors.New("err2")
+	err3 := xerrors.New("err3")
+	wrapped := xerrors.Wrap(xerrors.Join(err2, err3), "wrap")
+
+	testCases := []struct {
+		name     string
+		errs     []error
+		expected []error
+	}{
+		{
+			name:     "join nothing",
+			errs:     nil,
+			expected: nil,
+		},
+		{
+			name:     "join nil errors",
+			errs:     []error{nil, nil},
+			expected: nil,
+		},
+		{
+			name:     "join empty groups",
+			errs:     []error{xerrors.Join(), xerrors.Append(nil)},
+			expected: nil,
+		},
+		{
+			name:     "join errors including nil",
+			errs:     []error{nil, err0, nil, err1},
+			expected: []error{err0, err1},
+		},
+		{
+			name:     "join nested joined errors",
+			errs:     []error{err0, xerrors.Join(err1, xerrors.Join(err2, nil, err3))},
+			expected: []error{err0, err1, err2, err3},
+		},
+		{
+			name:     "join nested appended errors",
+			errs:     []error{xerrors.Append(err0, xerrors.Join(err1, err2)), err3},
+			expected: []error{err0, err1, err2, err3},
+		},
+		{
+			name:     "join wrapped group",
+			errs:     []error{err0, wrapped},
+			expected: []error{err0, wrapped},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xerrors.JoinFlat(tc.errs...)
+
+			if tc.expected == nil {
+				if got != nil {
+					t.Errorf("expected no error, got %s", got)
+				}
+				return
+			}
+
+			if got == nil {
+				t.Errorf("expected %q, got no error", tc.expected)
+				return
+			}
+
+			errs := got.(interface{ Unwrap() []error }).Unwrap()
+			if !slices.Equal(errs, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+			if len(errs) != cap(errs) {
+				t.Errorf("with %v len!=cap, len=%d, cap=%d", tc.errs, len(errs), cap(errs))
+			}
+		})
+	}
+}
+
 func TestJoinError_Error(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -0,0 +1,63 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors
+
+import "errors"
+
+// FromMessages returns an error grouping one error per message, in order, as
+// Join does. Empty messages are discarded. FromMessages returns nil if msgs
+// contains no non-empty message. A stack trace is recorded, if enabled,
+// at the point FromMessages is called.
+//
+// It is the counterpart of Messages, e.g. to turn an `"errors": [...]` payload
+// of an API response back into an error.
+func FromMessages(msgs []string) error {
+	n := 0
+	for _, msg := range msgs {
+		if msg != "" {
+			n++
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+
+	stack := callers()
+
+	e := &joinError{
+		errs: make([]error, 0, n),
+	}
+	for _, msg := range msgs {
+		if msg == "" {
+			continue
+		}
+		e.errs = append(e.errs, &withStack{
+			error: errors.New(msg),
+			stack: stack,
+		})
+	}
+	return e
+}
+
+// Messages returns the messages of the errors grouped by err, in order.
+// Errors grouping multiple errors created by Join, JoinFlat, Append or FromMessages
+// are flattened, as JoinFlat does, so that nested groups contribute one message
+// per error. If err is not such a group, Messages returns a single message: err's.
+// If err is nil, Messages returns nil.
+//
+// It is the counterpart of FromMessages, e.g. to render an error as an
+// `"errors": [...]` payload of an API response.
+func Messages(err error) []string {
+	errs := appendFlat(nil, err)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return msgs
+}
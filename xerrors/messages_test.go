@@ -0,0 +1,117 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/jlourenc/xgo/xerrors"
+)
+
+func TestFromMessages(t *testing.T) {
+	testCases := []struct {
+		name     string
+		msgs     []string
+		expected []string
+	}{
+		{
+			name:     "nil",
+			msgs:     nil,
+			expected: nil,
+		},
+		{
+			name:     "empty messages",
+			msgs:     []string{"", ""},
+			expected: nil,
+		},
+		{
+			name:     "single message",
+			msgs:     []string{"err0"},
+			expected: []string{"err0"},
+		},
+		{
+			name:     "messages including empty ones",
+			msgs:     []string{"", "err0", "", "err1"},
+			expected: []string{"err0", "err1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xerrors.FromMessages(tc.msgs)
+
+			if tc.expected == nil {
+				if got != nil {
+					t.Errorf("expected no error, got %s", got)
+				}
+				return
+			}
+
+			errs := xerrors.Errors(got)
+			if len(errs) != len(tc.expected) {
+				t.Fatalf("expected %d errors, got %d", len(tc.expected), len(errs))
+			}
+			for i, err := range errs {
+				if err.Error() != tc.expected[i] {
+					t.Errorf("expected %q at index %d, got %q", tc.expected[i], i, err)
+				}
+				if _, ok := err.(xerrors.StackTracer); !ok {
+					t.Errorf("expected error at index %d to have a stack trace", i)
+				}
+			}
+		})
+	}
+}
+
+func TestMessages(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected []string
+	}{
+		{
+			name:     "nil",
+			err:      nil,
+			expected: nil,
+		},
+		{
+			name:     "single error",
+			err:      errors.New("err0"),
+			expected: []string{"err0"},
+		},
+		{
+			name:     "wrapped group",
+			err:      xerrors.Wrap(xerrors.Join(errors.New("err0"), errors.New("err1")), "wrap"),
+			expected: []string{"wrap: 2 errors occurred:\n\t* err0\n\t* err1\n"},
+		},
+		{
+			name:     "joined errors",
+			err:      xerrors.Join(errors.New("err0"), errors.New("err1")),
+			expected: []string{"err0", "err1"},
+		},
+		{
+			name:     "nested groups",
+			err:      xerrors.Append(errors.New("err0"), xerrors.Join(errors.New("err1"), xerrors.Wrap(errors.New("err2"), "wrap"))),
+			expected: []string{"err0", "err1", "wrap: err2"},
+		},
+		{
+			name:     "round trip",
+			err:      xerrors.FromMessages([]string{"err0", "err1"}),
+			expected: []string{"err0", "err1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xerrors.Messages(tc.err)
+
+			if !slices.Equal(tc.expected, got) {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
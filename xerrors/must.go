@@ -0,0 +1,74 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors
+
+// Expect returns v if err is nil. Otherwise, it panics with err annotated with
+// the supplied message and a stack trace, if enabled and err does not already contain one,
+// at the point Expect is called.
+//
+// It is intended for use in variable initializations where failure is not expected.
+func Expect[T any](v T, err error, message string) T {
+	if err != nil {
+		if !hasStackTrace(err) {
+			err = &withStack{
+				error: err,
+				stack: callers(),
+			}
+		}
+		panic(&withWrap{err: err, msg: message})
+	}
+	return v
+}
+
+// Ignore explicitly discards err. It documents at the call site that the error
+// is deliberately not handled, e.g. when closing a read-only resource.
+func Ignore(error) {}
+
+// Must returns v if err is nil. Otherwise, it panics with err annotated with a
+// stack trace, if enabled and err does not already contain one, at the point Must is called.
+//
+// It is intended for use in variable initializations where failure is not expected, such as
+//
+//	var t = xerrors.Must(template.New("name").Parse("text"))
+func Must[T any](v T, err error) T {
+	if err != nil {
+		if !hasStackTrace(err) {
+			err = &withStack{
+				error: err,
+				stack: callers(),
+			}
+		}
+		panic(err)
+	}
+	return v
+}
+
+// Must2 is the equivalent of Must for functions returning two values and an error.
+func Must2[T1, T2 any](v1 T1, v2 T2, err error) (T1, T2) {
+	if err != nil {
+		if !hasStackTrace(err) {
+			err = &withStack{
+				error: err,
+				stack: callers(),
+			}
+		}
+		panic(err)
+	}
+	return v1, v2
+}
+
+// Must3 is the equivalent of Must for functions returning three values and an error.
+func Must3[T1, T2, T3 any](v1 T1, v2 T2, v3 T3, err error) (T1, T2, T3) {
+	if err != nil {
+		if !hasStackTrace(err) {
+			err = &withStack{
+				error: err,
+				stack: callers(),
+			}
+		}
+		panic(err)
+	}
+	return v1, v2, v3
+}
@@ -0,0 +1,155 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jlourenc/xgo/xerrors"
+)
+
+var errMust = errors.New("must error")
+
+func recoverError(tb testing.TB, fn func()) (err error) {
+	tb.Helper()
+
+	defer func() {
+		if r := recover(); r != nil {
+			var ok bool
+			if err, ok = r.(error); !ok {
+				tb.Fatalf("expected panic with an error, got %#v", r)
+			}
+		}
+	}()
+
+	fn()
+	return nil
+}
+
+func TestExpect(t *testing.T) {
+	testCases := []struct {
+		name        string
+		err         error
+		expectedErr string
+	}{
+		{
+			name: "no error",
+			err:  nil,
+		},
+		{
+			name:        "error",
+			err:         errMust,
+			expectedErr: "expected value: must error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got int
+			err := recoverError(t, func() {
+				got = xerrors.Expect(1, tc.err, "expected value")
+			})
+
+			if tc.err == nil {
+				if err != nil || got != 1 {
+					t.Errorf("expected 1 and no panic, got %d and %v", got, err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != tc.expectedErr || !errors.Is(err, tc.err) {
+				t.Errorf("expected panic with %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestIgnore(_ *testing.T) {
+	xerrors.Ignore(errMust)
+	xerrors.Ignore(nil)
+}
+
+func TestMust(t *testing.T) {
+	testCases := []struct {
+		name             string
+		err              error
+		enableStackTrace bool
+	}{
+		{
+			name: "no error",
+			err:  nil,
+		},
+		{
+			name: "error with stack trace disabled",
+			err:  errMust,
+		},
+		{
+			name:             "error with stack trace enabled",
+			err:              errMust,
+			enableStackTrace: true,
+		},
+		{
+			name:             "stack error",
+			err:              stackError{},
+			enableStackTrace: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			xerrors.EnableStackTrace(tc.enableStackTrace)
+			defer xerrors.EnableStackTrace(false)
+
+			var (
+				got1       int
+				got2       string
+				got3       bool
+				err1, err2 error
+				err3       error
+			)
+			err1 = recoverError(t, func() { got1 = xerrors.Must(1, tc.err) })
+			err2 = recoverError(t, func() { got1, got2 = xerrors.Must2(1, "2", tc.err) })
+			err3 = recoverError(t, func() { got1, got2, got3 = xerrors.Must3(1, "2", true, tc.err) })
+
+			if tc.err == nil {
+				if err1 != nil || err2 != nil || err3 != nil {
+					t.Errorf("expected no panic, got %v, %v, %v", err1, err2, err3)
+				}
+				if got1 != 1 || got2 != "2" || !got3 {
+					t.Errorf("expected values 1, 2, true, got %d, %s, %t", got1, got2, got3)
+				}
+				return
+			}
+
+			for _, err := range []error{err1, err2, err3} {
+				if !errors.Is(err, tc.err) {
+					t.Errorf("expected panic with %v, got %v", tc.err, err)
+				}
+
+				st, ok := err.(xerrors.StackTracer)
+				if !ok {
+					t.Fatalf("expected panic with a stack tracer, got %#v", err)
+				}
+				if tc.enableStackTrace != (len(st.StackTrace()) > 0) {
+					t.Errorf("expected stack trace %t, got %v", tc.enableStackTrace, st.StackTrace())
+				}
+			}
+		})
+	}
+}
+
+func TestMust_StackTrace(t *testing.T) {
+	xerrors.EnableStackTrace(true)
+	defer xerrors.EnableStackTrace(false)
+
+	err := recoverError(t, func() { xerrors.Must(0, errMust) })
+
+	frame := err.(xerrors.StackTracer).StackTrace()[0]
+	if got := fmt.Sprintf("%n", frame); got != "TestMust_StackTrace.func1" {
+		t.Errorf("expected first frame to be the caller of Must, got %s", got)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// RedactedPlaceholder is the string replacing secrets in redacted error messages.
+const RedactedPlaceholder = "[REDACTED]"
+
+// Redact returns an error masking every occurrence of secrets in the message of err,
+// including in the messages of nested and grouped errors, with RedactedPlaceholder.
+// Both Error and Format outputs are redacted. Empty secrets are ignored.
+// A stack trace is recorded, if enabled and err does not already contain one,
+// at the point Redact is called.
+// If err is nil, Redact returns nil.
+//
+// Note that errors obtained by unwrapping the returned error are not redacted.
+func Redact(err error, secrets ...string) error {
+	if err == nil {
+		return nil
+	}
+
+	if !hasStackTrace(err) {
+		err = &withStack{
+			error: err,
+			stack: callers(),
+		}
+	}
+
+	return newWithRedaction(err, secrets)
+}
+
+// WithRedactedValue returns an error masking every occurrence of value in the message of err.
+// If err has been returned by Redact or WithRedactedValue, value is added to the set of masked
+// secrets instead of wrapping err once more, so that secrets can be registered incrementally
+// along the call chain.
+// If err is nil, WithRedactedValue returns nil.
+//
+// See Redact for more information.
+func WithRedactedValue(err error, value string) error {
+	if err == nil {
+		return nil
+	}
+
+	if e, ok := err.(*withRedaction); ok {
+		return newWithRedaction(e.err, append(slices.Clip(e.secrets), value))
+	}
+
+	if !hasStackTrace(err) {
+		err = &withStack{
+			error: err,
+			stack: callers(),
+		}
+	}
+
+	return newWithRedaction(err, []string{value})
+}
+
+type withRedaction struct {
+	err      error
+	secrets  []string
+	replacer *strings.Replacer
+}
+
+func newWithRedaction(err error, secrets []string) *withRedaction {
+	s := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		if secret != "" && !slices.Contains(s, secret) {
+			s = append(s, secret)
+		}
+	}
+
+	// Longest secrets first so that a secret containing another one is fully masked.
+	slices.SortStableFunc(s, func(a, b string) int { return cmp.Compare(len(b), len(a)) })
+
+	oldnew := make([]string, 0, 2*len(s))
+	for _, secret := range s {
+		oldnew = append(oldnew, secret, RedactedPlaceholder)
+	}
+
+	return &withRedaction{
+		err:      err,
+		secrets:  s,
+		replacer: strings.NewReplacer(oldnew...),
+	}
+}
+
+// Cause makes withRedaction implement the github.com/pkg/errors causer interface.
+func (e *withRedaction) Cause() error { return e.err }
+
+// Error makes withRedaction implement the error interface.
+func (e *withRedaction) Error() string { return e.replacer.Replace(e.err.Error()) }
+
+// Format makes withRedaction implement the fmt.Formatter interface.
+func (e *withRedaction) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprint(s, e.replacer.Replace(fmt.Sprintf("%+v", e.err)))
+			return
+		}
+		if s.Flag('#') {
+			fmt.Fprintf(s, "%T{secrets:%d, err:(%T)(%p)}", e, len(e.secrets), e.err, &e.err)
+			return
+		}
+		fallthrough
+	case 's':
+		fmt.Fprint(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+// StackTrace makes withRedaction implement the StackTracer interface.
+func (e *withRedaction) StackTrace() StackTrace {
+	return stackTraceOf(e.err)
+}
+
+// Unwrap makes withRedaction implement the errors.Unwrapper interface.
+func (e *withRedaction) Unwrap() error { return e.err }
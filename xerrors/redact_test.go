@@ -0,0 +1,200 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors_test
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/jlourenc/xgo/xerrors"
+)
+
+func TestRedact(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		secrets  []string
+		expected string
+	}{
+		{
+			name:     "nil",
+			err:      nil,
+			secrets:  []string{"secret"},
+			expected: "",
+		},
+		{
+			name:     "no secret",
+			err:      errors.New("password=secret"),
+			secrets:  nil,
+			expected: "password=secret",
+		},
+		{
+			name:     "empty secret",
+			err:      errors.New("password=secret"),
+			secrets:  []string{""},
+			expected: "password=secret",
+		},
+		{
+			name:     "single secret",
+			err:      errors.New("password=secret, again=secret"),
+			secrets:  []string{"secret"},
+			expected: "password=[REDACTED], again=[REDACTED]",
+		},
+		{
+			name:     "overlapping secrets",
+			err:      errors.New("token=abc, password=abcdef"),
+			secrets:  []string{"abc", "abcdef"},
+			expected: "token=[REDACTED], password=[REDACTED]",
+		},
+		{
+			name:     "wrapped secret",
+			err:      xerrors.Wrap(fmt.Errorf("dial user:secret@host: %w", errors.New("refused")), "connect"),
+			secrets:  []string{"secret"},
+			expected: "connect: dial user:[REDACTED]@host: refused",
+		},
+		{
+			name:     "grouped secrets",
+			err:      xerrors.Join(errors.New("token=t0k"), xerrors.Append(errors.New("password=pwd"))),
+			secrets:  []string{"t0k", "pwd"},
+			expected: "2 errors occurred:\n\t* token=[REDACTED]\n\t* 1 error occurred:\n\t\t* password=[REDACTED]\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xerrors.Redact(tc.err, tc.secrets...)
+
+			if tc.err == nil {
+				if got != nil {
+					t.Errorf("expected nil, got %#v", got)
+				}
+				return
+			}
+
+			if got.Error() != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got.Error())
+			}
+			if !errors.Is(got, tc.err) {
+				t.Errorf("expected %v in chain, got %v", tc.err, got)
+			}
+		})
+	}
+}
+
+func TestWithRedactedValue(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected string
+		depth    int
+	}{
+		{
+			name: "nil",
+			err:  nil,
+		},
+		{
+			name:     "non-redacted error",
+			err:      xerrors.New("login=user, password=secret"),
+			expected: "login=user, password=[REDACTED]",
+			depth:    1,
+		},
+		{
+			name:     "redacted error",
+			err:      xerrors.Redact(xerrors.New("login=user, password=secret"), "user"),
+			expected: "login=[REDACTED], password=[REDACTED]",
+			depth:    1,
+		},
+		{
+			name:     "wrapped redacted error",
+			err:      xerrors.Wrap(xerrors.Redact(xerrors.New("login=user, password=secret"), "user"), "wrap"),
+			expected: "wrap: login=[REDACTED], password=[REDACTED]",
+			depth:    2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xerrors.WithRedactedValue(tc.err, "secret")
+
+			if tc.err == nil {
+				if got != nil {
+					t.Errorf("expected nil, got %#v", got)
+				}
+				return
+			}
+
+			if got.Error() != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got.Error())
+			}
+
+			depth := 0
+			xerrors.Walk(got, func(err error) bool {
+				if fmt.Sprintf("%T", err) == "*xerrors.withRedaction" {
+					depth++
+				}
+				return true
+			})
+			if depth != tc.depth {
+				t.Errorf("expected %d redaction wrappers, got %d", tc.depth, depth)
+			}
+		})
+	}
+}
+
+func TestWithRedaction_Format(t *testing.T) {
+	testCases := []struct {
+		name             string
+		enableStackTrace bool
+		format           string
+		expected         string
+	}{
+		{
+			name:     "default format",
+			format:   "%v",
+			expected: `^wrapped: password=\[REDACTED\]$`,
+		},
+		{
+			name:             "default format plus extra with stack trace enabled",
+			enableStackTrace: true,
+			format:           "%+v",
+			expected:         `^wrapped: password=\[REDACTED\](\n(\t)?[0-9a-zA-Z.\/_:-]+)+$`,
+		},
+		{
+			name:     "Go-syntax representation of the value",
+			format:   "%#v",
+			expected: `^\*xerrors\.withRedaction\{secrets:1, err:\(\*xerrors\.withWrap\)\(0x[a-f0-9]+\)\}$`,
+		},
+		{
+			name:     "string format",
+			format:   "%s",
+			expected: `^wrapped: password=\[REDACTED\]$`,
+		},
+		{
+			name:     "double-quoted string format",
+			format:   "%q",
+			expected: `^"wrapped: password=\[REDACTED\]"$`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			xerrors.EnableStackTrace(tc.enableStackTrace)
+			defer xerrors.EnableStackTrace(false)
+
+			err := xerrors.Redact(xerrors.Wrap(errors.New("password=secret"), "wrapped"), "secret")
+			got := fmt.Sprintf(tc.format, err)
+
+			re, err := regexp.Compile(tc.expected)
+			if err != nil {
+				t.Fatalf("invalid regex: %s", tc.expected)
+			}
+			if !re.MatchString(got) {
+				t.Errorf("expected pattern %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Level is the severity level of an error. Levels are ordered from the least to the most severe.
+type Level int
+
+// Enumeration of severity levels.
+const (
+	LevelInfo Level = iota + 1
+	LevelWarn
+	LevelError
+	LevelCritical
+)
+
+// String returns a string representation of the level.
+func (l Level) String() string {
+	switch l {
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	case LevelCritical:
+		return "CRITICAL"
+	default:
+		return "Level(" + strconv.Itoa(int(l)) + ")"
+	}
+}
+
+// WithSeverity annotates err with a severity level and a stack trace,
+// if enabled and err does not already contain one, at the point WithSeverity is called.
+// If err is nil, WithSeverity returns nil.
+func WithSeverity(err error, level Level) error {
+	if err == nil {
+		return nil
+	}
+
+	if !hasStackTrace(err) {
+		err = &withStack{
+			error: err,
+			stack: callers(),
+		}
+	}
+
+	return &withSeverity{
+		err:   err,
+		level: level,
+	}
+}
+
+// Severity returns the severity level of err:
+// 1) along a chain of errors, the level of the outermost error that has one, either via WithSeverity
+// or by implementing a method Severity() Level, prevails,
+// 2) errors grouping multiple errors (see Errors) have the maximum level of their errors,
+// 3) errors without any level are considered of level LevelError.
+//
+// If err is nil, Severity returns 0, which is lower than any level.
+func Severity(err error) Level {
+	if err == nil {
+		return 0
+	}
+
+	if s, ok := err.(interface{ Severity() Level }); ok {
+		return s.Severity()
+	}
+
+	if errs := Errors(err); errs != nil {
+		var level Level
+		for _, e := range errs {
+			level = max(level, Severity(e))
+		}
+		return level
+	}
+
+	if u := Unwrap(err); u != nil {
+		return Severity(u)
+	}
+
+	return LevelError
+}
+
+type withSeverity struct {
+	err   error
+	level Level
+}
+
+// Cause makes withSeverity implement the github.com/pkg/errors causer interface.
+func (e *withSeverity) Cause() error { return e.err }
+
+// Error makes withSeverity implement the error interface.
+func (e *withSeverity) Error() string { return e.err.Error() }
+
+// Format makes withSeverity implement the fmt.Formatter interface.
+func (e *withSeverity) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%+v", e.Unwrap())
+			return
+		}
+		if s.Flag('#') {
+			fmt.Fprintf(s, "%T{level:%s, err:(%T)(%p)}", e, e.level, e.err, &e.err)
+			return
+		}
+		fallthrough
+	case 's':
+		fmt.Fprint(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+// Severity returns the severity level attached to the error.
+func (e *withSeverity) Severity() Level { return e.level }
+
+// StackTrace makes withSeverity implement the StackTracer interface.
+func (e *withSeverity) StackTrace() StackTrace {
+	return stackTraceOf(e.err)
+}
+
+// Unwrap makes withSeverity implement the errors.Unwrapper interface.
+func (e *withSeverity) Unwrap() error { return e.err }
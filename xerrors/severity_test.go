@@ -0,0 +1,192 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors_test
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/jlourenc/xgo/xerrors"
+)
+
+func TestLevel_String(t *testing.T) {
+	testCases := []struct {
+		level    xerrors.Level
+		expected string
+	}{
+		{0, "Level(0)"},
+		{xerrors.LevelInfo, "INFO"},
+		{xerrors.LevelWarn, "WARN"},
+		{xerrors.LevelError, "ERROR"},
+		{xerrors.LevelCritical, "CRITICAL"},
+		{42, "Level(42)"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			if got := tc.level.String(); tc.expected != got {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestWithSeverity(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name: "nil",
+			err:  nil,
+		},
+		{
+			name:     "stack error",
+			err:      stackError{},
+			expected: "stack error",
+		},
+		{
+			name:     "unstack error",
+			err:      unstackError{},
+			expected: "unstack error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xerrors.WithSeverity(tc.err, xerrors.LevelWarn)
+
+			if tc.err == nil {
+				if got != nil {
+					t.Errorf("expected nil, got %#v", got)
+				}
+				return
+			}
+
+			if got.Error() != tc.expected || !errors.Is(got, tc.err) {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected xerrors.Level
+	}{
+		{
+			name:     "nil",
+			err:      nil,
+			expected: 0,
+		},
+		{
+			name:     "no level",
+			err:      errors.New("error"),
+			expected: xerrors.LevelError,
+		},
+		{
+			name:     "level",
+			err:      xerrors.WithSeverity(errors.New("error"), xerrors.LevelInfo),
+			expected: xerrors.LevelInfo,
+		},
+		{
+			name:     "outermost level prevails",
+			err:      xerrors.Wrap(xerrors.WithSeverity(xerrors.WithSeverity(errors.New("error"), xerrors.LevelCritical), xerrors.LevelWarn), "wrap"),
+			expected: xerrors.LevelWarn,
+		},
+		{
+			name: "maximum level of joined errors",
+			err: xerrors.Join(
+				xerrors.WithSeverity(errors.New("error"), xerrors.LevelInfo),
+				xerrors.WithSeverity(errors.New("error"), xerrors.LevelCritical),
+				xerrors.WithSeverity(errors.New("error"), xerrors.LevelWarn),
+			),
+			expected: xerrors.LevelCritical,
+		},
+		{
+			name: "maximum level of appended errors including error without level",
+			err: xerrors.Append(
+				xerrors.WithSeverity(errors.New("error"), xerrors.LevelInfo),
+				errors.New("error"),
+			),
+			expected: xerrors.LevelError,
+		},
+		{
+			name: "level of group prevails",
+			err: xerrors.WithSeverity(xerrors.Join(
+				xerrors.WithSeverity(errors.New("error"), xerrors.LevelCritical),
+			), xerrors.LevelInfo),
+			expected: xerrors.LevelInfo,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xerrors.Severity(tc.err)
+
+			if tc.expected != got {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestWithSeverity_Format(t *testing.T) {
+	testCases := []struct {
+		name             string
+		enableStackTrace bool
+		format           string
+		expected         string
+	}{
+		{
+			name:     "default format",
+			format:   "%v",
+			expected: `^error message$`,
+		},
+		{
+			name:             "default format plus extra with stack trace enabled",
+			enableStackTrace: true,
+			format:           "%+v",
+			expected:         `^error message(\n(\t)?[0-9a-zA-Z.\/_:-]+)+$`,
+		},
+		{
+			name:     "Go-syntax representation of the value",
+			format:   "%#v",
+			expected: `^\*xerrors\.withSeverity\{level:WARN, err:\(\*xerrors\.withStack\)\(0x[a-f0-9]+\)\}$`,
+		},
+		{
+			name:     "string format",
+			format:   "%s",
+			expected: `^error message$`,
+		},
+		{
+			name:     "double-quoted string format",
+			format:   "%q",
+			expected: `^"error message"$`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			xerrors.EnableStackTrace(tc.enableStackTrace)
+			defer xerrors.EnableStackTrace(false)
+
+			got := fmt.Sprintf(tc.format, xerrors.WithSeverity(errors.New("error message"), xerrors.LevelWarn))
+
+			re, err := regexp.Compile(tc.expected)
+			if err != nil {
+				t.Fatalf("invalid regex: %s", tc.expected)
+			}
+			if !re.MatchString(got) {
+				t.Errorf("expected pattern %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
@@ -29,7 +29,7 @@ func Append(err error, errs ...error) error {
 			continue
 		}
 
-		if _, ok := err.(StackTracer); !ok {
+		if !hasStackTrace(err) {
 			err = &withStack{
 				error: err,
 				stack: callers(),
@@ -51,7 +51,12 @@ type withSlice struct {
 }
 
 // Error makes withSlice implement the error interface.
+// The output is formatted by the function set with SetListFormatFunc, if any.
 func (e *withSlice) Error() string {
+	if listFormatFunc != nil {
+		return listFormatFunc(e.errs)
+	}
+
 	var sb strings.Builder
 
 	sb.WriteString(strconv.Itoa(len(e.errs)))
@@ -113,7 +118,7 @@ func (e *withSlice) Format(s fmt.State, verb rune) {
 
 // StackTrace makes withSlice implement the StackTracer interface.
 func (e *withSlice) StackTrace() StackTrace {
-	return e.errs[0].(StackTracer).StackTrace()
+	return stackTraceOf(e.errs[0])
 }
 
 // Unwrap makes withSlice implement the Unwrapper interface.
@@ -152,10 +157,7 @@ func (e chain) Is(target error) bool {
 
 // StackTrace makes chain implement the StackTracer interface.
 func (e chain) StackTrace() StackTrace {
-	if st, ok := e[0].(StackTracer); ok {
-		return st.StackTrace()
-	}
-	return nil
+	return stackTraceOf(e[0])
 }
 
 // Unwrap makes chain implement the Unwrapper interface.
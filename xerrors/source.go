@@ -0,0 +1,86 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// SourceReadFunc reads the source file at the given path, as reported by the stack frames.
+type SourceReadFunc func(file string) ([]byte, error)
+
+// WriteSource writes a developer-friendly rendering of st to w: each Frame is printed
+// as with the %+v verb, followed by the lines of source code around the frame's line,
+// within a window of n lines before and after it. The frame's line is marked with '>'.
+//
+//	main.parse
+//		/src/main.go:12
+//		   11 |	if len(s) == 0 {
+//		>  12 |		return xerrors.New("empty input")
+//		   13 |	}
+//
+// Source files are read with read, which allows for instance using source files embedded
+// in the binary; os.ReadFile is used if read is nil. Frames whose source code cannot be read
+// are printed without any snippet. WriteSource is meant for development builds only.
+func (st StackTrace) WriteSource(w io.Writer, n int, read SourceReadFunc) error {
+	if read == nil {
+		read = os.ReadFile
+	}
+
+	sources := make(map[string][][]byte)
+
+	for i, f := range st {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%+v\n", f); err != nil {
+			return err
+		}
+
+		file := f.file()
+		lines, ok := sources[file]
+		if !ok {
+			if b, err := read(file); err == nil {
+				lines = bytes.Split(b, []byte("\n"))
+			}
+			sources[file] = lines
+		}
+
+		if err := writeSnippet(w, lines, f.line(), n); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeSnippet writes lines within [line-n, line+n], line being 1-based.
+func writeSnippet(w io.Writer, lines [][]byte, line, n int) error {
+	if line < 1 || line > len(lines) {
+		return nil
+	}
+
+	first := max(line-n, 1)
+	last := min(line+n, len(lines))
+	width := len(strconv.Itoa(last))
+
+	for i := first; i <= last; i++ {
+		marker := ' '
+		if i == line {
+			marker = '>'
+		}
+		if _, err := fmt.Fprintf(w, "\t%c %*d |%s\n", marker, width, i, bytes.TrimRight(lines[i-1], "\r")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
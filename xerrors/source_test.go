@@ -0,0 +1,80 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors_test
+
+import (
+	"errors"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/jlourenc/xgo/xerrors"
+)
+
+func TestStackTrace_WriteSource(t *testing.T) {
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:]) // source marker
+	st := xerrors.StackTrace{xerrors.Frame(pcs[0])}
+
+	testCases := []struct {
+		name     string
+		st       xerrors.StackTrace
+		n        int
+		read     xerrors.SourceReadFunc
+		expected string
+	}{
+		{
+			name:     "empty stack trace",
+			st:       nil,
+			expected: `^$`,
+		},
+		{
+			name:     "source line only",
+			st:       st,
+			n:        0,
+			expected: `^.*\.TestStackTrace_WriteSource\n\t.*source_test\.go:\d+\n\t> \d+ \|\truntime\.Callers\(1, pcs\[:\]\) // source marker\n$`,
+		},
+		{
+			name:     "source window",
+			st:       st,
+			n:        1,
+			expected: `^.*\.TestStackTrace_WriteSource\n\t.*:\d+\n\t  \d+ \|\tvar pcs \[1\]uintptr\n\t> \d+ \|\truntime\.Callers.*\n\t  \d+ \|\tst := .*\n$`,
+		},
+		{
+			name: "custom source reader",
+			st:   st,
+			n:    100,
+			read: func(string) ([]byte, error) {
+				return []byte(strings.Repeat("line\n", 10)), nil
+			},
+			expected: `^.*\.TestStackTrace_WriteSource\n\t.*:\d+\n$`,
+		},
+		{
+			name: "unreadable source",
+			st:   append(st, st...),
+			n:    1,
+			read: func(string) ([]byte, error) {
+				return nil, errors.New("no source")
+			},
+			expected: `^.*\.TestStackTrace_WriteSource\n\t.*:\d+\n\n.*\.TestStackTrace_WriteSource\n\t.*:\d+\n$`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+
+			if err := tc.st.WriteSource(&sb, tc.n, tc.read); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			re := regexp.MustCompile(tc.expected)
+			if got := sb.String(); !re.MatchString(got) {
+				t.Errorf("expected pattern %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
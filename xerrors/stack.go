@@ -16,7 +16,7 @@ func WithStack(err error) error {
 		return nil
 	}
 
-	if _, ok := err.(StackTracer); ok {
+	if hasStackTrace(err) {
 		return err
 	}
 
@@ -31,6 +31,9 @@ type withStack struct {
 	stack
 }
 
+// Cause makes withStack implement the github.com/pkg/errors causer interface.
+func (e *withStack) Cause() error { return e.error }
+
 // Format makes withStack implement the fmt.Formatter interface.
 func (e *withStack) Format(s fmt.State, verb rune) {
 	switch verb {
@@ -141,22 +141,25 @@ type StackTrace []Frame
 // Format accepts flags that alter the printing of some verbs, as follows:
 //
 //	%+v   Prints filename, function, and line number for each Frame in the stack.
+//
+// Frames are excluded and limited according to ExcludeStackFrames and LimitStackFrames,
+// except with the %#v verb.
 func (st StackTrace) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		switch {
 		case s.Flag('+'):
-			for _, f := range st {
+			for _, f := range st.formatted() {
 				fmt.Fprint(s, "\n")
 				f.Format(s, verb)
 			}
 		case s.Flag('#'):
 			fmt.Fprintf(s, "%#v", []Frame(st))
 		default:
-			st.formatSlice(s, verb)
+			st.formatted().formatSlice(s, verb)
 		}
 	case 's':
-		st.formatSlice(s, verb)
+		st.formatted().formatSlice(s, verb)
 	}
 }
 
@@ -189,8 +192,8 @@ type StackTracer interface {
 // Format implements the fmt.Formatter interface.
 func (s stack) Format(st fmt.State, verb rune) {
 	if verb == 'v' && st.Flag('+') {
-		for _, pc := range s {
-			fmt.Fprintf(st, "\n%+v", Frame(pc))
+		for _, f := range s.StackTrace().formatted() {
+			fmt.Fprintf(st, "\n%+v", f)
 		}
 		return
 	}
@@ -0,0 +1,74 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors
+
+import (
+	"strings"
+)
+
+var (
+	excludedFramePrefixes []string
+	firstFramesLimit      int
+	lastFramesLimit       int
+)
+
+// ExcludeStackFrames permits excluding programmatically, when formatting stack traces, the frames
+// whose fully-qualified function name starts with any of prefixes, e.g. "runtime.", "testing." or
+// "github.com/some/vendor/". Calling it without any prefix disables the exclusion.
+// Stack traces returned by StackTrace methods are left intact.
+// It is NOT thread-safe.
+func ExcludeStackFrames(prefixes ...string) {
+	excludedFramePrefixes = prefixes
+}
+
+// LimitStackFrames permits limiting programmatically, when formatting stack traces, the frames
+// to the first (innermost) and last (outermost) ones, after any exclusion (see ExcludeStackFrames).
+// Frames in between are left out. Calling it with both values <= 0 disables the limit.
+// Stack traces returned by StackTrace methods are left intact.
+// It is NOT thread-safe.
+func LimitStackFrames(first, last int) {
+	firstFramesLimit = max(first, 0)
+	lastFramesLimit = max(last, 0)
+}
+
+// Trim returns a copy of st without the frames whose fully-qualified function name
+// starts with any of prefixes.
+func (st StackTrace) Trim(prefixes ...string) StackTrace {
+	if st == nil {
+		return nil
+	}
+
+	trimmed := make(StackTrace, 0, len(st))
+	for _, f := range st {
+		if !hasAnyPrefix(f.name(), prefixes) {
+			trimmed = append(trimmed, f)
+		}
+	}
+	return trimmed
+}
+
+// formatted returns the frames of st to format according to the package options.
+func (st StackTrace) formatted() StackTrace {
+	if len(excludedFramePrefixes) > 0 {
+		st = st.Trim(excludedFramePrefixes...)
+	}
+
+	if (firstFramesLimit > 0 || lastFramesLimit > 0) && len(st) > firstFramesLimit+lastFramesLimit {
+		limited := make(StackTrace, 0, firstFramesLimit+lastFramesLimit)
+		limited = append(limited, st[:firstFramesLimit]...)
+		st = append(limited, st[len(st)-lastFramesLimit:]...)
+	}
+
+	return st
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
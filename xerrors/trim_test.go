@@ -0,0 +1,156 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors_test
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/jlourenc/xgo/xerrors"
+)
+
+func callersStackTrace() xerrors.StackTrace {
+	var pcs [32]uintptr
+	n := runtime.Callers(1, pcs[:])
+	st := make(xerrors.StackTrace, n)
+	for i := range st {
+		st[i] = xerrors.Frame(pcs[i])
+	}
+	return st
+}
+
+func frameNames(st xerrors.StackTrace) []string {
+	names := make([]string, len(st))
+	for i, f := range st {
+		names[i] = fmt.Sprintf("%n", f)
+	}
+	return names
+}
+
+func TestStackTrace_Trim(t *testing.T) {
+	st := callersStackTrace()
+
+	testCases := []struct {
+		name     string
+		st       xerrors.StackTrace
+		prefixes []string
+		expected []string
+	}{
+		{
+			name:     "nil",
+			st:       nil,
+			prefixes: []string{"runtime."},
+			expected: []string{},
+		},
+		{
+			name:     "no prefix",
+			st:       st,
+			prefixes: nil,
+			expected: []string{"callersStackTrace", "TestStackTrace_Trim", "tRunner", "goexit"},
+		},
+		{
+			name:     "prefixes",
+			st:       st,
+			prefixes: []string{"runtime.", "testing."},
+			expected: []string{"callersStackTrace", "TestStackTrace_Trim"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := frameNames(tc.st.Trim(tc.prefixes...))
+
+			if strings.Join(tc.expected, ",") != strings.Join(got, ",") {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestExcludeStackFrames(t *testing.T) {
+	st := callersStackTrace()
+
+	testCases := []struct {
+		name          string
+		prefixes      []string
+		first, last   int
+		expectedCount int
+	}{
+		{
+			name:          "no exclusion nor limit",
+			expectedCount: 4,
+		},
+		{
+			name:          "exclusion",
+			prefixes:      []string{"runtime.", "testing."},
+			expectedCount: 2,
+		},
+		{
+			name:          "first frames",
+			first:         1,
+			expectedCount: 1,
+		},
+		{
+			name:          "first and last frames",
+			first:         1,
+			last:          2,
+			expectedCount: 3,
+		},
+		{
+			name:          "limit above frame count",
+			first:         10,
+			last:          10,
+			expectedCount: 4,
+		},
+		{
+			name:          "exclusion and limit",
+			prefixes:      []string{"runtime."},
+			last:          1,
+			expectedCount: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			xerrors.ExcludeStackFrames(tc.prefixes...)
+			xerrors.LimitStackFrames(tc.first, tc.last)
+			defer func() {
+				xerrors.ExcludeStackFrames()
+				xerrors.LimitStackFrames(0, 0)
+			}()
+
+			if got := strings.Count(fmt.Sprintf("%+v", st), "\n\t"); got != tc.expectedCount {
+				t.Errorf("expected %d formatted frames, got %d", tc.expectedCount, got)
+			}
+			if got := len(strings.Fields(strings.Trim(fmt.Sprintf("%v", st), "[]"))); got != tc.expectedCount {
+				t.Errorf("expected %d formatted frames, got %d", tc.expectedCount, got)
+			}
+			if got := len(st); got != 4 {
+				t.Errorf("expected raw stack trace to be intact, got %d frames", got)
+			}
+		})
+	}
+}
+
+func TestExcludeStackFrames_WithStack(t *testing.T) {
+	xerrors.EnableStackTrace(true)
+	xerrors.ExcludeStackFrames("runtime.", "testing.")
+	defer func() {
+		xerrors.EnableStackTrace(false)
+		xerrors.ExcludeStackFrames()
+	}()
+
+	err := xerrors.New("error")
+
+	got := fmt.Sprintf("%+v", err)
+	if strings.Contains(got, "testing.tRunner") || strings.Contains(got, "runtime.goexit") {
+		t.Errorf("expected excluded frames, got %s", got)
+	}
+	if n := len(err.(xerrors.StackTracer).StackTrace()); n != 3 {
+		t.Errorf("expected raw stack trace of 3 frames, got %d", n)
+	}
+}
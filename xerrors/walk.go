@@ -0,0 +1,86 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors
+
+// Errors returns the errors grouped by err, if err is an error grouping multiple errors,
+// such as the ones returned by Join, Append or Collector.Err, or any error implementing
+// a method Unwrap() []error. Otherwise, it returns nil.
+func Errors(err error) []error {
+	switch e := err.(type) {
+	case *withSlice:
+		return e.errs
+	case interface{ Unwrap() []error }:
+		return e.Unwrap()
+	default:
+		return nil
+	}
+}
+
+// Root returns the deepest error in err's chain, that is the first error
+// that does not wrap any other error. Errors wrapping multiple errors, such as
+// the ones returned by Join or Append, are followed through their first error.
+// If err is nil, Root returns nil.
+func Root(err error) error {
+	for err != nil {
+		next := unwrapFirst(err)
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+	return nil
+}
+
+// Walk traverses err's tree depth-first, calling fn for each error, starting with err itself.
+// The tree is made of errors obtained by repeatedly calling either Unwrap() error or
+// Unwrap() []error methods. If fn returns false, the traversal stops.
+// If err is nil, fn is never called.
+func Walk(err error, fn func(error) bool) {
+	walk(err, fn)
+}
+
+func walk(err error, fn func(error) bool) bool {
+	if err == nil {
+		return true
+	}
+
+	if !fn(err) {
+		return false
+	}
+
+	for _, child := range unwrapAll(err) {
+		if !walk(child, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// unwrapAll returns the errors directly wrapped by err.
+// withSlice is special-cased as its Unwrap method returns a flattened chain
+// for compatibility with errors.Is and errors.As.
+func unwrapAll(err error) []error {
+	switch e := err.(type) {
+	case *withSlice:
+		return e.errs
+	case interface{ Unwrap() []error }:
+		return e.Unwrap()
+	case interface{ Unwrap() error }:
+		if u := e.Unwrap(); u != nil {
+			return []error{u}
+		}
+	}
+	return nil
+}
+
+// unwrapFirst returns the first error directly wrapped by err, if any.
+func unwrapFirst(err error) error {
+	for _, u := range unwrapAll(err) {
+		if u != nil {
+			return u
+		}
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrors_test
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/jlourenc/xgo/xerrors"
+)
+
+func TestErrors(t *testing.T) {
+	err0 := xerrors.New("err0")
+	err1 := xerrors.New("err1")
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected []error
+	}{
+		{
+			name:     "nil",
+			err:      nil,
+			expected: nil,
+		},
+		{
+			name:     "non-group error",
+			err:      xerrors.Wrap(xerrors.Join(err0, err1), "wrap"),
+			expected: nil,
+		},
+		{
+			name:     "joined errors",
+			err:      xerrors.Join(err0, err1),
+			expected: []error{err0, err1},
+		},
+		{
+			name:     "appended errors",
+			err:      xerrors.Append(err0, err1),
+			expected: []error{err0, err1},
+		},
+		{
+			name:     "standard library joined errors",
+			err:      errors.Join(err0, err1),
+			expected: []error{err0, err1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xerrors.Errors(tc.err)
+
+			if !slices.Equal(tc.expected, got) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestRoot(t *testing.T) {
+	root := errors.New("root")
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{
+			name:     "nil",
+			err:      nil,
+			expected: nil,
+		},
+		{
+			name:     "non-wrapping error",
+			err:      root,
+			expected: root,
+		},
+		{
+			name:     "wrapped error",
+			err:      xerrors.Wrap(fmt.Errorf("fmt: %w", xerrors.WithStack(root)), "wrap"),
+			expected: root,
+		},
+		{
+			name:     "joined errors",
+			err:      xerrors.Wrap(xerrors.Join(xerrors.WithStack(root), errors.New("other")), "wrap"),
+			expected: root,
+		},
+		{
+			name:     "appended errors",
+			err:      xerrors.Append(xerrors.WithStack(root), errors.New("other")),
+			expected: root,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xerrors.Root(tc.err)
+
+			if tc.expected != got {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestWalk(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		stopAt   string
+		expected []string
+	}{
+		{
+			name:     "nil",
+			err:      nil,
+			expected: nil,
+		},
+		{
+			name:     "single error",
+			err:      errors.New("err0"),
+			expected: []string{"err0"},
+		},
+		{
+			name:     "wrapped error",
+			err:      fmt.Errorf("wrap: %w", errors.New("err0")),
+			expected: []string{"wrap: err0", "err0"},
+		},
+		{
+			name: "error tree",
+			err: fmt.Errorf("wrap: %w", errors.Join(
+				errors.New("err0"),
+				fmt.Errorf("err1: %w, %w", errors.New("err1.0"), errors.New("err1.1")),
+			)),
+			expected: []string{"wrap: err0\nerr1: err1.0, err1.1", "err0\nerr1: err1.0, err1.1", "err0", "err1: err1.0, err1.1", "err1.0", "err1.1"},
+		},
+		{
+			name: "appended errors",
+			err: xerrors.Append(
+				errors.New("err0"),
+				fmt.Errorf("wrap: %w", errors.New("err1")),
+			),
+			expected: []string{"2 errors occurred:\n\t* err0\n\t* wrap: err1\n", "err0", "err0", "wrap: err1", "wrap: err1", "err1"},
+		},
+		{
+			name: "stop traversal",
+			err: errors.Join(
+				errors.New("err0"),
+				errors.New("err1"),
+				errors.New("err2"),
+			),
+			stopAt:   "err1",
+			expected: []string{"err0\nerr1\nerr2", "err0", "err1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			xerrors.Walk(tc.err, func(err error) bool {
+				got = append(got, err.Error())
+				return err.Error() != tc.stopAt
+			})
+
+			if !slices.Equal(tc.expected, got) {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
@@ -31,6 +31,24 @@ func As(err error, target any) bool {
 	return errors.As(err, target)
 }
 
+// AsType finds the first error in err's chain that matches the type T, and if so,
+// returns that error value and true. Otherwise, it returns the zero value of T and false.
+//
+// It is the equivalent of As without the need of declaring a target variable:
+//
+//	if pathErr, ok := xerrors.AsType[*fs.PathError](err); ok {
+//		fmt.Println("Failed at path:", pathErr.Path)
+//	}
+//
+// See As for more information.
+func AsType[T error](err error) (T, bool) {
+	var target T
+	if errors.As(err, &target) {
+		return target, true
+	}
+	return target, false
+}
+
 // Is reports whether any error in err's chain matches target.
 //
 // The chain consists of err itself followed by the sequence of errors obtained by
@@ -69,7 +87,7 @@ func Wrap(err error, message string) error {
 		return nil
 	}
 
-	if _, ok := err.(StackTracer); !ok {
+	if !hasStackTrace(err) {
 		err = &withStack{
 			error: err,
 			stack: callers(),
@@ -90,7 +108,7 @@ func Wrapf(err error, format string, args ...any) error {
 		return nil
 	}
 
-	if _, ok := err.(StackTracer); !ok {
+	if !hasStackTrace(err) {
 		err = &withStack{
 			error: err,
 			stack: callers(),
@@ -103,11 +121,38 @@ func Wrapf(err error, format string, args ...any) error {
 	}
 }
 
+// WrapAs returns an error annotating err with the supplied message and a stack trace,
+// if enabled and err does not already contain one, at the point WrapAs is called,
+// only if an error in err's chain matches the type T (see AsType).
+// Otherwise, err is returned untouched. If err is nil, WrapAs returns nil.
+//
+// It permits annotating specific errors, e.g. *fs.PathError, while letting others bubble up as is.
+func WrapAs[T error](err error, message string) error {
+	if _, ok := AsType[T](err); !ok {
+		return err
+	}
+
+	if !hasStackTrace(err) {
+		err = &withStack{
+			error: err,
+			stack: callers(),
+		}
+	}
+
+	return &withWrap{
+		err: err,
+		msg: message,
+	}
+}
+
 type withWrap struct {
 	err error
 	msg string
 }
 
+// Cause makes withWrap implement the github.com/pkg/errors causer interface.
+func (e *withWrap) Cause() error { return e.err }
+
 // Error makes withWrap implement the error interface.
 func (e *withWrap) Error() string { return e.msg + ": " + e.err.Error() }
 
@@ -135,7 +180,7 @@ func (e *withWrap) Format(s fmt.State, verb rune) {
 
 // StackTrace makes withWrap implement the StackTracer interface.
 func (e *withWrap) StackTrace() StackTrace {
-	return e.err.(StackTracer).StackTrace()
+	return stackTraceOf(e.err)
 }
 
 // Unwrap makes withWrap implement the errors.Unwrapper interface.
@@ -46,6 +46,45 @@ func TestAs(t *testing.T) {
 	}
 }
 
+func TestAsType(t *testing.T) {
+	testCases := []struct {
+		name          string
+		err           error
+		expectedOK    bool
+		expectedValue *unstackError
+	}{
+		{
+			name:       "nil",
+			err:        nil,
+			expectedOK: false,
+		},
+		{
+			name:          "matches",
+			err:           xerrors.Wrap(fmt.Errorf("wrapped error: %w", &unstackError{}), "wrap"),
+			expectedOK:    true,
+			expectedValue: &unstackError{},
+		},
+		{
+			name:       "does not match",
+			err:        fmt.Errorf("wrapped error: %w", stackError{}),
+			expectedOK: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := xerrors.AsType[*unstackError](tc.err)
+
+			if tc.expectedOK != ok {
+				t.Errorf("expected %t, got %t", tc.expectedOK, ok)
+			}
+			if (tc.expectedValue == nil) != (got == nil) {
+				t.Errorf("expected %v, got %v", tc.expectedValue, got)
+			}
+		})
+	}
+}
+
 func TestIs(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -201,6 +240,47 @@ func TestWrapf(t *testing.T) {
 	}
 }
 
+func TestWrapAs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "nil",
+			err:      nil,
+			expected: "",
+		},
+		{
+			name:     "matches",
+			err:      fmt.Errorf("wrapped error: %w", unstackError{}),
+			expected: "wrap: wrapped error: unstack error",
+		},
+		{
+			name:     "does not match",
+			err:      fmt.Errorf("wrapped error: %w", stackError{}),
+			expected: "wrapped error: stack error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xerrors.WrapAs[unstackError](tc.err, "wrap")
+
+			if tc.err == nil {
+				if got != nil {
+					t.Errorf("expected nil, got %#v", got)
+				}
+				return
+			}
+
+			if got.Error() != tc.expected || !errors.Is(got, tc.err) {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
 func TestWithWrap_Error(t *testing.T) {
 	testCases := []struct {
 		name     string
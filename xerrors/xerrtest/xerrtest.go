@@ -0,0 +1,123 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package xerrtest provides assertion helpers to test errors,
+// reporting failures with a readable representation of error trees.
+package xerrtest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jlourenc/xgo/xerrors"
+)
+
+// AssertAs asserts that an error in err's tree matches the type T (see xerrors.AsType)
+// and returns it. It reports a failure otherwise.
+func AssertAs[T error](tb testing.TB, err error) T {
+	tb.Helper()
+
+	target, ok := xerrors.AsType[T](err)
+	if !ok {
+		tb.Errorf("expected an error of type %s in tree:\n%s", reflect.TypeOf((*T)(nil)).Elem(), Tree(err))
+	}
+	return target
+}
+
+// AssertCode asserts that the HTTP status code attached to err (see xerrors.HTTPStatus)
+// is equal to code. It reports a failure otherwise.
+func AssertCode(tb testing.TB, err error, code int) {
+	tb.Helper()
+
+	if got := xerrors.HTTPStatus(err); got != code {
+		tb.Errorf("expected HTTP status code %d, got %d in tree:\n%s", code, got, Tree(err))
+	}
+}
+
+// AssertContains asserts that err is not nil and its message contains substr.
+// It reports a failure otherwise.
+func AssertContains(tb testing.TB, err error, substr string) {
+	tb.Helper()
+
+	if err == nil {
+		tb.Errorf("expected an error containing %q, got nil", substr)
+		return
+	}
+	if !strings.Contains(err.Error(), substr) {
+		tb.Errorf("expected an error containing %q, got %q in tree:\n%s", substr, err.Error(), Tree(err))
+	}
+}
+
+// AssertGroupLen asserts that the first error of err's chain grouping multiple errors
+// (see xerrors.Errors) groups n errors. It reports a failure otherwise.
+func AssertGroupLen(tb testing.TB, err error, n int) {
+	tb.Helper()
+
+	if got := groupLen(err); got != n {
+		tb.Errorf("expected a group of %d errors, got %d in tree:\n%s", n, got, Tree(err))
+	}
+}
+
+// AssertIs asserts that an error in err's tree matches target (see xerrors.Is).
+// It reports a failure otherwise.
+func AssertIs(tb testing.TB, err, target error) {
+	tb.Helper()
+
+	if !xerrors.Is(err, target) {
+		tb.Errorf("expected %q in tree:\n%s", target, Tree(err))
+	}
+}
+
+// AssertNil asserts that err is nil. It reports a failure otherwise.
+func AssertNil(tb testing.TB, err error) {
+	tb.Helper()
+
+	if err != nil {
+		tb.Errorf("expected no error, got tree:\n%s", Tree(err))
+	}
+}
+
+// Tree returns a human-readable representation of err's tree (see xerrors.Walk),
+// listing each error with its type and message, indented by depth:
+//
+//	*xerrors.withWrap: "wrap: 2 errors occurred: ..."
+//	  *xerrors.joinError: "2 errors occurred: ..."
+//	    *errors.errorString: "err0"
+//	    *errors.errorString: "err1"
+func Tree(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+
+	var sb strings.Builder
+	writeTree(&sb, err, 0)
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func writeTree(sb *strings.Builder, err error, depth int) {
+	fmt.Fprintf(sb, "%s%T: %q\n", strings.Repeat("  ", depth), err, err.Error())
+
+	children := xerrors.Errors(err)
+	if children == nil {
+		if u := xerrors.Unwrap(err); u != nil {
+			children = []error{u}
+		}
+	}
+
+	for _, child := range children {
+		writeTree(sb, child, depth+1)
+	}
+}
+
+// groupLen returns the number of errors grouped by the first group of err's chain.
+func groupLen(err error) int {
+	for ; err != nil; err = xerrors.Unwrap(err) {
+		if errs := xerrors.Errors(err); errs != nil {
+			return len(errs)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,169 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xerrtest_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jlourenc/xgo/xerrors"
+	"github.com/jlourenc/xgo/xerrors/xerrtest"
+)
+
+type fakeTB struct {
+	testing.TB
+	msgs []string
+}
+
+func (*fakeTB) Helper() {}
+
+func (tb *fakeTB) Errorf(format string, args ...any) {
+	tb.msgs = append(tb.msgs, fmt.Sprintf(format, args...))
+}
+
+func TestAssertions(t *testing.T) {
+	_, pathErr := os.Open("non-existing")
+	err := xerrors.Wrap(xerrors.Join(
+		xerrors.WithHTTPStatus(pathErr, http.StatusNotFound),
+		errors.New("other"),
+	), "wrap")
+
+	testCases := []struct {
+		name            string
+		assert          func(tb testing.TB)
+		expectedFailure string
+	}{
+		{
+			name:   "as succeeds",
+			assert: func(tb testing.TB) { xerrtest.AssertAs[*fs.PathError](tb, err) },
+		},
+		{
+			name:            "as fails",
+			assert:          func(tb testing.TB) { xerrtest.AssertAs[*os.SyscallError](tb, err) },
+			expectedFailure: "expected an error of type *os.SyscallError in tree:\n*xerrors.withWrap:",
+		},
+		{
+			name:   "code succeeds",
+			assert: func(tb testing.TB) { xerrtest.AssertCode(tb, err, http.StatusNotFound) },
+		},
+		{
+			name:            "code fails",
+			assert:          func(tb testing.TB) { xerrtest.AssertCode(tb, err, http.StatusConflict) },
+			expectedFailure: "expected HTTP status code 409, got 404 in tree:",
+		},
+		{
+			name:   "contains succeeds",
+			assert: func(tb testing.TB) { xerrtest.AssertContains(tb, err, "non-existing") },
+		},
+		{
+			name:            "contains fails",
+			assert:          func(tb testing.TB) { xerrtest.AssertContains(tb, err, "unknown") },
+			expectedFailure: `expected an error containing "unknown", got "wrap: 2 errors occurred:`,
+		},
+		{
+			name:            "contains fails on nil",
+			assert:          func(tb testing.TB) { xerrtest.AssertContains(tb, nil, "unknown") },
+			expectedFailure: `expected an error containing "unknown", got nil`,
+		},
+		{
+			name:   "group length succeeds",
+			assert: func(tb testing.TB) { xerrtest.AssertGroupLen(tb, err, 2) },
+		},
+		{
+			name:   "group length of appended errors succeeds",
+			assert: func(tb testing.TB) { xerrtest.AssertGroupLen(tb, xerrors.Append(err, err, err), 3) },
+		},
+		{
+			name:            "group length fails",
+			assert:          func(tb testing.TB) { xerrtest.AssertGroupLen(tb, err, 3) },
+			expectedFailure: "expected a group of 3 errors, got 2 in tree:",
+		},
+		{
+			name:   "is succeeds",
+			assert: func(tb testing.TB) { xerrtest.AssertIs(tb, err, fs.ErrNotExist) },
+		},
+		{
+			name:            "is fails",
+			assert:          func(tb testing.TB) { xerrtest.AssertIs(tb, err, fs.ErrExist) },
+			expectedFailure: `expected "file already exists" in tree:`,
+		},
+		{
+			name:   "nil succeeds",
+			assert: func(tb testing.TB) { xerrtest.AssertNil(tb, nil) },
+		},
+		{
+			name:            "nil fails",
+			assert:          func(tb testing.TB) { xerrtest.AssertNil(tb, err) },
+			expectedFailure: "expected no error, got tree:",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tb := &fakeTB{}
+
+			tc.assert(tb)
+
+			switch {
+			case tc.expectedFailure == "" && len(tb.msgs) > 0:
+				t.Errorf("expected no failure, got %q", tb.msgs)
+			case tc.expectedFailure != "" && (len(tb.msgs) != 1 || !strings.HasPrefix(tb.msgs[0], tc.expectedFailure)):
+				t.Errorf("expected failure starting with %q, got %q", tc.expectedFailure, tb.msgs)
+			}
+		})
+	}
+}
+
+func TestTree(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "nil",
+			err:      nil,
+			expected: "<nil>",
+		},
+		{
+			name:     "single error",
+			err:      errors.New("err0"),
+			expected: `*errors.errorString: "err0"`,
+		},
+		{
+			name: "error tree",
+			err: fmt.Errorf("wrap: %w", xerrors.Append(
+				errors.New("err0"),
+				xerrors.Join(xerrors.New("err1.0"), xerrors.New("err1.1")),
+			)),
+			expected: strings.Join([]string{
+				`*fmt.wrapError: "wrap: 2 errors occurred:\n\t* err0\n\t* 2 errors occurred:\n\t\t* err1.0\n\t\t* err1.1\n"`,
+				`  *xerrors.withSlice: "2 errors occurred:\n\t* err0\n\t* 2 errors occurred:\n\t\t* err1.0\n\t\t* err1.1\n"`,
+				`    *xerrors.withStack: "err0"`,
+				`      *errors.errorString: "err0"`,
+				`    *xerrors.joinError: "2 errors occurred:\n\t* err1.0\n\t* err1.1\n"`,
+				`      *xerrors.withStack: "err1.0"`,
+				`        *errors.errorString: "err1.0"`,
+				`      *xerrors.withStack: "err1.1"`,
+				`        *errors.errorString: "err1.1"`,
+			}, "\n"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xerrtest.Tree(tc.err)
+
+			if tc.expected != got {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, got)
+			}
+		})
+	}
+}
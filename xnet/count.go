@@ -0,0 +1,129 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/jlourenc/xgo/xunit"
+)
+
+// ConnOp is an operation on a connection.
+type ConnOp string
+
+// Enumeration of connection operations.
+const (
+	ConnOpRead  ConnOp = "read"
+	ConnOpWrite ConnOp = "write"
+)
+
+// ConnOpInfo describes a completed operation on a CountingConn.
+type ConnOpInfo struct {
+	// Op is the operation.
+	Op ConnOp
+
+	// Bytes is the number of bytes read or written.
+	Bytes xunit.Byte
+
+	// Duration is the duration of the operation.
+	Duration time.Duration
+
+	// Err is the error returned by the operation, if any.
+	Err error
+}
+
+// CountingConn is a connection counting the bytes read and written through it.
+//
+// It is safe for concurrent use by multiple goroutines, as is a net.Conn.
+type CountingConn struct {
+	net.Conn
+
+	read    atomic.Int64
+	written atomic.Int64
+	onOp    func(info ConnOpInfo)
+}
+
+// CountConn returns a CountingConn wrapping c, configured with the options passed in input.
+func CountConn(c net.Conn, options ...CountConnOption) *CountingConn {
+	cc := &CountingConn{Conn: c}
+
+	for _, opt := range options {
+		opt.apply(cc)
+	}
+
+	return cc
+}
+
+// BytesRead returns the number of bytes read so far.
+func (c *CountingConn) BytesRead() xunit.Byte {
+	return xunit.Byte(c.read.Load())
+}
+
+// BytesWritten returns the number of bytes written so far.
+func (c *CountingConn) BytesWritten() xunit.Byte {
+	return xunit.Byte(c.written.Load())
+}
+
+// Read reads data from the connection, counting the bytes read.
+//
+// See net.Conn.Read for more information.
+func (c *CountingConn) Read(b []byte) (int, error) {
+	start := time.Now()
+	n, err := c.Conn.Read(b)
+	c.read.Add(int64(n))
+	c.done(ConnOpRead, start, n, err)
+	return n, err
+}
+
+// Write writes data to the connection, counting the bytes written.
+//
+// See net.Conn.Write for more information.
+func (c *CountingConn) Write(b []byte) (int, error) {
+	start := time.Now()
+	n, err := c.Conn.Write(b)
+	c.written.Add(int64(n))
+	c.done(ConnOpWrite, start, n, err)
+	return n, err
+}
+
+func (c *CountingConn) done(op ConnOp, start time.Time, n int, err error) {
+	if c.onOp != nil {
+		c.onOp(ConnOpInfo{Op: op, Bytes: xunit.Byte(n), Duration: time.Since(start), Err: err})
+	}
+}
+
+type (
+	// CountConnOption configures the CountingConn options when calling CountConn.
+	CountConnOption interface {
+		apply(c *CountingConn)
+	}
+
+	funcCountConnOption struct {
+		fn func(*CountingConn)
+	}
+)
+
+func newFuncCountConnOption(fn func(*CountingConn)) funcCountConnOption {
+	return funcCountConnOption{
+		fn: fn,
+	}
+}
+
+func (o funcCountConnOption) apply(c *CountingConn) {
+	o.fn(c)
+}
+
+// CountConnOnOp returns a CountConnOption that configures a function called after each Read and Write,
+// e.g. to record throughput metrics. It must not block.
+func CountConnOnOp(fn func(info ConnOpInfo)) CountConnOption {
+	if fn == nil {
+		panic("op function is nil")
+	}
+	return newFuncCountConnOption(func(c *CountingConn) {
+		c.onOp = fn
+	})
+}
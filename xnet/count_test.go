@@ -0,0 +1,74 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet_test
+
+import (
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+func TestDialCount(t *testing.T) {
+	ln, port, err := listenTCP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go serveEcho(ln)
+
+	var (
+		mu  sync.Mutex
+		ops = make(map[xnet.ConnOp]xunit.Byte)
+		cc  *xnet.CountingConn
+	)
+	c, err := xnet.Dial("tcp", "127.0.0.1:"+port, xnet.DialCount(
+		func(c *xnet.CountingConn) { cc = c },
+		xnet.CountConnOnOp(func(info xnet.ConnOpInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			ops[info.Op] += info.Bytes
+		}),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if cc == nil {
+		t.Fatal("expected the counting connection to be passed to onConn")
+	}
+
+	if _, err := io.WriteString(c, "hello world"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := io.ReadFull(c, make([]byte, 11)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cc.BytesWritten() != 11 {
+		t.Errorf("bytes written mismatch: expected 11; got %d", cc.BytesWritten())
+	}
+	if cc.BytesRead() != 11 {
+		t.Errorf("bytes read mismatch: expected 11; got %d", cc.BytesRead())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if ops[xnet.ConnOpWrite] != 11 || ops[xnet.ConnOpRead] != 11 {
+		t.Errorf("ops mismatch: expected 11 bytes read and written; got %v", ops)
+	}
+}
+
+func TestCountConnOnOp_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	xnet.CountConnOnOp(nil)
+}
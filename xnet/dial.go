@@ -6,9 +6,16 @@ package xnet
 
 import (
 	"context"
+	"crypto/tls"
 	"net"
+	"net/url"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
+
+	"github.com/jlourenc/xgo/xnet/xnettrace"
+	"github.com/jlourenc/xgo/xunit"
 )
 
 // Dialer is a wrapper around net.Dialer that provides additional options for connecting to an address.
@@ -34,6 +41,32 @@ type Dialer struct {
 	//
 	// The default is no timeout. (zero value)
 	WriteTimeout time.Duration
+	// HappyEyeballs enables Happy Eyeballs (RFC 8305) on TCP networks: the host is resolved
+	// to all its IPv6 and IPv4 addresses, which are interleaved, and connection attempts to them
+	// are raced, each one being started HappyEyeballsDelay after the previous one, or as soon as
+	// it fails. The first established connection is used and the others are closed.
+	//
+	// The default is disabled, in which case the fallback mechanism of net.Dialer is used. (zero value)
+	HappyEyeballs bool
+	// HappyEyeballsDelay is the delay between two connection attempts when HappyEyeballs is enabled.
+	//
+	// The default is 300ms. (zero value)
+	HappyEyeballsDelay time.Duration
+
+	count               func(c net.Conn) net.Conn
+	hosts               map[string][]string
+	idleTimeout         time.Duration
+	iface               string
+	keepAliveConfig     *keepAliveConfig
+	proxy               *url.URL
+	readRate            xunit.Byte
+	resolver            HostResolver
+	retryAttempts       int
+	retryBackoff        Backoff
+	tlsConfig           *tls.Config
+	tlsHandshakeTimeout time.Duration
+	tlsNextProtos       []string
+	writeRate           xunit.Byte
 }
 
 // Dial acts like net.Dial but uses a Dialer that supports read and write timeouts at the connection level.
@@ -72,13 +105,233 @@ func (d *Dialer) Dial(network, address string) (net.Conn, error) {
 //
 // See net.Dialer.DialContext for more information.
 func (d *Dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
-	c, err := d.Dialer.DialContext(ctx, network, address)
+	c, err := d.dialConn(ctx, network, address)
 	if err != nil {
 		return nil, err
 	}
 	return &conn{Conn: c, readTimeout: d.ReadTimeout, writeTimeout: d.WriteTimeout}, nil
 }
 
+// dialConn dials address and wraps the connection to cap its throughput, count its bytes
+// and apply its idle timeout, if configured.
+func (d *Dialer) dialConn(ctx context.Context, network, address string) (net.Conn, error) {
+	c, err := d.dialRetry(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	c = ThrottleConn(c, d.readRate, d.writeRate)
+	if d.count != nil {
+		c = d.count(c)
+	}
+	if d.idleTimeout > 0 {
+		c = IdleTimeoutConn(c, d.idleTimeout)
+	}
+	return c, nil
+}
+
+func (d *Dialer) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	if d.proxy != nil && strings.HasPrefix(network, NetworkTCP) {
+		return d.dialProxy(ctx, network, address)
+	}
+	return d.dialDirect(ctx, network, address)
+}
+
+// dialDirect dials address without going through the proxy, applying the host mapping
+// and the keep-alive config if any.
+func (d *Dialer) dialDirect(ctx context.Context, network, address string) (net.Conn, error) {
+	c, err := d.dialMapped(ctx, network, address)
+	if err != nil || d.keepAliveConfig == nil {
+		return c, err
+	}
+	if err := setKeepAliveConfig(c, *d.keepAliveConfig); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// dialMapped dials address, applying the host mapping if any.
+func (d *Dialer) dialMapped(ctx context.Context, network, address string) (net.Conn, error) {
+	addresses := d.mapAddress(network, address)
+	if len(addresses) == 1 {
+		return d.dialAddress(ctx, network, addresses[0])
+	}
+
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+
+	var firstErr error
+	for _, a := range addresses {
+		c, err := d.dialAddress(ctx, network, a)
+		if err == nil {
+			return c, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, firstErr
+}
+
+// mapAddress returns the addresses address is mapped to by the host mapping, or address itself.
+func (d *Dialer) mapAddress(network, address string) []string {
+	if len(d.hosts) == 0 || !isIPNetwork(network) {
+		return []string{address}
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return []string{address}
+	}
+
+	host = strings.ToLower(host)
+	targets, ok := d.hosts[net.JoinHostPort(host, port)]
+	if !ok {
+		targets = d.hosts[host]
+	}
+	if len(targets) == 0 {
+		return []string{address}
+	}
+
+	mapped := make([]string, len(targets))
+	for i, target := range targets {
+		if _, _, err := net.SplitHostPort(target); err == nil {
+			mapped[i] = target
+		} else {
+			mapped[i] = net.JoinHostPort(target, port)
+		}
+	}
+	return mapped
+}
+
+// dialAddress dials address, resolving its host itself if needed.
+func (d *Dialer) dialAddress(ctx context.Context, network, address string) (net.Conn, error) {
+	if !d.HappyEyeballs && d.resolver == nil {
+		return d.dialNet(ctx, network, address)
+	}
+
+	if !isIPNetwork(network) {
+		return d.dialNet(ctx, network, address)
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || net.ParseIP(host) != nil {
+		return d.dialNet(ctx, network, address)
+	}
+
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+
+	addrs, err := d.lookupIPAddrs(ctx, network, host)
+	if err != nil {
+		return nil, &net.OpError{Op: "dial", Net: network, Err: err}
+	}
+
+	if d.HappyEyeballs && strings.HasPrefix(network, NetworkTCP) {
+		return d.dialParallel(ctx, network, port, interleaveIPAddrs(addrs))
+	}
+	return d.dialSerial(ctx, network, port, addrs)
+}
+
+// withTimeout returns a copy of ctx bounded by the connect timeout and deadline, so that they apply
+// to the whole dial, resolution and multiple attempts included.
+func (d *Dialer) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	cancel := func() {}
+	if d.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, d.Timeout)
+	}
+	if !d.Deadline.IsZero() {
+		var cancelDeadline context.CancelFunc
+		ctx, cancelDeadline = context.WithDeadline(ctx, d.Deadline)
+		cancelTimeout := cancel
+		cancel = func() {
+			cancelDeadline()
+			cancelTimeout()
+		}
+	}
+	return ctx, cancel
+}
+
+// lookupIPAddrs resolves host to the IP addresses suitable for network.
+func (d *Dialer) lookupIPAddrs(ctx context.Context, network, host string) ([]net.IPAddr, error) {
+	var resolver HostResolver = net.DefaultResolver
+	switch {
+	case d.resolver != nil:
+		resolver = d.resolver
+	case d.Resolver != nil:
+		resolver = d.Resolver
+	}
+
+	trace := xnettrace.ContextDialTrace(ctx)
+	if trace != nil && trace.ResolveStart != nil {
+		trace.ResolveStart(xnettrace.ResolveStartInfo{Host: host})
+	}
+
+	start := time.Now()
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+
+	if trace != nil && trace.ResolveDone != nil {
+		trace.ResolveDone(xnettrace.ResolveDoneInfo{Host: host, Addrs: addrs, Err: err, Duration: time.Since(start)})
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var suitable []net.IPAddr
+	for _, addr := range addrs {
+		isIP4 := addr.IP.To4() != nil
+		if (strings.HasSuffix(network, "4") && !isIP4) || (strings.HasSuffix(network, "6") && isIP4) {
+			continue
+		}
+		suitable = append(suitable, addr)
+	}
+	if len(suitable) == 0 {
+		return nil, &net.AddrError{Err: "no suitable address found", Addr: host}
+	}
+
+	return suitable, nil
+}
+
+// dialSerial dials addrs in order and returns the first established connection,
+// or the error of the first attempt if they all fail.
+func (d *Dialer) dialSerial(ctx context.Context, network, port string, addrs []net.IPAddr) (net.Conn, error) {
+	nd, err := d.attemptDialer(network)
+	if err != nil {
+		return nil, err
+	}
+
+	var firstErr error
+	for _, addr := range addrs {
+		c, err := nd.DialContext(ctx, network, net.JoinHostPort(addr.String(), port))
+		if err == nil {
+			return c, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, firstErr
+}
+
+// attemptDialer returns the net.Dialer used to dial resolved addresses. The dial is bounded by
+// its context, attempts must not apply their own timeout.
+func (d *Dialer) attemptDialer(network string) (net.Dialer, error) {
+	nd, err := d.netDialer(network)
+	if err != nil {
+		return net.Dialer{}, err
+	}
+	nd.Timeout = 0
+	nd.Deadline = time.Time{}
+	return nd, nil
+}
+
 type (
 	// DialOption configures how connections are made.
 	DialOption interface {
@@ -114,7 +367,74 @@ func DialConnectTimeout(timeout time.Duration) DialOption {
 	})
 }
 
-// WithKeepAlive returns a DialOption that configures the interval
+// DialCount returns a DialOption that wraps connections with CountConn, configured with the options passed
+// in input, counting the bytes read and written at the network level, i.e. TLS records included.
+// onConn, if not nil, is called with each new connection, e.g. to read its counters once closed.
+func DialCount(onConn func(c *CountingConn), options ...CountConnOption) DialOption {
+	return newFuncDialOption(func(d *Dialer) {
+		d.count = func(c net.Conn) net.Conn {
+			cc := CountConn(c, options...)
+			if onConn != nil {
+				onConn(cc)
+			}
+			return cc
+		}
+	})
+}
+
+// DialHappyEyeballs returns a DialOption that enables Happy Eyeballs (RFC 8305) on TCP networks,
+// racing connection attempts to the IPv6 and IPv4 addresses of the host, each one being started
+// delay after the previous one. If delay <= 0, the standard 300ms delay is used.
+func DialHappyEyeballs(delay time.Duration) DialOption {
+	return newFuncDialOption(func(d *Dialer) {
+		d.HappyEyeballs = true
+		d.HappyEyeballsDelay = max(0, delay)
+	})
+}
+
+// DialHostMapping returns a DialOption that pins hostnames to specific addresses, e.g. in tests or canary
+// deployments, without editing /etc/hosts. Keys are either hostnames, or host:port pairs taking precedence
+// for a given port, matched case-insensitively. Values are the addresses to dial instead, in order until
+// one succeeds, either hosts (IP addresses or hostnames) keeping the dialed port, or host:port pairs.
+func DialHostMapping(hosts map[string][]string) DialOption {
+	mapping := make(map[string][]string, len(hosts))
+	for host, addresses := range hosts {
+		mapping[strings.ToLower(host)] = slices.Clone(addresses)
+	}
+	return newFuncDialOption(func(d *Dialer) {
+		d.hosts = mapping
+	})
+}
+
+// DialIdleTimeout returns a DialOption that configures a timeout after which a connection is closed when no Read
+// or Write has started or completed, unlike the read and write timeouts which apply to each operation.
+// Value must be >= 0, otherwise it panics. A zero value means no timeout.
+//
+// See IdleTimeoutConn for more information.
+func DialIdleTimeout(timeout time.Duration) DialOption {
+	if timeout < 0 {
+		panic("invalid idle timeout value")
+	}
+	return newFuncDialOption(func(d *Dialer) {
+		d.idleTimeout = timeout
+	})
+}
+
+// DialInterface returns a DialOption that binds TCP and UDP connections to the network interface name,
+// e.g. "eth1", for source-based routing on multi-homed hosts. On Linux, sockets are bound to the interface
+// with SO_BINDTODEVICE, which may require the CAP_NET_RAW capability. On other systems, they are bound
+// to the first address of the interface suitable for the network, IPv4 ones first, taking precedence over
+// the local address. It panics if name is empty.
+func DialInterface(name string) DialOption {
+	if name == "" {
+		panic("invalid interface name value")
+	}
+	return newFuncDialOption(func(d *Dialer) {
+		d.iface = name
+	})
+}
+
+// DialKeepAlive returns a DialOption that configures the interval
 // between keep-alive probes for an active network TCP connection.
 func DialKeepAlive(keepAlive time.Duration) DialOption {
 	return newFuncDialOption(func(d *Dialer) {
@@ -122,6 +442,61 @@ func DialKeepAlive(keepAlive time.Duration) DialOption {
 	})
 }
 
+// DialKeepAliveConfig returns a DialOption that configures the keep-alive probes of TCP connections:
+// idle is the time a connection must be idle before the first probe is sent, interval the time between
+// two probes and count the number of unanswered probes after which the connection is dropped.
+// A zero value leaves the system default. Values must be >= 0, otherwise it panics. Durations are
+// rounded up to the second.
+//
+// Interval and count are supported on Linux and Darwin only. On other systems, only idle is applied,
+// as with DialKeepAlive.
+func DialKeepAliveConfig(idle, interval time.Duration, count int) DialOption {
+	if idle < 0 {
+		panic("invalid keep alive idle value")
+	}
+	if interval < 0 {
+		panic("invalid keep alive interval value")
+	}
+	if count < 0 {
+		panic("invalid keep alive count value")
+	}
+	return newFuncDialOption(func(d *Dialer) {
+		d.keepAliveConfig = &keepAliveConfig{idle: idle, interval: interval, count: count}
+	})
+}
+
+// DialLocalAddr returns a DialOption that configures the local address to use when dialing, e.g. to select
+// the source IP on multi-homed hosts. Its type must match the network, e.g. *net.TCPAddr for TCP networks.
+// It panics if addr is nil.
+func DialLocalAddr(addr net.Addr) DialOption {
+	if addr == nil {
+		panic("local address is nil")
+	}
+	return newFuncDialOption(func(d *Dialer) {
+		d.LocalAddr = addr
+	})
+}
+
+// DialProxy returns a DialOption that configures a proxy through which TCP connections are made:
+// a SOCKS5 proxy (socks5:// or socks5h:// URL, the target hostname being resolved by the proxy in both cases)
+// or an HTTP proxy using the CONNECT method (http:// or https:// URL). Credentials of the URL, if any, are
+// used to authenticate to the proxy. It panics if proxyURL is nil or has another scheme.
+//
+// Read and write timeouts apply to the proxied connection as to direct ones.
+func DialProxy(proxyURL *url.URL) DialOption {
+	if proxyURL == nil {
+		panic("proxy url is nil")
+	}
+	switch proxyURL.Scheme {
+	case proxySchemeHTTP, proxySchemeHTTPS, proxySchemeSOCKS5, proxySchemeSOCKS5H:
+	default:
+		panic("invalid proxy scheme value")
+	}
+	return newFuncDialOption(func(d *Dialer) {
+		d.proxy = proxyURL
+	})
+}
+
 // DialReadTimeout returns a DialOption that configures a timeout for a Conn Read to complete.
 func DialReadTimeout(timeout time.Duration) DialOption {
 	return newFuncDialOption(func(d *Dialer) {
@@ -129,6 +504,81 @@ func DialReadTimeout(timeout time.Duration) DialOption {
 	})
 }
 
+// DialResolver returns a DialOption that configures the resolver used to resolve hostnames, e.g. a caching
+// Resolver or a *net.Resolver. When set, the Dialer resolves hostnames itself and dials the resulting
+// addresses in order, until one succeeds.
+func DialResolver(resolver HostResolver) DialOption {
+	if resolver == nil {
+		panic("resolver is nil")
+	}
+	return newFuncDialOption(func(d *Dialer) {
+		d.resolver = resolver
+	})
+}
+
+// DialRetry returns a DialOption that configures connection refusals, as returned while a server restarts,
+// to be retried up to a total of attempts, waiting between attempts following backoff until the context is done.
+// attempts must be >= 1 and backoff must not be nil, otherwise it panics.
+func DialRetry(attempts int, backoff Backoff) DialOption {
+	if attempts < 1 {
+		panic("invalid attempts value")
+	}
+	if backoff == nil {
+		panic("backoff is nil")
+	}
+	return newFuncDialOption(func(d *Dialer) {
+		d.retryAttempts = attempts
+		d.retryBackoff = backoff
+	})
+}
+
+// DialThrottle returns a DialOption that caps the throughput of connections to readRate and writeRate
+// bytes per second. A zero rate means no limit. Values must be >= 0, otherwise it panics.
+//
+// See ThrottleConn for more information.
+func DialThrottle(readRate, writeRate xunit.Byte) DialOption {
+	if readRate < 0 {
+		panic("invalid read rate value")
+	}
+	if writeRate < 0 {
+		panic("invalid write rate value")
+	}
+	return newFuncDialOption(func(d *Dialer) {
+		d.readRate = readRate
+		d.writeRate = writeRate
+	})
+}
+
+// DialTLSConfig returns a DialOption that configures the TLS config used by DialTLS and DialTLSContext.
+// The config is cloned before each handshake.
+func DialTLSConfig(config *tls.Config) DialOption {
+	if config == nil {
+		panic("tls config is nil")
+	}
+	return newFuncDialOption(func(d *Dialer) {
+		d.tlsConfig = config
+	})
+}
+
+// DialTLSHandshakeTimeout returns a DialOption that configures a timeout for a TLS handshake to complete,
+// in addition to the connect timeout. Value must be >= 0, otherwise it panics.
+func DialTLSHandshakeTimeout(timeout time.Duration) DialOption {
+	if timeout < 0 {
+		panic("invalid tls handshake timeout value")
+	}
+	return newFuncDialOption(func(d *Dialer) {
+		d.tlsHandshakeTimeout = timeout
+	})
+}
+
+// DialTLSNextProtos returns a DialOption that configures the application protocols offered
+// through ALPN during a TLS handshake, e.g. "h2" or "http/1.1", overriding those of the TLS config.
+func DialTLSNextProtos(protos ...string) DialOption {
+	return newFuncDialOption(func(d *Dialer) {
+		d.tlsNextProtos = protos
+	})
+}
+
 // DialWriteTimeout returns a DialOption that configures a timeout for a Conn Write to complete.
 func DialWriteTimeout(timeout time.Duration) DialOption {
 	return newFuncDialOption(func(d *Dialer) {
@@ -170,3 +620,22 @@ func ListenConfigKeepAlive(keepAlive time.Duration) ListenConfigOption {
 		lc.KeepAlive = keepAlive
 	})
 }
+
+// ListenConfigReuseAddr returns a ListenConfigOption that sets the SO_REUSEADDR socket option, allowing to bind
+// an address whose previous sockets are still in the TIME_WAIT state, e.g. on a restart. It is chained with the
+// control configured for the net.ListenConfig so far. Listening fails on systems other than Unix ones.
+func ListenConfigReuseAddr() ListenConfigOption {
+	return newFuncListenConfigOption(func(lc *net.ListenConfig) {
+		lc.Control = chainControl(lc.Control, sockoptControl(setReuseAddr))
+	})
+}
+
+// ListenConfigReusePort returns a ListenConfigOption that sets the SO_REUSEPORT socket option, allowing several
+// sockets, possibly of several processes, to bind the same address, e.g. for zero-downtime restarts or to shard
+// accepts. It is chained with the control configured for the net.ListenConfig so far. Listening fails on systems
+// not supporting it, i.e. other than Linux and BSD ones.
+func ListenConfigReusePort() ListenConfigOption {
+	return newFuncListenConfigOption(func(lc *net.ListenConfig) {
+		lc.Control = chainControl(lc.Control, sockoptControl(setReusePort))
+	})
+}
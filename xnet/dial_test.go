@@ -124,3 +124,148 @@ func TestDialContext(t *testing.T) {
 		})
 	}
 }
+
+func TestDialer_DialContext_HappyEyeballs(t *testing.T) {
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		name        string
+		ctx         context.Context
+		network     string
+		host        string
+		closed      bool
+		expectedErr bool
+	}{
+		{
+			name:        "hostname",
+			ctx:         context.Background(),
+			network:     xnet.NetworkTCP,
+			host:        "localhost",
+			expectedErr: false,
+		},
+		{
+			name:        "ip address",
+			ctx:         context.Background(),
+			network:     xnet.NetworkTCP4,
+			host:        "127.0.0.1",
+			expectedErr: false,
+		},
+		{
+			name:        "connection refused",
+			ctx:         context.Background(),
+			network:     xnet.NetworkTCP,
+			host:        "localhost",
+			closed:      true,
+			expectedErr: true,
+		},
+		{
+			name:        "canceled context",
+			ctx:         canceledCtx,
+			network:     xnet.NetworkTCP,
+			host:        "localhost",
+			expectedErr: true,
+		},
+		{
+			name:        "unknown host",
+			ctx:         context.Background(),
+			network:     xnet.NetworkTCP,
+			host:        "unknown.invalid",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ln, port, err := listenTCP()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ln.Close()
+			if tc.closed {
+				ln.Close()
+			}
+
+			conn, err := xnet.DialContext(tc.ctx, tc.network, net.JoinHostPort(tc.host, port),
+				xnet.DialHappyEyeballs(0), xnet.DialConnectTimeout(5*time.Second))
+			if conn != nil {
+				defer conn.Close()
+			}
+
+			assertDial(t, tc.expectedErr, conn, err)
+		})
+	}
+}
+
+func TestDialHostMapping(t *testing.T) {
+	ln, port, err := listenTCP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	closedLn, closedPort, err := listenTCP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedLn.Close()
+
+	testCases := []struct {
+		name        string
+		hosts       map[string][]string
+		address     string
+		expectedErr bool
+	}{
+		{
+			name:        "host mapped to ip",
+			hosts:       map[string][]string{"Example.test": {"127.0.0.1"}},
+			address:     net.JoinHostPort("example.test", port),
+			expectedErr: false,
+		},
+		{
+			name:        "host and port mapped to address",
+			hosts:       map[string][]string{"example.test:80": {net.JoinHostPort("127.0.0.1", port)}},
+			address:     "example.test:80",
+			expectedErr: false,
+		},
+		{
+			name:        "host mapped to hostname",
+			hosts:       map[string][]string{"example.test": {"localhost"}},
+			address:     net.JoinHostPort("example.test", port),
+			expectedErr: false,
+		},
+		{
+			name: "first address refused",
+			hosts: map[string][]string{"example.test": {
+				net.JoinHostPort("127.0.0.1", closedPort),
+				net.JoinHostPort("127.0.0.1", port),
+			}},
+			address:     "example.test:80",
+			expectedErr: false,
+		},
+		{
+			name:        "all addresses refused",
+			hosts:       map[string][]string{"example.test": {net.JoinHostPort("127.0.0.1", closedPort)}},
+			address:     "example.test:80",
+			expectedErr: true,
+		},
+		{
+			name:        "unmapped host",
+			hosts:       map[string][]string{"example.test": {"127.0.0.1"}},
+			address:     net.JoinHostPort("unknown.invalid", port),
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conn, err := xnet.DialContext(context.Background(), xnet.NetworkTCP, tc.address,
+				xnet.DialHostMapping(tc.hosts), xnet.DialConnectTimeout(5*time.Second))
+			if conn != nil {
+				defer conn.Close()
+			}
+
+			assertDial(t, tc.expectedErr, conn, err)
+		})
+	}
+}
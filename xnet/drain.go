@@ -0,0 +1,130 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+const drainListenerPollInterval = 10 * time.Millisecond
+
+// DrainListener is a listener tracking the connections it accepted so that they can be drained on shutdown,
+// the way http.Server.Shutdown works for HTTP, e.g. under a raw TCP server.
+//
+// It is safe for concurrent use by multiple goroutines.
+type DrainListener struct {
+	net.Listener
+
+	mu     sync.Mutex
+	conns  map[*drainConn]struct{}
+	closed bool
+}
+
+// NewDrainListener returns a DrainListener wrapping l.
+func NewDrainListener(l net.Listener) *DrainListener {
+	if l == nil {
+		panic("listener is nil")
+	}
+	return &DrainListener{
+		Listener: l,
+		conns:    make(map[*drainConn]struct{}),
+	}
+}
+
+// Accept waits for and returns the next connection to the listener, tracked until it is closed.
+// It fails with net.ErrClosed once Shutdown has been called.
+//
+// See net.Listener.Accept for more information.
+func (l *DrainListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		c.Close()
+		return nil, net.ErrClosed
+	}
+
+	dc := &drainConn{Conn: c, l: l}
+	l.conns[dc] = struct{}{}
+	return dc, nil
+}
+
+// ActiveConns returns the number of accepted connections which are not closed yet.
+func (l *DrainListener) ActiveConns() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.conns)
+}
+
+// Shutdown closes the listener, so that no more connections are accepted, and waits for the accepted
+// connections to be closed by their handlers. Once ctx is done, the remaining connections are closed
+// and the error of ctx is returned.
+func (l *DrainListener) Shutdown(ctx context.Context) error {
+	l.mu.Lock()
+	l.closed = true
+	l.mu.Unlock()
+
+	err := l.Listener.Close()
+	if errors.Is(err, net.ErrClosed) {
+		err = nil
+	}
+
+	ticker := time.NewTicker(drainListenerPollInterval)
+	defer ticker.Stop()
+
+	for l.ActiveConns() > 0 {
+		select {
+		case <-ctx.Done():
+			l.closeConns()
+			return errors.Join(err, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+	return err
+}
+
+func (l *DrainListener) closeConns() {
+	l.mu.Lock()
+	conns := make([]*drainConn, 0, len(l.conns))
+	for c := range l.conns {
+		conns = append(conns, c)
+	}
+	l.mu.Unlock()
+
+	for _, c := range conns {
+		c.Close()
+	}
+}
+
+type drainConn struct {
+	net.Conn
+	l    *DrainListener
+	once sync.Once
+	err  error
+}
+
+// Close closes the connection and stops tracking it.
+//
+// See net.Conn.Close for more information.
+func (c *drainConn) Close() error {
+	c.once.Do(func() {
+		c.err = c.Conn.Close()
+
+		c.l.mu.Lock()
+		delete(c.l.conns, c)
+		c.l.mu.Unlock()
+	})
+	return c.err
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet"
+)
+
+func TestDrainListener_Shutdown(t *testing.T) {
+	testCases := []struct {
+		name        string
+		handlerTime time.Duration
+		timeout     time.Duration
+		expectedErr error
+	}{
+		{
+			name:        "connections drained",
+			handlerTime: 20 * time.Millisecond,
+			timeout:     time.Second,
+		},
+		{
+			name:        "connections closed on timeout",
+			handlerTime: time.Minute,
+			timeout:     20 * time.Millisecond,
+			expectedErr: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			dl := xnet.NewDrainListener(ln)
+
+			client, err := net.Dial("tcp", ln.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+
+			c, err := dl.Accept()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := dl.ActiveConns(); n != 1 {
+				t.Errorf("active conns mismatch: expected 1; got %d", n)
+			}
+
+			// The handler closes the connection once done.
+			timer := time.AfterFunc(tc.handlerTime, func() { c.Close() })
+			defer timer.Stop()
+
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+
+			if err := dl.Shutdown(ctx); !errors.Is(err, tc.expectedErr) {
+				t.Errorf("error mismatch: expected %v; got %v", tc.expectedErr, err)
+			}
+			if n := dl.ActiveConns(); n != 0 {
+				t.Errorf("active conns mismatch: expected 0; got %d", n)
+			}
+
+			// The connection is closed on the server side either way.
+			client.SetReadDeadline(time.Now().Add(time.Second))
+			if _, err := client.Read(make([]byte, 1)); !errors.Is(err, io.EOF) {
+				t.Errorf("error mismatch: expected %v; got %v", io.EOF, err)
+			}
+
+			if _, err := dl.Accept(); err == nil {
+				t.Error("expected an error accepting after shutdown")
+			}
+		})
+	}
+}
+
+func TestNewDrainListener_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	xnet.NewDrainListener(nil)
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+const happyEyeballsDefaultDelay = 300 * time.Millisecond
+
+type dialResult struct {
+	conn net.Conn
+	err  error
+}
+
+// interleaveIPAddrs returns addrs with IPv6 and IPv4 addresses interleaved, starting with
+// the family of the first address, as recommended by Section 4 of RFC 8305.
+func interleaveIPAddrs(addrs []net.IPAddr) []net.IPAddr {
+	var primaries, fallbacks []net.IPAddr
+	isPrimary := func(addr net.IPAddr) bool {
+		return (addr.IP.To4() != nil) == (addrs[0].IP.To4() != nil)
+	}
+	for _, addr := range addrs {
+		if isPrimary(addr) {
+			primaries = append(primaries, addr)
+		} else {
+			fallbacks = append(fallbacks, addr)
+		}
+	}
+
+	interleaved := make([]net.IPAddr, 0, len(addrs))
+	for i := 0; i < max(len(primaries), len(fallbacks)); i++ {
+		if i < len(primaries) {
+			interleaved = append(interleaved, primaries[i])
+		}
+		if i < len(fallbacks) {
+			interleaved = append(interleaved, fallbacks[i])
+		}
+	}
+	return interleaved
+}
+
+// dialParallel races connection attempts to addrs, in order, each one being started after
+// the Happy Eyeballs delay or as soon as the previous one fails. It returns the first established
+// connection, or the error of the first attempt if they all fail.
+func (d *Dialer) dialParallel(ctx context.Context, network, port string, addrs []net.IPAddr) (net.Conn, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	delay := d.HappyEyeballsDelay
+	if delay <= 0 {
+		delay = happyEyeballsDefaultDelay
+	}
+
+	nd, err := d.attemptDialer(network)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(chan dialResult, len(addrs))
+	next, pending := 0, 0
+	start := func() {
+		address := net.JoinHostPort(addrs[next].String(), port)
+		next++
+		pending++
+		go func() {
+			c, err := nd.DialContext(ctx, network, address)
+			results <- dialResult{conn: c, err: err}
+		}()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	resetTimer := func() {
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(delay)
+	}
+
+	start()
+
+	var firstErr error
+	for pending > 0 {
+		select {
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				// Close the connections established by the attempts still pending.
+				go func(pending int) {
+					for ; pending > 0; pending-- {
+						if r := <-results; r.conn != nil {
+							r.conn.Close()
+						}
+					}
+				}(pending)
+				return r.conn, nil
+			}
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			if next < len(addrs) {
+				start()
+				resetTimer()
+			}
+		case <-timer.C:
+			if next < len(addrs) {
+				start()
+				timer.Reset(delay)
+			}
+		}
+	}
+
+	return nil, firstErr
+}
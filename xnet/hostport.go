@@ -0,0 +1,74 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	hostnameMaxLength      = 253
+	hostnameLabelMaxLength = 63
+)
+
+// SplitHostPortDefault acts like net.SplitHostPort but returns defaultPort when hostport has no port,
+// e.g. "example.com", "example.com:", "[::1]" or "::1".
+func SplitHostPortDefault(hostport, defaultPort string) (host, port string, err error) {
+	if net.ParseIP(hostport) != nil {
+		return hostport, defaultPort, nil
+	}
+	if strings.HasPrefix(hostport, "[") && strings.HasSuffix(hostport, "]") {
+		return hostport[1 : len(hostport)-1], defaultPort, nil
+	}
+
+	host, port, err = net.SplitHostPort(hostport)
+	if err != nil {
+		var addrErr *net.AddrError
+		if errors.As(err, &addrErr) && addrErr.Err == "missing port in address" {
+			return hostport, defaultPort, nil
+		}
+		return "", "", err
+	}
+	if port == "" {
+		port = defaultPort
+	}
+	return host, port, nil
+}
+
+// JoinHostPortInt acts like net.JoinHostPort but takes the port as an integer.
+func JoinHostPortInt(host string, port int) string {
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// IsIP reports whether s is the textual representation of an IPv4 or IPv6 address.
+func IsIP(s string) bool {
+	return net.ParseIP(s) != nil
+}
+
+// IsHostname reports whether s is a valid hostname as defined by RFC 1123, i.e. dot-separated labels of
+// up to 63 letters, digits and hyphens, not starting nor ending with a hyphen, of up to 253 characters
+// in total. A trailing dot is allowed. IP addresses are not considered hostnames.
+func IsHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > hostnameMaxLength || IsIP(s) {
+		return false
+	}
+
+	for _, label := range strings.Split(s, ".") {
+		if label == "" || len(label) > hostnameLabelMaxLength || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for i := 0; i < len(label); i++ {
+			c := label[i]
+			if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet"
+)
+
+func TestSplitHostPortDefault(t *testing.T) {
+	testCases := []struct {
+		name         string
+		hostport     string
+		expectedHost string
+		expectedPort string
+		expectedErr  bool
+	}{
+		{name: "host and port", hostport: "example.com:8080", expectedHost: "example.com", expectedPort: "8080"},
+		{name: "host only", hostport: "example.com", expectedHost: "example.com", expectedPort: "80"},
+		{name: "empty port", hostport: "example.com:", expectedHost: "example.com", expectedPort: "80"},
+		{name: "ipv4 only", hostport: "127.0.0.1", expectedHost: "127.0.0.1", expectedPort: "80"},
+		{name: "ipv6 and port", hostport: "[::1]:8080", expectedHost: "::1", expectedPort: "8080"},
+		{name: "bracketed ipv6 only", hostport: "[::1]", expectedHost: "::1", expectedPort: "80"},
+		{name: "bare ipv6 only", hostport: "::1", expectedHost: "::1", expectedPort: "80"},
+		{name: "empty", hostport: "", expectedHost: "", expectedPort: "80"},
+		{name: "too many colons", hostport: "a:b:c", expectedErr: true},
+		{name: "missing bracket", hostport: "[::1:80", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			host, port, err := xnet.SplitHostPortDefault(tc.hostport, "80")
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error is %t, got %v", tc.expectedErr, err)
+			}
+			if host != tc.expectedHost {
+				t.Errorf("host mismatch: expected %q; got %q", tc.expectedHost, host)
+			}
+			if port != tc.expectedPort {
+				t.Errorf("port mismatch: expected %q; got %q", tc.expectedPort, port)
+			}
+		})
+	}
+}
+
+func TestJoinHostPortInt(t *testing.T) {
+	testCases := []struct {
+		name     string
+		host     string
+		port     int
+		expected string
+	}{
+		{name: "hostname", host: "example.com", port: 80, expected: "example.com:80"},
+		{name: "ipv6", host: "::1", port: 443, expected: "[::1]:443"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := xnet.JoinHostPortInt(tc.host, tc.port); got != tc.expected {
+				t.Errorf("address mismatch: expected %q; got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestIsIP(t *testing.T) {
+	testCases := []struct {
+		name     string
+		s        string
+		expected bool
+	}{
+		{name: "ipv4", s: "192.0.2.1", expected: true},
+		{name: "ipv6", s: "2001:db8::1", expected: true},
+		{name: "hostname", s: "example.com", expected: false},
+		{name: "bracketed ipv6", s: "[::1]", expected: false},
+		{name: "empty", s: "", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := xnet.IsIP(tc.s); got != tc.expected {
+				t.Errorf("result mismatch: expected %t; got %t", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestIsHostname(t *testing.T) {
+	testCases := []struct {
+		name     string
+		s        string
+		expected bool
+	}{
+		{name: "single label", s: "localhost", expected: true},
+		{name: "multiple labels", s: "api-1.Example.com", expected: true},
+		{name: "trailing dot", s: "example.com.", expected: true},
+		{name: "leading digit", s: "3com.net", expected: true},
+		{name: "max label length", s: strings.Repeat("a", 63) + ".com", expected: true},
+		{name: "empty", s: "", expected: false},
+		{name: "dot only", s: ".", expected: false},
+		{name: "empty label", s: "example..com", expected: false},
+		{name: "leading hyphen", s: "-example.com", expected: false},
+		{name: "trailing hyphen", s: "example-.com", expected: false},
+		{name: "underscore", s: "my_host.com", expected: false},
+		{name: "label too long", s: strings.Repeat("a", 64) + ".com", expected: false},
+		{name: "hostname too long", s: strings.Repeat("a.", 127) + "ab", expected: false},
+		{name: "ip address", s: "192.0.2.1", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := xnet.IsHostname(tc.s); got != tc.expected {
+				t.Errorf("result mismatch: expected %t; got %t", tc.expected, got)
+			}
+		})
+	}
+}
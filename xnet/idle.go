@@ -0,0 +1,83 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"errors"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// ErrIdleTimeout is returned by the operations on a connection closed after being idle for too long.
+var ErrIdleTimeout = errors.New("connection idle timeout")
+
+// IdleTimeoutConn returns a connection wrapping c which is closed once no Read or Write has started or
+// completed for timeout, e.g. to reap abandoned long-lived connections. Unlike deadlines, the timeout is
+// not bound to a single operation. Once closed, pending and subsequent operations fail with ErrIdleTimeout.
+// It panics if timeout is <= 0.
+func IdleTimeoutConn(c net.Conn, timeout time.Duration) net.Conn {
+	if timeout <= 0 {
+		panic("invalid idle timeout value")
+	}
+	ic := &idleConn{Conn: c, timeout: timeout}
+	ic.timer = time.AfterFunc(timeout, ic.expire)
+	return ic
+}
+
+type idleConn struct {
+	net.Conn
+	timeout time.Duration
+	timer   *time.Timer
+	expired atomic.Bool
+}
+
+func (c *idleConn) expire() {
+	c.expired.Store(true)
+	c.Conn.Close()
+}
+
+// Read reads data from the connection, postponing its idle timeout.
+//
+// See net.Conn.Read for more information.
+func (c *idleConn) Read(b []byte) (int, error) {
+	if c.expired.Load() {
+		return 0, ErrIdleTimeout
+	}
+	c.timer.Reset(c.timeout)
+	n, err := c.Conn.Read(b)
+	return n, c.done(err)
+}
+
+// Write writes data to the connection, postponing its idle timeout.
+//
+// See net.Conn.Write for more information.
+func (c *idleConn) Write(b []byte) (int, error) {
+	if c.expired.Load() {
+		return 0, ErrIdleTimeout
+	}
+	c.timer.Reset(c.timeout)
+	n, err := c.Conn.Write(b)
+	return n, c.done(err)
+}
+
+// Close closes the connection.
+//
+// See net.Conn.Close for more information.
+func (c *idleConn) Close() error {
+	c.timer.Stop()
+	return c.Conn.Close()
+}
+
+func (c *idleConn) done(err error) error {
+	if c.expired.Load() {
+		if err != nil {
+			return ErrIdleTimeout
+		}
+		return nil
+	}
+	c.timer.Reset(c.timeout)
+	return err
+}
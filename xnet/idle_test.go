@@ -0,0 +1,76 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet"
+)
+
+func TestDialIdleTimeout(t *testing.T) {
+	ln, port, err := listenTCP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go serveEcho(ln)
+
+	c, err := xnet.Dial("tcp", "127.0.0.1:"+port, xnet.DialIdleTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// Activity keeps the connection open past the idle timeout.
+	b := make([]byte, 4)
+	for i := 0; i < 5; i++ {
+		time.Sleep(20 * time.Millisecond)
+		if _, err := io.WriteString(c, "ping"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := io.ReadFull(c, b); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// A read waiting for longer than the idle timeout fails, as do subsequent operations.
+	start := time.Now()
+	if _, err := c.Read(b); !errors.Is(err, xnet.ErrIdleTimeout) {
+		t.Errorf("error mismatch: expected %v; got %v", xnet.ErrIdleTimeout, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the read to fail after the idle timeout; took %s", elapsed)
+	}
+	if _, err := io.WriteString(c, "ping"); !errors.Is(err, xnet.ErrIdleTimeout) {
+		t.Errorf("error mismatch: expected %v; got %v", xnet.ErrIdleTimeout, err)
+	}
+}
+
+func TestIdleTimeoutConn_Panic(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func()
+		panic bool
+	}{
+		{name: "zero conn timeout", fn: func() { xnet.IdleTimeoutConn(nil, 0) }, panic: true},
+		{name: "negative dial timeout", fn: func() { xnet.DialIdleTimeout(-1) }, panic: true},
+		{name: "valid", fn: func() { xnet.DialIdleTimeout(0) }, panic: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tc.panic {
+					t.Errorf("panic mismatch: expected %t; got %v", tc.panic, r)
+				}
+			}()
+			tc.fn()
+		})
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"context"
+	"net"
+)
+
+// dialNet dials address with the embedded net.Dialer, bound to the interface if any.
+func (d *Dialer) dialNet(ctx context.Context, network, address string) (net.Conn, error) {
+	nd, err := d.netDialer(network)
+	if err != nil {
+		return nil, err
+	}
+	return nd.DialContext(ctx, network, address)
+}
+
+// netDialer returns a copy of the embedded net.Dialer, bound to the interface if any
+// when network is an IP network.
+func (d *Dialer) netDialer(network string) (net.Dialer, error) {
+	nd := d.Dialer
+	if d.iface == "" || !isIPNetwork(network) {
+		return nd, nil
+	}
+	if err := bindInterface(&nd, network, d.iface); err != nil {
+		return net.Dialer{}, &net.OpError{Op: "dial", Net: network, Err: err}
+	}
+	return nd, nil
+}
@@ -0,0 +1,19 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+// bindInterface binds the sockets of nd to the interface name with SO_BINDTODEVICE.
+func bindInterface(nd *net.Dialer, _, name string) error {
+	nd.Control = chainControl(nd.Control, sockoptControl(func(fd uintptr) error {
+		return os.NewSyscallError("setsockopt so_bindtodevice", syscall.BindToDevice(int(fd), name))
+	}))
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !linux
+
+package xnet
+
+import (
+	"net"
+	"strings"
+)
+
+// bindInterface binds the sockets of nd to the first address of the interface name
+// suitable for network, IPv4 addresses first.
+func bindInterface(nd *net.Dialer, network, name string) error {
+	ifi, err := net.InterfaceByName(name)
+	if err != nil {
+		return err
+	}
+	addrs, err := ifi.Addrs()
+	if err != nil {
+		return err
+	}
+
+	var ip4, ip6 *net.IPNet
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		if ipNet.IP.To4() != nil {
+			if ip4 == nil {
+				ip4 = ipNet
+			}
+		} else if ip6 == nil {
+			ip6 = ipNet
+		}
+	}
+
+	ipNet := ip4
+	if strings.HasSuffix(network, "6") || (ip4 == nil && !strings.HasSuffix(network, "4")) {
+		ipNet = ip6
+	}
+	if ipNet == nil {
+		return &net.AddrError{Err: "no suitable address found", Addr: name}
+	}
+
+	var zone string
+	if ipNet.IP.IsLinkLocalUnicast() {
+		zone = name
+	}
+
+	if strings.HasPrefix(network, NetworkUDP) {
+		nd.LocalAddr = &net.UDPAddr{IP: ipNet.IP, Zone: zone}
+	} else {
+		nd.LocalAddr = &net.TCPAddr{IP: ipNet.IP, Zone: zone}
+	}
+	return nil
+}
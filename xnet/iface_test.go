@@ -0,0 +1,134 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet"
+)
+
+func loopbackInterface(tb testing.TB) string {
+	tb.Helper()
+
+	ifis, err := net.Interfaces()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for _, ifi := range ifis {
+		if ifi.Flags&net.FlagLoopback != 0 && ifi.Flags&net.FlagUp != 0 {
+			return ifi.Name
+		}
+	}
+	tb.Skip("no loopback interface")
+	return ""
+}
+
+func TestDialInterface(t *testing.T) {
+	ln, port, err := listenTCP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	loopback := loopbackInterface(t)
+
+	testCases := []struct {
+		name        string
+		address     string
+		options     []xnet.DialOption
+		expectedErr bool
+	}{
+		{
+			name:        "loopback interface",
+			address:     net.JoinHostPort("127.0.0.1", port),
+			options:     []xnet.DialOption{xnet.DialInterface(loopback)},
+			expectedErr: false,
+		},
+		{
+			name:    "loopback interface with resolver",
+			address: net.JoinHostPort("example.test", port),
+			options: []xnet.DialOption{
+				xnet.DialInterface(loopback),
+				xnet.DialResolver(&fakeResolver{addrs: []net.IPAddr{{IP: net.IPv4(127, 0, 0, 1)}}}),
+			},
+			expectedErr: false,
+		},
+		{
+			name:        "unknown interface",
+			address:     net.JoinHostPort("127.0.0.1", port),
+			options:     []xnet.DialOption{xnet.DialInterface("xgo-unknown0")},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := xnet.Dial("tcp4", tc.address, tc.options...)
+			assertDial(t, tc.expectedErr, c, err)
+		})
+	}
+}
+
+func TestDialLocalAddr(t *testing.T) {
+	ln, port, err := listenTCP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	testCases := []struct {
+		name        string
+		localAddr   net.Addr
+		expectedErr bool
+	}{
+		{
+			name:        "tcp address",
+			localAddr:   &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)},
+			expectedErr: false,
+		},
+		{
+			name:        "mismatched address type",
+			localAddr:   &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := xnet.Dial("tcp", net.JoinHostPort("127.0.0.1", port), xnet.DialLocalAddr(tc.localAddr))
+			if err == nil {
+				if ip := c.LocalAddr().(*net.TCPAddr).IP; !ip.Equal(net.IPv4(127, 0, 0, 1)) {
+					t.Errorf("local address mismatch: expected 127.0.0.1; got %s", ip)
+				}
+			}
+			assertDial(t, tc.expectedErr, c, err)
+		})
+	}
+}
+
+func TestDialInterface_Panic(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func()
+		panic bool
+	}{
+		{name: "empty interface name", fn: func() { xnet.DialInterface("") }, panic: true},
+		{name: "nil local address", fn: func() { xnet.DialLocalAddr(nil) }, panic: true},
+		{name: "valid", fn: func() { xnet.DialInterface("lo"); xnet.DialLocalAddr(&net.TCPAddr{}) }, panic: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tc.panic {
+					t.Errorf("panic mismatch: expected %t; got %v", tc.panic, r)
+				}
+			}()
+			tc.fn()
+		})
+	}
+}
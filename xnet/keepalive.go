@@ -0,0 +1,33 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"net"
+	"time"
+)
+
+type keepAliveConfig struct {
+	idle     time.Duration
+	interval time.Duration
+	count    int
+}
+
+// setKeepAliveConfig enables keep-alive probes on c, if a TCP connection, and configures them with cfg.
+func setKeepAliveConfig(c net.Conn, cfg keepAliveConfig) error {
+	tc, ok := c.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+	if err := tc.SetKeepAlive(true); err != nil {
+		return err
+	}
+	return setKeepAliveParams(tc, cfg)
+}
+
+// roundSeconds returns d in seconds, rounded up.
+func roundSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
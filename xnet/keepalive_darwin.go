@@ -0,0 +1,15 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import "syscall"
+
+// TCP_KEEPINTVL and TCP_KEEPCNT are not defined by the syscall package on Darwin.
+// Their values come from bsd/netinet/tcp.h in the XNU sources.
+const (
+	sysTCPKeepIdle  = syscall.TCP_KEEPALIVE
+	sysTCPKeepIntvl = 0x101 // TCP_KEEPINTVL
+	sysTCPKeepCnt   = 0x102 // TCP_KEEPCNT
+)
@@ -0,0 +1,13 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import "syscall"
+
+const (
+	sysTCPKeepIdle  = syscall.TCP_KEEPIDLE
+	sysTCPKeepIntvl = syscall.TCP_KEEPINTVL
+	sysTCPKeepCnt   = syscall.TCP_KEEPCNT
+)
@@ -0,0 +1,18 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !darwin && !linux
+
+package xnet
+
+import "net"
+
+// setKeepAliveParams falls back to the keep-alive period supported by the net package, as with
+// net.Dialer.KeepAlive: only the idle time is applied while the interval and count are ignored.
+func setKeepAliveParams(tc *net.TCPConn, cfg keepAliveConfig) error {
+	if cfg.idle == 0 {
+		return nil
+	}
+	return tc.SetKeepAlivePeriod(cfg.idle)
+}
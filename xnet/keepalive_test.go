@@ -0,0 +1,47 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet"
+)
+
+func TestDialKeepAliveConfig(t *testing.T) {
+	ln, port, err := listenTCP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	c, err := xnet.Dial("tcp", "127.0.0.1:"+port, xnet.DialKeepAliveConfig(30*time.Second, 1500*time.Millisecond, 3))
+	assertDial(t, false, c, err)
+}
+
+func TestDialKeepAliveConfig_Panic(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func()
+		panic bool
+	}{
+		{name: "negative idle", fn: func() { xnet.DialKeepAliveConfig(-1, 0, 0) }, panic: true},
+		{name: "negative interval", fn: func() { xnet.DialKeepAliveConfig(0, -1, 0) }, panic: true},
+		{name: "negative count", fn: func() { xnet.DialKeepAliveConfig(0, 0, -1) }, panic: true},
+		{name: "valid", fn: func() { xnet.DialKeepAliveConfig(time.Second, time.Second, 1) }, panic: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tc.panic {
+					t.Errorf("panic mismatch: expected %t; got %v", tc.panic, r)
+				}
+			}()
+			tc.fn()
+		})
+	}
+}
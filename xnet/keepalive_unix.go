@@ -0,0 +1,50 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build darwin || linux
+
+package xnet
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+func setKeepAliveParams(tc *net.TCPConn, cfg keepAliveConfig) error {
+	rc, err := tc.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var sockErr error
+	if err := rc.Control(func(fd uintptr) {
+		sockErr = setKeepAliveSockopts(fd, cfg)
+	}); err != nil {
+		return err
+	}
+	return sockErr
+}
+
+func setKeepAliveSockopts(fd uintptr, cfg keepAliveConfig) error {
+	opts := []struct {
+		name  string
+		opt   int
+		value int
+	}{
+		{name: "tcp_keepidle", opt: sysTCPKeepIdle, value: roundSeconds(cfg.idle)},
+		{name: "tcp_keepintvl", opt: sysTCPKeepIntvl, value: roundSeconds(cfg.interval)},
+		{name: "tcp_keepcnt", opt: sysTCPKeepCnt, value: cfg.count},
+	}
+
+	for _, o := range opts {
+		if o.value == 0 {
+			continue
+		}
+		if err := syscall.SetsockoptInt(int(fd), syscall.IPPROTO_TCP, o.opt, o.value); err != nil {
+			return os.NewSyscallError("setsockopt "+o.name, err)
+		}
+	}
+	return nil
+}
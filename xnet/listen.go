@@ -0,0 +1,157 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"context"
+	"io/fs"
+	"net"
+	"time"
+)
+
+// ListenConfig is a wrapper around net.ListenConfig that provides additional options for listening on an address.
+//
+// See net.ListenConfig for more information.
+type ListenConfig struct {
+	net.ListenConfig
+	// ReadTimeout is the maximum amount of time after which each Read operation on an accepted Conn
+	// fails instead of blocking. In this case, an error that wraps os.ErrDeadlineExceeded
+	// is returned. This can be tested using errors.Is(err, os.ErrDeadlineExceeded).
+	//
+	// The default is no timeout. (zero value)
+	ReadTimeout time.Duration
+	// WriteTimeout is the maximum amount of time after which each Write operation on an accepted Conn
+	// fails instead of blocking. In this case, an error that wraps os.ErrDeadlineExceeded
+	// is returned. This can be tested using errors.Is(err, os.ErrDeadlineExceeded).
+	//
+	// The default is no timeout. (zero value)
+	WriteTimeout time.Duration
+
+	unixMode  *fs.FileMode
+	unixOwner *unixOwner
+}
+
+// Listen acts like net.Listen but uses a ListenConfig that supports read and write timeouts at the connection level.
+// Optional ListenOption parameters may be passed in to configure the ListenConfig.
+//
+// Read and write timeouts are respectively applied to each Read and Write call on the accepted net.Conn
+// (a zero value means no timeout), as for the connections made with Dial.
+//
+// See net.Listen for more information.
+func Listen(network, address string, options ...ListenOption) (net.Listener, error) {
+	return ListenContext(context.Background(), network, address, options...)
+}
+
+// ListenContext acts like Listen but takes a context.Context.
+//
+// See net.ListenConfig.Listen for more information.
+func ListenContext(ctx context.Context, network, address string, options ...ListenOption) (net.Listener, error) {
+	var lc ListenConfig
+
+	for _, option := range options {
+		option.apply(&lc)
+	}
+
+	return lc.Listen(ctx, network, address)
+}
+
+// Listen announces on the local network address. Accepted connections apply the read and write timeouts
+// of the ListenConfig.
+//
+// See net.ListenConfig.Listen for more information.
+func (lc *ListenConfig) Listen(ctx context.Context, network, address string) (net.Listener, error) {
+	l, err := lc.ListenConfig.Listen(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	return lc.wrap(l), nil
+}
+
+// wrap wraps l so that accepted connections apply the read and write timeouts, if any.
+func (lc *ListenConfig) wrap(l net.Listener) net.Listener {
+	if lc.ReadTimeout == 0 && lc.WriteTimeout == 0 {
+		return l
+	}
+	return &listener{Listener: l, readTimeout: lc.ReadTimeout, writeTimeout: lc.WriteTimeout}
+}
+
+type listener struct {
+	net.Listener
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+}
+
+// Accept waits for and returns the next connection to the listener,
+// wrapped to apply the read and write timeouts.
+//
+// See net.Listener.Accept for more information.
+func (l *listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: c, readTimeout: l.readTimeout, writeTimeout: l.writeTimeout}, nil
+}
+
+type (
+	// ListenOption configures how listeners are created.
+	ListenOption interface {
+		apply(lc *ListenConfig)
+	}
+
+	funcListenOption struct {
+		fn func(*ListenConfig)
+	}
+)
+
+func newFuncListenOption(fn func(*ListenConfig)) funcListenOption {
+	return funcListenOption{
+		fn: fn,
+	}
+}
+
+func (flo funcListenOption) apply(lc *ListenConfig) {
+	flo.fn(lc)
+}
+
+// ListenConfigOptions returns a ListenOption that applies the ListenConfigOption passed in input
+// to the underlying net.ListenConfig.
+func ListenConfigOptions(options ...ListenConfigOption) ListenOption {
+	return newFuncListenOption(func(lc *ListenConfig) {
+		for _, option := range options {
+			option.apply(&lc.ListenConfig)
+		}
+	})
+}
+
+// ListenReadTimeout returns a ListenOption that configures a timeout for a Read on an accepted Conn to complete.
+func ListenReadTimeout(timeout time.Duration) ListenOption {
+	return newFuncListenOption(func(lc *ListenConfig) {
+		lc.ReadTimeout = timeout
+	})
+}
+
+// ListenUnixMode returns a ListenOption that configures the file mode of the socket file created
+// by ListenUnix, e.g. 0o660 to restrict connections to the owner and group.
+func ListenUnixMode(mode fs.FileMode) ListenOption {
+	return newFuncListenOption(func(lc *ListenConfig) {
+		lc.unixMode = &mode
+	})
+}
+
+// ListenUnixOwner returns a ListenOption that configures the owner and group of the socket file created
+// by ListenUnix. A uid or gid of -1 leaves it unchanged.
+func ListenUnixOwner(uid, gid int) ListenOption {
+	return newFuncListenOption(func(lc *ListenConfig) {
+		lc.unixOwner = &unixOwner{uid: uid, gid: gid}
+	})
+}
+
+// ListenWriteTimeout returns a ListenOption that configures a timeout for a Write on an accepted Conn to complete.
+func ListenWriteTimeout(timeout time.Duration) ListenOption {
+	return newFuncListenOption(func(lc *ListenConfig) {
+		lc.WriteTimeout = timeout
+	})
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet_test
+
+import (
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet"
+)
+
+func TestListen(t *testing.T) {
+	testCases := []struct {
+		name            string
+		options         []xnet.ListenOption
+		expectedTimeout bool
+	}{
+		{
+			name:            "no timeout",
+			options:         []xnet.ListenOption{xnet.ListenConfigOptions(xnet.ListenConfigKeepAlive(time.Second))},
+			expectedTimeout: false,
+		},
+		{
+			name:            "read timeout",
+			options:         []xnet.ListenOption{xnet.ListenReadTimeout(10 * time.Millisecond)},
+			expectedTimeout: true,
+		},
+		{
+			name:            "read and write timeouts",
+			options:         []xnet.ListenOption{xnet.ListenReadTimeout(10 * time.Millisecond), xnet.ListenWriteTimeout(time.Second)},
+			expectedTimeout: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ln, err := xnet.Listen("tcp", "127.0.0.1:0", tc.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ln.Close()
+
+			client, err := net.Dial("tcp", ln.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+
+			c, err := ln.Accept()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+
+			if !tc.expectedTimeout {
+				// Unblock the read once the client closes the connection.
+				time.AfterFunc(50*time.Millisecond, func() { client.Close() })
+			}
+
+			_, err = c.Read(make([]byte, 1))
+			if timeout := errors.Is(err, os.ErrDeadlineExceeded); timeout != tc.expectedTimeout {
+				t.Errorf("timeout mismatch: expected %t; got %v", tc.expectedTimeout, err)
+			}
+		})
+	}
+}
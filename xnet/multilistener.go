@@ -0,0 +1,117 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"errors"
+	"net"
+	"sync"
+)
+
+// JoinListeners returns a listener accepting connections from all the listeners passed in input,
+// e.g. TCP and Unix sockets or IPv4 and IPv6 sockets, for servers bound to several endpoints.
+// Closing it closes them all, while a listener closed on its own is just no longer accepted from.
+// Its Addr is the one of the first listener. It panics if no listener is passed in input.
+func JoinListeners(listeners ...net.Listener) net.Listener {
+	if len(listeners) == 0 {
+		panic("no listener")
+	}
+
+	ml := &multiListener{
+		listeners: listeners,
+		accepted:  make(chan acceptResult),
+		done:      make(chan struct{}),
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(listeners))
+	for _, l := range listeners {
+		go func(l net.Listener) {
+			defer wg.Done()
+			ml.acceptLoop(l)
+		}(l)
+	}
+	go func() {
+		wg.Wait()
+		close(ml.accepted)
+	}()
+
+	return ml
+}
+
+type acceptResult struct {
+	conn net.Conn
+	err  error
+}
+
+type multiListener struct {
+	listeners []net.Listener
+	accepted  chan acceptResult
+	done      chan struct{}
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+func (ml *multiListener) acceptLoop(l net.Listener) {
+	for {
+		c, err := l.Accept()
+		select {
+		case ml.accepted <- acceptResult{conn: c, err: err}:
+		case <-ml.done:
+			if c != nil {
+				c.Close()
+			}
+			return
+		}
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+	}
+}
+
+// Accept waits for and returns the next connection accepted by any of the listeners.
+// Errors returned by a listener are passed through, except the one returned once closed.
+//
+// See net.Listener.Accept for more information.
+func (ml *multiListener) Accept() (net.Conn, error) {
+	for {
+		select {
+		case r, ok := <-ml.accepted:
+			if !ok {
+				return nil, net.ErrClosed
+			}
+			if errors.Is(r.err, net.ErrClosed) {
+				continue
+			}
+			return r.conn, r.err
+		case <-ml.done:
+			return nil, net.ErrClosed
+		}
+	}
+}
+
+// Addr returns the address of the first listener.
+func (ml *multiListener) Addr() net.Addr {
+	return ml.listeners[0].Addr()
+}
+
+// Close closes all the listeners.
+//
+// See net.Listener.Close for more information.
+func (ml *multiListener) Close() error {
+	ml.closeOnce.Do(func() {
+		close(ml.done)
+
+		var errs []error
+		for _, l := range ml.listeners {
+			if err := l.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+				errs = append(errs, err)
+			}
+		}
+		ml.closeErr = errors.Join(errs...)
+	})
+	return ml.closeErr
+}
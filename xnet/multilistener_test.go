@@ -0,0 +1,72 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet_test
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet"
+)
+
+func TestJoinListeners(t *testing.T) {
+	l1, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l2, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ml := xnet.JoinListeners(l1, l2)
+	defer ml.Close()
+
+	if ml.Addr() != l1.Addr() {
+		t.Errorf("addr mismatch: expected %v; got %v", l1.Addr(), ml.Addr())
+	}
+
+	// Closing one listener leaves the others accepting.
+	for i, l := range []net.Listener{l1, l2} {
+		c, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+
+		accepted, err := ml.Accept()
+		if err != nil {
+			t.Fatalf("listener %d: unexpected error: %v", i, err)
+		}
+		if accepted.LocalAddr().String() != l.Addr().String() {
+			t.Errorf("listener %d: local addr mismatch: expected %v; got %v", i, l.Addr(), accepted.LocalAddr())
+		}
+		accepted.Close()
+
+		if i == 0 {
+			l1.Close()
+		}
+	}
+
+	if err := ml.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := ml.Accept(); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("error mismatch: expected %v; got %v", net.ErrClosed, err)
+	}
+	if _, err := net.Dial("tcp", l2.Addr().String()); err == nil {
+		t.Error("expected all the listeners to be closed")
+	}
+}
+
+func TestJoinListeners_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	xnet.JoinListeners()
+}
@@ -27,6 +27,16 @@ const (
 	NetworkUnixpacket = "unixpacket"
 )
 
+// isIPNetwork reports whether network is a TCP or UDP network, whose addresses are host:port pairs.
+func isIPNetwork(network string) bool {
+	switch network {
+	case NetworkTCP, NetworkTCP4, NetworkTCP6, NetworkUDP, NetworkUDP4, NetworkUDP6:
+		return true
+	default:
+		return false
+	}
+}
+
 type conn struct {
 	net.Conn
 	readTimeout  time.Duration
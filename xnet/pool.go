@@ -0,0 +1,403 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jlourenc/xgo/xunit"
+)
+
+const (
+	poolDefaultIdleTimeout = 90 * time.Second
+	poolDefaultMaxIdle     = 2
+)
+
+// ErrPoolClosed is returned by Pool.Get once the pool is closed.
+var ErrPoolClosed = errors.New("pool closed")
+
+// Pool is a pool of connections, reused per network and address, for protocols lacking built-in pooling.
+//
+// It is safe for concurrent use by multiple goroutines.
+type Pool struct {
+	dial             func(ctx context.Context, network, address string) (net.Conn, error)
+	idleTimeout      time.Duration
+	maxIdle          int
+	maxOpen          int
+	maxLifetimeBytes xunit.Byte
+	ping             func(c net.Conn) error
+
+	mu         sync.Mutex
+	entries    map[poolKey]*poolEntry
+	sweepTimer *time.Timer
+	closed     bool
+}
+
+type poolKey struct {
+	network string
+	address string
+}
+
+type poolEntry struct {
+	idle   []*PoolConn // Most recently released last.
+	open   int
+	notify chan struct{}
+}
+
+// NewPool creates a new Pool configured with the options passed in input. By default, connections are
+// made with a zero Dialer, up to 2 idle connections are kept per address for up to 90s and the number of
+// open connections is not limited. Expired idle connections are closed in the background.
+func NewPool(options ...PoolOption) *Pool {
+	p := &Pool{
+		dial:        (&Dialer{}).DialContext,
+		idleTimeout: poolDefaultIdleTimeout,
+		maxIdle:     poolDefaultMaxIdle,
+		entries:     make(map[poolKey]*poolEntry),
+	}
+
+	for _, opt := range options {
+		opt.apply(p)
+	}
+
+	return p
+}
+
+// Get returns an idle connection to address, if any, or a new one otherwise. Idle connections are checked
+// with the ping function, if any, before being returned and discarded when it fails. Once the max number of
+// open connections to address is reached, it waits for one to be released or closed until ctx is done.
+//
+// The connection must be closed to be released to the pool.
+func (p *Pool) Get(ctx context.Context, network, address string) (*PoolConn, error) {
+	key := poolKey{network: network, address: address}
+
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrPoolClosed
+		}
+
+		e := p.entryLocked(key)
+
+		if c := p.popIdleLocked(e); c != nil {
+			p.mu.Unlock()
+			if p.ping != nil {
+				if err := p.ping(c.Conn); err != nil {
+					p.closeIdle(c) //nolint:errcheck // the connection is unhealthy anyway.
+					continue
+				}
+			}
+			c.released.Store(false)
+			return c, nil
+		}
+
+		if p.maxOpen <= 0 || e.open < p.maxOpen {
+			e.open++
+			p.mu.Unlock()
+			return p.dialConn(ctx, key, e)
+		}
+
+		notify := e.notify
+		p.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Close closes the idle connections of the pool. Connections in use are closed when released.
+// Get fails with ErrPoolClosed once the pool is closed.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	if p.sweepTimer != nil {
+		p.sweepTimer.Stop()
+		p.sweepTimer = nil
+	}
+	var idle []*PoolConn
+	for _, e := range p.entries {
+		idle = append(idle, e.idle...)
+		e.idle = nil
+	}
+	p.mu.Unlock()
+
+	var errs []error
+	for _, c := range idle {
+		errs = append(errs, p.closeIdle(c))
+	}
+	return errors.Join(errs...)
+}
+
+func (p *Pool) dialConn(ctx context.Context, key poolKey, e *poolEntry) (*PoolConn, error) {
+	c, err := p.dial(ctx, key.network, key.address)
+	if err != nil {
+		p.mu.Lock()
+		p.closedLocked(e)
+		p.mu.Unlock()
+		return nil, err
+	}
+
+	return &PoolConn{Conn: c, pool: p, entry: e}, nil
+}
+
+func (p *Pool) entryLocked(key poolKey) *poolEntry {
+	e, ok := p.entries[key]
+	if !ok {
+		e = &poolEntry{notify: make(chan struct{})}
+		p.entries[key] = e
+	}
+	return e
+}
+
+// popIdleLocked returns the most recently released idle connection of e, closing the expired ones.
+func (p *Pool) popIdleLocked(e *poolEntry) *PoolConn {
+	for len(e.idle) > 0 {
+		c := e.idle[len(e.idle)-1]
+		e.idle = e.idle[:len(e.idle)-1]
+
+		if p.idleTimeout > 0 && time.Since(c.idleSince) > p.idleTimeout {
+			c.Conn.Close()
+			p.closedLocked(e)
+			continue
+		}
+		return c
+	}
+	return nil
+}
+
+// closedLocked records that a connection of e was closed and wakes up the callers waiting for one.
+func (p *Pool) closedLocked(e *poolEntry) {
+	e.open--
+	p.notifyLocked(e)
+}
+
+func (p *Pool) notifyLocked(e *poolEntry) {
+	close(e.notify)
+	e.notify = make(chan struct{})
+}
+
+// closeIdle closes c, an idle connection removed from the pool.
+func (p *Pool) closeIdle(c *PoolConn) error {
+	p.mu.Lock()
+	p.closedLocked(c.entry)
+	p.mu.Unlock()
+	return c.Conn.Close()
+}
+
+func (p *Pool) release(c *PoolConn) error {
+	p.mu.Lock()
+	if p.closed || c.failed.Load() || len(c.entry.idle) >= p.maxIdle ||
+		(p.maxLifetimeBytes > 0 && xunit.Byte(c.transferred.Load()) >= p.maxLifetimeBytes) {
+		p.closedLocked(c.entry)
+		p.mu.Unlock()
+		return c.Conn.Close()
+	}
+
+	c.idleSince = time.Now()
+	c.entry.idle = append(c.entry.idle, c)
+	p.notifyLocked(c.entry)
+	if p.idleTimeout > 0 && p.sweepTimer == nil {
+		p.sweepTimer = time.AfterFunc(p.idleTimeout, p.sweep)
+	}
+	p.mu.Unlock()
+	return nil
+}
+
+// sweep closes the expired idle connections and removes the entries of addresses without open connections.
+// It is scheduled again as long as idle connections remain.
+func (p *Pool) sweep() {
+	p.mu.Lock()
+	p.sweepTimer = nil
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	var expired []*PoolConn
+	var next time.Duration
+	for key, e := range p.entries {
+		kept := e.idle[:0]
+		for _, c := range e.idle {
+			if d := p.idleTimeout - now.Sub(c.idleSince); d > 0 {
+				kept = append(kept, c)
+				if next == 0 || d < next {
+					next = d
+				}
+				continue
+			}
+			expired = append(expired, c)
+			p.closedLocked(e)
+		}
+		clear(e.idle[len(kept):])
+		e.idle = kept
+
+		if e.open == 0 {
+			delete(p.entries, key)
+		}
+	}
+	if next > 0 {
+		p.sweepTimer = time.AfterFunc(next, p.sweep)
+	}
+	p.mu.Unlock()
+
+	for _, c := range expired {
+		c.Conn.Close()
+	}
+}
+
+// PoolConn is a connection obtained from a Pool.
+type PoolConn struct {
+	net.Conn
+
+	pool        *Pool
+	entry       *poolEntry
+	idleSince   time.Time
+	transferred atomic.Int64
+	failed      atomic.Bool
+	released    atomic.Bool
+}
+
+// Read reads data from the connection.
+// Once a Read fails, the connection is not reused anymore.
+//
+// See net.Conn.Read for more information.
+func (c *PoolConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.transferred.Add(int64(n))
+	if err != nil {
+		c.failed.Store(true)
+	}
+	return n, err
+}
+
+// Write writes data to the connection.
+// Once a Write fails, the connection is not reused anymore.
+//
+// See net.Conn.Write for more information.
+func (c *PoolConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.transferred.Add(int64(n))
+	if err != nil {
+		c.failed.Store(true)
+	}
+	return n, err
+}
+
+// Close releases the connection to the pool. The connection is closed instead if it failed,
+// transferred more than the max lifetime bytes, if the pool has enough idle connections or is closed.
+// The connection must not be used after Close.
+func (c *PoolConn) Close() error {
+	if c.released.Swap(true) {
+		return nil
+	}
+	return c.pool.release(c)
+}
+
+// Discard closes the connection instead of releasing it to the pool,
+// e.g. when it is left in an unknown protocol state.
+func (c *PoolConn) Discard() error {
+	if c.released.Swap(true) {
+		return nil
+	}
+	c.failed.Store(true)
+	return c.pool.release(c)
+}
+
+type (
+	// PoolOption configures the Pool options when calling NewPool.
+	PoolOption interface {
+		apply(p *Pool)
+	}
+
+	funcPoolOption struct {
+		fn func(*Pool)
+	}
+)
+
+func newFuncPoolOption(fn func(*Pool)) funcPoolOption {
+	return funcPoolOption{
+		fn: fn,
+	}
+}
+
+func (o funcPoolOption) apply(p *Pool) {
+	o.fn(p)
+}
+
+// PoolDial returns a PoolOption that configures the function used to make new connections,
+// e.g. a Dialer DialContext method. If not used, a zero Dialer is used.
+func PoolDial(dial func(ctx context.Context, network, address string) (net.Conn, error)) PoolOption {
+	if dial == nil {
+		panic("dial function is nil")
+	}
+	return newFuncPoolOption(func(p *Pool) {
+		p.dial = dial
+	})
+}
+
+// PoolIdleTimeout returns a PoolOption that configures how long a connection may stay idle in the pool
+// before being closed. A zero value means no limit. Value must be >= 0, otherwise it panics.
+func PoolIdleTimeout(timeout time.Duration) PoolOption {
+	if timeout < 0 {
+		panic("invalid idle timeout value")
+	}
+	return newFuncPoolOption(func(p *Pool) {
+		p.idleTimeout = timeout
+	})
+}
+
+// PoolMaxIdle returns a PoolOption that configures the max number of idle connections kept per address.
+// Value must be >= 0, otherwise it panics.
+func PoolMaxIdle(n int) PoolOption {
+	if n < 0 {
+		panic("invalid max idle value")
+	}
+	return newFuncPoolOption(func(p *Pool) {
+		p.maxIdle = n
+	})
+}
+
+// PoolMaxLifetimeBytes returns a PoolOption that configures the number of bytes read and written
+// after which a connection is closed instead of being released to the pool. A zero value means no limit.
+// Value must be >= 0, otherwise it panics.
+func PoolMaxLifetimeBytes(size xunit.Byte) PoolOption {
+	if size < 0 {
+		panic("invalid max lifetime bytes value")
+	}
+	return newFuncPoolOption(func(p *Pool) {
+		p.maxLifetimeBytes = size
+	})
+}
+
+// PoolMaxOpen returns a PoolOption that configures the max number of open connections per address,
+// idle or in use. A zero value means no limit. Value must be >= 0, otherwise it panics.
+func PoolMaxOpen(n int) PoolOption {
+	if n < 0 {
+		panic("invalid max open value")
+	}
+	return newFuncPoolOption(func(p *Pool) {
+		p.maxOpen = n
+	})
+}
+
+// PoolPing returns a PoolOption that configures a function checking the health of an idle connection
+// before it is returned by Get. Connections for which it fails are closed.
+func PoolPing(ping func(c net.Conn) error) PoolOption {
+	if ping == nil {
+		panic("ping function is nil")
+	}
+	return newFuncPoolOption(func(p *Pool) {
+		p.ping = ping
+	})
+}
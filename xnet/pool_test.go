@@ -0,0 +1,247 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+func TestPool_Get(t *testing.T) {
+	ln, port, err := listenTCP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go serveEcho(ln)
+
+	address := "127.0.0.1:" + port
+
+	testCases := []struct {
+		name          string
+		options       []xnet.PoolOption
+		use           func(tb testing.TB, p *xnet.Pool)
+		expectedDials int32
+	}{
+		{
+			name: "idle connection reused",
+			use: func(tb testing.TB, p *xnet.Pool) {
+				for i := 0; i < 3; i++ {
+					getAndClose(tb, p, address)
+				}
+			},
+			expectedDials: 1,
+		},
+		{
+			name:    "max idle exceeded",
+			options: []xnet.PoolOption{xnet.PoolMaxIdle(1)},
+			use: func(tb testing.TB, p *xnet.Pool) {
+				c1 := get(tb, p, address)
+				c2 := get(tb, p, address)
+				c1.Close()
+				c2.Close()
+				getAndClose(tb, p, address)
+				getAndClose(tb, p, address)
+			},
+			expectedDials: 2,
+		},
+		{
+			name:    "idle timeout expired",
+			options: []xnet.PoolOption{xnet.PoolIdleTimeout(time.Millisecond)},
+			use: func(tb testing.TB, p *xnet.Pool) {
+				getAndClose(tb, p, address)
+				time.Sleep(10 * time.Millisecond)
+				getAndClose(tb, p, address)
+			},
+			expectedDials: 2,
+		},
+		{
+			name:    "ping failure",
+			options: []xnet.PoolOption{xnet.PoolPing(func(net.Conn) error { return errors.New("unhealthy") })},
+			use: func(tb testing.TB, p *xnet.Pool) {
+				getAndClose(tb, p, address)
+				getAndClose(tb, p, address)
+			},
+			expectedDials: 2,
+		},
+		{
+			name:    "max lifetime bytes exceeded",
+			options: []xnet.PoolOption{xnet.PoolMaxLifetimeBytes(8 * xunit.B)},
+			use: func(tb testing.TB, p *xnet.Pool) {
+				for i := 0; i < 3; i++ {
+					c := get(tb, p, address)
+					if _, err := io.WriteString(c, "ping"); err != nil {
+						tb.Fatalf("unexpected error: %v", err)
+					}
+					c.Close()
+				}
+			},
+			expectedDials: 2,
+		},
+		{
+			name: "discarded connection",
+			use: func(tb testing.TB, p *xnet.Pool) {
+				c := get(tb, p, address)
+				c.Discard()
+				getAndClose(tb, p, address)
+			},
+			expectedDials: 2,
+		},
+		{
+			name:    "max open reached",
+			options: []xnet.PoolOption{xnet.PoolMaxOpen(1)},
+			use: func(tb testing.TB, p *xnet.Pool) {
+				c := get(tb, p, address)
+
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+				defer cancel()
+				if _, err := p.Get(ctx, "tcp", address); !errors.Is(err, context.DeadlineExceeded) {
+					tb.Errorf("error mismatch: expected %v; got %v", context.DeadlineExceeded, err)
+				}
+
+				time.AfterFunc(10*time.Millisecond, func() { c.Close() })
+				getAndClose(tb, p, address)
+			},
+			expectedDials: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var dials atomic.Int32
+			options := append([]xnet.PoolOption{
+				xnet.PoolDial(func(ctx context.Context, network, address string) (net.Conn, error) {
+					dials.Add(1)
+					return xnet.DialContext(ctx, network, address)
+				}),
+			}, tc.options...)
+
+			p := xnet.NewPool(options...)
+			defer p.Close()
+
+			tc.use(t, p)
+
+			if n := dials.Load(); n != tc.expectedDials {
+				t.Errorf("dials mismatch: expected %d; got %d", tc.expectedDials, n)
+			}
+		})
+	}
+}
+
+func TestPool_Close(t *testing.T) {
+	ln, port, err := listenTCP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go serveEcho(ln)
+
+	p := xnet.NewPool()
+	getAndClose(t, p, "127.0.0.1:"+port)
+
+	if err := p.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := p.Get(context.Background(), "tcp", "127.0.0.1:"+port); !errors.Is(err, xnet.ErrPoolClosed) {
+		t.Errorf("error mismatch: expected %v; got %v", xnet.ErrPoolClosed, err)
+	}
+}
+
+func TestPool_IdleSweep(t *testing.T) {
+	ln, port, err := listenTCP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go serveEcho(ln)
+
+	var closes atomic.Int32
+	p := xnet.NewPool(
+		xnet.PoolIdleTimeout(10*time.Millisecond),
+		xnet.PoolDial(func(ctx context.Context, network, address string) (net.Conn, error) {
+			c, err := xnet.DialContext(ctx, network, address)
+			if err != nil {
+				return nil, err
+			}
+			return &closeCountingConn{Conn: c, closes: &closes}, nil
+		}),
+	)
+	defer p.Close()
+
+	// The address is not used anymore once the connection is released.
+	getAndClose(t, p, "127.0.0.1:"+port)
+
+	deadline := time.Now().Add(time.Second)
+	for closes.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := closes.Load(); n != 1 {
+		t.Errorf("closes mismatch: expected 1; got %d", n)
+	}
+}
+
+type closeCountingConn struct {
+	net.Conn
+
+	closes *atomic.Int32
+}
+
+func (c *closeCountingConn) Close() error {
+	c.closes.Add(1)
+	return c.Conn.Close()
+}
+
+func TestPoolOptions_Panic(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func()
+		panic bool
+	}{
+		{name: "nil dial", fn: func() { xnet.PoolDial(nil) }, panic: true},
+		{name: "negative idle timeout", fn: func() { xnet.PoolIdleTimeout(-1) }, panic: true},
+		{name: "negative max idle", fn: func() { xnet.PoolMaxIdle(-1) }, panic: true},
+		{name: "negative max lifetime bytes", fn: func() { xnet.PoolMaxLifetimeBytes(-1) }, panic: true},
+		{name: "negative max open", fn: func() { xnet.PoolMaxOpen(-1) }, panic: true},
+		{name: "nil ping", fn: func() { xnet.PoolPing(nil) }, panic: true},
+		{name: "valid", fn: func() { xnet.PoolMaxOpen(0) }, panic: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tc.panic {
+					t.Errorf("panic mismatch: expected %t; got %v", tc.panic, r)
+				}
+			}()
+			tc.fn()
+		})
+	}
+}
+
+func get(tb testing.TB, p *xnet.Pool, address string) *xnet.PoolConn {
+	tb.Helper()
+
+	c, err := p.Get(context.Background(), "tcp", address)
+	if err != nil {
+		tb.Fatalf("unexpected error: %v", err)
+	}
+	return c
+}
+
+func getAndClose(tb testing.TB, p *xnet.Pool, address string) {
+	tb.Helper()
+
+	if err := get(tb, p, address).Close(); err != nil {
+		tb.Fatalf("unexpected error: %v", err)
+	}
+}
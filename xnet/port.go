@@ -8,37 +8,162 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"math/rand"
 	"net"
 	"strconv"
 )
 
+var (
+	errReservationNoListener   = errors.New("no tcp listener held by the reservation")
+	errReservationNoPacketConn = errors.New("no udp connection held by the reservation")
+)
+
 // FreePort asks the kernel for a free open port, that is ready to use, on the specified Network.
 // Only TCP or UDP networks are supported.
+//
+// The port is released before FreePort returns, so it may be taken by another process before being used.
+// See ReservePort to hold it until then.
 func FreePort(ctx context.Context, network string, options ...ListenConfigOption) (int, error) {
+	r, err := ReservePort(ctx, network, options...)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Release()
+	return r.Port(), nil
+}
+
+// FreePortInRange returns a free open port within [first, last], that is ready to use, on the specified Network.
+// Ports are tried in a random order. Only TCP or UDP networks are supported.
+//
+// As with FreePort, the port is released before FreePortInRange returns.
+func FreePortInRange(ctx context.Context, network string, first, last int, options ...ListenConfigOption) (int, error) {
+	if first <= 0 || last > math.MaxUint16 || first > last {
+		return 0, fmt.Errorf("invalid port range: %d-%d", first, last)
+	}
+
+	n := last - first + 1
+	start := rand.Intn(n) //nolint:gosec // no need for a secure random number.
+	var lastErr error
+	for i := 0; i < n; i++ {
+		port := first + (start+i)%n
+		r, err := reservePort(ctx, network, port, options...)
+		if err == nil {
+			r.Release()
+			return port, nil
+		}
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		lastErr = err
+	}
+	return 0, fmt.Errorf("no free port in range %d-%d: %w", first, last, lastErr)
+}
+
+// Reservation is a port held open on the local host until it is released or handed off,
+// to avoid it being taken by another process in the meantime.
+type Reservation struct {
+	port     int
+	listener net.Listener
+	conn     net.PacketConn
+}
+
+// ReservePort asks the kernel for a free open port on the specified Network and holds it
+// until the returned Reservation is released or handed off. Only TCP or UDP networks are supported.
+func ReservePort(ctx context.Context, network string, options ...ListenConfigOption) (*Reservation, error) {
+	return reservePort(ctx, network, 0, options...)
+}
+
+// ReservePorts acts like ReservePort but reserves n distinct ports. Either all the ports are reserved
+// or none is. It panics if n is < 0.
+func ReservePorts(ctx context.Context, network string, n int, options ...ListenConfigOption) ([]*Reservation, error) {
+	if n < 0 {
+		panic("invalid number of ports value")
+	}
+
+	rs := make([]*Reservation, 0, n)
+	for len(rs) < n {
+		r, err := ReservePort(ctx, network, options...)
+		if err != nil {
+			for _, r := range rs {
+				r.Release()
+			}
+			return nil, err
+		}
+		rs = append(rs, r)
+	}
+	return rs, nil
+}
+
+func reservePort(ctx context.Context, network string, port int, options ...ListenConfigOption) (*Reservation, error) {
 	var lc net.ListenConfig
 
 	for _, option := range options {
 		option.apply(&lc)
 	}
 
+	address := JoinHostPortInt("localhost", port)
+
 	switch network {
 	case NetworkTCP, NetworkTCP4, NetworkTCP6:
-		listener, err := lc.Listen(ctx, network, "localhost:0")
+		listener, err := lc.Listen(ctx, network, address)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
-		defer listener.Close()
-		return listener.Addr().(*net.TCPAddr).Port, nil
+		return &Reservation{port: listener.Addr().(*net.TCPAddr).Port, listener: listener}, nil
 	case NetworkUDP, NetworkUDP4, NetworkUDP6:
-		listener, err := lc.ListenPacket(ctx, network, "localhost:0")
+		conn, err := lc.ListenPacket(ctx, network, address)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
-		defer listener.Close()
-		return listener.LocalAddr().(*net.UDPAddr).Port, nil
+		return &Reservation{port: conn.LocalAddr().(*net.UDPAddr).Port, conn: conn}, nil
 	default:
-		return 0, fmt.Errorf("invalid network: %s", network)
+		return nil, fmt.Errorf("invalid network: %s", network)
+	}
+}
+
+// Port returns the reserved port.
+func (r *Reservation) Port() int {
+	return r.port
+}
+
+// Release releases the port so that it can be bound, e.g. by a server started right after.
+// It is a no-op if the reservation has already been released or handed off.
+func (r *Reservation) Release() error {
+	switch {
+	case r.listener != nil:
+		err := r.listener.Close()
+		r.listener = nil
+		return err
+	case r.conn != nil:
+		err := r.conn.Close()
+		r.conn = nil
+		return err
+	default:
+		return nil
+	}
+}
+
+// Handoff returns the listener holding a TCP port, e.g. to be served by a server, transferring its
+// ownership to the caller without ever releasing the port. It fails if the port is not a TCP one or
+// if the reservation has already been released or handed off.
+func (r *Reservation) Handoff() (net.Listener, error) {
+	if r.listener == nil {
+		return nil, errReservationNoListener
+	}
+	l := r.listener
+	r.listener = nil
+	return l, nil
+}
+
+// HandoffPacket acts like Handoff for a UDP port.
+func (r *Reservation) HandoffPacket() (net.PacketConn, error) {
+	if r.conn == nil {
+		return nil, errReservationNoPacketConn
 	}
+	c := r.conn
+	r.conn = nil
+	return c, nil
 }
 
 // ParsePort parses a string representing a port.
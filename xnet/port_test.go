@@ -148,3 +148,127 @@ func TestParsePort(t *testing.T) {
 		})
 	}
 }
+
+func TestFreePortInRange(t *testing.T) {
+	r, err := xnet.ReservePort(context.Background(), xnet.NetworkTCP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+
+	testCases := []struct {
+		name         string
+		first, last  int
+		expectedPort int
+		expectedErr  bool
+	}{
+		{
+			name:        "invalid range",
+			first:       10,
+			last:        9,
+			expectedErr: true,
+		},
+		{
+			name:        "out of range",
+			first:       1,
+			last:        70000,
+			expectedErr: true,
+		},
+		{
+			name:        "reserved port",
+			first:       r.Port(),
+			last:        r.Port(),
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			port, err := xnet.FreePortInRange(context.Background(), xnet.NetworkTCP, tc.first, tc.last)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error is %t, got %v", tc.expectedErr, err)
+			}
+			if err == nil && (port < tc.first || port > tc.last) {
+				t.Errorf("port %d out of range %d-%d", port, tc.first, tc.last)
+			}
+		})
+	}
+
+	// The port is free again once released.
+	port := r.Port()
+	r.Release()
+	got, err := xnet.FreePortInRange(context.Background(), xnet.NetworkTCP, port, port)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != port {
+		t.Errorf("port mismatch: expected %d; got %d", port, got)
+	}
+}
+
+func TestReservePorts(t *testing.T) {
+	testCases := []struct {
+		name    string
+		network string
+	}{
+		{name: "tcp", network: xnet.NetworkTCP},
+		{name: "udp", network: xnet.NetworkUDP},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rs, err := xnet.ReservePorts(context.Background(), tc.network, 3)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			seen := make(map[int]bool)
+			for _, r := range rs {
+				if r.Port() == 0 || seen[r.Port()] {
+					t.Errorf("expected distinct non-zero ports; got %d", r.Port())
+				}
+				seen[r.Port()] = true
+
+				// A reserved port cannot be bound.
+				if _, err := xnet.FreePortInRange(context.Background(), tc.network, r.Port(), r.Port()); err == nil {
+					t.Errorf("expected port %d to be reserved", r.Port())
+				}
+			}
+
+			if tc.network == xnet.NetworkTCP {
+				if _, err := rs[0].HandoffPacket(); err == nil {
+					t.Error("expected an error handing off a tcp port as a packet conn")
+				}
+				l, err := rs[0].Handoff()
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				defer l.Close()
+				if _, err := rs[0].Handoff(); err == nil {
+					t.Error("expected an error handing off a port twice")
+				}
+			} else {
+				c, err := rs[0].HandoffPacket()
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				defer c.Close()
+			}
+
+			for _, r := range rs {
+				if err := r.Release(); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestReservePorts_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	xnet.ReservePorts(context.Background(), xnet.NetworkTCP, -1)
+}
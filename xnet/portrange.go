@@ -0,0 +1,123 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// PortRange is an inclusive range of ports, e.g. a pool of ports a service may listen on.
+// The zero value is an empty range.
+type PortRange struct {
+	// First is the first port of the range.
+	First int
+	// Last is the last port of the range.
+	Last int
+}
+
+// ParsePortRange parses a string representing a range of ports, in the form "first-last", e.g. "8000-8100",
+// or a single port, e.g. "8000". Ports must be valid as defined by ParsePort, without zero, and the first port
+// must not be greater than the last one, otherwise an error is returned.
+func ParsePortRange(s string) (PortRange, error) {
+	firstStr, lastStr, ok := strings.Cut(s, "-")
+	if !ok {
+		lastStr = firstStr
+	}
+
+	first, err := ParsePort(strings.TrimSpace(firstStr), false)
+	if err != nil {
+		return PortRange{}, fmt.Errorf("invalid port range %q: %w", s, err)
+	}
+	last, err := ParsePort(strings.TrimSpace(lastStr), false)
+	if err != nil {
+		return PortRange{}, fmt.Errorf("invalid port range %q: %w", s, err)
+	}
+	if first > last {
+		return PortRange{}, fmt.Errorf("invalid port range %q: first port is greater than last port", s)
+	}
+
+	return PortRange{First: first, Last: last}, nil
+}
+
+// Contains reports whether port is within the range.
+func (r PortRange) Contains(port int) bool {
+	return !r.IsEmpty() && port >= r.First && port <= r.Last
+}
+
+// IsEmpty reports whether the range contains no port.
+func (r PortRange) IsEmpty() bool {
+	return r.First <= 0 || r.First > r.Last
+}
+
+// Iterate calls fn for each port of the range in ascending order, until fn returns false.
+func (r PortRange) Iterate(fn func(port int) bool) {
+	if r.IsEmpty() {
+		return
+	}
+	for port := r.First; port <= r.Last; port++ {
+		if !fn(port) {
+			return
+		}
+	}
+}
+
+// Len returns the number of ports in the range.
+func (r PortRange) Len() int {
+	if r.IsEmpty() {
+		return 0
+	}
+	return r.Last - r.First + 1
+}
+
+// Random returns a random port of the range. It panics if the range is empty.
+func (r PortRange) Random() int {
+	if r.IsEmpty() {
+		panic("empty port range")
+	}
+	return r.First + rand.Intn(r.Len()) //nolint:gosec // no need for a secure random number.
+}
+
+// Get returns the PortRange value.
+// It makes PortRange implement the flag package Getter interface.
+func (r PortRange) Get() any { return r }
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// The encoding is the same as returned by String.
+func (r PortRange) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// Set parses the string in input and assign it to r if valid, otherwise an error is returned.
+// It makes PortRange implement the flag package Value interface.
+func (r *PortRange) Set(s string) error {
+	pr, err := ParsePortRange(s)
+	if err != nil {
+		return err
+	}
+	*r = pr
+	return nil
+}
+
+// String returns a string representation of PortRange in the form "first-last",
+// or "first" if the range contains a single port.
+func (r PortRange) String() string {
+	if r.First == r.Last {
+		return strconv.Itoa(r.First)
+	}
+	return strconv.Itoa(r.First) + "-" + strconv.Itoa(r.Last)
+}
+
+// Type returns a string representation of PortRange type.
+// It makes PortRange implement the pflag Value interface.
+func (PortRange) Type() string { return "xnet_port_range" }
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The text is expected in a form accepted by ParsePortRange.
+func (r *PortRange) UnmarshalText(text []byte) error {
+	return r.Set(string(text))
+}
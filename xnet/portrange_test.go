@@ -0,0 +1,108 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet_test
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet"
+)
+
+func TestParsePortRange(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         string
+		expectedRange xnet.PortRange
+		expectedErr   bool
+	}{
+		{name: "range", input: "8000-8100", expectedRange: xnet.PortRange{First: 8000, Last: 8100}},
+		{name: "range with spaces", input: "8000 - 8100", expectedRange: xnet.PortRange{First: 8000, Last: 8100}},
+		{name: "single port", input: "8000", expectedRange: xnet.PortRange{First: 8000, Last: 8000}},
+		{name: "empty", input: "", expectedErr: true},
+		{name: "zero port", input: "0-10", expectedErr: true},
+		{name: "out of range port", input: "8000-70000", expectedErr: true},
+		{name: "reversed", input: "8100-8000", expectedErr: true},
+		{name: "not a number", input: "a-b", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := xnet.ParsePortRange(tc.input)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error is %t, got %v", tc.expectedErr, err)
+			}
+			if r != tc.expectedRange {
+				t.Errorf("range mismatch: expected %v; got %v", tc.expectedRange, r)
+			}
+		})
+	}
+}
+
+func TestPortRange(t *testing.T) {
+	r := xnet.PortRange{First: 8000, Last: 8002}
+
+	if r.Len() != 3 {
+		t.Errorf("len mismatch: expected 3; got %d", r.Len())
+	}
+	for port, expected := range map[int]bool{7999: false, 8000: true, 8002: true, 8003: false} {
+		if r.Contains(port) != expected {
+			t.Errorf("contains %d mismatch: expected %t", port, expected)
+		}
+	}
+
+	var ports []int
+	r.Iterate(func(port int) bool {
+		ports = append(ports, port)
+		return port < 8001
+	})
+	if len(ports) != 2 || ports[0] != 8000 || ports[1] != 8001 {
+		t.Errorf("iterated ports mismatch: expected [8000 8001]; got %v", ports)
+	}
+
+	for i := 0; i < 100; i++ {
+		if port := r.Random(); !r.Contains(port) {
+			t.Fatalf("random port %d out of range %s", port, r)
+		}
+	}
+
+	var empty xnet.PortRange
+	if !empty.IsEmpty() || empty.Len() != 0 || empty.Contains(0) {
+		t.Error("expected the zero value to be an empty range")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+		empty.Random()
+	}()
+}
+
+func TestPortRange_Flag(t *testing.T) {
+	var r xnet.PortRange
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&r, "ports", "port range")
+	if err := fs.Parse([]string{"-ports", "9000-9010"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if r.String() != "9000-9010" {
+		t.Errorf("string mismatch: expected %q; got %q", "9000-9010", r.String())
+	}
+	if r.Get() != (xnet.PortRange{First: 9000, Last: 9010}) {
+		t.Errorf("value mismatch: got %v", r.Get())
+	}
+
+	b, err := xnet.PortRange{First: 80, Last: 80}.MarshalText()
+	if err != nil || string(b) != "80" {
+		t.Errorf("marshal mismatch: expected %q; got %q (%v)", "80", b, err)
+	}
+	if err := r.UnmarshalText([]byte("invalid")); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	proxySchemeHTTP    = "http"
+	proxySchemeHTTPS   = "https"
+	proxySchemeSOCKS5  = "socks5"
+	proxySchemeSOCKS5H = "socks5h"
+
+	socks5Version         = 0x05
+	socks5AuthNone        = 0x00
+	socks5AuthPassword    = 0x02
+	socks5PasswordVersion = 0x01
+	socks5CmdConnect      = 0x01
+	socks5AddrIPv4        = 0x01
+	socks5AddrDomain      = 0x03
+	socks5AddrIPv6        = 0x04
+	socks5Succeeded       = 0x00
+)
+
+// ErrProxy is returned when a proxy fails to establish a connection to the target address.
+var ErrProxy = errors.New("proxy error")
+
+var proxyLongTimeAgo = time.Unix(1, 0)
+
+// dialProxy connects to address through the proxy.
+func (d *Dialer) dialProxy(ctx context.Context, network, address string) (c net.Conn, err error) {
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+
+	proxyHost, proxyPort, err := SplitHostPortDefault(d.proxy.Host, proxyDefaultPort(d.proxy.Scheme))
+	if err != nil {
+		return nil, &net.OpError{Op: "dial", Net: network, Err: err}
+	}
+	proxyAddr := net.JoinHostPort(proxyHost, proxyPort)
+
+	c, err = d.dialDirect(ctx, NetworkTCP, proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			c.Close()
+		}
+	}()
+
+	// Abort the handshake once ctx is done.
+	stop := context.AfterFunc(ctx, func() {
+		c.SetDeadline(proxyLongTimeAgo) //nolint:errcheck // best effort.
+	})
+	defer func() {
+		if !stop() && err == nil {
+			err = ctx.Err()
+		}
+	}()
+
+	switch d.proxy.Scheme {
+	case proxySchemeSOCKS5, proxySchemeSOCKS5H:
+		err = d.socks5Connect(c, address)
+	case proxySchemeHTTPS:
+		tlsConn := tls.Client(c, &tls.Config{ServerName: d.proxy.Hostname()}) //nolint:gosec // the minimum version is the default one.
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			return c, err
+		}
+		c = tlsConn
+		c, err = d.httpConnect(c, address)
+	default:
+		c, err = d.httpConnect(c, address)
+	}
+	if err != nil {
+		return c, &net.OpError{Op: "dial", Net: network, Addr: proxyNetAddr(address), Err: ctxErrOr(ctx, err)}
+	}
+
+	return c, nil
+}
+
+// httpConnect asks an HTTP proxy to open a tunnel to address with the CONNECT method.
+func (d *Dialer) httpConnect(c net.Conn, address string) (net.Conn, error) {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if u := d.proxy.User; u != nil {
+		password, _ := u.Password()
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(u.Username()+":"+password)))
+	}
+
+	if err := req.Write(c); err != nil {
+		return c, err
+	}
+
+	br := bufio.NewReader(c)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return c, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return c, fmt.Errorf("%w: %s", ErrProxy, resp.Status)
+	}
+
+	// The proxy may have sent bytes from the target along with its response.
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: c, r: br}, nil
+	}
+	return c, nil
+}
+
+// socks5Connect asks a SOCKS5 proxy to connect to address, as defined by RFC 1928,
+// authenticating with a username and password if any, as defined by RFC 1929.
+func (d *Dialer) socks5Connect(c net.Conn, address string) error {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	port, err := ParsePort(portStr, false)
+	if err != nil {
+		return err
+	}
+
+	methods := []byte{socks5AuthNone}
+	if d.proxy.User != nil {
+		methods = append(methods, socks5AuthPassword)
+	}
+	if _, err := c.Write(append([]byte{socks5Version, byte(len(methods))}, methods...)); err != nil {
+		return err
+	}
+
+	var reply [2]byte
+	if _, err := io.ReadFull(c, reply[:]); err != nil {
+		return err
+	}
+	if reply[0] != socks5Version {
+		return fmt.Errorf("%w: unexpected socks version %d", ErrProxy, reply[0])
+	}
+
+	switch reply[1] {
+	case socks5AuthNone:
+	case socks5AuthPassword:
+		if d.proxy.User == nil {
+			return fmt.Errorf("%w: authentication required", ErrProxy)
+		}
+		username := d.proxy.User.Username()
+		password, _ := d.proxy.User.Password()
+		if len(username) > 255 || len(password) > 255 {
+			return fmt.Errorf("%w: credentials too long", ErrProxy)
+		}
+		auth := []byte{socks5PasswordVersion, byte(len(username))}
+		auth = append(auth, username...)
+		auth = append(auth, byte(len(password)))
+		auth = append(auth, password...)
+		if _, err := c.Write(auth); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(c, reply[:]); err != nil {
+			return err
+		}
+		if reply[1] != socks5Succeeded {
+			return fmt.Errorf("%w: authentication failed", ErrProxy)
+		}
+	default:
+		return fmt.Errorf("%w: no acceptable authentication method", ErrProxy)
+	}
+
+	req := []byte{socks5Version, socks5CmdConnect, 0}
+	ip := net.ParseIP(host)
+	switch {
+	case ip == nil:
+		if len(host) > 255 {
+			return fmt.Errorf("%w: host too long", ErrProxy)
+		}
+		req = append(req, socks5AddrDomain, byte(len(host)))
+		req = append(req, host...)
+	case ip.To4() != nil:
+		req = append(req, socks5AddrIPv4)
+		req = append(req, ip.To4()...)
+	default:
+		req = append(req, socks5AddrIPv6)
+		req = append(req, ip.To16()...)
+	}
+	req = binary.BigEndian.AppendUint16(req, uint16(port))
+	if _, err := c.Write(req); err != nil {
+		return err
+	}
+
+	var head [4]byte
+	if _, err := io.ReadFull(c, head[:]); err != nil {
+		return err
+	}
+	if head[1] != socks5Succeeded {
+		return fmt.Errorf("%w: connect failed with socks reply %d", ErrProxy, head[1])
+	}
+
+	// Discard the bound address and port.
+	var n int
+	switch head[3] {
+	case socks5AddrIPv4:
+		n = net.IPv4len
+	case socks5AddrIPv6:
+		n = net.IPv6len
+	case socks5AddrDomain:
+		var l [1]byte
+		if _, err := io.ReadFull(c, l[:]); err != nil {
+			return err
+		}
+		n = int(l[0])
+	default:
+		return fmt.Errorf("%w: unexpected socks address type %d", ErrProxy, head[3])
+	}
+	_, err = io.CopyN(io.Discard, c, int64(n)+2) //nolint:gomnd // port size.
+	return err
+}
+
+func proxyDefaultPort(scheme string) string {
+	switch scheme {
+	case proxySchemeHTTP:
+		return "80"
+	case proxySchemeHTTPS:
+		return "443"
+	default:
+		return "1080"
+	}
+}
+
+// proxyNetAddr is the net.Addr of the target address of a proxied connection.
+type proxyNetAddr string
+
+func (a proxyNetAddr) Network() string { return NetworkTCP }
+func (a proxyNetAddr) String() string  { return string(a) }
+
+// bufferedConn is a connection whose reads are served from r first.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// ctxErrOr returns the error of ctx if it is done, err otherwise.
+func ctxErrOr(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
@@ -0,0 +1,281 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet_test
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet"
+)
+
+// serveEcho accepts connections on ln and echoes back what they send.
+func serveEcho(ln net.Listener) {
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer c.Close()
+			io.Copy(c, c)
+		}()
+	}
+}
+
+// serveProxy accepts connections on ln and serves them with handshake, which returns the target address
+// to which the connection is then tunneled, or an empty string to reject it.
+func serveProxy(ln net.Listener, handshake func(c net.Conn, r *bufio.Reader) string) {
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer c.Close()
+			r := bufio.NewReader(c)
+			target := handshake(c, r)
+			if target == "" {
+				return
+			}
+			tc, err := net.Dial("tcp", target)
+			if err != nil {
+				return
+			}
+			defer tc.Close()
+			go io.Copy(tc, r)
+			io.Copy(c, tc)
+		}()
+	}
+}
+
+func httpConnectHandshake(auth string) func(c net.Conn, r *bufio.Reader) string {
+	return func(c net.Conn, r *bufio.Reader) string {
+		req, err := http.ReadRequest(r)
+		if err != nil || req.Method != http.MethodConnect {
+			return ""
+		}
+		if auth != "" && req.Header.Get("Proxy-Authorization") != "Basic "+base64.StdEncoding.EncodeToString([]byte(auth)) {
+			io.WriteString(c, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+			return ""
+		}
+		io.WriteString(c, "HTTP/1.1 200 Connection established\r\n\r\n")
+		return req.Host
+	}
+}
+
+func socks5Handshake(username, password string) func(c net.Conn, r *bufio.Reader) string {
+	return func(c net.Conn, r *bufio.Reader) string {
+		var head [2]byte
+		if _, err := io.ReadFull(r, head[:]); err != nil {
+			return ""
+		}
+		methods := make([]byte, head[1])
+		if _, err := io.ReadFull(r, methods); err != nil {
+			return ""
+		}
+
+		if username == "" {
+			c.Write([]byte{5, 0})
+		} else {
+			c.Write([]byte{5, 2})
+			var l [2]byte
+			if _, err := io.ReadFull(r, l[:]); err != nil {
+				return ""
+			}
+			u := make([]byte, l[1])
+			io.ReadFull(r, u)
+			io.ReadFull(r, l[:1])
+			p := make([]byte, l[0])
+			io.ReadFull(r, p)
+			if string(u) != username || string(p) != password {
+				c.Write([]byte{1, 1})
+				return ""
+			}
+			c.Write([]byte{1, 0})
+		}
+
+		var req [4]byte
+		if _, err := io.ReadFull(r, req[:]); err != nil {
+			return ""
+		}
+		var host string
+		switch req[3] {
+		case 1:
+			ip := make([]byte, 4)
+			io.ReadFull(r, ip)
+			host = net.IP(ip).String()
+		case 3:
+			var l [1]byte
+			io.ReadFull(r, l[:])
+			h := make([]byte, l[0])
+			io.ReadFull(r, h)
+			host = string(h)
+		default:
+			return ""
+		}
+		var port [2]byte
+		io.ReadFull(r, port[:])
+
+		c.Write([]byte{5, 0, 0, 1, 127, 0, 0, 1, 0, 0})
+		return xnet.JoinHostPortInt(host, int(binary.BigEndian.Uint16(port[:])))
+	}
+}
+
+func TestDialProxy(t *testing.T) {
+	target, targetPort, err := listenTCP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+	go serveEcho(target)
+
+	testCases := []struct {
+		name        string
+		handshake   func(c net.Conn, r *bufio.Reader) string
+		scheme      string
+		user        *url.Userinfo
+		address     string
+		expectedErr bool
+	}{
+		{
+			name:      "http connect",
+			handshake: httpConnectHandshake(""),
+			scheme:    "http",
+			address:   "127.0.0.1:" + targetPort,
+		},
+		{
+			name:      "http connect with authentication",
+			handshake: httpConnectHandshake("user:pass"),
+			scheme:    "http",
+			user:      url.UserPassword("user", "pass"),
+			address:   "localhost:" + targetPort,
+		},
+		{
+			name:        "http connect with wrong credentials",
+			handshake:   httpConnectHandshake("user:pass"),
+			scheme:      "http",
+			user:        url.UserPassword("user", "wrong"),
+			address:     "127.0.0.1:" + targetPort,
+			expectedErr: true,
+		},
+		{
+			name:      "socks5",
+			handshake: socks5Handshake("", ""),
+			scheme:    "socks5",
+			address:   "127.0.0.1:" + targetPort,
+		},
+		{
+			name:      "socks5 with authentication",
+			handshake: socks5Handshake("user", "pass"),
+			scheme:    "socks5h",
+			user:      url.UserPassword("user", "pass"),
+			address:   "localhost:" + targetPort,
+		},
+		{
+			name:        "socks5 with wrong credentials",
+			handshake:   socks5Handshake("user", "pass"),
+			scheme:      "socks5",
+			user:        url.UserPassword("user", "wrong"),
+			address:     "127.0.0.1:" + targetPort,
+			expectedErr: true,
+		},
+		{
+			name:        "socks5 without required credentials",
+			handshake:   socks5Handshake("user", "pass"),
+			scheme:      "socks5",
+			address:     "127.0.0.1:" + targetPort,
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ln, port, err := listenTCP()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ln.Close()
+			go serveProxy(ln, tc.handshake)
+
+			proxyURL := &url.URL{Scheme: tc.scheme, Host: "127.0.0.1:" + port, User: tc.user}
+			conn, err := xnet.Dial("tcp", tc.address, xnet.DialProxy(proxyURL), xnet.DialReadTimeout(100*time.Millisecond))
+			if tc.expectedErr {
+				if err == nil {
+					conn.Close()
+					t.Fatal("expected an error")
+				}
+				if !errors.Is(err, xnet.ErrProxy) {
+					t.Errorf("error mismatch: expected %v; got %v", xnet.ErrProxy, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer conn.Close()
+
+			if _, err := io.WriteString(conn, "ping"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			b := make([]byte, 4)
+			if _, err := io.ReadFull(conn, b); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(b) != "ping" {
+				t.Errorf("echo mismatch: expected %q; got %q", "ping", b)
+			}
+
+			// The read timeout applies to the proxied connection.
+			var netErr net.Error
+			if _, err := conn.Read(b); !errors.As(err, &netErr) || !netErr.Timeout() {
+				t.Errorf("expected a timeout error; got %v", err)
+			}
+		})
+	}
+}
+
+func TestDialProxy_Panic(t *testing.T) {
+	testCases := []struct {
+		name     string
+		proxyURL *url.URL
+		panic    bool
+	}{
+		{
+			name:     "nil",
+			proxyURL: nil,
+			panic:    true,
+		},
+		{
+			name:     "unsupported scheme",
+			proxyURL: &url.URL{Scheme: "ftp", Host: "localhost:21"},
+			panic:    true,
+		},
+		{
+			name:     "valid",
+			proxyURL: &url.URL{Scheme: "socks5", Host: "localhost:1080"},
+			panic:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tc.panic {
+					t.Errorf("panic mismatch: expected %t; got %v", tc.panic, r)
+				}
+			}()
+			xnet.DialProxy(tc.proxyURL)
+		})
+	}
+}
@@ -0,0 +1,382 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	proxyProtocolDefaultHeaderTimeout = 10 * time.Second
+	proxyProtocolV1MaxLength          = 107
+	proxyProtocolV2HeaderLength       = 16
+	proxyProtocolV2UnixAddrLength     = 108
+)
+
+// ErrProxyProtocol is returned by the connections accepted by a ProxyProtocolListener
+// when their PROXY protocol header is missing or invalid.
+var ErrProxyProtocol = errors.New("proxy protocol error")
+
+var (
+	proxyProtocolV1Prefix    = []byte("PROXY ")
+	proxyProtocolV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+)
+
+// ProxyProtocolPolicy defines how the PROXY protocol header of a connection is handled.
+type ProxyProtocolPolicy int
+
+// Enumeration of PROXY protocol policies.
+const (
+	// ProxyProtocolRequire requires the connection to start with a header.
+	ProxyProtocolRequire ProxyProtocolPolicy = iota
+	// ProxyProtocolAllow uses the header the connection starts with, if any.
+	ProxyProtocolAllow
+	// ProxyProtocolSkip does not look for a header, e.g. for untrusted sources.
+	ProxyProtocolSkip
+)
+
+// ProxyProtocolListener is a listener accepting connections from a proxy or load balancer which starts them
+// with a PROXY protocol header, in version 1 or 2, e.g. HAProxy or AWS NLB. The original client and destination
+// addresses it carries are returned by the RemoteAddr and LocalAddr methods of the connections.
+//
+// The header is read on the first call to Read, RemoteAddr or LocalAddr of a connection, not to block Accept.
+// When it is missing while required or invalid, Read fails with ErrProxyProtocol.
+type ProxyProtocolListener struct {
+	net.Listener
+
+	headerTimeout time.Duration
+	policy        func(src net.Addr) ProxyProtocolPolicy
+}
+
+// NewProxyProtocolListener returns a ProxyProtocolListener wrapping l, configured with the options passed
+// in input. By default, the header is required from any source and must be read within 10s.
+func NewProxyProtocolListener(l net.Listener, options ...ProxyProtocolListenerOption) *ProxyProtocolListener {
+	if l == nil {
+		panic("listener is nil")
+	}
+
+	pl := &ProxyProtocolListener{
+		Listener:      l,
+		headerTimeout: proxyProtocolDefaultHeaderTimeout,
+	}
+
+	for _, opt := range options {
+		opt.apply(pl)
+	}
+
+	return pl
+}
+
+// Accept waits for and returns the next connection to the listener, whose header is handled
+// according to the policy for its source.
+//
+// See net.Listener.Accept for more information.
+func (l *ProxyProtocolListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	policy := ProxyProtocolRequire
+	if l.policy != nil {
+		policy = l.policy(c.RemoteAddr())
+	}
+	if policy == ProxyProtocolSkip {
+		return c, nil
+	}
+
+	return &proxyProtocolConn{
+		Conn:          c,
+		r:             bufio.NewReader(c),
+		required:      policy == ProxyProtocolRequire,
+		headerTimeout: l.headerTimeout,
+	}, nil
+}
+
+type proxyProtocolConn struct {
+	net.Conn
+	r             *bufio.Reader
+	required      bool
+	headerTimeout time.Duration
+
+	once       sync.Once
+	err        error
+	remoteAddr net.Addr
+	localAddr  net.Addr
+
+	mu           sync.Mutex
+	readDeadline time.Time
+}
+
+// Read reads data from the connection, after its header.
+//
+// See net.Conn.Read for more information.
+func (c *proxyProtocolConn) Read(b []byte) (int, error) {
+	c.once.Do(c.readHeader)
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.r.Read(b)
+}
+
+// RemoteAddr returns the source address carried by the header, if any,
+// or the remote network address otherwise.
+//
+// See net.Conn.RemoteAddr for more information.
+func (c *proxyProtocolConn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.remoteAddr != nil {
+		return c.remoteAddr
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// LocalAddr returns the destination address carried by the header, if any,
+// or the local network address otherwise.
+//
+// See net.Conn.LocalAddr for more information.
+func (c *proxyProtocolConn) LocalAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.localAddr != nil {
+		return c.localAddr
+	}
+	return c.Conn.LocalAddr()
+}
+
+// SetDeadline sets the read and write deadlines of the connection.
+//
+// See net.Conn.SetDeadline for more information.
+func (c *proxyProtocolConn) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.readDeadline = t
+	return c.Conn.SetDeadline(t)
+}
+
+// SetReadDeadline sets the read deadline of the connection.
+//
+// See net.Conn.SetReadDeadline for more information.
+func (c *proxyProtocolConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.readDeadline = t
+	return c.Conn.SetReadDeadline(t)
+}
+
+// readHeader reads the header within the header timeout, then restores the read deadline.
+func (c *proxyProtocolConn) readHeader() {
+	if c.headerTimeout > 0 {
+		c.Conn.SetReadDeadline(time.Now().Add(c.headerTimeout)) //nolint:errcheck // reading fails anyway.
+		defer func() {
+			c.mu.Lock()
+			c.Conn.SetReadDeadline(c.readDeadline) //nolint:errcheck // reading fails anyway.
+			c.mu.Unlock()
+		}()
+	}
+
+	version, err := c.detectVersion()
+	switch {
+	case err != nil:
+		c.err = err
+	case version == 1:
+		c.err = c.readHeaderV1()
+	case version == 2:
+		c.err = c.readHeaderV2()
+	case c.required:
+		c.err = fmt.Errorf("%w: missing header", ErrProxyProtocol)
+	}
+}
+
+// detectVersion peeks at the first bytes of the connection, as few as needed, and returns
+// the version of its header, or 0 if it has none.
+func (c *proxyProtocolConn) detectVersion() (int, error) {
+	for n := 1; n <= len(proxyProtocolV2Signature); n++ {
+		b, err := c.r.Peek(n)
+		if err != nil {
+			return 0, fmt.Errorf("%w: reading header: %w", ErrProxyProtocol, err)
+		}
+
+		switch {
+		case n <= len(proxyProtocolV1Prefix) && bytes.Equal(b, proxyProtocolV1Prefix[:n]):
+			if n == len(proxyProtocolV1Prefix) {
+				return 1, nil
+			}
+		case bytes.Equal(b, proxyProtocolV2Signature[:n]):
+			if n == len(proxyProtocolV2Signature) {
+				return 2, nil
+			}
+		default:
+			return 0, nil
+		}
+	}
+	return 0, nil
+}
+
+// readHeaderV1 reads a human-readable header, e.g. "PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\r\n".
+func (c *proxyProtocolConn) readHeaderV1() error {
+	line, err := c.r.ReadSlice('\n')
+	if err != nil {
+		return fmt.Errorf("%w: reading header: %w", ErrProxyProtocol, err)
+	}
+	if len(line) > proxyProtocolV1MaxLength || !bytes.HasSuffix(line, []byte("\r\n")) {
+		return fmt.Errorf("%w: invalid header", ErrProxyProtocol)
+	}
+
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return fmt.Errorf("%w: invalid header", ErrProxyProtocol)
+	}
+
+	isIP4 := fields[1] == "TCP4"
+	src, dst := net.ParseIP(fields[2]), net.ParseIP(fields[3])
+	if src == nil || dst == nil || (src.To4() != nil) != isIP4 || (dst.To4() != nil) != isIP4 {
+		return fmt.Errorf("%w: invalid address", ErrProxyProtocol)
+	}
+	srcPort, err := ParsePort(fields[4], true)
+	if err != nil {
+		return fmt.Errorf("%w: invalid port: %w", ErrProxyProtocol, err)
+	}
+	dstPort, err := ParsePort(fields[5], true)
+	if err != nil {
+		return fmt.Errorf("%w: invalid port: %w", ErrProxyProtocol, err)
+	}
+
+	c.remoteAddr = &net.TCPAddr{IP: src, Port: srcPort}
+	c.localAddr = &net.TCPAddr{IP: dst, Port: dstPort}
+	return nil
+}
+
+// readHeaderV2 reads a binary header, skipping its TLVs.
+func (c *proxyProtocolConn) readHeaderV2() error {
+	var header [proxyProtocolV2HeaderLength]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		return fmt.Errorf("%w: reading header: %w", ErrProxyProtocol, err)
+	}
+
+	verCmd, family := header[12], header[13]
+	if verCmd>>4 != 2 {
+		return fmt.Errorf("%w: invalid version", ErrProxyProtocol)
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:]))
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return fmt.Errorf("%w: reading header: %w", ErrProxyProtocol, err)
+	}
+
+	switch verCmd & 0xf {
+	case 0x0: // LOCAL, e.g. health checks of the proxy itself.
+		return nil
+	case 0x1: // PROXY
+	default:
+		return fmt.Errorf("%w: invalid command", ErrProxyProtocol)
+	}
+
+	isDgram := family&0xf == 0x2
+	switch family >> 4 {
+	case 0x0: // AF_UNSPEC
+		return nil
+	case 0x1: // AF_INET
+		if len(payload) < 2*net.IPv4len+4 {
+			return fmt.Errorf("%w: invalid address", ErrProxyProtocol)
+		}
+		c.remoteAddr = proxyProtocolIPAddr(isDgram, payload[:4], payload[8:10])
+		c.localAddr = proxyProtocolIPAddr(isDgram, payload[4:8], payload[10:12])
+	case 0x2: // AF_INET6
+		if len(payload) < 2*net.IPv6len+4 {
+			return fmt.Errorf("%w: invalid address", ErrProxyProtocol)
+		}
+		c.remoteAddr = proxyProtocolIPAddr(isDgram, payload[:16], payload[32:34])
+		c.localAddr = proxyProtocolIPAddr(isDgram, payload[16:32], payload[34:36])
+	case 0x3: // AF_UNIX
+		if len(payload) < 2*proxyProtocolV2UnixAddrLength {
+			return fmt.Errorf("%w: invalid address", ErrProxyProtocol)
+		}
+		network := NetworkUnix
+		if isDgram {
+			network = NetworkUnixgram
+		}
+		src, dst := payload[:proxyProtocolV2UnixAddrLength], payload[proxyProtocolV2UnixAddrLength:]
+		c.remoteAddr = &net.UnixAddr{Name: proxyProtocolUnixName(src), Net: network}
+		c.localAddr = &net.UnixAddr{Name: proxyProtocolUnixName(dst[:proxyProtocolV2UnixAddrLength]), Net: network}
+	default:
+		return fmt.Errorf("%w: invalid address family", ErrProxyProtocol)
+	}
+	return nil
+}
+
+func proxyProtocolIPAddr(isDgram bool, ip, port []byte) net.Addr {
+	addrIP := net.IP(bytes.Clone(ip))
+	addrPort := int(binary.BigEndian.Uint16(port))
+	if isDgram {
+		return &net.UDPAddr{IP: addrIP, Port: addrPort}
+	}
+	return &net.TCPAddr{IP: addrIP, Port: addrPort}
+}
+
+func proxyProtocolUnixName(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+type (
+	// ProxyProtocolListenerOption configures the ProxyProtocolListener options
+	// when calling NewProxyProtocolListener.
+	ProxyProtocolListenerOption interface {
+		apply(l *ProxyProtocolListener)
+	}
+
+	funcProxyProtocolListenerOption struct {
+		fn func(*ProxyProtocolListener)
+	}
+)
+
+func newFuncProxyProtocolListenerOption(fn func(*ProxyProtocolListener)) funcProxyProtocolListenerOption {
+	return funcProxyProtocolListenerOption{
+		fn: fn,
+	}
+}
+
+func (o funcProxyProtocolListenerOption) apply(l *ProxyProtocolListener) {
+	o.fn(l)
+}
+
+// ProxyProtocolListenerHeaderTimeout returns a ProxyProtocolListenerOption that configures a timeout
+// for the header to be read. A zero value means no timeout. Value must be >= 0, otherwise it panics.
+func ProxyProtocolListenerHeaderTimeout(timeout time.Duration) ProxyProtocolListenerOption {
+	if timeout < 0 {
+		panic("invalid header timeout value")
+	}
+	return newFuncProxyProtocolListenerOption(func(l *ProxyProtocolListener) {
+		l.headerTimeout = timeout
+	})
+}
+
+// ProxyProtocolListenerPolicy returns a ProxyProtocolListenerOption that configures a function returning
+// the policy applied to a connection given its source, i.e. the address of the proxy, e.g. to trust
+// the headers of known proxies only.
+func ProxyProtocolListenerPolicy(fn func(src net.Addr) ProxyProtocolPolicy) ProxyProtocolListenerOption {
+	if fn == nil {
+		panic("policy function is nil")
+	}
+	return newFuncProxyProtocolListenerOption(func(l *ProxyProtocolListener) {
+		l.policy = fn
+	})
+}
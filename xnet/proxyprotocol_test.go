@@ -0,0 +1,226 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet"
+)
+
+func proxyProtocolV2Header(cmd, family byte, addrs []byte) []byte {
+	b := []byte("\r\n\r\n\x00\r\nQUIT\n")
+	b = append(b, 0x20|cmd, family)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(addrs)))
+	return append(b, addrs...)
+}
+
+func TestProxyProtocolListener(t *testing.T) {
+	v2TCP4 := []byte{192, 0, 2, 1, 192, 0, 2, 2, 0xdc, 0x04, 0x01, 0xbb}
+	v2TCP4WithTLV := append(append([]byte{}, v2TCP4...), 0x04, 0x00, 0x01, 0x00)
+
+	testCases := []struct {
+		name               string
+		policy             xnet.ProxyProtocolPolicy
+		header             []byte
+		expectedRemoteAddr string
+		expectedLocalAddr  string
+		expectedData       string
+		expectedErr        bool
+	}{
+		{
+			name:               "v1 tcp4",
+			header:             []byte("PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\r\n"),
+			expectedRemoteAddr: "192.0.2.1:56324",
+			expectedLocalAddr:  "192.0.2.2:443",
+			expectedData:       "hello",
+		},
+		{
+			name:               "v1 tcp6",
+			header:             []byte("PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\n"),
+			expectedRemoteAddr: "[2001:db8::1]:56324",
+			expectedLocalAddr:  "[2001:db8::2]:443",
+			expectedData:       "hello",
+		},
+		{
+			name:         "v1 unknown",
+			header:       []byte("PROXY UNKNOWN\r\n"),
+			expectedData: "hello",
+		},
+		{
+			name:        "v1 mismatched family",
+			header:      []byte("PROXY TCP4 2001:db8::1 192.0.2.2 56324 443\r\n"),
+			expectedErr: true,
+		},
+		{
+			name:        "v1 invalid port",
+			header:      []byte("PROXY TCP4 192.0.2.1 192.0.2.2 65536 443\r\n"),
+			expectedErr: true,
+		},
+		{
+			name:        "v1 missing fields",
+			header:      []byte("PROXY TCP4 192.0.2.1\r\n"),
+			expectedErr: true,
+		},
+		{
+			name:               "v2 tcp4",
+			header:             proxyProtocolV2Header(0x1, 0x11, v2TCP4),
+			expectedRemoteAddr: "192.0.2.1:56324",
+			expectedLocalAddr:  "192.0.2.2:443",
+			expectedData:       "hello",
+		},
+		{
+			name:               "v2 tcp4 with tlv",
+			header:             proxyProtocolV2Header(0x1, 0x11, v2TCP4WithTLV),
+			expectedRemoteAddr: "192.0.2.1:56324",
+			expectedLocalAddr:  "192.0.2.2:443",
+			expectedData:       "hello",
+		},
+		{
+			name:         "v2 local",
+			header:       proxyProtocolV2Header(0x0, 0x00, nil),
+			expectedData: "hello",
+		},
+		{
+			name:        "v2 truncated address",
+			header:      proxyProtocolV2Header(0x1, 0x11, v2TCP4[:8]),
+			expectedErr: true,
+		},
+		{
+			name:        "v2 invalid command",
+			header:      proxyProtocolV2Header(0x2, 0x11, v2TCP4),
+			expectedErr: true,
+		},
+		{
+			name:        "required header missing",
+			expectedErr: true,
+		},
+		{
+			name:         "allowed header missing",
+			policy:       xnet.ProxyProtocolAllow,
+			expectedData: "hello",
+		},
+		{
+			name:               "allowed header present",
+			policy:             xnet.ProxyProtocolAllow,
+			header:             []byte("PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\r\n"),
+			expectedRemoteAddr: "192.0.2.1:56324",
+			expectedLocalAddr:  "192.0.2.2:443",
+			expectedData:       "hello",
+		},
+		{
+			name:         "skipped header",
+			policy:       xnet.ProxyProtocolSkip,
+			header:       []byte("PROXY UNKNOWN\r\n"),
+			expectedData: "PROXY UNKNOWN\r\nhello",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ln, _, err := listenTCP()
+			if err != nil {
+				t.Fatal(err)
+			}
+			pl := xnet.NewProxyProtocolListener(ln, xnet.ProxyProtocolListenerPolicy(func(net.Addr) xnet.ProxyProtocolPolicy {
+				return tc.policy
+			}))
+			defer pl.Close()
+
+			client, err := net.Dial("tcp", ln.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+
+			if _, err = client.Write(append(tc.header, "hello"...)); err != nil {
+				t.Fatal(err)
+			}
+			client.(*net.TCPConn).CloseWrite()
+
+			c, err := pl.Accept()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+
+			data, err := io.ReadAll(c)
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error is %t, got %v", tc.expectedErr, err)
+			}
+			if err != nil && !errors.Is(err, xnet.ErrProxyProtocol) {
+				t.Errorf("error mismatch: expected %v; got %v", xnet.ErrProxyProtocol, err)
+			}
+			if string(data) != tc.expectedData {
+				t.Errorf("data mismatch: expected %q; got %q", tc.expectedData, data)
+			}
+
+			expectedRemoteAddr, expectedLocalAddr := tc.expectedRemoteAddr, tc.expectedLocalAddr
+			if expectedRemoteAddr == "" {
+				expectedRemoteAddr, expectedLocalAddr = client.LocalAddr().String(), client.RemoteAddr().String()
+			}
+			if got := c.RemoteAddr().String(); got != expectedRemoteAddr {
+				t.Errorf("remote address mismatch: expected %s; got %s", expectedRemoteAddr, got)
+			}
+			if got := c.LocalAddr().String(); got != expectedLocalAddr {
+				t.Errorf("local address mismatch: expected %s; got %s", expectedLocalAddr, got)
+			}
+		})
+	}
+}
+
+func TestProxyProtocolListener_HeaderTimeout(t *testing.T) {
+	ln, _, err := listenTCP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pl := xnet.NewProxyProtocolListener(ln, xnet.ProxyProtocolListenerHeaderTimeout(10*time.Millisecond))
+	defer pl.Close()
+
+	client, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	c, err := pl.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err = c.Read(make([]byte, 1)); !errors.Is(err, xnet.ErrProxyProtocol) {
+		t.Errorf("error mismatch: expected %v; got %v", xnet.ErrProxyProtocol, err)
+	}
+}
+
+func TestNewProxyProtocolListener_Panic(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func()
+		panic bool
+	}{
+		{name: "nil listener", fn: func() { xnet.NewProxyProtocolListener(nil) }, panic: true},
+		{name: "negative header timeout", fn: func() { xnet.ProxyProtocolListenerHeaderTimeout(-1) }, panic: true},
+		{name: "nil policy", fn: func() { xnet.ProxyProtocolListenerPolicy(nil) }, panic: true},
+		{name: "valid", fn: func() { xnet.ProxyProtocolListenerHeaderTimeout(time.Second) }, panic: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tc.panic {
+					t.Errorf("panic mismatch: expected %t; got %v", tc.panic, r)
+				}
+			}()
+			tc.fn()
+		})
+	}
+}
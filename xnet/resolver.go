@@ -0,0 +1,252 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	resolverDefaultTTL         = 30 * time.Second
+	resolverDefaultNegativeTTL = 5 * time.Second
+	resolverMaxEntries         = 10000
+)
+
+// HostResolver resolves hostnames to IP addresses. It is implemented by both *net.Resolver and *Resolver.
+type HostResolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// LookupInfo describes a completed hostname lookup made through a Resolver.
+type LookupInfo struct {
+	// Host is the hostname looked up.
+	Host string
+
+	// Addrs are the IP addresses the host resolved to.
+	Addrs []net.IPAddr
+
+	// Err is the error returned by the lookup, if any.
+	Err error
+
+	// Cached reports whether the result was served from the cache.
+	Cached bool
+
+	// Shared reports whether the result was shared with a concurrent lookup of the same host.
+	Shared bool
+
+	// Latency is the duration of the lookup.
+	Latency time.Duration
+}
+
+// Resolver is a caching wrapper around a HostResolver, net.DefaultResolver by default. Successful lookups are cached for a TTL and
+// lookups of hosts which are not found for a negative TTL, while concurrent lookups of the same
+// host are deduplicated into a single query.
+//
+// It is safe for concurrent use by multiple goroutines.
+type Resolver struct {
+	upstream    HostResolver
+	ttl         time.Duration
+	negativeTTL time.Duration
+	onLookup    func(ctx context.Context, info LookupInfo)
+
+	mu      sync.Mutex
+	entries map[string]resolverEntry
+	flights map[string]*resolverFlight
+}
+
+type resolverEntry struct {
+	addrs   []net.IPAddr
+	err     error
+	expires time.Time
+}
+
+type resolverFlight struct {
+	done  chan struct{}
+	addrs []net.IPAddr
+	err   error
+}
+
+// NewResolver creates a new Resolver configured with the options passed in input.
+// By default, net.DefaultResolver lookups are cached: successful lookups are cached for 30s and
+// lookups of hosts which are not found for 5s.
+func NewResolver(options ...ResolverOption) *Resolver {
+	r := &Resolver{
+		upstream:    net.DefaultResolver,
+		ttl:         resolverDefaultTTL,
+		negativeTTL: resolverDefaultNegativeTTL,
+		entries:     make(map[string]resolverEntry),
+		flights:     make(map[string]*resolverFlight),
+	}
+
+	for _, opt := range options {
+		opt.apply(r)
+	}
+
+	return r
+}
+
+// LookupIPAddr looks up host, returning its IP addresses from the cache when available.
+//
+// See net.Resolver.LookupIPAddr for more information.
+func (r *Resolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	start := time.Now()
+	info := LookupInfo{Host: host}
+
+	r.mu.Lock()
+	if e, ok := r.entries[host]; ok && time.Now().Before(e.expires) {
+		r.mu.Unlock()
+		info.Addrs, info.Err, info.Cached = slices.Clone(e.addrs), e.err, true
+		return r.done(ctx, start, info)
+	}
+
+	f, ok := r.flights[host]
+	if ok {
+		info.Shared = true
+	} else {
+		f = &resolverFlight{done: make(chan struct{})}
+		r.flights[host] = f
+		// The lookup is shared, so it must not be canceled along with the context of this caller.
+		go r.lookup(context.WithoutCancel(ctx), host, f)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-f.done:
+		info.Addrs, info.Err = slices.Clone(f.addrs), f.err
+	case <-ctx.Done():
+		info.Err = ctx.Err()
+	}
+	return r.done(ctx, start, info)
+}
+
+// Flush removes all the entries from the cache.
+func (r *Resolver) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	clear(r.entries)
+}
+
+func (r *Resolver) lookup(ctx context.Context, host string, f *resolverFlight) {
+	f.addrs, f.err = r.upstream.LookupIPAddr(ctx, host)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.flights, host)
+
+	var ttl time.Duration
+	var dnsErr *net.DNSError
+	switch {
+	case f.err == nil:
+		ttl = r.ttl
+	case errors.As(f.err, &dnsErr) && dnsErr.IsNotFound:
+		ttl = r.negativeTTL
+	}
+	if ttl > 0 {
+		r.evictLocked()
+		r.entries[host] = resolverEntry{addrs: f.addrs, err: f.err, expires: time.Now().Add(ttl)}
+	}
+
+	close(f.done)
+}
+
+// evictLocked makes room for a new entry once the cache is full, removing expired entries first.
+func (r *Resolver) evictLocked() {
+	if len(r.entries) < resolverMaxEntries {
+		return
+	}
+
+	now := time.Now()
+	for host, e := range r.entries {
+		if now.After(e.expires) {
+			delete(r.entries, host)
+		}
+	}
+	for host := range r.entries {
+		if len(r.entries) < resolverMaxEntries {
+			break
+		}
+		delete(r.entries, host)
+	}
+}
+
+func (r *Resolver) done(ctx context.Context, start time.Time, info LookupInfo) ([]net.IPAddr, error) {
+	info.Latency = time.Since(start)
+	if r.onLookup != nil {
+		r.onLookup(ctx, info)
+	}
+	return info.Addrs, info.Err
+}
+
+type (
+	// ResolverOption configures the Resolver options when calling NewResolver.
+	ResolverOption interface {
+		apply(r *Resolver)
+	}
+
+	funcResolverOption struct {
+		fn func(*Resolver)
+	}
+)
+
+func newFuncResolverOption(fn func(*Resolver)) funcResolverOption {
+	return funcResolverOption{
+		fn: fn,
+	}
+}
+
+func (o funcResolverOption) apply(r *Resolver) {
+	o.fn(r)
+}
+
+// ResolverNegativeTTL returns a ResolverOption that configures how long lookups of hosts which are not found
+// are cached. A zero value disables negative caching. Value must be >= 0, otherwise it panics.
+func ResolverNegativeTTL(ttl time.Duration) ResolverOption {
+	if ttl < 0 {
+		panic("invalid negative ttl value")
+	}
+	return newFuncResolverOption(func(r *Resolver) {
+		r.negativeTTL = ttl
+	})
+}
+
+// ResolverOnLookup returns a ResolverOption that configures a function called after each lookup,
+// e.g. to record cache hits and lookup latencies.
+func ResolverOnLookup(fn func(ctx context.Context, info LookupInfo)) ResolverOption {
+	if fn == nil {
+		panic("lookup function is nil")
+	}
+	return newFuncResolverOption(func(r *Resolver) {
+		r.onLookup = fn
+	})
+}
+
+// ResolverUpstream returns a ResolverOption that configures the resolver whose lookups are cached,
+// e.g. a *net.Resolver. If not used, net.DefaultResolver is used.
+func ResolverUpstream(upstream HostResolver) ResolverOption {
+	if upstream == nil {
+		panic("upstream resolver is nil")
+	}
+	return newFuncResolverOption(func(r *Resolver) {
+		r.upstream = upstream
+	})
+}
+
+// ResolverTTL returns a ResolverOption that configures how long successful lookups are cached.
+// A zero value disables caching. Value must be >= 0, otherwise it panics.
+func ResolverTTL(ttl time.Duration) ResolverOption {
+	if ttl < 0 {
+		panic("invalid ttl value")
+	}
+	return newFuncResolverOption(func(r *Resolver) {
+		r.ttl = ttl
+	})
+}
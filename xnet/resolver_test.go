@@ -0,0 +1,234 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet"
+)
+
+type fakeResolver struct {
+	addrs   []net.IPAddr
+	err     error
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (r *fakeResolver) LookupIPAddr(_ context.Context, _ string) ([]net.IPAddr, error) {
+	r.calls.Add(1)
+	if r.release != nil {
+		<-r.release
+	}
+	return r.addrs, r.err
+}
+
+func TestResolver_LookupIPAddr(t *testing.T) {
+	localhost := []net.IPAddr{{IP: net.IPv4(127, 0, 0, 1)}}
+	notFound := &net.DNSError{Err: "no such host", Name: "example.test", IsNotFound: true}
+	temporary := &net.DNSError{Err: "server misbehaving", Name: "example.test", IsTemporary: true}
+
+	testCases := []struct {
+		name          string
+		upstream      *fakeResolver
+		options       []xnet.ResolverOption
+		expectedAddrs []net.IPAddr
+		expectedErr   error
+		expectedCalls int32
+	}{
+		{
+			name:          "cached",
+			upstream:      &fakeResolver{addrs: localhost},
+			expectedAddrs: localhost,
+			expectedCalls: 1,
+		},
+		{
+			name:          "caching disabled",
+			upstream:      &fakeResolver{addrs: localhost},
+			options:       []xnet.ResolverOption{xnet.ResolverTTL(0)},
+			expectedAddrs: localhost,
+			expectedCalls: 2,
+		},
+		{
+			name:          "expired",
+			upstream:      &fakeResolver{addrs: localhost},
+			options:       []xnet.ResolverOption{xnet.ResolverTTL(time.Nanosecond)},
+			expectedAddrs: localhost,
+			expectedCalls: 2,
+		},
+		{
+			name:          "not found cached",
+			upstream:      &fakeResolver{err: notFound},
+			expectedErr:   notFound,
+			expectedCalls: 1,
+		},
+		{
+			name:          "negative caching disabled",
+			upstream:      &fakeResolver{err: notFound},
+			options:       []xnet.ResolverOption{xnet.ResolverNegativeTTL(0)},
+			expectedErr:   notFound,
+			expectedCalls: 2,
+		},
+		{
+			name:          "temporary error not cached",
+			upstream:      &fakeResolver{err: temporary},
+			expectedErr:   temporary,
+			expectedCalls: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var cached []bool
+			options := append([]xnet.ResolverOption{
+				xnet.ResolverUpstream(tc.upstream),
+				xnet.ResolverOnLookup(func(_ context.Context, info xnet.LookupInfo) {
+					cached = append(cached, info.Cached)
+				}),
+			}, tc.options...)
+			r := xnet.NewResolver(options...)
+
+			for i := 0; i < 2; i++ {
+				addrs, err := r.LookupIPAddr(context.Background(), "example.test")
+
+				if !errors.Is(err, tc.expectedErr) {
+					t.Errorf("lookup %d: error mismatch: expected %v; got %v", i, tc.expectedErr, err)
+				}
+				if !reflect.DeepEqual(tc.expectedAddrs, addrs) {
+					t.Errorf("lookup %d: addresses mismatch: expected %v; got %v", i, tc.expectedAddrs, addrs)
+				}
+			}
+
+			if calls := tc.upstream.calls.Load(); calls != tc.expectedCalls {
+				t.Errorf("upstream calls mismatch: expected %d; got %d", tc.expectedCalls, calls)
+			}
+			if expected := []bool{false, tc.expectedCalls == 1}; !reflect.DeepEqual(expected, cached) {
+				t.Errorf("cached mismatch: expected %v; got %v", expected, cached)
+			}
+		})
+	}
+}
+
+func TestResolver_LookupIPAddr_Concurrent(t *testing.T) {
+	upstream := &fakeResolver{
+		addrs:   []net.IPAddr{{IP: net.IPv4(127, 0, 0, 1)}},
+		release: make(chan struct{}),
+	}
+
+	var reused atomic.Int32
+	r := xnet.NewResolver(xnet.ResolverUpstream(upstream), xnet.ResolverOnLookup(func(_ context.Context, info xnet.LookupInfo) {
+		if info.Shared || info.Cached {
+			reused.Add(1)
+		}
+	}))
+
+	const lookups = 5
+	var wg sync.WaitGroup
+	wg.Add(lookups)
+	for i := 0; i < lookups; i++ {
+		go func() {
+			defer wg.Done()
+			if _, err := r.LookupIPAddr(context.Background(), "example.test"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+
+	for upstream.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(upstream.release)
+	wg.Wait()
+
+	if calls := upstream.calls.Load(); calls != 1 {
+		t.Errorf("upstream calls mismatch: expected 1; got %d", calls)
+	}
+	if n := reused.Load(); n != lookups-1 {
+		t.Errorf("reused lookups mismatch: expected %d; got %d", lookups-1, n)
+	}
+}
+
+func TestResolver_LookupIPAddr_Canceled(t *testing.T) {
+	upstream := &fakeResolver{release: make(chan struct{})}
+	defer close(upstream.release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := xnet.NewResolver(xnet.ResolverUpstream(upstream)).LookupIPAddr(ctx, "example.test"); !errors.Is(err, context.Canceled) {
+		t.Errorf("error mismatch: expected %v; got %v", context.Canceled, err)
+	}
+}
+
+func TestResolver_Flush(t *testing.T) {
+	upstream := &fakeResolver{addrs: []net.IPAddr{{IP: net.IPv4(127, 0, 0, 1)}}}
+	r := xnet.NewResolver(xnet.ResolverUpstream(upstream))
+
+	r.LookupIPAddr(context.Background(), "example.test")
+	r.Flush()
+	r.LookupIPAddr(context.Background(), "example.test")
+
+	if calls := upstream.calls.Load(); calls != 2 {
+		t.Errorf("upstream calls mismatch: expected 2; got %d", calls)
+	}
+}
+
+func TestDialResolver(t *testing.T) {
+	ln, port, err := listenTCP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	upstream := &fakeResolver{addrs: []net.IPAddr{{IP: net.IPv4(127, 0, 0, 1)}}}
+	conn, err := xnet.DialContext(context.Background(), xnet.NetworkTCP, net.JoinHostPort("example.test", port),
+		xnet.DialResolver(xnet.NewResolver(xnet.ResolverUpstream(upstream))))
+	if conn != nil {
+		defer conn.Close()
+	}
+
+	assertDial(t, false, conn, err)
+	if calls := upstream.calls.Load(); calls != 1 {
+		t.Errorf("upstream calls mismatch: expected 1; got %d", calls)
+	}
+}
+
+func TestResolverOptions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func()
+		panic bool
+	}{
+		{name: "dial resolver nil", fn: func() { xnet.DialResolver(nil) }, panic: true},
+		{name: "dial resolver valid", fn: func() { xnet.DialResolver(net.DefaultResolver) }, panic: false},
+		{name: "negative ttl invalid", fn: func() { xnet.ResolverNegativeTTL(-1) }, panic: true},
+		{name: "negative ttl valid", fn: func() { xnet.ResolverNegativeTTL(0) }, panic: false},
+		{name: "on lookup nil", fn: func() { xnet.ResolverOnLookup(nil) }, panic: true},
+		{name: "on lookup valid", fn: func() { xnet.ResolverOnLookup(func(context.Context, xnet.LookupInfo) {}) }, panic: false},
+		{name: "upstream nil", fn: func() { xnet.ResolverUpstream(nil) }, panic: true},
+		{name: "upstream valid", fn: func() { xnet.ResolverUpstream(net.DefaultResolver) }, panic: false},
+		{name: "ttl invalid", fn: func() { xnet.ResolverTTL(-1) }, panic: true},
+		{name: "ttl valid", fn: func() { xnet.ResolverTTL(0) }, panic: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tc.panic {
+					t.Errorf("panic mismatch: expected %t; got %v", tc.panic, r)
+				}
+			}()
+			tc.fn()
+		})
+	}
+}
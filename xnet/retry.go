@@ -0,0 +1,96 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet/xnettrace"
+)
+
+// Backoff returns the duration to wait after the given attempt, starting at 1, before making the next one.
+type Backoff func(attempt int) time.Duration
+
+// ConstantBackoff returns a Backoff waiting interval between attempts. Value must be >= 0, otherwise it panics.
+func ConstantBackoff(interval time.Duration) Backoff {
+	if interval < 0 {
+		panic("invalid interval value")
+	}
+	return func(int) time.Duration {
+		return interval
+	}
+}
+
+// ExponentialBackoff returns a Backoff waiting initial after the first attempt, the wait doubling after each
+// attempt up to maxInterval. Values must be > 0 and maxInterval must be >= initial, otherwise it panics.
+func ExponentialBackoff(initial, maxInterval time.Duration) Backoff {
+	if initial <= 0 {
+		panic("invalid initial interval value")
+	}
+	if maxInterval < initial {
+		panic("invalid max interval value")
+	}
+	return func(attempt int) time.Duration {
+		d := initial
+		for i := 1; i < attempt && d < maxInterval; i++ {
+			d *= 2
+		}
+		return min(d, maxInterval)
+	}
+}
+
+// dialRetry dials address until it succeeds, the error is not a transient one or the attempts are exhausted,
+// waiting between attempts following the backoff.
+func (d *Dialer) dialRetry(ctx context.Context, network, address string) (net.Conn, error) {
+	trace := xnettrace.ContextDialTrace(ctx)
+
+	for attempt := 1; ; attempt++ {
+		c, err := d.dialAttempt(ctx, trace, network, address, attempt)
+		if err == nil || attempt >= d.retryAttempts || !isTransientDialError(err) {
+			return c, err
+		}
+
+		wait := d.retryBackoff(attempt)
+		if trace != nil && trace.RetryWait != nil {
+			trace.RetryWait(xnettrace.RetryWaitInfo{Attempt: attempt, Err: err, Duration: wait})
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+func (d *Dialer) dialAttempt(
+	ctx context.Context, trace *xnettrace.DialTrace, network, address string, attempt int,
+) (net.Conn, error) {
+	if trace != nil && trace.DialStart != nil {
+		trace.DialStart(xnettrace.DialStartInfo{Network: network, Address: address, Attempt: attempt})
+	}
+
+	start := time.Now()
+	c, err := d.dial(ctx, network, address)
+
+	if trace != nil && trace.DialDone != nil {
+		info := xnettrace.DialDoneInfo{Network: network, Address: address, Attempt: attempt, Err: err, Duration: time.Since(start)}
+		if c != nil {
+			info.RemoteAddr = c.RemoteAddr()
+		}
+		trace.DialDone(info)
+	}
+
+	return c, err
+}
+
+// isTransientDialError reports whether err is a connection refusal, as returned while a server restarts.
+func isTransientDialError(err error) bool {
+	return isConnRefused(err)
+}
@@ -0,0 +1,13 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import "strings"
+
+// isConnRefused reports whether err is caused by a connection refusal.
+// Plan 9 reports network errors as plain strings, so the error message is matched.
+func isConnRefused(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "connection refused")
+}
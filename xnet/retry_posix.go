@@ -0,0 +1,17 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !plan9
+
+package xnet
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isConnRefused reports whether err is caused by a connection refusal.
+func isConnRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
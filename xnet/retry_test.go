@@ -0,0 +1,111 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet"
+)
+
+func TestDialRetry(t *testing.T) {
+	testCases := []struct {
+		name        string
+		options     []xnet.DialOption
+		expectedErr bool
+	}{
+		{
+			name:        "no retry",
+			expectedErr: true,
+		},
+		{
+			name:        "attempts exhausted",
+			options:     []xnet.DialOption{xnet.DialRetry(2, xnet.ConstantBackoff(time.Millisecond))},
+			expectedErr: true,
+		},
+		{
+			name:        "refusal retried",
+			options:     []xnet.DialOption{xnet.DialRetry(100, xnet.ConstantBackoff(10*time.Millisecond))},
+			expectedErr: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := xnet.ReservePort(context.Background(), xnet.NetworkTCP)
+			if err != nil {
+				t.Fatal(err)
+			}
+			address := xnet.JoinHostPortInt("127.0.0.1", r.Port())
+			r.Release()
+
+			// Start listening while the connection is being refused.
+			listening := make(chan net.Listener, 1)
+			timer := time.AfterFunc(50*time.Millisecond, func() {
+				ln, err := net.Listen("tcp", address)
+				if err != nil {
+					t.Error(err)
+				}
+				listening <- ln
+			})
+			defer func() {
+				if timer.Stop() {
+					return
+				}
+				if ln := <-listening; ln != nil {
+					ln.Close()
+				}
+			}()
+
+			c, err := xnet.Dial("tcp", address, tc.options...)
+			if err == nil {
+				c.Close()
+			}
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error is %t, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := xnet.ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+
+	expected := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond}
+	for i, e := range expected {
+		if d := backoff(i + 1); d != e {
+			t.Errorf("attempt %d: backoff mismatch: expected %s; got %s", i+1, e, d)
+		}
+	}
+}
+
+func TestDialRetry_Panic(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func()
+		panic bool
+	}{
+		{name: "zero attempts", fn: func() { xnet.DialRetry(0, xnet.ConstantBackoff(0)) }, panic: true},
+		{name: "nil backoff", fn: func() { xnet.DialRetry(1, nil) }, panic: true},
+		{name: "negative constant interval", fn: func() { xnet.ConstantBackoff(-1) }, panic: true},
+		{name: "zero initial interval", fn: func() { xnet.ExponentialBackoff(0, time.Second) }, panic: true},
+		{name: "max lower than initial", fn: func() { xnet.ExponentialBackoff(time.Second, time.Millisecond) }, panic: true},
+		{name: "valid", fn: func() { xnet.DialRetry(3, xnet.ExponentialBackoff(time.Millisecond, time.Second)) }, panic: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tc.panic {
+					t.Errorf("panic mismatch: expected %t; got %v", tc.panic, r)
+				}
+			}()
+			tc.fn()
+		})
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import "syscall"
+
+type controlFunc func(network, address string, c syscall.RawConn) error
+
+// chainControl returns a control calling first, if not nil, then next.
+func chainControl(first, next controlFunc) controlFunc {
+	if first == nil {
+		return next
+	}
+	return func(network, address string, c syscall.RawConn) error {
+		if err := first(network, address, c); err != nil {
+			return err
+		}
+		return next(network, address, c)
+	}
+}
+
+// sockoptControl returns a control calling set with the file descriptor of the socket.
+func sockoptControl(set func(fd uintptr) error) controlFunc {
+	return func(_, _ string, c syscall.RawConn) error {
+		var sockErr error
+		if err := c.Control(func(fd uintptr) {
+			sockErr = set(fd)
+		}); err != nil {
+			return err
+		}
+		return sockErr
+	}
+}
@@ -0,0 +1,11 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package xnet
+
+import "syscall"
+
+const sysSOReusePort = syscall.SO_REUSEPORT
@@ -0,0 +1,10 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux && (mips || mipsle || mips64 || mips64le)
+
+package xnet
+
+// SO_REUSEPORT is not defined by the syscall package on Linux.
+const sysSOReusePort = 0x200
@@ -0,0 +1,20 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !unix
+
+package xnet
+
+import (
+	"errors"
+	"runtime"
+)
+
+func setReuseAddr(uintptr) error {
+	return errors.New("so_reuseaddr not supported on " + runtime.GOOS)
+}
+
+func setReusePort(uintptr) error {
+	return errors.New("so_reuseport not supported on " + runtime.GOOS)
+}
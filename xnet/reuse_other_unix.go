@@ -0,0 +1,9 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build unix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+
+package xnet
+
+const sysSOReusePort = 0
@@ -0,0 +1,80 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"syscall"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet"
+)
+
+func TestListenConfigReuse(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("socket reuse options not tested on " + runtime.GOOS)
+	}
+
+	testCases := []struct {
+		name          string
+		options       []xnet.ListenConfigOption
+		expectedErr   bool
+		expectedReuse bool
+	}{
+		{
+			name:          "no option",
+			expectedReuse: false,
+		},
+		{
+			name:          "reuse addr",
+			options:       []xnet.ListenConfigOption{xnet.ListenConfigReuseAddr()},
+			expectedReuse: false,
+		},
+		{
+			name:          "reuse port",
+			options:       []xnet.ListenConfigOption{xnet.ListenConfigReuseAddr(), xnet.ListenConfigReusePort()},
+			expectedReuse: true,
+		},
+		{
+			name: "chained with a failing control",
+			options: []xnet.ListenConfigOption{
+				xnet.ListenConfigControl(func(string, string, syscall.RawConn) error {
+					return errors.New("always error")
+				}),
+				xnet.ListenConfigReusePort(),
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options := []xnet.ListenOption{xnet.ListenConfigOptions(tc.options...)}
+
+			l1, err := xnet.ListenContext(context.Background(), "tcp", "127.0.0.1:0", options...)
+			if tc.expectedErr {
+				if err == nil {
+					l1.Close()
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer l1.Close()
+
+			l2, err := xnet.ListenContext(context.Background(), "tcp", l1.Addr().String(), options...)
+			if err == nil {
+				l2.Close()
+			}
+			if reused := err == nil; reused != tc.expectedReuse {
+				t.Errorf("reuse mismatch: expected %t; got %v", tc.expectedReuse, err)
+			}
+		})
+	}
+}
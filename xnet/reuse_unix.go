@@ -0,0 +1,25 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build unix
+
+package xnet
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"syscall"
+)
+
+func setReuseAddr(fd uintptr) error {
+	return os.NewSyscallError("setsockopt so_reuseaddr", syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1))
+}
+
+func setReusePort(fd uintptr) error {
+	if sysSOReusePort == 0 {
+		return errors.New("so_reuseport not supported on " + runtime.GOOS)
+	}
+	return os.NewSyscallError("setsockopt so_reuseport", syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, sysSOReusePort, 1))
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/jlourenc/xgo/xunit"
+)
+
+// throttleBurstDivisor bounds the bursts of a throttled connection to 100ms worth of traffic.
+const throttleBurstDivisor = 10
+
+// ThrottleConn returns a connection wrapping c whose throughput is capped to readRate and writeRate bytes
+// per second, using token buckets allowing bursts of up to 100ms of traffic. A zero rate means no limit.
+// It panics if a rate is < 0.
+//
+// Reads are paced after data is received while writes are paced before data is sent.
+func ThrottleConn(c net.Conn, readRate, writeRate xunit.Byte) net.Conn {
+	if readRate < 0 {
+		panic("invalid read rate value")
+	}
+	if writeRate < 0 {
+		panic("invalid write rate value")
+	}
+	if readRate == 0 && writeRate == 0 {
+		return c
+	}
+	return &throttledConn{
+		Conn:  c,
+		read:  newTokenBucket(readRate),
+		write: newTokenBucket(writeRate),
+	}
+}
+
+type throttledConn struct {
+	net.Conn
+	read  *tokenBucket
+	write *tokenBucket
+}
+
+// Read reads data from the connection, waiting as needed to stay under the read rate.
+//
+// See net.Conn.Read for more information.
+func (c *throttledConn) Read(b []byte) (int, error) {
+	if c.read == nil {
+		return c.Conn.Read(b)
+	}
+
+	if len(b) > c.read.burst {
+		b = b[:c.read.burst]
+	}
+	n, err := c.Conn.Read(b)
+	time.Sleep(c.read.reserve(n))
+	return n, err
+}
+
+// Write writes data to the connection, waiting as needed to stay under the write rate.
+//
+// See net.Conn.Write for more information.
+func (c *throttledConn) Write(b []byte) (int, error) {
+	if c.write == nil {
+		return c.Conn.Write(b)
+	}
+
+	var written int
+	for len(b) > 0 {
+		chunk := b[:min(len(b), c.write.burst)]
+		time.Sleep(c.write.reserve(len(chunk)))
+
+		n, err := c.Conn.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		b = b[n:]
+	}
+	return written, nil
+}
+
+// tokenBucket is a token bucket rate limiter, where a token is a byte.
+type tokenBucket struct {
+	rate  float64 // Tokens per second.
+	burst int
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate xunit.Byte) *tokenBucket {
+	if rate == 0 {
+		return nil
+	}
+	burst := max(int(rate/throttleBurstDivisor), 1)
+	return &tokenBucket{
+		rate:   float64(rate),
+		burst:  burst,
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes n tokens from the bucket and returns how long to wait for them to be available.
+func (b *tokenBucket) reserve(n int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.rate, float64(b.burst))
+	b.last = now
+
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
@@ -0,0 +1,110 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet_test
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+func TestThrottleConn(t *testing.T) {
+	const size = 50 * xunit.KiB
+
+	testCases := []struct {
+		name        string
+		readRate    xunit.Byte
+		writeRate   xunit.Byte
+		minDuration time.Duration
+		maxDuration time.Duration
+	}{
+		{
+			name:        "unlimited",
+			maxDuration: 200 * time.Millisecond,
+		},
+		{
+			name:        "read rate",
+			readRate:    100 * xunit.KiB,
+			minDuration: 350 * time.Millisecond,
+			maxDuration: 2 * time.Second,
+		},
+		{
+			name:        "write rate",
+			writeRate:   100 * xunit.KiB,
+			minDuration: 350 * time.Millisecond,
+			maxDuration: 2 * time.Second,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer server.Close()
+
+			c := xnet.ThrottleConn(client, tc.readRate, tc.writeRate)
+			defer c.Close()
+
+			start := time.Now()
+
+			errc := make(chan error, 1)
+			var n int64
+			if tc.readRate > 0 {
+				go func() {
+					_, err := server.Write(make([]byte, size))
+					errc <- err
+				}()
+				n, _ = io.CopyN(io.Discard, c, int64(size))
+			} else {
+				go func() {
+					_, err := io.CopyN(io.Discard, server, int64(size))
+					errc <- err
+				}()
+				var written int
+				written, _ = c.Write(make([]byte, size))
+				n = int64(written)
+			}
+			if err := <-errc; err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			elapsed := time.Since(start)
+			if n != int64(size) {
+				t.Errorf("size mismatch: expected %d; got %d", size, n)
+			}
+			if elapsed < tc.minDuration || elapsed > tc.maxDuration {
+				t.Errorf("duration mismatch: expected within [%s, %s]; got %s", tc.minDuration, tc.maxDuration, elapsed)
+			}
+		})
+	}
+}
+
+func TestThrottleConn_Panic(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func()
+		panic bool
+	}{
+		{name: "negative read rate", fn: func() { xnet.ThrottleConn(nil, -1, 0) }, panic: true},
+		{name: "negative write rate", fn: func() { xnet.ThrottleConn(nil, 0, -1) }, panic: true},
+		{name: "negative dial read rate", fn: func() { xnet.DialThrottle(-1, 0) }, panic: true},
+		{name: "negative dial write rate", fn: func() { xnet.DialThrottle(0, -1) }, panic: true},
+		{name: "valid", fn: func() { xnet.DialThrottle(xunit.KiB, 0) }, panic: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tc.panic {
+					t.Errorf("panic mismatch: expected %t; got %v", tc.panic, r)
+				}
+			}()
+			tc.fn()
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+)
+
+// DialTLS acts like Dial but performs a TLS handshake once connected.
+// Optional DialOption parameters may be passed in to configure the Dialer, including TLS-specific ones.
+//
+// The returned net.Conn has a ConnectionState method, as tls.Conn.
+//
+// See tls.Dial for more information.
+func DialTLS(network, address string, options ...DialOption) (net.Conn, error) {
+	return DialTLSContext(context.Background(), network, address, options...)
+}
+
+// DialTLSContext acts like DialTLS but takes a context.Context.
+//
+// See tls.Dialer.DialContext for more information.
+func DialTLSContext(ctx context.Context, network, address string, options ...DialOption) (net.Conn, error) {
+	var d Dialer
+
+	for _, option := range options {
+		option.apply(&d)
+	}
+
+	return d.DialTLSContext(ctx, network, address)
+}
+
+// DialTLSContext connects to the address on the named network and performs a TLS handshake.
+// The connect timeout and deadline apply to the handshake too. If ServerName is not set in the TLS config,
+// it is set to the host of address, before any host mapping applies.
+//
+// The returned net.Conn has a ConnectionState method, as tls.Conn.
+func (d *Dialer) DialTLSContext(ctx context.Context, network, address string) (net.Conn, error) {
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+
+	c, err := d.dialConn(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	tc := tls.Client(c, d.tlsClientConfig(address))
+
+	hctx := ctx
+	if d.tlsHandshakeTimeout > 0 {
+		var hcancel context.CancelFunc
+		hctx, hcancel = context.WithTimeout(ctx, d.tlsHandshakeTimeout)
+		defer hcancel()
+	}
+	if err := tc.HandshakeContext(hctx); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	return &tlsConn{
+		conn: conn{Conn: tc, readTimeout: d.ReadTimeout, writeTimeout: d.WriteTimeout},
+		tc:   tc,
+	}, nil
+}
+
+func (d *Dialer) tlsClientConfig(address string) *tls.Config {
+	var cfg *tls.Config
+	if d.tlsConfig != nil {
+		cfg = d.tlsConfig.Clone()
+	} else {
+		cfg = &tls.Config{} //nolint:gosec // the minimum version is the default one.
+	}
+
+	if cfg.ServerName == "" {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			host = address
+		}
+		cfg.ServerName = host
+	}
+	if len(d.tlsNextProtos) > 0 {
+		cfg.NextProtos = d.tlsNextProtos
+	}
+
+	return cfg
+}
+
+type tlsConn struct {
+	conn
+	tc *tls.Conn
+}
+
+// ConnectionState returns basic TLS details about the connection.
+//
+// See tls.Conn.ConnectionState for more information.
+func (c *tlsConn) ConnectionState() tls.ConnectionState {
+	return c.tc.ConnectionState()
+}
@@ -0,0 +1,178 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet"
+)
+
+func TestDialTLS(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.NotFoundHandler())
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	_, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+
+	testCases := []struct {
+		name             string
+		address          string
+		options          []xnet.DialOption
+		expectedProtocol string
+		expectedErr      bool
+	}{
+		{
+			name:    "ip address",
+			address: "127.0.0.1:" + port,
+			options: []xnet.DialOption{xnet.DialTLSConfig(&tls.Config{RootCAs: roots})},
+		},
+		{
+			name:    "server name of mapped host",
+			address: "example.com:" + port,
+			options: []xnet.DialOption{
+				xnet.DialTLSConfig(&tls.Config{RootCAs: roots}),
+				xnet.DialHostMapping(map[string][]string{"example.com": {"127.0.0.1"}}),
+			},
+		},
+		{
+			name:    "alpn",
+			address: "127.0.0.1:" + port,
+			options: []xnet.DialOption{
+				xnet.DialTLSConfig(&tls.Config{RootCAs: roots}),
+				xnet.DialTLSNextProtos("h2", "http/1.1"),
+			},
+			expectedProtocol: "h2",
+		},
+		{
+			name:        "unknown authority",
+			address:     "127.0.0.1:" + port,
+			expectedErr: true,
+		},
+		{
+			name:    "server name mismatch",
+			address: "127.0.0.1:" + port,
+			options: []xnet.DialOption{
+				xnet.DialTLSConfig(&tls.Config{RootCAs: roots, ServerName: "example.org"}),
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := xnet.DialTLS("tcp", tc.address, tc.options...)
+			if tc.expectedErr {
+				if err == nil {
+					c.Close()
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer c.Close()
+
+			state := c.(interface{ ConnectionState() tls.ConnectionState }).ConnectionState()
+			if !state.HandshakeComplete {
+				t.Error("expected a complete handshake")
+			}
+			if state.NegotiatedProtocol != tc.expectedProtocol {
+				t.Errorf("protocol mismatch: expected %q; got %q", tc.expectedProtocol, state.NegotiatedProtocol)
+			}
+		})
+	}
+}
+
+func TestDialTLSContext_HandshakeTimeout(t *testing.T) {
+	ln, port, err := listenTCP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// Accept connections without ever answering the handshake.
+	go func() {
+		var conns []net.Conn
+		defer func() {
+			for _, c := range conns {
+				c.Close()
+			}
+		}()
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, c)
+		}
+	}()
+
+	testCases := []struct {
+		name    string
+		options []xnet.DialOption
+	}{
+		{
+			name:    "handshake timeout",
+			options: []xnet.DialOption{xnet.DialTLSHandshakeTimeout(20 * time.Millisecond)},
+		},
+		{
+			name:    "connect timeout",
+			options: []xnet.DialOption{xnet.DialConnectTimeout(20 * time.Millisecond)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := xnet.DialTLSContext(context.Background(), "tcp", "127.0.0.1:"+port, tc.options...)
+			if err == nil {
+				c.Close()
+				t.Fatal("expected an error")
+			}
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected a timeout error; got %v", err)
+			}
+		})
+	}
+}
+
+func TestDialTLSOptions_Panic(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func()
+		panic bool
+	}{
+		{name: "nil config", fn: func() { xnet.DialTLSConfig(nil) }, panic: true},
+		{name: "negative handshake timeout", fn: func() { xnet.DialTLSHandshakeTimeout(-1) }, panic: true},
+		{name: "valid", fn: func() { xnet.DialTLSHandshakeTimeout(time.Second) }, panic: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tc.panic {
+					t.Errorf("panic mismatch: expected %t; got %v", tc.panic, r)
+				}
+			}()
+			tc.fn()
+		})
+	}
+}
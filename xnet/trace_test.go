@@ -0,0 +1,104 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet"
+	"github.com/jlourenc/xgo/xnet/xnettrace"
+)
+
+func TestDialContext_Trace(t *testing.T) {
+	ln, port, err := listenTCP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	r, err := xnet.ReservePort(context.Background(), xnet.NetworkTCP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refusedPort := strconv.Itoa(r.Port())
+	r.Release()
+
+	testCases := []struct {
+		name           string
+		port           string
+		expectedEvents []string
+	}{
+		{
+			name: "success",
+			port: port,
+			expectedEvents: []string{
+				"dial start 1",
+				"resolve start example.test",
+				"resolve done example.test 1 <nil>",
+				"dial done 1 true <nil>",
+			},
+		},
+		{
+			name: "retried",
+			port: refusedPort,
+			expectedEvents: []string{
+				"dial start 1",
+				"resolve start example.test",
+				"resolve done example.test 1 <nil>",
+				"dial done 1 false refused",
+				"retry wait 1 1ms",
+				"dial start 2",
+				"resolve start example.test",
+				"resolve done example.test 1 <nil>",
+				"dial done 2 false refused",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var events []string
+			ctx := xnettrace.WithDialTrace(context.Background(), &xnettrace.DialTrace{
+				DialDone: func(i xnettrace.DialDoneInfo) {
+					errStr := "<nil>"
+					if i.Err != nil {
+						errStr = "refused"
+					}
+					events = append(events, fmt.Sprintf("dial done %d %t %s", i.Attempt, i.RemoteAddr != nil, errStr))
+				},
+				DialStart: func(i xnettrace.DialStartInfo) {
+					events = append(events, fmt.Sprintf("dial start %d", i.Attempt))
+				},
+				ResolveDone: func(i xnettrace.ResolveDoneInfo) {
+					events = append(events, fmt.Sprintf("resolve done %s %d %v", i.Host, len(i.Addrs), i.Err))
+				},
+				ResolveStart: func(i xnettrace.ResolveStartInfo) {
+					events = append(events, "resolve start "+i.Host)
+				},
+				RetryWait: func(i xnettrace.RetryWaitInfo) {
+					events = append(events, fmt.Sprintf("retry wait %d %s", i.Attempt, i.Duration))
+				},
+			})
+
+			c, err := xnet.DialContext(ctx, "tcp", net.JoinHostPort("example.test", tc.port),
+				xnet.DialResolver(&fakeResolver{addrs: []net.IPAddr{{IP: net.IPv4(127, 0, 0, 1)}}}),
+				xnet.DialRetry(2, xnet.ConstantBackoff(time.Millisecond)),
+			)
+			if err == nil {
+				c.Close()
+			}
+
+			if !reflect.DeepEqual(tc.expectedEvents, events) {
+				t.Errorf("events mismatch: expected %q; got %q", tc.expectedEvents, events)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// DialUDP acts like Dial for UDP networks but returns a connection which also implements net.PacketConn,
+// read and write timeouts applying to each ReadFrom and WriteTo call as well as to each Read and Write call.
+//
+// Options targeting stream connections, such as DialProxy, DialThrottle, DialCount or DialIdleTimeout, are ignored.
+//
+// See net.DialUDP for more information.
+func DialUDP(network, address string, options ...DialOption) (net.Conn, error) {
+	return DialUDPContext(context.Background(), network, address, options...)
+}
+
+// DialUDPContext acts like DialUDP but takes a context.Context.
+func DialUDPContext(ctx context.Context, network, address string, options ...DialOption) (net.Conn, error) {
+	if !isUDPNetwork(network) {
+		return nil, fmt.Errorf("invalid network: %s", network)
+	}
+
+	var d Dialer
+
+	for _, option := range options {
+		option.apply(&d)
+	}
+
+	c, err := d.dialDirect(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	return newUDPConn(c, d.ReadTimeout, d.WriteTimeout)
+}
+
+// ListenUDP acts like Listen for UDP networks, read and write timeouts applying to each ReadFrom and WriteTo call
+// on the returned net.PacketConn (a zero value means no timeout).
+//
+// See net.ListenUDP for more information.
+func ListenUDP(network, address string, options ...ListenOption) (net.PacketConn, error) {
+	return ListenUDPContext(context.Background(), network, address, options...)
+}
+
+// ListenUDPContext acts like ListenUDP but takes a context.Context.
+func ListenUDPContext(ctx context.Context, network, address string, options ...ListenOption) (net.PacketConn, error) {
+	if !isUDPNetwork(network) {
+		return nil, fmt.Errorf("invalid network: %s", network)
+	}
+
+	var lc ListenConfig
+
+	for _, option := range options {
+		option.apply(&lc)
+	}
+
+	c, err := lc.ListenConfig.ListenPacket(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	return newUDPConn(c, lc.ReadTimeout, lc.WriteTimeout)
+}
+
+func isUDPNetwork(network string) bool {
+	switch network {
+	case NetworkUDP, NetworkUDP4, NetworkUDP6:
+		return true
+	default:
+		return false
+	}
+}
+
+type udpConn struct {
+	*net.UDPConn
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+}
+
+func newUDPConn(c any, readTimeout, writeTimeout time.Duration) (*udpConn, error) {
+	uc, ok := c.(*net.UDPConn)
+	if !ok {
+		if closer, ok := c.(interface{ Close() error }); ok {
+			closer.Close()
+		}
+		return nil, fmt.Errorf("unexpected connection type: %T", c)
+	}
+	return &udpConn{UDPConn: uc, readTimeout: readTimeout, writeTimeout: writeTimeout}, nil
+}
+
+// Read reads a packet from the connection, applying the read timeout if any.
+//
+// See net.UDPConn.Read for more information.
+func (c *udpConn) Read(b []byte) (int, error) {
+	if err := c.setReadDeadline(); err != nil {
+		return 0, err
+	}
+	return c.UDPConn.Read(b)
+}
+
+// ReadFrom reads a packet from the connection, applying the read timeout if any.
+//
+// See net.UDPConn.ReadFrom for more information.
+func (c *udpConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	if err := c.setReadDeadline(); err != nil {
+		return 0, nil, err
+	}
+	return c.UDPConn.ReadFrom(b)
+}
+
+// Write writes a packet to the connection, applying the write timeout if any.
+//
+// See net.UDPConn.Write for more information.
+func (c *udpConn) Write(b []byte) (int, error) {
+	if err := c.setWriteDeadline(); err != nil {
+		return 0, err
+	}
+	return c.UDPConn.Write(b)
+}
+
+// WriteTo writes a packet to addr, applying the write timeout if any.
+//
+// See net.UDPConn.WriteTo for more information.
+func (c *udpConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	if err := c.setWriteDeadline(); err != nil {
+		return 0, err
+	}
+	return c.UDPConn.WriteTo(b, addr)
+}
+
+func (c *udpConn) setReadDeadline() error {
+	if c.readTimeout == 0 {
+		return nil
+	}
+	return c.UDPConn.SetReadDeadline(time.Now().Add(c.readTimeout))
+}
+
+func (c *udpConn) setWriteDeadline() error {
+	if c.writeTimeout == 0 {
+		return nil
+	}
+	return c.UDPConn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+}
@@ -0,0 +1,74 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet_test
+
+import (
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet"
+)
+
+func TestListenUDP_DialUDP(t *testing.T) {
+	server, err := xnet.ListenUDP("udp", "127.0.0.1:0", xnet.ListenReadTimeout(20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	client, err := xnet.DialUDP("udp", server.LocalAddr().String(), xnet.DialReadTimeout(20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if _, ok := client.(net.PacketConn); !ok {
+		t.Error("expected the dialed connection to implement net.PacketConn")
+	}
+
+	if _, err := client.Write([]byte("ping")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b := make([]byte, 16)
+	n, addr, err := server.ReadFrom(b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(b[:n]) != "ping" {
+		t.Errorf("packet mismatch: expected %q; got %q", "ping", b[:n])
+	}
+
+	if _, err := server.WriteTo([]byte("pong"), addr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	n, err = client.Read(b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(b[:n]) != "pong" {
+		t.Errorf("packet mismatch: expected %q; got %q", "pong", b[:n])
+	}
+
+	// Read timeouts apply on both sides.
+	if _, _, err := server.ReadFrom(b); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("error mismatch: expected %v; got %v", os.ErrDeadlineExceeded, err)
+	}
+	if _, err := client.Read(b); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("error mismatch: expected %v; got %v", os.ErrDeadlineExceeded, err)
+	}
+}
+
+func TestListenUDP_InvalidNetwork(t *testing.T) {
+	if _, err := xnet.ListenUDP("tcp", "127.0.0.1:0"); err == nil {
+		t.Error("expected an error")
+	}
+	if _, err := xnet.DialUDP("tcp", "127.0.0.1:1"); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"context"
+	"net"
+	"os"
+)
+
+// ListenUnix announces on the Unix domain socket at path, configured with the options passed in input,
+// taking care of the socket file:
+//   - a stale socket file left by a previous process is removed, while an error is returned if the socket is in use
+//     or if path is not a socket,
+//   - the file mode and ownership configured with ListenUnixMode and ListenUnixOwner are applied,
+//   - the socket file is removed when the listener is closed.
+//
+// Read and write timeouts are applied to the accepted connections as with Listen.
+func ListenUnix(path string, options ...ListenOption) (net.Listener, error) {
+	return ListenUnixContext(context.Background(), path, options...)
+}
+
+// ListenUnixContext acts like ListenUnix but takes a context.Context.
+func ListenUnixContext(ctx context.Context, path string, options ...ListenOption) (net.Listener, error) {
+	var lc ListenConfig
+
+	for _, option := range options {
+		option.apply(&lc)
+	}
+
+	if err := removeStaleUnixSocket(ctx, path); err != nil {
+		return nil, err
+	}
+
+	l, err := lc.ListenConfig.Listen(ctx, NetworkUnix, path)
+	if err != nil {
+		return nil, err
+	}
+	setUnlinkOnClose(l)
+
+	if err := lc.applyUnixFileOptions(path); err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	return lc.wrap(l), nil
+}
+
+func (lc *ListenConfig) applyUnixFileOptions(path string) error {
+	if lc.unixMode != nil {
+		if err := os.Chmod(path, *lc.unixMode); err != nil {
+			return err
+		}
+	}
+	if lc.unixOwner != nil {
+		if err := os.Chown(path, lc.unixOwner.uid, lc.unixOwner.gid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type unixOwner struct {
+	uid int
+	gid int
+}
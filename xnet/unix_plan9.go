@@ -0,0 +1,19 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"context"
+	"net"
+)
+
+// removeStaleUnixSocket is a no-op as Unix domain sockets are not supported on plan9,
+// so that listening fails with the error returned by the net package.
+func removeStaleUnixSocket(context.Context, string) error {
+	return nil
+}
+
+// setUnlinkOnClose is a no-op on plan9.
+func setUnlinkOnClose(net.Listener) {}
@@ -0,0 +1,111 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !plan9
+
+package xnet_test
+
+import (
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet"
+)
+
+func TestListenUnix(t *testing.T) {
+	testCases := []struct {
+		name        string
+		setup       func(tb testing.TB, path string) func()
+		expectedErr bool
+	}{
+		{
+			name:  "no file",
+			setup: func(testing.TB, string) func() { return func() {} },
+		},
+		{
+			name: "stale socket",
+			setup: func(tb testing.TB, path string) func() {
+				l, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+				if err != nil {
+					tb.Fatal(err)
+				}
+				l.SetUnlinkOnClose(false)
+				l.Close()
+				return func() {}
+			},
+		},
+		{
+			name: "socket in use",
+			setup: func(tb testing.TB, path string) func() {
+				l, err := net.Listen("unix", path)
+				if err != nil {
+					tb.Fatal(err)
+				}
+				return func() { l.Close() }
+			},
+			expectedErr: true,
+		},
+		{
+			name: "not a socket",
+			setup: func(tb testing.TB, path string) func() {
+				if err := os.WriteFile(path, nil, 0o600); err != nil {
+					tb.Fatal(err)
+				}
+				return func() {}
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Socket paths are limited in length, so avoid the longer paths of t.TempDir.
+			dir, err := os.MkdirTemp("", "xnet")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "test.sock")
+
+			defer tc.setup(t, path)()
+
+			l, err := xnet.ListenUnix(path, xnet.ListenUnixMode(0o660), xnet.ListenUnixOwner(-1, -1))
+			if tc.expectedErr {
+				if err == nil {
+					l.Close()
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			fi, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mode := fi.Mode().Perm(); mode != 0o660 {
+				t.Errorf("mode mismatch: expected %v; got %v", fs.FileMode(0o660), mode)
+			}
+
+			c, err := net.Dial("unix", path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			c.Close()
+
+			if err := l.Close(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("expected the socket file to be removed; got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	waitForAddrDefaultInitialInterval = 50 * time.Millisecond
+	waitForAddrDefaultMaxInterval     = time.Second
+	waitForAddrDefaultMultiplier      = 2
+)
+
+// WaitForAddrStats describes the attempts made by WaitForAddr.
+type WaitForAddrStats struct {
+	// Attempts is the number of connection attempts made.
+	Attempts int
+
+	// Elapsed is the time spent waiting for the address.
+	Elapsed time.Duration
+
+	// LastErr is the error of the last failed attempt, if any.
+	LastErr error
+}
+
+type waitForAddr struct {
+	initialInterval time.Duration
+	maxInterval     time.Duration
+	dialOptions     []DialOption
+}
+
+// WaitForAddr waits until address accepts connections on the named network, e.g. for a service to be
+// started, configured with the options passed in input. It dials address until a connection is established,
+// which is closed right away, waiting between attempts following an exponential backoff policy: the interval
+// starts at 50ms and doubles after each attempt, up to 1s.
+//
+// It fails with the error of ctx, wrapping the error of the last attempt, once ctx is done.
+func WaitForAddr(ctx context.Context, network, address string, options ...WaitForAddrOption) (WaitForAddrStats, error) {
+	w := &waitForAddr{
+		initialInterval: waitForAddrDefaultInitialInterval,
+		maxInterval:     waitForAddrDefaultMaxInterval,
+	}
+
+	for _, opt := range options {
+		opt.apply(w)
+	}
+
+	var d Dialer
+	for _, option := range w.dialOptions {
+		option.apply(&d)
+	}
+
+	var stats WaitForAddrStats
+	start := time.Now()
+	interval := w.initialInterval
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			stats.Elapsed = time.Since(start)
+			if stats.LastErr != nil {
+				return stats, fmt.Errorf("waiting for %s: %w: %w", address, ctx.Err(), stats.LastErr)
+			}
+			return stats, fmt.Errorf("waiting for %s: %w", address, ctx.Err())
+		case <-timer.C:
+		}
+
+		stats.Attempts++
+		c, err := d.DialContext(ctx, network, address)
+		if err == nil {
+			c.Close()
+			stats.Elapsed = time.Since(start)
+			stats.LastErr = nil
+			return stats, nil
+		}
+		stats.LastErr = err
+
+		timer.Reset(interval)
+		interval = min(interval*waitForAddrDefaultMultiplier, w.maxInterval)
+	}
+}
+
+type (
+	// WaitForAddrOption configures the WaitForAddr options when calling WaitForAddr.
+	WaitForAddrOption interface {
+		apply(w *waitForAddr)
+	}
+
+	funcWaitForAddrOption struct {
+		fn func(*waitForAddr)
+	}
+)
+
+func newFuncWaitForAddrOption(fn func(*waitForAddr)) funcWaitForAddrOption {
+	return funcWaitForAddrOption{
+		fn: fn,
+	}
+}
+
+func (o funcWaitForAddrOption) apply(w *waitForAddr) {
+	o.fn(w)
+}
+
+// WaitForAddrDialOptions returns a WaitForAddrOption that configures the Dialer used for each attempt,
+// e.g. with a connect timeout.
+func WaitForAddrDialOptions(options ...DialOption) WaitForAddrOption {
+	return newFuncWaitForAddrOption(func(w *waitForAddr) {
+		w.dialOptions = options
+	})
+}
+
+// WaitForAddrInitialInterval returns a WaitForAddrOption that configures the interval between the first
+// two attempts. Value must be > 0, otherwise it panics.
+func WaitForAddrInitialInterval(interval time.Duration) WaitForAddrOption {
+	if interval <= 0 {
+		panic("invalid initial interval value")
+	}
+	return newFuncWaitForAddrOption(func(w *waitForAddr) {
+		w.initialInterval = interval
+	})
+}
+
+// WaitForAddrMaxInterval returns a WaitForAddrOption that configures the max interval between two attempts.
+// Once reached, the interval is not increased. Value must be > 0, otherwise it panics.
+func WaitForAddrMaxInterval(interval time.Duration) WaitForAddrOption {
+	if interval <= 0 {
+		panic("invalid max interval value")
+	}
+	return newFuncWaitForAddrOption(func(w *waitForAddr) {
+		w.maxInterval = interval
+	})
+}
@@ -0,0 +1,105 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnet_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet"
+)
+
+func TestWaitForAddr(t *testing.T) {
+	r, err := xnet.ReservePort(context.Background(), xnet.NetworkTCP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := xnet.JoinHostPortInt("localhost", r.Port())
+	r.Release()
+
+	// Start listening after a few attempts.
+	var ln net.Listener
+	listening := make(chan struct{})
+	time.AfterFunc(60*time.Millisecond, func() {
+		defer close(listening)
+		ln, err = net.Listen("tcp", address)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stats, waitErr := xnet.WaitForAddr(ctx, "tcp", address, xnet.WaitForAddrInitialInterval(10*time.Millisecond))
+	<-listening
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	if waitErr != nil {
+		t.Fatalf("unexpected error: %v", waitErr)
+	}
+	if stats.Attempts < 2 {
+		t.Errorf("expected several attempts; got %d", stats.Attempts)
+	}
+	if stats.Elapsed < 50*time.Millisecond {
+		t.Errorf("expected to wait for the listener; waited %s", stats.Elapsed)
+	}
+	if stats.LastErr != nil {
+		t.Errorf("unexpected last error: %v", stats.LastErr)
+	}
+}
+
+func TestWaitForAddr_ContextDone(t *testing.T) {
+	r, err := xnet.ReservePort(context.Background(), xnet.NetworkTCP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := xnet.JoinHostPortInt("localhost", r.Port())
+	r.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	stats, err := xnet.WaitForAddr(ctx, "tcp", address,
+		xnet.WaitForAddrInitialInterval(10*time.Millisecond),
+		xnet.WaitForAddrMaxInterval(20*time.Millisecond),
+		xnet.WaitForAddrDialOptions(xnet.DialConnectTimeout(time.Second)),
+	)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error mismatch: expected %v; got %v", context.DeadlineExceeded, err)
+	}
+	if stats.LastErr == nil || !errors.Is(err, stats.LastErr) {
+		t.Errorf("expected the error to wrap the last attempt error %v; got %v", stats.LastErr, err)
+	}
+	if stats.Attempts < 3 {
+		t.Errorf("expected several attempts; got %d", stats.Attempts)
+	}
+}
+
+func TestWaitForAddrOptions_Panic(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func()
+		panic bool
+	}{
+		{name: "zero initial interval", fn: func() { xnet.WaitForAddrInitialInterval(0) }, panic: true},
+		{name: "zero max interval", fn: func() { xnet.WaitForAddrMaxInterval(0) }, panic: true},
+		{name: "valid", fn: func() { xnet.WaitForAddrMaxInterval(time.Second) }, panic: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tc.panic {
+					t.Errorf("panic mismatch: expected %t; got %v", tc.panic, r)
+				}
+			}()
+			tc.fn()
+		})
+	}
+}
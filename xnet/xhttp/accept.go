@@ -0,0 +1,167 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AcceptValue is a value of a content negotiation header, such as Accept or Accept-Encoding,
+// along with its parameters and weight.
+type AcceptValue struct {
+	// Value is the media range, e.g. "text/*", or the coding, e.g. "gzip", in lower case.
+	Value string
+
+	// Params are the media type parameters of a media range, if any, e.g. "charset".
+	Params map[string]string
+
+	// Q is the weight of the value, between 0 (not acceptable) and 1.
+	Q float64
+}
+
+// ParseAccept parses the Accept header and returns its media ranges ordered by decreasing weight,
+// more specific ranges first for equal weights. Invalid values are ignored.
+// It returns nil if the header does not exist.
+// https://datatracker.ietf.org/doc/html/rfc9110#section-12.5.1
+func ParseAccept(headers http.Header) []AcceptValue {
+	values := parseAcceptHeader(headers, HeaderAccept, true)
+	sort.SliceStable(values, func(i, j int) bool {
+		if values[i].Q != values[j].Q {
+			return values[i].Q > values[j].Q
+		}
+		return mediaRangeSpecificity(values[i]) > mediaRangeSpecificity(values[j])
+	})
+	return values
+}
+
+// ParseAcceptEncoding parses the Accept-Encoding header and returns its codings ordered by decreasing weight.
+// Invalid values are ignored. It returns nil if the header does not exist.
+// https://datatracker.ietf.org/doc/html/rfc9110#section-12.5.3
+func ParseAcceptEncoding(headers http.Header) []AcceptValue {
+	values := parseAcceptHeader(headers, HeaderAcceptEncoding, false)
+	sort.SliceStable(values, func(i, j int) bool {
+		return values[i].Q > values[j].Q
+	})
+	return values
+}
+
+// NegotiateContentType returns the offered media type best matching the Accept header of the request r,
+// offers being ordered by decreasing server preference. The weight of an offer is the one of the most
+// specific media range matching it. If the request has no Accept header, the first offer is returned.
+// It returns "" if no offer is acceptable.
+func NegotiateContentType(r *http.Request, offers ...string) string {
+	accepts := ParseAccept(r.Header)
+	if accepts == nil {
+		if len(offers) == 0 {
+			return ""
+		}
+		return offers[0]
+	}
+
+	var (
+		best  string
+		bestQ float64
+	)
+	for _, offer := range offers {
+		mediaType, params, err := mime.ParseMediaType(offer)
+		if err != nil {
+			continue
+		}
+
+		q, specificity := 0.0, -1
+		for _, a := range accepts {
+			if s := mediaRangeSpecificity(a); s > specificity && mediaRangeMatch(a, mediaType, params) {
+				q, specificity = a.Q, s
+			}
+		}
+
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+func parseAcceptHeader(headers http.Header, key string, mediaRange bool) []AcceptValue {
+	values := HeaderValues(headers, key)
+	if values == nil {
+		return nil
+	}
+
+	accepts := make([]AcceptValue, 0, len(values))
+	for _, v := range values {
+		fields := strings.Split(v, ";")
+
+		a := AcceptValue{
+			Value: strings.ToLower(strings.TrimSpace(fields[0])),
+			Q:     1,
+		}
+		if a.Value == "" || (mediaRange && strings.Count(a.Value, "/") != 1) {
+			continue
+		}
+
+		valid := true
+		for _, f := range fields[1:] {
+			name, value, _ := strings.Cut(f, "=")
+			name = strings.ToLower(strings.TrimSpace(name))
+			value = strings.Trim(strings.TrimSpace(value), `"`)
+
+			if name == "q" {
+				q, err := strconv.ParseFloat(value, 64)
+				if err != nil || q < 0 || q > 1 {
+					valid = false
+				}
+				a.Q = q
+				// Parameters following the weight are extensions, not media type parameters.
+				break
+			}
+
+			if mediaRange && name != "" {
+				if a.Params == nil {
+					a.Params = map[string]string{}
+				}
+				a.Params[name] = value
+			}
+		}
+
+		if valid {
+			accepts = append(accepts, a)
+		}
+	}
+	return accepts
+}
+
+// mediaRangeSpecificity ranks media ranges as per Section 12.5.1 of the RFC 9110:
+// */* < type/* < type/subtype < type/subtype;params.
+func mediaRangeSpecificity(a AcceptValue) int {
+	switch {
+	case a.Value == "*/*":
+		return 0
+	case strings.HasSuffix(a.Value, "/*"):
+		return 1
+	default:
+		return 2 + len(a.Params) //nolint:gomnd // ranks after wildcards.
+	}
+}
+
+func mediaRangeMatch(a AcceptValue, mediaType string, params map[string]string) bool {
+	if a.Value != "*/*" {
+		typ, subtype, _ := strings.Cut(a.Value, "/")
+		offerType, offerSubtype, _ := strings.Cut(mediaType, "/")
+		if typ != offerType || (subtype != "*" && subtype != offerSubtype) {
+			return false
+		}
+	}
+
+	for k, v := range a.Params {
+		if !strings.EqualFold(params[k], v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,195 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestParseAccept(t *testing.T) {
+	testCases := []struct {
+		name     string
+		headers  http.Header
+		expected []xhttp.AcceptValue
+	}{
+		{
+			name:     "no header",
+			headers:  http.Header{},
+			expected: nil,
+		},
+		{
+			name:    "single value",
+			headers: http.Header{xhttp.HeaderAccept: {"application/json"}},
+			expected: []xhttp.AcceptValue{
+				{Value: "application/json", Q: 1},
+			},
+		},
+		{
+			name:    "ordered by weight",
+			headers: http.Header{xhttp.HeaderAccept: {"text/html;q=0.5, application/json, text/plain;q=0.8"}},
+			expected: []xhttp.AcceptValue{
+				{Value: "application/json", Q: 1},
+				{Value: "text/plain", Q: 0.8},
+				{Value: "text/html", Q: 0.5},
+			},
+		},
+		{
+			name:    "ordered by specificity",
+			headers: http.Header{xhttp.HeaderAccept: {"*/*, text/*", "text/plain, text/plain;format=flowed"}},
+			expected: []xhttp.AcceptValue{
+				{Value: "text/plain", Params: map[string]string{"format": "flowed"}, Q: 1},
+				{Value: "text/plain", Q: 1},
+				{Value: "text/*", Q: 1},
+				{Value: "*/*", Q: 1},
+			},
+		},
+		{
+			name:    "extension parameters ignored",
+			headers: http.Header{xhttp.HeaderAccept: {`Text/HTML; Level="1"; q=0.7; ext=1`}},
+			expected: []xhttp.AcceptValue{
+				{Value: "text/html", Params: map[string]string{"level": "1"}, Q: 0.7},
+			},
+		},
+		{
+			name:    "invalid values ignored",
+			headers: http.Header{xhttp.HeaderAccept: {"text, text/html;q=2, text/plain;q=x, , application/json;q=0"}},
+			expected: []xhttp.AcceptValue{
+				{Value: "application/json", Q: 0},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xhttp.ParseAccept(tc.headers)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %v; got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseAcceptEncoding(t *testing.T) {
+	testCases := []struct {
+		name     string
+		headers  http.Header
+		expected []xhttp.AcceptValue
+	}{
+		{
+			name:     "no header",
+			headers:  http.Header{},
+			expected: nil,
+		},
+		{
+			name:     "empty header",
+			headers:  http.Header{xhttp.HeaderAcceptEncoding: {""}},
+			expected: []xhttp.AcceptValue{},
+		},
+		{
+			name:    "ordered by weight",
+			headers: http.Header{xhttp.HeaderAcceptEncoding: {"deflate;q=0.5, GZIP, br;q=0.8", "identity;q=0"}},
+			expected: []xhttp.AcceptValue{
+				{Value: "gzip", Q: 1},
+				{Value: "br", Q: 0.8},
+				{Value: "deflate", Q: 0.5},
+				{Value: "identity", Q: 0},
+			},
+		},
+		{
+			name:    "invalid values ignored",
+			headers: http.Header{xhttp.HeaderAcceptEncoding: {"gzip;q=-1, br;q=abc, *"}},
+			expected: []xhttp.AcceptValue{
+				{Value: "*", Q: 1},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xhttp.ParseAcceptEncoding(tc.headers)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %v; got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestNegotiateContentType(t *testing.T) {
+	testCases := []struct {
+		name     string
+		accept   []string
+		offers   []string
+		expected string
+	}{
+		{
+			name:     "no accept header",
+			offers:   []string{"application/json", "text/html"},
+			expected: "application/json",
+		},
+		{
+			name:     "no accept header and no offer",
+			expected: "",
+		},
+		{
+			name:     "exact match",
+			accept:   []string{"text/html"},
+			offers:   []string{"application/json", "text/html"},
+			expected: "text/html",
+		},
+		{
+			name:     "highest weight",
+			accept:   []string{"application/json;q=0.5, text/html"},
+			offers:   []string{"application/json", "text/html"},
+			expected: "text/html",
+		},
+		{
+			name:     "server preference on equal weights",
+			accept:   []string{"text/*"},
+			offers:   []string{"text/plain", "text/html"},
+			expected: "text/plain",
+		},
+		{
+			name:     "most specific range wins",
+			accept:   []string{"text/*, text/html;q=0"},
+			offers:   []string{"text/html", "text/plain"},
+			expected: "text/plain",
+		},
+		{
+			name:     "wildcard",
+			accept:   []string{"*/*;q=0.1"},
+			offers:   []string{"application/json"},
+			expected: "application/json",
+		},
+		{
+			name:     "range parameters",
+			accept:   []string{"text/plain;charset=utf-8"},
+			offers:   []string{"text/plain", "text/plain; charset=UTF-8"},
+			expected: "text/plain; charset=UTF-8",
+		},
+		{
+			name:     "not acceptable",
+			accept:   []string{"application/xml"},
+			offers:   []string{"application/json", "invalid"},
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			if tc.accept != nil {
+				r.Header[xhttp.HeaderAccept] = tc.accept
+			}
+
+			if got := xhttp.NegotiateContentType(r, tc.offers...); got != tc.expected {
+				t.Errorf("expected %q; got %q", tc.expected, got)
+			}
+		})
+	}
+}
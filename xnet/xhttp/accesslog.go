@@ -0,0 +1,153 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/jlourenc/xgo/xunit"
+)
+
+type (
+	// AccessLogEntry describes a request served by an HTTP handler.
+	AccessLogEntry struct {
+		// Method is the HTTP method of the request.
+		Method string
+
+		// Path is the path of the request URL.
+		Path string
+
+		// RemoteAddr is the network address that sent the request.
+		RemoteAddr string
+
+		// Status is the HTTP status code of the response.
+		Status int
+
+		// BytesWritten is the size of the response body written.
+		BytesWritten xunit.Byte
+
+		// Latency is the duration taken by the handler to serve the request.
+		Latency time.Duration
+
+		// RequestID is the ID of the request, if any (see RequestID).
+		RequestID string
+	}
+
+	// AccessLogger records access log entries, e.g. to a log or an analytics sink.
+	// Implementations must be safe for concurrent use by multiple goroutines.
+	AccessLogger interface {
+		LogAccess(ctx context.Context, entry AccessLogEntry)
+	}
+)
+
+// AccessLog returns a Middleware recording an access log entry for each request served by the next
+// handler, configured with the options passed in input. By default, entries are logged with slog.Default.
+//
+// The request ID is only available if the RequestID middleware is chained before AccessLog.
+func AccessLog(options ...AccessLogOption) Middleware {
+	al := &accessLog{}
+
+	for _, opt := range options {
+		opt.apply(al)
+	}
+
+	if al.logger == nil {
+		al.logger = NewSlogAccessLogger(nil)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w}
+
+			defer func() {
+				al.logger.LogAccess(r.Context(), AccessLogEntry{
+					Method:       r.Method,
+					Path:         r.URL.Path,
+					RemoteAddr:   r.RemoteAddr,
+					Status:       sw.Status(),
+					BytesWritten: xunit.Byte(sw.written),
+					Latency:      time.Since(start),
+					RequestID:    RequestIDFromContext(r.Context()),
+				})
+			}()
+
+			next.ServeHTTP(sw, r)
+		})
+	}
+}
+
+type accessLog struct {
+	logger AccessLogger
+}
+
+// slogAccessLogger is an AccessLogger logging entries with a slog.Logger.
+type slogAccessLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogAccessLogger creates an AccessLogger logging each entry with logger at info level.
+// If logger is nil, slog.Default is used.
+func NewSlogAccessLogger(logger *slog.Logger) AccessLogger {
+	return &slogAccessLogger{
+		logger: logger,
+	}
+}
+
+// LogAccess makes slogAccessLogger implement the AccessLogger interface.
+func (s *slogAccessLogger) LogAccess(ctx context.Context, entry AccessLogEntry) {
+	logger := s.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", entry.Method),
+		slog.String("path", entry.Path),
+		slog.String("remote_addr", entry.RemoteAddr),
+		slog.Int("status", entry.Status),
+		slog.Any("bytes_written", entry.BytesWritten),
+		slog.Duration("latency", entry.Latency),
+	}
+	if entry.RequestID != "" {
+		attrs = append(attrs, slog.String("request_id", entry.RequestID))
+	}
+
+	logger.LogAttrs(ctx, slog.LevelInfo, "http request", attrs...)
+}
+
+type (
+	// AccessLogOption configures the AccessLog middleware options when calling AccessLog.
+	AccessLogOption interface {
+		apply(al *accessLog)
+	}
+
+	funcAccessLogOption struct {
+		fn func(*accessLog)
+	}
+)
+
+func newFuncAccessLogOption(fn func(*accessLog)) funcAccessLogOption {
+	return funcAccessLogOption{
+		fn: fn,
+	}
+}
+
+func (o funcAccessLogOption) apply(al *accessLog) {
+	o.fn(al)
+}
+
+// AccessLogLogger returns an AccessLogOption that configures the logger recording access log entries.
+// Value must not be nil, otherwise it panics.
+func AccessLogLogger(logger AccessLogger) AccessLogOption {
+	if logger == nil {
+		panic("access logger is nil")
+	}
+	return newFuncAccessLogOption(func(al *accessLog) {
+		al.logger = logger
+	})
+}
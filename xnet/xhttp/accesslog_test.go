@@ -0,0 +1,181 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+type accessLoggerFunc func(ctx context.Context, entry xhttp.AccessLogEntry)
+
+func (f accessLoggerFunc) LogAccess(ctx context.Context, entry xhttp.AccessLogEntry) {
+	f(ctx, entry)
+}
+
+func TestAccessLog(t *testing.T) {
+	testCases := []struct {
+		name           string
+		handler        http.HandlerFunc
+		requestID      bool
+		expectedStatus int
+		expectedBytes  xunit.Byte
+	}{
+		{
+			name:           "no write",
+			handler:        func(http.ResponseWriter, *http.Request) {},
+			expectedStatus: http.StatusOK,
+			expectedBytes:  0,
+		},
+		{
+			name: "write body",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				io.WriteString(w, "hello")
+				io.WriteString(w, " world")
+			},
+			expectedStatus: http.StatusOK,
+			expectedBytes:  11,
+		},
+		{
+			name: "write status",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				w.WriteHeader(http.StatusInternalServerError)
+				io.WriteString(w, "not found")
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBytes:  9,
+		},
+		{
+			name: "informational status ignored",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusEarlyHints)
+				w.WriteHeader(http.StatusCreated)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBytes:  0,
+		},
+		{
+			name:           "with request ID",
+			handler:        func(http.ResponseWriter, *http.Request) {},
+			requestID:      true,
+			expectedStatus: http.StatusOK,
+			expectedBytes:  0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var entry xhttp.AccessLogEntry
+			mws := []xhttp.Middleware{
+				xhttp.AccessLog(xhttp.AccessLogLogger(accessLoggerFunc(func(_ context.Context, e xhttp.AccessLogEntry) {
+					entry = e
+				}))),
+			}
+			if tc.requestID {
+				mws = append([]xhttp.Middleware{xhttp.RequestID()}, mws...)
+			}
+			h := xhttp.Chain(mws...).Then(tc.handler)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/path?q=1", http.NoBody)
+			h.ServeHTTP(rec, req)
+
+			if entry.Method != http.MethodPost {
+				t.Errorf("method mismatch: expected %s; got %s", http.MethodPost, entry.Method)
+			}
+			if entry.Path != "/path" {
+				t.Errorf("path mismatch: expected /path; got %s", entry.Path)
+			}
+			if entry.RemoteAddr != req.RemoteAddr {
+				t.Errorf("remote address mismatch: expected %s; got %s", req.RemoteAddr, entry.RemoteAddr)
+			}
+			if entry.Status != tc.expectedStatus {
+				t.Errorf("status mismatch: expected %d; got %d", tc.expectedStatus, entry.Status)
+			}
+			if entry.BytesWritten != tc.expectedBytes {
+				t.Errorf("bytes written mismatch: expected %d; got %d", tc.expectedBytes, entry.BytesWritten)
+			}
+			if entry.Latency <= 0 {
+				t.Errorf("expected positive latency; got %v", entry.Latency)
+			}
+			if expected := rec.Header().Get(xhttp.HeaderXRequestID); entry.RequestID != expected {
+				t.Errorf("request ID mismatch: expected %q; got %q", expected, entry.RequestID)
+			}
+		})
+	}
+}
+
+func TestAccessLog_Flush(t *testing.T) {
+	h := xhttp.AccessLog(xhttp.AccessLogLogger(accessLoggerFunc(func(context.Context, xhttp.AccessLogEntry) {}))).
+		ThenFunc(func(w http.ResponseWriter, _ *http.Request) {
+			f, ok := w.(http.Flusher)
+			if !ok {
+				t.Fatal("expected http.Flusher")
+			}
+			f.Flush()
+		})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	if !rec.Flushed {
+		t.Error("expected response to be flushed")
+	}
+}
+
+func TestNewSlogAccessLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := xhttp.NewSlogAccessLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	logger.LogAccess(context.Background(), xhttp.AccessLogEntry{
+		Method:       http.MethodGet,
+		Path:         "/path",
+		Status:       http.StatusOK,
+		BytesWritten: 2 * xunit.KB,
+		RequestID:    "id",
+	})
+
+	for _, s := range []string{"http request", "method=GET", "path=/path", "status=200", "bytes_written=2KB", "request_id=id"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("expected %q in log %q", s, buf.String())
+		}
+	}
+}
+
+func TestAccessLogLogger(t *testing.T) {
+	testCases := []struct {
+		name   string
+		logger xhttp.AccessLogger
+		panic  bool
+	}{
+		{
+			name:   "panic",
+			logger: nil,
+			panic:  true,
+		},
+		{
+			name:   "valid",
+			logger: xhttp.NewSlogAccessLogger(nil),
+			panic:  false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, func() xhttp.AccessLogOption {
+				return xhttp.AccessLogLogger(tc.logger)
+			})
+		})
+	}
+}
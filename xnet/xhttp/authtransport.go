@@ -0,0 +1,124 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jlourenc/xgo/xio"
+)
+
+var errNoToken = errors.New("no token provided by token source")
+
+// AuthTransport is an HTTP transport that authenticates requests with tokens provided by a TokenSource.
+type authTransport struct {
+	next   http.RoundTripper
+	source TokenSource
+}
+
+// NewAuthTransport creates a new AuthTransport authenticating requests with the tokens provided by source,
+// configured with the options passed in input, notably the next round tripper in the chain.
+// Source must not be nil, otherwise it panics.
+func NewAuthTransport(source TokenSource, options ...AuthTransportOption) http.RoundTripper {
+	if source == nil {
+		panic("token source is nil")
+	}
+
+	t := &authTransport{
+		next:   http.DefaultTransport,
+		source: source,
+	}
+
+	for _, opt := range options {
+		opt.apply(t)
+	}
+
+	return t
+}
+
+// RoundTrip makes AuthTransport implement the RoundTripper interface.
+//
+// The Authorization header of the request is set from the token provided by the source.
+// If the response status is 401 Unauthorized and the source implements TokenInvalidator,
+// the token is invalidated and the request, if rewindable, sent once more with a new token.
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	token, err := t.source.Token(ctx)
+	if err == nil && token == nil {
+		err = errNoToken
+	}
+	if err != nil {
+		closeRequestBody(req)
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(authRequest(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !isRequestRewindable(req) {
+		return resp, err
+	}
+
+	invalidator, ok := t.source.(TokenInvalidator)
+	if !ok {
+		return resp, nil
+	}
+	invalidator.InvalidateToken(token)
+
+	newToken, terr := t.source.Token(ctx)
+	if terr != nil || newToken == nil || newToken.String() == token.String() {
+		return resp, nil
+	}
+
+	r := authRequest(req, newToken)
+	if req.GetBody != nil {
+		body, gerr := req.GetBody()
+		if gerr != nil {
+			return resp, nil
+		}
+		r.Body = body
+	}
+
+	xio.DrainClose(resp.Body)
+	return t.next.RoundTrip(r)
+}
+
+// authRequest returns a copy of req authenticated with token.
+func authRequest(req *http.Request, token *Token) *http.Request {
+	r := req.Clone(req.Context())
+	r.Header.Set(HeaderAuthorization, token.String())
+	return r
+}
+
+type (
+	// AuthTransportOption configures the AuthTransport options when calling NewAuthTransport.
+	AuthTransportOption interface {
+		apply(t *authTransport)
+	}
+
+	funcAuthTransportOption struct {
+		fn func(*authTransport)
+	}
+)
+
+func newFuncAuthTransportOption(fn func(*authTransport)) funcAuthTransportOption {
+	return funcAuthTransportOption{
+		fn: fn,
+	}
+}
+
+func (o funcAuthTransportOption) apply(t *authTransport) {
+	o.fn(t)
+}
+
+// AuthTransportNextRoundTripper returns an AuthTransportOption that configures the
+// next round tripper to call. If not used http.DefaultTransport will be used.
+func AuthTransportNextRoundTripper(next http.RoundTripper) AuthTransportOption {
+	if next == nil {
+		panic("next http.RoundTripper is nil")
+	}
+	return newFuncAuthTransportOption(func(t *authTransport) {
+		t.next = next
+	})
+}
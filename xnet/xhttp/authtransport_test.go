@@ -0,0 +1,209 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestAuthTransport_RoundTrip(t *testing.T) {
+	testCases := []struct {
+		name             string
+		refresh          bool
+		validTokens      map[string]bool
+		body             io.Reader
+		expectedStatus   int
+		expectedRequests int
+	}{
+		{
+			name:             "authorized",
+			validTokens:      map[string]bool{"Bearer token1": true},
+			expectedStatus:   http.StatusOK,
+			expectedRequests: 1,
+		},
+		{
+			name:             "unauthorized with static source",
+			validTokens:      map[string]bool{},
+			expectedStatus:   http.StatusUnauthorized,
+			expectedRequests: 1,
+		},
+		{
+			name:             "unauthorized then refreshed",
+			refresh:          true,
+			validTokens:      map[string]bool{"Bearer token2": true},
+			body:             strings.NewReader("body"),
+			expectedStatus:   http.StatusOK,
+			expectedRequests: 2,
+		},
+		{
+			name:             "unauthorized after refresh",
+			refresh:          true,
+			validTokens:      map[string]bool{},
+			expectedStatus:   http.StatusUnauthorized,
+			expectedRequests: 2,
+		},
+		{
+			name:             "unauthorized with non rewindable body",
+			refresh:          true,
+			validTokens:      map[string]bool{"Bearer token2": true},
+			body:             io.MultiReader(strings.NewReader("body")),
+			expectedStatus:   http.StatusUnauthorized,
+			expectedRequests: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if tc.body != nil {
+					if b, _ := io.ReadAll(r.Body); string(b) != "body" {
+						t.Errorf("body mismatch: expected %q; got %q", "body", b)
+					}
+				}
+				if !tc.validTokens[r.Header.Get(xhttp.HeaderAuthorization)] {
+					w.WriteHeader(http.StatusUnauthorized)
+				}
+			}))
+			defer srv.Close()
+
+			n := 0
+			var src xhttp.TokenSource = xhttp.StaticTokenSource(&xhttp.Token{Value: "token1"})
+			if tc.refresh {
+				src = xhttp.NewRefreshTokenSource(xhttp.TokenSourceFunc(func(context.Context) (*xhttp.Token, error) {
+					n++
+					return &xhttp.Token{Value: "token" + strconv.Itoa(n)}, nil
+				}))
+			}
+
+			client := http.Client{Transport: xhttp.NewAuthTransport(src)}
+
+			body := tc.body
+			if body == nil {
+				body = http.NoBody
+			}
+			req, err := http.NewRequest(http.MethodPost, srv.URL, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tc.expectedStatus {
+				t.Errorf("status code mismatch: expected %d; got %d", tc.expectedStatus, resp.StatusCode)
+			}
+			if requests != tc.expectedRequests {
+				t.Errorf("requests mismatch: expected %d; got %d", tc.expectedRequests, requests)
+			}
+			if req.Header.Get(xhttp.HeaderAuthorization) != "" {
+				t.Error("expected original request not to be modified")
+			}
+		})
+	}
+}
+
+func TestAuthTransport_RoundTrip_Error(t *testing.T) {
+	errToken := errors.New("token error")
+
+	testCases := []struct {
+		name          string
+		source        xhttp.TokenSource
+		next          http.RoundTripper
+		expectedError error
+	}{
+		{
+			name: "token source error",
+			source: xhttp.TokenSourceFunc(func(context.Context) (*xhttp.Token, error) {
+				return nil, errToken
+			}),
+			next:          &fakeTransport{},
+			expectedError: errToken,
+		},
+		{
+			name:          "next round tripper error",
+			source:        xhttp.StaticTokenSource(&xhttp.Token{Value: "token"}),
+			next:          &fakeTransport{},
+			expectedError: errNoResponse,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			authTransp := xhttp.NewAuthTransport(tc.source, xhttp.AuthTransportNextRoundTripper(tc.next))
+
+			req, err := http.NewRequest(http.MethodGet, "http://example.com", strings.NewReader(""))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err = authTransp.RoundTrip(req); err != tc.expectedError {
+				t.Errorf("error mismatch: %v != %v", err, tc.expectedError)
+			}
+		})
+	}
+
+	t.Run("no token", func(t *testing.T) {
+		authTransp := xhttp.NewAuthTransport(xhttp.StaticTokenSource(nil), xhttp.AuthTransportNextRoundTripper(&fakeTransport{}))
+
+		req, err := http.NewRequest(http.MethodGet, "http://example.com", http.NoBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = authTransp.RoundTrip(req); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestNewAuthTransport_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+
+	xhttp.NewAuthTransport(nil)
+}
+
+func TestAuthTransportNextRoundTripper(t *testing.T) {
+	testCases := []struct {
+		name  string
+		next  http.RoundTripper
+		panic bool
+	}{
+		{
+			name:  "panic",
+			next:  nil,
+			panic: true,
+		},
+		{
+			name:  "valid",
+			next:  &fakeTransport{},
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, func() xhttp.AuthTransportOption {
+				return xhttp.AuthTransportNextRoundTripper(tc.next)
+			})
+		})
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/jlourenc/xgo/xio"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+const bodyCaptureDefaultMaxSize = 64 * xunit.KiB
+
+// BodyCapture holds the bodies of a completed HTTP round trip, captured for auditing purposes.
+type BodyCapture struct {
+	// Request is the request sent.
+	Request *http.Request
+
+	// Response is the response received, or nil if the round trip failed.
+	// Its body has already been consumed by the caller.
+	Response *http.Response
+
+	// RequestBody is the request body, truncated to the max capture size.
+	RequestBody []byte
+
+	// RequestBodyTruncated reports whether RequestBody was truncated.
+	RequestBodyTruncated bool
+
+	// ResponseBody is the part of the response body read by the caller, truncated to the max capture size.
+	ResponseBody []byte
+
+	// ResponseBodyTruncated reports whether ResponseBody was truncated.
+	ResponseBodyTruncated bool
+
+	// Err is the error returned by the round trip, if any.
+	Err error
+}
+
+// BodyCaptureTransport is an HTTP transport that exposes request and response bodies to a callback.
+type bodyCaptureTransport struct {
+	next    http.RoundTripper
+	fn      func(ctx context.Context, c BodyCapture)
+	maxSize xunit.Byte
+}
+
+// NewBodyCaptureTransport creates a new BodyCaptureTransport calling fn with the bodies of each round trip,
+// configured with the options passed in input, notably the next round tripper in the chain. By default,
+// bodies are captured up to 64 KiB. It panics if fn is nil.
+//
+// Bodies are captured as they are streamed: request bodies whose Content-Length fits in the max capture
+// size are duplicated with xio.DuplicateReadCloser before being sent, others are captured as the next
+// round tripper reads them, and response bodies are captured as the caller reads them. fn is called
+// once the response body is fully read or closed, or as soon as the round trip fails.
+func NewBodyCaptureTransport(fn func(ctx context.Context, c BodyCapture), options ...BodyCaptureTransportOption) http.RoundTripper {
+	if fn == nil {
+		panic("capture function is nil")
+	}
+
+	t := &bodyCaptureTransport{
+		next:    http.DefaultTransport,
+		fn:      fn,
+		maxSize: bodyCaptureDefaultMaxSize,
+	}
+
+	for _, opt := range options {
+		opt.apply(t)
+	}
+
+	return t
+}
+
+// RoundTrip makes BodyCaptureTransport implement the RoundTripper interface.
+func (t *bodyCaptureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	reqCapture := &captureBuffer{max: int64(t.maxSize)}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		req = req.Clone(ctx)
+
+		if req.ContentLength > 0 && req.ContentLength <= int64(t.maxSize) {
+			body, dup, err := xio.DuplicateReadCloser(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			io.Copy(reqCapture, dup) //nolint:errcheck // reading from memory does not fail.
+			req.Body = body
+		} else {
+			req.Body = &struct {
+				io.Reader
+				io.Closer
+			}{
+				Reader: io.TeeReader(req.Body, reqCapture),
+				Closer: req.Body,
+			}
+		}
+	}
+
+	c := BodyCapture{
+		Request: req,
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		c.Err = err
+		c.RequestBody, c.RequestBodyTruncated = reqCapture.captured()
+		t.fn(ctx, c)
+		return resp, err
+	}
+
+	c.Response = resp
+
+	if resp.Body == nil || resp.Body == http.NoBody {
+		c.RequestBody, c.RequestBodyTruncated = reqCapture.captured()
+		t.fn(ctx, c)
+		return resp, nil
+	}
+
+	respCapture := &captureBuffer{max: int64(t.maxSize)}
+	resp.Body = &observedBody{
+		ReadCloser: &struct {
+			io.Reader
+			io.Closer
+		}{
+			Reader: io.TeeReader(resp.Body, respCapture),
+			Closer: resp.Body,
+		},
+		done: func(int64) {
+			c.RequestBody, c.RequestBodyTruncated = reqCapture.captured()
+			c.ResponseBody, c.ResponseBodyTruncated = respCapture.captured()
+			t.fn(ctx, c)
+		},
+	}
+
+	return resp, nil
+}
+
+// captureBuffer is a writer keeping at most max bytes of what is written to it.
+// It is safe for concurrent use by multiple goroutines.
+type captureBuffer struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	max       int64
+	truncated bool
+}
+
+func (b *captureBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := len(p)
+	if room := b.max - int64(b.buf.Len()); int64(len(p)) > room {
+		p = p[:room]
+		b.truncated = true
+	}
+	b.buf.Write(p)
+	return n, nil
+}
+
+// captured returns a copy of the bytes kept and whether some were dropped.
+func (b *captureBuffer) captured() ([]byte, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return bytes.Clone(b.buf.Bytes()), b.truncated
+}
+
+type (
+	// BodyCaptureTransportOption configures the BodyCaptureTransport options
+	// when calling NewBodyCaptureTransport.
+	BodyCaptureTransportOption interface {
+		apply(t *bodyCaptureTransport)
+	}
+
+	funcBodyCaptureTransportOption struct {
+		fn func(*bodyCaptureTransport)
+	}
+)
+
+func newFuncBodyCaptureTransportOption(fn func(*bodyCaptureTransport)) funcBodyCaptureTransportOption {
+	return funcBodyCaptureTransportOption{
+		fn: fn,
+	}
+}
+
+func (o funcBodyCaptureTransportOption) apply(t *bodyCaptureTransport) {
+	o.fn(t)
+}
+
+// BodyCaptureTransportMaxSize returns a BodyCaptureTransportOption that configures the max size
+// of the captured request and response bodies. Value must be >= 0, otherwise it panics.
+func BodyCaptureTransportMaxSize(maxSize xunit.Byte) BodyCaptureTransportOption {
+	if maxSize < 0 {
+		panic("invalid max size value")
+	}
+	return newFuncBodyCaptureTransportOption(func(t *bodyCaptureTransport) {
+		t.maxSize = maxSize
+	})
+}
+
+// BodyCaptureTransportNextRoundTripper returns a BodyCaptureTransportOption that configures the
+// next round tripper to call. If not used http.DefaultTransport will be used.
+func BodyCaptureTransportNextRoundTripper(next http.RoundTripper) BodyCaptureTransportOption {
+	if next == nil {
+		panic("next http.RoundTripper is nil")
+	}
+	return newFuncBodyCaptureTransportOption(func(t *bodyCaptureTransport) {
+		t.next = next
+	})
+}
@@ -0,0 +1,194 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+func TestBodyCaptureTransport_RoundTrip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "response: ")
+		io.Copy(w, r.Body)
+	}))
+	defer srv.Close()
+
+	testCases := []struct {
+		name                          string
+		body                          string
+		streamed                      bool
+		maxSize                       xunit.Byte
+		expectedRequestBody           string
+		expectedRequestBodyTruncated  bool
+		expectedResponseBody          string
+		expectedResponseBodyTruncated bool
+	}{
+		{
+			name:                 "no body",
+			body:                 "",
+			maxSize:              64,
+			expectedRequestBody:  "",
+			expectedResponseBody: "response: ",
+		},
+		{
+			name:                 "duplicated body",
+			body:                 "hello",
+			maxSize:              64,
+			expectedRequestBody:  "hello",
+			expectedResponseBody: "response: hello",
+		},
+		{
+			name:                          "streamed body",
+			body:                          "hello world",
+			streamed:                      true,
+			maxSize:                       5,
+			expectedRequestBody:           "hello",
+			expectedRequestBodyTruncated:  true,
+			expectedResponseBody:          "respo",
+			expectedResponseBodyTruncated: true,
+		},
+		{
+			name:                          "truncated body",
+			body:                          "hello world",
+			maxSize:                       10,
+			expectedRequestBody:           "hello worl",
+			expectedRequestBodyTruncated:  true,
+			expectedResponseBody:          "response: ",
+			expectedResponseBodyTruncated: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				captures int
+				got      xhttp.BodyCapture
+			)
+			transport := xhttp.NewBodyCaptureTransport(func(_ context.Context, c xhttp.BodyCapture) {
+				captures++
+				got = c
+			}, xhttp.BodyCaptureTransportMaxSize(tc.maxSize))
+
+			var body io.Reader = strings.NewReader(tc.body)
+			if tc.streamed {
+				// Hide the length of the body.
+				body = io.MultiReader(body)
+			}
+
+			req, err := http.NewRequest(http.MethodPost, srv.URL, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := (&http.Client{Transport: transport}).Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			b, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if expected := "response: " + tc.body; string(b) != expected {
+				t.Errorf("body mismatch: expected %q; got %q", expected, b)
+			}
+			if captures != 1 {
+				t.Fatalf("captures mismatch: expected 1; got %d", captures)
+			}
+			if string(got.RequestBody) != tc.expectedRequestBody {
+				t.Errorf("request body mismatch: expected %q; got %q", tc.expectedRequestBody, got.RequestBody)
+			}
+			if got.RequestBodyTruncated != tc.expectedRequestBodyTruncated {
+				t.Errorf("request body truncated mismatch: expected %t; got %t", tc.expectedRequestBodyTruncated, got.RequestBodyTruncated)
+			}
+			if string(got.ResponseBody) != tc.expectedResponseBody {
+				t.Errorf("response body mismatch: expected %q; got %q", tc.expectedResponseBody, got.ResponseBody)
+			}
+			if got.ResponseBodyTruncated != tc.expectedResponseBodyTruncated {
+				t.Errorf("response body truncated mismatch: expected %t; got %t", tc.expectedResponseBodyTruncated, got.ResponseBodyTruncated)
+			}
+			if got.Response == nil || got.Response.StatusCode != http.StatusOK {
+				t.Errorf("response mismatch: expected status code %d; got %v", http.StatusOK, got.Response)
+			}
+		})
+	}
+}
+
+func TestBodyCaptureTransport_RoundTrip_Error(t *testing.T) {
+	var got xhttp.BodyCapture
+	transport := xhttp.NewBodyCaptureTransport(func(_ context.Context, c xhttp.BodyCapture) {
+		got = c
+	}, xhttp.BodyCaptureTransportNextRoundTripper(&fakeTransport{}))
+
+	req, err := http.NewRequest(http.MethodPost, "http://example.com", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = transport.RoundTrip(req); err != errNoResponse {
+		t.Errorf("error mismatch: %v != %v", err, errNoResponse)
+	}
+	if got.Err != errNoResponse {
+		t.Errorf("captured error mismatch: %v != %v", got.Err, errNoResponse)
+	}
+	if string(got.RequestBody) != "hello" {
+		t.Errorf("request body mismatch: expected %q; got %q", "hello", got.RequestBody)
+	}
+}
+
+func TestNewBodyCaptureTransport_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+
+	xhttp.NewBodyCaptureTransport(nil)
+}
+
+func TestBodyCaptureTransportOptions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func() xhttp.BodyCaptureTransportOption
+		panic bool
+	}{
+		{
+			name:  "max size invalid",
+			fn:    func() xhttp.BodyCaptureTransportOption { return xhttp.BodyCaptureTransportMaxSize(-1) },
+			panic: true,
+		},
+		{
+			name:  "max size valid",
+			fn:    func() xhttp.BodyCaptureTransportOption { return xhttp.BodyCaptureTransportMaxSize(0) },
+			panic: false,
+		},
+		{
+			name:  "next round tripper nil",
+			fn:    func() xhttp.BodyCaptureTransportOption { return xhttp.BodyCaptureTransportNextRoundTripper(nil) },
+			panic: true,
+		},
+		{
+			name: "next round tripper valid",
+			fn: func() xhttp.BodyCaptureTransportOption {
+				return xhttp.BodyCaptureTransportNextRoundTripper(&fakeTransport{})
+			},
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, tc.fn)
+		})
+	}
+}
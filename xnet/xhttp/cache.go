@@ -0,0 +1,95 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Cache is a store of serialized HTTP responses used by CacheTransport.
+// Implementations must be safe for concurrent use by multiple goroutines.
+type Cache interface {
+	// Get returns the value stored under key and whether it was found.
+	Get(key string) ([]byte, bool)
+
+	// Set stores value under key.
+	Set(key string, value []byte)
+
+	// Delete removes the value stored under key, if any.
+	Delete(key string)
+}
+
+// memoryCache is an in-memory Cache evicting the least recently used entries
+// once its capacity is reached.
+type memoryCache struct {
+	capacity int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+type memoryCacheEntry struct {
+	key   string
+	value []byte
+}
+
+// NewMemoryCache creates an in-memory Cache holding at most capacity entries.
+// When full, the least recently used entry is evicted. Capacity must be > 0, otherwise it panics.
+func NewMemoryCache(capacity int) Cache {
+	if capacity <= 0 {
+		panic("invalid capacity value")
+	}
+	return &memoryCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// Delete makes memoryCache implement the Cache interface.
+func (c *memoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+	}
+}
+
+// Get makes memoryCache implement the Cache interface.
+func (c *memoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*memoryCacheEntry).value, true //nolint:forcetypeassert // only entries are stored.
+}
+
+// Set makes memoryCache implement the Cache interface.
+func (c *memoryCache) Set(key string, value []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*memoryCacheEntry).value = value //nolint:forcetypeassert // only entries are stored.
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&memoryCacheEntry{key: key, value: value})
+
+	if c.lru.Len() > c.capacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheEntry).key) //nolint:forcetypeassert // only entries are stored.
+	}
+}
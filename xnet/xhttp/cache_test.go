@@ -0,0 +1,62 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestNewMemoryCache(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("panic expected; got none")
+		}
+	}()
+
+	xhttp.NewMemoryCache(0)
+}
+
+func TestMemoryCache(t *testing.T) {
+	c := xhttp.NewMemoryCache(2)
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected no value for key a")
+	}
+
+	c.Set("a", []byte("1"))
+	c.Set("b", []byte("2"))
+	c.Set("a", []byte("3")) // a becomes the most recently used entry.
+	c.Set("c", []byte("4")) // b is evicted.
+
+	testCases := []struct {
+		key      string
+		expected string
+		found    bool
+	}{
+		{key: "a", expected: "3", found: true},
+		{key: "b", found: false},
+		{key: "c", expected: "4", found: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.key, func(t *testing.T) {
+			got, ok := c.Get(tc.key)
+			if ok != tc.found {
+				t.Fatalf("expected found %t; got %t", tc.found, ok)
+			}
+			if string(got) != tc.expected {
+				t.Errorf("expected %q; got %q", tc.expected, got)
+			}
+		})
+	}
+
+	c.Delete("a")
+	c.Delete("unknown")
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected no value for deleted key a")
+	}
+}
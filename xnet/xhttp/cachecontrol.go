@@ -0,0 +1,220 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheControl represents the directives of a Cache-Control header, either of a request or of a response.
+// Directives with a delta-seconds value are nil when absent.
+//
+// See https://datatracker.ietf.org/doc/html/rfc9111#section-5.2.
+type CacheControl struct {
+	// Immutable is the response immutable directive.
+	Immutable bool
+
+	// MaxAge is the request or response max-age directive.
+	MaxAge *time.Duration
+
+	// MaxStale is the request max-stale directive with a value.
+	MaxStale *time.Duration
+
+	// MaxStaleAny is the request max-stale directive without value, accepting any staleness.
+	MaxStaleAny bool
+
+	// MinFresh is the request min-fresh directive.
+	MinFresh *time.Duration
+
+	// MustRevalidate is the response must-revalidate directive.
+	MustRevalidate bool
+
+	// NoCache is the request or response no-cache directive.
+	NoCache bool
+
+	// NoCacheHeaders are the field names qualifying the response no-cache directive, if any.
+	NoCacheHeaders []string
+
+	// NoStore is the request or response no-store directive.
+	NoStore bool
+
+	// NoTransform is the request or response no-transform directive.
+	NoTransform bool
+
+	// OnlyIfCached is the request only-if-cached directive.
+	OnlyIfCached bool
+
+	// Private is the response private directive.
+	Private bool
+
+	// PrivateHeaders are the field names qualifying the response private directive, if any.
+	PrivateHeaders []string
+
+	// ProxyRevalidate is the response proxy-revalidate directive.
+	ProxyRevalidate bool
+
+	// Public is the response public directive.
+	Public bool
+
+	// SMaxAge is the response s-maxage directive.
+	SMaxAge *time.Duration
+
+	// StaleIfError is the request or response stale-if-error directive.
+	StaleIfError *time.Duration
+
+	// StaleWhileRevalidate is the response stale-while-revalidate directive.
+	StaleWhileRevalidate *time.Duration
+
+	// Extensions are the unknown directives with their value, if any.
+	Extensions map[string]string
+}
+
+// ParseCacheControl parses the Cache-Control header. Directive names are case insensitive and
+// invalid delta-seconds values are parsed as 0, i.e. stale, as per Section 4.2.1 of the RFC 9111.
+func ParseCacheControl(headers http.Header) CacheControl {
+	var cc CacheControl
+
+	for _, v := range splitHeaderList(headers.Values(HeaderCacheControl)) {
+		name, value, _ := strings.Cut(v, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+
+		switch name {
+		case "":
+		case CacheControlImmutable:
+			cc.Immutable = true
+		case CacheControlMaxAge:
+			cc.MaxAge = parseCacheControlDeltaSeconds(value)
+		case CacheControlMaxStale:
+			if value == "" {
+				cc.MaxStaleAny = true
+			} else {
+				cc.MaxStale = parseCacheControlDeltaSeconds(value)
+			}
+		case CacheControlMinFresh:
+			cc.MinFresh = parseCacheControlDeltaSeconds(value)
+		case CacheControlMustRevalidate:
+			cc.MustRevalidate = true
+		case CacheControlNoCache:
+			cc.NoCache = true
+			cc.NoCacheHeaders = append(cc.NoCacheHeaders, parseCacheControlFieldNames(value)...)
+		case CacheControlNoStore:
+			cc.NoStore = true
+		case CacheControlNoTransform:
+			cc.NoTransform = true
+		case CacheControlOnlyIfCached:
+			cc.OnlyIfCached = true
+		case CacheControlPrivate:
+			cc.Private = true
+			cc.PrivateHeaders = append(cc.PrivateHeaders, parseCacheControlFieldNames(value)...)
+		case CacheControlProxyRevalidate:
+			cc.ProxyRevalidate = true
+		case CacheControlPublic:
+			cc.Public = true
+		case CacheControlSMaxAge:
+			cc.SMaxAge = parseCacheControlDeltaSeconds(value)
+		case CacheControlStaleIfError:
+			cc.StaleIfError = parseCacheControlDeltaSeconds(value)
+		case CacheControlStaleWhileRevalidate:
+			cc.StaleWhileRevalidate = parseCacheControlDeltaSeconds(value)
+		default:
+			if cc.Extensions == nil {
+				cc.Extensions = map[string]string{}
+			}
+			cc.Extensions[name] = value
+		}
+	}
+
+	return cc
+}
+
+// Apply sets the Cache-Control header to the directives of cc, or removes it if cc has none.
+func (cc CacheControl) Apply(headers http.Header) {
+	if s := cc.String(); s != "" {
+		headers.Set(HeaderCacheControl, s)
+	} else {
+		headers.Del(HeaderCacheControl)
+	}
+}
+
+// String returns the directives of cc formatted as the value of a Cache-Control header,
+// e.g. "public, max-age=3600". Delta-seconds values are truncated to the second.
+func (cc CacheControl) String() string {
+	var directives []string
+
+	flag := func(set bool, name string) {
+		if set {
+			directives = append(directives, name)
+		}
+	}
+	deltaSeconds := func(d *time.Duration, name string) {
+		if d != nil {
+			directives = append(directives, name+"="+strconv.FormatInt(int64(max(*d, 0)/time.Second), 10))
+		}
+	}
+	fieldNames := func(set bool, headers []string, name string) {
+		switch {
+		case len(headers) > 0:
+			directives = append(directives, name+`="`+strings.Join(headers, ", ")+`"`)
+		case set:
+			directives = append(directives, name)
+		}
+	}
+
+	flag(cc.Public, CacheControlPublic)
+	fieldNames(cc.Private, cc.PrivateHeaders, CacheControlPrivate)
+	fieldNames(cc.NoCache, cc.NoCacheHeaders, CacheControlNoCache)
+	flag(cc.NoStore, CacheControlNoStore)
+	flag(cc.NoTransform, CacheControlNoTransform)
+	deltaSeconds(cc.MaxAge, CacheControlMaxAge)
+	deltaSeconds(cc.SMaxAge, CacheControlSMaxAge)
+	if cc.MaxStale != nil {
+		deltaSeconds(cc.MaxStale, CacheControlMaxStale)
+	} else {
+		flag(cc.MaxStaleAny, CacheControlMaxStale)
+	}
+	deltaSeconds(cc.MinFresh, CacheControlMinFresh)
+	flag(cc.MustRevalidate, CacheControlMustRevalidate)
+	flag(cc.ProxyRevalidate, CacheControlProxyRevalidate)
+	flag(cc.OnlyIfCached, CacheControlOnlyIfCached)
+	flag(cc.Immutable, CacheControlImmutable)
+	deltaSeconds(cc.StaleWhileRevalidate, CacheControlStaleWhileRevalidate)
+	deltaSeconds(cc.StaleIfError, CacheControlStaleIfError)
+
+	extensions := make([]string, 0, len(cc.Extensions))
+	for name, value := range cc.Extensions {
+		switch {
+		case strings.ContainsAny(value, ` ,;="`):
+			name += "=" + strconv.Quote(value)
+		case value != "":
+			name += "=" + value
+		}
+		extensions = append(extensions, name)
+	}
+	sort.Strings(extensions)
+
+	return strings.Join(append(directives, extensions...), ", ")
+}
+
+func parseCacheControlDeltaSeconds(v string) *time.Duration {
+	d, ok := parseDeltaSeconds(v)
+	if !ok {
+		d = 0
+	}
+	return &d
+}
+
+func parseCacheControlFieldNames(v string) []string {
+	var names []string
+	for _, name := range strings.Split(v, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, http.CanonicalHeaderKey(name))
+		}
+	}
+	return names
+}
@@ -0,0 +1,201 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
+
+func TestParseCacheControl(t *testing.T) {
+	testCases := []struct {
+		name     string
+		headers  http.Header
+		expected xhttp.CacheControl
+	}{
+		{
+			name:     "no header",
+			headers:  http.Header{},
+			expected: xhttp.CacheControl{},
+		},
+		{
+			name: "response directives",
+			headers: http.Header{xhttp.HeaderCacheControl: {
+				"public, max-age=60, s-maxage=120, must-revalidate, proxy-revalidate, immutable",
+				"stale-while-revalidate=30, stale-if-error=300, no-transform",
+			}},
+			expected: xhttp.CacheControl{
+				Immutable:            true,
+				MaxAge:               durationPtr(time.Minute),
+				MustRevalidate:       true,
+				NoTransform:          true,
+				ProxyRevalidate:      true,
+				Public:               true,
+				SMaxAge:              durationPtr(2 * time.Minute),
+				StaleIfError:         durationPtr(5 * time.Minute),
+				StaleWhileRevalidate: durationPtr(30 * time.Second),
+			},
+		},
+		{
+			name:    "request directives",
+			headers: http.Header{xhttp.HeaderCacheControl: {"No-Cache, NO-STORE, max-stale=10, min-fresh=5, only-if-cached"}},
+			expected: xhttp.CacheControl{
+				MaxStale:     durationPtr(10 * time.Second),
+				MinFresh:     durationPtr(5 * time.Second),
+				NoCache:      true,
+				NoStore:      true,
+				OnlyIfCached: true,
+			},
+		},
+		{
+			name:    "max-stale without value",
+			headers: http.Header{xhttp.HeaderCacheControl: {"max-stale"}},
+			expected: xhttp.CacheControl{
+				MaxStaleAny: true,
+			},
+		},
+		{
+			name:    "field names",
+			headers: http.Header{xhttp.HeaderCacheControl: {`private="set-cookie, x-custom", no-cache="Set-Cookie"`}},
+			expected: xhttp.CacheControl{
+				NoCache:        true,
+				NoCacheHeaders: []string{"Set-Cookie"},
+				Private:        true,
+				PrivateHeaders: []string{"Set-Cookie", "X-Custom"},
+			},
+		},
+		{
+			name:    "invalid delta-seconds",
+			headers: http.Header{xhttp.HeaderCacheControl: {"max-age=abc, s-maxage=-1"}},
+			expected: xhttp.CacheControl{
+				MaxAge:  durationPtr(0),
+				SMaxAge: durationPtr(0),
+			},
+		},
+		{
+			name:    "quoted delta-seconds",
+			headers: http.Header{xhttp.HeaderCacheControl: {`max-age="60"`}},
+			expected: xhttp.CacheControl{
+				MaxAge: durationPtr(time.Minute),
+			},
+		},
+		{
+			name:    "extensions",
+			headers: http.Header{xhttp.HeaderCacheControl: {"community=UCI, ext, , "}},
+			expected: xhttp.CacheControl{
+				Extensions: map[string]string{"community": "UCI", "ext": ""},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xhttp.ParseCacheControl(tc.headers)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %+v; got %+v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestCacheControl_String(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cc       xhttp.CacheControl
+		expected string
+	}{
+		{
+			name:     "empty",
+			cc:       xhttp.CacheControl{},
+			expected: "",
+		},
+		{
+			name: "response directives",
+			cc: xhttp.CacheControl{
+				Immutable:            true,
+				MaxAge:               durationPtr(time.Minute + 500*time.Millisecond),
+				MustRevalidate:       true,
+				Public:               true,
+				SMaxAge:              durationPtr(0),
+				StaleIfError:         durationPtr(5 * time.Minute),
+				StaleWhileRevalidate: durationPtr(30 * time.Second),
+			},
+			expected: "public, max-age=60, s-maxage=0, must-revalidate, immutable, stale-while-revalidate=30, stale-if-error=300",
+		},
+		{
+			name: "request directives",
+			cc: xhttp.CacheControl{
+				MaxStaleAny:  true,
+				MinFresh:     durationPtr(5 * time.Second),
+				NoCache:      true,
+				NoStore:      true,
+				NoTransform:  true,
+				OnlyIfCached: true,
+			},
+			expected: "no-cache, no-store, no-transform, max-stale, min-fresh=5, only-if-cached",
+		},
+		{
+			name: "max-stale with value",
+			cc: xhttp.CacheControl{
+				MaxStale:    durationPtr(10 * time.Second),
+				MaxStaleAny: true,
+			},
+			expected: "max-stale=10",
+		},
+		{
+			name: "field names",
+			cc: xhttp.CacheControl{
+				NoCacheHeaders: []string{"Set-Cookie"},
+				Private:        true,
+				PrivateHeaders: []string{"Set-Cookie", "X-Custom"},
+			},
+			expected: `private="Set-Cookie, X-Custom", no-cache="Set-Cookie"`,
+		},
+		{
+			name: "extensions",
+			cc: xhttp.CacheControl{
+				ProxyRevalidate: true,
+				Extensions:      map[string]string{"community": "UCI", "ext": "", "quoted": "a, b"},
+			},
+			expected: `proxy-revalidate, community=UCI, ext, quoted="a, b"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.cc.String()
+			if got != tc.expected {
+				t.Errorf("expected %q; got %q", tc.expected, got)
+			}
+
+			if got != "" {
+				if parsed := xhttp.ParseCacheControl(http.Header{xhttp.HeaderCacheControl: {got}}); parsed.String() != got {
+					t.Errorf("round trip mismatch: expected %q; got %q", got, parsed.String())
+				}
+			}
+		})
+	}
+}
+
+func TestCacheControl_Apply(t *testing.T) {
+	headers := http.Header{xhttp.HeaderCacheControl: {"public"}}
+
+	xhttp.CacheControl{NoStore: true}.Apply(headers)
+	if got := headers.Values(xhttp.HeaderCacheControl); !reflect.DeepEqual(got, []string{"no-store"}) {
+		t.Errorf("expected [no-store]; got %v", got)
+	}
+
+	xhttp.CacheControl{}.Apply(headers)
+	if xhttp.HeaderExist(headers, xhttp.HeaderCacheControl) {
+		t.Errorf("expected no header; got %v", headers.Values(xhttp.HeaderCacheControl))
+	}
+}
@@ -0,0 +1,463 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jlourenc/xgo/xio"
+	"github.com/jlourenc/xgo/xnet/xhttp/xhttptrace"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+const (
+	cacheTransportDefaultCapacity = 1000
+	cacheTransportDefaultMaxSize  = 1 * xunit.MiB
+
+	// cacheVariedHeaderPrefix prefixes the headers recording, along with a stored response,
+	// the values of the request headers nominated by its Vary header.
+	cacheVariedHeaderPrefix = "X-Varied-"
+)
+
+// CacheTransport is an HTTP transport that implements a private HTTP cache according to
+// the HTTP caching semantics defined in https://datatracker.ietf.org/doc/html/rfc9111.
+type cacheTransport struct {
+	next    http.RoundTripper
+	cache   Cache
+	maxSize xunit.Byte
+}
+
+// NewCacheTransport creates a new CacheTransport configured with the options passed in input,
+// notably the cache storing responses and the next round tripper in the chain.
+// If not configured, responses are stored in an in-memory cache of 1000 entries,
+// and responses whose body is larger than 1MiB are not stored.
+func NewCacheTransport(options ...CacheTransportOption) http.RoundTripper {
+	t := &cacheTransport{
+		next:    http.DefaultTransport,
+		maxSize: cacheTransportDefaultMaxSize,
+	}
+
+	for _, opt := range options {
+		opt.apply(t)
+	}
+
+	if t.cache == nil {
+		t.cache = NewMemoryCache(cacheTransportDefaultCapacity)
+	}
+
+	return t
+}
+
+// RoundTrip makes CacheTransport implement the RoundTripper interface.
+//
+// Responses to GET requests are stored and reused as long as they are fresh, as defined
+// by their Cache-Control, Expires and Last-Modified headers, and revalidated with conditional
+// requests, based on their ETag and Last-Modified headers, once stale. Cache-Control request
+// directives are honored, and successful responses to unsafe requests invalidate the stored
+// response of the target URI.
+//
+// See HTTP caching semantics defined in: https://datatracker.ietf.org/doc/html/rfc9111.
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := req.URL.String()
+
+	if !isRequestCacheable(req) {
+		resp, err := t.next.RoundTrip(req)
+		if err == nil && !isRequestSafe(req) && resp.StatusCode < http.StatusBadRequest {
+			// https://datatracker.ietf.org/doc/html/rfc9111#section-4.4
+			t.cache.Delete(key)
+		}
+		return resp, err
+	}
+
+	reqCC := ParseCacheControl(req.Header)
+
+	if reqCC.NoStore {
+		return t.next.RoundTrip(req)
+	}
+
+	trace := xhttptrace.ContextClientTrace(req.Context())
+	if trace == nil {
+		trace = &xhttptrace.ClientTrace{}
+	}
+
+	cached := t.load(key, req)
+	if cached != nil {
+		if isResponseUsable(cached, reqCC, time.Now()) {
+			if trace.CacheHit != nil {
+				trace.CacheHit(xhttptrace.CacheHitInfo{Key: key})
+			}
+			return cached, nil
+		}
+	} else if reqCC.OnlyIfCached {
+		// https://datatracker.ietf.org/doc/html/rfc9111#section-5.2.1.7
+		if trace.CacheMiss != nil {
+			trace.CacheMiss(xhttptrace.CacheMissInfo{Key: key})
+		}
+		closeRequestBody(req)
+		return &http.Response{
+			Status:     strconv.Itoa(http.StatusGatewayTimeout) + " " + http.StatusText(http.StatusGatewayTimeout),
+			StatusCode: http.StatusGatewayTimeout,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{},
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	}
+
+	outReq := req
+	if cached != nil {
+		outReq = conditionalRequest(req, cached.Header)
+	}
+
+	resp, err := t.next.RoundTrip(outReq)
+	if err != nil {
+		if trace.CacheMiss != nil {
+			trace.CacheMiss(xhttptrace.CacheMissInfo{Key: key, Stale: cached != nil})
+		}
+		if cached != nil {
+			xio.DrainClose(cached.Body)
+		}
+		return resp, err
+	}
+
+	if cached != nil && resp.StatusCode == http.StatusNotModified {
+		// https://datatracker.ietf.org/doc/html/rfc9111#section-4.3.4
+		xio.DrainClose(resp.Body)
+		for k, v := range resp.Header {
+			if k != HeaderContentLength && k != HeaderTransferEncoding {
+				cached.Header[k] = v
+			}
+		}
+		t.store(key, req, cached)
+		cached.Header.Del(HeaderAge)
+		if trace.CacheHit != nil {
+			trace.CacheHit(xhttptrace.CacheHitInfo{Key: key, Revalidated: true})
+		}
+		return cached, nil
+	}
+
+	if trace.CacheMiss != nil {
+		trace.CacheMiss(xhttptrace.CacheMissInfo{Key: key, Stale: cached != nil})
+	}
+
+	if cached != nil {
+		xio.DrainClose(cached.Body)
+	}
+
+	if isResponseStorable(req, resp) {
+		t.store(key, req, resp)
+	}
+
+	return resp, nil
+}
+
+// load returns the response stored under key if it matches req, nil otherwise.
+func (t *cacheTransport) load(key string, req *http.Request) *http.Response {
+	b, ok := t.cache.Get(key)
+	if !ok {
+		return nil
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(b)), req)
+	if err != nil {
+		t.cache.Delete(key)
+		return nil
+	}
+
+	// https://datatracker.ietf.org/doc/html/rfc9111#section-4.1
+	for _, name := range HeaderValues(resp.Header, HeaderVary) {
+		if req.Header.Get(name) != resp.Header.Get(cacheVariedHeaderPrefix+name) {
+			xio.DrainClose(resp.Body)
+			return nil
+		}
+	}
+	for k := range resp.Header {
+		if strings.HasPrefix(k, cacheVariedHeaderPrefix) {
+			resp.Header.Del(k)
+		}
+	}
+
+	return resp
+}
+
+// store serializes resp under key, restoring resp's body so that it can still be read.
+// Responses whose body is larger than the max size are not stored.
+func (t *cacheTransport) store(key string, req *http.Request, resp *http.Response) {
+	if !bufferResponseBody(resp, t.maxSize) {
+		return
+	}
+
+	varied := make(http.Header)
+	for _, name := range HeaderValues(resp.Header, HeaderVary) {
+		varied.Set(cacheVariedHeaderPrefix+name, req.Header.Get(name))
+	}
+
+	stored := *resp
+	stored.Header = resp.Header.Clone()
+	if stored.Header.Get(HeaderDate) == "" {
+		stored.Header.Set(HeaderDate, time.Now().UTC().Format(http.TimeFormat))
+	}
+	for k, v := range varied {
+		stored.Header[k] = v
+	}
+
+	b, err := httputil.DumpResponse(&stored, true)
+	resp.Body = stored.Body
+	if err != nil {
+		return
+	}
+
+	t.cache.Set(key, b)
+}
+
+// bufferResponseBody reads the body of resp in memory, restoring it so that it can still be read.
+// It returns false if the body is larger than maxSize, in which case it is not read further than needed.
+func bufferResponseBody(resp *http.Response, maxSize xunit.Byte) bool {
+	if resp.ContentLength > int64(maxSize) {
+		return false
+	}
+
+	body := resp.Body
+	var peeked bytes.Buffer
+	if _, err := peeked.ReadFrom(io.LimitReader(body, int64(maxSize)+1)); err != nil || peeked.Len() > int(maxSize) {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{
+			Reader: io.MultiReader(&peeked, body),
+			Closer: body,
+		}
+		return false
+	}
+	body.Close()
+	resp.Body = io.NopCloser(&peeked)
+	return true
+}
+
+// isRequestCacheable returns whether a response to req may be served from or stored in the cache.
+func isRequestCacheable(req *http.Request) bool {
+	return req.Method == http.MethodGet &&
+		req.Header.Get(HeaderRange) == "" &&
+		req.Header.Get(HeaderIfNoneMatch) == "" &&
+		req.Header.Get(HeaderIfModifiedSince) == ""
+}
+
+// isRequestSafe returns whether req uses a safe method as defined in
+// https://datatracker.ietf.org/doc/html/rfc9110#section-9.2.1.
+func isRequestSafe(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
+
+// isResponseStorable returns whether resp may be stored, as defined in
+// https://datatracker.ietf.org/doc/html/rfc9111#section-3.
+func isResponseStorable(req *http.Request, resp *http.Response) bool {
+	switch resp.StatusCode {
+	// heuristically cacheable status codes: https://datatracker.ietf.org/doc/html/rfc9110#section-15.1
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent, http.StatusMultipleChoices,
+		http.StatusMovedPermanently, http.StatusPermanentRedirect, http.StatusNotFound, http.StatusMethodNotAllowed,
+		http.StatusGone, http.StatusRequestURITooLong, http.StatusNotImplemented:
+	default:
+		return false
+	}
+
+	for _, name := range HeaderValues(resp.Header, HeaderVary) {
+		if name == "*" {
+			return false
+		}
+	}
+
+	respCC := ParseCacheControl(resp.Header)
+	if respCC.NoStore {
+		return false
+	}
+
+	// https://datatracker.ietf.org/doc/html/rfc9111#section-3.5
+	if req.Header.Get(HeaderAuthorization) != "" && !respCC.Public && !respCC.MustRevalidate && respCC.SMaxAge == nil {
+		return false
+	}
+
+	// Responses which can neither be fresh nor be validated would never be reused.
+	return respCC.MaxAge != nil ||
+		resp.Header.Get(HeaderExpires) != "" ||
+		resp.Header.Get(HeaderEtag) != "" ||
+		resp.Header.Get(HeaderLastModified) != ""
+}
+
+// isResponseUsable returns whether the stored resp may be served without validation,
+// as defined in https://datatracker.ietf.org/doc/html/rfc9111#section-4.2.
+func isResponseUsable(resp *http.Response, reqCC CacheControl, now time.Time) bool {
+	respCC := ParseCacheControl(resp.Header)
+
+	if respCC.NoCache || reqCC.NoCache {
+		return false
+	}
+
+	age := responseAge(resp.Header, now)
+	lifetime := responseFreshnessLifetime(resp.Header, respCC)
+
+	if reqCC.MaxAge != nil && age > *reqCC.MaxAge {
+		return false
+	}
+
+	if reqCC.MinFresh != nil && lifetime-age < *reqCC.MinFresh {
+		return false
+	}
+
+	if age >= lifetime {
+		if respCC.MustRevalidate {
+			return false
+		}
+		if reqCC.MaxStale == nil && !reqCC.MaxStaleAny {
+			return false
+		}
+		if reqCC.MaxStale != nil && age-lifetime > *reqCC.MaxStale {
+			return false
+		}
+	}
+
+	resp.Header.Set(HeaderAge, strconv.Itoa(int(age/time.Second)))
+	return true
+}
+
+// responseAge returns the current age of a response, as defined in
+// https://datatracker.ietf.org/doc/html/rfc9111#section-4.2.3.
+func responseAge(header http.Header, now time.Time) time.Duration {
+	var age time.Duration
+
+	if date, err := http.ParseTime(header.Get(HeaderDate)); err == nil && now.After(date) {
+		age = now.Sub(date)
+	}
+
+	if v, ok := parseDeltaSeconds(header.Get(HeaderAge)); ok {
+		age += v
+	}
+
+	return age
+}
+
+// responseFreshnessLifetime returns the freshness lifetime of a response, as defined in
+// https://datatracker.ietf.org/doc/html/rfc9111#section-4.2.1.
+func responseFreshnessLifetime(header http.Header, respCC CacheControl) time.Duration {
+	if respCC.MaxAge != nil {
+		return *respCC.MaxAge
+	}
+
+	date, err := http.ParseTime(header.Get(HeaderDate))
+	if err != nil {
+		return 0
+	}
+
+	if v := header.Get(HeaderExpires); v != "" {
+		expires, err := http.ParseTime(v)
+		if err != nil {
+			return 0
+		}
+		return expires.Sub(date)
+	}
+
+	// https://datatracker.ietf.org/doc/html/rfc9111#section-4.2.2
+	if lastModified, err := http.ParseTime(header.Get(HeaderLastModified)); err == nil && date.After(lastModified) {
+		return date.Sub(lastModified) / 10 //nolint:gomnd // 10% of the time since last modification.
+	}
+
+	return 0
+}
+
+// parseDeltaSeconds parses a delta-seconds value as defined in
+// https://datatracker.ietf.org/doc/html/rfc9111#section-1.2.2.
+func parseDeltaSeconds(v string) (time.Duration, bool) {
+	secs, err := strconv.Atoi(strings.Trim(v, `"`))
+	if err != nil || secs < 0 {
+		return 0, false
+	}
+	return time.Duration(secs) * time.Second, true
+}
+
+// conditionalRequest returns a copy of req validating the stored response whose headers are passed in input,
+// as defined in https://datatracker.ietf.org/doc/html/rfc9111#section-4.3.1.
+func conditionalRequest(req *http.Request, header http.Header) *http.Request {
+	etag := header.Get(HeaderEtag)
+	lastModified := header.Get(HeaderLastModified)
+	if etag == "" && lastModified == "" {
+		return req
+	}
+
+	r := req.Clone(req.Context())
+	if etag != "" {
+		r.Header.Set(HeaderIfNoneMatch, etag)
+	}
+	if lastModified != "" {
+		r.Header.Set(HeaderIfModifiedSince, lastModified)
+	}
+	return r
+}
+
+type (
+	// CacheTransportOption configures the CacheTransport options
+	// when calling NewCacheTransport.
+	CacheTransportOption interface {
+		apply(t *cacheTransport)
+	}
+
+	funcCacheTransportOption struct {
+		fn func(*cacheTransport)
+	}
+)
+
+func newFuncCacheTransportOption(fn func(*cacheTransport)) funcCacheTransportOption {
+	return funcCacheTransportOption{
+		fn: fn,
+	}
+}
+
+func (o funcCacheTransportOption) apply(t *cacheTransport) {
+	o.fn(t)
+}
+
+// CacheTransportCache returns a CacheTransportOption that configures the cache storing
+// responses. If not used, an in-memory cache of 1000 entries is used.
+func CacheTransportCache(cache Cache) CacheTransportOption {
+	if cache == nil {
+		panic("cache is nil")
+	}
+	return newFuncCacheTransportOption(func(t *cacheTransport) {
+		t.cache = cache
+	})
+}
+
+// CacheTransportMaxSize returns a CacheTransportOption that configures the max size of the
+// bodies of stored responses. If not used, 1MiB is used. Value must be >= 0, otherwise it panics.
+func CacheTransportMaxSize(maxSize xunit.Byte) CacheTransportOption {
+	if maxSize < 0 {
+		panic("invalid max size value")
+	}
+	return newFuncCacheTransportOption(func(t *cacheTransport) {
+		t.maxSize = maxSize
+	})
+}
+
+// CacheTransportNextRoundTripper returns a CacheTransportOption that configures the
+// next round tripper to call. If not used http.DefaultTransport will be used.
+func CacheTransportNextRoundTripper(next http.RoundTripper) CacheTransportOption {
+	if next == nil {
+		panic("next http.RoundTripper is nil")
+	}
+	return newFuncCacheTransportOption(func(t *cacheTransport) {
+		t.next = next
+	})
+}
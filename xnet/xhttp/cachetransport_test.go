@@ -0,0 +1,442 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+	"github.com/jlourenc/xgo/xnet/xhttp/xhttptrace"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+func TestCacheTransport_RoundTrip(t *testing.T) {
+	lastModified := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+
+	type request struct {
+		method         string
+		header         http.Header
+		expectedStatus int
+		expectedBody   string
+	}
+
+	testCases := []struct {
+		name         string
+		options      []xhttp.CacheTransportOption
+		respHeader   http.Header
+		reqs         []request
+		expectedHits int
+	}{
+		{
+			name:       "fresh response served from cache",
+			respHeader: http.Header{xhttp.HeaderCacheControl: {"max-age=60"}},
+			reqs: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+			},
+			expectedHits: 1,
+		},
+		{
+			name:       "expires in the future served from cache",
+			respHeader: http.Header{xhttp.HeaderExpires: {time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)}},
+			reqs: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+			},
+			expectedHits: 1,
+		},
+		{
+			name:       "no-store response not stored",
+			respHeader: http.Header{xhttp.HeaderCacheControl: {"no-store, max-age=60"}},
+			reqs: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{expectedStatus: http.StatusOK, expectedBody: "body 2"},
+			},
+			expectedHits: 2,
+		},
+		{
+			name:       "no-store request bypasses cache",
+			respHeader: http.Header{xhttp.HeaderCacheControl: {"max-age=60"}},
+			reqs: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{header: http.Header{xhttp.HeaderCacheControl: {"no-store"}}, expectedStatus: http.StatusOK, expectedBody: "body 2"},
+			},
+			expectedHits: 2,
+		},
+		{
+			name:       "stale response revalidated with etag",
+			respHeader: http.Header{xhttp.HeaderCacheControl: {"max-age=0"}, xhttp.HeaderEtag: {`"v1"`}},
+			reqs: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+			},
+			expectedHits: 2,
+		},
+		{
+			name:       "no-cache response revalidated with last-modified",
+			respHeader: http.Header{xhttp.HeaderCacheControl: {"no-cache"}, xhttp.HeaderLastModified: {lastModified}},
+			reqs: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+			},
+			expectedHits: 2,
+		},
+		{
+			name:       "no-cache request revalidates fresh response",
+			respHeader: http.Header{xhttp.HeaderCacheControl: {"max-age=60"}},
+			reqs: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{header: http.Header{xhttp.HeaderCacheControl: {"no-cache"}}, expectedStatus: http.StatusOK, expectedBody: "body 2"},
+			},
+			expectedHits: 2,
+		},
+		{
+			name:       "max-stale request accepts stale response",
+			respHeader: http.Header{xhttp.HeaderCacheControl: {"max-age=0"}},
+			reqs: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{header: http.Header{xhttp.HeaderCacheControl: {"max-stale"}}, expectedStatus: http.StatusOK, expectedBody: "body 1"},
+			},
+			expectedHits: 1,
+		},
+		{
+			name:       "only-if-cached request without stored response",
+			respHeader: http.Header{},
+			reqs: []request{
+				{header: http.Header{xhttp.HeaderCacheControl: {"only-if-cached"}}, expectedStatus: http.StatusGatewayTimeout},
+			},
+			expectedHits: 0,
+		},
+		{
+			name:       "vary header mismatch not served from cache",
+			respHeader: http.Header{xhttp.HeaderCacheControl: {"max-age=60"}, xhttp.HeaderVary: {"Accept-Language"}},
+			reqs: []request{
+				{header: http.Header{xhttp.HeaderAcceptLanguage: {"en"}}, expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{header: http.Header{xhttp.HeaderAcceptLanguage: {"en"}}, expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{header: http.Header{xhttp.HeaderAcceptLanguage: {"fr"}}, expectedStatus: http.StatusOK, expectedBody: "body 2"},
+			},
+			expectedHits: 2,
+		},
+		{
+			name:       "unsafe request invalidates stored response",
+			respHeader: http.Header{xhttp.HeaderCacheControl: {"max-age=60"}},
+			reqs: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{method: http.MethodPost, expectedStatus: http.StatusOK, expectedBody: "body 2"},
+				{expectedStatus: http.StatusOK, expectedBody: "body 3"},
+			},
+			expectedHits: 3,
+		},
+		{
+			name:       "authorized request not stored",
+			respHeader: http.Header{xhttp.HeaderCacheControl: {"max-age=60"}},
+			reqs: []request{
+				{header: http.Header{xhttp.HeaderAuthorization: {"Bearer token"}}, expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{header: http.Header{xhttp.HeaderAuthorization: {"Bearer token"}}, expectedStatus: http.StatusOK, expectedBody: "body 2"},
+			},
+			expectedHits: 2,
+		},
+		{
+			name:       "response without freshness nor validator not stored",
+			respHeader: http.Header{},
+			reqs: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{header: http.Header{xhttp.HeaderCacheControl: {"max-stale"}}, expectedStatus: http.StatusOK, expectedBody: "body 2"},
+			},
+			expectedHits: 2,
+		},
+		{
+			name:       "response within max size served from cache",
+			options:    []xhttp.CacheTransportOption{xhttp.CacheTransportMaxSize(6 * xunit.B)},
+			respHeader: http.Header{xhttp.HeaderCacheControl: {"max-age=60"}},
+			reqs: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+			},
+			expectedHits: 1,
+		},
+		{
+			name:       "response larger than max size not stored",
+			options:    []xhttp.CacheTransportOption{xhttp.CacheTransportMaxSize(5 * xunit.B)},
+			respHeader: http.Header{xhttp.HeaderCacheControl: {"max-age=60"}},
+			reqs: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{expectedStatus: http.StatusOK, expectedBody: "body 2"},
+			},
+			expectedHits: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hits := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tc.respHeader {
+					w.Header()[k] = v
+				}
+
+				etag := tc.respHeader.Get(xhttp.HeaderEtag)
+				if (etag != "" && r.Header.Get(xhttp.HeaderIfNoneMatch) == etag) ||
+					(r.Header.Get(xhttp.HeaderIfModifiedSince) != "" && r.Header.Get(xhttp.HeaderIfModifiedSince) == lastModified) {
+					hits++
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+
+				hits++
+				io.WriteString(w, "body "+strconv.Itoa(hits))
+			}))
+			defer srv.Close()
+
+			client := http.Client{Transport: xhttp.NewCacheTransport(tc.options...)}
+
+			for i, r := range tc.reqs {
+				method := r.method
+				if method == "" {
+					method = http.MethodGet
+				}
+
+				req, err := http.NewRequest(method, srv.URL, http.NoBody)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for k, v := range r.header {
+					req.Header[k] = v
+				}
+
+				resp, err := client.Do(req)
+				if err != nil {
+					t.Fatalf("request %d: unexpected error: %v", i, err)
+				}
+				b, err := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if err != nil {
+					t.Fatalf("request %d: unexpected error: %v", i, err)
+				}
+
+				if resp.StatusCode != r.expectedStatus {
+					t.Errorf("request %d: status code mismatch: expected %d; got %d", i, r.expectedStatus, resp.StatusCode)
+				}
+				if string(b) != r.expectedBody {
+					t.Errorf("request %d: body mismatch: expected %q; got %q", i, r.expectedBody, b)
+				}
+			}
+
+			if hits != tc.expectedHits {
+				t.Errorf("origin hits mismatch: expected %d; got %d", tc.expectedHits, hits)
+			}
+		})
+	}
+}
+
+func TestCacheTransport_RoundTrip_Error(t *testing.T) {
+	cacheTransp := xhttp.NewCacheTransport(xhttp.CacheTransportNextRoundTripper(&fakeTransport{}))
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = cacheTransp.RoundTrip(req); err != errNoResponse {
+		t.Errorf("error mismatch: %v != %v", err, errNoResponse)
+	}
+}
+
+func TestCacheTransport_RoundTrip_UnknownLength(t *testing.T) {
+	testCases := []struct {
+		name         string
+		maxSize      xunit.Byte
+		expectedHits int
+	}{
+		{
+			name:         "within max size",
+			maxSize:      4 * xunit.B,
+			expectedHits: 1,
+		},
+		{
+			name:         "larger than max size",
+			maxSize:      3 * xunit.B,
+			expectedHits: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hits := 0
+			next := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				hits++
+				return &http.Response{
+					StatusCode:    http.StatusOK,
+					Header:        http.Header{xhttp.HeaderCacheControl: {"max-age=60"}},
+					Body:          io.NopCloser(strings.NewReader("body")),
+					ContentLength: -1,
+					Request:       r,
+				}, nil
+			})
+			transp := xhttp.NewCacheTransport(xhttp.CacheTransportNextRoundTripper(next), xhttp.CacheTransportMaxSize(tc.maxSize))
+
+			for i := 0; i < 2; i++ {
+				req := httptest.NewRequest(http.MethodGet, "http://example.com", http.NoBody)
+				resp, err := transp.RoundTrip(req)
+				if err != nil {
+					t.Fatalf("request %d: unexpected error: %v", i, err)
+				}
+				b, err := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if err != nil {
+					t.Fatalf("request %d: unexpected error: %v", i, err)
+				}
+				if string(b) != "body" {
+					t.Errorf("request %d: body mismatch: expected %q; got %q", i, "body", b)
+				}
+			}
+
+			if hits != tc.expectedHits {
+				t.Errorf("origin hits mismatch: expected %d; got %d", tc.expectedHits, hits)
+			}
+		})
+	}
+}
+
+func TestCacheTransportCache(t *testing.T) {
+	testCases := []struct {
+		name  string
+		cache xhttp.Cache
+		panic bool
+	}{
+		{
+			name:  "panic",
+			cache: nil,
+			panic: true,
+		},
+		{
+			name:  "valid",
+			cache: xhttp.NewMemoryCache(1),
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, func() xhttp.CacheTransportOption {
+				return xhttp.CacheTransportCache(tc.cache)
+			})
+		})
+	}
+}
+
+func TestCacheTransportMaxSize(t *testing.T) {
+	testCases := []struct {
+		name    string
+		maxSize xunit.Byte
+		panic   bool
+	}{
+		{
+			name:    "panic",
+			maxSize: -1,
+			panic:   true,
+		},
+		{
+			name:    "valid",
+			maxSize: xunit.KiB,
+			panic:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, func() xhttp.CacheTransportOption {
+				return xhttp.CacheTransportMaxSize(tc.maxSize)
+			})
+		})
+	}
+}
+
+func TestCacheTransportNextRoundTripper(t *testing.T) {
+	testCases := []struct {
+		name  string
+		next  http.RoundTripper
+		panic bool
+	}{
+		{
+			name:  "panic",
+			next:  nil,
+			panic: true,
+		},
+		{
+			name:  "valid",
+			next:  &fakeTransport{},
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, func() xhttp.CacheTransportOption {
+				return xhttp.CacheTransportNextRoundTripper(tc.next)
+			})
+		})
+	}
+}
+
+func TestCacheTransport_RoundTrip_Trace(t *testing.T) {
+	etag := `"v1"`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(xhttp.HeaderEtag, etag)
+		if r.URL.Query().Has("stale") {
+			w.Header().Set(xhttp.HeaderCacheControl, "max-age=0")
+		} else {
+			w.Header().Set(xhttp.HeaderCacheControl, "max-age=60")
+		}
+		if r.Header.Get(xhttp.HeaderIfNoneMatch) == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		io.WriteString(w, "body")
+	}))
+	defer srv.Close()
+
+	var events []string
+	ctx := xhttptrace.WithClientTrace(context.Background(), &xhttptrace.ClientTrace{
+		CacheHit: func(i xhttptrace.CacheHitInfo) {
+			events = append(events, fmt.Sprintf("hit %s %t", strings.TrimPrefix(i.Key, srv.URL), i.Revalidated))
+		},
+		CacheMiss: func(i xhttptrace.CacheMissInfo) {
+			events = append(events, fmt.Sprintf("miss %s %t", strings.TrimPrefix(i.Key, srv.URL), i.Stale))
+		},
+	})
+
+	client := http.Client{Transport: xhttp.NewCacheTransport()}
+	for _, path := range []string{"/fresh", "/fresh", "/?stale", "/?stale"} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+path, http.NoBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	expected := []string{
+		"miss /fresh false",
+		"hit /fresh false",
+		"miss /?stale false",
+		"hit /?stale true",
+	}
+	if !reflect.DeepEqual(expected, events) {
+		t.Errorf("events mismatch: expected %q; got %q", expected, events)
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	clientDefaultTimeout               = 30 * time.Second
+	clientDefaultDialTimeout           = 5 * time.Second
+	clientDefaultKeepAlive             = 30 * time.Second
+	clientDefaultTLSHandshakeTimeout   = 5 * time.Second
+	clientDefaultResponseHeaderTimeout = 10 * time.Second
+	clientDefaultExpectContinueTimeout = time.Second
+	clientDefaultIdleConnTimeout       = 90 * time.Second
+	clientDefaultMaxIdleConns          = 100
+	clientDefaultMaxIdleConnsPerHost   = 10
+)
+
+type client struct {
+	timeout             time.Duration
+	jar                 http.CookieJar
+	transport           http.RoundTripper
+	maxConnsPerHost     int
+	maxIdleConnsPerHost int
+	tokenSource         TokenSource
+	observer            Observer
+	retry               bool
+	retryOptions        []RetryTransportOption
+}
+
+// NewClient creates a new http.Client configured with the options passed in input.
+//
+// Requests go through the following transports, from the client to the network: an ObserveTransport,
+// if an observer is configured, a RetryTransport, unless disabled, an AuthTransport, if a token source
+// is configured, a DecompressTransport and the base transport.
+//
+// By default, requests time out after 30s and the base transport is an http.Transport dialing with a 5s
+// timeout, waiting for TLS handshakes and response headers up to 5s and 10s, and keeping up to 10 idle
+// connections per host for 90s. The client has no cookie jar.
+func NewClient(options ...ClientOption) *http.Client {
+	c := &client{
+		timeout:             clientDefaultTimeout,
+		maxIdleConnsPerHost: clientDefaultMaxIdleConnsPerHost,
+		retry:               true,
+	}
+
+	for _, opt := range options {
+		opt.apply(c)
+	}
+
+	transport := c.transport
+	if transport == nil {
+		transport = c.newTransport()
+	}
+
+	transport = NewDecompressTransport(DecompressTransportNextRoundTripper(transport))
+
+	if c.tokenSource != nil {
+		transport = NewAuthTransport(c.tokenSource, AuthTransportNextRoundTripper(transport))
+	}
+
+	if c.retry {
+		retryOptions := append(append([]RetryTransportOption(nil), c.retryOptions...), RetryTransportNextRoundTripper(transport))
+		transport = NewRetryTransport(retryOptions...)
+	}
+
+	if c.observer != nil {
+		transport = NewObserveTransport(ObserveTransportObserver(c.observer), ObserveTransportNextRoundTripper(transport))
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Jar:       c.jar,
+		Timeout:   c.timeout,
+	}
+}
+
+func (c *client) newTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   clientDefaultDialTimeout,
+		KeepAlive: clientDefaultKeepAlive,
+	}
+
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSHandshakeTimeout:   clientDefaultTLSHandshakeTimeout,
+		ResponseHeaderTimeout: clientDefaultResponseHeaderTimeout,
+		ExpectContinueTimeout: clientDefaultExpectContinueTimeout,
+		IdleConnTimeout:       clientDefaultIdleConnTimeout,
+		MaxIdleConns:          max(clientDefaultMaxIdleConns, c.maxIdleConnsPerHost),
+		MaxIdleConnsPerHost:   c.maxIdleConnsPerHost,
+		MaxConnsPerHost:       c.maxConnsPerHost,
+	}
+}
+
+type (
+	// ClientOption configures the client options when calling NewClient.
+	ClientOption interface {
+		apply(c *client)
+	}
+
+	funcClientOption struct {
+		fn func(*client)
+	}
+)
+
+func newFuncClientOption(fn func(*client)) funcClientOption {
+	return funcClientOption{
+		fn: fn,
+	}
+}
+
+func (o funcClientOption) apply(c *client) {
+	o.fn(c)
+}
+
+// ClientCookieJar returns a ClientOption that configures the cookie jar of the client,
+// e.g. created with cookiejar.New. Value must not be nil, otherwise it panics.
+func ClientCookieJar(jar http.CookieJar) ClientOption {
+	if jar == nil {
+		panic("cookie jar is nil")
+	}
+	return newFuncClientOption(func(c *client) {
+		c.jar = jar
+	})
+}
+
+// ClientMaxConnsPerHost returns a ClientOption that configures the max number of connections per host
+// of the base transport, 0 meaning no limit. It has no effect along with ClientTransport.
+// Value must be >= 0, otherwise it panics.
+func ClientMaxConnsPerHost(n int) ClientOption {
+	if n < 0 {
+		panic("invalid max conns per host value")
+	}
+	return newFuncClientOption(func(c *client) {
+		c.maxConnsPerHost = n
+	})
+}
+
+// ClientMaxIdleConnsPerHost returns a ClientOption that configures the max number of idle connections
+// per host kept by the base transport. It has no effect along with ClientTransport.
+// Value must be > 0, otherwise it panics.
+func ClientMaxIdleConnsPerHost(n int) ClientOption {
+	if n <= 0 {
+		panic("invalid max idle conns per host value")
+	}
+	return newFuncClientOption(func(c *client) {
+		c.maxIdleConnsPerHost = n
+	})
+}
+
+// ClientNoRetry returns a ClientOption that configures the client not to retry requests.
+func ClientNoRetry() ClientOption {
+	return newFuncClientOption(func(c *client) {
+		c.retry = false
+	})
+}
+
+// ClientObserver returns a ClientOption that configures the observer notified of the requests
+// of the client, e.g. NewSlogObserver. Value must not be nil, otherwise it panics.
+func ClientObserver(observer Observer) ClientOption {
+	if observer == nil {
+		panic("observer is nil")
+	}
+	return newFuncClientOption(func(c *client) {
+		c.observer = observer
+	})
+}
+
+// ClientRetryOptions returns a ClientOption that configures the RetryTransport of the client.
+// The next round tripper of the RetryTransport is set by NewClient.
+func ClientRetryOptions(options ...RetryTransportOption) ClientOption {
+	return newFuncClientOption(func(c *client) {
+		c.retryOptions = options
+	})
+}
+
+// ClientTimeout returns a ClientOption that configures the time limit of requests made by the client,
+// including reading the response body. 0 means no timeout. Value must be >= 0, otherwise it panics.
+func ClientTimeout(timeout time.Duration) ClientOption {
+	if timeout < 0 {
+		panic("invalid timeout value")
+	}
+	return newFuncClientOption(func(c *client) {
+		c.timeout = timeout
+	})
+}
+
+// ClientTokenSource returns a ClientOption that configures the source of the tokens authenticating
+// the requests of the client with an AuthTransport. Value must not be nil, otherwise it panics.
+func ClientTokenSource(source TokenSource) ClientOption {
+	if source == nil {
+		panic("token source is nil")
+	}
+	return newFuncClientOption(func(c *client) {
+		c.tokenSource = source
+	})
+}
+
+// ClientTransport returns a ClientOption that configures the base transport of the client,
+// replacing the default http.Transport. Value must not be nil, otherwise it panics.
+func ClientTransport(transport http.RoundTripper) ClientOption {
+	if transport == nil {
+		panic("transport is nil")
+	}
+	return newFuncClientOption(func(c *client) {
+		c.transport = transport
+	})
+}
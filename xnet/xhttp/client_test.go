@@ -0,0 +1,172 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestNewClient(t *testing.T) {
+	var attempts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		if auth := r.Header.Get(xhttp.HeaderAuthorization); auth != "Bearer token" {
+			t.Errorf("authorization mismatch: expected %q; got %q", "Bearer token", auth)
+		}
+		if _, err := r.Cookie("session"); err != nil {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "id"})
+		}
+
+		w.Header().Set(xhttp.HeaderContentEncoding, xhttp.EncodingGzip)
+		w.Write(gzipString(t, "hello"))
+	}))
+	defer srv.Close()
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	observer := &fakeObserver{}
+
+	client := xhttp.NewClient(
+		xhttp.ClientCookieJar(jar),
+		xhttp.ClientObserver(observer),
+		xhttp.ClientRetryOptions(xhttp.RetryTransportInitialInterval(time.Millisecond)),
+		xhttp.ClientTimeout(time.Second),
+		xhttp.ClientTokenSource(xhttp.StaticTokenSource(&xhttp.Token{Scheme: xhttp.AuthSchemeBearer, Value: "token"})),
+	)
+
+	if client.Timeout != time.Second {
+		t.Errorf("timeout mismatch: expected %s; got %s", time.Second, client.Timeout)
+	}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status code mismatch: expected %d; got %d", http.StatusOK, resp.StatusCode)
+	}
+	if string(b) != "hello" {
+		t.Errorf("body mismatch: expected %q; got %q", "hello", b)
+	}
+	if len(observer.observations) != 1 || observer.observations[0].Attempts != 2 {
+		t.Errorf("observations mismatch: expected 1 observation with 2 attempts; got %+v", observer.observations)
+	}
+	if cookies := jar.Cookies(resp.Request.URL); len(cookies) != 1 {
+		t.Errorf("cookies mismatch: expected 1 cookie; got %v", cookies)
+	}
+}
+
+func TestNewClient_NoRetry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	client := xhttp.NewClient(xhttp.ClientNoRetry(), xhttp.ClientTransport(http.DefaultTransport))
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status code mismatch: expected %d; got %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+}
+
+func TestClientOptions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func() xhttp.ClientOption
+		panic bool
+	}{
+		{
+			name:  "cookie jar nil",
+			fn:    func() xhttp.ClientOption { return xhttp.ClientCookieJar(nil) },
+			panic: true,
+		},
+		{
+			name:  "max conns per host negative",
+			fn:    func() xhttp.ClientOption { return xhttp.ClientMaxConnsPerHost(-1) },
+			panic: true,
+		},
+		{
+			name:  "max conns per host valid",
+			fn:    func() xhttp.ClientOption { return xhttp.ClientMaxConnsPerHost(0) },
+			panic: false,
+		},
+		{
+			name:  "max idle conns per host zero",
+			fn:    func() xhttp.ClientOption { return xhttp.ClientMaxIdleConnsPerHost(0) },
+			panic: true,
+		},
+		{
+			name:  "max idle conns per host valid",
+			fn:    func() xhttp.ClientOption { return xhttp.ClientMaxIdleConnsPerHost(10) },
+			panic: false,
+		},
+		{
+			name:  "observer nil",
+			fn:    func() xhttp.ClientOption { return xhttp.ClientObserver(nil) },
+			panic: true,
+		},
+		{
+			name:  "observer valid",
+			fn:    func() xhttp.ClientOption { return xhttp.ClientObserver(&fakeObserver{}) },
+			panic: false,
+		},
+		{
+			name:  "timeout negative",
+			fn:    func() xhttp.ClientOption { return xhttp.ClientTimeout(-1) },
+			panic: true,
+		},
+		{
+			name:  "timeout valid",
+			fn:    func() xhttp.ClientOption { return xhttp.ClientTimeout(0) },
+			panic: false,
+		},
+		{
+			name:  "token source nil",
+			fn:    func() xhttp.ClientOption { return xhttp.ClientTokenSource(nil) },
+			panic: true,
+		},
+		{
+			name:  "transport nil",
+			fn:    func() xhttp.ClientOption { return xhttp.ClientTransport(nil) },
+			panic: true,
+		},
+		{
+			name:  "transport valid",
+			fn:    func() xhttp.ClientOption { return xhttp.ClientTransport(&fakeTransport{}) },
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, tc.fn)
+		})
+	}
+}
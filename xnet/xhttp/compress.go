@@ -0,0 +1,357 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jlourenc/xgo/xunit"
+)
+
+const compressDefaultMinSize = xunit.KiB
+
+var compressDefaultEncoders = map[string]Encoder{
+	EncodingDeflate: func(w io.Writer) io.WriteCloser {
+		fw, _ := flate.NewWriter(w, flate.DefaultCompression) //nolint:errcheck // default level is valid.
+		return fw
+	},
+	EncodingGzip: func(w io.Writer) io.WriteCloser {
+		return gzip.NewWriter(w)
+	},
+}
+
+// Encoder returns a writer compressing data written to w with a content coding.
+// Data is expected to be flushed to w on Close and, if the writer implements
+// interface{ Flush() error }, on Flush.
+type Encoder func(w io.Writer) io.WriteCloser
+
+type compress struct {
+	codings      []string
+	encoders     map[string]Encoder
+	contentTypes []string
+	minSize      xunit.Byte
+}
+
+// Compress returns a Middleware compressing response bodies with the content coding preferred by
+// the client, as announced by the request Accept-Encoding header, configured with the options passed
+// in input. By default, gzip and deflate are supported and text/*, application/json, application/javascript,
+// application/xml and image/svg+xml responses of at least 1KiB are compressed.
+//
+// Responses already encoded, with no content, with the no-transform directive or to HEAD and range
+// requests are never compressed.
+// When the response is compressed, its Content-Length header is removed and its ETag made weak.
+func Compress(options ...CompressOption) Middleware {
+	c := &compress{
+		encoders: map[string]Encoder{},
+		contentTypes: []string{
+			"text/*",
+			"application/json",
+			"application/javascript",
+			"application/xml",
+			"image/svg+xml",
+		},
+		minSize: compressDefaultMinSize,
+	}
+
+	for _, opt := range options {
+		opt.apply(c)
+	}
+
+	for _, coding := range []string{EncodingGzip, EncodingDeflate} {
+		if !c.hasCoding(coding) {
+			c.codings = append(c.codings, coding)
+			c.encoders[coding] = compressDefaultEncoders[coding]
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add(HeaderVary, HeaderAcceptEncoding)
+
+			coding := c.negotiate(r.Header)
+			if coding == "" || r.Method == http.MethodHead || r.Header.Get(HeaderRange) != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: w,
+				c:              c,
+				coding:         coding,
+			}
+			defer cw.close()
+
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+func (c *compress) hasCoding(coding string) bool {
+	for _, cc := range c.codings {
+		if cc == coding {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiate returns the supported content coding with the highest weight in the Accept-Encoding header,
+// ties being broken by the server preference, or "" if none is acceptable.
+func (c *compress) negotiate(headers http.Header) string {
+	accepts := ParseAcceptEncoding(headers)
+	if len(accepts) == 0 {
+		return ""
+	}
+
+	weights := make(map[string]float64, len(accepts))
+	// Values are ordered by decreasing weight: keep the first weight of duplicated codings.
+	for i := len(accepts) - 1; i >= 0; i-- {
+		weights[accepts[i].Value] = accepts[i].Q
+	}
+
+	var (
+		best  string
+		bestQ float64
+	)
+	for _, coding := range c.codings {
+		q, ok := weights[coding]
+		if !ok {
+			q = weights["*"]
+		}
+		if q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+func (c *compress) isCompressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, ct := range c.contentTypes {
+		if ct == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(ct, "*"); ok && strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressWriter is a http.ResponseWriter buffering the response body until enough data is written
+// to decide whether to compress it.
+type compressWriter struct {
+	http.ResponseWriter
+
+	c      *compress
+	coding string
+
+	status  int
+	buf     []byte
+	decided bool
+	enc     io.WriteCloser
+}
+
+// Flush makes compressWriter implement the http.Flusher interface.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide()
+	}
+	if f, ok := w.enc.(interface{ Flush() error }); ok {
+		_ = f.Flush() //nolint:errcheck // best effort as per http.Flusher.
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush() //nolint:errcheck // best effort as per http.Flusher.
+}
+
+// Hijack makes compressWriter implement the http.Hijacker interface.
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap returns the wrapped writer, as expected by http.ResponseController.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Write makes compressWriter implement the http.ResponseWriter interface.
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if !w.decided {
+		w.buf = append(w.buf, b...)
+		if xunit.Byte(len(w.buf)) < w.c.minSize {
+			return len(b), nil
+		}
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+
+	if w.enc != nil {
+		return w.enc.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// WriteHeader makes compressWriter implement the http.ResponseWriter interface.
+func (w *compressWriter) WriteHeader(code int) {
+	if code >= http.StatusContinue && code < http.StatusOK && code != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+// decide writes the response header, with the compression headers if the buffered response
+// is to be compressed, followed by the buffered data.
+func (w *compressWriter) decide() error {
+	w.decided = true
+
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	if w.shouldCompress() {
+		h := w.Header()
+		h.Del(HeaderContentLength)
+		h.Set(HeaderContentEncoding, w.coding)
+		if etag := h.Get(HeaderEtag); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set(HeaderEtag, "W/"+etag)
+		}
+		w.enc = w.c.encoders[w.coding](w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+
+	if len(w.buf) == 0 {
+		return nil
+	}
+
+	var err error
+	if w.enc != nil {
+		_, err = w.enc.Write(w.buf)
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf)
+	}
+	w.buf = nil
+	return err
+}
+
+func (w *compressWriter) shouldCompress() bool {
+	if w.status < http.StatusOK || w.status == http.StatusNoContent ||
+		w.status == http.StatusPartialContent || w.status == http.StatusNotModified {
+		return false
+	}
+
+	h := w.Header()
+	if h.Get(HeaderContentEncoding) != "" || h.Get(HeaderContentRange) != "" {
+		return false
+	}
+	if ParseCacheControl(h).NoTransform {
+		return false
+	}
+
+	if len(w.buf) == 0 || xunit.Byte(len(w.buf)) < w.c.minSize {
+		return false
+	}
+	if cl, err := strconv.ParseInt(h.Get(HeaderContentLength), 10, 64); err == nil && xunit.Byte(cl) < w.c.minSize {
+		return false
+	}
+
+	contentType := h.Get(HeaderContentType)
+	if contentType == "" {
+		contentType = http.DetectContentType(w.buf)
+		h.Set(HeaderContentType, contentType)
+	}
+	return w.c.isCompressible(contentType)
+}
+
+func (w *compressWriter) close() {
+	if !w.decided {
+		if w.status == 0 && len(w.buf) == 0 {
+			return
+		}
+		w.decide() //nolint:errcheck // nothing to do with the error once the handler returned.
+	}
+	if w.enc != nil {
+		w.enc.Close()
+	}
+}
+
+type (
+	// CompressOption configures the Compress middleware options when calling Compress.
+	CompressOption interface {
+		apply(c *compress)
+	}
+
+	funcCompressOption struct {
+		fn func(*compress)
+	}
+)
+
+func newFuncCompressOption(fn func(*compress)) funcCompressOption {
+	return funcCompressOption{
+		fn: fn,
+	}
+}
+
+func (o funcCompressOption) apply(c *compress) {
+	o.fn(c)
+}
+
+// CompressContentTypes returns a CompressOption that configures the media types of the responses
+// to compress, e.g. "application/json". A media type ending with "*" matches any media type with the
+// same prefix, e.g. "text/*".
+func CompressContentTypes(types ...string) CompressOption {
+	return newFuncCompressOption(func(c *compress) {
+		c.contentTypes = types
+	})
+}
+
+// CompressEncoder returns a CompressOption that registers the encoder for the content coding,
+// e.g. "br" or "zstd", overriding the built-in one if any. Registered codings are preferred over
+// built-in ones, in the order of their registration, when equally acceptable to the client.
+// Coding must not be empty and encoder must not be nil, otherwise it panics.
+func CompressEncoder(coding string, encoder Encoder) CompressOption {
+	if coding == "" {
+		panic("empty coding")
+	}
+	if encoder == nil {
+		panic("encoder is nil")
+	}
+	coding = strings.ToLower(coding)
+	return newFuncCompressOption(func(c *compress) {
+		if !c.hasCoding(coding) {
+			c.codings = append(c.codings, coding)
+		}
+		c.encoders[coding] = encoder
+	})
+}
+
+// CompressMinSize returns a CompressOption that configures the min size of the responses to compress.
+// Value must be >= 0, otherwise it panics.
+func CompressMinSize(size xunit.Byte) CompressOption {
+	if size < 0 {
+		panic("invalid min size value")
+	}
+	return newFuncCompressOption(func(c *compress) {
+		c.minSize = size
+	})
+}
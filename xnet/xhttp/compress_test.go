@@ -0,0 +1,302 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+type upperWriter struct {
+	w io.Writer
+}
+
+func (u upperWriter) Write(b []byte) (int, error) {
+	return u.w.Write([]byte(strings.ToUpper(string(b))))
+}
+
+func (upperWriter) Close() error {
+	return nil
+}
+
+func TestCompress(t *testing.T) {
+	body := strings.Repeat("hello world ", 200)
+
+	testCases := []struct {
+		name             string
+		options          []xhttp.CompressOption
+		method           string
+		reqHeader        http.Header
+		respHeader       http.Header
+		status           int
+		body             string
+		expectedEncoding string
+	}{
+		{
+			name:             "gzip",
+			reqHeader:        http.Header{xhttp.HeaderAcceptEncoding: {"gzip, deflate"}},
+			body:             body,
+			expectedEncoding: xhttp.EncodingGzip,
+		},
+		{
+			name:             "deflate preferred by weight",
+			reqHeader:        http.Header{xhttp.HeaderAcceptEncoding: {"gzip;q=0.5, deflate"}},
+			body:             body,
+			expectedEncoding: xhttp.EncodingDeflate,
+		},
+		{
+			name:             "wildcard",
+			reqHeader:        http.Header{xhttp.HeaderAcceptEncoding: {"*"}},
+			body:             body,
+			expectedEncoding: xhttp.EncodingGzip,
+		},
+		{
+			name:             "wildcard with excluded coding",
+			reqHeader:        http.Header{xhttp.HeaderAcceptEncoding: {"gzip;q=0, *"}},
+			body:             body,
+			expectedEncoding: xhttp.EncodingDeflate,
+		},
+		{
+			name:             "registered encoder",
+			options:          []xhttp.CompressOption{xhttp.CompressEncoder("upper", func(w io.Writer) io.WriteCloser { return upperWriter{w} })},
+			reqHeader:        http.Header{xhttp.HeaderAcceptEncoding: {"gzip, upper"}},
+			body:             body,
+			expectedEncoding: "upper",
+		},
+		{
+			name:      "no accept encoding",
+			reqHeader: http.Header{},
+			body:      body,
+		},
+		{
+			name:      "unsupported encoding",
+			reqHeader: http.Header{xhttp.HeaderAcceptEncoding: {"br"}},
+			body:      body,
+		},
+		{
+			name:      "below min size",
+			reqHeader: http.Header{xhttp.HeaderAcceptEncoding: {"gzip"}},
+			body:      "hello world",
+		},
+		{
+			name:             "min size",
+			options:          []xhttp.CompressOption{xhttp.CompressMinSize(0)},
+			reqHeader:        http.Header{xhttp.HeaderAcceptEncoding: {"gzip"}},
+			body:             "hello world",
+			expectedEncoding: xhttp.EncodingGzip,
+		},
+		{
+			name:       "content type not compressible",
+			reqHeader:  http.Header{xhttp.HeaderAcceptEncoding: {"gzip"}},
+			respHeader: http.Header{xhttp.HeaderContentType: {"image/png"}},
+			body:       body,
+		},
+		{
+			name:             "content types",
+			options:          []xhttp.CompressOption{xhttp.CompressContentTypes("image/*")},
+			reqHeader:        http.Header{xhttp.HeaderAcceptEncoding: {"gzip"}},
+			respHeader:       http.Header{xhttp.HeaderContentType: {"image/png"}},
+			body:             body,
+			expectedEncoding: xhttp.EncodingGzip,
+		},
+		{
+			name:       "already encoded",
+			reqHeader:  http.Header{xhttp.HeaderAcceptEncoding: {"gzip"}},
+			respHeader: http.Header{xhttp.HeaderContentEncoding: {"custom"}},
+			body:       body,
+		},
+		{
+			name:       "no-transform",
+			reqHeader:  http.Header{xhttp.HeaderAcceptEncoding: {"gzip"}},
+			respHeader: http.Header{xhttp.HeaderCacheControl: {"no-transform"}},
+			body:       body,
+		},
+		{
+			name:      "partial content",
+			reqHeader: http.Header{xhttp.HeaderAcceptEncoding: {"gzip"}},
+			status:    http.StatusPartialContent,
+			body:      body,
+		},
+		{
+			name:      "range request",
+			reqHeader: http.Header{xhttp.HeaderAcceptEncoding: {"gzip"}, xhttp.HeaderRange: {"bytes=0-"}},
+			body:      body,
+		},
+		{
+			name:      "head request",
+			method:    http.MethodHead,
+			reqHeader: http.Header{xhttp.HeaderAcceptEncoding: {"gzip"}},
+		},
+		{
+			name:      "no content",
+			reqHeader: http.Header{xhttp.HeaderAcceptEncoding: {"gzip"}},
+			status:    http.StatusNoContent,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := xhttp.Compress(tc.options...).ThenFunc(func(w http.ResponseWriter, _ *http.Request) {
+				for k, v := range tc.respHeader {
+					w.Header()[k] = v
+				}
+				w.Header().Set(xhttp.HeaderContentLength, strconv.Itoa(len(tc.body)))
+				w.Header().Set(xhttp.HeaderEtag, `"etag"`)
+				if tc.status != 0 {
+					w.WriteHeader(tc.status)
+				}
+				// Write in several chunks to exercise buffering.
+				for i := 0; i < len(tc.body); i += 100 {
+					io.WriteString(w, tc.body[i:min(i+100, len(tc.body))])
+				}
+			})
+
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, "/", http.NoBody)
+			req.Header = tc.reqHeader
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			expectedStatus := tc.status
+			if expectedStatus == 0 {
+				expectedStatus = http.StatusOK
+			}
+			if rec.Code != expectedStatus {
+				t.Errorf("status code mismatch: expected %d; got %d", expectedStatus, rec.Code)
+			}
+			if vary := rec.Header().Get(xhttp.HeaderVary); vary != xhttp.HeaderAcceptEncoding {
+				t.Errorf("vary mismatch: expected %s; got %s", xhttp.HeaderAcceptEncoding, vary)
+			}
+
+			encoding := rec.Header().Get(xhttp.HeaderContentEncoding)
+			if tc.respHeader.Get(xhttp.HeaderContentEncoding) == "" && encoding != tc.expectedEncoding {
+				t.Fatalf("encoding mismatch: expected %q; got %q", tc.expectedEncoding, encoding)
+			}
+
+			var r io.Reader = rec.Body
+			switch tc.expectedEncoding {
+			case "":
+				if etag := rec.Header().Get(xhttp.HeaderEtag); etag != `"etag"` {
+					t.Errorf("etag mismatch: expected %q; got %q", `"etag"`, etag)
+				}
+				if rec.Body.String() != tc.body {
+					t.Errorf("body mismatch: expected %q; got %q", tc.body, rec.Body.String())
+				}
+				return
+			case xhttp.EncodingGzip:
+				gr, err := gzip.NewReader(rec.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				r = gr
+			case xhttp.EncodingDeflate:
+				r = flate.NewReader(rec.Body)
+			}
+
+			if cl := rec.Header().Get(xhttp.HeaderContentLength); cl != "" {
+				t.Errorf("expected no content length; got %s", cl)
+			}
+			if etag := rec.Header().Get(xhttp.HeaderEtag); etag != `W/"etag"` {
+				t.Errorf("etag mismatch: expected %q; got %q", `W/"etag"`, etag)
+			}
+
+			b, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := tc.body
+			if tc.expectedEncoding == "upper" {
+				expected = strings.ToUpper(tc.body)
+			}
+			if string(b) != expected {
+				t.Errorf("body mismatch: expected %q; got %q", expected, b)
+			}
+		})
+	}
+}
+
+func TestCompress_Flush(t *testing.T) {
+	h := xhttp.Compress(xhttp.CompressMinSize(0)).ThenFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, "hello")
+		w.(http.Flusher).Flush()
+		io.WriteString(w, " world")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.Header.Set(xhttp.HeaderAcceptEncoding, xhttp.EncodingGzip)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if !rec.Flushed {
+		t.Error("expected response to be flushed")
+	}
+
+	gr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(gr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "hello world" {
+		t.Errorf("body mismatch: expected %q; got %q", "hello world", b)
+	}
+}
+
+func TestCompressOptions(t *testing.T) {
+	encoder := func(w io.Writer) io.WriteCloser { return upperWriter{w} }
+
+	testCases := []struct {
+		name  string
+		fn    func() xhttp.CompressOption
+		panic bool
+	}{
+		{
+			name:  "encoder with empty coding",
+			fn:    func() xhttp.CompressOption { return xhttp.CompressEncoder("", encoder) },
+			panic: true,
+		},
+		{
+			name:  "encoder with nil encoder",
+			fn:    func() xhttp.CompressOption { return xhttp.CompressEncoder("upper", nil) },
+			panic: true,
+		},
+		{
+			name:  "valid encoder",
+			fn:    func() xhttp.CompressOption { return xhttp.CompressEncoder("upper", encoder) },
+			panic: false,
+		},
+		{
+			name:  "negative min size",
+			fn:    func() xhttp.CompressOption { return xhttp.CompressMinSize(-1) },
+			panic: true,
+		},
+		{
+			name:  "valid min size",
+			fn:    func() xhttp.CompressOption { return xhttp.CompressMinSize(xunit.KB) },
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, tc.fn)
+		})
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"bufio"
+	"bytes"
+	"net/http"
+	"net/http/httputil"
+
+	"github.com/jlourenc/xgo/xio"
+	"github.com/jlourenc/xgo/xnet/xhttp/xhttptrace"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+const (
+	conditionalTransportDefaultCapacity = 1000
+	conditionalTransportDefaultMaxSize  = 1 * xunit.MiB
+)
+
+// ConditionalTransport is an HTTP transport that revalidates the responses to GET requests
+// with conditional requests, e.g. to poll an API without transferring unchanged bodies.
+type conditionalTransport struct {
+	next    http.RoundTripper
+	cache   Cache
+	maxSize xunit.Byte
+}
+
+// NewConditionalTransport creates a new ConditionalTransport configured with the options passed in input,
+// notably the cache storing responses and the next round tripper in the chain.
+// If not configured, responses are stored in an in-memory cache of 1000 entries,
+// and responses whose body is larger than 1MiB are not stored.
+//
+// Unlike a CacheTransport, responses are never served without being revalidated, regardless of their
+// freshness. Responses are stored by URL: the transport should not be shared by requests made on
+// behalf of different users.
+func NewConditionalTransport(options ...ConditionalTransportOption) http.RoundTripper {
+	t := &conditionalTransport{
+		next:    http.DefaultTransport,
+		maxSize: conditionalTransportDefaultMaxSize,
+	}
+
+	for _, opt := range options {
+		opt.apply(t)
+	}
+
+	if t.cache == nil {
+		t.cache = NewMemoryCache(conditionalTransportDefaultCapacity)
+	}
+
+	return t
+}
+
+// RoundTrip makes ConditionalTransport implement the RoundTripper interface.
+//
+// The last 200 OK response to a GET request with an ETag or Last-Modified header is stored and the next
+// requests to the same URL are made conditional with If-None-Match and If-Modified-Since headers. When
+// the server replies with 304 Not Modified, the stored response is returned instead, updated with the
+// headers of the 304 response. Requests already conditional or with a Range header are left untouched.
+func (t *conditionalTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isRequestCacheable(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	trace := xhttptrace.ContextClientTrace(req.Context())
+	if trace == nil {
+		trace = &xhttptrace.ClientTrace{}
+	}
+
+	key := req.URL.String()
+
+	stored := t.load(key, req)
+
+	outReq := req
+	if stored != nil {
+		outReq = conditionalRequest(req, stored.Header)
+	}
+
+	resp, err := t.next.RoundTrip(outReq)
+	if err != nil {
+		if stored != nil {
+			xio.DrainClose(stored.Body)
+		}
+		return resp, err
+	}
+
+	if stored != nil && resp.StatusCode == http.StatusNotModified {
+		xio.DrainClose(resp.Body)
+		for k, v := range resp.Header {
+			if k != HeaderContentLength && k != HeaderTransferEncoding {
+				stored.Header[k] = v
+			}
+		}
+		t.store(key, stored)
+		if trace.CacheHit != nil {
+			trace.CacheHit(xhttptrace.CacheHitInfo{Key: key, Revalidated: true})
+		}
+		return stored, nil
+	}
+
+	if stored != nil {
+		xio.DrainClose(stored.Body)
+	}
+	if trace.CacheMiss != nil {
+		trace.CacheMiss(xhttptrace.CacheMissInfo{Key: key, Stale: stored != nil})
+	}
+
+	if resp.StatusCode == http.StatusOK && (resp.Header.Get(HeaderEtag) != "" || resp.Header.Get(HeaderLastModified) != "") {
+		if !t.store(key, resp) {
+			// The stored response is outdated.
+			t.cache.Delete(key)
+		}
+	} else if resp.StatusCode < http.StatusInternalServerError {
+		// The stored response is outdated.
+		t.cache.Delete(key)
+	}
+
+	return resp, nil
+}
+
+// load returns the response stored under key, nil if none.
+func (t *conditionalTransport) load(key string, req *http.Request) *http.Response {
+	b, ok := t.cache.Get(key)
+	if !ok {
+		return nil
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(b)), req)
+	if err != nil {
+		t.cache.Delete(key)
+		return nil
+	}
+	return resp
+}
+
+// store serializes resp under key, restoring resp's body so that it can still be read.
+// It returns false if resp is not stored, e.g. because its body is larger than the max size.
+func (t *conditionalTransport) store(key string, resp *http.Response) bool {
+	if !bufferResponseBody(resp, t.maxSize) {
+		return false
+	}
+	b, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return false
+	}
+	t.cache.Set(key, b)
+	return true
+}
+
+type (
+	// ConditionalTransportOption configures the ConditionalTransport options
+	// when calling NewConditionalTransport.
+	ConditionalTransportOption interface {
+		apply(t *conditionalTransport)
+	}
+
+	funcConditionalTransportOption struct {
+		fn func(*conditionalTransport)
+	}
+)
+
+func newFuncConditionalTransportOption(fn func(*conditionalTransport)) funcConditionalTransportOption {
+	return funcConditionalTransportOption{
+		fn: fn,
+	}
+}
+
+func (o funcConditionalTransportOption) apply(t *conditionalTransport) {
+	o.fn(t)
+}
+
+// ConditionalTransportCache returns a ConditionalTransportOption that configures the cache
+// storing responses. If not used, an in-memory cache of 1000 entries is used.
+func ConditionalTransportCache(cache Cache) ConditionalTransportOption {
+	if cache == nil {
+		panic("cache is nil")
+	}
+	return newFuncConditionalTransportOption(func(t *conditionalTransport) {
+		t.cache = cache
+	})
+}
+
+// ConditionalTransportMaxSize returns a ConditionalTransportOption that configures the max size of the
+// bodies of stored responses. If not used, 1MiB is used. Value must be >= 0, otherwise it panics.
+func ConditionalTransportMaxSize(maxSize xunit.Byte) ConditionalTransportOption {
+	if maxSize < 0 {
+		panic("invalid max size value")
+	}
+	return newFuncConditionalTransportOption(func(t *conditionalTransport) {
+		t.maxSize = maxSize
+	})
+}
+
+// ConditionalTransportNextRoundTripper returns a ConditionalTransportOption that configures the
+// next round tripper to call. If not used http.DefaultTransport will be used.
+func ConditionalTransportNextRoundTripper(next http.RoundTripper) ConditionalTransportOption {
+	if next == nil {
+		panic("next http.RoundTripper is nil")
+	}
+	return newFuncConditionalTransportOption(func(t *conditionalTransport) {
+		t.next = next
+	})
+}
@@ -0,0 +1,246 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+func TestConditionalTransport_RoundTrip(t *testing.T) {
+	lastModified := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+
+	type request struct {
+		header             http.Header
+		expectedStatus     int
+		expectedBody       string
+		expectedConditions bool
+	}
+
+	testCases := []struct {
+		name       string
+		options    []xhttp.ConditionalTransportOption
+		respHeader http.Header
+		modified   bool
+		reqs       []request
+	}{
+		{
+			name:       "revalidated with etag",
+			respHeader: http.Header{xhttp.HeaderEtag: {`"v1"`}, xhttp.HeaderCacheControl: {"max-age=60"}},
+			reqs: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{expectedStatus: http.StatusOK, expectedBody: "body 1", expectedConditions: true},
+				{expectedStatus: http.StatusOK, expectedBody: "body 1", expectedConditions: true},
+			},
+		},
+		{
+			name:       "revalidated with last-modified",
+			respHeader: http.Header{xhttp.HeaderLastModified: {lastModified}},
+			reqs: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{expectedStatus: http.StatusOK, expectedBody: "body 1", expectedConditions: true},
+			},
+		},
+		{
+			name:       "modified response replaces stored one",
+			respHeader: http.Header{xhttp.HeaderEtag: {`"v1"`}},
+			modified:   true,
+			reqs: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{expectedStatus: http.StatusOK, expectedBody: "body 2", expectedConditions: true},
+				{expectedStatus: http.StatusOK, expectedBody: "body 3", expectedConditions: true},
+			},
+		},
+		{
+			name:       "response without validator not stored",
+			respHeader: http.Header{},
+			reqs: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{expectedStatus: http.StatusOK, expectedBody: "body 2"},
+			},
+		},
+		{
+			name:       "response within max size stored",
+			options:    []xhttp.ConditionalTransportOption{xhttp.ConditionalTransportMaxSize(6 * xunit.B)},
+			respHeader: http.Header{xhttp.HeaderEtag: {`"v1"`}},
+			reqs: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{expectedStatus: http.StatusOK, expectedBody: "body 1", expectedConditions: true},
+			},
+		},
+		{
+			name:       "response larger than max size not stored",
+			options:    []xhttp.ConditionalTransportOption{xhttp.ConditionalTransportMaxSize(5 * xunit.B)},
+			respHeader: http.Header{xhttp.HeaderEtag: {`"v1"`}},
+			reqs: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{expectedStatus: http.StatusOK, expectedBody: "body 2"},
+			},
+		},
+		{
+			name:       "conditional request untouched",
+			respHeader: http.Header{xhttp.HeaderEtag: {`"v1"`}},
+			reqs: []request{
+				{expectedStatus: http.StatusOK, expectedBody: "body 1"},
+				{header: http.Header{xhttp.HeaderIfNoneMatch: {`"v1"`}}, expectedStatus: http.StatusNotModified, expectedConditions: true},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hits := 0
+			var conditions []bool
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				hits++
+				conditional := r.Header.Get(xhttp.HeaderIfNoneMatch) != "" || r.Header.Get(xhttp.HeaderIfModifiedSince) != ""
+				conditions = append(conditions, conditional)
+
+				for k, v := range tc.respHeader {
+					w.Header()[k] = v
+				}
+				if conditional && !tc.modified {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				io.WriteString(w, "body "+strconv.Itoa(hits))
+			}))
+			defer srv.Close()
+
+			client := http.Client{Transport: xhttp.NewConditionalTransport(tc.options...)}
+
+			for i, r := range tc.reqs {
+				req, err := http.NewRequest(http.MethodGet, srv.URL, http.NoBody)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for k, v := range r.header {
+					req.Header[k] = v
+				}
+
+				resp, err := client.Do(req)
+				if err != nil {
+					t.Fatalf("request %d: unexpected error: %v", i, err)
+				}
+				b, err := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if err != nil {
+					t.Fatalf("request %d: unexpected error: %v", i, err)
+				}
+
+				if resp.StatusCode != r.expectedStatus {
+					t.Errorf("request %d: status code mismatch: expected %d; got %d", i, r.expectedStatus, resp.StatusCode)
+				}
+				if string(b) != r.expectedBody {
+					t.Errorf("request %d: body mismatch: expected %q; got %q", i, r.expectedBody, b)
+				}
+				if conditions[i] != r.expectedConditions {
+					t.Errorf("request %d: conditional mismatch: expected %t; got %t", i, r.expectedConditions, conditions[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConditionalTransport_RoundTrip_Error(t *testing.T) {
+	transport := xhttp.NewConditionalTransport(xhttp.ConditionalTransportNextRoundTripper(&fakeTransport{}))
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = transport.RoundTrip(req); err != errNoResponse {
+		t.Errorf("error mismatch: %v != %v", err, errNoResponse)
+	}
+}
+
+func TestConditionalTransportCache(t *testing.T) {
+	testCases := []struct {
+		name  string
+		cache xhttp.Cache
+		panic bool
+	}{
+		{
+			name:  "panic",
+			cache: nil,
+			panic: true,
+		},
+		{
+			name:  "valid",
+			cache: xhttp.NewMemoryCache(1),
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, func() xhttp.ConditionalTransportOption {
+				return xhttp.ConditionalTransportCache(tc.cache)
+			})
+		})
+	}
+}
+
+func TestConditionalTransportMaxSize(t *testing.T) {
+	testCases := []struct {
+		name    string
+		maxSize xunit.Byte
+		panic   bool
+	}{
+		{
+			name:    "panic",
+			maxSize: -1,
+			panic:   true,
+		},
+		{
+			name:    "valid",
+			maxSize: xunit.KiB,
+			panic:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, func() xhttp.ConditionalTransportOption {
+				return xhttp.ConditionalTransportMaxSize(tc.maxSize)
+			})
+		})
+	}
+}
+
+func TestConditionalTransportNextRoundTripper(t *testing.T) {
+	testCases := []struct {
+		name  string
+		next  http.RoundTripper
+		panic bool
+	}{
+		{
+			name:  "panic",
+			next:  nil,
+			panic: true,
+		},
+		{
+			name:  "valid",
+			next:  &fakeTransport{},
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, func() xhttp.ConditionalTransportOption {
+				return xhttp.ConditionalTransportNextRoundTripper(tc.next)
+			})
+		})
+	}
+}
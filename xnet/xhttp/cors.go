@@ -0,0 +1,244 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type cors struct {
+	allowedOrigins   []string
+	allowedMethods   []string
+	allowedHeaders   []string
+	exposedHeaders   []string
+	maxAge           time.Duration
+	allowCredentials bool
+}
+
+// CORS returns a Middleware implementing Cross-Origin Resource Sharing, configured with the options
+// passed in input. By default, all origins are allowed to use the GET, HEAD and POST methods with
+// the Accept, Accept-Language, Content-Language and Content-Type headers, without credentials.
+//
+// Allowing credentials requires an explicit list of allowed origins: it panics if "*" is allowed
+// along with credentials, as any site could then make credentialed requests.
+//
+// Preflight requests are replied to directly, without calling the next handler.
+//
+// See https://fetch.spec.whatwg.org/#http-cors-protocol.
+func CORS(options ...CORSOption) Middleware {
+	c := &cors{
+		allowedOrigins: []string{"*"},
+		allowedMethods: []string{http.MethodGet, http.MethodHead, http.MethodPost},
+		allowedHeaders: []string{HeaderAccept, HeaderAcceptLanguage, HeaderContentLanguage, HeaderContentType},
+	}
+
+	for _, opt := range options {
+		opt.apply(c)
+	}
+
+	if c.allowCredentials {
+		for _, o := range c.allowedOrigins {
+			if o == "*" {
+				panic("wildcard origin with credentials")
+			}
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions && r.Header.Get(HeaderAccessControlRequestMethod) != "" {
+				c.handlePreflight(w, r)
+				return
+			}
+
+			c.handleActual(w, r)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (c *cors) handlePreflight(w http.ResponseWriter, r *http.Request) {
+	h := w.Header()
+	h.Add(HeaderVary, HeaderOrigin)
+	h.Add(HeaderVary, HeaderAccessControlRequestMethod)
+	h.Add(HeaderVary, HeaderAccessControlRequestHeaders)
+
+	defer w.WriteHeader(http.StatusNoContent)
+
+	origin := r.Header.Get(HeaderOrigin)
+	if origin == "" || !c.isOriginAllowed(origin) {
+		return
+	}
+
+	method := r.Header.Get(HeaderAccessControlRequestMethod)
+	if !c.isMethodAllowed(method) {
+		return
+	}
+
+	reqHeaders := HeaderValues(r.Header, HeaderAccessControlRequestHeaders)
+	if !c.areHeadersAllowed(reqHeaders) {
+		return
+	}
+
+	c.setAllowOrigin(h, origin)
+	h.Set(HeaderAccessControlAllowMethods, method)
+	if len(reqHeaders) > 0 {
+		h.Set(HeaderAccessControlAllowHeaders, strings.Join(reqHeaders, ", "))
+	}
+	if c.maxAge > 0 {
+		h.Set(HeaderAccessControlMaxAge, strconv.Itoa(int(c.maxAge/time.Second)))
+	}
+}
+
+func (c *cors) handleActual(w http.ResponseWriter, r *http.Request) {
+	h := w.Header()
+	h.Add(HeaderVary, HeaderOrigin)
+
+	origin := r.Header.Get(HeaderOrigin)
+	if origin == "" || !c.isOriginAllowed(origin) {
+		return
+	}
+
+	c.setAllowOrigin(h, origin)
+	if len(c.exposedHeaders) > 0 {
+		h.Set(HeaderAccessControlExposeHeaders, strings.Join(c.exposedHeaders, ", "))
+	}
+}
+
+func (c *cors) setAllowOrigin(h http.Header, origin string) {
+	if c.allowCredentials {
+		// The wildcard is not allowed with credentials: https://fetch.spec.whatwg.org/#cors-protocol-and-credentials.
+		h.Set(HeaderAccessControlAllowOrigin, origin)
+		h.Set(HeaderAccessControlAllowCredentials, "true")
+		return
+	}
+
+	for _, o := range c.allowedOrigins {
+		if o == "*" {
+			h.Set(HeaderAccessControlAllowOrigin, "*")
+			return
+		}
+	}
+	h.Set(HeaderAccessControlAllowOrigin, origin)
+}
+
+func (c *cors) isOriginAllowed(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, o := range c.allowedOrigins {
+		if o == "*" || o == origin {
+			return true
+		}
+		if prefix, suffix, ok := strings.Cut(o, "*"); ok &&
+			len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *cors) isMethodAllowed(method string) bool {
+	for _, m := range c.allowedMethods {
+		if m == "*" || m == method {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *cors) areHeadersAllowed(headers []string) bool {
+	for _, header := range headers {
+		allowed := false
+		for _, h := range c.allowedHeaders {
+			if h == "*" || strings.EqualFold(h, header) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
+
+type (
+	// CORSOption configures the CORS middleware options when calling CORS.
+	CORSOption interface {
+		apply(c *cors)
+	}
+
+	funcCORSOption struct {
+		fn func(*cors)
+	}
+)
+
+func newFuncCORSOption(fn func(*cors)) funcCORSOption {
+	return funcCORSOption{
+		fn: fn,
+	}
+}
+
+func (o funcCORSOption) apply(c *cors) {
+	o.fn(c)
+}
+
+// CORSAllowCredentials returns a CORSOption that configures the middleware to allow requests
+// with credentials, such as cookies or authorization headers. Allowed origins must be configured
+// explicitly with CORSAllowedOrigins, without "*".
+func CORSAllowCredentials() CORSOption {
+	return newFuncCORSOption(func(c *cors) {
+		c.allowCredentials = true
+	})
+}
+
+// CORSAllowedHeaders returns a CORSOption that configures the request headers allowed in
+// cross-origin requests. "*" allows any header.
+func CORSAllowedHeaders(headers ...string) CORSOption {
+	return newFuncCORSOption(func(c *cors) {
+		c.allowedHeaders = headers
+	})
+}
+
+// CORSAllowedMethods returns a CORSOption that configures the methods allowed in
+// cross-origin requests. "*" allows any method.
+func CORSAllowedMethods(methods ...string) CORSOption {
+	return newFuncCORSOption(func(c *cors) {
+		c.allowedMethods = methods
+	})
+}
+
+// CORSAllowedOrigins returns a CORSOption that configures the origins allowed to make
+// cross-origin requests, e.g. "https://example.com". "*" allows any origin and an origin may contain
+// a single wildcard to match several origins, e.g. "https://*.example.com".
+func CORSAllowedOrigins(origins ...string) CORSOption {
+	lowered := make([]string, len(origins))
+	for i, o := range origins {
+		lowered[i] = strings.ToLower(o)
+	}
+	return newFuncCORSOption(func(c *cors) {
+		c.allowedOrigins = lowered
+	})
+}
+
+// CORSExposedHeaders returns a CORSOption that configures the response headers exposed
+// to cross-origin requests.
+func CORSExposedHeaders(headers ...string) CORSOption {
+	return newFuncCORSOption(func(c *cors) {
+		c.exposedHeaders = headers
+	})
+}
+
+// CORSMaxAge returns a CORSOption that configures how long the results of a preflight request
+// can be cached, with a second precision. Value must be >= 0, otherwise it panics.
+func CORSMaxAge(maxAge time.Duration) CORSOption {
+	if maxAge < 0 {
+		panic("invalid max age value")
+	}
+	return newFuncCORSOption(func(c *cors) {
+		c.maxAge = maxAge
+	})
+}
@@ -0,0 +1,225 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestCORS(t *testing.T) {
+	testCases := []struct {
+		name            string
+		options         []xhttp.CORSOption
+		method          string
+		reqHeader       http.Header
+		expectedCode    int
+		expectedHeader  http.Header
+		expectedHandled bool
+	}{
+		{
+			name:            "no origin",
+			method:          http.MethodGet,
+			expectedCode:    http.StatusOK,
+			expectedHeader:  http.Header{xhttp.HeaderVary: {"Origin"}},
+			expectedHandled: true,
+		},
+		{
+			name:         "actual request with any origin allowed",
+			method:       http.MethodGet,
+			reqHeader:    http.Header{xhttp.HeaderOrigin: {"https://example.com"}},
+			expectedCode: http.StatusOK,
+			expectedHeader: http.Header{
+				xhttp.HeaderVary:                     {"Origin"},
+				xhttp.HeaderAccessControlAllowOrigin: {"*"},
+			},
+			expectedHandled: true,
+		},
+		{
+			name: "actual request with credentials and exposed headers",
+			options: []xhttp.CORSOption{
+				xhttp.CORSAllowedOrigins("https://*.example.com"),
+				xhttp.CORSAllowCredentials(),
+				xhttp.CORSExposedHeaders("X-Total-Count", "Link"),
+			},
+			method:       http.MethodGet,
+			reqHeader:    http.Header{xhttp.HeaderOrigin: {"https://api.example.com"}},
+			expectedCode: http.StatusOK,
+			expectedHeader: http.Header{
+				xhttp.HeaderVary:                          {"Origin"},
+				xhttp.HeaderAccessControlAllowOrigin:      {"https://api.example.com"},
+				xhttp.HeaderAccessControlAllowCredentials: {"true"},
+				xhttp.HeaderAccessControlExposeHeaders:    {"X-Total-Count, Link"},
+			},
+			expectedHandled: true,
+		},
+		{
+			name:            "actual request with disallowed origin",
+			options:         []xhttp.CORSOption{xhttp.CORSAllowedOrigins("https://Example.com")},
+			method:          http.MethodGet,
+			reqHeader:       http.Header{xhttp.HeaderOrigin: {"https://example.org"}},
+			expectedCode:    http.StatusOK,
+			expectedHeader:  http.Header{xhttp.HeaderVary: {"Origin"}},
+			expectedHandled: true,
+		},
+		{
+			name: "preflight request allowed",
+			options: []xhttp.CORSOption{
+				xhttp.CORSAllowedOrigins("https://Example.com"),
+				xhttp.CORSAllowedMethods(http.MethodPut),
+				xhttp.CORSAllowedHeaders("Content-Type", "X-Custom"),
+				xhttp.CORSMaxAge(10 * time.Minute),
+			},
+			method: http.MethodOptions,
+			reqHeader: http.Header{
+				xhttp.HeaderOrigin:                      {"https://example.com"},
+				xhttp.HeaderAccessControlRequestMethod:  {http.MethodPut},
+				xhttp.HeaderAccessControlRequestHeaders: {"content-type, x-custom"},
+			},
+			expectedCode: http.StatusNoContent,
+			expectedHeader: http.Header{
+				xhttp.HeaderVary:                      {"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+				xhttp.HeaderAccessControlAllowOrigin:  {"https://example.com"},
+				xhttp.HeaderAccessControlAllowMethods: {http.MethodPut},
+				xhttp.HeaderAccessControlAllowHeaders: {"content-type, x-custom"},
+				xhttp.HeaderAccessControlMaxAge:       {"600"},
+			},
+		},
+		{
+			name:    "preflight request with disallowed method",
+			options: []xhttp.CORSOption{xhttp.CORSAllowedMethods(http.MethodGet)},
+			method:  http.MethodOptions,
+			reqHeader: http.Header{
+				xhttp.HeaderOrigin:                     {"https://example.com"},
+				xhttp.HeaderAccessControlRequestMethod: {http.MethodDelete},
+			},
+			expectedCode: http.StatusNoContent,
+			expectedHeader: http.Header{
+				xhttp.HeaderVary: {"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+			},
+		},
+		{
+			name:   "preflight request with disallowed header",
+			method: http.MethodOptions,
+			reqHeader: http.Header{
+				xhttp.HeaderOrigin:                      {"https://example.com"},
+				xhttp.HeaderAccessControlRequestMethod:  {http.MethodGet},
+				xhttp.HeaderAccessControlRequestHeaders: {"X-Custom"},
+			},
+			expectedCode: http.StatusNoContent,
+			expectedHeader: http.Header{
+				xhttp.HeaderVary: {"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+			},
+		},
+		{
+			name:            "options request without preflight",
+			method:          http.MethodOptions,
+			reqHeader:       http.Header{xhttp.HeaderOrigin: {"https://example.com"}},
+			expectedCode:    http.StatusOK,
+			expectedHeader:  http.Header{xhttp.HeaderVary: {"Origin"}, xhttp.HeaderAccessControlAllowOrigin: {"*"}},
+			expectedHandled: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handled := false
+			h := xhttp.CORS(tc.options...).ThenFunc(func(http.ResponseWriter, *http.Request) {
+				handled = true
+			})
+
+			req := httptest.NewRequest(tc.method, "/", http.NoBody)
+			req.Header = tc.reqHeader
+			if req.Header == nil {
+				req.Header = http.Header{}
+			}
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tc.expectedCode {
+				t.Errorf("status code mismatch: expected %d; got %d", tc.expectedCode, rec.Code)
+			}
+			if handled != tc.expectedHandled {
+				t.Errorf("handled mismatch: expected %t; got %t", tc.expectedHandled, handled)
+			}
+			if len(rec.Header()) != len(tc.expectedHeader) {
+				t.Errorf("header mismatch: expected %v; got %v", tc.expectedHeader, rec.Header())
+			}
+			for k, v := range tc.expectedHeader {
+				if got := rec.Header()[k]; !slices.Equal(got, v) {
+					t.Errorf("header %s mismatch: expected %q; got %q", k, v, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCORS_Panic(t *testing.T) {
+	testCases := []struct {
+		name    string
+		options []xhttp.CORSOption
+		panic   bool
+	}{
+		{
+			name:    "credentials with default origins",
+			options: []xhttp.CORSOption{xhttp.CORSAllowCredentials()},
+			panic:   true,
+		},
+		{
+			name:    "credentials with wildcard origin",
+			options: []xhttp.CORSOption{xhttp.CORSAllowedOrigins("https://example.com", "*"), xhttp.CORSAllowCredentials()},
+			panic:   true,
+		},
+		{
+			name:    "credentials with explicit origins",
+			options: []xhttp.CORSOption{xhttp.CORSAllowedOrigins("https://*.example.com"), xhttp.CORSAllowCredentials()},
+			panic:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tc.panic {
+					t.Errorf("panic mismatch: expected %t; got %v", tc.panic, r)
+				}
+			}()
+
+			xhttp.CORS(tc.options...)
+		})
+	}
+}
+
+func TestCORSMaxAge(t *testing.T) {
+	testCases := []struct {
+		name   string
+		maxAge time.Duration
+		panic  bool
+	}{
+		{
+			name:   "panic",
+			maxAge: -1,
+			panic:  true,
+		},
+		{
+			name:   "valid",
+			maxAge: 0,
+			panic:  false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, func() xhttp.CORSOption {
+				return xhttp.CORSMaxAge(tc.maxAge)
+			})
+		})
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Decoder returns a reader decompressing data read from r encoded with a content coding.
+type Decoder func(r io.Reader) (io.ReadCloser, error)
+
+var decompressTransportDefaultDecoders = map[string]Decoder{
+	EncodingDeflate: func(r io.Reader) (io.ReadCloser, error) {
+		return flate.NewReader(r), nil
+	},
+	EncodingGzip: func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+}
+
+// DecompressTransport is an HTTP transport that negotiates compressed responses and decodes them
+// transparently, so that callers always read plain response bodies.
+type decompressTransport struct {
+	next     http.RoundTripper
+	codings  []string
+	decoders map[string]Decoder
+}
+
+// NewDecompressTransport creates a new DecompressTransport configured with the options passed in input,
+// notably the decoders and the next round tripper in the chain. By default, gzip and deflate are supported.
+func NewDecompressTransport(options ...DecompressTransportOption) http.RoundTripper {
+	t := &decompressTransport{
+		next:     http.DefaultTransport,
+		decoders: map[string]Decoder{},
+	}
+
+	for _, opt := range options {
+		opt.apply(t)
+	}
+
+	for _, coding := range []string{EncodingGzip, EncodingDeflate} {
+		if _, ok := t.decoders[coding]; !ok {
+			t.codings = append(t.codings, coding)
+			t.decoders[coding] = decompressTransportDefaultDecoders[coding]
+		}
+	}
+
+	return t
+}
+
+// RoundTrip makes DecompressTransport implement the RoundTripper interface.
+//
+// The Accept-Encoding header of the request is set to the supported codings, unless already set or
+// the request is a range request, in which case the response is returned untouched. When the response is encoded with supported
+// codings, its body is decoded and its Content-Encoding and Content-Length headers are removed.
+func (t *decompressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get(HeaderAcceptEncoding) != "" || req.Header.Get(HeaderRange) != "" {
+		return t.next.RoundTrip(req)
+	}
+
+	r := req.Clone(req.Context())
+	r.Header.Set(HeaderAcceptEncoding, strings.Join(t.codings, ", "))
+
+	resp, err := t.next.RoundTrip(r)
+	if err != nil {
+		return resp, err
+	}
+
+	if req.Method == http.MethodHead {
+		return resp, nil
+	}
+
+	codings := HeaderValues(resp.Header, HeaderContentEncoding)
+	decoders := make([]Decoder, 0, len(codings))
+	// Codings are listed in the order in which they were applied.
+	for i := len(codings) - 1; i >= 0; i-- {
+		coding := strings.ToLower(codings[i])
+		if coding == EncodingIdentity || coding == "" {
+			continue
+		}
+		dec, ok := t.decoders[coding]
+		if !ok {
+			return resp, nil
+		}
+		decoders = append(decoders, dec)
+	}
+	if len(decoders) == 0 {
+		return resp, nil
+	}
+
+	if resp.Body != nil && resp.Body != http.NoBody {
+		resp.Body = &decompressBody{
+			body:     resp.Body,
+			decoders: decoders,
+		}
+		resp.Header.Del(HeaderContentLength)
+		resp.ContentLength = -1
+	}
+	resp.Header.Del(HeaderContentEncoding)
+	resp.Uncompressed = true
+
+	return resp, nil
+}
+
+// decompressBody is a response body lazily decoded on first read.
+type decompressBody struct {
+	body     io.ReadCloser
+	decoders []Decoder
+
+	r       io.Reader
+	closers []io.Closer
+	err     error
+}
+
+func (b *decompressBody) Close() error {
+	for i := len(b.closers) - 1; i >= 0; i-- {
+		b.closers[i].Close()
+	}
+	return b.body.Close()
+}
+
+func (b *decompressBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	if b.r == nil {
+		var r io.Reader = b.body
+		for _, dec := range b.decoders {
+			rc, err := dec(r)
+			if err != nil {
+				b.err = err
+				return 0, err
+			}
+			b.closers = append(b.closers, rc)
+			r = rc
+		}
+		b.r = r
+	}
+
+	return b.r.Read(p)
+}
+
+type (
+	// DecompressTransportOption configures the DecompressTransport options
+	// when calling NewDecompressTransport.
+	DecompressTransportOption interface {
+		apply(t *decompressTransport)
+	}
+
+	funcDecompressTransportOption struct {
+		fn func(*decompressTransport)
+	}
+)
+
+func newFuncDecompressTransportOption(fn func(*decompressTransport)) funcDecompressTransportOption {
+	return funcDecompressTransportOption{
+		fn: fn,
+	}
+}
+
+func (o funcDecompressTransportOption) apply(t *decompressTransport) {
+	o.fn(t)
+}
+
+// DecompressTransportDecoder returns a DecompressTransportOption that registers the decoder for the
+// content coding, e.g. "br" or "zstd", overriding the built-in one if any. Registered codings are
+// advertised before built-in ones, in the order of their registration.
+// Coding must not be empty and decoder must not be nil, otherwise it panics.
+func DecompressTransportDecoder(coding string, decoder Decoder) DecompressTransportOption {
+	if coding == "" {
+		panic("empty coding")
+	}
+	if decoder == nil {
+		panic("decoder is nil")
+	}
+	coding = strings.ToLower(coding)
+	return newFuncDecompressTransportOption(func(t *decompressTransport) {
+		if _, ok := t.decoders[coding]; !ok {
+			t.codings = append(t.codings, coding)
+		}
+		t.decoders[coding] = decoder
+	})
+}
+
+// DecompressTransportNextRoundTripper returns a DecompressTransportOption that configures the
+// next round tripper to call. If not used http.DefaultTransport will be used.
+func DecompressTransportNextRoundTripper(next http.RoundTripper) DecompressTransportOption {
+	if next == nil {
+		panic("next http.RoundTripper is nil")
+	}
+	return newFuncDecompressTransportOption(func(t *decompressTransport) {
+		t.next = next
+	})
+}
@@ -0,0 +1,232 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func gzipString(tb testing.TB, s string) []byte {
+	tb.Helper()
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(gw, s); err != nil {
+		tb.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		tb.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecompressTransport_RoundTrip(t *testing.T) {
+	upperDecoder := func(r io.Reader) (io.ReadCloser, error) {
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(strings.NewReader(strings.ToLower(string(b)))), nil
+	}
+
+	testCases := []struct {
+		name                   string
+		options                []xhttp.DecompressTransportOption
+		reqHeader              http.Header
+		respEncoding           string
+		respBody               []byte
+		expectedAcceptEncoding string
+		expectedEncoding       string
+		expectedBody           string
+	}{
+		{
+			name:                   "gzip",
+			respEncoding:           "gzip",
+			respBody:               gzipString(t, "hello world"),
+			expectedAcceptEncoding: "gzip, deflate",
+			expectedBody:           "hello world",
+		},
+		{
+			name:                   "identity",
+			respBody:               []byte("hello world"),
+			expectedAcceptEncoding: "gzip, deflate",
+			expectedBody:           "hello world",
+		},
+		{
+			name:                   "empty gzip body",
+			respEncoding:           "gzip",
+			respBody:               []byte{},
+			expectedAcceptEncoding: "gzip, deflate",
+			expectedBody:           "",
+		},
+		{
+			name:                   "multiple codings",
+			options:                []xhttp.DecompressTransportOption{xhttp.DecompressTransportDecoder("upper", upperDecoder)},
+			respEncoding:           "upper, gzip",
+			respBody:               gzipString(t, "HELLO WORLD"),
+			expectedAcceptEncoding: "upper, gzip, deflate",
+			expectedBody:           "hello world",
+		},
+		{
+			name:                   "unsupported coding",
+			respEncoding:           "br",
+			respBody:               []byte("brotli"),
+			expectedAcceptEncoding: "gzip, deflate",
+			expectedEncoding:       "br",
+			expectedBody:           "brotli",
+		},
+		{
+			name:                   "accept encoding set by caller",
+			reqHeader:              http.Header{xhttp.HeaderAcceptEncoding: {"gzip"}},
+			respEncoding:           "gzip",
+			respBody:               gzipString(t, "hello world"),
+			expectedAcceptEncoding: "gzip",
+			expectedEncoding:       "gzip",
+			expectedBody:           string(gzipString(t, "hello world")),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var acceptEncoding string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				acceptEncoding = r.Header.Get(xhttp.HeaderAcceptEncoding)
+				if tc.respEncoding != "" {
+					w.Header().Set(xhttp.HeaderContentEncoding, tc.respEncoding)
+				}
+				w.Write(tc.respBody)
+			}))
+			defer srv.Close()
+
+			options := append([]xhttp.DecompressTransportOption{xhttp.DecompressTransportNextRoundTripper(srv.Client().Transport)}, tc.options...)
+			client := http.Client{Transport: xhttp.NewDecompressTransport(options...)}
+
+			req, err := http.NewRequest(http.MethodGet, srv.URL, http.NoBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, v := range tc.reqHeader {
+				req.Header[k] = v
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			b, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if acceptEncoding != tc.expectedAcceptEncoding {
+				t.Errorf("accept encoding mismatch: expected %q; got %q", tc.expectedAcceptEncoding, acceptEncoding)
+			}
+			if encoding := resp.Header.Get(xhttp.HeaderContentEncoding); encoding != tc.expectedEncoding {
+				t.Errorf("content encoding mismatch: expected %q; got %q", tc.expectedEncoding, encoding)
+			}
+			if string(b) != tc.expectedBody {
+				t.Errorf("body mismatch: expected %q; got %q", tc.expectedBody, b)
+			}
+		})
+	}
+}
+
+func TestDecompressTransport_RoundTrip_Compress(t *testing.T) {
+	body := strings.Repeat("hello world ", 200)
+
+	srv := httptest.NewServer(xhttp.Compress().ThenFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+
+	client := http.Client{Transport: xhttp.NewDecompressTransport(xhttp.DecompressTransportNextRoundTripper(srv.Client().Transport))}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !resp.Uncompressed {
+		t.Error("expected uncompressed response")
+	}
+	if resp.ContentLength != -1 {
+		t.Errorf("content length mismatch: expected -1; got %d", resp.ContentLength)
+	}
+	if string(b) != body {
+		t.Errorf("body mismatch: expected %q; got %q", body, b)
+	}
+}
+
+func TestDecompressTransport_RoundTrip_Error(t *testing.T) {
+	decompressTransp := xhttp.NewDecompressTransport(xhttp.DecompressTransportNextRoundTripper(&fakeTransport{}))
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = decompressTransp.RoundTrip(req); err != errNoResponse {
+		t.Errorf("error mismatch: %v != %v", err, errNoResponse)
+	}
+}
+
+func TestDecompressTransportOptions(t *testing.T) {
+	decoder := func(r io.Reader) (io.ReadCloser, error) { return io.NopCloser(r), nil }
+
+	testCases := []struct {
+		name  string
+		fn    func() xhttp.DecompressTransportOption
+		panic bool
+	}{
+		{
+			name:  "decoder with empty coding",
+			fn:    func() xhttp.DecompressTransportOption { return xhttp.DecompressTransportDecoder("", decoder) },
+			panic: true,
+		},
+		{
+			name:  "decoder with nil decoder",
+			fn:    func() xhttp.DecompressTransportOption { return xhttp.DecompressTransportDecoder("br", nil) },
+			panic: true,
+		},
+		{
+			name:  "valid decoder",
+			fn:    func() xhttp.DecompressTransportOption { return xhttp.DecompressTransportDecoder("br", decoder) },
+			panic: false,
+		},
+		{
+			name:  "nil next round tripper",
+			fn:    func() xhttp.DecompressTransportOption { return xhttp.DecompressTransportNextRoundTripper(nil) },
+			panic: true,
+		},
+		{
+			name: "valid next round tripper",
+			fn: func() xhttp.DecompressTransportOption {
+				return xhttp.DecompressTransportNextRoundTripper(&fakeTransport{})
+			},
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, tc.fn)
+		})
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"sync"
+
+	"github.com/jlourenc/xgo/xunit"
+)
+
+const dumpRedacted = "[REDACTED]"
+
+// DumpTransport is an HTTP transport that dumps requests and responses to a writer, for debugging purposes.
+type dumpTransport struct {
+	next            http.RoundTripper
+	mu              sync.Mutex
+	w               io.Writer
+	body            bool
+	maxBodySize     xunit.Byte
+	redactedHeaders []string
+}
+
+// NewDumpTransport creates a new DumpTransport configured with the options passed in input, notably
+// the writer and the next round tripper in the chain. By default, request and response heads are dumped
+// to os.Stderr, without bodies, and the values of the Authorization, Cookie, Proxy-Authorization and
+// Set-Cookie headers are redacted.
+func NewDumpTransport(options ...DumpTransportOption) http.RoundTripper {
+	t := &dumpTransport{
+		next:            http.DefaultTransport,
+		w:               os.Stderr,
+		redactedHeaders: []string{HeaderAuthorization, HeaderCookie, HeaderProxyAuthorization, HeaderSetCookie},
+	}
+
+	for _, opt := range options {
+		opt.apply(t)
+	}
+
+	return t
+}
+
+// RoundTrip makes DumpTransport implement the RoundTripper interface.
+func (t *dumpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var buf bytes.Buffer
+
+	r := req.Clone(req.Context())
+	r.Header = t.redact(req.Header)
+	if head, err := httputil.DumpRequestOut(r, false); err == nil {
+		buf.Write(head)
+	}
+	if t.body && req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if req.Body, err = t.dumpBody(&buf, req.Body); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n\r\n")
+	}
+	t.write(buf.Bytes())
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.write([]byte(fmt.Sprintf("error: %v\r\n\r\n", err)))
+		return resp, err
+	}
+
+	buf.Reset()
+	dumped := *resp
+	dumped.Header = t.redact(resp.Header)
+	if head, derr := httputil.DumpResponse(&dumped, false); derr == nil {
+		buf.Write(head)
+	}
+	if t.body && resp.Body != nil && resp.Body != http.NoBody {
+		if resp.Body, err = t.dumpBody(&buf, resp.Body); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n\r\n")
+	}
+	t.write(buf.Bytes())
+
+	return resp, nil
+}
+
+// dumpBody writes to buf at most maxBodySize bytes of body and returns a body yielding the same bytes.
+func (t *dumpTransport) dumpBody(buf *bytes.Buffer, body io.ReadCloser) (io.ReadCloser, error) {
+	var peeked bytes.Buffer
+	n, err := io.CopyN(&peeked, body, int64(t.maxBodySize)+1)
+	if err != nil && err != io.EOF {
+		body.Close()
+		return nil, err
+	}
+
+	if xunit.Byte(n) > t.maxBodySize {
+		buf.Write(peeked.Bytes()[:t.maxBodySize])
+		fmt.Fprintf(buf, "\r\n[truncated at %s]", t.maxBodySize)
+	} else {
+		buf.Write(peeked.Bytes())
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.MultiReader(&peeked, body),
+		Closer: body,
+	}, nil
+}
+
+// redact returns a copy of headers whose redacted headers values are replaced.
+func (t *dumpTransport) redact(headers http.Header) http.Header {
+	h := headers.Clone()
+	for _, key := range t.redactedHeaders {
+		if values := h.Values(key); len(values) > 0 {
+			redacted := make([]string, len(values))
+			for i := range redacted {
+				redacted[i] = dumpRedacted
+			}
+			h[http.CanonicalHeaderKey(key)] = redacted
+		}
+	}
+	return h
+}
+
+func (t *dumpTransport) write(b []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.w.Write(b) //nolint:errcheck // dumping is best effort.
+}
+
+type (
+	// DumpTransportOption configures the DumpTransport options when calling NewDumpTransport.
+	DumpTransportOption interface {
+		apply(t *dumpTransport)
+	}
+
+	funcDumpTransportOption struct {
+		fn func(*dumpTransport)
+	}
+)
+
+func newFuncDumpTransportOption(fn func(*dumpTransport)) funcDumpTransportOption {
+	return funcDumpTransportOption{
+		fn: fn,
+	}
+}
+
+func (o funcDumpTransportOption) apply(t *dumpTransport) {
+	o.fn(t)
+}
+
+// DumpTransportBody returns a DumpTransportOption that configures the transport to dump request
+// and response bodies, truncated to maxSize. Value must be >= 0, otherwise it panics.
+func DumpTransportBody(maxSize xunit.Byte) DumpTransportOption {
+	if maxSize < 0 {
+		panic("invalid max body size value")
+	}
+	return newFuncDumpTransportOption(func(t *dumpTransport) {
+		t.body = true
+		t.maxBodySize = maxSize
+	})
+}
+
+// DumpTransportNextRoundTripper returns a DumpTransportOption that configures the
+// next round tripper to call. If not used http.DefaultTransport will be used.
+func DumpTransportNextRoundTripper(next http.RoundTripper) DumpTransportOption {
+	if next == nil {
+		panic("next http.RoundTripper is nil")
+	}
+	return newFuncDumpTransportOption(func(t *dumpTransport) {
+		t.next = next
+	})
+}
+
+// DumpTransportRedactedHeaders returns a DumpTransportOption that configures the headers
+// whose values are redacted, replacing the default ones.
+func DumpTransportRedactedHeaders(headers ...string) DumpTransportOption {
+	return newFuncDumpTransportOption(func(t *dumpTransport) {
+		t.redactedHeaders = headers
+	})
+}
+
+// DumpTransportWriter returns a DumpTransportOption that configures the writer to dump to.
+// If not used, os.Stderr is used.
+func DumpTransportWriter(w io.Writer) DumpTransportOption {
+	if w == nil {
+		panic("writer is nil")
+	}
+	return newFuncDumpTransportOption(func(t *dumpTransport) {
+		t.w = w
+	})
+}
@@ -0,0 +1,211 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+func TestDumpTransport_RoundTrip(t *testing.T) {
+	testCases := []struct {
+		name        string
+		options     []xhttp.DumpTransportOption
+		contains    []string
+		notContains []string
+	}{
+		{
+			name: "heads only",
+			contains: []string{
+				"POST /path?q=1 HTTP/1.1",
+				"Authorization: [REDACTED]",
+				"X-Custom: value",
+				"HTTP/1.1 200 OK",
+				"Set-Cookie: [REDACTED]",
+			},
+			notContains: []string{"secret", "request body", "response body"},
+		},
+		{
+			name:    "bodies",
+			options: []xhttp.DumpTransportOption{xhttp.DumpTransportBody(xunit.KB)},
+			contains: []string{
+				"request body",
+				"response body",
+			},
+		},
+		{
+			name:    "truncated bodies",
+			options: []xhttp.DumpTransportOption{xhttp.DumpTransportBody(7 * xunit.B)},
+			contains: []string{
+				"request\r\n[truncated at 7B]",
+				"respons\r\n[truncated at 7B]",
+			},
+			notContains: []string{"request body", "response body"},
+		},
+		{
+			name:    "redacted headers",
+			options: []xhttp.DumpTransportOption{xhttp.DumpTransportRedactedHeaders("X-Custom")},
+			contains: []string{
+				"Authorization: Bearer secret",
+				"X-Custom: [REDACTED]",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if b, _ := io.ReadAll(r.Body); string(b) != "request body" {
+					t.Errorf("request body mismatch: expected %q; got %q", "request body", b)
+				}
+				w.Header().Set(xhttp.HeaderSetCookie, "session=secret")
+				io.WriteString(w, "response body")
+			}))
+			defer srv.Close()
+
+			var buf bytes.Buffer
+			options := append([]xhttp.DumpTransportOption{
+				xhttp.DumpTransportWriter(&buf),
+				xhttp.DumpTransportNextRoundTripper(srv.Client().Transport),
+			}, tc.options...)
+			client := http.Client{Transport: xhttp.NewDumpTransport(options...)}
+
+			req, err := http.NewRequest(http.MethodPost, srv.URL+"/path?q=1", strings.NewReader("request body"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set(xhttp.HeaderAuthorization, "Bearer secret")
+			req.Header.Set("X-Custom", "value")
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(b) != "response body" {
+				t.Errorf("response body mismatch: expected %q; got %q", "response body", b)
+			}
+			for _, s := range tc.contains {
+				if !strings.Contains(buf.String(), s) {
+					t.Errorf("expected %q in dump:\n%s", s, buf.String())
+				}
+			}
+			for _, s := range tc.notContains {
+				if strings.Contains(buf.String(), s) {
+					t.Errorf("expected no %q in dump:\n%s", s, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestDumpTransport_RoundTrip_Error(t *testing.T) {
+	errRead := errors.New("read error")
+
+	testCases := []struct {
+		name          string
+		body          io.Reader
+		expectedError error
+		expectedDump  string
+	}{
+		{
+			name:          "next round tripper error",
+			body:          strings.NewReader("body"),
+			expectedError: errNoResponse,
+			expectedDump:  "error: no response",
+		},
+		{
+			name:          "body read error",
+			body:          iotest.ErrReader(errRead),
+			expectedError: errRead,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			transp := xhttp.NewDumpTransport(
+				xhttp.DumpTransportWriter(&buf),
+				xhttp.DumpTransportBody(xunit.KB),
+				xhttp.DumpTransportNextRoundTripper(&fakeTransport{}),
+			)
+
+			req, err := http.NewRequest(http.MethodPost, "http://example.com", tc.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err = transp.RoundTrip(req); err != tc.expectedError {
+				t.Errorf("error mismatch: %v != %v", err, tc.expectedError)
+			}
+			if !strings.Contains(buf.String(), tc.expectedDump) {
+				t.Errorf("expected %q in dump:\n%s", tc.expectedDump, buf.String())
+			}
+		})
+	}
+}
+
+func TestDumpTransportOptions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func() xhttp.DumpTransportOption
+		panic bool
+	}{
+		{
+			name:  "negative max body size",
+			fn:    func() xhttp.DumpTransportOption { return xhttp.DumpTransportBody(-1) },
+			panic: true,
+		},
+		{
+			name:  "valid max body size",
+			fn:    func() xhttp.DumpTransportOption { return xhttp.DumpTransportBody(0) },
+			panic: false,
+		},
+		{
+			name:  "nil next round tripper",
+			fn:    func() xhttp.DumpTransportOption { return xhttp.DumpTransportNextRoundTripper(nil) },
+			panic: true,
+		},
+		{
+			name:  "valid next round tripper",
+			fn:    func() xhttp.DumpTransportOption { return xhttp.DumpTransportNextRoundTripper(&fakeTransport{}) },
+			panic: false,
+		},
+		{
+			name:  "redacted headers",
+			fn:    func() xhttp.DumpTransportOption { return xhttp.DumpTransportRedactedHeaders() },
+			panic: false,
+		},
+		{
+			name:  "nil writer",
+			fn:    func() xhttp.DumpTransportOption { return xhttp.DumpTransportWriter(nil) },
+			panic: true,
+		},
+		{
+			name:  "valid writer",
+			fn:    func() xhttp.DumpTransportOption { return xhttp.DumpTransportWriter(io.Discard) },
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, tc.fn)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xhttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/jlourenc/xgo/xerrors"
+)
+
+// WriteError replies to the request with the HTTP status code attached to err
+// (see xerrors.HTTPStatus) and its standard status text as plain text body.
+// The error message itself is not written to the response to avoid leaking
+// internal details to clients.
+// If err is nil, WriteError does nothing.
+func WriteError(w http.ResponseWriter, err error) {
+	if err == nil {
+		return
+	}
+
+	code := xerrors.HTTPStatus(err)
+	http.Error(w, http.StatusText(code), code)
+}
+
+// IsRetryableError reports whether err is a transport-level error that is usually transient,
+// so that retrying the request is likely to succeed: unexpected connection closures (io.EOF,
+// io.ErrUnexpectedEOF), connection resets or refusals, DNS errors flagged as timeouts or temporary,
+// and network timeouts. Errors caused by a canceled or expired context are not retryable.
+//
+// It is meant to be used with RetryTransportRetryOnError.
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || isConnResetOrRefused(err) {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
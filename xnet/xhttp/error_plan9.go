@@ -0,0 +1,17 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xhttp
+
+import "strings"
+
+// isConnResetOrRefused reports whether err is caused by a connection reset or refusal.
+// Plan 9 reports network errors as plain strings, so the error message is matched.
+func isConnResetOrRefused(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "connection reset") || strings.Contains(msg, "connection refused")
+}
@@ -0,0 +1,9 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xhttp_test
+
+import "errors"
+
+var errConnReset = errors.New("connection reset by peer")
@@ -0,0 +1,17 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !plan9
+
+package xhttp
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isConnResetOrRefused reports whether err is caused by a connection reset or refusal.
+func isConnResetOrRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED)
+}
@@ -0,0 +1,11 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !plan9
+
+package xhttp_test
+
+import "syscall"
+
+var errConnReset error = syscall.ECONNRESET
@@ -0,0 +1,119 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xhttp_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/jlourenc/xgo/xerrors"
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "unexpected EOF",
+			err:      fmt.Errorf("read: %w", io.ErrUnexpectedEOF),
+			expected: true,
+		},
+		{
+			name:     "connection reset",
+			err:      &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", errConnReset)},
+			expected: true,
+		},
+		{
+			name:     "DNS timeout",
+			err:      &net.DNSError{Err: "timeout", Name: "example.com", IsTimeout: true},
+			expected: true,
+		},
+		{
+			name:     "DNS not found",
+			err:      &net.DNSError{Err: "no such host", Name: "example.com", IsNotFound: true},
+			expected: false,
+		},
+		{
+			name:     "network timeout",
+			err:      &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded},
+			expected: true,
+		},
+		{
+			name:     "context canceled",
+			err:      fmt.Errorf("round trip: %w", context.Canceled),
+			expected: false,
+		},
+		{
+			name:     "other error",
+			err:      xerrors.New("failure"),
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := xhttp.IsRetryableError(tc.err); got != tc.expected {
+				t.Errorf("expected %t; got %t", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	testCases := []struct {
+		name         string
+		err          error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "nil error",
+			err:          nil,
+			expectedCode: http.StatusOK,
+			expectedBody: "",
+		},
+		{
+			name:         "error without status",
+			err:          xerrors.New("secret failure"),
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: "Internal Server Error\n",
+		},
+		{
+			name:         "error with status",
+			err:          xerrors.Wrap(xerrors.WithHTTPStatus(xerrors.New("secret failure"), http.StatusNotFound), "wrapped"),
+			expectedCode: http.StatusNotFound,
+			expectedBody: "Not Found\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+
+			xhttp.WriteError(rec, tc.err)
+
+			if rec.Code != tc.expectedCode {
+				t.Errorf("expected status code %d; got %d", tc.expectedCode, rec.Code)
+			}
+			if got := rec.Body.String(); got != tc.expectedBody {
+				t.Errorf("expected body %q; got %q", tc.expectedBody, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const etagWeakPrefix = "W/"
+
+// ComputeETag returns an entity tag identifying the content read from r until EOF, derived from its
+// SHA-256 hash, e.g. `"uU0nuZNNPgilLlLX2n2r-sSE7-N6U4DukIj3rOLvzek"` for "hello world". If weak is true, the entity tag
+// is marked as weak, i.e. prefixed with "W/".
+// https://datatracker.ietf.org/doc/html/rfc9110#section-8.8.3
+func ComputeETag(r io.Reader, weak bool) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+
+	etag := `"` + base64.RawURLEncoding.EncodeToString(h.Sum(nil)) + `"`
+	if weak {
+		etag = etagWeakPrefix + etag
+	}
+	return etag, nil
+}
+
+// CheckConditional evaluates the preconditions of the request r, i.e. its If-Match, If-Unmodified-Since,
+// If-None-Match and If-Modified-Since headers, against the current state of the selected representation,
+// described by its entity tag and last modification time, following the precedence defined in
+// https://datatracker.ietf.org/doc/html/rfc9110#section-13.2.2. An empty etag and a zero lastModified
+// denote a representation without validator, or no current representation if both are missing.
+//
+// It returns the status code to reply with: http.StatusOK if the request is to be processed normally,
+// http.StatusNotModified or http.StatusPreconditionFailed otherwise.
+func CheckConditional(r *http.Request, etag string, lastModified time.Time) int {
+	exists := etag != "" || !lastModified.IsZero()
+	isGetOrHead := r.Method == http.MethodGet || r.Method == http.MethodHead
+
+	if ifMatch := r.Header.Values(HeaderIfMatch); len(ifMatch) > 0 {
+		if !matchETags(ifMatch, etag, exists, true) {
+			return http.StatusPreconditionFailed
+		}
+	} else if since, ok := parseConditionalTime(r.Header, HeaderIfUnmodifiedSince); ok && !lastModified.IsZero() {
+		if lastModified.Truncate(time.Second).After(since) {
+			return http.StatusPreconditionFailed
+		}
+	}
+
+	if ifNoneMatch := r.Header.Values(HeaderIfNoneMatch); len(ifNoneMatch) > 0 {
+		if matchETags(ifNoneMatch, etag, exists, false) {
+			if isGetOrHead {
+				return http.StatusNotModified
+			}
+			return http.StatusPreconditionFailed
+		}
+	} else if since, ok := parseConditionalTime(r.Header, HeaderIfModifiedSince); ok && isGetOrHead && !lastModified.IsZero() {
+		if !lastModified.Truncate(time.Second).After(since) {
+			return http.StatusNotModified
+		}
+	}
+
+	return http.StatusOK
+}
+
+// matchETags returns whether etag matches one of the entity tags listed in values,
+// using the strong or weak comparison function.
+func matchETags(values []string, etag string, exists, strong bool) bool {
+	for _, v := range splitHeaderList(values) {
+		if v == "*" {
+			if exists {
+				return true
+			}
+			continue
+		}
+		if etag != "" && compareETags(v, etag, strong) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareETags compares two entity tags as defined in https://datatracker.ietf.org/doc/html/rfc9110#section-8.8.3.2.
+func compareETags(a, b string, strong bool) bool {
+	aOpaque, aWeak := strings.CutPrefix(a, etagWeakPrefix)
+	bOpaque, bWeak := strings.CutPrefix(b, etagWeakPrefix)
+	if strong && (aWeak || bWeak) {
+		return false
+	}
+	return aOpaque == bOpaque
+}
+
+func parseConditionalTime(headers http.Header, key string) (time.Time, bool) {
+	v := headers.Get(key)
+	if v == "" {
+		return time.Time{}, false
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,218 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestComputeETag(t *testing.T) {
+	errRead := errors.New("read error")
+
+	testCases := []struct {
+		name          string
+		content       string
+		weak          bool
+		err           error
+		expected      string
+		expectedError error
+	}{
+		{
+			name:     "empty",
+			content:  "",
+			expected: `"47DEQpj8HBSa-_TImW-5JCeuQeRkm5NMpJWZG3hSuFU"`,
+		},
+		{
+			name:     "strong",
+			content:  "hello world",
+			expected: `"uU0nuZNNPgilLlLX2n2r-sSE7-N6U4DukIj3rOLvzek"`,
+		},
+		{
+			name:     "weak",
+			content:  "hello world",
+			weak:     true,
+			expected: `W/"uU0nuZNNPgilLlLX2n2r-sSE7-N6U4DukIj3rOLvzek"`,
+		},
+		{
+			name:          "read error",
+			err:           errRead,
+			expectedError: errRead,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := iotest.OneByteReader(strings.NewReader(tc.content))
+			if tc.err != nil {
+				r = iotest.ErrReader(tc.err)
+			}
+
+			got, err := xhttp.ComputeETag(r, tc.weak)
+			if !errors.Is(err, tc.expectedError) {
+				t.Fatalf("error mismatch: expected %v; got %v", tc.expectedError, err)
+			}
+			if got != tc.expected {
+				t.Errorf("expected %s; got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestCheckConditional(t *testing.T) {
+	lastModified := time.Date(2024, time.January, 1, 12, 0, 0, 500, time.UTC)
+	before := lastModified.Add(-time.Hour).Format(http.TimeFormat)
+	at := lastModified.Format(http.TimeFormat)
+
+	testCases := []struct {
+		name         string
+		method       string
+		headers      http.Header
+		etag         string
+		lastModified time.Time
+		expected     int
+	}{
+		{
+			name:     "no precondition",
+			headers:  http.Header{},
+			etag:     `"v1"`,
+			expected: http.StatusOK,
+		},
+		{
+			name:     "if-match matches",
+			method:   http.MethodPut,
+			headers:  http.Header{xhttp.HeaderIfMatch: {`"v0", "v1"`}},
+			etag:     `"v1"`,
+			expected: http.StatusOK,
+		},
+		{
+			name:     "if-match does not match",
+			method:   http.MethodPut,
+			headers:  http.Header{xhttp.HeaderIfMatch: {`"v0"`}},
+			etag:     `"v1"`,
+			expected: http.StatusPreconditionFailed,
+		},
+		{
+			name:     "if-match uses strong comparison",
+			method:   http.MethodPut,
+			headers:  http.Header{xhttp.HeaderIfMatch: {`W/"v1"`}},
+			etag:     `W/"v1"`,
+			expected: http.StatusPreconditionFailed,
+		},
+		{
+			name:     "if-match wildcard with representation",
+			method:   http.MethodPut,
+			headers:  http.Header{xhttp.HeaderIfMatch: {"*"}},
+			etag:     `"v1"`,
+			expected: http.StatusOK,
+		},
+		{
+			name:     "if-match wildcard without representation",
+			method:   http.MethodPut,
+			headers:  http.Header{xhttp.HeaderIfMatch: {"*"}},
+			expected: http.StatusPreconditionFailed,
+		},
+		{
+			name:         "if-unmodified-since not modified",
+			method:       http.MethodPut,
+			headers:      http.Header{xhttp.HeaderIfUnmodifiedSince: {at}},
+			lastModified: lastModified,
+			expected:     http.StatusOK,
+		},
+		{
+			name:         "if-unmodified-since modified",
+			method:       http.MethodPut,
+			headers:      http.Header{xhttp.HeaderIfUnmodifiedSince: {before}},
+			lastModified: lastModified,
+			expected:     http.StatusPreconditionFailed,
+		},
+		{
+			name:         "if-match takes precedence over if-unmodified-since",
+			method:       http.MethodPut,
+			headers:      http.Header{xhttp.HeaderIfMatch: {`"v1"`}, xhttp.HeaderIfUnmodifiedSince: {before}},
+			etag:         `"v1"`,
+			lastModified: lastModified,
+			expected:     http.StatusOK,
+		},
+		{
+			name:     "if-none-match matches on get",
+			headers:  http.Header{xhttp.HeaderIfNoneMatch: {`"v0", W/"v1"`}},
+			etag:     `"v1"`,
+			expected: http.StatusNotModified,
+		},
+		{
+			name:     "if-none-match matches on put",
+			method:   http.MethodPut,
+			headers:  http.Header{xhttp.HeaderIfNoneMatch: {"*"}},
+			etag:     `"v1"`,
+			expected: http.StatusPreconditionFailed,
+		},
+		{
+			name:     "if-none-match wildcard without representation",
+			method:   http.MethodPut,
+			headers:  http.Header{xhttp.HeaderIfNoneMatch: {"*"}},
+			expected: http.StatusOK,
+		},
+		{
+			name:     "if-none-match does not match",
+			headers:  http.Header{xhttp.HeaderIfNoneMatch: {`"v0"`}},
+			etag:     `"v1"`,
+			expected: http.StatusOK,
+		},
+		{
+			name:         "if-modified-since not modified",
+			headers:      http.Header{xhttp.HeaderIfModifiedSince: {at}},
+			lastModified: lastModified,
+			expected:     http.StatusNotModified,
+		},
+		{
+			name:         "if-modified-since modified",
+			headers:      http.Header{xhttp.HeaderIfModifiedSince: {before}},
+			lastModified: lastModified,
+			expected:     http.StatusOK,
+		},
+		{
+			name:         "if-modified-since ignored on post",
+			method:       http.MethodPost,
+			headers:      http.Header{xhttp.HeaderIfModifiedSince: {at}},
+			lastModified: lastModified,
+			expected:     http.StatusOK,
+		},
+		{
+			name:         "if-modified-since invalid date",
+			headers:      http.Header{xhttp.HeaderIfModifiedSince: {"invalid"}},
+			lastModified: lastModified,
+			expected:     http.StatusOK,
+		},
+		{
+			name:         "if-none-match takes precedence over if-modified-since",
+			headers:      http.Header{xhttp.HeaderIfNoneMatch: {`"v0"`}, xhttp.HeaderIfModifiedSince: {at}},
+			etag:         `"v1"`,
+			lastModified: lastModified,
+			expected:     http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			r := httptest.NewRequest(method, "/", http.NoBody)
+			r.Header = tc.headers
+
+			if got := xhttp.CheckConditional(r, tc.etag, tc.lastModified); got != tc.expected {
+				t.Errorf("expected %d; got %d", tc.expected, got)
+			}
+		})
+	}
+}
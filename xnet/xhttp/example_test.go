@@ -15,6 +15,20 @@ import (
 	"github.com/jlourenc/xgo/xnet/xhttp/xhttptrace"
 )
 
+func ExampleCacheControl_Apply() {
+	maxAge := time.Hour
+	cc := xhttp.CacheControl{
+		Public: true,
+		MaxAge: &maxAge,
+	}
+
+	headers := http.Header{}
+	cc.Apply(headers)
+
+	fmt.Printf("got: %s", headers.Get(xhttp.HeaderCacheControl))
+	// Output: got: public, max-age=3600
+}
+
 func ExampleHeaderExist() {
 	headers := http.Header{
 		"Header-Key": {"key1=val1", "key2", "key3=val3, key4"},
@@ -75,6 +89,17 @@ func ExampleNewRetryTransport() {
 	}
 }
 
+func ExampleParseCacheControl() {
+	headers := http.Header{
+		xhttp.HeaderCacheControl: {"no-cache, max-age=60"},
+	}
+
+	cc := xhttp.ParseCacheControl(headers)
+
+	fmt.Printf("no-cache: %t, max-age: %s", cc.NoCache, *cc.MaxAge)
+	// Output: no-cache: true, max-age: 1m0s
+}
+
 func ExampleParseHeaderDate() {
 	headers := http.Header{
 		xhttp.HeaderDate: []string{"Sun, 10 Jul 2016 21:12:00.499 GMT"},
@@ -0,0 +1,155 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ForwardedElement is an element of the Forwarded header, describing a hop of the request
+// through a proxy. Fields are empty when the corresponding parameter is absent.
+// https://datatracker.ietf.org/doc/html/rfc7239#section-4
+type ForwardedElement struct {
+	// By is the interface where the request came in to the proxy.
+	By string
+
+	// For is the node making the request to the proxy, e.g. "192.0.2.43" or "[2001:db8:cafe::17]:4711".
+	For string
+
+	// Host is the Host request header as received by the proxy.
+	Host string
+
+	// Proto is the protocol used to make the request to the proxy, e.g. "https".
+	Proto string
+}
+
+// ParseForwarded parses the Forwarded header and returns its elements in order, i.e. from the
+// one added by the proxy closest to the client to the one added by the closest to the server.
+// Parsing of a header value stops at the first malformed element. It returns nil if the header does not exist.
+// https://datatracker.ietf.org/doc/html/rfc7239#section-4
+func ParseForwarded(headers http.Header) []ForwardedElement {
+	var elements []ForwardedElement
+	for _, v := range headers.Values(HeaderForwarded) {
+		e, _ := parseForwarded(v)
+		elements = append(elements, e...)
+	}
+	return elements
+}
+
+// ClientIP returns the IP address of the client that made the request r, as seen through the proxies
+// whose address belongs to trustedProxies. Starting from the address of the peer, the chain of addresses
+// reported by the Forwarded header, or the X-Forwarded-For header if absent, is walked backwards as long
+// as they are trusted: the first untrusted one is the client. Addresses reported by untrusted peers are
+// thus never taken into account. If a reported address is malformed or obfuscated, or if the Forwarded
+// header is malformed, the last trusted address is returned.
+//
+// It returns the zero netip.Addr if the address of the peer, r.RemoteAddr, cannot be parsed.
+func ClientIP(r *http.Request, trustedProxies []netip.Prefix) netip.Addr {
+	ip, ok := parseNodeAddr(r.RemoteAddr)
+	if !ok {
+		return netip.Addr{}
+	}
+
+	var chain []string
+	for _, v := range r.Header.Values(HeaderForwarded) {
+		elements, ok := parseForwarded(v)
+		for _, e := range elements {
+			chain = append(chain, e.For)
+		}
+		if !ok {
+			// The elements following a malformed one are lost: they are replaced by
+			// a malformed address the chain cannot be walked past.
+			chain = append(chain, "")
+		}
+	}
+	if chain == nil {
+		chain = HeaderValues(r.Header, HeaderXForwardedFor)
+	}
+
+	for i := len(chain) - 1; i >= 0 && isTrustedProxy(ip, trustedProxies); i-- {
+		prev, ok := parseNodeAddr(chain[i])
+		if !ok {
+			break
+		}
+		ip = prev
+	}
+	return ip
+}
+
+func isTrustedProxy(ip netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, p := range trustedProxies {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNodeAddr parses the IP address of a node, either a network address
+// or a Forwarded node identifier, ignoring its port if any.
+func parseNodeAddr(s string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+
+	ip, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return ip.Unmap(), true
+}
+
+// parseForwarded parses a Forwarded header value. It returns false, along with the elements
+// preceding it, if an element is malformed.
+func parseForwarded(s string) ([]ForwardedElement, bool) {
+	var (
+		elements []ForwardedElement
+		e        ForwardedElement
+		empty    = true
+	)
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" || s[0] == ',' {
+			if !empty {
+				elements = append(elements, e)
+			}
+			if s == "" {
+				return elements, true
+			}
+			e, empty = ForwardedElement{}, true
+			s = s[1:]
+			continue
+		}
+		if s[0] == ';' {
+			s = s[1:]
+			continue
+		}
+
+		name, rest, ok := strings.Cut(s, "=")
+		if !ok || strings.ContainsAny(name, ";,") {
+			return elements, false
+		}
+		value, rest, ok := consumeValue(strings.TrimLeft(rest, " \t"))
+		if !ok {
+			return elements, false
+		}
+		s = rest
+
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "by":
+			e.By = value
+		case "for":
+			e.For = value
+		case "host":
+			e.Host = value
+		case "proto":
+			e.Proto = strings.ToLower(value)
+		}
+		empty = false
+	}
+}
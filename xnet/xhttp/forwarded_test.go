@@ -0,0 +1,173 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestParseForwarded(t *testing.T) {
+	testCases := []struct {
+		name     string
+		headers  http.Header
+		expected []xhttp.ForwardedElement
+	}{
+		{
+			name:     "no header",
+			headers:  http.Header{},
+			expected: nil,
+		},
+		{
+			name:    "single element",
+			headers: http.Header{xhttp.HeaderForwarded: {`for="_gazonk"`}},
+			expected: []xhttp.ForwardedElement{
+				{For: "_gazonk"},
+			},
+		},
+		{
+			name:    "all parameters",
+			headers: http.Header{xhttp.HeaderForwarded: {`For="[2001:db8:cafe::17]:4711"; Proto=HTTPS; by=203.0.113.43; host=example.com; ext=1`}},
+			expected: []xhttp.ForwardedElement{
+				{By: "203.0.113.43", For: "[2001:db8:cafe::17]:4711", Host: "example.com", Proto: "https"},
+			},
+		},
+		{
+			name: "multiple elements",
+			headers: http.Header{xhttp.HeaderForwarded: {
+				`for=192.0.2.43, for=198.51.100.17;proto=http`,
+				`for=unknown`,
+			}},
+			expected: []xhttp.ForwardedElement{
+				{For: "192.0.2.43"},
+				{For: "198.51.100.17", Proto: "http"},
+				{For: "unknown"},
+			},
+		},
+		{
+			name:    "malformed element",
+			headers: http.Header{xhttp.HeaderForwarded: {`for=192.0.2.43, for;proto=http, for=198.51.100.17`}},
+			expected: []xhttp.ForwardedElement{
+				{For: "192.0.2.43"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xhttp.ParseForwarded(tc.headers)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %+v; got %+v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	trustedProxies := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("fd00::/8"),
+	}
+
+	testCases := []struct {
+		name           string
+		remoteAddr     string
+		headers        http.Header
+		trustedProxies []netip.Prefix
+		expected       string
+	}{
+		{
+			name:       "no proxy",
+			remoteAddr: "192.0.2.1:1234",
+			headers:    http.Header{},
+			expected:   "192.0.2.1",
+		},
+		{
+			name:       "untrusted peer",
+			remoteAddr: "192.0.2.1:1234",
+			headers:    http.Header{xhttp.HeaderXForwardedFor: {"198.51.100.1"}},
+			expected:   "192.0.2.1",
+		},
+		{
+			name:           "no trusted proxies",
+			remoteAddr:     "10.0.0.1:1234",
+			headers:        http.Header{xhttp.HeaderXForwardedFor: {"198.51.100.1"}},
+			trustedProxies: []netip.Prefix{},
+			expected:       "10.0.0.1",
+		},
+		{
+			name:       "x-forwarded-for",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    http.Header{xhttp.HeaderXForwardedFor: {"203.0.113.7, 198.51.100.1", "10.0.0.2"}},
+			expected:   "198.51.100.1",
+		},
+		{
+			name:       "all trusted",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    http.Header{xhttp.HeaderXForwardedFor: {"10.0.0.3, 10.0.0.2"}},
+			expected:   "10.0.0.3",
+		},
+		{
+			name:       "forwarded preferred",
+			remoteAddr: "[fd00::1]:1234",
+			headers: http.Header{
+				xhttp.HeaderForwarded:     {`for="[2001:db8::17]:4711", for=10.0.0.2`},
+				xhttp.HeaderXForwardedFor: {"198.51.100.1"},
+			},
+			expected: "2001:db8::17",
+		},
+		{
+			name:       "obfuscated identifier",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    http.Header{xhttp.HeaderForwarded: {`for=192.0.2.43, for=_hidden, for=10.0.0.2`}},
+			expected:   "10.0.0.2",
+		},
+		{
+			name:       "malformed forwarded",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    http.Header{xhttp.HeaderForwarded: {`for=6.6.6.6, for="bad, for=203.0.113.5`}},
+			expected:   "10.0.0.1",
+		},
+		{
+			name:       "malformed forwarded followed by trusted proxy",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    http.Header{xhttp.HeaderForwarded: {`for=6.6.6.6, for="bad`, `for=10.0.0.2`}},
+			expected:   "10.0.0.2",
+		},
+		{
+			name:       "ipv4-mapped ipv6 peer",
+			remoteAddr: "[::ffff:10.0.0.1]:1234",
+			headers:    http.Header{xhttp.HeaderXForwardedFor: {"198.51.100.1"}},
+			expected:   "198.51.100.1",
+		},
+		{
+			name:       "invalid remote address",
+			remoteAddr: "invalid",
+			headers:    http.Header{},
+			expected:   "invalid IP",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			r.RemoteAddr = tc.remoteAddr
+			r.Header = tc.headers
+
+			proxies := trustedProxies
+			if tc.trustedProxies != nil {
+				proxies = tc.trustedProxies
+			}
+
+			if got := xhttp.ClientIP(r, proxies); got.String() != tc.expected {
+				t.Errorf("expected %s; got %s", tc.expected, got)
+			}
+		})
+	}
+}
@@ -118,6 +118,8 @@ const (
 	HeaderIfUnmodifiedSince = "If-Unmodified-Since"
 	// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Keep-Alive
 	HeaderKeepAlive = "Keep-Alive"
+	// https://html.spec.whatwg.org/multipage/server-sent-events.html#the-last-event-id-header
+	HeaderLastEventID = "Last-Event-ID"
 	// https://datatracker.ietf.org/doc/html/rfc9110#section-8.8.2
 	HeaderLastModified = "Last-Modified"
 	// https://datatracker.ietf.org/doc/html/rfc8288#section-3
@@ -158,6 +160,8 @@ const (
 	HeaderSecFetchUser = "Sec-Fetch-User"
 	// https://datatracker.ietf.org/doc/html/rfc6455#section-11.3.3
 	HeaderSecWebSocketAccept = "Sec-WebSocket-Accept"
+	// https://datatracker.ietf.org/doc/html/rfc6455#section-11.3.1
+	HeaderSecWebSocketKey = "Sec-WebSocket-Key"
 	// https://datatracker.ietf.org/doc/html/rfc6455#section-11.3.4
 	HeaderSecWebSocketProtocol = "Sec-WebSocket-Protocol"
 	// https://datatracker.ietf.org/doc/html/rfc6455#section-11.3.5
@@ -202,6 +206,10 @@ const (
 const (
 	// https://datatracker.ietf.org/doc/draft-ietf-httpapi-idempotency-key-header/
 	HeaderIdempotencyKey = "Idempotency-Key"
+	// https://docs.stripe.com/api/idempotent_requests
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+	// https://www.w3.org/TR/trace-context/#traceparent-header
+	HeaderTraceparent = "Traceparent"
 	// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/X-Content-Type-Options
 	HeaderXContentTypeOptions = "X-Content-Type-Options"
 	// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/X-DNS-Prefetch-Control
@@ -217,6 +225,8 @@ const (
 	// https://tools.ietf.org/id/draft-idempotency-header-01.html
 	// Deprecated: use HeaderIdempotencyKey instead.
 	HeaderXIdempotencyKey = "X-Idempotency-Key"
+	// https://http.dev/x-request-id
+	HeaderXRequestID = "X-Request-Id"
 	// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/X-XSS-Protection
 	HeaderXXSSProtection = "X-XSS-Protection"
 )
@@ -255,6 +265,20 @@ const (
 	CacheControlStaleWhileRevalidate = "stale-while-revalidate"
 )
 
+// HTTP content codings.
+const (
+	// https://datatracker.ietf.org/doc/html/rfc7932
+	EncodingBrotli = "br"
+	// https://datatracker.ietf.org/doc/html/rfc9110#section-8.4.1.2
+	EncodingDeflate = "deflate"
+	// https://datatracker.ietf.org/doc/html/rfc9110#section-8.4.1.3
+	EncodingGzip = "gzip"
+	// https://datatracker.ietf.org/doc/html/rfc9110#section-12.5.3
+	EncodingIdentity = "identity"
+	// https://datatracker.ietf.org/doc/html/rfc8878
+	EncodingZstd = "zstd"
+)
+
 var errHeaderNoDate = errors.New("no date header")
 
 // HeaderExist returns whether the key exists in headers.
@@ -341,3 +365,25 @@ func ReplaceHeader(headers http.Header, prefix, key string, values ...string) {
 
 	headers[http.CanonicalHeaderKey(key)] = values
 }
+
+// splitHeaderList splits the values of a comma-separated list header into elements,
+// ignoring commas within quoted strings.
+func splitHeaderList(values []string) []string {
+	var elements []string
+	for _, v := range values {
+		quoted, start := false, 0
+		for i := 0; i < len(v); i++ {
+			switch v[i] {
+			case '"':
+				quoted = !quoted
+			case ',':
+				if !quoted {
+					elements = append(elements, strings.TrimSpace(v[start:i]))
+					start = i + 1
+				}
+			}
+		}
+		elements = append(elements, strings.TrimSpace(v[start:]))
+	}
+	return elements
+}
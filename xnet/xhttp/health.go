@@ -0,0 +1,289 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// HealthStatusOK is the status of a passing health check or report.
+	HealthStatusOK = "ok"
+
+	// HealthStatusFail is the status of a failing health check or report.
+	HealthStatusFail = "fail"
+)
+
+const (
+	healthDefaultTimeout  = 5 * time.Second
+	healthDefaultCacheTTL = time.Second
+)
+
+var errServerNotReady = errors.New("server not ready")
+
+type (
+	// HealthChecker checks the health of a component, returning a non-nil error if unhealthy.
+	// It should return once ctx is done.
+	HealthChecker func(ctx context.Context) error
+
+	// HealthReport is the JSON output of the health endpoints.
+	HealthReport struct {
+		// Status is the aggregated status of the checks, HealthStatusOK if all of them pass.
+		Status string `json:"status"`
+
+		// Checks are the results of the checks, by name.
+		Checks map[string]HealthCheckResult `json:"checks,omitempty"`
+	}
+
+	// HealthCheckResult is the result of a health check.
+	HealthCheckResult struct {
+		// Status is the status of the check, either HealthStatusOK or HealthStatusFail.
+		Status string `json:"status"`
+
+		// Error is the message of the error returned by the check, if any.
+		Error string `json:"error,omitempty"`
+
+		// Latency is the duration of the check, e.g. "1.5ms".
+		Latency string `json:"latency"`
+	}
+)
+
+// HealthHandler is an HTTP handler serving the liveness and readiness of a service.
+//
+// The liveness endpoint, /livez, reports whether the service is running and should be restarted
+// otherwise: it only runs the liveness checks. The readiness endpoint, /readyz, reports whether the
+// service can serve requests and should be routed traffic: it runs both the liveness and readiness
+// checks, e.g. of dependencies.
+//
+// Checks run concurrently with a timeout and their results are cached to protect dependencies
+// from frequent probes. Endpoints reply with 200 OK if all checks pass and 503 Service Unavailable
+// otherwise, along with a HealthReport. Error messages of checks are thus exposed and should not
+// contain sensitive details.
+type HealthHandler struct {
+	liveness  []*healthCheck
+	readiness []*healthCheck
+	timeout   time.Duration
+	cacheTTL  time.Duration
+}
+
+type healthCheck struct {
+	name    string
+	checker HealthChecker
+
+	mu        sync.Mutex
+	result    HealthCheckResult
+	checkedAt time.Time
+}
+
+// NewHealthHandler creates a new HealthHandler configured with the options passed in input.
+// By default, there is no check, the timeout of checks is 5s and results are cached for 1s.
+func NewHealthHandler(options ...HealthHandlerOption) *HealthHandler {
+	h := &HealthHandler{
+		timeout:  healthDefaultTimeout,
+		cacheTTL: healthDefaultCacheTTL,
+	}
+
+	for _, opt := range options {
+		opt.apply(h)
+	}
+
+	return h
+}
+
+// Livez returns the HTTP handler of the liveness endpoint.
+func (h *HealthHandler) Livez() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.serve(w, r, h.liveness)
+	})
+}
+
+// Readyz returns the HTTP handler of the readiness endpoint.
+func (h *HealthHandler) Readyz() http.Handler {
+	checks := append(append([]*healthCheck(nil), h.liveness...), h.readiness...)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.serve(w, r, checks)
+	})
+}
+
+// ServeHTTP makes HealthHandler implement the http.Handler interface. It serves the liveness
+// endpoint for paths ending with /livez and the readiness one for paths ending with /readyz.
+// It replies with 404 Not Found otherwise.
+func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/livez"):
+		h.serve(w, r, h.liveness)
+	case strings.HasSuffix(r.URL.Path, "/readyz"):
+		h.serve(w, r, append(append([]*healthCheck(nil), h.liveness...), h.readiness...))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *HealthHandler) serve(w http.ResponseWriter, r *http.Request, checks []*healthCheck) {
+	report := HealthReport{
+		Status: HealthStatusOK,
+	}
+
+	if len(checks) > 0 {
+		results := make([]HealthCheckResult, len(checks))
+
+		var wg sync.WaitGroup
+		wg.Add(len(checks))
+		for i, c := range checks {
+			go func(i int, c *healthCheck) {
+				defer wg.Done()
+				results[i] = h.check(r.Context(), c)
+			}(i, c)
+		}
+		wg.Wait()
+
+		report.Checks = make(map[string]HealthCheckResult, len(checks))
+		for i, c := range checks {
+			report.Checks[c.name] = results[i]
+			if results[i].Status != HealthStatusOK {
+				report.Status = HealthStatusFail
+			}
+		}
+	}
+
+	status := http.StatusOK
+	if report.Status != HealthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	header := w.Header()
+	header.Set(HeaderContentType, "application/json")
+	header.Set(HeaderCacheControl, CacheControlNoStore)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report) //nolint:errcheck // the response is already committed.
+}
+
+// check runs c, unless its cached result is still fresh. Concurrent callers wait for the running check.
+func (h *HealthHandler) check(ctx context.Context, c *healthCheck) HealthCheckResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.checkedAt.IsZero() && time.Since(c.checkedAt) < h.cacheTTL {
+		return c.result
+	}
+
+	// The check is not bound to the request, so that a probe giving up does not fail it.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- c.checker(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	c.result = HealthCheckResult{
+		Status:  HealthStatusOK,
+		Latency: time.Since(start).String(),
+	}
+	if err != nil {
+		c.result.Status = HealthStatusFail
+		c.result.Error = err.Error()
+	}
+	c.checkedAt = time.Now()
+
+	return c.result
+}
+
+type (
+	// HealthHandlerOption configures the HealthHandler options when calling NewHealthHandler.
+	HealthHandlerOption interface {
+		apply(h *HealthHandler)
+	}
+
+	funcHealthHandlerOption struct {
+		fn func(*HealthHandler)
+	}
+)
+
+func newFuncHealthHandlerOption(fn func(*HealthHandler)) funcHealthHandlerOption {
+	return funcHealthHandlerOption{
+		fn: fn,
+	}
+}
+
+func (o funcHealthHandlerOption) apply(h *HealthHandler) {
+	o.fn(h)
+}
+
+// HealthHandlerCacheTTL returns a HealthHandlerOption that configures how long the result of a check
+// is cached. 0 disables caching. Value must be >= 0, otherwise it panics.
+func HealthHandlerCacheTTL(ttl time.Duration) HealthHandlerOption {
+	if ttl < 0 {
+		panic("invalid cache ttl value")
+	}
+	return newFuncHealthHandlerOption(func(h *HealthHandler) {
+		h.cacheTTL = ttl
+	})
+}
+
+// HealthHandlerLivenessCheck returns a HealthHandlerOption that adds a liveness check, run by both endpoints.
+// Names must be unique. Name must not be empty and checker must not be nil, otherwise it panics.
+func HealthHandlerLivenessCheck(name string, checker HealthChecker) HealthHandlerOption {
+	validateHealthCheck(name, checker)
+	return newFuncHealthHandlerOption(func(h *HealthHandler) {
+		h.liveness = append(h.liveness, &healthCheck{name: name, checker: checker})
+	})
+}
+
+// HealthHandlerReadinessCheck returns a HealthHandlerOption that adds a readiness check, run by the readiness
+// endpoint only. Names must be unique. Name must not be empty and checker must not be nil, otherwise it panics.
+func HealthHandlerReadinessCheck(name string, checker HealthChecker) HealthHandlerOption {
+	validateHealthCheck(name, checker)
+	return newFuncHealthHandlerOption(func(h *HealthHandler) {
+		h.readiness = append(h.readiness, &healthCheck{name: name, checker: checker})
+	})
+}
+
+// HealthHandlerServer returns a HealthHandlerOption that adds a "server" readiness check,
+// failing when s is not ready, e.g. while shutting down. Value must not be nil, otherwise it panics.
+func HealthHandlerServer(s *Server) HealthHandlerOption {
+	if s == nil {
+		panic("server is nil")
+	}
+	return HealthHandlerReadinessCheck("server", func(context.Context) error {
+		if !s.Ready() {
+			return errServerNotReady
+		}
+		return nil
+	})
+}
+
+// HealthHandlerTimeout returns a HealthHandlerOption that configures the max duration of a check.
+// Value must be > 0, otherwise it panics.
+func HealthHandlerTimeout(timeout time.Duration) HealthHandlerOption {
+	if timeout <= 0 {
+		panic("invalid timeout value")
+	}
+	return newFuncHealthHandlerOption(func(h *HealthHandler) {
+		h.timeout = timeout
+	})
+}
+
+func validateHealthCheck(name string, checker HealthChecker) {
+	if name == "" {
+		panic("check name is empty")
+	}
+	if checker == nil {
+		panic("checker is nil")
+	}
+}
@@ -0,0 +1,261 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestHealthHandler(t *testing.T) {
+	errDown := errors.New("database down")
+	ok := func(context.Context) error { return nil }
+	fail := func(context.Context) error { return errDown }
+	block := func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}
+
+	testCases := []struct {
+		name           string
+		options        []xhttp.HealthHandlerOption
+		path           string
+		expectedStatus int
+		expectedReport xhttp.HealthReport
+	}{
+		{
+			name:           "livez without check",
+			path:           "/livez",
+			expectedStatus: http.StatusOK,
+			expectedReport: xhttp.HealthReport{Status: xhttp.HealthStatusOK},
+		},
+		{
+			name: "livez ignores readiness checks",
+			options: []xhttp.HealthHandlerOption{
+				xhttp.HealthHandlerLivenessCheck("self", ok),
+				xhttp.HealthHandlerReadinessCheck("db", fail),
+			},
+			path:           "/livez",
+			expectedStatus: http.StatusOK,
+			expectedReport: xhttp.HealthReport{
+				Status: xhttp.HealthStatusOK,
+				Checks: map[string]xhttp.HealthCheckResult{"self": {Status: xhttp.HealthStatusOK}},
+			},
+		},
+		{
+			name: "readyz runs all checks",
+			options: []xhttp.HealthHandlerOption{
+				xhttp.HealthHandlerLivenessCheck("self", ok),
+				xhttp.HealthHandlerReadinessCheck("db", fail),
+			},
+			path:           "/health/readyz",
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedReport: xhttp.HealthReport{
+				Status: xhttp.HealthStatusFail,
+				Checks: map[string]xhttp.HealthCheckResult{
+					"self": {Status: xhttp.HealthStatusOK},
+					"db":   {Status: xhttp.HealthStatusFail, Error: errDown.Error()},
+				},
+			},
+		},
+		{
+			name: "check timeout",
+			options: []xhttp.HealthHandlerOption{
+				xhttp.HealthHandlerReadinessCheck("slow", block),
+				xhttp.HealthHandlerTimeout(time.Millisecond),
+			},
+			path:           "/readyz",
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedReport: xhttp.HealthReport{
+				Status: xhttp.HealthStatusFail,
+				Checks: map[string]xhttp.HealthCheckResult{
+					"slow": {Status: xhttp.HealthStatusFail, Error: context.DeadlineExceeded.Error()},
+				},
+			},
+		},
+		{
+			name: "server not ready",
+			options: []xhttp.HealthHandlerOption{
+				xhttp.HealthHandlerServer(xhttp.NewServer(http.NotFoundHandler())),
+			},
+			path:           "/readyz",
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedReport: xhttp.HealthReport{
+				Status: xhttp.HealthStatusFail,
+				Checks: map[string]xhttp.HealthCheckResult{
+					"server": {Status: xhttp.HealthStatusFail, Error: "server not ready"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			xhttp.NewHealthHandler(tc.options...).ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, http.NoBody))
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("status code mismatch: expected %d; got %d", tc.expectedStatus, w.Code)
+			}
+			if ct := w.Header().Get(xhttp.HeaderContentType); ct != "application/json" {
+				t.Errorf("content type mismatch: expected %q; got %q", "application/json", ct)
+			}
+
+			var report xhttp.HealthReport
+			if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if report.Status != tc.expectedReport.Status {
+				t.Errorf("status mismatch: expected %q; got %q", tc.expectedReport.Status, report.Status)
+			}
+			if len(report.Checks) != len(tc.expectedReport.Checks) {
+				t.Fatalf("checks mismatch: expected %v; got %v", tc.expectedReport.Checks, report.Checks)
+			}
+			for name, expected := range tc.expectedReport.Checks {
+				got := report.Checks[name]
+				if got.Status != expected.Status || got.Error != expected.Error {
+					t.Errorf("check %q mismatch: expected %+v; got %+v", name, expected, got)
+				}
+				if _, err := time.ParseDuration(got.Latency); err != nil {
+					t.Errorf("check %q latency invalid: %q", name, got.Latency)
+				}
+			}
+		})
+	}
+}
+
+func TestHealthHandler_NotFound(t *testing.T) {
+	w := httptest.NewRecorder()
+	xhttp.NewHealthHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", http.NoBody))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status code mismatch: expected %d; got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestHealthHandler_Cache(t *testing.T) {
+	testCases := []struct {
+		name          string
+		cacheTTL      time.Duration
+		expectedCalls int32
+	}{
+		{
+			name:          "cached",
+			cacheTTL:      time.Hour,
+			expectedCalls: 1,
+		},
+		{
+			name:          "not cached",
+			cacheTTL:      0,
+			expectedCalls: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls atomic.Int32
+			h := xhttp.NewHealthHandler(
+				xhttp.HealthHandlerLivenessCheck("self", func(context.Context) error {
+					calls.Add(1)
+					return nil
+				}),
+				xhttp.HealthHandlerCacheTTL(tc.cacheTTL),
+			)
+
+			h.Livez().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+			h.Readyz().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/livez", http.NoBody))
+
+			if got := calls.Load(); got != tc.expectedCalls {
+				t.Errorf("calls mismatch: expected %d; got %d", tc.expectedCalls, got)
+			}
+		})
+	}
+}
+
+func TestHealthHandlerOptions(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+
+	testCases := []struct {
+		name  string
+		fn    func() xhttp.HealthHandlerOption
+		panic bool
+	}{
+		{
+			name:  "cache ttl negative",
+			fn:    func() xhttp.HealthHandlerOption { return xhttp.HealthHandlerCacheTTL(-1) },
+			panic: true,
+		},
+		{
+			name:  "cache ttl valid",
+			fn:    func() xhttp.HealthHandlerOption { return xhttp.HealthHandlerCacheTTL(0) },
+			panic: false,
+		},
+		{
+			name:  "liveness check empty name",
+			fn:    func() xhttp.HealthHandlerOption { return xhttp.HealthHandlerLivenessCheck("", ok) },
+			panic: true,
+		},
+		{
+			name:  "liveness check nil checker",
+			fn:    func() xhttp.HealthHandlerOption { return xhttp.HealthHandlerLivenessCheck("self", nil) },
+			panic: true,
+		},
+		{
+			name:  "liveness check valid",
+			fn:    func() xhttp.HealthHandlerOption { return xhttp.HealthHandlerLivenessCheck("self", ok) },
+			panic: false,
+		},
+		{
+			name:  "readiness check empty name",
+			fn:    func() xhttp.HealthHandlerOption { return xhttp.HealthHandlerReadinessCheck("", ok) },
+			panic: true,
+		},
+		{
+			name:  "readiness check nil checker",
+			fn:    func() xhttp.HealthHandlerOption { return xhttp.HealthHandlerReadinessCheck("db", nil) },
+			panic: true,
+		},
+		{
+			name:  "readiness check valid",
+			fn:    func() xhttp.HealthHandlerOption { return xhttp.HealthHandlerReadinessCheck("db", ok) },
+			panic: false,
+		},
+		{
+			name:  "server nil",
+			fn:    func() xhttp.HealthHandlerOption { return xhttp.HealthHandlerServer(nil) },
+			panic: true,
+		},
+		{
+			name:  "server valid",
+			fn:    func() xhttp.HealthHandlerOption { return xhttp.HealthHandlerServer(&xhttp.Server{}) },
+			panic: false,
+		},
+		{
+			name:  "timeout zero",
+			fn:    func() xhttp.HealthHandlerOption { return xhttp.HealthHandlerTimeout(0) },
+			panic: true,
+		},
+		{
+			name:  "timeout valid",
+			fn:    func() xhttp.HealthHandlerOption { return xhttp.HealthHandlerTimeout(time.Second) },
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, tc.fn)
+		})
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/jlourenc/xgo/xio"
+	"github.com/jlourenc/xgo/xnet/xhttp/xhttptrace"
+)
+
+const (
+	hedgingTransportDefaultDelay     = 100 * time.Millisecond
+	hedgingTransportDefaultMaxHedges = 1
+)
+
+// HedgingTransport is an HTTP transport that sends hedged requests, i.e. additional copies of
+// a request, when the original one takes too long to complete, to reduce tail latency.
+type hedgingTransport struct {
+	next http.RoundTripper
+
+	delay     time.Duration
+	maxHedges int
+}
+
+type hedgeResult struct {
+	index int
+	resp  *http.Response
+	err   error
+}
+
+// NewHedgingTransport creates a new HedgingTransport configured with the options passed in input,
+// notably the delay before sending hedged requests, their maximum number and the next round tripper
+// in the chain. By default, a single hedged request is sent after 100ms.
+func NewHedgingTransport(options ...HedgingTransportOption) http.RoundTripper {
+	t := &hedgingTransport{
+		delay:     hedgingTransportDefaultDelay,
+		maxHedges: hedgingTransportDefaultMaxHedges,
+		next:      http.DefaultTransport,
+	}
+
+	for _, opt := range options {
+		opt.apply(t)
+	}
+
+	return t
+}
+
+// RoundTrip makes HedgingTransport implement the RoundTripper interface.
+//
+// Idempotent requests whose body can be rewound are sent again each time the delay elapses
+// without a successful response, up to the maximum number of hedged requests. The first successful
+// response, i.e. one with a status code lower than 500, is returned and the other requests are canceled.
+// If every request fails, the outcome of the last one to complete is returned.
+// Other requests are passed through to the next round tripper as is.
+func (t *hedgingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isRequestIdempotent(req) || !isRequestRewindable(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+
+	trace := xhttptrace.ContextClientTrace(ctx)
+	if trace == nil {
+		trace = &xhttptrace.ClientTrace{}
+	}
+
+	results := make(chan hedgeResult, t.maxHedges+1)
+	cancels := make([]context.CancelFunc, 0, t.maxHedges+1)
+
+	launch := func(r *http.Request) {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		cancels = append(cancels, cancel)
+
+		index := len(cancels) - 1
+		r = r.WithContext(attemptCtx)
+		go func() {
+			resp, err := t.next.RoundTrip(r)
+			results <- hedgeResult{index: index, resp: resp, err: err}
+		}()
+	}
+
+	launch(req)
+	pending := 1
+
+	timer := time.NewTimer(t.delay)
+	defer timer.Stop()
+	timerC := timer.C
+
+	var last *hedgeResult
+	for pending > 0 {
+		select {
+		case <-timerC:
+			r, err := hedgeRequest(req)
+			if err != nil {
+				timerC = nil
+				continue
+			}
+
+			launch(r)
+			pending++
+
+			if trace.Hedge != nil {
+				trace.Hedge(xhttptrace.HedgeInfo{
+					HedgeCount: len(cancels) - 1,
+				})
+			}
+
+			if len(cancels) > t.maxHedges {
+				timerC = nil
+			} else {
+				timer.Reset(t.delay)
+			}
+
+		case res := <-results:
+			pending--
+
+			// Release the outcome of the previous failed request, if any.
+			if last != nil {
+				if last.resp != nil {
+					xio.DrainClose(last.resp.Body)
+				}
+				cancels[last.index]()
+			}
+			last = &res
+
+			if res.err == nil && res.resp.StatusCode < http.StatusInternalServerError {
+				// Cancel the other requests and release their responses, if any.
+				for i, cancel := range cancels {
+					if i != res.index {
+						cancel()
+					}
+				}
+				go func(n int) {
+					for i := 0; i < n; i++ {
+						if r := <-results; r.resp != nil {
+							xio.DrainClose(r.resp.Body)
+						}
+					}
+				}(pending)
+				pending = 0
+			}
+		}
+	}
+
+	if last.err != nil || last.resp.Body == nil {
+		cancels[last.index]()
+		return last.resp, last.err
+	}
+
+	last.resp.Body = &cancelBody{ReadCloser: last.resp.Body, cancel: cancels[last.index]}
+	return last.resp, nil
+}
+
+// hedgeRequest returns a copy of req with a rewound body, if any.
+func hedgeRequest(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
+
+// cancelBody is a response body canceling the context of its request once closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+type (
+	// HedgingTransportOption configures the HedgingTransport options
+	// when calling NewHedgingTransport.
+	HedgingTransportOption interface {
+		apply(t *hedgingTransport)
+	}
+
+	funcHedgingTransportOption struct {
+		fn func(*hedgingTransport)
+	}
+)
+
+func newFuncHedgingTransportOption(fn func(*hedgingTransport)) funcHedgingTransportOption {
+	return funcHedgingTransportOption{
+		fn: fn,
+	}
+}
+
+func (o funcHedgingTransportOption) apply(t *hedgingTransport) {
+	o.fn(t)
+}
+
+// HedgingTransportDelay returns a HedgingTransportOption that configures the delay to wait
+// for a successful response before sending each hedged request. Value must be > 0, otherwise it panics.
+func HedgingTransportDelay(delay time.Duration) HedgingTransportOption {
+	if delay <= 0 {
+		panic("invalid delay value")
+	}
+	return newFuncHedgingTransportOption(func(t *hedgingTransport) {
+		t.delay = delay
+	})
+}
+
+// HedgingTransportMaxHedges returns a HedgingTransportOption that configures the max number
+// of hedged requests sent in addition to the original one. Value must be >= 1, otherwise it panics.
+func HedgingTransportMaxHedges(n int) HedgingTransportOption {
+	if n < 1 {
+		panic("invalid max hedges value")
+	}
+	return newFuncHedgingTransportOption(func(t *hedgingTransport) {
+		t.maxHedges = n
+	})
+}
+
+// HedgingTransportNextRoundTripper returns a HedgingTransportOption that configures the
+// next round tripper to call. If not used http.DefaultTransport will be used.
+func HedgingTransportNextRoundTripper(next http.RoundTripper) HedgingTransportOption {
+	if next == nil {
+		panic("next http.RoundTripper is nil")
+	}
+	return newFuncHedgingTransportOption(func(t *hedgingTransport) {
+		t.next = next
+	})
+}
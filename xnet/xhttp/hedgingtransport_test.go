@@ -0,0 +1,244 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+	"github.com/jlourenc/xgo/xnet/xhttp/xhttptrace"
+)
+
+// delayTransport replies to its i-th round trip with resps[i] after delays[i],
+// unless the request context is done first.
+type delayTransport struct {
+	mu      sync.Mutex
+	counter int
+	delays  []time.Duration
+	resps   []*http.Response
+}
+
+func (t *delayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	i := t.counter
+	t.counter++
+	t.mu.Unlock()
+
+	if i >= len(t.resps) {
+		return nil, errNoResponse
+	}
+
+	select {
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	case <-time.After(t.delays[i]):
+		return t.resps[i], nil
+	}
+}
+
+func TestHedgingTransport_RoundTrip(t *testing.T) {
+	newResp := func(code int) *http.Response {
+		return &http.Response{StatusCode: code, Body: io.NopCloser(strings.NewReader("body"))}
+	}
+
+	testCases := []struct {
+		name           string
+		method         string
+		maxHedges      int
+		delays         []time.Duration
+		codes          []int
+		expectedIndex  int
+		expectedHedges int
+	}{
+		{
+			name:           "fast response not hedged",
+			delays:         []time.Duration{0, 0},
+			codes:          []int{http.StatusOK, http.StatusOK},
+			expectedIndex:  0,
+			expectedHedges: 0,
+		},
+		{
+			name:           "slow response hedged",
+			delays:         []time.Duration{time.Second, 0},
+			codes:          []int{http.StatusOK, http.StatusNoContent},
+			expectedIndex:  1,
+			expectedHedges: 1,
+		},
+		{
+			name:           "slow responses hedged twice",
+			maxHedges:      2,
+			delays:         []time.Duration{time.Second, time.Second, 0},
+			codes:          []int{http.StatusOK, http.StatusOK, http.StatusNoContent},
+			expectedIndex:  2,
+			expectedHedges: 2,
+		},
+		{
+			name:           "failed hedged response ignored",
+			delays:         []time.Duration{50 * time.Millisecond, 0},
+			codes:          []int{http.StatusOK, http.StatusServiceUnavailable},
+			expectedIndex:  0,
+			expectedHedges: 1,
+		},
+		{
+			name:           "last failed response returned",
+			delays:         []time.Duration{50 * time.Millisecond, 0},
+			codes:          []int{http.StatusBadGateway, http.StatusServiceUnavailable},
+			expectedIndex:  0,
+			expectedHedges: 1,
+		},
+		{
+			name:           "non idempotent request not hedged",
+			method:         http.MethodPost,
+			delays:         []time.Duration{50 * time.Millisecond, 0},
+			codes:          []int{http.StatusOK, http.StatusNoContent},
+			expectedIndex:  0,
+			expectedHedges: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			next := &delayTransport{delays: tc.delays}
+			for _, code := range tc.codes {
+				next.resps = append(next.resps, newResp(code))
+			}
+
+			options := []xhttp.HedgingTransportOption{
+				xhttp.HedgingTransportNextRoundTripper(next),
+				xhttp.HedgingTransportDelay(10 * time.Millisecond),
+			}
+			if tc.maxHedges > 0 {
+				options = append(options, xhttp.HedgingTransportMaxHedges(tc.maxHedges))
+			}
+			hedgingTransp := xhttp.NewHedgingTransport(options...)
+
+			var hedges atomic.Int32
+			ctx := xhttptrace.WithClientTrace(context.Background(), &xhttptrace.ClientTrace{
+				Hedge: func(hi xhttptrace.HedgeInfo) {
+					hedges.Add(1)
+					if hi.HedgeCount != int(hedges.Load()) {
+						t.Errorf("hedge count mismatch: expected %d; got %d", hedges.Load(), hi.HedgeCount)
+					}
+				},
+			})
+
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req, err := http.NewRequestWithContext(ctx, method, "http://example.com", http.NoBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			start := time.Now()
+			resp, err := hedgingTransp.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp != next.resps[tc.expectedIndex] {
+				t.Errorf("response mismatch: expected response %d; got %v", tc.expectedIndex, resp)
+			}
+			if got := int(hedges.Load()); got != tc.expectedHedges {
+				t.Errorf("hedges mismatch: expected %d; got %d", tc.expectedHedges, got)
+			}
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("expected round trip to be fast; took %s", elapsed)
+			}
+			if b, err := io.ReadAll(resp.Body); err != nil || string(b) != "body" {
+				t.Errorf("expected readable body; got %q, %v", b, err)
+			}
+		})
+	}
+}
+
+func TestHedgingTransportDelay(t *testing.T) {
+	testCases := []struct {
+		name  string
+		delay time.Duration
+		panic bool
+	}{
+		{
+			name:  "panic",
+			delay: 0,
+			panic: true,
+		},
+		{
+			name:  "valid",
+			delay: 1,
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, func() xhttp.HedgingTransportOption {
+				return xhttp.HedgingTransportDelay(tc.delay)
+			})
+		})
+	}
+}
+
+func TestHedgingTransportMaxHedges(t *testing.T) {
+	testCases := []struct {
+		name  string
+		n     int
+		panic bool
+	}{
+		{
+			name:  "panic",
+			n:     0,
+			panic: true,
+		},
+		{
+			name:  "valid",
+			n:     2,
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, func() xhttp.HedgingTransportOption {
+				return xhttp.HedgingTransportMaxHedges(tc.n)
+			})
+		})
+	}
+}
+
+func TestHedgingTransportNextRoundTripper(t *testing.T) {
+	testCases := []struct {
+		name  string
+		next  http.RoundTripper
+		panic bool
+	}{
+		{
+			name:  "panic",
+			next:  nil,
+			panic: true,
+		},
+		{
+			name:  "valid",
+			next:  &fakeTransport{},
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, func() xhttp.HedgingTransportOption {
+				return xhttp.HedgingTransportNextRoundTripper(tc.next)
+			})
+		})
+	}
+}
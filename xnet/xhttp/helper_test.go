@@ -46,8 +46,34 @@ func (t *fakeTransport) RoundTrip(req *http.Request) (resp *http.Response, err e
 	return resp, nil
 }
 
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// errTransport fails with its errors, in order, before delegating to next.
+type errTransport struct {
+	errs []error
+	next http.RoundTripper
+}
+
+func (t *errTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(t.errs) > 0 {
+		err := t.errs[0]
+		t.errs = t.errs[1:]
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
+
 func testRetryTransportOptionPanic(tb testing.TB, shouldPanic bool, fn func() xhttp.RetryTransportOption) {
 	tb.Helper()
+	testOptionPanic(tb, shouldPanic, fn)
+}
+
+func testOptionPanic[O any](tb testing.TB, shouldPanic bool, fn func() O) {
+	tb.Helper()
 
 	defer func() {
 		r := recover()
@@ -60,7 +86,7 @@ func testRetryTransportOptionPanic(tb testing.TB, shouldPanic bool, fn func() xh
 
 	got := fn()
 
-	if got == nil {
-		tb.Error("retry option expected; got nil")
+	if any(got) == nil {
+		tb.Error("option expected; got nil")
 	}
 }
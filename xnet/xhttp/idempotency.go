@@ -0,0 +1,239 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/jlourenc/xgo/xerrors"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+const idempotencyDefaultMaxBodySize = 10 * xunit.MiB
+
+var errIdempotencyKeyReused = errors.New("idempotency key reused with a different request")
+
+type idempotency struct {
+	store       IdempotencyStore
+	keyScope    func(r *http.Request) string
+	maxBodySize xunit.Byte
+
+	mu      sync.Mutex
+	flights map[string]*idempotencyFlight
+}
+
+type idempotencyFlight struct {
+	done chan struct{}
+}
+
+// Idempotency returns a Middleware making POST and PATCH requests with an Idempotency-Key or
+// X-Idempotency-Key header idempotent: the response of the next handler is captured in store, unless
+// it is a server error, and replayed to subsequent requests with the same key, along with an
+// Idempotent-Replayed header. Concurrent requests with the same key wait for the first one to complete.
+//
+// Keys are scoped per Authorization header by default, so that a client cannot be replayed the response
+// of another one. Only the headers set by the next handler are captured along with the response.
+//
+// Requests reusing a key with a different method, path or body are replied to with a 422 Unprocessable
+// Entity error written with WriteError. The request body is read in memory to compute its digest, up to
+// 10MiB by default. Requests with a larger body are replied to with 413 Content Too Large.
+// Store must not be nil, otherwise it panics.
+// https://datatracker.ietf.org/doc/draft-ietf-httpapi-idempotency-key-header/
+func Idempotency(store IdempotencyStore, options ...IdempotencyOption) Middleware {
+	if store == nil {
+		panic("store is nil")
+	}
+
+	i := &idempotency{
+		store:       store,
+		keyScope:    authorizationKeyScope,
+		maxBodySize: idempotencyDefaultMaxBodySize,
+		flights:     make(map[string]*idempotencyFlight),
+	}
+
+	for _, opt := range options {
+		opt.apply(i)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(HeaderIdempotencyKey)
+			if key == "" {
+				key = r.Header.Get(HeaderXIdempotencyKey)
+			}
+			if key == "" || (r.Method != http.MethodPost && r.Method != http.MethodPatch) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if scope := i.keyScope(r); scope != "" {
+				// Header values cannot contain NUL, so that scoped keys cannot collide.
+				key = scope + "\x00" + key
+			}
+
+			digest, err := readRequestBodyDigest(w, r, i.maxBodySize)
+			if err != nil {
+				if !errors.Is(err, ErrBodyTooLarge) {
+					err = xerrors.WithHTTPStatus(err, http.StatusBadRequest)
+				}
+				WriteError(w, err)
+				return
+			}
+			fingerprint := r.Method + " " + r.URL.Path + " " + digest
+
+			for {
+				if resp, ok := i.store.Get(key); ok {
+					replayIdempotentResponse(w, resp, fingerprint)
+					return
+				}
+
+				f, leader := i.join(key)
+				if leader {
+					defer i.leave(key, f)
+					break
+				}
+
+				select {
+				case <-f.done:
+				case <-r.Context().Done():
+					WriteError(w, xerrors.WithHTTPStatus(r.Context().Err(), http.StatusServiceUnavailable))
+					return
+				}
+			}
+
+			outer := w.Header().Clone()
+			iw := &idempotencyWriter{statusWriter: statusWriter{ResponseWriter: w}}
+			next.ServeHTTP(iw, r)
+
+			if status := iw.Status(); status < http.StatusInternalServerError {
+				i.store.Set(key, &IdempotentResponse{
+					Fingerprint: fingerprint,
+					StatusCode:  status,
+					Header:      headerChanges(outer, w.Header()),
+					Body:        iw.buf.Bytes(),
+				})
+			}
+		})
+	}
+}
+
+// authorizationKeyScope scopes keys per Authorization header, hashed not to keep credentials in the store.
+func authorizationKeyScope(r *http.Request) string {
+	auth := r.Header.Get(HeaderAuthorization)
+	if auth == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(auth))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// headerChanges returns the entries of after which are not in before.
+func headerChanges(before, after http.Header) http.Header {
+	h := make(http.Header)
+	for k, v := range after {
+		if !slices.Equal(before[k], v) {
+			h[k] = slices.Clone(v)
+		}
+	}
+	return h
+}
+
+// join returns the in-flight request for key, and whether the caller leads it.
+func (i *idempotency) join(key string) (*idempotencyFlight, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if f, ok := i.flights[key]; ok {
+		return f, false
+	}
+	f := &idempotencyFlight{done: make(chan struct{})}
+	i.flights[key] = f
+	return f, true
+}
+
+func (i *idempotency) leave(key string, f *idempotencyFlight) {
+	i.mu.Lock()
+	delete(i.flights, key)
+	i.mu.Unlock()
+	close(f.done)
+}
+
+func replayIdempotentResponse(w http.ResponseWriter, resp *IdempotentResponse, fingerprint string) {
+	if resp.Fingerprint != fingerprint {
+		WriteError(w, xerrors.WithHTTPStatus(errIdempotencyKeyReused, http.StatusUnprocessableEntity))
+		return
+	}
+
+	h := w.Header()
+	for k, v := range resp.Header {
+		h[k] = v
+	}
+	h.Set(HeaderIdempotentReplayed, "true")
+	w.WriteHeader(resp.StatusCode)
+	w.Write(resp.Body) //nolint:errcheck // the response is already committed.
+}
+
+// idempotencyWriter is a http.ResponseWriter capturing the response written.
+type idempotencyWriter struct {
+	statusWriter
+
+	buf bytes.Buffer
+}
+
+// Write makes idempotencyWriter implement the http.ResponseWriter interface.
+func (w *idempotencyWriter) Write(b []byte) (int, error) {
+	n, err := w.statusWriter.Write(b)
+	w.buf.Write(b[:n])
+	return n, err
+}
+
+type (
+	// IdempotencyOption configures the Idempotency middleware options when calling Idempotency.
+	IdempotencyOption interface {
+		apply(i *idempotency)
+	}
+
+	funcIdempotencyOption struct {
+		fn func(*idempotency)
+	}
+)
+
+func newFuncIdempotencyOption(fn func(*idempotency)) funcIdempotencyOption {
+	return funcIdempotencyOption{
+		fn: fn,
+	}
+}
+
+func (o funcIdempotencyOption) apply(i *idempotency) {
+	o.fn(i)
+}
+
+// IdempotencyKeyScope returns an IdempotencyOption that configures the function returning the scope of
+// the key of a request, e.g. the identity of the client. Keys are only shared by requests of the same scope.
+// If not used, keys are scoped per Authorization header. Value must not be nil, otherwise it panics.
+func IdempotencyKeyScope(fn func(r *http.Request) string) IdempotencyOption {
+	if fn == nil {
+		panic("key scope func is nil")
+	}
+	return newFuncIdempotencyOption(func(i *idempotency) {
+		i.keyScope = fn
+	})
+}
+
+// IdempotencyMaxBodySize returns an IdempotencyOption that configures the max size of the request
+// bodies read to compute their digest. Value must be >= 0, otherwise it panics.
+func IdempotencyMaxBodySize(size xunit.Byte) IdempotencyOption {
+	if size < 0 {
+		panic("invalid max body size value")
+	}
+	return newFuncIdempotencyOption(func(i *idempotency) {
+		i.maxBodySize = size
+	})
+}
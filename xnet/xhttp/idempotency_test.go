@@ -0,0 +1,291 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+func TestIdempotency(t *testing.T) {
+	type request struct {
+		method           string
+		path             string
+		key              string
+		auth             string
+		body             string
+		expectedStatus   int
+		expectedBody     string
+		expectedReplayed bool
+	}
+
+	testCases := []struct {
+		name          string
+		options       []xhttp.IdempotencyOption
+		status        int
+		reqs          []request
+		expectedCalls int32
+	}{
+		{
+			name:   "duplicate request replayed",
+			status: http.StatusCreated,
+			reqs: []request{
+				{key: "k1", body: "a", expectedStatus: http.StatusCreated, expectedBody: "call 1"},
+				{key: "k1", body: "a", expectedStatus: http.StatusCreated, expectedBody: "call 1", expectedReplayed: true},
+			},
+			expectedCalls: 1,
+		},
+		{
+			name:   "distinct keys not replayed",
+			status: http.StatusCreated,
+			reqs: []request{
+				{key: "k1", expectedStatus: http.StatusCreated, expectedBody: "call 1"},
+				{key: "k2", expectedStatus: http.StatusCreated, expectedBody: "call 2"},
+			},
+			expectedCalls: 2,
+		},
+		{
+			name:   "requests without key not replayed",
+			status: http.StatusCreated,
+			reqs: []request{
+				{expectedStatus: http.StatusCreated, expectedBody: "call 1"},
+				{expectedStatus: http.StatusCreated, expectedBody: "call 2"},
+			},
+			expectedCalls: 2,
+		},
+		{
+			name:   "idempotent methods not replayed",
+			status: http.StatusOK,
+			reqs: []request{
+				{method: http.MethodPut, key: "k1", expectedStatus: http.StatusOK, expectedBody: "call 1"},
+				{method: http.MethodPut, key: "k1", expectedStatus: http.StatusOK, expectedBody: "call 2"},
+			},
+			expectedCalls: 2,
+		},
+		{
+			name:   "server errors not stored",
+			status: http.StatusInternalServerError,
+			reqs: []request{
+				{key: "k1", expectedStatus: http.StatusInternalServerError, expectedBody: "call 1"},
+				{key: "k1", expectedStatus: http.StatusInternalServerError, expectedBody: "call 2"},
+			},
+			expectedCalls: 2,
+		},
+		{
+			name:   "key reused with different body",
+			status: http.StatusCreated,
+			reqs: []request{
+				{key: "k1", body: "a", expectedStatus: http.StatusCreated, expectedBody: "call 1"},
+				{key: "k1", body: "b", expectedStatus: http.StatusUnprocessableEntity, expectedBody: "Unprocessable Entity\n"},
+			},
+			expectedCalls: 1,
+		},
+		{
+			name:   "key reused with different path",
+			status: http.StatusCreated,
+			reqs: []request{
+				{key: "k1", path: "/a", expectedStatus: http.StatusCreated, expectedBody: "call 1"},
+				{key: "k1", path: "/b", expectedStatus: http.StatusUnprocessableEntity, expectedBody: "Unprocessable Entity\n"},
+			},
+			expectedCalls: 1,
+		},
+		{
+			name:   "keys scoped per authorization",
+			status: http.StatusCreated,
+			reqs: []request{
+				{key: "k1", auth: "Bearer a", expectedStatus: http.StatusCreated, expectedBody: "call 1"},
+				{key: "k1", auth: "Bearer b", expectedStatus: http.StatusCreated, expectedBody: "call 2"},
+				{key: "k1", auth: "Bearer a", expectedStatus: http.StatusCreated, expectedBody: "call 1", expectedReplayed: true},
+			},
+			expectedCalls: 2,
+		},
+		{
+			name: "custom key scope",
+			options: []xhttp.IdempotencyOption{xhttp.IdempotencyKeyScope(func(r *http.Request) string {
+				return r.URL.Query().Get("tenant")
+			})},
+			status: http.StatusCreated,
+			reqs: []request{
+				{key: "k1", path: "/?tenant=a", auth: "Bearer a", expectedStatus: http.StatusCreated, expectedBody: "call 1"},
+				{key: "k1", path: "/?tenant=a", auth: "Bearer b", expectedStatus: http.StatusCreated, expectedBody: "call 1", expectedReplayed: true},
+				{key: "k1", path: "/?tenant=b", auth: "Bearer a", expectedStatus: http.StatusCreated, expectedBody: "call 2"},
+			},
+			expectedCalls: 2,
+		},
+		{
+			name:    "body too large",
+			options: []xhttp.IdempotencyOption{xhttp.IdempotencyMaxBodySize(xunit.B)},
+			status:  http.StatusCreated,
+			reqs: []request{
+				{key: "k1", body: "ab", expectedStatus: http.StatusRequestEntityTooLarge, expectedBody: "Request Entity Too Large\n"},
+			},
+			expectedCalls: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls atomic.Int32
+			handler := xhttp.Idempotency(xhttp.NewMemoryIdempotencyStore(time.Minute), tc.options...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.Copy(io.Discard, r.Body)
+				w.WriteHeader(tc.status)
+				io.WriteString(w, "call "+strconv.Itoa(int(calls.Add(1))))
+			}))
+
+			for i, req := range tc.reqs {
+				method, path := req.method, req.path
+				if method == "" {
+					method = http.MethodPost
+				}
+				if path == "" {
+					path = "/"
+				}
+
+				r := httptest.NewRequest(method, path, strings.NewReader(req.body))
+				if req.key != "" {
+					r.Header.Set(xhttp.HeaderIdempotencyKey, req.key)
+				}
+				if req.auth != "" {
+					r.Header.Set(xhttp.HeaderAuthorization, req.auth)
+				}
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, r)
+
+				if w.Code != req.expectedStatus {
+					t.Errorf("request %d: status code mismatch: expected %d; got %d", i, req.expectedStatus, w.Code)
+				}
+				if body := w.Body.String(); body != req.expectedBody {
+					t.Errorf("request %d: body mismatch: expected %q; got %q", i, req.expectedBody, body)
+				}
+				if replayed := w.Header().Get(xhttp.HeaderIdempotentReplayed) == "true"; replayed != req.expectedReplayed {
+					t.Errorf("request %d: replayed mismatch: expected %t; got %t", i, req.expectedReplayed, replayed)
+				}
+			}
+
+			if got := calls.Load(); got != tc.expectedCalls {
+				t.Errorf("calls mismatch: expected %d; got %d", tc.expectedCalls, got)
+			}
+		})
+	}
+}
+
+func TestIdempotency_Header(t *testing.T) {
+	store := xhttp.NewMemoryIdempotencyStore(time.Minute)
+	handler := xhttp.Idempotency(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Inner", "inner")
+		w.Header().Set("X-Overridden", "inner")
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/", http.NoBody)
+	r.Header.Set(xhttp.HeaderIdempotencyKey, "k1")
+	w := httptest.NewRecorder()
+	w.Header().Set("X-Outer", "outer")
+	w.Header().Set("X-Overridden", "outer")
+	handler.ServeHTTP(w, r)
+
+	resp, ok := store.Get("k1")
+	if !ok {
+		t.Fatal("response not stored")
+	}
+	expected := http.Header{"X-Inner": {"inner"}, "X-Overridden": {"inner"}}
+	if !reflect.DeepEqual(resp.Header, expected) {
+		t.Errorf("header mismatch: expected %v; got %v", expected, resp.Header)
+	}
+}
+
+func TestIdempotency_Concurrent(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	handler := xhttp.Idempotency(xhttp.NewMemoryIdempotencyStore(time.Minute))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		io.WriteString(w, "done")
+	}))
+
+	const n = 5
+	var wg sync.WaitGroup
+	recorders := make([]*httptest.ResponseRecorder, n)
+	for i := 0; i < n; i++ {
+		recorders[i] = httptest.NewRecorder()
+		wg.Add(1)
+		go func(w *httptest.ResponseRecorder) {
+			defer wg.Done()
+			r := httptest.NewRequest(http.MethodPost, "/", http.NoBody)
+			r.Header.Set(xhttp.HeaderIdempotencyKey, "k1")
+			handler.ServeHTTP(w, r)
+		}(recorders[i])
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("calls mismatch: expected 1; got %d", got)
+	}
+	for i, w := range recorders {
+		if body := w.Body.String(); body != "done" {
+			t.Errorf("request %d: body mismatch: expected %q; got %q", i, "done", body)
+		}
+	}
+}
+
+func TestIdempotency_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+
+	xhttp.Idempotency(nil)
+}
+
+func TestIdempotencyOptions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func() xhttp.IdempotencyOption
+		panic bool
+	}{
+		{
+			name:  "nil key scope",
+			fn:    func() xhttp.IdempotencyOption { return xhttp.IdempotencyKeyScope(nil) },
+			panic: true,
+		},
+		{
+			name: "valid key scope",
+			fn: func() xhttp.IdempotencyOption {
+				return xhttp.IdempotencyKeyScope(func(*http.Request) string { return "" })
+			},
+			panic: false,
+		},
+		{
+			name:  "invalid max body size",
+			fn:    func() xhttp.IdempotencyOption { return xhttp.IdempotencyMaxBodySize(-1) },
+			panic: true,
+		},
+		{
+			name:  "valid max body size",
+			fn:    func() xhttp.IdempotencyOption { return xhttp.IdempotencyMaxBodySize(xunit.KiB) },
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, tc.fn)
+		})
+	}
+}
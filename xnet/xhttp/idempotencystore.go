@@ -0,0 +1,103 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+type (
+	// IdempotentResponse is a response captured by the Idempotency middleware.
+	IdempotentResponse struct {
+		// Fingerprint identifies the request the response was captured for.
+		Fingerprint string
+
+		// StatusCode is the HTTP status code of the response.
+		StatusCode int
+
+		// Header is the header of the response.
+		Header http.Header
+
+		// Body is the body of the response.
+		Body []byte
+	}
+
+	// IdempotencyStore is a store of responses captured by the Idempotency middleware.
+	// Implementations must be safe for concurrent use by multiple goroutines.
+	IdempotencyStore interface {
+		// Get returns the response stored under key and whether it was found.
+		Get(key string) (*IdempotentResponse, bool)
+
+		// Set stores resp under key.
+		Set(key string, resp *IdempotentResponse)
+	}
+)
+
+// memoryIdempotencyStore is an in-memory IdempotencyStore expiring entries after a TTL.
+type memoryIdempotencyStore struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]memoryIdempotencyEntry
+	lastSweep time.Time
+}
+
+type memoryIdempotencyEntry struct {
+	resp      *IdempotentResponse
+	expiresAt time.Time
+}
+
+// NewMemoryIdempotencyStore creates an in-memory IdempotencyStore keeping responses for ttl.
+// Expired responses are evicted lazily. TTL must be > 0, otherwise it panics.
+func NewMemoryIdempotencyStore(ttl time.Duration) IdempotencyStore {
+	if ttl <= 0 {
+		panic("invalid ttl value")
+	}
+	return &memoryIdempotencyStore{
+		ttl:       ttl,
+		entries:   make(map[string]memoryIdempotencyEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// Get makes memoryIdempotencyStore implement the IdempotencyStore interface.
+func (s *memoryIdempotencyStore) Get(key string) (*IdempotentResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expiresAt) {
+		delete(s.entries, key)
+		return nil, false
+	}
+	return e.resp, true
+}
+
+// Set makes memoryIdempotencyStore implement the IdempotencyStore interface.
+func (s *memoryIdempotencyStore) Set(key string, resp *IdempotentResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	// Sweep expired entries at most once per TTL to bound the memory held.
+	if now.Sub(s.lastSweep) >= s.ttl {
+		for k, e := range s.entries {
+			if now.After(e.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	s.entries[key] = memoryIdempotencyEntry{
+		resp:      resp,
+		expiresAt: now.Add(s.ttl),
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	store := xhttp.NewMemoryIdempotencyStore(20 * time.Millisecond)
+
+	if _, ok := store.Get("k1"); ok {
+		t.Fatal("expected no response")
+	}
+
+	resp := &xhttp.IdempotentResponse{StatusCode: http.StatusCreated}
+	store.Set("k1", resp)
+
+	if got, ok := store.Get("k1"); !ok || got != resp {
+		t.Errorf("response mismatch: expected %v; got %v", resp, got)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if _, ok := store.Get("k1"); ok {
+		t.Error("expected response to be expired")
+	}
+}
+
+func TestNewMemoryIdempotencyStore_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+
+	xhttp.NewMemoryIdempotencyStore(0)
+}
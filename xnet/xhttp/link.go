@@ -0,0 +1,210 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Link relation types.
+const (
+	// https://www.iana.org/assignments/link-relations/link-relations.xhtml
+	LinkRelFirst = "first"
+	// https://www.iana.org/assignments/link-relations/link-relations.xhtml
+	LinkRelLast = "last"
+	// https://www.iana.org/assignments/link-relations/link-relations.xhtml
+	LinkRelNext = "next"
+	// https://www.iana.org/assignments/link-relations/link-relations.xhtml
+	LinkRelPrev = "prev"
+)
+
+// Link is a web link as conveyed by the Link header.
+// https://datatracker.ietf.org/doc/html/rfc8288
+type Link struct {
+	// URL is the target of the link, as written in the header.
+	URL string
+
+	// Rel is the relation type of the link, e.g. "next".
+	// Several relation types are separated by a space.
+	Rel string
+
+	// Params are the target attributes of the link other than rel, e.g. "title",
+	// with names in lower case.
+	Params map[string]string
+}
+
+// HasRel returns whether the link has the relation type rel, which is case insensitive.
+func (l Link) HasRel(rel string) bool {
+	for _, r := range strings.Fields(l.Rel) {
+		if strings.EqualFold(r, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseLinkHeader parses the Link header and returns its links in order.
+// Malformed links, and the ones following them in the same header value, are ignored.
+// It returns nil if the header does not exist.
+// https://datatracker.ietf.org/doc/html/rfc8288#section-3
+func ParseLinkHeader(headers http.Header) []Link {
+	var links []Link
+	for _, v := range headers.Values(HeaderLink) {
+		links = append(links, parseLinks(v)...)
+	}
+	return links
+}
+
+// FormatLinkHeader returns the value of a Link header conveying links,
+// e.g. `<https://example.com/?page=2>; rel="next"`. Params are sorted by name.
+func FormatLinkHeader(links ...Link) string {
+	values := make([]string, 0, len(links))
+	for _, l := range links {
+		var sb strings.Builder
+		sb.WriteString("<" + l.URL + ">")
+		if l.Rel != "" {
+			sb.WriteString("; rel=" + quoteString(l.Rel))
+		}
+
+		names := make([]string, 0, len(l.Params))
+		for name := range l.Params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			sb.WriteString("; " + name + "=" + quoteString(l.Params[name]))
+		}
+
+		values = append(values, sb.String())
+	}
+	return strings.Join(values, ", ")
+}
+
+// NextPage returns the URL of the link with the "next" relation type in the Link header of resp,
+// resolved against the request URL, as used by paginated APIs. It returns nil if there is none.
+func NextPage(resp *http.Response) *url.URL {
+	for _, l := range ParseLinkHeader(resp.Header) {
+		if !l.HasRel(LinkRelNext) {
+			continue
+		}
+
+		u, err := url.Parse(l.URL)
+		if err != nil {
+			return nil
+		}
+		if resp.Request != nil && resp.Request.URL != nil {
+			u = resp.Request.URL.ResolveReference(u)
+		}
+		return u
+	}
+	return nil
+}
+
+func parseLinks(s string) []Link {
+	var links []Link
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if s == "" || s[0] != '<' {
+			return links
+		}
+
+		end := strings.IndexByte(s, '>')
+		if end < 0 {
+			return links
+		}
+		l := Link{URL: strings.TrimSpace(s[1:end])}
+		s = s[end+1:]
+
+		for {
+			s = strings.TrimLeft(s, " \t")
+			if s == "" || s[0] == ',' {
+				break
+			}
+			if s[0] != ';' {
+				return links
+			}
+			s = strings.TrimLeft(s[1:], " \t")
+
+			i := strings.IndexAny(s, "=;,")
+			if i < 0 {
+				i = len(s)
+			}
+			name := strings.ToLower(strings.TrimSpace(s[:i]))
+			s = s[i:]
+
+			var value string
+			if s != "" && s[0] == '=' {
+				s = strings.TrimLeft(s[1:], " \t")
+				var ok bool
+				if value, s, ok = consumeValue(s); !ok {
+					return links
+				}
+			}
+
+			switch {
+			case name == "":
+			case name == "rel":
+				// Occurrences after the first one must be ignored: https://datatracker.ietf.org/doc/html/rfc8288#section-3.3.
+				if l.Rel == "" {
+					l.Rel = value
+				}
+			default:
+				if l.Params == nil {
+					l.Params = map[string]string{}
+				}
+				if _, ok := l.Params[name]; !ok {
+					l.Params[name] = value
+				}
+			}
+		}
+
+		links = append(links, l)
+	}
+}
+
+// consumeValue consumes a token or a quoted string at the beginning of s
+// and returns its value along with the rest of s.
+func consumeValue(s string) (value, rest string, ok bool) {
+	if s == "" || s[0] != '"' {
+		i := strings.IndexAny(s, ";,")
+		if i < 0 {
+			i = len(s)
+		}
+		return strings.TrimSpace(s[:i]), s[i:], true
+	}
+
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+			if i < len(s) {
+				sb.WriteByte(s[i])
+			}
+		case '"':
+			return sb.String(), s[i+1:], true
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return "", "", false
+}
+
+// quoteString returns s as a quoted-string as defined in
+// https://datatracker.ietf.org/doc/html/rfc9110#section-5.6.4.
+func quoteString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(s[i])
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,183 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestParseLinkHeader(t *testing.T) {
+	testCases := []struct {
+		name     string
+		headers  http.Header
+		expected []xhttp.Link
+	}{
+		{
+			name:     "no header",
+			headers:  http.Header{},
+			expected: nil,
+		},
+		{
+			name: "github pagination",
+			headers: http.Header{xhttp.HeaderLink: {
+				`<https://api.github.com/repositories/1300192/issues?page=2>; rel="prev", ` +
+					`<https://api.github.com/repositories/1300192/issues?page=4>; rel="next"`,
+			}},
+			expected: []xhttp.Link{
+				{URL: "https://api.github.com/repositories/1300192/issues?page=2", Rel: "prev"},
+				{URL: "https://api.github.com/repositories/1300192/issues?page=4", Rel: "next"},
+			},
+		},
+		{
+			name: "multiple header values",
+			headers: http.Header{xhttp.HeaderLink: {
+				`</first>; rel=first`,
+				`</last>; rel=last`,
+			}},
+			expected: []xhttp.Link{
+				{URL: "/first", Rel: "first"},
+				{URL: "/last", Rel: "last"},
+			},
+		},
+		{
+			name: "params",
+			headers: http.Header{xhttp.HeaderLink: {
+				`<https://example.com/a,b>; REL="next last"; Title="a \"quoted\", title"; type=text/html; crossorigin; rel=ignored`,
+			}},
+			expected: []xhttp.Link{
+				{
+					URL: "https://example.com/a,b",
+					Rel: "next last",
+					Params: map[string]string{
+						"title":       `a "quoted", title`,
+						"type":        "text/html",
+						"crossorigin": "",
+					},
+				},
+			},
+		},
+		{
+			name: "malformed link",
+			headers: http.Header{xhttp.HeaderLink: {
+				`</first>; rel=first, /second; rel=second`,
+				`</third>; rel="unterminated`,
+			}},
+			expected: []xhttp.Link{
+				{URL: "/first", Rel: "first"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xhttp.ParseLinkHeader(tc.headers)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %+v; got %+v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestFormatLinkHeader(t *testing.T) {
+	testCases := []struct {
+		name     string
+		links    []xhttp.Link
+		expected string
+	}{
+		{
+			name:     "no link",
+			links:    nil,
+			expected: "",
+		},
+		{
+			name: "links",
+			links: []xhttp.Link{
+				{URL: "/?page=1", Rel: "prev"},
+				{URL: "/?page=3", Rel: "next", Params: map[string]string{"title": `page "3"`, "hreflang": "en"}},
+				{URL: "/"},
+			},
+			expected: `</?page=1>; rel="prev", </?page=3>; rel="next"; hreflang="en"; title="page \"3\"", </>`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xhttp.FormatLinkHeader(tc.links...)
+			if got != tc.expected {
+				t.Errorf("expected %q; got %q", tc.expected, got)
+			}
+
+			if parsed := xhttp.ParseLinkHeader(http.Header{xhttp.HeaderLink: {got}}); len(tc.links) > 0 && !reflect.DeepEqual(parsed, tc.links) {
+				t.Errorf("round trip mismatch: expected %+v; got %+v", tc.links, parsed)
+			}
+		})
+	}
+}
+
+func TestLink_HasRel(t *testing.T) {
+	l := xhttp.Link{URL: "/", Rel: "next  Last"}
+
+	for rel, expected := range map[string]bool{"next": true, "last": true, "prev": false, "": false} {
+		if got := l.HasRel(rel); got != expected {
+			t.Errorf("rel %q: expected %t; got %t", rel, expected, got)
+		}
+	}
+}
+
+func TestNextPage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		link     string
+		expected string
+	}{
+		{
+			name:     "no link",
+			link:     "",
+			expected: "",
+		},
+		{
+			name:     "absolute",
+			link:     `<https://example.com/items?page=3>; rel="next"`,
+			expected: "https://example.com/items?page=3",
+		},
+		{
+			name:     "relative",
+			link:     `</items?page=1>; rel="prev", </items?page=3>; rel="next last"`,
+			expected: "https://api.example.com/items?page=3",
+		},
+		{
+			name:     "no next",
+			link:     `</items?page=1>; rel="prev"`,
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{
+				Header:  http.Header{},
+				Request: httptest.NewRequest(http.MethodGet, "https://api.example.com/items?page=2", http.NoBody),
+			}
+			if tc.link != "" {
+				resp.Header.Set(xhttp.HeaderLink, tc.link)
+			}
+
+			got := xhttp.NextPage(resp)
+			if tc.expected == "" {
+				if got != nil {
+					t.Errorf("expected nil; got %s", got)
+				}
+				return
+			}
+			if got == nil || got.String() != tc.expected {
+				t.Errorf("expected %s; got %v", tc.expected, got)
+			}
+		})
+	}
+}
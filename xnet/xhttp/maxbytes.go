@@ -0,0 +1,56 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jlourenc/xgo/xerrors"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+// ErrBodyTooLarge is returned when a body exceeds the size limit set by
+// the MaxBytes middleware, a MaxBytesTransport or a MultipartReader.
+var ErrBodyTooLarge = errors.New("body too large")
+
+// MaxBytes returns a Middleware limiting the size of request bodies to limit. Requests announcing a larger
+// body with their Content-Length are replied to with a 413 Content Too Large error written with WriteError,
+// without calling the next handler. Otherwise, reading beyond the limit fails with an *http.MaxBytesError,
+// which the handler should reply to with a 413 error, and the server closes the connection afterwards.
+// Limit must be >= 0, otherwise it panics.
+func MaxBytes(limit xunit.Byte) Middleware {
+	if limit < 0 {
+		panic("invalid limit value")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > int64(limit) {
+				w.Header().Set(HeaderConnection, "close")
+				WriteError(w, xerrors.WithHTTPStatus(ErrBodyTooLarge, http.StatusRequestEntityTooLarge))
+				return
+			}
+
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = http.MaxBytesReader(w, r.Body, int64(limit))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// limitRequestBody limits the size of the body of r to limit. It returns ErrBodyTooLarge, with a 413 status,
+// if r announces a larger body with its Content-Length.
+func limitRequestBody(w http.ResponseWriter, r *http.Request, limit xunit.Byte) error {
+	if r.ContentLength > int64(limit) {
+		w.Header().Set(HeaderConnection, "close")
+		return xerrors.WithHTTPStatus(ErrBodyTooLarge, http.StatusRequestEntityTooLarge)
+	}
+	if r.Body != nil && r.Body != http.NoBody {
+		r.Body = http.MaxBytesReader(w, r.Body, int64(limit))
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestMaxBytes(t *testing.T) {
+	testCases := []struct {
+		name           string
+		body           io.Reader
+		contentLength  int64
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "no body",
+			body:           http.NoBody,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "",
+		},
+		{
+			name:           "body within limit",
+			body:           strings.NewReader("hello"),
+			contentLength:  5,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "hello",
+		},
+		{
+			name:           "content length exceeding limit",
+			body:           strings.NewReader("hello world"),
+			contentLength:  11,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   "Request Entity Too Large\n",
+		},
+		{
+			name:           "unknown length body exceeding limit",
+			body:           strings.NewReader("hello world"),
+			contentLength:  -1,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   "too large\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := xhttp.MaxBytes(5)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, err := io.ReadAll(r.Body)
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					http.Error(w, "too large", http.StatusRequestEntityTooLarge)
+					return
+				}
+				w.Write(b)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/", tc.body)
+			req.ContentLength = tc.contentLength
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("status code mismatch: expected %d; got %d", tc.expectedStatus, w.Code)
+			}
+			if body := w.Body.String(); body != tc.expectedBody {
+				t.Errorf("body mismatch: expected %q; got %q", tc.expectedBody, body)
+			}
+		})
+	}
+}
+
+func TestMaxBytes_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+
+	xhttp.MaxBytes(-1)
+}
@@ -0,0 +1,128 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/jlourenc/xgo/xio"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+// MaxBytesTransport is an HTTP transport that limits the size of response bodies.
+type maxBytesTransport struct {
+	next  http.RoundTripper
+	limit xunit.Byte
+}
+
+// NewMaxBytesTransport creates a new MaxBytesTransport limiting the size of response bodies to limit,
+// configured with the options passed in input, notably the next round tripper in the chain.
+// Limit must be >= 0, otherwise it panics.
+//
+// To protect against decompression bombs, a DecompressTransport must come after it in the chain,
+// so that decoded bodies are limited.
+func NewMaxBytesTransport(limit xunit.Byte, options ...MaxBytesTransportOption) http.RoundTripper {
+	if limit < 0 {
+		panic("invalid limit value")
+	}
+
+	t := &maxBytesTransport{
+		next:  http.DefaultTransport,
+		limit: limit,
+	}
+
+	for _, opt := range options {
+		opt.apply(t)
+	}
+
+	return t
+}
+
+// RoundTrip makes MaxBytesTransport implement the RoundTripper interface.
+//
+// Responses announcing a larger body with their Content-Length fail with ErrBodyTooLarge.
+// Otherwise, reading the body beyond the limit fails with ErrBodyTooLarge.
+func (t *maxBytesTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	if resp.ContentLength > int64(t.limit) {
+		xio.DrainClose(resp.Body) //nolint:errcheck // the response is discarded.
+		return nil, fmt.Errorf("%w: content length %s exceeds limit %s", ErrBodyTooLarge, xunit.Byte(resp.ContentLength), t.limit)
+	}
+
+	if resp.Body != nil && resp.Body != http.NoBody {
+		resp.Body = &maxBytesBody{
+			ReadCloser: resp.Body,
+			remaining:  int64(t.limit),
+		}
+	}
+
+	return resp, nil
+}
+
+// maxBytesBody is a response body failing with ErrBodyTooLarge once more than remaining bytes are read.
+type maxBytesBody struct {
+	io.ReadCloser
+
+	remaining int64
+	err       error
+}
+
+func (b *maxBytesBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	// Read one more byte than remaining to detect oversized bodies.
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.err = ErrBodyTooLarge
+		err = b.err
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
+
+type (
+	// MaxBytesTransportOption configures the MaxBytesTransport options
+	// when calling NewMaxBytesTransport.
+	MaxBytesTransportOption interface {
+		apply(t *maxBytesTransport)
+	}
+
+	funcMaxBytesTransportOption struct {
+		fn func(*maxBytesTransport)
+	}
+)
+
+func newFuncMaxBytesTransportOption(fn func(*maxBytesTransport)) funcMaxBytesTransportOption {
+	return funcMaxBytesTransportOption{
+		fn: fn,
+	}
+}
+
+func (o funcMaxBytesTransportOption) apply(t *maxBytesTransport) {
+	o.fn(t)
+}
+
+// MaxBytesTransportNextRoundTripper returns a MaxBytesTransportOption that configures the
+// next round tripper to call. If not used http.DefaultTransport will be used.
+func MaxBytesTransportNextRoundTripper(next http.RoundTripper) MaxBytesTransportOption {
+	if next == nil {
+		panic("next http.RoundTripper is nil")
+	}
+	return newFuncMaxBytesTransportOption(func(t *maxBytesTransport) {
+		t.next = next
+	})
+}
@@ -0,0 +1,136 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestMaxBytesTransport_RoundTrip(t *testing.T) {
+	testCases := []struct {
+		name          string
+		body          string
+		contentLength int64
+		expectedErr   error
+		expectedBody  string
+		expectedRead  error
+	}{
+		{
+			name:          "body within limit",
+			body:          "hello",
+			contentLength: 5,
+			expectedBody:  "hello",
+		},
+		{
+			name:          "content length exceeding limit",
+			body:          "hello world",
+			contentLength: 11,
+			expectedErr:   xhttp.ErrBodyTooLarge,
+		},
+		{
+			name:          "unknown length body within limit",
+			body:          "hello",
+			contentLength: -1,
+			expectedBody:  "hello",
+		},
+		{
+			name:          "unknown length body exceeding limit",
+			body:          "hello world",
+			contentLength: -1,
+			expectedBody:  "hello",
+			expectedRead:  xhttp.ErrBodyTooLarge,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			next := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode:    http.StatusOK,
+					Body:          io.NopCloser(strings.NewReader(tc.body)),
+					ContentLength: tc.contentLength,
+				}, nil
+			})
+			transport := xhttp.NewMaxBytesTransport(5, xhttp.MaxBytesTransportNextRoundTripper(next))
+
+			req, err := http.NewRequest(http.MethodGet, "http://example.com", http.NoBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := transport.RoundTrip(req)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("error mismatch: expected %v; got %v", tc.expectedErr, err)
+			}
+			if err != nil {
+				return
+			}
+			defer resp.Body.Close()
+
+			b, err := io.ReadAll(resp.Body)
+			if !errors.Is(err, tc.expectedRead) {
+				t.Errorf("read error mismatch: expected %v; got %v", tc.expectedRead, err)
+			}
+			if string(b) != tc.expectedBody {
+				t.Errorf("body mismatch: expected %q; got %q", tc.expectedBody, b)
+			}
+		})
+	}
+}
+
+func TestMaxBytesTransport_RoundTrip_Error(t *testing.T) {
+	transport := xhttp.NewMaxBytesTransport(5, xhttp.MaxBytesTransportNextRoundTripper(&fakeTransport{}))
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", http.NoBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = transport.RoundTrip(req); err != errNoResponse {
+		t.Errorf("error mismatch: %v != %v", err, errNoResponse)
+	}
+}
+
+func TestNewMaxBytesTransport_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+
+	xhttp.NewMaxBytesTransport(-1)
+}
+
+func TestMaxBytesTransportNextRoundTripper(t *testing.T) {
+	testCases := []struct {
+		name  string
+		next  http.RoundTripper
+		panic bool
+	}{
+		{
+			name:  "panic",
+			next:  nil,
+			panic: true,
+		},
+		{
+			name:  "valid",
+			next:  &fakeTransport{},
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, func() xhttp.MaxBytesTransportOption {
+				return xhttp.MaxBytesTransportNextRoundTripper(tc.next)
+			})
+		})
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import "net/http"
+
+// Middleware wraps an HTTP handler to run logic before and/or after it.
+type Middleware func(http.Handler) http.Handler
+
+// Chain returns a Middleware composing the given middlewares, in order: the first one
+// is the outermost, i.e. the first to handle requests. Nil middlewares are ignored.
+func Chain(mws ...Middleware) Middleware {
+	return func(h http.Handler) http.Handler {
+		for i := len(mws) - 1; i >= 0; i-- {
+			if mws[i] != nil {
+				h = mws[i](h)
+			}
+		}
+		return h
+	}
+}
+
+// Then returns h wrapped by m. If h is nil, http.DefaultServeMux is wrapped.
+func (m Middleware) Then(h http.Handler) http.Handler {
+	if h == nil {
+		h = http.DefaultServeMux
+	}
+	if m == nil {
+		return h
+	}
+	return m(h)
+}
+
+// ThenFunc returns fn wrapped by m. If fn is nil, http.DefaultServeMux is wrapped.
+func (m Middleware) ThenFunc(fn http.HandlerFunc) http.Handler {
+	if fn == nil {
+		return m.Then(nil)
+	}
+	return m.Then(fn)
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func tagMiddleware(tag string) xhttp.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, tag+">")
+			next.ServeHTTP(w, r)
+			io.WriteString(w, "<"+tag)
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, "handler")
+	})
+
+	testCases := []struct {
+		name     string
+		mw       xhttp.Middleware
+		expected string
+	}{
+		{
+			name:     "no middleware",
+			mw:       xhttp.Chain(),
+			expected: "handler",
+		},
+		{
+			name:     "nil middleware",
+			mw:       nil,
+			expected: "handler",
+		},
+		{
+			name:     "middlewares in order",
+			mw:       xhttp.Chain(tagMiddleware("a"), nil, tagMiddleware("b")),
+			expected: "a>b>handler<b<a",
+		},
+		{
+			name:     "nested chains",
+			mw:       xhttp.Chain(tagMiddleware("a"), xhttp.Chain(tagMiddleware("b"), tagMiddleware("c"))),
+			expected: "a>b>c>handler<c<b<a",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for name, h := range map[string]http.Handler{
+				"Then":     tc.mw.Then(handler),
+				"ThenFunc": tc.mw.ThenFunc(handler),
+			} {
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+				if got := rec.Body.String(); got != tc.expected {
+					t.Errorf("%s: expected %q; got %q", name, tc.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMiddleware_Then(t *testing.T) {
+	if got := xhttp.Chain().Then(nil); got != http.DefaultServeMux {
+		t.Errorf("expected http.DefaultServeMux; got %v", got)
+	}
+	if got := xhttp.Chain().ThenFunc(nil); got != http.DefaultServeMux {
+		t.Errorf("expected http.DefaultServeMux; got %v", got)
+	}
+}
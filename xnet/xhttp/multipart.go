@@ -0,0 +1,405 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jlourenc/xgo/xunit"
+)
+
+const (
+	multipartDefaultMaxParts     = 1000
+	multipartFormDataMediaType   = "multipart/form-data"
+	multipartDefaultFileMimeType = "application/octet-stream"
+)
+
+// ErrTooManyParts is returned by MultipartReader when a multipart body has more parts than allowed.
+var ErrTooManyParts = errors.New("too many parts")
+
+// MultipartField is a field of a multipart/form-data body built with NewMultipartRequest.
+type MultipartField struct {
+	// Name is the name of the form field.
+	Name string
+
+	// FileName is the name of the file, if the field is a file.
+	FileName string
+
+	// ContentType is the media type of the field content. If empty, no Content-Type is set for values
+	// and application/octet-stream is used for files.
+	ContentType string
+
+	open       func() (io.Reader, error)
+	rewindable bool
+}
+
+// MultipartValue returns a MultipartField holding the value of a form field.
+func MultipartValue(name, value string) MultipartField {
+	return MultipartField{
+		Name: name,
+		open: func() (io.Reader, error) {
+			return strings.NewReader(value), nil
+		},
+		rewindable: true,
+	}
+}
+
+// MultipartFile returns a MultipartField holding the content of the file at path, named after its base name
+// and typed after its extension. The file is opened when the body is written and closed once copied.
+func MultipartFile(name, path string) MultipartField {
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = multipartDefaultFileMimeType
+	}
+
+	return MultipartField{
+		Name:        name,
+		FileName:    filepath.Base(path),
+		ContentType: contentType,
+		open: func() (io.Reader, error) {
+			return os.Open(path)
+		},
+		rewindable: true,
+	}
+}
+
+// MultipartStream returns a MultipartField holding the content read from r as a file named fileName.
+// If r implements io.Closer, it is closed once copied.
+//
+// As r can only be read once, a request including such a field cannot be retried.
+func MultipartStream(name, fileName string, r io.Reader) MultipartField {
+	return MultipartField{
+		Name:     name,
+		FileName: fileName,
+		open: func() (io.Reader, error) {
+			return r, nil
+		},
+	}
+}
+
+type multipartRequest struct {
+	fields   []MultipartField
+	boundary string
+	progress func(written xunit.Byte)
+}
+
+// NewMultipartRequest returns a new request with a multipart/form-data body made of fields, configured with
+// the options passed in input. The body is streamed as it is sent, without being buffered in memory, and its
+// Content-Type, including the boundary, is set accordingly.
+//
+// When all the fields can be read several times, i.e. none was created with MultipartStream, the GetBody
+// field of the request is set so that it can be retried or redirected.
+func NewMultipartRequest(
+	ctx context.Context, method, url string, fields []MultipartField, options ...MultipartRequestOption,
+) (*http.Request, error) {
+	m := &multipartRequest{
+		fields:   fields,
+		boundary: multipart.NewWriter(io.Discard).Boundary(),
+	}
+
+	for _, opt := range options {
+		opt.apply(m)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, m.body())
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set(HeaderContentType, mime.FormatMediaType(multipartFormDataMediaType, map[string]string{"boundary": m.boundary}))
+
+	rewindable := true
+	for _, f := range fields {
+		rewindable = rewindable && f.rewindable
+	}
+	if rewindable {
+		req.GetBody = func() (io.ReadCloser, error) {
+			return m.body(), nil
+		}
+	}
+
+	return req, nil
+}
+
+// body returns a reader streaming the multipart body, written by a goroutine
+// until it is fully read or closed.
+func (m *multipartRequest) body() io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	go func() {
+		pw.CloseWithError(m.write(&progressWriter{w: pw, fn: m.progress}))
+	}()
+
+	return pr
+}
+
+func (m *multipartRequest) write(w io.Writer) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(m.boundary); err != nil {
+		return err
+	}
+
+	for _, f := range m.fields {
+		if err := writeMultipartField(mw, f); err != nil {
+			return fmt.Errorf("writing field %q: %w", f.Name, err)
+		}
+	}
+
+	return mw.Close()
+}
+
+func writeMultipartField(mw *multipart.Writer, f MultipartField) error {
+	r, err := f.open()
+	if err != nil {
+		return err
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	disposition := `form-data; name="` + escapeMultipartQuotes(f.Name) + `"`
+	if f.FileName != "" {
+		disposition += `; filename="` + escapeMultipartQuotes(f.FileName) + `"`
+	}
+
+	h := textproto.MIMEHeader{}
+	h.Set(HeaderContentDisposition, disposition)
+	switch {
+	case f.ContentType != "":
+		h.Set(HeaderContentType, f.ContentType)
+	case f.FileName != "":
+		h.Set(HeaderContentType, multipartDefaultFileMimeType)
+	}
+
+	pw, err := mw.CreatePart(h)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(pw, r)
+	return err
+}
+
+var multipartQuoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func escapeMultipartQuotes(s string) string {
+	return multipartQuoteEscaper.Replace(s)
+}
+
+// progressWriter is a writer reporting the number of bytes written so far to fn, if not nil.
+type progressWriter struct {
+	w       io.Writer
+	fn      func(written xunit.Byte)
+	written int64
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.written += int64(n)
+	if w.fn != nil && n > 0 {
+		w.fn(xunit.Byte(w.written))
+	}
+	return n, err
+}
+
+type (
+	// MultipartRequestOption configures the multipart request options
+	// when calling NewMultipartRequest.
+	MultipartRequestOption interface {
+		apply(m *multipartRequest)
+	}
+
+	funcMultipartRequestOption struct {
+		fn func(*multipartRequest)
+	}
+)
+
+func newFuncMultipartRequestOption(fn func(*multipartRequest)) funcMultipartRequestOption {
+	return funcMultipartRequestOption{
+		fn: fn,
+	}
+}
+
+func (o funcMultipartRequestOption) apply(m *multipartRequest) {
+	o.fn(m)
+}
+
+// MultipartRequestBoundary returns a MultipartRequestOption that configures the boundary separating
+// the parts of the body. It must be a valid boundary as defined by RFC 2046, otherwise it panics.
+// If not used, a random boundary is generated.
+func MultipartRequestBoundary(boundary string) MultipartRequestOption {
+	if err := multipart.NewWriter(io.Discard).SetBoundary(boundary); err != nil {
+		panic("invalid boundary value")
+	}
+	return newFuncMultipartRequestOption(func(m *multipartRequest) {
+		m.boundary = boundary
+	})
+}
+
+// MultipartRequestProgress returns a MultipartRequestOption that configures a function called with
+// the number of bytes of the body written so far, each time some are sent.
+func MultipartRequestProgress(fn func(written xunit.Byte)) MultipartRequestOption {
+	if fn == nil {
+		panic("progress function is nil")
+	}
+	return newFuncMultipartRequestOption(func(m *multipartRequest) {
+		m.progress = fn
+	})
+}
+
+// MultipartReader iterates over the parts of a multipart request body, enforcing size limits.
+type MultipartReader struct {
+	r           *multipart.Reader
+	maxParts    int
+	maxPartSize xunit.Byte
+	maxSize     xunit.Byte
+	parts       int
+}
+
+// MultipartPart is a part of a multipart body read with a MultipartReader.
+// Reading its content beyond the part size limit fails with ErrBodyTooLarge.
+type MultipartPart struct {
+	*multipart.Part
+
+	body io.Reader
+}
+
+// Read reads the content of the part.
+func (p *MultipartPart) Read(b []byte) (int, error) {
+	return p.body.Read(b)
+}
+
+// NewMultipartReader returns a MultipartReader iterating over the parts of the multipart body of r,
+// configured with the options passed in input. By default, a body may have up to 1000 parts,
+// without any size limit.
+//
+// It fails with http.ErrNotMultipart if r is not a multipart request and with ErrBodyTooLarge
+// if r announces a body larger than the size limit with its Content-Length.
+func NewMultipartReader(r *http.Request, options ...MultipartReaderOption) (*MultipartReader, error) {
+	mr := &MultipartReader{
+		maxParts:    multipartDefaultMaxParts,
+		maxPartSize: -1,
+		maxSize:     -1,
+	}
+
+	for _, opt := range options {
+		opt.apply(mr)
+	}
+
+	if mr.maxSize >= 0 {
+		if r.ContentLength > int64(mr.maxSize) {
+			return nil, fmt.Errorf("%w: content length %s exceeds limit %s", ErrBodyTooLarge, xunit.Byte(r.ContentLength), mr.maxSize)
+		}
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = &maxBytesBody{
+				ReadCloser: r.Body,
+				remaining:  int64(mr.maxSize),
+			}
+		}
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	mr.r = reader
+
+	return mr, nil
+}
+
+// NextPart returns the next part of the body, or io.EOF if there are no more parts.
+// It fails with ErrTooManyParts once the limit on the number of parts is exceeded and with
+// ErrBodyTooLarge once the body size limit is exceeded.
+func (mr *MultipartReader) NextPart() (*MultipartPart, error) {
+	if mr.parts >= mr.maxParts {
+		// Check whether the body has any other part before failing.
+		if _, err := mr.r.NextPart(); err != nil {
+			return nil, err
+		}
+		return nil, ErrTooManyParts
+	}
+
+	p, err := mr.r.NextPart()
+	if err != nil {
+		return nil, err
+	}
+	mr.parts++
+
+	var body io.Reader = p
+	if mr.maxPartSize >= 0 {
+		body = &maxBytesBody{
+			ReadCloser: p,
+			remaining:  int64(mr.maxPartSize),
+		}
+	}
+
+	return &MultipartPart{
+		Part: p,
+		body: body,
+	}, nil
+}
+
+type (
+	// MultipartReaderOption configures the MultipartReader options when calling NewMultipartReader.
+	MultipartReaderOption interface {
+		apply(mr *MultipartReader)
+	}
+
+	funcMultipartReaderOption struct {
+		fn func(*MultipartReader)
+	}
+)
+
+func newFuncMultipartReaderOption(fn func(*MultipartReader)) funcMultipartReaderOption {
+	return funcMultipartReaderOption{
+		fn: fn,
+	}
+}
+
+func (o funcMultipartReaderOption) apply(mr *MultipartReader) {
+	o.fn(mr)
+}
+
+// MultipartReaderMaxPartSize returns a MultipartReaderOption that configures the max size of the content
+// of each part. Value must be >= 0, otherwise it panics.
+func MultipartReaderMaxPartSize(size xunit.Byte) MultipartReaderOption {
+	if size < 0 {
+		panic("invalid max part size value")
+	}
+	return newFuncMultipartReaderOption(func(mr *MultipartReader) {
+		mr.maxPartSize = size
+	})
+}
+
+// MultipartReaderMaxParts returns a MultipartReaderOption that configures the max number of parts
+// of the body. Value must be > 0, otherwise it panics.
+func MultipartReaderMaxParts(n int) MultipartReaderOption {
+	if n <= 0 {
+		panic("invalid max parts value")
+	}
+	return newFuncMultipartReaderOption(func(mr *MultipartReader) {
+		mr.maxParts = n
+	})
+}
+
+// MultipartReaderMaxSize returns a MultipartReaderOption that configures the max size of the whole body,
+// including part headers and boundaries. Value must be >= 0, otherwise it panics.
+func MultipartReaderMaxSize(size xunit.Byte) MultipartReaderOption {
+	if size < 0 {
+		panic("invalid max size value")
+	}
+	return newFuncMultipartReaderOption(func(mr *MultipartReader) {
+		mr.maxSize = size
+	})
+}
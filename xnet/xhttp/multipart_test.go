@@ -0,0 +1,343 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+func TestNewMultipartRequest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.txt")
+	if err := os.WriteFile(path, []byte("file content"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name               string
+		fields             []xhttp.MultipartField
+		expectedFields     map[string]string
+		expectedFiles      map[string]string
+		expectedTypes      map[string]string
+		expectedRewindable bool
+	}{
+		{
+			name: "values and file",
+			fields: []xhttp.MultipartField{
+				xhttp.MultipartValue("title", "quarterly"),
+				xhttp.MultipartFile("report", path),
+			},
+			expectedFields:     map[string]string{"title": "quarterly"},
+			expectedFiles:      map[string]string{"report": "report.txt: file content"},
+			expectedTypes:      map[string]string{"report": "text/plain; charset=utf-8"},
+			expectedRewindable: true,
+		},
+		{
+			name: "stream",
+			fields: []xhttp.MultipartField{
+				xhttp.MultipartStream("data", `da"ta.bin`, strings.NewReader("streamed content")),
+			},
+			expectedFields:     map[string]string{},
+			expectedFiles:      map[string]string{"data": `da"ta.bin: streamed content`},
+			expectedTypes:      map[string]string{"data": "application/octet-stream"},
+			expectedRewindable: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				fields = map[string]string{}
+				files  = map[string]string{}
+				types  = map[string]string{}
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseMultipartForm(1 << 20); err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				for k, v := range r.MultipartForm.Value {
+					fields[k] = v[0]
+				}
+				for k, v := range r.MultipartForm.File {
+					f, err := v[0].Open()
+					if err != nil {
+						t.Errorf("unexpected error: %v", err)
+						return
+					}
+					b, _ := io.ReadAll(f)
+					f.Close()
+					files[k] = v[0].Filename + ": " + string(b)
+					types[k] = v[0].Header.Get(xhttp.HeaderContentType)
+				}
+			}))
+			defer srv.Close()
+
+			var written xunit.Byte
+			req, err := xhttp.NewMultipartRequest(context.Background(), http.MethodPost, srv.URL, tc.fields,
+				xhttp.MultipartRequestProgress(func(n xunit.Byte) { written = n }))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if rewindable := req.GetBody != nil; rewindable != tc.expectedRewindable {
+				t.Errorf("rewindable mismatch: expected %t; got %t", tc.expectedRewindable, rewindable)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp.Body.Close()
+
+			if !reflect.DeepEqual(tc.expectedFields, fields) {
+				t.Errorf("fields mismatch: expected %v; got %v", tc.expectedFields, fields)
+			}
+			if !reflect.DeepEqual(tc.expectedFiles, files) {
+				t.Errorf("files mismatch: expected %v; got %v", tc.expectedFiles, files)
+			}
+			if !reflect.DeepEqual(tc.expectedTypes, types) {
+				t.Errorf("types mismatch: expected %v; got %v", tc.expectedTypes, types)
+			}
+			if written == 0 {
+				t.Error("expected progress to be reported")
+			}
+		})
+	}
+}
+
+func TestNewMultipartRequest_GetBody(t *testing.T) {
+	req, err := xhttp.NewMultipartRequest(context.Background(), http.MethodPost, "http://example.com",
+		[]xhttp.MultipartField{xhttp.MultipartValue("key", "value")}, xhttp.MultipartRequestBoundary("boundary"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if ct := req.Header.Get(xhttp.HeaderContentType); ct != "multipart/form-data; boundary=boundary" {
+		t.Errorf("content type mismatch: expected %q; got %q", "multipart/form-data; boundary=boundary", ct)
+	}
+
+	first, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(first, second) {
+		t.Errorf("body mismatch: expected %q; got %q", first, second)
+	}
+}
+
+func TestNewMultipartRequest_Error(t *testing.T) {
+	req, err := xhttp.NewMultipartRequest(context.Background(), http.MethodPost, "http://example.com",
+		[]xhttp.MultipartField{xhttp.MultipartFile("file", filepath.Join(t.TempDir(), "missing"))})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err = io.ReadAll(req.Body); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("error mismatch: expected %v; got %v", os.ErrNotExist, err)
+	}
+}
+
+func TestNewMultipartReader(t *testing.T) {
+	newBody := func(t *testing.T, parts ...string) (*bytes.Buffer, string) {
+		t.Helper()
+		var b bytes.Buffer
+		mw := multipart.NewWriter(&b)
+		for i, p := range parts {
+			if err := mw.WriteField(string(rune('a'+i)), p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		mw.Close()
+		return &b, mw.FormDataContentType()
+	}
+
+	testCases := []struct {
+		name          string
+		parts         []string
+		contentType   string
+		options       []xhttp.MultipartReaderOption
+		expectedParts []string
+		expectedErr   error
+	}{
+		{
+			name:          "no limit",
+			parts:         []string{"one", "two"},
+			expectedParts: []string{"one", "two"},
+		},
+		{
+			name:          "within limits",
+			parts:         []string{"one", "two"},
+			options:       []xhttp.MultipartReaderOption{xhttp.MultipartReaderMaxParts(2), xhttp.MultipartReaderMaxPartSize(3)},
+			expectedParts: []string{"one", "two"},
+		},
+		{
+			name:          "part too large",
+			parts:         []string{"one", "three"},
+			options:       []xhttp.MultipartReaderOption{xhttp.MultipartReaderMaxPartSize(3)},
+			expectedParts: []string{"one"},
+			expectedErr:   xhttp.ErrBodyTooLarge,
+		},
+		{
+			name:          "too many parts",
+			parts:         []string{"one", "two", "three"},
+			options:       []xhttp.MultipartReaderOption{xhttp.MultipartReaderMaxParts(2)},
+			expectedParts: []string{"one", "two"},
+			expectedErr:   xhttp.ErrTooManyParts,
+		},
+		{
+			name:        "body too large",
+			parts:       []string{"one"},
+			options:     []xhttp.MultipartReaderOption{xhttp.MultipartReaderMaxSize(10)},
+			expectedErr: xhttp.ErrBodyTooLarge,
+		},
+		{
+			name:        "not multipart",
+			parts:       []string{"one"},
+			contentType: "text/plain",
+			expectedErr: http.ErrNotMultipart,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, contentType := newBody(t, tc.parts...)
+			if tc.contentType != "" {
+				contentType = tc.contentType
+			}
+			req := httptest.NewRequest(http.MethodPost, "/", body)
+			req.Header.Set(xhttp.HeaderContentType, contentType)
+
+			var (
+				parts []string
+				err   error
+			)
+			mr, err := xhttp.NewMultipartReader(req, tc.options...)
+			for err == nil {
+				var p *xhttp.MultipartPart
+				if p, err = mr.NextPart(); err != nil {
+					break
+				}
+				var b []byte
+				if b, err = io.ReadAll(p); err != nil {
+					break
+				}
+				parts = append(parts, string(b))
+			}
+			if err == io.EOF {
+				err = nil
+			}
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("error mismatch: expected %v; got %v", tc.expectedErr, err)
+			}
+			if !reflect.DeepEqual(tc.expectedParts, parts) {
+				t.Errorf("parts mismatch: expected %q; got %q", tc.expectedParts, parts)
+			}
+		})
+	}
+}
+
+func TestMultipartRequestOptions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func() xhttp.MultipartRequestOption
+		panic bool
+	}{
+		{
+			name:  "boundary invalid",
+			fn:    func() xhttp.MultipartRequestOption { return xhttp.MultipartRequestBoundary("invalid boundary ") },
+			panic: true,
+		},
+		{
+			name:  "boundary valid",
+			fn:    func() xhttp.MultipartRequestOption { return xhttp.MultipartRequestBoundary("boundary") },
+			panic: false,
+		},
+		{
+			name:  "progress nil",
+			fn:    func() xhttp.MultipartRequestOption { return xhttp.MultipartRequestProgress(nil) },
+			panic: true,
+		},
+		{
+			name:  "progress valid",
+			fn:    func() xhttp.MultipartRequestOption { return xhttp.MultipartRequestProgress(func(xunit.Byte) {}) },
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, tc.fn)
+		})
+	}
+}
+
+func TestMultipartReaderOptions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func() xhttp.MultipartReaderOption
+		panic bool
+	}{
+		{
+			name:  "max part size invalid",
+			fn:    func() xhttp.MultipartReaderOption { return xhttp.MultipartReaderMaxPartSize(-1) },
+			panic: true,
+		},
+		{
+			name:  "max part size valid",
+			fn:    func() xhttp.MultipartReaderOption { return xhttp.MultipartReaderMaxPartSize(0) },
+			panic: false,
+		},
+		{
+			name:  "max parts invalid",
+			fn:    func() xhttp.MultipartReaderOption { return xhttp.MultipartReaderMaxParts(0) },
+			panic: true,
+		},
+		{
+			name:  "max parts valid",
+			fn:    func() xhttp.MultipartReaderOption { return xhttp.MultipartReaderMaxParts(1) },
+			panic: false,
+		},
+		{
+			name:  "max size invalid",
+			fn:    func() xhttp.MultipartReaderOption { return xhttp.MultipartReaderMaxSize(-1) },
+			panic: true,
+		},
+		{
+			name:  "max size valid",
+			fn:    func() xhttp.MultipartReaderOption { return xhttp.MultipartReaderMaxSize(0) },
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, tc.fn)
+		})
+	}
+}
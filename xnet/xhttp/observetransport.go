@@ -0,0 +1,255 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet/xhttp/xhttptrace"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+type (
+	// Observation describes a completed HTTP round trip.
+	Observation struct {
+		// Method is the HTTP method of the request.
+		Method string
+
+		// Host is the host targeted by the request.
+		Host string
+
+		// StatusCode is the HTTP status code of the response, or 0 if the round trip failed.
+		StatusCode int
+
+		// Attempts is the number of attempts made, including retries made by
+		// a RetryTransport placed after the ObserveTransport in the chain.
+		Attempts int
+
+		// RequestSize is the size of the request body as announced by its Content-Length,
+		// or 0 if unknown.
+		RequestSize xunit.Byte
+
+		// ResponseSize is the size of the response body read by the caller.
+		ResponseSize xunit.Byte
+
+		// Latency is the duration between the start of the round trip and either the
+		// response body being fully read or closed, or the round trip failing.
+		Latency time.Duration
+
+		// Err is the error returned by the round trip, if any.
+		Err error
+	}
+
+	// Observer is notified of completed HTTP round trips, e.g. to log them or record metrics.
+	// Implementations must be safe for concurrent use by multiple goroutines.
+	Observer interface {
+		Observe(ctx context.Context, o Observation)
+	}
+)
+
+// ObserveTransport is an HTTP transport that notifies an Observer of every round trip.
+type observeTransport struct {
+	next     http.RoundTripper
+	observer Observer
+}
+
+// NewObserveTransport creates a new ObserveTransport configured with the options passed in input,
+// notably the observer and the next round tripper in the chain.
+// If not configured, round trips are logged with slog.Default.
+func NewObserveTransport(options ...ObserveTransportOption) http.RoundTripper {
+	t := &observeTransport{
+		next: http.DefaultTransport,
+	}
+
+	for _, opt := range options {
+		opt.apply(t)
+	}
+
+	if t.observer == nil {
+		t.observer = NewSlogObserver(nil)
+	}
+
+	return t
+}
+
+// RoundTrip makes ObserveTransport implement the RoundTripper interface.
+//
+// The observer is notified once the response body is fully read or closed,
+// or as soon as the round trip fails.
+func (t *observeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	ctx := req.Context()
+
+	o := Observation{
+		Method: req.Method,
+		Host:   req.URL.Host,
+	}
+	if req.ContentLength > 0 {
+		o.RequestSize = xunit.Byte(req.ContentLength)
+	}
+
+	var (
+		mu       sync.Mutex
+		attempts = 1
+	)
+	trace := xhttptrace.ContextClientTrace(ctx)
+	observeTrace := &xhttptrace.ClientTrace{}
+	if trace != nil {
+		*observeTrace = *trace
+	}
+	observeTrace.Retry = func(ri xhttptrace.RetryInfo) {
+		mu.Lock()
+		attempts = ri.RetryCount + 1
+		mu.Unlock()
+		if trace != nil && trace.Retry != nil {
+			trace.Retry(ri)
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req.WithContext(xhttptrace.WithClientTrace(ctx, observeTrace)))
+
+	mu.Lock()
+	o.Attempts = attempts
+	mu.Unlock()
+
+	if err != nil {
+		o.Err = err
+		o.Latency = time.Since(start)
+		t.observer.Observe(ctx, o)
+		return resp, err
+	}
+
+	o.StatusCode = resp.StatusCode
+
+	if resp.Body == nil || resp.Body == http.NoBody {
+		o.Latency = time.Since(start)
+		t.observer.Observe(ctx, o)
+		return resp, nil
+	}
+
+	resp.Body = &observedBody{
+		ReadCloser: resp.Body,
+		done: func(n int64) {
+			o.ResponseSize = xunit.Byte(n)
+			o.Latency = time.Since(start)
+			t.observer.Observe(ctx, o)
+		},
+	}
+
+	return resp, nil
+}
+
+// observedBody is a response body calling done with the number of bytes read
+// once fully read or closed.
+type observedBody struct {
+	io.ReadCloser
+
+	n    int64
+	once sync.Once
+	done func(n int64)
+}
+
+func (b *observedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b.n) })
+	return err
+}
+
+func (b *observedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	if err == io.EOF {
+		b.once.Do(func() { b.done(b.n) })
+	}
+	return n, err
+}
+
+// slogObserver is an Observer logging observations with a slog.Logger.
+type slogObserver struct {
+	logger *slog.Logger
+}
+
+// NewSlogObserver creates an Observer logging each observation with logger,
+// at error level if the round trip failed and at info level otherwise.
+// If logger is nil, slog.Default is used.
+func NewSlogObserver(logger *slog.Logger) Observer {
+	return &slogObserver{
+		logger: logger,
+	}
+}
+
+// Observe makes slogObserver implement the Observer interface.
+func (s *slogObserver) Observe(ctx context.Context, o Observation) {
+	logger := s.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", o.Method),
+		slog.String("host", o.Host),
+		slog.Int("status", o.StatusCode),
+		slog.Int("attempts", o.Attempts),
+		slog.Any("request_size", o.RequestSize),
+		slog.Any("response_size", o.ResponseSize),
+		slog.Duration("latency", o.Latency),
+	}
+
+	level := slog.LevelInfo
+	if o.Err != nil {
+		level = slog.LevelError
+		attrs = append(attrs, slog.String("error", o.Err.Error()))
+	}
+
+	logger.LogAttrs(ctx, level, "http round trip", attrs...)
+}
+
+type (
+	// ObserveTransportOption configures the ObserveTransport options
+	// when calling NewObserveTransport.
+	ObserveTransportOption interface {
+		apply(t *observeTransport)
+	}
+
+	funcObserveTransportOption struct {
+		fn func(*observeTransport)
+	}
+)
+
+func newFuncObserveTransportOption(fn func(*observeTransport)) funcObserveTransportOption {
+	return funcObserveTransportOption{
+		fn: fn,
+	}
+}
+
+func (o funcObserveTransportOption) apply(t *observeTransport) {
+	o.fn(t)
+}
+
+// ObserveTransportNextRoundTripper returns an ObserveTransportOption that configures the
+// next round tripper to call. If not used http.DefaultTransport will be used.
+func ObserveTransportNextRoundTripper(next http.RoundTripper) ObserveTransportOption {
+	if next == nil {
+		panic("next http.RoundTripper is nil")
+	}
+	return newFuncObserveTransportOption(func(t *observeTransport) {
+		t.next = next
+	})
+}
+
+// ObserveTransportObserver returns an ObserveTransportOption that configures the observer
+// notified of round trips. If not used, round trips are logged with slog.Default.
+func ObserveTransportObserver(observer Observer) ObserveTransportOption {
+	if observer == nil {
+		panic("observer is nil")
+	}
+	return newFuncObserveTransportOption(func(t *observeTransport) {
+		t.observer = observer
+	})
+}
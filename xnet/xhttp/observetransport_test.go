@@ -0,0 +1,245 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+type fakeObserver struct {
+	mu           sync.Mutex
+	observations []xhttp.Observation
+}
+
+func (o *fakeObserver) Observe(_ context.Context, obs xhttp.Observation) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.observations = append(o.observations, obs)
+}
+
+func TestObserveTransport_RoundTrip(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		next                 func() http.RoundTripper
+		body                 string
+		expectedStatusCode   int
+		expectedAttempts     int
+		expectedRequestSize  xunit.Byte
+		expectedResponseSize xunit.Byte
+		expectedErr          error
+	}{
+		{
+			name:                "round trip error",
+			next:                func() http.RoundTripper { return &fakeTransport{} },
+			body:                "payload",
+			expectedAttempts:    1,
+			expectedRequestSize: 7,
+			expectedErr:         errNoResponse,
+		},
+		{
+			name: "response without body",
+			next: func() http.RoundTripper {
+				return &fakeTransport{resps: []*http.Response{{StatusCode: http.StatusNoContent}}}
+			},
+			expectedStatusCode: http.StatusNoContent,
+			expectedAttempts:   1,
+		},
+		{
+			name: "response with body",
+			next: func() http.RoundTripper {
+				return &fakeTransport{resps: []*http.Response{{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader("response")),
+				}}}
+			},
+			body:                 "payload",
+			expectedStatusCode:   http.StatusOK,
+			expectedAttempts:     1,
+			expectedRequestSize:  7,
+			expectedResponseSize: 8,
+		},
+		{
+			name: "response after retries",
+			next: func() http.RoundTripper {
+				return xhttp.NewRetryTransport(
+					xhttp.RetryTransportInitialInterval(time.Millisecond),
+					xhttp.RetryTransportNextRoundTripper(&fakeTransport{resps: []*http.Response{
+						{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody},
+						{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody},
+						{StatusCode: http.StatusNoContent},
+					}}))
+			},
+			expectedStatusCode: http.StatusNoContent,
+			expectedAttempts:   3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			observer := &fakeObserver{}
+			transp := xhttp.NewObserveTransport(
+				xhttp.ObserveTransportNextRoundTripper(tc.next()),
+				xhttp.ObserveTransportObserver(observer))
+
+			req, err := http.NewRequest(http.MethodGet, "http://example.com", strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := transp.RoundTrip(req)
+			if err != tc.expectedErr {
+				t.Errorf("error mismatch: %v != %v", err, tc.expectedErr)
+			}
+			if resp != nil && resp.Body != nil {
+				io.ReadAll(resp.Body)
+				resp.Body.Close()
+			}
+
+			if len(observer.observations) != 1 {
+				t.Fatalf("expected 1 observation; got %d", len(observer.observations))
+			}
+
+			got := observer.observations[0]
+			if got.Method != http.MethodGet || got.Host != "example.com" {
+				t.Errorf("request mismatch: %s %s", got.Method, got.Host)
+			}
+			if got.StatusCode != tc.expectedStatusCode {
+				t.Errorf("status code mismatch: %d != %d", got.StatusCode, tc.expectedStatusCode)
+			}
+			if got.Attempts != tc.expectedAttempts {
+				t.Errorf("attempts mismatch: %d != %d", got.Attempts, tc.expectedAttempts)
+			}
+			if got.RequestSize != tc.expectedRequestSize {
+				t.Errorf("request size mismatch: %s != %s", got.RequestSize, tc.expectedRequestSize)
+			}
+			if got.ResponseSize != tc.expectedResponseSize {
+				t.Errorf("response size mismatch: %s != %s", got.ResponseSize, tc.expectedResponseSize)
+			}
+			if got.Err != tc.expectedErr {
+				t.Errorf("observed error mismatch: %v != %v", got.Err, tc.expectedErr)
+			}
+			if got.Latency <= 0 {
+				t.Errorf("expected positive latency; got %s", got.Latency)
+			}
+		})
+	}
+}
+
+func TestSlogObserver_Observe(t *testing.T) {
+	testCases := []struct {
+		name        string
+		observation xhttp.Observation
+		expected    string
+	}{
+		{
+			name: "success",
+			observation: xhttp.Observation{
+				Method:       http.MethodGet,
+				Host:         "example.com",
+				StatusCode:   http.StatusOK,
+				Attempts:     2,
+				RequestSize:  xunit.KB,
+				ResponseSize: 2 * xunit.KB,
+				Latency:      time.Second,
+			},
+			expected: `level=INFO msg="http round trip" method=GET host=example.com status=200 attempts=2 request_size=1KB response_size=2KB latency=1s` + "\n",
+		},
+		{
+			name: "failure",
+			observation: xhttp.Observation{
+				Method:   http.MethodGet,
+				Host:     "example.com",
+				Attempts: 1,
+				Latency:  time.Second,
+				Err:      errNoResponse,
+			},
+			expected: `level=ERROR msg="http round trip" method=GET host=example.com status=0 attempts=1 request_size=0B response_size=0B latency=1s error="no response"` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+				ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+					if a.Key == slog.TimeKey {
+						return slog.Attr{}
+					}
+					return a
+				},
+			}))
+
+			xhttp.NewSlogObserver(logger).Observe(context.Background(), tc.observation)
+
+			if got := buf.String(); got != tc.expected {
+				t.Errorf("expected %q; got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestObserveTransportNextRoundTripper(t *testing.T) {
+	testCases := []struct {
+		name  string
+		next  http.RoundTripper
+		panic bool
+	}{
+		{
+			name:  "panic",
+			next:  nil,
+			panic: true,
+		},
+		{
+			name:  "valid",
+			next:  &fakeTransport{},
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, func() xhttp.ObserveTransportOption {
+				return xhttp.ObserveTransportNextRoundTripper(tc.next)
+			})
+		})
+	}
+}
+
+func TestObserveTransportObserver(t *testing.T) {
+	testCases := []struct {
+		name     string
+		observer xhttp.Observer
+		panic    bool
+	}{
+		{
+			name:     "panic",
+			observer: nil,
+			panic:    true,
+		},
+		{
+			name:     "valid",
+			observer: &fakeObserver{},
+			panic:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, func() xhttp.ObserveTransportOption {
+				return xhttp.ObserveTransportObserver(tc.observer)
+			})
+		})
+	}
+}
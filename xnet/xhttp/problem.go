@@ -0,0 +1,198 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/jlourenc/xgo/xerrors"
+)
+
+const problemMediaType = "application/problem+json"
+
+// Problem is a problem details object describing an error of an HTTP API.
+// It implements the error interface so that it can be returned by handlers.
+// https://datatracker.ietf.org/doc/html/rfc9457
+type Problem struct {
+	// Type is a URI reference identifying the problem type. Empty means "about:blank".
+	Type string
+
+	// Title is a short human-readable summary of the problem type.
+	Title string
+
+	// Status is the HTTP status code of the response.
+	Status int
+
+	// Detail is a human-readable explanation specific to this occurrence of the problem.
+	Detail string
+
+	// Instance is a URI reference identifying the specific occurrence of the problem.
+	Instance string
+
+	// Extensions are additional members of the problem details object.
+	// Members named as the fields above are ignored.
+	Extensions map[string]any
+}
+
+// ProblemFromError returns the problem describing err. If err's tree contains a *Problem, a copy of it is
+// returned, its status defaulting to the HTTP status code attached to err (see xerrors.HTTPStatus).
+// Otherwise, the problem only has the HTTP status code attached to err along with its standard status
+// text as title: the error message itself is not exposed to avoid leaking internal details to clients.
+// If err is nil, ProblemFromError returns nil.
+func ProblemFromError(err error) *Problem {
+	if err == nil {
+		return nil
+	}
+
+	if p, ok := xerrors.AsType[*Problem](err); ok {
+		problem := *p
+		if problem.Status == 0 {
+			problem.Status = xerrors.HTTPStatus(err)
+		}
+		return &problem
+	}
+
+	status := xerrors.HTTPStatus(err)
+	return &Problem{
+		Title:  http.StatusText(status),
+		Status: status,
+	}
+}
+
+// WriteProblem replies to the request with the problem p as application/problem+json body.
+// Its status defaults to 500 and its title, if empty and its type is "about:blank", to the standard
+// status text. If p is nil, WriteProblem does nothing.
+func WriteProblem(w http.ResponseWriter, p *Problem) {
+	if p == nil {
+		return
+	}
+
+	problem := *p
+	if problem.Status == 0 {
+		problem.Status = http.StatusInternalServerError
+	}
+	if problem.Title == "" && (problem.Type == "" || problem.Type == "about:blank") {
+		problem.Title = http.StatusText(problem.Status)
+	}
+
+	b, err := json.Marshal(problem)
+	if err != nil {
+		// Extensions cannot be marshaled: fall back to the standard members.
+		problem.Extensions = nil
+		b, _ = json.Marshal(problem) //nolint:errcheck // standard members are always marshaled.
+	}
+
+	h := w.Header()
+	h.Del(HeaderContentLength)
+	h.Set(HeaderContentType, problemMediaType)
+	h.Set(HeaderXContentTypeOptions, "nosniff")
+	w.WriteHeader(problem.Status)
+	w.Write(append(b, '\n')) //nolint:errcheck // the response is already committed.
+}
+
+// WriteProblemError replies to the request with the problem describing err (see ProblemFromError).
+// It may be used with RecoverWriteFunc or TimeoutWriteFunc. If err is nil, WriteProblemError does nothing.
+func WriteProblemError(w http.ResponseWriter, _ *http.Request, err error) {
+	WriteProblem(w, ProblemFromError(err))
+}
+
+// Error makes Problem implement the error interface.
+func (p *Problem) Error() string {
+	msg := p.Title
+	if msg == "" {
+		msg = http.StatusText(p.Status)
+	}
+	if msg == "" {
+		msg = "status " + strconv.Itoa(p.Status)
+	}
+	if p.Detail != "" {
+		msg += ": " + p.Detail
+	}
+	return msg
+}
+
+// HTTPStatus returns the HTTP status code of the problem, as expected by xerrors.HTTPStatus.
+func (p *Problem) HTTPStatus() int {
+	if p.Status == 0 {
+		return http.StatusInternalServerError
+	}
+	return p.Status
+}
+
+// MarshalJSON makes Problem implement the json.Marshaler interface.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(p.Extensions)+5) //nolint:gomnd // standard members.
+	for k, v := range p.Extensions {
+		if !isProblemStandardMember(k) {
+			members[k] = v
+		}
+	}
+
+	if p.Type != "" {
+		members["type"] = p.Type
+	}
+	if p.Title != "" {
+		members["title"] = p.Title
+	}
+	if p.Status != 0 {
+		members["status"] = p.Status
+	}
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+
+	return json.Marshal(members)
+}
+
+// UnmarshalJSON makes Problem implement the json.Unmarshaler interface.
+// Standard members of unexpected types are ignored, as required by RFC 9457.
+func (p *Problem) UnmarshalJSON(b []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(b, &members); err != nil {
+		return err
+	}
+
+	*p = Problem{}
+	for k, raw := range members {
+		var target any
+		switch k {
+		case "type":
+			target = &p.Type
+		case "title":
+			target = &p.Title
+		case "status":
+			target = &p.Status
+		case "detail":
+			target = &p.Detail
+		case "instance":
+			target = &p.Instance
+		default:
+			var v any
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return err
+			}
+			if p.Extensions == nil {
+				p.Extensions = make(map[string]any)
+			}
+			p.Extensions[k] = v
+			continue
+		}
+		json.Unmarshal(raw, target) //nolint:errcheck // invalid standard members are ignored.
+	}
+	return nil
+}
+
+func isProblemStandardMember(name string) bool {
+	switch name {
+	case "type", "title", "status", "detail", "instance":
+		return true
+	default:
+		return false
+	}
+}
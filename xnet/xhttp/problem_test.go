@@ -0,0 +1,227 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/jlourenc/xgo/xerrors"
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestWriteProblem(t *testing.T) {
+	testCases := []struct {
+		name           string
+		problem        *xhttp.Problem
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "nil problem",
+			problem:        nil,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "",
+		},
+		{
+			name:           "default status and title",
+			problem:        &xhttp.Problem{},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"status":500,"title":"Internal Server Error"}` + "\n",
+		},
+		{
+			name: "typed problem without title",
+			problem: &xhttp.Problem{
+				Type:   "https://example.com/probs/out-of-credit",
+				Status: http.StatusForbidden,
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"status":403,"type":"https://example.com/probs/out-of-credit"}` + "\n",
+		},
+		{
+			name: "all members",
+			problem: &xhttp.Problem{
+				Type:       "https://example.com/probs/out-of-credit",
+				Title:      "You do not have enough credit.",
+				Status:     http.StatusForbidden,
+				Detail:     "Your current balance is 30, but that costs 50.",
+				Instance:   "/account/12345/msgs/abc",
+				Extensions: map[string]any{"balance": 30, "status": "ignored"},
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody: `{"balance":30,"detail":"Your current balance is 30, but that costs 50.",` +
+				`"instance":"/account/12345/msgs/abc","status":403,"title":"You do not have enough credit.",` +
+				`"type":"https://example.com/probs/out-of-credit"}` + "\n",
+		},
+		{
+			name: "unmarshalable extensions dropped",
+			problem: &xhttp.Problem{
+				Status:     http.StatusBadRequest,
+				Extensions: map[string]any{"fn": func() {}},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"status":400,"title":"Bad Request"}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			xhttp.WriteProblem(w, tc.problem)
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("status code mismatch: expected %d; got %d", tc.expectedStatus, w.Code)
+			}
+			if body := w.Body.String(); body != tc.expectedBody {
+				t.Errorf("body mismatch: expected %s; got %s", tc.expectedBody, body)
+			}
+			if tc.problem != nil {
+				if ct := w.Header().Get(xhttp.HeaderContentType); ct != "application/problem+json" {
+					t.Errorf("content type mismatch: expected %q; got %q", "application/problem+json", ct)
+				}
+			}
+		})
+	}
+}
+
+func TestProblemFromError(t *testing.T) {
+	problem := &xhttp.Problem{Title: "Out of credit", Detail: "Balance is 30."}
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected *xhttp.Problem
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: nil,
+		},
+		{
+			name:     "plain error",
+			err:      errors.New("secret internal details"),
+			expected: &xhttp.Problem{Title: "Internal Server Error", Status: http.StatusInternalServerError},
+		},
+		{
+			name:     "error with http status",
+			err:      xerrors.WithHTTPStatus(errors.New("secret internal details"), http.StatusNotFound),
+			expected: &xhttp.Problem{Title: "Not Found", Status: http.StatusNotFound},
+		},
+		{
+			name:     "wrapped problem with http status",
+			err:      xerrors.WithHTTPStatus(xerrors.Wrap(problem, "charging"), http.StatusForbidden),
+			expected: &xhttp.Problem{Title: "Out of credit", Status: http.StatusForbidden, Detail: "Balance is 30."},
+		},
+		{
+			name:     "problem with status",
+			err:      &xhttp.Problem{Title: "Conflict", Status: http.StatusConflict},
+			expected: &xhttp.Problem{Title: "Conflict", Status: http.StatusConflict},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xhttp.ProblemFromError(tc.err)
+
+			if !reflect.DeepEqual(tc.expected, got) {
+				t.Errorf("problem mismatch: expected %+v; got %+v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestWriteProblemError(t *testing.T) {
+	w := httptest.NewRecorder()
+	xhttp.WriteProblemError(w, httptest.NewRequest(http.MethodGet, "/", http.NoBody), xerrors.WithHTTPStatus(errors.New("boom"), http.StatusBadGateway))
+
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("status code mismatch: expected %d; got %d", http.StatusBadGateway, w.Code)
+	}
+	if expected, body := `{"status":502,"title":"Bad Gateway"}`+"\n", w.Body.String(); body != expected {
+		t.Errorf("body mismatch: expected %s; got %s", expected, body)
+	}
+}
+
+func TestProblem_Error(t *testing.T) {
+	testCases := []struct {
+		name     string
+		problem  *xhttp.Problem
+		expected string
+	}{
+		{
+			name:     "title and detail",
+			problem:  &xhttp.Problem{Title: "Out of credit", Detail: "Balance is 30."},
+			expected: "Out of credit: Balance is 30.",
+		},
+		{
+			name:     "status only",
+			problem:  &xhttp.Problem{Status: http.StatusNotFound},
+			expected: "Not Found",
+		},
+		{
+			name:     "unknown status",
+			problem:  &xhttp.Problem{Status: 599},
+			expected: "status 599",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.problem.Error(); got != tc.expected {
+				t.Errorf("error mismatch: expected %q; got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestProblem_UnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		name        string
+		data        string
+		expected    xhttp.Problem
+		expectedErr bool
+	}{
+		{
+			name: "all members",
+			data: `{"type":"https://example.com/probs/out-of-credit","title":"Out of credit","status":403,` +
+				`"detail":"Balance is 30.","instance":"/account/12345","balance":30}`,
+			expected: xhttp.Problem{
+				Type:       "https://example.com/probs/out-of-credit",
+				Title:      "Out of credit",
+				Status:     http.StatusForbidden,
+				Detail:     "Balance is 30.",
+				Instance:   "/account/12345",
+				Extensions: map[string]any{"balance": float64(30)},
+			},
+		},
+		{
+			name:     "invalid standard members ignored",
+			data:     `{"title":42,"status":"403"}`,
+			expected: xhttp.Problem{},
+		},
+		{
+			name:        "invalid json",
+			data:        `[]`,
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got xhttp.Problem
+			err := json.Unmarshal([]byte(tc.data), &got)
+
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("error mismatch: expected error %t; got %v", tc.expectedErr, err)
+			}
+			if !reflect.DeepEqual(tc.expected, got) {
+				t.Errorf("problem mismatch: expected %+v; got %+v", tc.expected, got)
+			}
+		})
+	}
+}
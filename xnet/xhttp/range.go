@@ -0,0 +1,216 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+const rangeUnitBytes = "bytes"
+
+var (
+	// ErrInvalidRange is returned by ParseRange when the range is malformed.
+	ErrInvalidRange = errors.New("invalid range")
+
+	// ErrRangeNotSatisfiable is returned by ParseRange when none of the ranges overlap the content.
+	ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+)
+
+// ByteRange is a range of bytes of a content.
+type ByteRange struct {
+	// Start is the offset of the first byte of the range.
+	Start int64
+
+	// Length is the number of bytes of the range.
+	Length int64
+}
+
+// ParseRange parses the value of a Range header, e.g. "bytes=0-499", and returns the ranges of a content
+// of the given size it selects. Ranges not overlapping the content are ignored, the other ones are truncated
+// to the content size. It returns ErrInvalidRange if s is malformed and ErrRangeNotSatisfiable if no range
+// overlaps the content. It returns nil if s is empty.
+// https://datatracker.ietf.org/doc/html/rfc9110#section-14.2
+func ParseRange(s string, size int64) ([]ByteRange, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	unit, specs, ok := strings.Cut(s, "=")
+	if !ok || !strings.EqualFold(strings.TrimSpace(unit), rangeUnitBytes) {
+		return nil, ErrInvalidRange
+	}
+
+	var (
+		ranges         []ByteRange
+		notSatisfiable bool
+	)
+	for _, spec := range strings.Split(specs, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		first, last, ok := strings.Cut(spec, "-")
+		if !ok {
+			return nil, ErrInvalidRange
+		}
+		first, last = strings.TrimSpace(first), strings.TrimSpace(last)
+
+		var r ByteRange
+		if first == "" {
+			// Suffix range: the last bytes of the content.
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || n < 0 {
+				return nil, ErrInvalidRange
+			}
+			if n == 0 || size == 0 {
+				notSatisfiable = true
+				continue
+			}
+			n = min(n, size)
+			r = ByteRange{Start: size - n, Length: n}
+		} else {
+			start, err := strconv.ParseInt(first, 10, 64)
+			if err != nil || start < 0 {
+				return nil, ErrInvalidRange
+			}
+
+			end := size - 1
+			if last != "" {
+				end, err = strconv.ParseInt(last, 10, 64)
+				if err != nil || end < start {
+					return nil, ErrInvalidRange
+				}
+				end = min(end, size-1)
+			}
+
+			if start >= size {
+				notSatisfiable = true
+				continue
+			}
+			r = ByteRange{Start: start, Length: end - start + 1}
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	if len(ranges) == 0 {
+		if notSatisfiable {
+			return nil, ErrRangeNotSatisfiable
+		}
+		return nil, ErrInvalidRange
+	}
+	return ranges, nil
+}
+
+// FormatContentRange returns the value of a Content-Range header for the range r of a content
+// of the given size, e.g. "bytes 0-499/1234". A negative size denotes an unknown size.
+// https://datatracker.ietf.org/doc/html/rfc9110#section-14.4
+func FormatContentRange(r ByteRange, size int64) string {
+	completeLength := "*"
+	if size >= 0 {
+		completeLength = strconv.FormatInt(size, 10)
+	}
+	return fmt.Sprintf("%s %d-%d/%s", rangeUnitBytes, r.Start, r.Start+r.Length-1, completeLength)
+}
+
+// ServeByteRanges replies to the request with the content, honoring the Range header of GET requests:
+// a single range is served as a 206 Partial Content response, several ranges as a multipart/byteranges one.
+// Malformed ranges are ignored and unsatisfiable ones are replied to with 416 Range Not Satisfiable.
+//
+// If the Content-Type header is not set, it is detected from the content. Unlike http.ServeContent,
+// conditional requests are not handled.
+func ServeByteRanges(w http.ResponseWriter, r *http.Request, content io.ReadSeeker) {
+	size, err := content.Seek(0, io.SeekEnd)
+	if err == nil {
+		_, err = content.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	h := w.Header()
+	if h.Get(HeaderContentType) == "" {
+		var buf [512]byte
+		n, _ := io.ReadFull(content, buf[:])
+		h.Set(HeaderContentType, http.DetectContentType(buf[:n]))
+		if _, err := content.Seek(0, io.SeekStart); err != nil {
+			WriteError(w, err)
+			return
+		}
+	}
+	h.Set(HeaderAcceptRanges, rangeUnitBytes)
+
+	var ranges []ByteRange
+	if r.Method == http.MethodGet {
+		ranges, err = ParseRange(r.Header.Get(HeaderRange), size)
+		if errors.Is(err, ErrRangeNotSatisfiable) {
+			h.Set(HeaderContentRange, fmt.Sprintf("%s */%d", rangeUnitBytes, size))
+			http.Error(w, err.Error(), http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+	}
+
+	var total int64
+	for _, br := range ranges {
+		total += br.Length
+	}
+	// Serve the whole content when it is cheaper than the requested ranges,
+	// e.g. with overlapping ranges.
+	if total > size {
+		ranges = nil
+	}
+
+	switch len(ranges) {
+	case 0:
+		h.Set(HeaderContentLength, strconv.FormatInt(size, 10))
+		w.WriteHeader(http.StatusOK)
+		if r.Method != http.MethodHead {
+			io.CopyN(w, content, size) //nolint:errcheck // the response is already committed.
+		}
+
+	case 1:
+		br := ranges[0]
+		if _, err := content.Seek(br.Start, io.SeekStart); err != nil {
+			WriteError(w, err)
+			return
+		}
+		h.Set(HeaderContentRange, FormatContentRange(br, size))
+		h.Set(HeaderContentLength, strconv.FormatInt(br.Length, 10))
+		w.WriteHeader(http.StatusPartialContent)
+		io.CopyN(w, content, br.Length) //nolint:errcheck // the response is already committed.
+
+	default:
+		contentType := h.Get(HeaderContentType)
+		mw := multipart.NewWriter(w)
+		h.Set(HeaderContentType, "multipart/byteranges; boundary="+mw.Boundary())
+		h.Del(HeaderContentLength)
+		w.WriteHeader(http.StatusPartialContent)
+
+		for _, br := range ranges {
+			part, err := mw.CreatePart(textproto.MIMEHeader{
+				HeaderContentType:  {contentType},
+				HeaderContentRange: {FormatContentRange(br, size)},
+			})
+			if err != nil {
+				return
+			}
+			if _, err := content.Seek(br.Start, io.SeekStart); err != nil {
+				return
+			}
+			if _, err := io.CopyN(part, content, br.Length); err != nil {
+				return
+			}
+		}
+		mw.Close()
+	}
+}
@@ -0,0 +1,298 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestParseRange(t *testing.T) {
+	testCases := []struct {
+		name          string
+		s             string
+		size          int64
+		expected      []xhttp.ByteRange
+		expectedError error
+	}{
+		{
+			name:     "empty",
+			s:        "",
+			size:     100,
+			expected: nil,
+		},
+		{
+			name:     "first bytes",
+			s:        "bytes=0-49",
+			size:     100,
+			expected: []xhttp.ByteRange{{Start: 0, Length: 50}},
+		},
+		{
+			name:     "open ended",
+			s:        "bytes=90-",
+			size:     100,
+			expected: []xhttp.ByteRange{{Start: 90, Length: 10}},
+		},
+		{
+			name:     "suffix",
+			s:        "bytes=-20",
+			size:     100,
+			expected: []xhttp.ByteRange{{Start: 80, Length: 20}},
+		},
+		{
+			name:     "suffix larger than size",
+			s:        "bytes=-200",
+			size:     100,
+			expected: []xhttp.ByteRange{{Start: 0, Length: 100}},
+		},
+		{
+			name:     "end truncated",
+			s:        "bytes=50-200",
+			size:     100,
+			expected: []xhttp.ByteRange{{Start: 50, Length: 50}},
+		},
+		{
+			name:     "multiple ranges",
+			s:        "Bytes= 0-9 , 20-29,-5",
+			size:     100,
+			expected: []xhttp.ByteRange{{Start: 0, Length: 10}, {Start: 20, Length: 10}, {Start: 95, Length: 5}},
+		},
+		{
+			name:     "unsatisfiable range ignored",
+			s:        "bytes=200-300, 0-9",
+			size:     100,
+			expected: []xhttp.ByteRange{{Start: 0, Length: 10}},
+		},
+		{
+			name:          "not satisfiable",
+			s:             "bytes=100-",
+			size:          100,
+			expectedError: xhttp.ErrRangeNotSatisfiable,
+		},
+		{
+			name:          "empty suffix not satisfiable",
+			s:             "bytes=-0",
+			size:          100,
+			expectedError: xhttp.ErrRangeNotSatisfiable,
+		},
+		{
+			name:          "invalid unit",
+			s:             "items=0-9",
+			size:          100,
+			expectedError: xhttp.ErrInvalidRange,
+		},
+		{
+			name:          "no range",
+			s:             "bytes=",
+			size:          100,
+			expectedError: xhttp.ErrInvalidRange,
+		},
+		{
+			name:          "missing dash",
+			s:             "bytes=10",
+			size:          100,
+			expectedError: xhttp.ErrInvalidRange,
+		},
+		{
+			name:          "end before start",
+			s:             "bytes=10-5",
+			size:          100,
+			expectedError: xhttp.ErrInvalidRange,
+		},
+		{
+			name:          "invalid number",
+			s:             "bytes=a-5",
+			size:          100,
+			expectedError: xhttp.ErrInvalidRange,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := xhttp.ParseRange(tc.s, tc.size)
+			if err != tc.expectedError {
+				t.Fatalf("error mismatch: expected %v; got %v", tc.expectedError, err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %v; got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestFormatContentRange(t *testing.T) {
+	testCases := []struct {
+		name     string
+		r        xhttp.ByteRange
+		size     int64
+		expected string
+	}{
+		{
+			name:     "known size",
+			r:        xhttp.ByteRange{Start: 0, Length: 500},
+			size:     1234,
+			expected: "bytes 0-499/1234",
+		},
+		{
+			name:     "unknown size",
+			r:        xhttp.ByteRange{Start: 500, Length: 10},
+			size:     -1,
+			expected: "bytes 500-509/*",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := xhttp.FormatContentRange(tc.r, tc.size); got != tc.expected {
+				t.Errorf("expected %q; got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestServeByteRanges(t *testing.T) {
+	const content = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+	testCases := []struct {
+		name                 string
+		method               string
+		rangeHeader          string
+		expectedStatus       int
+		expectedContentRange string
+		expectedBody         string
+	}{
+		{
+			name:           "no range",
+			rangeHeader:    "",
+			expectedStatus: http.StatusOK,
+			expectedBody:   content,
+		},
+		{
+			name:                 "single range",
+			rangeHeader:          "bytes=10-15",
+			expectedStatus:       http.StatusPartialContent,
+			expectedContentRange: "bytes 10-15/36",
+			expectedBody:         "abcdef",
+		},
+		{
+			name:           "invalid range ignored",
+			rangeHeader:    "bytes=abc",
+			expectedStatus: http.StatusOK,
+			expectedBody:   content,
+		},
+		{
+			name:           "overlapping ranges larger than content",
+			rangeHeader:    "bytes=0-,0-",
+			expectedStatus: http.StatusOK,
+			expectedBody:   content,
+		},
+		{
+			name:                 "not satisfiable",
+			rangeHeader:          "bytes=100-",
+			expectedStatus:       http.StatusRequestedRangeNotSatisfiable,
+			expectedContentRange: "bytes */36",
+			expectedBody:         "range not satisfiable\n",
+		},
+		{
+			name:           "head",
+			method:         http.MethodHead,
+			rangeHeader:    "bytes=10-15",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, "/", http.NoBody)
+			if tc.rangeHeader != "" {
+				req.Header.Set(xhttp.HeaderRange, tc.rangeHeader)
+			}
+
+			rec := httptest.NewRecorder()
+			xhttp.ServeByteRanges(rec, req, strings.NewReader(content))
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("status code mismatch: expected %d; got %d", tc.expectedStatus, rec.Code)
+			}
+			if got := rec.Header().Get(xhttp.HeaderContentRange); got != tc.expectedContentRange {
+				t.Errorf("content range mismatch: expected %q; got %q", tc.expectedContentRange, got)
+			}
+			if got := rec.Header().Get(xhttp.HeaderAcceptRanges); got != "bytes" {
+				t.Errorf("accept ranges mismatch: expected bytes; got %q", got)
+			}
+			if rec.Body.String() != tc.expectedBody {
+				t.Errorf("body mismatch: expected %q; got %q", tc.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestServeByteRanges_Multipart(t *testing.T) {
+	const content = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.Header.Set(xhttp.HeaderRange, "bytes=0-4, -3")
+
+	rec := httptest.NewRecorder()
+	rec.Header().Set(xhttp.HeaderContentType, "text/plain")
+	xhttp.ServeByteRanges(rec, req, strings.NewReader(content))
+
+	if rec.Code != http.StatusPartialContent {
+		t.Fatalf("status code mismatch: expected %d; got %d", http.StatusPartialContent, rec.Code)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(rec.Header().Get(xhttp.HeaderContentType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mediaType != "multipart/byteranges" {
+		t.Fatalf("content type mismatch: expected multipart/byteranges; got %s", mediaType)
+	}
+
+	expected := []struct {
+		contentRange string
+		body         string
+	}{
+		{contentRange: "bytes 0-4/36", body: "01234"},
+		{contentRange: "bytes 33-35/36", body: "xyz"},
+	}
+
+	mr := multipart.NewReader(rec.Body, params["boundary"])
+	for i, e := range expected {
+		part, err := mr.NextPart()
+		if err != nil {
+			t.Fatalf("part %d: unexpected error: %v", i, err)
+		}
+		if got := part.Header.Get(xhttp.HeaderContentType); got != "text/plain" {
+			t.Errorf("part %d: content type mismatch: expected text/plain; got %s", i, got)
+		}
+		if got := part.Header.Get(xhttp.HeaderContentRange); got != e.contentRange {
+			t.Errorf("part %d: content range mismatch: expected %s; got %s", i, e.contentRange, got)
+		}
+		b, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != e.body {
+			t.Errorf("part %d: body mismatch: expected %q; got %q", i, e.body, b)
+		}
+	}
+
+	if _, err := mr.NextPart(); err != io.EOF {
+		t.Errorf("expected io.EOF; got %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	rateLimitTransportDefaultLimit = 10.0
+	rateLimitTransportDefaultBurst = 1
+)
+
+// ErrRateLimited is returned by a RateLimitTransport configured to fail fast
+// when a request exceeds the rate limit.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// RateLimitTransport is an HTTP transport that limits the rate of outgoing requests
+// according to a token bucket algorithm.
+type rateLimitTransport struct {
+	next http.RoundTripper
+
+	limit    float64
+	burst    int
+	perHost  bool
+	failFast bool
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	sweepAt time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimitTransport creates a new RateLimitTransport configured with the options passed in input,
+// notably the rate limit, the burst size and the next round tripper in the chain.
+// By default, 10 requests per second with a burst of 1 are allowed across all hosts
+// and requests exceeding the limit wait for their turn.
+func NewRateLimitTransport(options ...RateLimitTransportOption) http.RoundTripper {
+	t := &rateLimitTransport{
+		limit:   rateLimitTransportDefaultLimit,
+		burst:   rateLimitTransportDefaultBurst,
+		next:    http.DefaultTransport,
+		buckets: make(map[string]*tokenBucket),
+	}
+
+	for _, opt := range options {
+		opt.apply(t)
+	}
+
+	return t
+}
+
+// RoundTrip makes RateLimitTransport implement the RoundTripper interface.
+//
+// Requests exceeding the rate limit either wait until they are allowed, or until the request
+// context is done in which case the context error is returned, or fail immediately with
+// ErrRateLimited if the transport is configured to fail fast.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := ""
+	if t.perHost {
+		key = req.URL.Host
+	}
+
+	wait, ok := t.reserve(key)
+	if !ok {
+		closeRequestBody(req)
+		return nil, ErrRateLimited
+	}
+
+	if wait > 0 {
+		ctx := req.Context()
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			t.release(key)
+			closeRequestBody(req)
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return t.next.RoundTrip(req)
+}
+
+// reserve takes a token from the bucket identified by key and returns the duration to wait
+// before the token is actually available. It returns false if the transport fails fast
+// and no token is available.
+func (t *rateLimitTransport) reserve(key string) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.sweep(now)
+
+	b, ok := t.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(t.burst), last: now}
+		t.buckets[key] = b
+	} else {
+		b.tokens = t.refill(b, now)
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+
+	if t.failFast {
+		return 0, false
+	}
+
+	b.tokens--
+	return time.Duration(-b.tokens / t.limit * float64(time.Second)), true
+}
+
+// refill returns the tokens available in b at now.
+func (t *rateLimitTransport) refill(b *tokenBucket, now time.Time) float64 {
+	return min(float64(t.burst), b.tokens+now.Sub(b.last).Seconds()*t.limit)
+}
+
+// sweep evicts the buckets which are full at now, as they are equivalent to missing ones,
+// so that buckets of idle hosts do not accumulate. It runs at most once per time needed
+// to refill an empty bucket. t.mu must be held.
+func (t *rateLimitTransport) sweep(now time.Time) {
+	if now.Before(t.sweepAt) {
+		return
+	}
+	for key, b := range t.buckets {
+		if t.refill(b, now) >= float64(t.burst) {
+			delete(t.buckets, key)
+		}
+	}
+	t.sweepAt = now.Add(time.Duration(float64(t.burst) / t.limit * float64(time.Second)))
+}
+
+// release gives back a token previously reserved from the bucket identified by key.
+func (t *rateLimitTransport) release(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if b, ok := t.buckets[key]; ok {
+		b.tokens = min(float64(t.burst), b.tokens+1)
+	}
+}
+
+type (
+	// RateLimitTransportOption configures the RateLimitTransport options
+	// when calling NewRateLimitTransport.
+	RateLimitTransportOption interface {
+		apply(t *rateLimitTransport)
+	}
+
+	funcRateLimitTransportOption struct {
+		fn func(*rateLimitTransport)
+	}
+)
+
+func newFuncRateLimitTransportOption(fn func(*rateLimitTransport)) funcRateLimitTransportOption {
+	return funcRateLimitTransportOption{
+		fn: fn,
+	}
+}
+
+func (o funcRateLimitTransportOption) apply(t *rateLimitTransport) {
+	o.fn(t)
+}
+
+// RateLimitTransportBurst returns a RateLimitTransportOption that configures the maximum
+// number of requests allowed at once, i.e. the size of the token bucket. Value must be >= 1,
+// otherwise it panics.
+func RateLimitTransportBurst(burst int) RateLimitTransportOption {
+	if burst < 1 {
+		panic("invalid burst value")
+	}
+	return newFuncRateLimitTransportOption(func(t *rateLimitTransport) {
+		t.burst = burst
+	})
+}
+
+// RateLimitTransportFailFast returns a RateLimitTransportOption that configures the transport
+// to fail with ErrRateLimited, instead of waiting, when a request exceeds the rate limit.
+func RateLimitTransportFailFast() RateLimitTransportOption {
+	return newFuncRateLimitTransportOption(func(t *rateLimitTransport) {
+		t.failFast = true
+	})
+}
+
+// RateLimitTransportLimit returns a RateLimitTransportOption that configures the number
+// of requests allowed per second on average. Value must be > 0, otherwise it panics.
+func RateLimitTransportLimit(requestsPerSecond float64) RateLimitTransportOption {
+	if requestsPerSecond <= 0 {
+		panic("invalid limit value")
+	}
+	return newFuncRateLimitTransportOption(func(t *rateLimitTransport) {
+		t.limit = requestsPerSecond
+	})
+}
+
+// RateLimitTransportNextRoundTripper returns a RateLimitTransportOption that configures the
+// next round tripper to call. If not used http.DefaultTransport will be used.
+func RateLimitTransportNextRoundTripper(next http.RoundTripper) RateLimitTransportOption {
+	if next == nil {
+		panic("next http.RoundTripper is nil")
+	}
+	return newFuncRateLimitTransportOption(func(t *rateLimitTransport) {
+		t.next = next
+	})
+}
+
+// RateLimitTransportPerHost returns a RateLimitTransportOption that configures the transport
+// to apply the rate limit to each host independently rather than to all requests.
+// The state of hosts which are idle long enough for their limit to be fully restored is discarded.
+func RateLimitTransportPerHost() RateLimitTransportOption {
+	return newFuncRateLimitTransportOption(func(t *rateLimitTransport) {
+		t.perHost = true
+	})
+}
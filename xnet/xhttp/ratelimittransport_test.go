@@ -0,0 +1,201 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestRateLimitTransport_RoundTrip(t *testing.T) {
+	u1, _ := url.Parse("http://example.com")
+	u2, _ := url.Parse("http://example.org")
+	resp := &http.Response{StatusCode: http.StatusNoContent}
+
+	testCases := []struct {
+		name        string
+		options     []xhttp.RateLimitTransportOption
+		urls        []*url.URL
+		pause       time.Duration
+		timeout     time.Duration
+		minDuration time.Duration
+		expectedErr error
+	}{
+		{
+			name:    "requests within burst do not wait",
+			options: []xhttp.RateLimitTransportOption{xhttp.RateLimitTransportLimit(1), xhttp.RateLimitTransportBurst(3)},
+			urls:    []*url.URL{u1, u1, u1},
+		},
+		{
+			name:        "requests exceeding burst wait",
+			options:     []xhttp.RateLimitTransportOption{xhttp.RateLimitTransportLimit(20), xhttp.RateLimitTransportBurst(1)},
+			urls:        []*url.URL{u1, u1, u1},
+			minDuration: 90 * time.Millisecond,
+		},
+		{
+			name:        "requests exceeding burst fail fast",
+			options:     []xhttp.RateLimitTransportOption{xhttp.RateLimitTransportLimit(1), xhttp.RateLimitTransportFailFast()},
+			urls:        []*url.URL{u1, u1},
+			expectedErr: xhttp.ErrRateLimited,
+		},
+		{
+			name:    "requests to different hosts do not share limit",
+			options: []xhttp.RateLimitTransportOption{xhttp.RateLimitTransportLimit(1), xhttp.RateLimitTransportFailFast(), xhttp.RateLimitTransportPerHost()},
+			urls:    []*url.URL{u1, u2},
+		},
+		{
+			name: "requests to idle hosts do not wait",
+			options: []xhttp.RateLimitTransportOption{
+				xhttp.RateLimitTransportLimit(20), xhttp.RateLimitTransportFailFast(), xhttp.RateLimitTransportPerHost(),
+			},
+			urls:  []*url.URL{u1, u2, u1, u2},
+			pause: 60 * time.Millisecond,
+		},
+		{
+			name:        "requests to different hosts share limit",
+			options:     []xhttp.RateLimitTransportOption{xhttp.RateLimitTransportLimit(1), xhttp.RateLimitTransportFailFast()},
+			urls:        []*url.URL{u1, u2},
+			expectedErr: xhttp.ErrRateLimited,
+		},
+		{
+			name:        "context done while waiting",
+			options:     []xhttp.RateLimitTransportOption{xhttp.RateLimitTransportLimit(1)},
+			urls:        []*url.URL{u1, u1},
+			timeout:     10 * time.Millisecond,
+			expectedErr: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			next := &fakeTransport{}
+			for range tc.urls {
+				next.resps = append(next.resps, resp)
+			}
+			rlTransp := xhttp.NewRateLimitTransport(append(tc.options, xhttp.RateLimitTransportNextRoundTripper(next))...)
+
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			var gotErr error
+			start := time.Now()
+			for i, u := range tc.urls {
+				if i > 0 {
+					time.Sleep(tc.pause)
+				}
+				req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), http.NoBody)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var gotResp *http.Response
+				gotResp, gotErr = rlTransp.RoundTrip(req)
+				if gotErr != nil {
+					break
+				}
+				if gotResp != resp {
+					t.Errorf("response mismatch: %v != %v", gotResp, resp)
+				}
+			}
+
+			if !errors.Is(gotErr, tc.expectedErr) {
+				t.Errorf("error mismatch: %v != %v", gotErr, tc.expectedErr)
+			}
+			if elapsed := time.Since(start); elapsed < tc.minDuration {
+				t.Errorf("expected round trips to take at least %s; took %s", tc.minDuration, elapsed)
+			}
+		})
+	}
+}
+
+func TestRateLimitTransportBurst(t *testing.T) {
+	testCases := []struct {
+		name  string
+		burst int
+		panic bool
+	}{
+		{
+			name:  "panic",
+			burst: 0,
+			panic: true,
+		},
+		{
+			name:  "valid",
+			burst: 1,
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, func() xhttp.RateLimitTransportOption {
+				return xhttp.RateLimitTransportBurst(tc.burst)
+			})
+		})
+	}
+}
+
+func TestRateLimitTransportLimit(t *testing.T) {
+	testCases := []struct {
+		name  string
+		limit float64
+		panic bool
+	}{
+		{
+			name:  "panic",
+			limit: 0,
+			panic: true,
+		},
+		{
+			name:  "valid",
+			limit: 0.5,
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, func() xhttp.RateLimitTransportOption {
+				return xhttp.RateLimitTransportLimit(tc.limit)
+			})
+		})
+	}
+}
+
+func TestRateLimitTransportNextRoundTripper(t *testing.T) {
+	testCases := []struct {
+		name  string
+		next  http.RoundTripper
+		panic bool
+	}{
+		{
+			name:  "panic",
+			next:  nil,
+			panic: true,
+		},
+		{
+			name:  "valid",
+			next:  &fakeTransport{},
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, func() xhttp.RateLimitTransportOption {
+				return xhttp.RateLimitTransportNextRoundTripper(tc.next)
+			})
+		})
+	}
+}
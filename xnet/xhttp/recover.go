@@ -0,0 +1,124 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/jlourenc/xgo/xerrors"
+)
+
+type recoverer struct {
+	log   func(r *http.Request, err error)
+	write func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// Recover returns a Middleware that recovers from panics of the next handler, configured with the
+// options passed in input. The recovered value is converted to an error carrying a stack trace, if
+// enabled (see xerrors.EnableStackTrace), that is logged and written to the response. By default, the
+// error is logged with slog.Default, along with the request ID if any (see RequestID), and a 500
+// Internal Server Error is replied with WriteError.
+//
+// Panics with http.ErrAbortHandler are not recovered, so that the server aborts the response.
+func Recover(options ...RecoverOption) Middleware {
+	rec := &recoverer{
+		log:   logPanic,
+		write: writePanic,
+	}
+
+	for _, opt := range options {
+		opt.apply(rec)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+
+				var err error
+				if e, ok := v.(error); ok {
+					if errors.Is(e, http.ErrAbortHandler) {
+						panic(v)
+					}
+					err = xerrors.Wrap(e, "panic recovered")
+				} else {
+					err = xerrors.Newf("panic recovered: %v", v)
+				}
+				err = xerrors.WithHTTPStatus(err, http.StatusInternalServerError)
+
+				rec.log(r, err)
+				rec.write(w, r, err)
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func logPanic(r *http.Request, err error) {
+	attrs := []slog.Attr{
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.String("error", fmt.Sprintf("%+v", err)),
+	}
+	if id := RequestIDFromContext(r.Context()); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+
+	slog.Default().LogAttrs(r.Context(), slog.LevelError, "http handler panic", attrs...)
+}
+
+func writePanic(w http.ResponseWriter, _ *http.Request, err error) {
+	WriteError(w, err)
+}
+
+type (
+	// RecoverOption configures the Recover middleware options when calling Recover.
+	RecoverOption interface {
+		apply(rec *recoverer)
+	}
+
+	funcRecoverOption struct {
+		fn func(*recoverer)
+	}
+)
+
+func newFuncRecoverOption(fn func(*recoverer)) funcRecoverOption {
+	return funcRecoverOption{
+		fn: fn,
+	}
+}
+
+func (o funcRecoverOption) apply(rec *recoverer) {
+	o.fn(rec)
+}
+
+// RecoverLogFunc returns a RecoverOption that configures the function logging recovered panics.
+// Value must not be nil, otherwise it panics.
+func RecoverLogFunc(log func(r *http.Request, err error)) RecoverOption {
+	if log == nil {
+		panic("log function is nil")
+	}
+	return newFuncRecoverOption(func(rec *recoverer) {
+		rec.log = log
+	})
+}
+
+// RecoverWriteFunc returns a RecoverOption that configures the function replying to requests
+// whose handler panicked, e.g. to write a problem details body. The error carries the HTTP status
+// code 500 (see xerrors.HTTPStatus). Value must not be nil, otherwise it panics.
+func RecoverWriteFunc(write func(w http.ResponseWriter, r *http.Request, err error)) RecoverOption {
+	if write == nil {
+		panic("write function is nil")
+	}
+	return newFuncRecoverOption(func(rec *recoverer) {
+		rec.write = write
+	})
+}
@@ -0,0 +1,149 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jlourenc/xgo/xerrors"
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestRecover(t *testing.T) {
+	errPanic := errors.New("panic error")
+
+	testCases := []struct {
+		name         string
+		panicValue   any
+		expectedCode int
+		expectedErr  string
+	}{
+		{
+			name:         "no panic",
+			panicValue:   nil,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "panic with error",
+			panicValue:   errPanic,
+			expectedCode: http.StatusInternalServerError,
+			expectedErr:  "panic recovered: panic error",
+		},
+		{
+			name:         "panic with value",
+			panicValue:   42,
+			expectedCode: http.StatusInternalServerError,
+			expectedErr:  "panic recovered: 42",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			xerrors.EnableStackTrace(true)
+			defer xerrors.EnableStackTrace(false)
+
+			var logged error
+			h := xhttp.Recover(xhttp.RecoverLogFunc(func(_ *http.Request, err error) {
+				logged = err
+			})).ThenFunc(func(http.ResponseWriter, *http.Request) {
+				if tc.panicValue != nil {
+					panic(tc.panicValue)
+				}
+			})
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+			if rec.Code != tc.expectedCode {
+				t.Errorf("status code mismatch: expected %d; got %d", tc.expectedCode, rec.Code)
+			}
+
+			if tc.expectedErr == "" {
+				if logged != nil {
+					t.Errorf("expected no error; got %v", logged)
+				}
+				return
+			}
+
+			if logged == nil || logged.Error() != tc.expectedErr {
+				t.Fatalf("expected error %q; got %v", tc.expectedErr, logged)
+			}
+			if err, ok := tc.panicValue.(error); ok && !errors.Is(logged, err) {
+				t.Errorf("expected %v in error chain", err)
+			}
+			if xerrors.HTTPStatus(logged) != http.StatusInternalServerError {
+				t.Errorf("expected error to carry status %d; got %d", http.StatusInternalServerError, xerrors.HTTPStatus(logged))
+			}
+			if st := logged.(xerrors.StackTracer).StackTrace(); !strings.Contains(fmt.Sprintf("%+v", st), "recover_test.go") {
+				t.Errorf("expected stack trace to contain the panicking handler; got %+v", st)
+			}
+		})
+	}
+}
+
+func TestRecover_DefaultLog(t *testing.T) {
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	h := xhttp.Chain(xhttp.RequestID(xhttp.RequestIDGenerator(func() string { return "id" })), xhttp.Recover()).
+		ThenFunc(func(http.ResponseWriter, *http.Request) {
+			panic("boom")
+		})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/path", http.NoBody))
+
+	if rec.Code != http.StatusInternalServerError || rec.Body.String() != "Internal Server Error\n" {
+		t.Errorf("unexpected response: %d %q", rec.Code, rec.Body.String())
+	}
+	if got := buf.String(); !strings.Contains(got, `msg="http handler panic" method=GET path=/path error="panic recovered: boom" request_id=id`) {
+		t.Errorf("unexpected log: %s", got)
+	}
+}
+
+func TestRecover_AbortHandler(t *testing.T) {
+	h := xhttp.Recover().ThenFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("expected http.ErrAbortHandler panic; got %v", r)
+		}
+	}()
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+}
+
+func TestRecoverOptions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func() xhttp.RecoverOption
+		panic bool
+	}{
+		{name: "log func panic", fn: func() xhttp.RecoverOption { return xhttp.RecoverLogFunc(nil) }, panic: true},
+		{name: "log func valid", fn: func() xhttp.RecoverOption { return xhttp.RecoverLogFunc(func(*http.Request, error) {}) }},
+		{name: "write func panic", fn: func() xhttp.RecoverOption { return xhttp.RecoverWriteFunc(nil) }, panic: true},
+		{
+			name: "write func valid",
+			fn: func() xhttp.RecoverOption {
+				return xhttp.RecoverWriteFunc(func(http.ResponseWriter, *http.Request, error) {})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, tc.fn)
+		})
+	}
+}
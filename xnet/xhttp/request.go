@@ -3,7 +3,11 @@
 // license that can be found in the LICENSE file.
 package xhttp
 
-import "net/http"
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
 
 func isRequestIdempotent(req *http.Request) bool {
 	switch req.Method {
@@ -19,3 +23,23 @@ func isRequestIdempotent(req *http.Request) bool {
 func isRequestRewindable(req *http.Request) bool {
 	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
 }
+
+// closeRequestBody closes the body of req, if any, as a RoundTripper must do
+// even when it returns an error without calling the next round tripper.
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// newIdempotencyKey returns a random (version 4) UUID to be used as idempotency key.
+func newIdempotencyKey() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err) // crypto/rand never fails on supported platforms.
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant 10
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,146 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+const requestIDMaxLength = 128
+
+type (
+	requestID struct {
+		header   string
+		generate func() string
+	}
+
+	requestIDContextKey struct{}
+)
+
+// RequestID returns a Middleware that identifies each request with an ID, configured with the
+// options passed in input. The ID is read from the X-Request-Id request header if valid, i.e. made of
+// at most 128 printable ASCII characters, or else from the trace ID of a valid W3C Traceparent request
+// header, or else generated as 32 random lowercase hexadecimal characters, compatible with a trace ID.
+//
+// The ID is stored in the request context, see RequestIDFromContext, and set in the X-Request-Id
+// response header.
+func RequestID(options ...RequestIDOption) Middleware {
+	rid := &requestID{
+		header:   HeaderXRequestID,
+		generate: newRequestID,
+	}
+
+	for _, opt := range options {
+		opt.apply(rid)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(rid.header)
+			if !isRequestIDValid(id) {
+				id = traceIDFromTraceparent(r.Header.Get(HeaderTraceparent))
+			}
+			if id == "" {
+				id = rid.generate()
+			}
+
+			w.Header().Set(rid.header, id)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDContextKey{}, id)))
+		})
+	}
+}
+
+// RequestIDFromContext returns the request ID stored in ctx by the RequestID middleware.
+// If none, it returns an empty string.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string) //nolint:errcheck,revive // empty string returned if none.
+	return id
+}
+
+func isRequestIDValid(id string) bool {
+	if id == "" || len(id) > requestIDMaxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// traceIDFromTraceparent returns the trace ID of a traceparent header value, or an empty string
+// if the value is invalid. See https://www.w3.org/TR/trace-context/#traceparent-header-field-values.
+func traceIDFromTraceparent(traceparent string) string {
+	parts := strings.Split(traceparent, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 {
+		return ""
+	}
+
+	traceID := parts[1]
+	if traceID == strings.Repeat("0", 32) {
+		return ""
+	}
+	for i := 0; i < len(traceID); i++ {
+		if !('0' <= traceID[i] && traceID[i] <= '9' || 'a' <= traceID[i] && traceID[i] <= 'f') {
+			return ""
+		}
+	}
+	return traceID
+}
+
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err) // crypto/rand never fails on supported platforms.
+	}
+	return hex.EncodeToString(b[:])
+}
+
+type (
+	// RequestIDOption configures the RequestID middleware options when calling RequestID.
+	RequestIDOption interface {
+		apply(rid *requestID)
+	}
+
+	funcRequestIDOption struct {
+		fn func(*requestID)
+	}
+)
+
+func newFuncRequestIDOption(fn func(*requestID)) funcRequestIDOption {
+	return funcRequestIDOption{
+		fn: fn,
+	}
+}
+
+func (o funcRequestIDOption) apply(rid *requestID) {
+	o.fn(rid)
+}
+
+// RequestIDGenerator returns a RequestIDOption that configures the function generating request IDs.
+// Value must not be nil, otherwise it panics.
+func RequestIDGenerator(generate func() string) RequestIDOption {
+	if generate == nil {
+		panic("request ID generator is nil")
+	}
+	return newFuncRequestIDOption(func(rid *requestID) {
+		rid.generate = generate
+	})
+}
+
+// RequestIDHeader returns a RequestIDOption that configures the request and response header
+// carrying the request ID, instead of X-Request-Id. Value must not be empty, otherwise it panics.
+func RequestIDHeader(header string) RequestIDOption {
+	if header == "" {
+		panic("request ID header is empty")
+	}
+	return newFuncRequestIDOption(func(rid *requestID) {
+		rid.header = header
+	})
+}
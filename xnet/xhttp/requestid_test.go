@@ -0,0 +1,113 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestRequestID(t *testing.T) {
+	generated := regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+	testCases := []struct {
+		name           string
+		options        []xhttp.RequestIDOption
+		reqHeader      http.Header
+		expectedHeader string
+		expected       string
+	}{
+		{
+			name:           "generated",
+			expectedHeader: xhttp.HeaderXRequestID,
+		},
+		{
+			name:           "from request header",
+			reqHeader:      http.Header{xhttp.HeaderXRequestID: {"abc-123"}},
+			expectedHeader: xhttp.HeaderXRequestID,
+			expected:       "abc-123",
+		},
+		{
+			name:           "invalid request header ignored",
+			reqHeader:      http.Header{xhttp.HeaderXRequestID: {strings.Repeat("a", 129)}},
+			expectedHeader: xhttp.HeaderXRequestID,
+		},
+		{
+			name:           "from traceparent header",
+			reqHeader:      http.Header{xhttp.HeaderTraceparent: {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}},
+			expectedHeader: xhttp.HeaderXRequestID,
+			expected:       "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name:           "invalid traceparent header ignored",
+			reqHeader:      http.Header{xhttp.HeaderTraceparent: {"00-00000000000000000000000000000000-00f067aa0ba902b7-01"}},
+			expectedHeader: xhttp.HeaderXRequestID,
+		},
+		{
+			name:           "custom header and generator",
+			options:        []xhttp.RequestIDOption{xhttp.RequestIDHeader("X-Correlation-Id"), xhttp.RequestIDGenerator(func() string { return "id" })},
+			reqHeader:      http.Header{xhttp.HeaderXRequestID: {"abc-123"}},
+			expectedHeader: "X-Correlation-Id",
+			expected:       "id",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got string
+			h := xhttp.RequestID(tc.options...).ThenFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got = xhttp.RequestIDFromContext(r.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			for k, v := range tc.reqHeader {
+				req.Header[k] = v
+			}
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if tc.expected != "" && got != tc.expected {
+				t.Errorf("expected request ID %q; got %q", tc.expected, got)
+			}
+			if tc.expected == "" && !generated.MatchString(got) {
+				t.Errorf("expected generated request ID; got %q", got)
+			}
+			if h := rec.Header().Get(tc.expectedHeader); h != got {
+				t.Errorf("expected response header %s %q; got %q", tc.expectedHeader, got, h)
+			}
+		})
+	}
+}
+
+func TestRequestIDFromContext(t *testing.T) {
+	if got := xhttp.RequestIDFromContext(context.Background()); got != "" {
+		t.Errorf("expected no request ID; got %q", got)
+	}
+}
+
+func TestRequestIDOptions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func() xhttp.RequestIDOption
+		panic bool
+	}{
+		{name: "generator panic", fn: func() xhttp.RequestIDOption { return xhttp.RequestIDGenerator(nil) }, panic: true},
+		{name: "generator valid", fn: func() xhttp.RequestIDOption { return xhttp.RequestIDGenerator(func() string { return "" }) }},
+		{name: "header panic", fn: func() xhttp.RequestIDOption { return xhttp.RequestIDHeader("") }, panic: true},
+		{name: "header valid", fn: func() xhttp.RequestIDOption { return xhttp.RequestIDHeader("X-Correlation-Id") }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, tc.fn)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// statusWriter is a http.ResponseWriter recording the status code and the number
+// of bytes written. Flushing and hijacking are passed through to the wrapped writer.
+type statusWriter struct {
+	http.ResponseWriter
+
+	status      int
+	written     int64
+	wroteHeader bool
+}
+
+// Flush makes statusWriter implement the http.Flusher interface.
+func (w *statusWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush() //nolint:errcheck // best effort as per http.Flusher.
+}
+
+// Hijack makes statusWriter implement the http.Hijacker interface.
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Status returns the status code written, http.StatusOK if none.
+func (w *statusWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Unwrap returns the wrapped writer, as expected by http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Write makes statusWriter implement the http.ResponseWriter interface.
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.written += int64(n)
+	return n, err
+}
+
+// WriteHeader makes statusWriter implement the http.ResponseWriter interface.
+func (w *statusWriter) WriteHeader(code int) {
+	// Informational responses may precede the final one.
+	if !w.wroteHeader && (code < http.StatusContinue || code >= http.StatusOK || code == http.StatusSwitchingProtocols) {
+		w.status = code
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
@@ -0,0 +1,59 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	errHeaderNoRetryAfter      = errors.New("no retry-after header")
+	errHeaderInvalidRetryAfter = errors.New("invalid retry-after header")
+)
+
+// ParseRetryAfter parses the Retry-After header and returns the duration to wait before making
+// a follow-up request, whether it is expressed as a number of seconds or as a date. Past dates
+// result in a zero duration. An error is returned if the header is missing or invalid.
+// https://datatracker.ietf.org/doc/html/rfc9110#section-10.2.3
+func ParseRetryAfter(headers http.Header) (time.Duration, error) {
+	retryAfter := headers.Get(HeaderRetryAfter)
+	if retryAfter == "" {
+		return 0, errHeaderNoRetryAfter
+	}
+
+	if secs, err := strconv.Atoi(retryAfter); err == nil {
+		// Large values are capped rather than overflowing.
+		return max(0, time.Duration(min(int64(secs), math.MaxInt64/int64(time.Second)))*time.Second), nil
+	}
+
+	if date, err := http.ParseTime(retryAfter); err == nil {
+		return max(0, time.Until(date)), nil
+	}
+
+	return 0, errHeaderInvalidRetryAfter
+}
+
+// SetRetryAfter sets the Retry-After header of w to d, rounded up to the second.
+// Negative durations are set as 0.
+func SetRetryAfter(w http.ResponseWriter, d time.Duration) {
+	secs := (max(0, d) + time.Second - 1) / time.Second
+	w.Header().Set(HeaderRetryAfter, strconv.FormatInt(int64(secs), 10))
+}
+
+// SetRetryAfterTime sets the Retry-After header of w to the HTTP date t.
+func SetRetryAfterTime(w http.ResponseWriter, t time.Time) {
+	w.Header().Set(HeaderRetryAfter, t.UTC().Format(http.TimeFormat))
+}
+
+// WriteStatusRetryAfter sets the Retry-After header of w to d, rounded up to the second,
+// and sends an HTTP response header with the provided status code, e.g. 429 Too Many Requests
+// or 503 Service Unavailable.
+func WriteStatusRetryAfter(w http.ResponseWriter, code int, d time.Duration) {
+	SetRetryAfter(w, d)
+	w.WriteHeader(code)
+}
@@ -0,0 +1,135 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	testCases := []struct {
+		name             string
+		headers          http.Header
+		expectedDuration time.Duration
+		expectedErr      bool
+	}{
+		{
+			name:        "undefined",
+			headers:     nil,
+			expectedErr: true,
+		},
+		{
+			name:        "invalid",
+			headers:     http.Header{xhttp.HeaderRetryAfter: {"soon"}},
+			expectedErr: true,
+		},
+		{
+			name:             "seconds",
+			headers:          http.Header{xhttp.HeaderRetryAfter: {"120"}},
+			expectedDuration: 2 * time.Minute,
+		},
+		{
+			name:             "negative seconds",
+			headers:          http.Header{xhttp.HeaderRetryAfter: {"-1"}},
+			expectedDuration: 0,
+		},
+		{
+			name:             "huge seconds",
+			headers:          http.Header{xhttp.HeaderRetryAfter: {"9223372036854775807"}},
+			expectedDuration: math.MaxInt64 / time.Second * time.Second,
+		},
+		{
+			name:             "past date",
+			headers:          http.Header{xhttp.HeaderRetryAfter: {"Sun, 10 Jul 2016 21:12:00 GMT"}},
+			expectedDuration: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := xhttp.ParseRetryAfter(tc.headers)
+
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("error mismatch: expected error %t; got %v", tc.expectedErr, err)
+			}
+			if d != tc.expectedDuration {
+				t.Errorf("duration mismatch: expected %s; got %s", tc.expectedDuration, d)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter_FutureDate(t *testing.T) {
+	headers := http.Header{xhttp.HeaderRetryAfter: {time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)}}
+
+	d, err := xhttp.ParseRetryAfter(headers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d <= 59*time.Minute || d > time.Hour {
+		t.Errorf("duration mismatch: expected about %s; got %s", time.Hour, d)
+	}
+}
+
+func TestSetRetryAfter(t *testing.T) {
+	testCases := []struct {
+		name     string
+		d        time.Duration
+		expected string
+	}{
+		{
+			name:     "negative",
+			d:        -time.Second,
+			expected: "0",
+		},
+		{
+			name:     "whole seconds",
+			d:        2 * time.Second,
+			expected: "2",
+		},
+		{
+			name:     "rounded up",
+			d:        1500 * time.Millisecond,
+			expected: "2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			xhttp.SetRetryAfter(w, tc.d)
+
+			if got := w.Header().Get(xhttp.HeaderRetryAfter); got != tc.expected {
+				t.Errorf("header mismatch: expected %q; got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestSetRetryAfterTime(t *testing.T) {
+	w := httptest.NewRecorder()
+	xhttp.SetRetryAfterTime(w, time.Date(2016, time.July, 10, 23, 12, 0, 0, time.FixedZone("CEST", 2*60*60)))
+
+	if expected, got := "Sun, 10 Jul 2016 21:12:00 GMT", w.Header().Get(xhttp.HeaderRetryAfter); got != expected {
+		t.Errorf("header mismatch: expected %q; got %q", expected, got)
+	}
+}
+
+func TestWriteStatusRetryAfter(t *testing.T) {
+	w := httptest.NewRecorder()
+	xhttp.WriteStatusRetryAfter(w, http.StatusTooManyRequests, 30*time.Second)
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("status code mismatch: expected %d; got %d", http.StatusTooManyRequests, w.Code)
+	}
+	if expected, got := "30", w.Header().Get(xhttp.HeaderRetryAfter); got != expected {
+		t.Errorf("header mismatch: expected %q; got %q", expected, got)
+	}
+}
@@ -6,7 +6,6 @@ package xhttp
 import (
 	"math/rand"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/jlourenc/xgo/xio"
@@ -18,6 +17,7 @@ const (
 	retryTransportDefaultIntervalMultiplier = 1.5
 	retryTransportDefaultJitterFactor       = 0.2
 	retryTransportDefaultMaxInterval        = 30 * time.Second
+	retryTransportDefaultMaxRetries         = -1 // unlimited
 )
 
 // RetryTransport is an HTTP transport that implements HTTP retries according to
@@ -30,8 +30,29 @@ type retryTransport struct {
 	intervalMultiplier float64
 	jitterFactor       float64
 	maxInterval        time.Duration
+
+	// retry budget
+	maxRetries     int
+	maxElapsedTime time.Duration
+
+	// retry policy
+	retryPolicy      RetryPolicy
+	retryStatusCodes map[int]struct{}
+	retryOnError     func(error) bool
+
+	// Retry-After handling
+	ignoreRetryAfter bool
+	maxRetryAfter    time.Duration
+
+	// idempotency
+	idempotencyKey bool
 }
 
+// RetryPolicy reports whether a round trip should be retried given its outcome,
+// either a response or an error, and the number of attempts made so far, starting at 1.
+// It is only consulted for idempotent requests whose body can be rewound.
+type RetryPolicy func(resp *http.Response, err error, attempt int) bool
+
 // NewRetryTransport creates a new RetryTransport configured with the options passed in input,
 // notably the backoff policy and the next round tripper in the chain.
 func NewRetryTransport(options ...RetryTransportOption) http.RoundTripper {
@@ -40,6 +61,7 @@ func NewRetryTransport(options ...RetryTransportOption) http.RoundTripper {
 		intervalMultiplier: retryTransportDefaultIntervalMultiplier,
 		jitterFactor:       retryTransportDefaultJitterFactor,
 		maxInterval:        retryTransportDefaultMaxInterval,
+		maxRetries:         retryTransportDefaultMaxRetries,
 		next:               http.DefaultTransport,
 	}
 
@@ -52,14 +74,25 @@ func NewRetryTransport(options ...RetryTransportOption) http.RoundTripper {
 
 // RoundTrip makes RetryTransport implement the RoundTripper interface.
 //
-// It retries retryable (as defined by their status code) responses of idempotent requests,
+// It retries retryable (as defined by the retry policy) responses of idempotent requests,
 // following a backoff policy or respecting Retry-After response headers.
+// By default, responses are retryable based on their status code and errors are not retryable,
+// unless configured with RetryTransportRetryOnError.
+// Retries stop when the retry budget, if any, is exhausted or when the request context is done,
+// in which case the last response or error is returned.
 //
 // See HTTP semantics defined in: https://datatracker.ietf.org/doc/html/rfc9110.
 func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	ctx := req.Context()
+	start := time.Now()
+
+	if t.idempotencyKey && (req.Method == http.MethodPost || req.Method == http.MethodPatch) &&
+		req.Header.Get(HeaderIdempotencyKey) == "" && req.Header.Get(HeaderXIdempotencyKey) == "" {
+		req = req.Clone(ctx)
+		req.Header.Set(HeaderIdempotencyKey, newIdempotencyKey())
+	}
+
 	reqRetryable := isRequestIdempotent(req) && isRequestRewindable(req)
-	retryCount := 0
 	retryInterval := t.initialInterval
 
 	trace := xhttptrace.ContextClientTrace(ctx)
@@ -67,66 +100,132 @@ func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		trace = &xhttptrace.ClientTrace{}
 	}
 
-	for {
-		resp, err := t.next.RoundTrip(req)
-		if err != nil {
+	for attempt := 1; ; attempt++ {
+		resp, err := t.attempt(req, attempt, trace)
+
+		if !reqRetryable || !t.shouldRetry(resp, err, attempt) || ctx.Err() != nil {
 			return resp, err
 		}
 
-		if !reqRetryable || !isResponseRetryable(resp) {
-			return resp, nil
+		if t.maxRetries >= 0 && attempt > t.maxRetries {
+			return resp, err
 		}
 
 		// Clone request if body is rewindable.
 		if req.GetBody != nil {
-			body, err := req.GetBody()
-			if err != nil {
-				return resp, nil //nolint:nilerr // return last response
+			body, gerr := req.GetBody()
+			if gerr != nil {
+				return resp, err // return last response
 			}
 			req = req.Clone(ctx)
 			req.Body = body
 		}
 
-		timer := time.NewTimer(computeWaitDuration(retryInterval, t.jitterFactor, resp.Header))
+		var (
+			header     http.Header
+			statusCode int
+		)
+		if resp != nil {
+			header = resp.Header
+			statusCode = resp.StatusCode
+		}
+
+		wait := t.computeWaitDuration(retryInterval, header)
+		if t.maxElapsedTime > 0 && time.Since(start)+wait > t.maxElapsedTime {
+			return resp, err
+		}
+
+		if trace.BackoffWait != nil {
+			trace.BackoffWait(xhttptrace.BackoffWaitInfo{
+				Attempt:  attempt,
+				Duration: wait,
+			})
+		}
+
+		timer := time.NewTimer(wait)
 		select {
 		case <-ctx.Done():
 			timer.Stop()
-			return resp, nil
+			return resp, err
 		case <-timer.C:
-			xio.DrainClose(resp.Body)
+			if resp != nil {
+				xio.DrainClose(resp.Body)
+			}
 		}
 
 		retryInterval = time.Duration(float64(retryInterval) * t.intervalMultiplier)
 		if retryInterval > t.maxInterval {
 			retryInterval = t.maxInterval
 		}
-		retryCount++
 
 		if trace.Retry != nil {
 			trace.Retry(xhttptrace.RetryInfo{
-				RetryCount: retryCount,
-				StatusCode: resp.StatusCode,
+				RetryCount: attempt,
+				StatusCode: statusCode,
 			})
 		}
 	}
 }
 
-func computeWaitDuration(interval time.Duration, jitterFactor float64, headers http.Header) time.Duration {
-	if retryAfter := headers.Get(HeaderRetryAfter); retryAfter != "" {
-		if secs, err := strconv.Atoi(retryAfter); err == nil {
-			return time.Duration(secs) * time.Second
+// attempt makes an attempt of the round trip, notifying trace of its start and completion.
+func (t *retryTransport) attempt(req *http.Request, attempt int, trace *xhttptrace.ClientTrace) (*http.Response, error) {
+	if trace.AttemptStart != nil {
+		trace.AttemptStart(xhttptrace.AttemptStartInfo{
+			Attempt: attempt,
+		})
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	if trace.AttemptDone != nil {
+		info := xhttptrace.AttemptDoneInfo{
+			Attempt:  attempt,
+			Err:      err,
+			Duration: time.Since(start),
 		}
+		if resp != nil {
+			info.StatusCode = resp.StatusCode
+		}
+		trace.AttemptDone(info)
+	}
 
-		if date, err := http.ParseTime(retryAfter); err == nil {
-			return time.Until(date)
+	return resp, err
+}
+
+// shouldRetry returns whether the outcome of the given attempt is retryable.
+func (t *retryTransport) shouldRetry(resp *http.Response, err error, attempt int) bool {
+	if t.retryPolicy != nil {
+		return t.retryPolicy(resp, err, attempt)
+	}
+
+	if err != nil {
+		return t.retryOnError != nil && t.retryOnError(err)
+	}
+
+	if t.retryStatusCodes != nil {
+		_, ok := t.retryStatusCodes[resp.StatusCode]
+		return ok
+	}
+
+	return isResponseRetryable(resp)
+}
+
+func (t *retryTransport) computeWaitDuration(interval time.Duration, headers http.Header) time.Duration {
+	if !t.ignoreRetryAfter {
+		if d, err := ParseRetryAfter(headers); err == nil {
+			if t.maxRetryAfter > 0 && d > t.maxRetryAfter {
+				return t.maxRetryAfter
+			}
+			return d
 		}
 	}
 
-	if jitterFactor == 0.0 {
+	if t.jitterFactor == 0.0 {
 		return interval
 	}
 
-	delta := jitterFactor * float64(interval)
+	delta := t.jitterFactor * float64(interval)
 	minInterval := float64(interval) - delta
 
 	// returns a random value in the half-open interval [interval - delta, interval + delta).
@@ -166,6 +265,25 @@ func RetryTransportInitialInterval(interval time.Duration) RetryTransportOption
 	})
 }
 
+// RetryTransportIdempotencyKey returns a RetryTransportOption that configures the transport to
+// attach a randomly generated Idempotency-Key header to POST and PATCH requests that have neither
+// an Idempotency-Key nor an X-Idempotency-Key header, making them retryable.
+//
+// See https://datatracker.ietf.org/doc/draft-ietf-httpapi-idempotency-key-header/.
+func RetryTransportIdempotencyKey() RetryTransportOption {
+	return newFuncRetryTransportOption(func(rt *retryTransport) {
+		rt.idempotencyKey = true
+	})
+}
+
+// RetryTransportIgnoreRetryAfter returns a RetryTransportOption that configures the transport
+// to ignore Retry-After response headers and always follow the backoff policy.
+func RetryTransportIgnoreRetryAfter() RetryTransportOption {
+	return newFuncRetryTransportOption(func(rt *retryTransport) {
+		rt.ignoreRetryAfter = true
+	})
+}
+
 // RetryTransportIntervalMultiplier returns a RetryTransportOption that configures the
 // interval multiplier of the backoff policy. Value must be >= 1.0, otherwise it panics.
 func RetryTransportIntervalMultiplier(multiplier float64) RetryTransportOption {
@@ -200,6 +318,88 @@ func RetryTransportMaxInterval(interval time.Duration) RetryTransportOption {
 	})
 }
 
+// RetryTransportMaxElapsedTime returns a RetryTransportOption that configures the max time
+// spent in a round trip, including retries. A retry is not attempted if waiting for it would
+// exceed this duration. Value must be > 0, otherwise it panics.
+func RetryTransportMaxElapsedTime(d time.Duration) RetryTransportOption {
+	if d <= 0 {
+		panic("invalid max elapsed time value")
+	}
+	return newFuncRetryTransportOption(func(rt *retryTransport) {
+		rt.maxElapsedTime = d
+	})
+}
+
+// RetryTransportMaxRetryAfter returns a RetryTransportOption that configures the max duration
+// to wait before a retry when respecting a Retry-After response header. Longer durations are capped
+// to this value. If not used, Retry-After headers are respected verbatim. Value must be > 0,
+// otherwise it panics.
+func RetryTransportMaxRetryAfter(d time.Duration) RetryTransportOption {
+	if d <= 0 {
+		panic("invalid max retry after value")
+	}
+	return newFuncRetryTransportOption(func(rt *retryTransport) {
+		rt.maxRetryAfter = d
+	})
+}
+
+// RetryTransportMaxRetries returns a RetryTransportOption that configures the max number
+// of retries of a request, 0 disabling retries. If not used, requests are retried until
+// their context is done. Value must be >= 0, otherwise it panics.
+func RetryTransportMaxRetries(n int) RetryTransportOption {
+	if n < 0 {
+		panic("invalid max retries value")
+	}
+	return newFuncRetryTransportOption(func(rt *retryTransport) {
+		rt.maxRetries = n
+	})
+}
+
+// RetryTransportRetryOnError returns a RetryTransportOption that configures the transport to retry
+// round trips failing with an error for which classifier returns true, e.g. IsRetryableError.
+// Value must not be nil, otherwise it panics.
+func RetryTransportRetryOnError(classifier func(error) bool) RetryTransportOption {
+	if classifier == nil {
+		panic("retry error classifier is nil")
+	}
+	return newFuncRetryTransportOption(func(rt *retryTransport) {
+		rt.retryOnError = classifier
+	})
+}
+
+// RetryTransportRetryPolicy returns a RetryTransportOption that configures the policy deciding
+// whether a round trip is retried, taking precedence over the status codes configured with
+// RetryTransportRetryStatusCodes and the error classifier configured with RetryTransportRetryOnError.
+// Requests that are not idempotent or whose body cannot be rewound are never retried, whatever
+// the policy. Value must not be nil, otherwise it panics.
+func RetryTransportRetryPolicy(policy RetryPolicy) RetryTransportOption {
+	if policy == nil {
+		panic("retry policy is nil")
+	}
+	return newFuncRetryTransportOption(func(rt *retryTransport) {
+		rt.retryPolicy = policy
+	})
+}
+
+// RetryTransportRetryStatusCodes returns a RetryTransportOption that configures the status codes
+// of retryable responses, replacing the default ones: 408, 413 with a Retry-After header, 425, 429,
+// 500, 502, 503 and 504. At least one valid status code must be provided, otherwise it panics.
+func RetryTransportRetryStatusCodes(codes ...int) RetryTransportOption {
+	if len(codes) == 0 {
+		panic("no retry status codes")
+	}
+	statusCodes := make(map[int]struct{}, len(codes))
+	for _, code := range codes {
+		if code < http.StatusContinue || code > 599 { //nolint:gomnd // 599 is the greatest valid status code.
+			panic("invalid retry status code value")
+		}
+		statusCodes[code] = struct{}{}
+	}
+	return newFuncRetryTransportOption(func(rt *retryTransport) {
+		rt.retryStatusCodes = statusCodes
+	})
+}
+
 // RetryTransportNextRoundTripper returns a RetryTransportOption that configures the
 // next round tripper to call. If not used http.DefaultTransport will be used.
 func RetryTransportNextRoundTripper(next http.RoundTripper) RetryTransportOption {
@@ -5,9 +5,12 @@ package xhttp_test
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
@@ -48,12 +51,14 @@ func TestRetryTransport_RoundTrip(t *testing.T) {
 		Header:     http.Header{xhttp.HeaderRetryAfter: []string{time.Now().Add(50 * time.Millisecond).Format(http.TimeFormat)}},
 		StatusCode: http.StatusTooManyRequests,
 	}
+	resp502 := &http.Response{StatusCode: http.StatusBadGateway}
 	resp503 := &http.Response{StatusCode: http.StatusServiceUnavailable}
 
 	testCases := []struct {
 		name         string
 		ctx          context.Context //nolint:containedctx // ctx appended to req object
 		jitterFactor float64
+		options      []xhttp.RetryTransportOption
 		next         http.RoundTripper
 		req          *http.Request
 		expectedResp *http.Response
@@ -161,16 +166,136 @@ func TestRetryTransport_RoundTrip(t *testing.T) {
 			},
 			expectedResp: resp204,
 		},
+		{
+			name:    "max retries exhausted returns last http response",
+			options: []xhttp.RetryTransportOption{xhttp.RetryTransportMaxRetries(2)},
+			next:    &fakeTransport{resps: []*http.Response{resp503, resp502, resp503, resp204}},
+			req: &http.Request{
+				Body:   http.NoBody,
+				Method: http.MethodGet,
+				URL:    u,
+			},
+			expectedResp: resp503,
+		},
+		{
+			name:    "no retries",
+			options: []xhttp.RetryTransportOption{xhttp.RetryTransportMaxRetries(0)},
+			next:    &fakeTransport{resps: []*http.Response{resp502, resp204}},
+			req: &http.Request{
+				Body:   http.NoBody,
+				Method: http.MethodGet,
+				URL:    u,
+			},
+			expectedResp: resp502,
+		},
+		{
+			name:    "max elapsed time exhausted returns last http response",
+			options: []xhttp.RetryTransportOption{xhttp.RetryTransportMaxElapsedTime(25 * time.Millisecond)},
+			next:    &fakeTransport{resps: []*http.Response{resp503, resp502, resp503, resp204}},
+			req: &http.Request{
+				Body:   http.NoBody,
+				Method: http.MethodGet,
+				URL:    u,
+			},
+			expectedResp: resp502,
+		},
+		{
+			name:    "retryable errors retried",
+			options: []xhttp.RetryTransportOption{xhttp.RetryTransportRetryOnError(xhttp.IsRetryableError)},
+			next:    &errTransport{errs: []error{io.EOF, io.ErrUnexpectedEOF}, next: &fakeTransport{resps: []*http.Response{resp204}}},
+			req: &http.Request{
+				Body:   http.NoBody,
+				Method: http.MethodGet,
+				URL:    u,
+			},
+			expectedResp: resp204,
+		},
+		{
+			name:    "non retryable error bubbles up",
+			options: []xhttp.RetryTransportOption{xhttp.RetryTransportRetryOnError(xhttp.IsRetryableError)},
+			next:    &errTransport{errs: []error{io.EOF, errNoBody}, next: &fakeTransport{resps: []*http.Response{resp204}}},
+			req: &http.Request{
+				Body:   http.NoBody,
+				Method: http.MethodGet,
+				URL:    u,
+			},
+			expectedErr: errNoBody,
+		},
+		{
+			name:    "retryable error of non idempotent request bubbles up",
+			options: []xhttp.RetryTransportOption{xhttp.RetryTransportRetryOnError(xhttp.IsRetryableError)},
+			next:    &errTransport{errs: []error{io.EOF}, next: &fakeTransport{resps: []*http.Response{resp204}}},
+			req: &http.Request{
+				Body:   http.NoBody,
+				Method: http.MethodPost,
+				URL:    u,
+			},
+			expectedErr: io.EOF,
+		},
+		{
+			name:    "custom retry status codes",
+			options: []xhttp.RetryTransportOption{xhttp.RetryTransportRetryStatusCodes(http.StatusBadGateway)},
+			next:    &fakeTransport{resps: []*http.Response{resp502, resp502, resp503}},
+			req: &http.Request{
+				Body:   http.NoBody,
+				Method: http.MethodGet,
+				URL:    u,
+			},
+			expectedResp: resp503,
+		},
+		{
+			name: "custom retry policy",
+			options: []xhttp.RetryTransportOption{xhttp.RetryTransportRetryPolicy(func(resp *http.Response, err error, attempt int) bool {
+				return err == nil && resp.StatusCode == http.StatusServiceUnavailable && attempt < 2
+			})},
+			next: &fakeTransport{resps: []*http.Response{resp503, resp503, resp204}},
+			req: &http.Request{
+				Body:   http.NoBody,
+				Method: http.MethodGet,
+				URL:    u,
+			},
+			expectedResp: resp503,
+		},
+		{
+			name: "custom retry policy retrying errors",
+			options: []xhttp.RetryTransportOption{xhttp.RetryTransportRetryPolicy(func(_ *http.Response, err error, _ int) bool {
+				return err == errNoBody
+			})},
+			next: &fakeTransport{resps: []*http.Response{resp204}},
+			req: &http.Request{
+				Body: nil,
+				GetBody: func() (io.ReadCloser, error) {
+					return http.NoBody, nil
+				},
+				Method: http.MethodGet,
+				URL:    u,
+			},
+			expectedResp: resp204,
+		},
+		{
+			name: "custom retry policy not consulted for non idempotent requests",
+			options: []xhttp.RetryTransportOption{xhttp.RetryTransportRetryPolicy(func(*http.Response, error, int) bool {
+				return true
+			})},
+			next: &fakeTransport{resps: []*http.Response{resp503, resp204}},
+			req: &http.Request{
+				Body:   http.NoBody,
+				Method: http.MethodPost,
+				URL:    u,
+			},
+			expectedResp: resp503,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			retryTransp := xhttp.NewRetryTransport(
+			retryTransp := xhttp.NewRetryTransport(append([]xhttp.RetryTransportOption{
 				xhttp.RetryTransportNextRoundTripper(tc.next),
-				xhttp.RetryTransportInitialInterval(10*time.Millisecond),
+				xhttp.RetryTransportInitialInterval(10 * time.Millisecond),
 				xhttp.RetryTransportIntervalMultiplier(2),
 				xhttp.RetryTransportJitterFactor(tc.jitterFactor),
-				xhttp.RetryTransportMaxInterval(20*time.Millisecond))
+				xhttp.RetryTransportMaxInterval(20 * time.Millisecond),
+			}, tc.options...)...)
 
 			if tc.ctx != nil {
 				tc.req = tc.req.Clone(tc.ctx)
@@ -188,6 +313,169 @@ func TestRetryTransport_RoundTrip(t *testing.T) {
 	}
 }
 
+func TestRetryTransport_RoundTrip_RetryAfter(t *testing.T) {
+	u, _ := url.Parse("http://example.com")
+
+	testCases := []struct {
+		name        string
+		retryAfter  string
+		options     []xhttp.RetryTransportOption
+		maxDuration time.Duration
+	}{
+		{
+			name:        "retry-after in seconds capped",
+			retryAfter:  "3600",
+			options:     []xhttp.RetryTransportOption{xhttp.RetryTransportMaxRetryAfter(10 * time.Millisecond)},
+			maxDuration: time.Second,
+		},
+		{
+			name:        "retry-after date capped",
+			retryAfter:  time.Now().Add(time.Hour).Format(http.TimeFormat),
+			options:     []xhttp.RetryTransportOption{xhttp.RetryTransportMaxRetryAfter(10 * time.Millisecond)},
+			maxDuration: time.Second,
+		},
+		{
+			name:        "retry-after ignored",
+			retryAfter:  "3600",
+			options:     []xhttp.RetryTransportOption{xhttp.RetryTransportIgnoreRetryAfter()},
+			maxDuration: time.Second,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp429 := &http.Response{
+				Header:     http.Header{xhttp.HeaderRetryAfter: []string{tc.retryAfter}},
+				StatusCode: http.StatusTooManyRequests,
+			}
+			resp204 := &http.Response{StatusCode: http.StatusNoContent}
+
+			retryTransp := xhttp.NewRetryTransport(append([]xhttp.RetryTransportOption{
+				xhttp.RetryTransportNextRoundTripper(&fakeTransport{resps: []*http.Response{resp429, resp204}}),
+				xhttp.RetryTransportInitialInterval(10 * time.Millisecond),
+			}, tc.options...)...)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*tc.maxDuration)
+			defer cancel()
+
+			req := &http.Request{Body: http.NoBody, Method: http.MethodGet, URL: u}
+
+			start := time.Now()
+			gotResp, gotErr := retryTransp.RoundTrip(req.WithContext(ctx))
+
+			if gotResp != resp204 {
+				t.Errorf("response mistmatch: %v != %v", gotResp, resp204)
+			}
+			if gotErr != nil {
+				t.Errorf("unexpected error: %v", gotErr)
+			}
+			if elapsed := time.Since(start); elapsed > tc.maxDuration {
+				t.Errorf("expected round trip to take at most %s; took %s", tc.maxDuration, elapsed)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_RoundTrip_IdempotencyKey(t *testing.T) {
+	u, _ := url.Parse("http://example.com")
+	keyPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	testCases := []struct {
+		name             string
+		method           string
+		header           http.Header
+		options          []xhttp.RetryTransportOption
+		expectedAttempts int
+		expectedKey      string
+	}{
+		{
+			name:             "key generated for post request",
+			method:           http.MethodPost,
+			options:          []xhttp.RetryTransportOption{xhttp.RetryTransportIdempotencyKey()},
+			expectedAttempts: 2,
+		},
+		{
+			name:             "key generated for patch request",
+			method:           http.MethodPatch,
+			options:          []xhttp.RetryTransportOption{xhttp.RetryTransportIdempotencyKey()},
+			expectedAttempts: 2,
+		},
+		{
+			name:             "existing key preserved",
+			method:           http.MethodPost,
+			header:           http.Header{xhttp.HeaderIdempotencyKey: {"key"}},
+			options:          []xhttp.RetryTransportOption{xhttp.RetryTransportIdempotencyKey()},
+			expectedAttempts: 2,
+			expectedKey:      "key",
+		},
+		{
+			name:             "no key generated for put request",
+			method:           http.MethodPut,
+			options:          []xhttp.RetryTransportOption{xhttp.RetryTransportIdempotencyKey()},
+			expectedAttempts: 2,
+		},
+		{
+			name:             "no key generated by default",
+			method:           http.MethodPost,
+			expectedAttempts: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var keys []string
+			next := &fakeTransport{resps: []*http.Response{
+				{StatusCode: http.StatusServiceUnavailable},
+				{StatusCode: http.StatusNoContent},
+			}}
+
+			retryTransp := xhttp.NewRetryTransport(append([]xhttp.RetryTransportOption{
+				xhttp.RetryTransportNextRoundTripper(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					keys = append(keys, req.Header.Get(xhttp.HeaderIdempotencyKey))
+					return next.RoundTrip(req)
+				})),
+				xhttp.RetryTransportInitialInterval(time.Millisecond),
+			}, tc.options...)...)
+
+			header := tc.header.Clone()
+			if header == nil {
+				header = http.Header{}
+			}
+			req := &http.Request{Body: http.NoBody, Header: header, Method: tc.method, URL: u}
+
+			if _, err := retryTransp.RoundTrip(req); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(keys) != tc.expectedAttempts {
+				t.Fatalf("expected %d attempts; got %d", tc.expectedAttempts, len(keys))
+			}
+			for _, key := range keys {
+				if key != keys[0] {
+					t.Errorf("expected the same key on every attempt; got %q", keys)
+				}
+			}
+			switch {
+			case tc.expectedKey != "":
+				if keys[0] != tc.expectedKey {
+					t.Errorf("expected key %q; got %q", tc.expectedKey, keys[0])
+				}
+			case len(tc.options) > 0 && tc.method != http.MethodPut:
+				if !keyPattern.MatchString(keys[0]) {
+					t.Errorf("expected generated key; got %q", keys[0])
+				}
+				if req.Header.Get(xhttp.HeaderIdempotencyKey) != "" {
+					t.Error("expected original request to be left unmodified")
+				}
+			default:
+				if keys[0] != "" {
+					t.Errorf("expected no key; got %q", keys[0])
+				}
+			}
+		})
+	}
+}
+
 func TestRetryTransportInitialInterval(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -301,6 +589,173 @@ func TestRetryTransportMaxInterval(t *testing.T) {
 	}
 }
 
+func TestRetryTransportMaxElapsedTime(t *testing.T) {
+	testCases := []struct {
+		name  string
+		d     time.Duration
+		panic bool
+	}{
+		{
+			name:  "panic",
+			d:     0,
+			panic: true,
+		},
+		{
+			name:  "valid",
+			d:     1,
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testRetryTransportOptionPanic(t, tc.panic, func() xhttp.RetryTransportOption {
+				return xhttp.RetryTransportMaxElapsedTime(tc.d)
+			})
+		})
+	}
+}
+
+func TestRetryTransportMaxRetryAfter(t *testing.T) {
+	testCases := []struct {
+		name  string
+		d     time.Duration
+		panic bool
+	}{
+		{
+			name:  "panic",
+			d:     0,
+			panic: true,
+		},
+		{
+			name:  "valid",
+			d:     1,
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testRetryTransportOptionPanic(t, tc.panic, func() xhttp.RetryTransportOption {
+				return xhttp.RetryTransportMaxRetryAfter(tc.d)
+			})
+		})
+	}
+}
+
+func TestRetryTransportMaxRetries(t *testing.T) {
+	testCases := []struct {
+		name  string
+		n     int
+		panic bool
+	}{
+		{
+			name:  "panic",
+			n:     -1,
+			panic: true,
+		},
+		{
+			name:  "valid",
+			n:     0,
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testRetryTransportOptionPanic(t, tc.panic, func() xhttp.RetryTransportOption {
+				return xhttp.RetryTransportMaxRetries(tc.n)
+			})
+		})
+	}
+}
+
+func TestRetryTransportRetryOnError(t *testing.T) {
+	testCases := []struct {
+		name       string
+		classifier func(error) bool
+		panic      bool
+	}{
+		{
+			name:       "panic",
+			classifier: nil,
+			panic:      true,
+		},
+		{
+			name:       "valid",
+			classifier: xhttp.IsRetryableError,
+			panic:      false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testRetryTransportOptionPanic(t, tc.panic, func() xhttp.RetryTransportOption {
+				return xhttp.RetryTransportRetryOnError(tc.classifier)
+			})
+		})
+	}
+}
+
+func TestRetryTransportRetryPolicy(t *testing.T) {
+	testCases := []struct {
+		name   string
+		policy xhttp.RetryPolicy
+		panic  bool
+	}{
+		{
+			name:   "panic",
+			policy: nil,
+			panic:  true,
+		},
+		{
+			name:   "valid",
+			policy: func(*http.Response, error, int) bool { return false },
+			panic:  false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testRetryTransportOptionPanic(t, tc.panic, func() xhttp.RetryTransportOption {
+				return xhttp.RetryTransportRetryPolicy(tc.policy)
+			})
+		})
+	}
+}
+
+func TestRetryTransportRetryStatusCodes(t *testing.T) {
+	testCases := []struct {
+		name  string
+		codes []int
+		panic bool
+	}{
+		{
+			name:  "panic - no status code",
+			codes: nil,
+			panic: true,
+		},
+		{
+			name:  "panic - invalid status code",
+			codes: []int{http.StatusBadGateway, 600},
+			panic: true,
+		},
+		{
+			name:  "valid",
+			codes: []int{http.StatusBadGateway, http.StatusGatewayTimeout},
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testRetryTransportOptionPanic(t, tc.panic, func() xhttp.RetryTransportOption {
+				return xhttp.RetryTransportRetryStatusCodes(tc.codes...)
+			})
+		})
+	}
+}
+
 func TestRetryTransportNextRoundTripper(t *testing.T) {
 	testCases := []struct {
 		name  string
@@ -327,3 +782,49 @@ func TestRetryTransportNextRoundTripper(t *testing.T) {
 		})
 	}
 }
+
+func TestRetryTransport_RoundTrip_Trace(t *testing.T) {
+	u, _ := url.Parse("http://example.com")
+
+	resp503 := &http.Response{Body: http.NoBody, StatusCode: http.StatusServiceUnavailable}
+	resp204 := &http.Response{Body: http.NoBody, StatusCode: http.StatusNoContent}
+
+	retryTransp := xhttp.NewRetryTransport(
+		xhttp.RetryTransportNextRoundTripper(&fakeTransport{resps: []*http.Response{resp503, resp204}}),
+		xhttp.RetryTransportInitialInterval(time.Millisecond),
+		xhttp.RetryTransportJitterFactor(0),
+	)
+
+	var events []string
+	ctx := xhttptrace.WithClientTrace(context.Background(), &xhttptrace.ClientTrace{
+		AttemptStart: func(i xhttptrace.AttemptStartInfo) {
+			events = append(events, fmt.Sprintf("attempt start %d", i.Attempt))
+		},
+		AttemptDone: func(i xhttptrace.AttemptDoneInfo) {
+			events = append(events, fmt.Sprintf("attempt done %d %d %v", i.Attempt, i.StatusCode, i.Err))
+		},
+		BackoffWait: func(i xhttptrace.BackoffWaitInfo) {
+			events = append(events, fmt.Sprintf("backoff wait %d %s", i.Attempt, i.Duration))
+		},
+		Retry: func(i xhttptrace.RetryInfo) {
+			events = append(events, fmt.Sprintf("retry %d %d", i.RetryCount, i.StatusCode))
+		},
+	})
+
+	req := &http.Request{Body: http.NoBody, Method: http.MethodGet, URL: u}
+	if _, err := retryTransp.RoundTrip(req.WithContext(ctx)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"attempt start 1",
+		"attempt done 1 503 <nil>",
+		"backoff wait 1 1ms",
+		"retry 1 503",
+		"attempt start 2",
+		"attempt done 2 204 <nil>",
+	}
+	if !reflect.DeepEqual(expected, events) {
+		t.Errorf("events mismatch: expected %q; got %q", expected, events)
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+const reverseProxyDefaultOriginalHeaderPrefix = "X-Original"
+
+type reverseProxy struct {
+	target               *url.URL
+	transport            http.RoundTripper
+	trustedProxies       []netip.Prefix
+	originalHeaderPrefix string
+	preserveHost         bool
+	rewrites             []func(*httputil.ProxyRequest)
+}
+
+// NewReverseProxy creates a new httputil.ReverseProxy routing requests to target, configured with
+// the options passed in input. The path of target is joined with the one of incoming requests and
+// hop-by-hop headers are stripped from requests and responses.
+//
+// The Forwarded, X-Forwarded-For, X-Forwarded-Host and X-Forwarded-Proto headers describe the
+// incoming request. When the peer is a trusted proxy, the values it sent are extended. Otherwise,
+// they cannot be relied upon and are replaced, the original values being preserved with ReplaceHeader
+// under the "X-Original" prefix by default, e.g. X-Original-Forwarded. By default, no proxy is trusted.
+//
+// Rewrite hooks are called in order once the outbound request is set up.
+// The returned proxy may be further configured before serving.
+func NewReverseProxy(target *url.URL, options ...ReverseProxyOption) *httputil.ReverseProxy {
+	if target == nil {
+		panic("target is nil")
+	}
+
+	p := &reverseProxy{
+		target:               target,
+		originalHeaderPrefix: reverseProxyDefaultOriginalHeaderPrefix,
+	}
+
+	for _, opt := range options {
+		opt.apply(p)
+	}
+
+	return &httputil.ReverseProxy{
+		Rewrite:   p.rewrite,
+		Transport: p.transport,
+	}
+}
+
+func (p *reverseProxy) rewrite(pr *httputil.ProxyRequest) {
+	pr.SetURL(p.target)
+	if p.preserveHost {
+		pr.Out.Host = pr.In.Host
+	}
+
+	p.setForwarded(pr)
+
+	for _, fn := range p.rewrites {
+		fn(pr)
+	}
+}
+
+// setForwarded sets the forwarding headers of the outbound request. httputil.ReverseProxy
+// removes them from the outbound request before calling Rewrite.
+func (p *reverseProxy) setForwarded(pr *httputil.ProxyRequest) {
+	ip, ok := parseNodeAddr(pr.In.RemoteAddr)
+	trusted := ok && isTrustedProxy(ip, p.trustedProxies)
+
+	proto := "http"
+	if pr.In.TLS != nil {
+		proto = "https"
+	}
+
+	node := "unknown"
+	xff := ""
+	if ok {
+		node = ip.String()
+		if ip.Is6() {
+			node = "[" + node + "]"
+		}
+		xff = ip.String()
+	}
+
+	forwarded := "for=" + formatForwardedValue(node) + ";host=" + formatForwardedValue(pr.In.Host) + ";proto=" + proto
+
+	in, out := pr.In.Header, pr.Out.Header
+	if trusted {
+		if prior := in.Values(HeaderForwarded); len(prior) > 0 {
+			forwarded = strings.Join(prior, ", ") + ", " + forwarded
+		}
+		if prior := in.Values(HeaderXForwardedFor); len(prior) > 0 && xff != "" {
+			xff = strings.Join(prior, ", ") + ", " + xff
+		}
+		out.Set(HeaderForwarded, forwarded)
+		if xff != "" {
+			out.Set(HeaderXForwardedFor, xff)
+		}
+		out.Set(HeaderXForwardedHost, headerOrDefault(in, HeaderXForwardedHost, pr.In.Host))
+		out.Set(HeaderXForwardedProto, headerOrDefault(in, HeaderXForwardedProto, proto))
+		return
+	}
+
+	for _, key := range []string{HeaderForwarded, HeaderXForwardedFor, HeaderXForwardedHost, HeaderXForwardedProto} {
+		if v, ok := in[key]; ok {
+			out[key] = v
+		}
+	}
+	ReplaceHeader(out, p.originalHeaderPrefix, HeaderForwarded, forwarded)
+	ReplaceHeader(out, p.originalHeaderPrefix, HeaderXForwardedFor, xff)
+	if xff == "" {
+		out.Del(HeaderXForwardedFor)
+	}
+	ReplaceHeader(out, p.originalHeaderPrefix, HeaderXForwardedHost, pr.In.Host)
+	ReplaceHeader(out, p.originalHeaderPrefix, HeaderXForwardedProto, proto)
+}
+
+// formatForwardedValue returns s as a token if possible, or as a quoted-string otherwise.
+// https://datatracker.ietf.org/doc/html/rfc7239#section-4
+func formatForwardedValue(s string) string {
+	if s != "" && !strings.ContainsFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("!#$%&'*+-.^_`|~", r))
+	}) {
+		return s
+	}
+	return quoteString(s)
+}
+
+func headerOrDefault(headers http.Header, key, defaultValue string) string {
+	if v := headers.Get(key); v != "" {
+		return v
+	}
+	return defaultValue
+}
+
+type (
+	// ReverseProxyOption configures the reverse proxy options when calling NewReverseProxy.
+	ReverseProxyOption interface {
+		apply(p *reverseProxy)
+	}
+
+	funcReverseProxyOption struct {
+		fn func(*reverseProxy)
+	}
+)
+
+func newFuncReverseProxyOption(fn func(*reverseProxy)) funcReverseProxyOption {
+	return funcReverseProxyOption{
+		fn: fn,
+	}
+}
+
+func (o funcReverseProxyOption) apply(p *reverseProxy) {
+	o.fn(p)
+}
+
+// ReverseProxyOriginalHeaderPrefix returns a ReverseProxyOption that configures the prefix of the headers
+// preserving the forwarding headers sent by untrusted peers. Value must not be empty, otherwise it panics.
+func ReverseProxyOriginalHeaderPrefix(prefix string) ReverseProxyOption {
+	if prefix == "" {
+		panic("original header prefix is empty")
+	}
+	return newFuncReverseProxyOption(func(p *reverseProxy) {
+		p.originalHeaderPrefix = prefix
+	})
+}
+
+// ReverseProxyPreserveHost returns a ReverseProxyOption that configures the proxy to forward
+// the Host header of incoming requests instead of setting it to the host of the target.
+func ReverseProxyPreserveHost() ReverseProxyOption {
+	return newFuncReverseProxyOption(func(p *reverseProxy) {
+		p.preserveHost = true
+	})
+}
+
+// ReverseProxyRewrite returns a ReverseProxyOption that adds a hook to rewrite the outbound request.
+// Hooks are called in the order they are added. Value must not be nil, otherwise it panics.
+func ReverseProxyRewrite(fn func(*httputil.ProxyRequest)) ReverseProxyOption {
+	if fn == nil {
+		panic("rewrite func is nil")
+	}
+	return newFuncReverseProxyOption(func(p *reverseProxy) {
+		p.rewrites = append(p.rewrites, fn)
+	})
+}
+
+// ReverseProxyTransport returns a ReverseProxyOption that configures the round tripper used to send
+// outbound requests. If not used http.DefaultTransport will be used.
+func ReverseProxyTransport(transport http.RoundTripper) ReverseProxyOption {
+	if transport == nil {
+		panic("transport is nil")
+	}
+	return newFuncReverseProxyOption(func(p *reverseProxy) {
+		p.transport = transport
+	})
+}
+
+// ReverseProxyTrustedProxies returns a ReverseProxyOption that configures the addresses of the proxies
+// trusted to report the forwarding headers of requests, as in ClientIP.
+func ReverseProxyTrustedProxies(trustedProxies ...netip.Prefix) ReverseProxyOption {
+	return newFuncReverseProxyOption(func(p *reverseProxy) {
+		p.trustedProxies = trustedProxies
+	})
+}
@@ -0,0 +1,256 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/netip"
+	"net/url"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestNewReverseProxy(t *testing.T) {
+	testCases := []struct {
+		name           string
+		options        []xhttp.ReverseProxyOption
+		path           string
+		remoteAddr     string
+		header         http.Header
+		expectedPath   string
+		expectedHost   string
+		expectedHeader http.Header
+	}{
+		{
+			name:         "no forwarding headers",
+			path:         "/foo",
+			remoteAddr:   "192.0.2.1:1234",
+			expectedPath: "/base/foo",
+			expectedHeader: http.Header{
+				xhttp.HeaderForwarded:       {`for=192.0.2.1;host=example.com;proto=http`},
+				xhttp.HeaderXForwardedFor:   {"192.0.2.1"},
+				xhttp.HeaderXForwardedHost:  {"example.com"},
+				xhttp.HeaderXForwardedProto: {"http"},
+			},
+		},
+		{
+			name:       "untrusted peer headers preserved",
+			path:       "/foo",
+			remoteAddr: "192.0.2.1:1234",
+			header: http.Header{
+				xhttp.HeaderForwarded:       {"for=198.51.100.1"},
+				xhttp.HeaderXForwardedFor:   {"198.51.100.1"},
+				xhttp.HeaderXForwardedHost:  {"spoofed.com"},
+				xhttp.HeaderXForwardedProto: {"https"},
+			},
+			expectedPath: "/base/foo",
+			expectedHeader: http.Header{
+				xhttp.HeaderForwarded:                     {`for=192.0.2.1;host=example.com;proto=http`},
+				xhttp.HeaderXForwardedFor:                 {"192.0.2.1"},
+				xhttp.HeaderXForwardedHost:                {"example.com"},
+				xhttp.HeaderXForwardedProto:               {"http"},
+				"X-Original-" + xhttp.HeaderForwarded:     {"for=198.51.100.1"},
+				"X-Original-" + xhttp.HeaderXForwardedFor: {"198.51.100.1"},
+				"X-Original-X-Forwarded-Host":             {"spoofed.com"},
+				"X-Original-X-Forwarded-Proto":            {"https"},
+			},
+		},
+		{
+			name:       "trusted peer headers extended",
+			options:    []xhttp.ReverseProxyOption{xhttp.ReverseProxyTrustedProxies(netip.MustParsePrefix("192.0.2.0/24"))},
+			path:       "/foo",
+			remoteAddr: "192.0.2.1:1234",
+			header: http.Header{
+				xhttp.HeaderForwarded:       {"for=198.51.100.1;proto=https"},
+				xhttp.HeaderXForwardedFor:   {"198.51.100.1"},
+				xhttp.HeaderXForwardedHost:  {"public.com"},
+				xhttp.HeaderXForwardedProto: {"https"},
+			},
+			expectedPath: "/base/foo",
+			expectedHeader: http.Header{
+				xhttp.HeaderForwarded:       {`for=198.51.100.1;proto=https, for=192.0.2.1;host=example.com;proto=http`},
+				xhttp.HeaderXForwardedFor:   {"198.51.100.1, 192.0.2.1"},
+				xhttp.HeaderXForwardedHost:  {"public.com"},
+				xhttp.HeaderXForwardedProto: {"https"},
+			},
+		},
+		{
+			name:         "ipv6 peer and custom prefix",
+			options:      []xhttp.ReverseProxyOption{xhttp.ReverseProxyOriginalHeaderPrefix("X-Untrusted")},
+			path:         "/foo",
+			remoteAddr:   "[2001:db8::1]:1234",
+			header:       http.Header{xhttp.HeaderXForwardedFor: {"198.51.100.1"}},
+			expectedPath: "/base/foo",
+			expectedHeader: http.Header{
+				xhttp.HeaderForwarded:         {`for="[2001:db8::1]";host=example.com;proto=http`},
+				xhttp.HeaderXForwardedFor:     {"2001:db8::1"},
+				xhttp.HeaderXForwardedHost:    {"example.com"},
+				xhttp.HeaderXForwardedProto:   {"http"},
+				"X-Untrusted-X-Forwarded-For": {"198.51.100.1"},
+			},
+		},
+		{
+			name:         "peer without ip",
+			path:         "/foo",
+			remoteAddr:   "@",
+			header:       http.Header{xhttp.HeaderXForwardedFor: {"198.51.100.1"}},
+			expectedPath: "/base/foo",
+			expectedHeader: http.Header{
+				xhttp.HeaderForwarded:                     {`for=unknown;host=example.com;proto=http`},
+				xhttp.HeaderXForwardedFor:                 nil,
+				xhttp.HeaderXForwardedHost:                {"example.com"},
+				xhttp.HeaderXForwardedProto:               {"http"},
+				"X-Original-" + xhttp.HeaderXForwardedFor: {"198.51.100.1"},
+			},
+		},
+		{
+			name:       "hop-by-hop headers stripped",
+			path:       "/foo",
+			remoteAddr: "192.0.2.1:1234",
+			header: http.Header{
+				xhttp.HeaderConnection: {"X-Hop"},
+				"X-Hop":                {"value"},
+				xhttp.HeaderKeepAlive:  {"timeout=5"},
+			},
+			expectedPath: "/base/foo",
+			expectedHeader: http.Header{
+				xhttp.HeaderForwarded:       {`for=192.0.2.1;host=example.com;proto=http`},
+				xhttp.HeaderXForwardedFor:   {"192.0.2.1"},
+				xhttp.HeaderXForwardedHost:  {"example.com"},
+				xhttp.HeaderXForwardedProto: {"http"},
+				xhttp.HeaderConnection:      nil,
+				"X-Hop":                     nil,
+				xhttp.HeaderKeepAlive:       nil,
+			},
+		},
+		{
+			name:         "preserve host",
+			options:      []xhttp.ReverseProxyOption{xhttp.ReverseProxyPreserveHost()},
+			path:         "/foo",
+			remoteAddr:   "192.0.2.1:1234",
+			expectedPath: "/base/foo",
+			expectedHost: "example.com",
+		},
+		{
+			name: "rewrite hooks",
+			options: []xhttp.ReverseProxyOption{
+				xhttp.ReverseProxyRewrite(func(pr *httputil.ProxyRequest) {
+					pr.Out.URL.Path = "/rewritten"
+				}),
+				xhttp.ReverseProxyRewrite(func(pr *httputil.ProxyRequest) {
+					pr.Out.Header.Set("X-Hook", pr.Out.URL.Path)
+				}),
+			},
+			path:           "/foo",
+			remoteAddr:     "192.0.2.1:1234",
+			expectedPath:   "/rewritten",
+			expectedHeader: http.Header{"X-Hook": {"/rewritten"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				gotPath   string
+				gotHost   string
+				gotHeader http.Header
+			)
+			backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath, gotHost, gotHeader = r.URL.Path, r.Host, r.Header
+			}))
+			defer backend.Close()
+
+			target, err := url.Parse(backend.URL + "/base")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com"+tc.path, http.NoBody)
+			req.RemoteAddr = tc.remoteAddr
+			for k, v := range tc.header {
+				req.Header[k] = v
+			}
+
+			w := httptest.NewRecorder()
+			xhttp.NewReverseProxy(target, tc.options...).ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status code mismatch: expected %d; got %d", http.StatusOK, w.Code)
+			}
+			if gotPath != tc.expectedPath {
+				t.Errorf("path mismatch: expected %q; got %q", tc.expectedPath, gotPath)
+			}
+			expectedHost := tc.expectedHost
+			if expectedHost == "" {
+				expectedHost = target.Host
+			}
+			if gotHost != expectedHost {
+				t.Errorf("host mismatch: expected %q; got %q", expectedHost, gotHost)
+			}
+			for k, v := range tc.expectedHeader {
+				if got := gotHeader.Values(k); len(got) != len(v) || (len(v) > 0 && got[0] != v[0]) {
+					t.Errorf("header %s mismatch: expected %q; got %q", k, v, got)
+				}
+			}
+		})
+	}
+}
+
+func TestNewReverseProxy_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+
+	xhttp.NewReverseProxy(nil)
+}
+
+func TestReverseProxyOptions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func() xhttp.ReverseProxyOption
+		panic bool
+	}{
+		{
+			name:  "original header prefix empty",
+			fn:    func() xhttp.ReverseProxyOption { return xhttp.ReverseProxyOriginalHeaderPrefix("") },
+			panic: true,
+		},
+		{
+			name:  "original header prefix valid",
+			fn:    func() xhttp.ReverseProxyOption { return xhttp.ReverseProxyOriginalHeaderPrefix("X-Untrusted") },
+			panic: false,
+		},
+		{
+			name:  "rewrite nil",
+			fn:    func() xhttp.ReverseProxyOption { return xhttp.ReverseProxyRewrite(nil) },
+			panic: true,
+		},
+		{
+			name:  "rewrite valid",
+			fn:    func() xhttp.ReverseProxyOption { return xhttp.ReverseProxyRewrite(func(*httputil.ProxyRequest) {}) },
+			panic: false,
+		},
+		{
+			name:  "transport nil",
+			fn:    func() xhttp.ReverseProxyOption { return xhttp.ReverseProxyTransport(nil) },
+			panic: true,
+		},
+		{
+			name:  "transport valid",
+			fn:    func() xhttp.ReverseProxyOption { return xhttp.ReverseProxyTransport(&fakeTransport{}) },
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, tc.fn)
+		})
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet"
+)
+
+const (
+	serverDefaultReadHeaderTimeout   = 10 * time.Second
+	serverDefaultReadTimeout         = 30 * time.Second
+	serverDefaultWriteTimeout        = 30 * time.Second
+	serverDefaultIdleTimeout         = 120 * time.Second
+	serverDefaultShutdownGracePeriod = 30 * time.Second
+)
+
+// Server is an HTTP server managing its own lifecycle: it is configured with sane timeouts
+// by default and shuts down gracefully, draining in-flight requests, when its context is done.
+//
+// The embedded http.Server may be further configured before serving.
+type Server struct {
+	http.Server
+
+	shutdownDelay       time.Duration
+	shutdownGracePeriod time.Duration
+
+	ready atomic.Bool
+}
+
+// NewServer creates a new Server serving handler, configured with the options passed in input.
+// By default, the server listens on ":http", read header, read, write and idle timeouts are respectively
+// set to 10s, 30s, 30s and 120s, and in-flight requests are given 30s to complete on shutdown.
+func NewServer(handler http.Handler, options ...ServerOption) *Server {
+	s := &Server{
+		Server: http.Server{
+			Handler:           handler,
+			ReadHeaderTimeout: serverDefaultReadHeaderTimeout,
+			ReadTimeout:       serverDefaultReadTimeout,
+			WriteTimeout:      serverDefaultWriteTimeout,
+			IdleTimeout:       serverDefaultIdleTimeout,
+		},
+		shutdownGracePeriod: serverDefaultShutdownGracePeriod,
+	}
+
+	for _, opt := range options {
+		opt.apply(s)
+	}
+
+	return s
+}
+
+// ListenAndServeContext listens on the TCP network address s.Addr and then calls ServeContext.
+// If s.Addr is blank, ":http" is used.
+func (s *Server) ListenAndServeContext(ctx context.Context) error {
+	addr := s.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+
+	l, err := net.Listen(xnet.NetworkTCP, addr)
+	if err != nil {
+		return err
+	}
+
+	return s.ServeContext(ctx, l)
+}
+
+// ServeContext accepts incoming connections on the listener l and serves them until ctx is done.
+// The server is then marked as not ready, waits for the shutdown delay, if any, and shuts down
+// gracefully: it stops accepting connections and waits for in-flight requests to complete, up to
+// the shutdown grace period, after which remaining connections are closed.
+//
+// It returns nil once the server is shut down gracefully, or the error that prevented it.
+func (s *Server) ServeContext(ctx context.Context, l net.Listener) error {
+	errCh := make(chan error, 1)
+
+	s.ready.Store(true)
+	go func() {
+		errCh <- s.Serve(l)
+	}()
+
+	select {
+	case err := <-errCh:
+		s.ready.Store(false)
+		return err
+	case <-ctx.Done():
+	}
+
+	s.ready.Store(false)
+
+	if s.shutdownDelay > 0 {
+		time.Sleep(s.shutdownDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.shutdownGracePeriod)
+	defer cancel()
+
+	if err := s.Shutdown(shutdownCtx); err != nil {
+		s.Close()
+		return err
+	}
+
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Ready returns whether the server is serving requests and not shutting down,
+// e.g. to report its readiness to a load balancer.
+func (s *Server) Ready() bool {
+	return s.ready.Load()
+}
+
+type (
+	// ServerOption configures the Server options when calling NewServer.
+	ServerOption interface {
+		apply(s *Server)
+	}
+
+	funcServerOption struct {
+		fn func(*Server)
+	}
+)
+
+func newFuncServerOption(fn func(*Server)) funcServerOption {
+	return funcServerOption{
+		fn: fn,
+	}
+}
+
+func (o funcServerOption) apply(s *Server) {
+	o.fn(s)
+}
+
+// ServerAddr returns a ServerOption that configures the TCP address to listen on.
+func ServerAddr(addr string) ServerOption {
+	return newFuncServerOption(func(s *Server) {
+		s.Addr = addr
+	})
+}
+
+// ServerIdleTimeout returns a ServerOption that configures the max amount of time to wait
+// for the next request when keep-alives are enabled. Value must be > 0, otherwise it panics.
+func ServerIdleTimeout(timeout time.Duration) ServerOption {
+	if timeout <= 0 {
+		panic("invalid idle timeout value")
+	}
+	return newFuncServerOption(func(s *Server) {
+		s.IdleTimeout = timeout
+	})
+}
+
+// ServerReadHeaderTimeout returns a ServerOption that configures the amount of time allowed
+// to read request headers. Value must be > 0, otherwise it panics.
+func ServerReadHeaderTimeout(timeout time.Duration) ServerOption {
+	if timeout <= 0 {
+		panic("invalid read header timeout value")
+	}
+	return newFuncServerOption(func(s *Server) {
+		s.ReadHeaderTimeout = timeout
+	})
+}
+
+// ServerReadTimeout returns a ServerOption that configures the max duration for reading
+// the entire request, including the body. Value must be > 0, otherwise it panics.
+func ServerReadTimeout(timeout time.Duration) ServerOption {
+	if timeout <= 0 {
+		panic("invalid read timeout value")
+	}
+	return newFuncServerOption(func(s *Server) {
+		s.ReadTimeout = timeout
+	})
+}
+
+// ServerShutdownDelay returns a ServerOption that configures the amount of time to wait, once
+// the server is marked as not ready, before shutting it down, e.g. to let load balancers stop
+// routing requests to it. Value must be >= 0, otherwise it panics.
+func ServerShutdownDelay(delay time.Duration) ServerOption {
+	if delay < 0 {
+		panic("invalid shutdown delay value")
+	}
+	return newFuncServerOption(func(s *Server) {
+		s.shutdownDelay = delay
+	})
+}
+
+// ServerShutdownGracePeriod returns a ServerOption that configures the max amount of time to wait
+// for in-flight requests to complete on shutdown. Value must be > 0, otherwise it panics.
+func ServerShutdownGracePeriod(period time.Duration) ServerOption {
+	if period <= 0 {
+		panic("invalid shutdown grace period value")
+	}
+	return newFuncServerOption(func(s *Server) {
+		s.shutdownGracePeriod = period
+	})
+}
+
+// ServerWriteTimeout returns a ServerOption that configures the max duration before timing out
+// writes of the response. Value must be > 0, otherwise it panics.
+func ServerWriteTimeout(timeout time.Duration) ServerOption {
+	if timeout <= 0 {
+		panic("invalid write timeout value")
+	}
+	return newFuncServerOption(func(s *Server) {
+		s.WriteTimeout = timeout
+	})
+}
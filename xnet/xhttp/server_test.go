@@ -0,0 +1,148 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestNewServer(t *testing.T) {
+	s := xhttp.NewServer(http.NotFoundHandler(),
+		xhttp.ServerAddr(":8080"),
+		xhttp.ServerReadHeaderTimeout(time.Second),
+		xhttp.ServerReadTimeout(2*time.Second),
+		xhttp.ServerWriteTimeout(3*time.Second),
+		xhttp.ServerIdleTimeout(4*time.Second))
+
+	if s.Addr != ":8080" {
+		t.Errorf("addr mismatch: %q", s.Addr)
+	}
+	if s.ReadHeaderTimeout != time.Second || s.ReadTimeout != 2*time.Second ||
+		s.WriteTimeout != 3*time.Second || s.IdleTimeout != 4*time.Second {
+		t.Errorf("timeouts mismatch: %s, %s, %s, %s", s.ReadHeaderTimeout, s.ReadTimeout, s.WriteTimeout, s.IdleTimeout)
+	}
+	if s.Ready() {
+		t.Error("expected server not to be ready before serving")
+	}
+}
+
+func TestServer_ServeContext(t *testing.T) {
+	testCases := []struct {
+		name          string
+		gracePeriod   time.Duration
+		handlerDelay  time.Duration
+		expectedErr   error
+		expectedReply bool
+	}{
+		{
+			name:          "in-flight request drained",
+			gracePeriod:   time.Second,
+			handlerDelay:  50 * time.Millisecond,
+			expectedReply: true,
+		},
+		{
+			name:         "grace period exceeded",
+			gracePeriod:  10 * time.Millisecond,
+			handlerDelay: time.Second,
+			expectedErr:  context.DeadlineExceeded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			started := make(chan struct{})
+			s := xhttp.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(started)
+				select {
+				case <-time.After(tc.handlerDelay):
+				case <-r.Context().Done():
+				}
+				io.WriteString(w, "done")
+			}), xhttp.ServerShutdownGracePeriod(tc.gracePeriod), xhttp.ServerShutdownDelay(time.Millisecond))
+
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			serveErr := make(chan error, 1)
+			go func() {
+				serveErr <- s.ServeContext(ctx, l)
+			}()
+
+			replied := make(chan bool, 1)
+			go func() {
+				resp, err := http.Get("http://" + l.Addr().String())
+				if err != nil {
+					replied <- false
+					return
+				}
+				defer resp.Body.Close()
+				b, _ := io.ReadAll(resp.Body)
+				replied <- string(b) == "done"
+			}()
+
+			<-started
+			if !s.Ready() {
+				t.Error("expected server to be ready while serving")
+			}
+			cancel()
+
+			if err := <-serveErr; err != tc.expectedErr {
+				t.Errorf("error mismatch: %v != %v", err, tc.expectedErr)
+			}
+			if s.Ready() {
+				t.Error("expected server not to be ready after shutdown")
+			}
+			if got := <-replied; got != tc.expectedReply {
+				t.Errorf("reply mismatch: expected %t; got %t", tc.expectedReply, got)
+			}
+		})
+	}
+}
+
+func TestServer_ListenAndServeContext(t *testing.T) {
+	s := xhttp.NewServer(http.NotFoundHandler(), xhttp.ServerAddr("invalid address"))
+
+	if err := s.ListenAndServeContext(context.Background()); err == nil {
+		t.Error("expected listen error; got none")
+	}
+}
+
+func TestServerOptions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func() xhttp.ServerOption
+		panic bool
+	}{
+		{name: "idle timeout panic", fn: func() xhttp.ServerOption { return xhttp.ServerIdleTimeout(0) }, panic: true},
+		{name: "idle timeout valid", fn: func() xhttp.ServerOption { return xhttp.ServerIdleTimeout(1) }},
+		{name: "read header timeout panic", fn: func() xhttp.ServerOption { return xhttp.ServerReadHeaderTimeout(0) }, panic: true},
+		{name: "read header timeout valid", fn: func() xhttp.ServerOption { return xhttp.ServerReadHeaderTimeout(1) }},
+		{name: "read timeout panic", fn: func() xhttp.ServerOption { return xhttp.ServerReadTimeout(0) }, panic: true},
+		{name: "read timeout valid", fn: func() xhttp.ServerOption { return xhttp.ServerReadTimeout(1) }},
+		{name: "shutdown delay panic", fn: func() xhttp.ServerOption { return xhttp.ServerShutdownDelay(-1) }, panic: true},
+		{name: "shutdown delay valid", fn: func() xhttp.ServerOption { return xhttp.ServerShutdownDelay(0) }},
+		{name: "shutdown grace period panic", fn: func() xhttp.ServerOption { return xhttp.ServerShutdownGracePeriod(0) }, panic: true},
+		{name: "shutdown grace period valid", fn: func() xhttp.ServerOption { return xhttp.ServerShutdownGracePeriod(1) }},
+		{name: "write timeout panic", fn: func() xhttp.ServerOption { return xhttp.ServerWriteTimeout(0) }, panic: true},
+		{name: "write timeout valid", fn: func() xhttp.ServerOption { return xhttp.ServerWriteTimeout(1) }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, tc.fn)
+		})
+	}
+}
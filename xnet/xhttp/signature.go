@@ -0,0 +1,233 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jlourenc/xgo/xerrors"
+	"github.com/jlourenc/xgo/xio"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+const (
+	signatureDefaultHeader      = "X-Signature"
+	signatureDefaultClockSkew   = 5 * time.Minute
+	signatureDefaultMaxBodySize = 10 * xunit.MiB
+
+	signatureParamKeyID     = "keyId"
+	signatureParamSignature = "signature"
+)
+
+// ErrInvalidSignature is the error reported by VerifySignature when a request signature is missing or invalid.
+var ErrInvalidSignature = errors.New("invalid request signature")
+
+type (
+	// SigningKeyFunc returns the secret key identified by keyID, empty if none was sent, to verify
+	// request signatures. It returns an error if the key is unknown.
+	SigningKeyFunc func(keyID string) ([]byte, error)
+
+	// ReplayCheckFunc is called by VerifySignature for each request with a valid signature, along with the
+	// request date, and returns an error if the signature was already seen, e.g. by storing signatures
+	// for the duration of the tolerated clock skew.
+	ReplayCheckFunc func(ctx context.Context, signature string, date time.Time) error
+)
+
+type verifySignature struct {
+	keys        SigningKeyFunc
+	header      string
+	clockSkew   time.Duration
+	maxBodySize xunit.Byte
+	replayCheck ReplayCheckFunc
+}
+
+// VerifySignature returns a Middleware authenticating requests signed by a SigningTransport with the keys
+// returned by keys, configured with the options passed in input. Requests whose signature is missing or
+// invalid, or whose Date header differs from the current time by more than the tolerated clock skew,
+// 5 minutes by default, are replied to with 401 Unauthorized.
+//
+// The request body is read in memory to compute its digest, up to 10MiB by default. Requests with a larger
+// body are replied to with 413 Content Too Large. Keys must not be nil, otherwise it panics.
+func VerifySignature(keys SigningKeyFunc, options ...VerifySignatureOption) Middleware {
+	if keys == nil {
+		panic("signing key func is nil")
+	}
+
+	v := &verifySignature{
+		keys:        keys,
+		header:      signatureDefaultHeader,
+		clockSkew:   signatureDefaultClockSkew,
+		maxBodySize: signatureDefaultMaxBodySize,
+	}
+
+	for _, opt := range options {
+		opt.apply(v)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := v.verify(w, r); err != nil {
+				if !errors.Is(err, ErrBodyTooLarge) {
+					err = xerrors.WithHTTPStatus(err, http.StatusUnauthorized)
+				}
+				WriteError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (v *verifySignature) verify(w http.ResponseWriter, r *http.Request) error {
+	params := map[string]string{}
+	for _, p := range splitHeaderList(r.Header.Values(v.header)) {
+		name, value, _ := strings.Cut(p, "=")
+		params[strings.TrimSpace(name)] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(params[signatureParamSignature])
+	if err != nil || len(signature) == 0 {
+		return ErrInvalidSignature
+	}
+
+	date, err := http.ParseTime(r.Header.Get(HeaderDate))
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if skew := time.Since(date); skew > v.clockSkew || skew < -v.clockSkew {
+		return ErrInvalidSignature
+	}
+
+	key, err := v.keys(params[signatureParamKeyID])
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	digest, err := readRequestBodyDigest(w, r, v.maxBodySize)
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal(signature, computeSignature(key, r.Method, r.Host, r.URL.RequestURI(), r.Header.Get(HeaderDate), digest)) {
+		return ErrInvalidSignature
+	}
+
+	if v.replayCheck != nil {
+		if err := v.replayCheck(r.Context(), params[signatureParamSignature], date); err != nil {
+			return xerrors.Wrap(err, ErrInvalidSignature.Error())
+		}
+	}
+
+	return nil
+}
+
+// computeSignature returns the HMAC-SHA256 of the canonical representation of a request.
+func computeSignature(key []byte, method, host, requestURI, date, digest string) []byte {
+	mac := hmac.New(sha256.New, key)
+	io.WriteString(mac, strings.Join([]string{method, strings.ToLower(host), requestURI, date, digest}, "\n"))
+	return mac.Sum(nil)
+}
+
+// readBodyDigest returns the base64 encoded SHA-256 digest of the body, which is replaced by an
+// equivalent body to be read again.
+func readBodyDigest(body *io.ReadCloser) (string, error) {
+	h := sha256.New()
+	if *body != nil && *body != http.NoBody {
+		rc1, rc2, err := xio.DuplicateReadCloser(*body)
+		if err != nil {
+			return "", err
+		}
+		*body = rc2
+		io.Copy(h, rc1) //nolint:errcheck // reading from memory does not fail.
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// readRequestBodyDigest acts like readBodyDigest on the body of r, limited to limit bytes.
+// It returns ErrBodyTooLarge, with a 413 status, if the body is larger.
+func readRequestBodyDigest(w http.ResponseWriter, r *http.Request, limit xunit.Byte) (string, error) {
+	if err := limitRequestBody(w, r, limit); err != nil {
+		return "", err
+	}
+
+	digest, err := readBodyDigest(&r.Body)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return "", xerrors.WithHTTPStatus(ErrBodyTooLarge, http.StatusRequestEntityTooLarge)
+	}
+	return digest, err
+}
+
+type (
+	// VerifySignatureOption configures the VerifySignature middleware options when calling VerifySignature.
+	VerifySignatureOption interface {
+		apply(v *verifySignature)
+	}
+
+	funcVerifySignatureOption struct {
+		fn func(*verifySignature)
+	}
+)
+
+func newFuncVerifySignatureOption(fn func(*verifySignature)) funcVerifySignatureOption {
+	return funcVerifySignatureOption{
+		fn: fn,
+	}
+}
+
+func (o funcVerifySignatureOption) apply(v *verifySignature) {
+	o.fn(v)
+}
+
+// VerifySignatureClockSkew returns a VerifySignatureOption that configures the max difference tolerated
+// between the Date header of requests and the current time. Value must be > 0, otherwise it panics.
+func VerifySignatureClockSkew(skew time.Duration) VerifySignatureOption {
+	if skew <= 0 {
+		panic("invalid clock skew value")
+	}
+	return newFuncVerifySignatureOption(func(v *verifySignature) {
+		v.clockSkew = skew
+	})
+}
+
+// VerifySignatureHeader returns a VerifySignatureOption that configures the header conveying the signature.
+// If not used, X-Signature is used.
+func VerifySignatureHeader(header string) VerifySignatureOption {
+	if header == "" {
+		panic("empty header")
+	}
+	return newFuncVerifySignatureOption(func(v *verifySignature) {
+		v.header = header
+	})
+}
+
+// VerifySignatureMaxBodySize returns a VerifySignatureOption that configures the max size of the request
+// bodies read to compute their digest. Value must be >= 0, otherwise it panics.
+func VerifySignatureMaxBodySize(size xunit.Byte) VerifySignatureOption {
+	if size < 0 {
+		panic("invalid max body size value")
+	}
+	return newFuncVerifySignatureOption(func(v *verifySignature) {
+		v.maxBodySize = size
+	})
+}
+
+// VerifySignatureReplayCheck returns a VerifySignatureOption that configures the function called to
+// reject replayed requests. Value must not be nil, otherwise it panics.
+func VerifySignatureReplayCheck(fn ReplayCheckFunc) VerifySignatureOption {
+	if fn == nil {
+		panic("replay check func is nil")
+	}
+	return newFuncVerifySignatureOption(func(v *verifySignature) {
+		v.replayCheck = fn
+	})
+}
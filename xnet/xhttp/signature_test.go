@@ -0,0 +1,239 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+var errUnknownKey = errors.New("unknown key")
+
+func signingKeys(keyID string) ([]byte, error) {
+	switch keyID {
+	case "", "k1":
+		return []byte("secret"), nil
+	default:
+		return nil, errUnknownKey
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	testCases := []struct {
+		name           string
+		key            string
+		signOptions    []xhttp.SigningTransportOption
+		verifyOptions  []xhttp.VerifySignatureOption
+		date           time.Time
+		tamper         func(r *http.Request)
+		expectedStatus int
+	}{
+		{
+			name:           "valid signature",
+			key:            "secret",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "valid signature with key ID",
+			key:            "secret",
+			signOptions:    []xhttp.SigningTransportOption{xhttp.SigningTransportKeyID("k1")},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "custom header",
+			key:            "secret",
+			signOptions:    []xhttp.SigningTransportOption{xhttp.SigningTransportHeader("X-Custom-Signature")},
+			verifyOptions:  []xhttp.VerifySignatureOption{xhttp.VerifySignatureHeader("X-Custom-Signature")},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown key ID",
+			key:            "secret",
+			signOptions:    []xhttp.SigningTransportOption{xhttp.SigningTransportKeyID("k2")},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "wrong key",
+			key:            "wrong",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "missing signature",
+			key:            "secret",
+			signOptions:    []xhttp.SigningTransportOption{xhttp.SigningTransportHeader("X-Other")},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "date too old",
+			key:            "secret",
+			date:           time.Now().Add(-10 * time.Minute),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "date within clock skew",
+			key:            "secret",
+			verifyOptions:  []xhttp.VerifySignatureOption{xhttp.VerifySignatureClockSkew(time.Hour)},
+			date:           time.Now().Add(-10 * time.Minute),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "body within max size",
+			key:            "secret",
+			verifyOptions:  []xhttp.VerifySignatureOption{xhttp.VerifySignatureMaxBodySize(4 * xunit.B)},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "body too large",
+			key:            "secret",
+			verifyOptions:  []xhttp.VerifySignatureOption{xhttp.VerifySignatureMaxBodySize(3 * xunit.B)},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:          "body too large without content length",
+			key:           "secret",
+			verifyOptions: []xhttp.VerifySignatureOption{xhttp.VerifySignatureMaxBodySize(3 * xunit.B)},
+			tamper: func(r *http.Request) {
+				r.ContentLength = -1
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "tampered body",
+			key:  "secret",
+			tamper: func(r *http.Request) {
+				r.Body = io.NopCloser(strings.NewReader("tampered"))
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "tampered path",
+			key:  "secret",
+			tamper: func(r *http.Request) {
+				r.URL.Path = "/other"
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "replayed request",
+			key:  "secret",
+			verifyOptions: []xhttp.VerifySignatureOption{xhttp.VerifySignatureReplayCheck(func(context.Context, string, time.Time) error {
+				return errors.New("replayed")
+			})},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := xhttp.VerifySignature(signingKeys, tc.verifyOptions...).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+				if b, _ := io.ReadAll(r.Body); string(b) != "body" {
+					t.Errorf("body mismatch: expected %q; got %q", "body", b)
+				}
+			})
+
+			var signed *http.Request
+			options := append([]xhttp.SigningTransportOption{
+				xhttp.SigningTransportNextRoundTripper(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					signed = r
+					return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+				})),
+			}, tc.signOptions...)
+			transp := xhttp.NewSigningTransport([]byte(tc.key), options...)
+
+			req := httptest.NewRequest(http.MethodPost, "http://example.com/path?q=1", strings.NewReader("body"))
+			if !tc.date.IsZero() {
+				req.Header.Set(xhttp.HeaderDate, tc.date.UTC().Format(http.TimeFormat))
+			}
+			if _, err := transp.RoundTrip(req); err != nil {
+				t.Fatal(err)
+			}
+			if tc.tamper != nil {
+				tc.tamper(signed)
+			}
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, signed)
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("status code mismatch: expected %d; got %d", tc.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestVerifySignature_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+
+	xhttp.VerifySignature(nil)
+}
+
+func TestVerifySignatureOptions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func() xhttp.VerifySignatureOption
+		panic bool
+	}{
+		{
+			name:  "invalid clock skew",
+			fn:    func() xhttp.VerifySignatureOption { return xhttp.VerifySignatureClockSkew(0) },
+			panic: true,
+		},
+		{
+			name:  "valid clock skew",
+			fn:    func() xhttp.VerifySignatureOption { return xhttp.VerifySignatureClockSkew(time.Minute) },
+			panic: false,
+		},
+		{
+			name:  "empty header",
+			fn:    func() xhttp.VerifySignatureOption { return xhttp.VerifySignatureHeader("") },
+			panic: true,
+		},
+		{
+			name:  "valid header",
+			fn:    func() xhttp.VerifySignatureOption { return xhttp.VerifySignatureHeader("X-Sig") },
+			panic: false,
+		},
+		{
+			name:  "invalid max body size",
+			fn:    func() xhttp.VerifySignatureOption { return xhttp.VerifySignatureMaxBodySize(-1) },
+			panic: true,
+		},
+		{
+			name:  "valid max body size",
+			fn:    func() xhttp.VerifySignatureOption { return xhttp.VerifySignatureMaxBodySize(xunit.KiB) },
+			panic: false,
+		},
+		{
+			name:  "nil replay check",
+			fn:    func() xhttp.VerifySignatureOption { return xhttp.VerifySignatureReplayCheck(nil) },
+			panic: true,
+		},
+		{
+			name: "valid replay check",
+			fn: func() xhttp.VerifySignatureOption {
+				return xhttp.VerifySignatureReplayCheck(func(context.Context, string, time.Time) error { return nil })
+			},
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, tc.fn)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"encoding/base64"
+	"net/http"
+	"time"
+)
+
+// SigningTransport is an HTTP transport that signs requests with an HMAC-SHA256 of their method, host,
+// request URI, Date header and body digest, to be verified by the VerifySignature middleware.
+type signingTransport struct {
+	next   http.RoundTripper
+	key    []byte
+	keyID  string
+	header string
+}
+
+// NewSigningTransport creates a new SigningTransport signing requests with key, configured with the options
+// passed in input, notably the next round tripper in the chain. The signature is conveyed by the X-Signature
+// header by default, e.g. `X-Signature: keyId="k1", signature="base64"`.
+// Key must not be empty, otherwise it panics.
+func NewSigningTransport(key []byte, options ...SigningTransportOption) http.RoundTripper {
+	if len(key) == 0 {
+		panic("empty signing key")
+	}
+
+	t := &signingTransport{
+		next:   http.DefaultTransport,
+		key:    key,
+		header: signatureDefaultHeader,
+	}
+
+	for _, opt := range options {
+		opt.apply(t)
+	}
+
+	return t
+}
+
+// RoundTrip makes SigningTransport implement the RoundTripper interface.
+//
+// The Date header is set to the current time if missing and the request body is read in memory
+// to compute its digest.
+func (t *signingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+
+	date := r.Header.Get(HeaderDate)
+	if date == "" {
+		date = time.Now().UTC().Format(http.TimeFormat)
+		r.Header.Set(HeaderDate, date)
+	}
+
+	digest, err := readBodyDigest(&r.Body)
+	if err != nil {
+		closeRequestBody(req)
+		return nil, err
+	}
+
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+
+	signature := base64.StdEncoding.EncodeToString(computeSignature(t.key, r.Method, host, r.URL.RequestURI(), date, digest))
+
+	value := signatureParamSignature + "=" + quoteString(signature)
+	if t.keyID != "" {
+		value = signatureParamKeyID + "=" + quoteString(t.keyID) + ", " + value
+	}
+	r.Header.Set(t.header, value)
+
+	return t.next.RoundTrip(r)
+}
+
+type (
+	// SigningTransportOption configures the SigningTransport options when calling NewSigningTransport.
+	SigningTransportOption interface {
+		apply(t *signingTransport)
+	}
+
+	funcSigningTransportOption struct {
+		fn func(*signingTransport)
+	}
+)
+
+func newFuncSigningTransportOption(fn func(*signingTransport)) funcSigningTransportOption {
+	return funcSigningTransportOption{
+		fn: fn,
+	}
+}
+
+func (o funcSigningTransportOption) apply(t *signingTransport) {
+	o.fn(t)
+}
+
+// SigningTransportHeader returns a SigningTransportOption that configures the header conveying the signature.
+// If not used, X-Signature is used.
+func SigningTransportHeader(header string) SigningTransportOption {
+	if header == "" {
+		panic("empty header")
+	}
+	return newFuncSigningTransportOption(func(t *signingTransport) {
+		t.header = header
+	})
+}
+
+// SigningTransportKeyID returns a SigningTransportOption that configures the identifier of the key sent
+// along with the signature, e.g. to allow key rotation.
+func SigningTransportKeyID(keyID string) SigningTransportOption {
+	return newFuncSigningTransportOption(func(t *signingTransport) {
+		t.keyID = keyID
+	})
+}
+
+// SigningTransportNextRoundTripper returns a SigningTransportOption that configures the
+// next round tripper to call. If not used http.DefaultTransport will be used.
+func SigningTransportNextRoundTripper(next http.RoundTripper) SigningTransportOption {
+	if next == nil {
+		panic("next http.RoundTripper is nil")
+	}
+	return newFuncSigningTransportOption(func(t *signingTransport) {
+		t.next = next
+	})
+}
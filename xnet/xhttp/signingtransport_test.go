@@ -0,0 +1,129 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestSigningTransport_RoundTrip(t *testing.T) {
+	srv := httptest.NewServer(xhttp.VerifySignature(signingKeys).ThenFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	client := http.Client{Transport: xhttp.NewSigningTransport([]byte("secret"), xhttp.SigningTransportNextRoundTripper(srv.Client().Transport))}
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		req, err := http.NewRequest(method, srv.URL+"/path?q=1", strings.NewReader("body"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: status code mismatch: expected %d; got %d", method, http.StatusOK, resp.StatusCode)
+		}
+		if req.Header.Get("X-Signature") != "" || req.Header.Get(xhttp.HeaderDate) != "" {
+			t.Errorf("%s: expected original request not to be modified", method)
+		}
+	}
+}
+
+func TestSigningTransport_RoundTrip_Error(t *testing.T) {
+	errRead := errors.New("read error")
+
+	testCases := []struct {
+		name          string
+		body          io.Reader
+		expectedError error
+	}{
+		{
+			name:          "next round tripper error",
+			body:          strings.NewReader("body"),
+			expectedError: errNoResponse,
+		},
+		{
+			name:          "body read error",
+			body:          iotest.ErrReader(errRead),
+			expectedError: errRead,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			transp := xhttp.NewSigningTransport([]byte("secret"), xhttp.SigningTransportNextRoundTripper(&fakeTransport{}))
+
+			req, err := http.NewRequest(http.MethodPost, "http://example.com", tc.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err = transp.RoundTrip(req); err != tc.expectedError {
+				t.Errorf("error mismatch: %v != %v", err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestNewSigningTransport_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+
+	xhttp.NewSigningTransport(nil)
+}
+
+func TestSigningTransportOptions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func() xhttp.SigningTransportOption
+		panic bool
+	}{
+		{
+			name:  "empty header",
+			fn:    func() xhttp.SigningTransportOption { return xhttp.SigningTransportHeader("") },
+			panic: true,
+		},
+		{
+			name:  "valid header",
+			fn:    func() xhttp.SigningTransportOption { return xhttp.SigningTransportHeader("X-Sig") },
+			panic: false,
+		},
+		{
+			name:  "key ID",
+			fn:    func() xhttp.SigningTransportOption { return xhttp.SigningTransportKeyID("k1") },
+			panic: false,
+		},
+		{
+			name:  "nil next round tripper",
+			fn:    func() xhttp.SigningTransportOption { return xhttp.SigningTransportNextRoundTripper(nil) },
+			panic: true,
+		},
+		{
+			name:  "valid next round tripper",
+			fn:    func() xhttp.SigningTransportOption { return xhttp.SigningTransportNextRoundTripper(&fakeTransport{}) },
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, tc.fn)
+		})
+	}
+}
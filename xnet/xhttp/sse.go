@@ -0,0 +1,391 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jlourenc/xgo/xio"
+)
+
+const (
+	sseMediaType                = "text/event-stream"
+	sseDefaultReconnectDelay    = 3 * time.Second
+	sseDefaultMaxReconnectDelay = time.Minute
+	sseMaxLineSize              = 1 << 20
+)
+
+// ErrEventStreamNotSupported is returned by SubscribeEvents when the server replies with an unexpected
+// status code or media type, in which case no reconnection is attempted.
+var ErrEventStreamNotSupported = errors.New("event stream not supported")
+
+// ServerSentEvent is an event of a Server-Sent Events stream.
+// https://html.spec.whatwg.org/multipage/server-sent-events.html
+type ServerSentEvent struct {
+	// ID is the event ID. When reading, it is the last event ID of the stream.
+	ID string
+
+	// Type is the event type. An empty type denotes the default "message" type.
+	Type string
+
+	// Data is the event payload. Lines are separated by "\n".
+	Data string
+
+	// Retry is the reconnection time hint sent along with the event, if any.
+	Retry time.Duration
+}
+
+// EventWriter writes Server-Sent Events to a response, flushing each of them to the client.
+// It is safe for concurrent use by multiple goroutines.
+type EventWriter struct {
+	mu          sync.Mutex
+	w           http.ResponseWriter
+	rc          *http.ResponseController
+	wroteHeader bool
+}
+
+// NewEventWriter creates a new EventWriter writing events to w. The response headers are
+// written along with the first event.
+func NewEventWriter(w http.ResponseWriter) *EventWriter {
+	h := w.Header()
+	h.Set(HeaderContentType, sseMediaType)
+	h.Set(HeaderCacheControl, CacheControlNoCache)
+
+	return &EventWriter{
+		w:  w,
+		rc: http.NewResponseController(w),
+	}
+}
+
+// Comment writes a comment, ignored by clients, e.g. to keep the connection alive.
+func (ew *EventWriter) Comment(text string) error {
+	var buf bytes.Buffer
+	for _, line := range splitEventLines(text) {
+		buf.WriteString(":" + line + "\n")
+	}
+	buf.WriteString("\n")
+	return ew.write(buf.Bytes())
+}
+
+// Retry writes a hint of the time clients should wait before reconnecting.
+func (ew *EventWriter) Retry(d time.Duration) error {
+	return ew.write([]byte("retry:" + strconv.FormatInt(d.Milliseconds(), 10) + "\n\n"))
+}
+
+// Send writes the event e.
+func (ew *EventWriter) Send(e ServerSentEvent) error {
+	var buf bytes.Buffer
+	if e.ID != "" {
+		buf.WriteString("id:" + strings.NewReplacer("\r", "", "\n", "", "\x00", "").Replace(e.ID) + "\n")
+	}
+	if e.Type != "" {
+		buf.WriteString("event:" + strings.NewReplacer("\r", "", "\n", "").Replace(e.Type) + "\n")
+	}
+	if e.Retry > 0 {
+		buf.WriteString("retry:" + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+	for _, line := range splitEventLines(e.Data) {
+		buf.WriteString("data:" + line + "\n")
+	}
+	buf.WriteString("\n")
+	return ew.write(buf.Bytes())
+}
+
+func (ew *EventWriter) write(b []byte) error {
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+
+	if !ew.wroteHeader {
+		ew.w.WriteHeader(http.StatusOK)
+		ew.wroteHeader = true
+	}
+
+	if _, err := ew.w.Write(b); err != nil {
+		return err
+	}
+	return ew.rc.Flush()
+}
+
+func splitEventLines(s string) []string {
+	return strings.Split(strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(s), "\n")
+}
+
+// EventReader reads Server-Sent Events from a stream, e.g. the body of a response.
+type EventReader struct {
+	sc          *bufio.Scanner
+	lastEventID string
+	retry       time.Duration
+}
+
+// NewEventReader creates a new EventReader reading events from r.
+func NewEventReader(r io.Reader) *EventReader {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, sseMaxLineSize)
+	sc.Split(scanEventLines)
+
+	return &EventReader{
+		sc: sc,
+	}
+}
+
+// Next returns the next event of the stream. It returns io.EOF once the stream ends,
+// discarding an incomplete event if any.
+// https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation
+func (er *EventReader) Next() (ServerSentEvent, error) {
+	var (
+		e       ServerSentEvent
+		data    strings.Builder
+		hasData bool
+	)
+
+	for er.sc.Scan() {
+		line := er.sc.Text()
+
+		if line == "" {
+			if !hasData {
+				e = ServerSentEvent{}
+				continue
+			}
+			e.ID = er.lastEventID
+			e.Data = strings.TrimSuffix(data.String(), "\n")
+			return e, nil
+		}
+
+		if line[0] == ':' {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			e.Type = value
+		case "data":
+			data.WriteString(value + "\n")
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				er.lastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(value, 10, 63); err == nil {
+				e.Retry = time.Duration(ms) * time.Millisecond
+				er.retry = e.Retry
+			}
+		}
+	}
+
+	if err := er.sc.Err(); err != nil {
+		return ServerSentEvent{}, err
+	}
+	return ServerSentEvent{}, io.EOF
+}
+
+// scanEventLines is a bufio.SplitFunc splitting lines ended by "\r\n", "\n" or "\r".
+func scanEventLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil //nolint:gomnd // skip "\r\n".
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		// A "\n" may follow.
+		return 0, nil, nil
+	}
+
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+type subscribeEvents struct {
+	client            *http.Client
+	reconnectDelay    time.Duration
+	maxReconnectDelay time.Duration
+}
+
+// SubscribeEvents subscribes to the Server-Sent Events stream at url, calling fn for each event,
+// configured with the options passed in input. When the stream ends, the connection fails or the
+// server replies with a retryable status code, it reconnects after the reconnection delay, sending
+// the last event ID received. The delay, which the server may set, doubles after each failed
+// attempt up to the max reconnection delay.
+// By default, requests are sent with a RetryTransport and the delay is 3s, up to 1min.
+//
+// It returns when ctx is done (ctx.Err()), fn returns an error (that error), the server replies with
+// 204 No Content (nil), or with another non-retryable status code than 200 OK or an unexpected
+// media type (ErrEventStreamNotSupported).
+func SubscribeEvents(ctx context.Context, url string, fn func(ServerSentEvent) error, options ...SubscribeEventsOption) error {
+	s := &subscribeEvents{
+		reconnectDelay:    sseDefaultReconnectDelay,
+		maxReconnectDelay: sseDefaultMaxReconnectDelay,
+	}
+
+	for _, opt := range options {
+		opt.apply(s)
+	}
+
+	if s.client == nil {
+		s.client = &http.Client{Transport: NewRetryTransport()}
+	}
+
+	var (
+		lastEventID string
+		baseDelay   = s.reconnectDelay
+		delay       time.Duration
+		failed      bool
+	)
+	for {
+		received, retry, stop, err := s.subscribe(ctx, url, &lastEventID, fn)
+		if stop {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if retry > 0 {
+			baseDelay = retry
+		}
+		if failed && !received {
+			delay = min(2*delay, s.maxReconnectDelay)
+		} else {
+			delay = min(baseDelay, s.maxReconnectDelay)
+		}
+		failed = !received
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// subscribe reads the event stream at url until it ends. It returns whether events were received,
+// the reconnection time sent by the server, if any, and whether to stop subscribing along with the reason.
+func (s *subscribeEvents) subscribe(
+	ctx context.Context, url string, lastEventID *string, fn func(ServerSentEvent) error,
+) (received bool, retry time.Duration, stop bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return false, 0, true, err
+	}
+	req.Header.Set(HeaderAccept, sseMediaType)
+	req.Header.Set(HeaderCacheControl, CacheControlNoStore)
+	if *lastEventID != "" {
+		req.Header.Set(HeaderLastEventID, *lastEventID)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return false, 0, false, nil
+	}
+	defer xio.DrainClose(resp.Body) //nolint:errcheck // the stream is over.
+
+	if resp.StatusCode == http.StatusNoContent {
+		return false, 0, true, nil
+	}
+	if isResponseRetryable(resp) {
+		return false, 0, false, nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get(HeaderContentType))
+	if resp.StatusCode != http.StatusOK || mediaType != sseMediaType {
+		return false, 0, true, fmt.Errorf("%w: status %d, content type %q", ErrEventStreamNotSupported, resp.StatusCode, mediaType)
+	}
+
+	er := NewEventReader(resp.Body)
+	er.lastEventID = *lastEventID
+
+	for {
+		e, err := er.Next()
+		*lastEventID = er.lastEventID
+		if err != nil {
+			return received, er.retry, false, nil
+		}
+		received = true
+		if err := fn(e); err != nil {
+			return received, er.retry, true, err
+		}
+	}
+}
+
+type (
+	// SubscribeEventsOption configures the SubscribeEvents options when calling SubscribeEvents.
+	SubscribeEventsOption interface {
+		apply(s *subscribeEvents)
+	}
+
+	funcSubscribeEventsOption struct {
+		fn func(*subscribeEvents)
+	}
+)
+
+func newFuncSubscribeEventsOption(fn func(*subscribeEvents)) funcSubscribeEventsOption {
+	return funcSubscribeEventsOption{
+		fn: fn,
+	}
+}
+
+func (o funcSubscribeEventsOption) apply(s *subscribeEvents) {
+	o.fn(s)
+}
+
+// SubscribeEventsClient returns a SubscribeEventsOption that configures the client used to subscribe.
+// It should not have a timeout, which would apply to the whole stream. Value must not be nil, otherwise it panics.
+func SubscribeEventsClient(client *http.Client) SubscribeEventsOption {
+	if client == nil {
+		panic("client is nil")
+	}
+	return newFuncSubscribeEventsOption(func(s *subscribeEvents) {
+		s.client = client
+	})
+}
+
+// SubscribeEventsMaxReconnectDelay returns a SubscribeEventsOption that configures the max delay
+// between reconnection attempts. Value must be > 0, otherwise it panics.
+func SubscribeEventsMaxReconnectDelay(delay time.Duration) SubscribeEventsOption {
+	if delay <= 0 {
+		panic("invalid max reconnect delay value")
+	}
+	return newFuncSubscribeEventsOption(func(s *subscribeEvents) {
+		s.maxReconnectDelay = delay
+	})
+}
+
+// SubscribeEventsReconnectDelay returns a SubscribeEventsOption that configures the initial delay
+// before reconnecting, unless set by the server. Value must be > 0, otherwise it panics.
+func SubscribeEventsReconnectDelay(delay time.Duration) SubscribeEventsOption {
+	if delay <= 0 {
+		panic("invalid reconnect delay value")
+	}
+	return newFuncSubscribeEventsOption(func(s *subscribeEvents) {
+		s.reconnectDelay = delay
+	})
+}
@@ -0,0 +1,357 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestEventWriter(t *testing.T) {
+	testCases := []struct {
+		name         string
+		write        func(ew *xhttp.EventWriter) error
+		expectedBody string
+	}{
+		{
+			name: "data only",
+			write: func(ew *xhttp.EventWriter) error {
+				return ew.Send(xhttp.ServerSentEvent{Data: "hello"})
+			},
+			expectedBody: "data:hello\n\n",
+		},
+		{
+			name: "all fields",
+			write: func(ew *xhttp.EventWriter) error {
+				return ew.Send(xhttp.ServerSentEvent{ID: "1", Type: "update", Data: "hello", Retry: 2 * time.Second})
+			},
+			expectedBody: "id:1\nevent:update\nretry:2000\ndata:hello\n\n",
+		},
+		{
+			name: "multi-line data",
+			write: func(ew *xhttp.EventWriter) error {
+				return ew.Send(xhttp.ServerSentEvent{Data: "a\nb\r\nc\rd"})
+			},
+			expectedBody: "data:a\ndata:b\ndata:c\ndata:d\n\n",
+		},
+		{
+			name: "empty data",
+			write: func(ew *xhttp.EventWriter) error {
+				return ew.Send(xhttp.ServerSentEvent{})
+			},
+			expectedBody: "data:\n\n",
+		},
+		{
+			name: "id with line break",
+			write: func(ew *xhttp.EventWriter) error {
+				return ew.Send(xhttp.ServerSentEvent{ID: "1\n2", Data: "x"})
+			},
+			expectedBody: "id:12\ndata:x\n\n",
+		},
+		{
+			name: "comment",
+			write: func(ew *xhttp.EventWriter) error {
+				return ew.Comment("keep-alive")
+			},
+			expectedBody: ":keep-alive\n\n",
+		},
+		{
+			name: "retry",
+			write: func(ew *xhttp.EventWriter) error {
+				return ew.Retry(1500 * time.Millisecond)
+			},
+			expectedBody: "retry:1500\n\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			if err := tc.write(xhttp.NewEventWriter(w)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if w.Code != http.StatusOK {
+				t.Errorf("status code mismatch: expected %d; got %d", http.StatusOK, w.Code)
+			}
+			if ct := w.Header().Get(xhttp.HeaderContentType); ct != "text/event-stream" {
+				t.Errorf("content type mismatch: expected %q; got %q", "text/event-stream", ct)
+			}
+			if cc := w.Header().Get(xhttp.HeaderCacheControl); cc != "no-cache" {
+				t.Errorf("cache control mismatch: expected %q; got %q", "no-cache", cc)
+			}
+			if !w.Flushed {
+				t.Error("response not flushed")
+			}
+			if body := w.Body.String(); body != tc.expectedBody {
+				t.Errorf("body mismatch: expected %q; got %q", tc.expectedBody, body)
+			}
+		})
+	}
+}
+
+func TestEventWriter_FlushNotSupported(t *testing.T) {
+	w := struct{ http.ResponseWriter }{httptest.NewRecorder()}
+
+	err := xhttp.NewEventWriter(w).Send(xhttp.ServerSentEvent{Data: "hello"})
+	if !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("error mismatch: expected %v; got %v", http.ErrNotSupported, err)
+	}
+}
+
+func TestEventReader_Next(t *testing.T) {
+	testCases := []struct {
+		name           string
+		stream         string
+		expectedEvents []xhttp.ServerSentEvent
+	}{
+		{
+			name:           "empty stream",
+			stream:         "",
+			expectedEvents: nil,
+		},
+		{
+			name:           "single event",
+			stream:         "data: hello\n\n",
+			expectedEvents: []xhttp.ServerSentEvent{{Data: "hello"}},
+		},
+		{
+			name:           "all fields",
+			stream:         "id: 1\nevent: update\nretry: 2000\ndata: hello\n\n",
+			expectedEvents: []xhttp.ServerSentEvent{{ID: "1", Type: "update", Data: "hello", Retry: 2 * time.Second}},
+		},
+		{
+			name:           "multi-line data",
+			stream:         "data: a\ndata:b\ndata\n\n",
+			expectedEvents: []xhttp.ServerSentEvent{{Data: "a\nb\n"}},
+		},
+		{
+			name:           "crlf and cr line endings",
+			stream:         "data: a\r\ndata: b\r\r\ndata: c\r\r",
+			expectedEvents: []xhttp.ServerSentEvent{{Data: "a\nb"}, {Data: "c"}},
+		},
+		{
+			name:           "comments and unknown fields ignored",
+			stream:         ": comment\nfoo: bar\ndata: hello\n\n",
+			expectedEvents: []xhttp.ServerSentEvent{{Data: "hello"}},
+		},
+		{
+			name:           "events without data not dispatched",
+			stream:         "event: ping\n\ndata: hello\n\n",
+			expectedEvents: []xhttp.ServerSentEvent{{Data: "hello"}},
+		},
+		{
+			name:           "last event id persists",
+			stream:         "id: 1\ndata: a\n\ndata: b\n\nid\ndata: c\n\n",
+			expectedEvents: []xhttp.ServerSentEvent{{ID: "1", Data: "a"}, {ID: "1", Data: "b"}, {Data: "c"}},
+		},
+		{
+			name:           "id with null ignored",
+			stream:         "id: 1\x002\ndata: a\n\n",
+			expectedEvents: []xhttp.ServerSentEvent{{Data: "a"}},
+		},
+		{
+			name:           "invalid retry ignored",
+			stream:         "retry: 1s\ndata: a\n\n",
+			expectedEvents: []xhttp.ServerSentEvent{{Data: "a"}},
+		},
+		{
+			name:           "incomplete event discarded",
+			stream:         "data: a\n\ndata: b\n",
+			expectedEvents: []xhttp.ServerSentEvent{{Data: "a"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			er := xhttp.NewEventReader(strings.NewReader(tc.stream))
+
+			var events []xhttp.ServerSentEvent
+			for {
+				e, err := er.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				events = append(events, e)
+			}
+
+			if !reflect.DeepEqual(tc.expectedEvents, events) {
+				t.Errorf("events mismatch: expected %+v; got %+v", tc.expectedEvents, events)
+			}
+		})
+	}
+}
+
+func TestSubscribeEvents(t *testing.T) {
+	var (
+		mu           sync.Mutex
+		lastEventIDs []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		lastEventIDs = append(lastEventIDs, r.Header.Get(xhttp.HeaderLastEventID))
+		attempt := len(lastEventIDs)
+		mu.Unlock()
+
+		switch attempt {
+		case 1:
+			ew := xhttp.NewEventWriter(w)
+			ew.Retry(time.Millisecond)
+			ew.Send(xhttp.ServerSentEvent{ID: "1", Data: "a"})
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		case 3:
+			ew := xhttp.NewEventWriter(w)
+			ew.Send(xhttp.ServerSentEvent{ID: "2", Data: "b"})
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	var data []string
+	err := xhttp.SubscribeEvents(context.Background(), srv.URL, func(e xhttp.ServerSentEvent) error {
+		data = append(data, e.Data)
+		return nil
+	}, xhttp.SubscribeEventsClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := []string{"a", "b"}; !reflect.DeepEqual(expected, data) {
+		t.Errorf("data mismatch: expected %v; got %v", expected, data)
+	}
+	if expected := []string{"", "1", "1", "2"}; !reflect.DeepEqual(expected, lastEventIDs) {
+		t.Errorf("last event IDs mismatch: expected %v; got %v", expected, lastEventIDs)
+	}
+}
+
+func TestSubscribeEvents_Error(t *testing.T) {
+	errHandler := errors.New("handler error")
+
+	testCases := []struct {
+		name          string
+		handler       http.HandlerFunc
+		fn            func(e xhttp.ServerSentEvent) error
+		expectedError error
+	}{
+		{
+			name: "unexpected status code",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			expectedError: xhttp.ErrEventStreamNotSupported,
+		},
+		{
+			name: "unexpected media type",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(xhttp.HeaderContentType, "application/json")
+			},
+			expectedError: xhttp.ErrEventStreamNotSupported,
+		},
+		{
+			name: "handler error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				xhttp.NewEventWriter(w).Send(xhttp.ServerSentEvent{Data: "a"})
+			},
+			fn: func(e xhttp.ServerSentEvent) error {
+				return errHandler
+			},
+			expectedError: errHandler,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(tc.handler)
+			defer srv.Close()
+
+			fn := tc.fn
+			if fn == nil {
+				fn = func(xhttp.ServerSentEvent) error { return nil }
+			}
+
+			err := xhttp.SubscribeEvents(context.Background(), srv.URL, fn, xhttp.SubscribeEventsClient(srv.Client()))
+			if !errors.Is(err, tc.expectedError) {
+				t.Errorf("error mismatch: expected %v; got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestSubscribeEvents_ContextDone(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		xhttp.NewEventWriter(w).Send(xhttp.ServerSentEvent{Data: "a"})
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err := xhttp.SubscribeEvents(ctx, srv.URL, func(xhttp.ServerSentEvent) error {
+		cancel()
+		return nil
+	}, xhttp.SubscribeEventsClient(srv.Client()), xhttp.SubscribeEventsReconnectDelay(time.Hour))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error mismatch: expected %v; got %v", context.Canceled, err)
+	}
+}
+
+func TestSubscribeEventsOptions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func() xhttp.SubscribeEventsOption
+		panic bool
+	}{
+		{
+			name:  "client nil",
+			fn:    func() xhttp.SubscribeEventsOption { return xhttp.SubscribeEventsClient(nil) },
+			panic: true,
+		},
+		{
+			name:  "client valid",
+			fn:    func() xhttp.SubscribeEventsOption { return xhttp.SubscribeEventsClient(http.DefaultClient) },
+			panic: false,
+		},
+		{
+			name:  "max reconnect delay zero",
+			fn:    func() xhttp.SubscribeEventsOption { return xhttp.SubscribeEventsMaxReconnectDelay(0) },
+			panic: true,
+		},
+		{
+			name:  "max reconnect delay valid",
+			fn:    func() xhttp.SubscribeEventsOption { return xhttp.SubscribeEventsMaxReconnectDelay(time.Second) },
+			panic: false,
+		},
+		{
+			name:  "reconnect delay zero",
+			fn:    func() xhttp.SubscribeEventsOption { return xhttp.SubscribeEventsReconnectDelay(0) },
+			panic: true,
+		},
+		{
+			name:  "reconnect delay valid",
+			fn:    func() xhttp.SubscribeEventsOption { return xhttp.SubscribeEventsReconnectDelay(time.Second) },
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, tc.fn)
+		})
+	}
+}
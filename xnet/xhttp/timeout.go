@@ -0,0 +1,189 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jlourenc/xgo/xerrors"
+)
+
+const timeoutDefaultRetryAfter = time.Second
+
+type timeout struct {
+	timeout    time.Duration
+	retryAfter time.Duration
+	write      func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// Timeout returns a Middleware that runs the next handler with a context whose deadline is derived from
+// timeout, configured with the options passed in input. If the handler does not complete in time, a
+// 503 Service Unavailable error is replied with WriteError by default, along with a Retry-After header,
+// 1s by default.
+//
+// The response of the handler is buffered until it completes, so that it is not mixed with the one
+// of the timeout: once timed out, writes of the handler fail with http.ErrHandlerTimeout. Flushing
+// and hijacking are thus not supported. Panics of the handler are propagated to the caller.
+func Timeout(d time.Duration, options ...TimeoutOption) Middleware {
+	if d <= 0 {
+		panic("invalid timeout value")
+	}
+
+	t := &timeout{
+		timeout:    d,
+		retryAfter: timeoutDefaultRetryAfter,
+		write:      writeTimeout,
+	}
+
+	for _, opt := range options {
+		opt.apply(t)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), t.timeout)
+			defer cancel()
+			r = r.WithContext(ctx)
+
+			tw := &timeoutWriter{header: make(http.Header)}
+			done := make(chan struct{})
+			panicCh := make(chan any, 1)
+
+			go func() {
+				defer func() {
+					if v := recover(); v != nil {
+						panicCh <- v
+					}
+				}()
+				next.ServeHTTP(tw, r)
+				close(done)
+			}()
+
+			select {
+			case v := <-panicCh:
+				panic(v)
+			case <-done:
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+
+				h := w.Header()
+				for k, v := range tw.header {
+					h[k] = v
+				}
+				if !tw.wroteHeader {
+					tw.code = http.StatusOK
+				}
+				w.WriteHeader(tw.code)
+				w.Write(tw.buf.Bytes()) //nolint:errcheck // the response is already committed.
+			case <-ctx.Done():
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				tw.timedOut = true
+
+				if t.retryAfter > 0 {
+					SetRetryAfter(w, t.retryAfter)
+				}
+				t.write(w, r, xerrors.WithHTTPStatus(http.ErrHandlerTimeout, http.StatusServiceUnavailable))
+			}
+		})
+	}
+}
+
+func writeTimeout(w http.ResponseWriter, _ *http.Request, err error) {
+	WriteError(w, err)
+}
+
+// timeoutWriter is a http.ResponseWriter buffering the response of a handler
+// until it completes, and failing writes once timed out.
+type timeoutWriter struct {
+	mu          sync.Mutex
+	header      http.Header
+	buf         bytes.Buffer
+	code        int
+	wroteHeader bool
+	timedOut    bool
+}
+
+// Header makes timeoutWriter implement the http.ResponseWriter interface.
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+// Write makes timeoutWriter implement the http.ResponseWriter interface.
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.writeHeaderLocked(http.StatusOK)
+	}
+	return tw.buf.Write(b)
+}
+
+// WriteHeader makes timeoutWriter implement the http.ResponseWriter interface.
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.writeHeaderLocked(code)
+}
+
+func (tw *timeoutWriter) writeHeaderLocked(code int) {
+	tw.code = code
+	tw.wroteHeader = true
+}
+
+type (
+	// TimeoutOption configures the Timeout middleware options when calling Timeout.
+	TimeoutOption interface {
+		apply(t *timeout)
+	}
+
+	funcTimeoutOption struct {
+		fn func(*timeout)
+	}
+)
+
+func newFuncTimeoutOption(fn func(*timeout)) funcTimeoutOption {
+	return funcTimeoutOption{
+		fn: fn,
+	}
+}
+
+func (o funcTimeoutOption) apply(t *timeout) {
+	o.fn(t)
+}
+
+// TimeoutRetryAfter returns a TimeoutOption that configures the Retry-After header of timed out responses,
+// rounded up to the second. 0 omits the header. Value must be >= 0, otherwise it panics.
+func TimeoutRetryAfter(retryAfter time.Duration) TimeoutOption {
+	if retryAfter < 0 {
+		panic("invalid retry after value")
+	}
+	return newFuncTimeoutOption(func(t *timeout) {
+		t.retryAfter = retryAfter
+	})
+}
+
+// TimeoutWriteFunc returns a TimeoutOption that configures the function replying to timed out requests,
+// e.g. to write a problem details body. The error wraps http.ErrHandlerTimeout and carries the HTTP status
+// code 503 (see xerrors.HTTPStatus). Value must not be nil, otherwise it panics.
+func TimeoutWriteFunc(write func(w http.ResponseWriter, r *http.Request, err error)) TimeoutOption {
+	if write == nil {
+		panic("write function is nil")
+	}
+	return newFuncTimeoutOption(func(t *timeout) {
+		t.write = write
+	})
+}
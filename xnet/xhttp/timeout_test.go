@@ -0,0 +1,193 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xerrors"
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestTimeout(t *testing.T) {
+	lateWriteErr := make(chan error, 1)
+
+	testCases := []struct {
+		name               string
+		options            []xhttp.TimeoutOption
+		handler            http.HandlerFunc
+		expectedStatus     int
+		expectedBody       string
+		expectedHeader     string
+		expectedRetryAfter string
+	}{
+		{
+			name: "completed in time",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Test", "value")
+				w.WriteHeader(http.StatusCreated)
+				io.WriteString(w, "created")
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   "created",
+			expectedHeader: "value",
+		},
+		{
+			name: "completed in time without writing",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Test", "value")
+			},
+			expectedStatus: http.StatusOK,
+			expectedHeader: "value",
+		},
+		{
+			name: "timed out",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Test", "value")
+				<-r.Context().Done()
+			},
+			expectedStatus:     http.StatusServiceUnavailable,
+			expectedBody:       "Service Unavailable\n",
+			expectedRetryAfter: "1",
+		},
+		{
+			name:    "timed out with retry after rounded up",
+			options: []xhttp.TimeoutOption{xhttp.TimeoutRetryAfter(1500 * time.Millisecond)},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+			},
+			expectedStatus:     http.StatusServiceUnavailable,
+			expectedBody:       "Service Unavailable\n",
+			expectedRetryAfter: "2",
+		},
+		{
+			name:    "timed out without retry after",
+			options: []xhttp.TimeoutOption{xhttp.TimeoutRetryAfter(0)},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "Service Unavailable\n",
+		},
+		{
+			name: "timed out with write func",
+			options: []xhttp.TimeoutOption{xhttp.TimeoutWriteFunc(func(w http.ResponseWriter, r *http.Request, err error) {
+				if !errors.Is(err, http.ErrHandlerTimeout) {
+					t.Errorf("error mismatch: expected %v; got %v", http.ErrHandlerTimeout, err)
+				}
+				w.WriteHeader(xerrors.HTTPStatus(err))
+				io.WriteString(w, "custom")
+			})},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+			},
+			expectedStatus:     http.StatusServiceUnavailable,
+			expectedBody:       "custom",
+			expectedRetryAfter: "1",
+		},
+		{
+			name: "late write",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+				time.Sleep(10 * time.Millisecond)
+				_, err := io.WriteString(w, "late")
+				lateWriteErr <- err
+			},
+			expectedStatus:     http.StatusServiceUnavailable,
+			expectedBody:       "Service Unavailable\n",
+			expectedRetryAfter: "1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler := xhttp.Timeout(10*time.Millisecond, tc.options...)(tc.handler)
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+			if w.Code != tc.expectedStatus {
+				t.Errorf("status code mismatch: expected %d; got %d", tc.expectedStatus, w.Code)
+			}
+			if body := w.Body.String(); body != tc.expectedBody {
+				t.Errorf("body mismatch: expected %q; got %q", tc.expectedBody, body)
+			}
+			if h := w.Header().Get("X-Test"); h != tc.expectedHeader {
+				t.Errorf("header mismatch: expected %q; got %q", tc.expectedHeader, h)
+			}
+			if ra := w.Header().Get(xhttp.HeaderRetryAfter); ra != tc.expectedRetryAfter {
+				t.Errorf("retry after mismatch: expected %q; got %q", tc.expectedRetryAfter, ra)
+			}
+		})
+	}
+
+	if err := <-lateWriteErr; !errors.Is(err, http.ErrHandlerTimeout) {
+		t.Errorf("late write error mismatch: expected %v; got %v", http.ErrHandlerTimeout, err)
+	}
+}
+
+func TestTimeout_HandlerPanic(t *testing.T) {
+	handler := xhttp.Timeout(time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	defer func() {
+		if v := recover(); v != "boom" {
+			t.Errorf("panic mismatch: expected %v; got %v", "boom", v)
+		}
+	}()
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+}
+
+func TestTimeoutOptions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func() xhttp.TimeoutOption
+		panic bool
+	}{
+		{
+			name:  "retry after negative",
+			fn:    func() xhttp.TimeoutOption { return xhttp.TimeoutRetryAfter(-1) },
+			panic: true,
+		},
+		{
+			name:  "retry after valid",
+			fn:    func() xhttp.TimeoutOption { return xhttp.TimeoutRetryAfter(time.Second) },
+			panic: false,
+		},
+		{
+			name:  "write func nil",
+			fn:    func() xhttp.TimeoutOption { return xhttp.TimeoutWriteFunc(nil) },
+			panic: true,
+		},
+		{
+			name: "write func valid",
+			fn: func() xhttp.TimeoutOption {
+				return xhttp.TimeoutWriteFunc(func(http.ResponseWriter, *http.Request, error) {})
+			},
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, tc.fn)
+		})
+	}
+}
+
+func TestTimeout_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+
+	xhttp.Timeout(0)
+}
@@ -0,0 +1,165 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"context"
+	"encoding/base64"
+	"sync"
+	"time"
+)
+
+// Authorization schemes.
+const (
+	// https://datatracker.ietf.org/doc/html/rfc7617
+	AuthSchemeBasic = "Basic"
+	// https://datatracker.ietf.org/doc/html/rfc6750
+	AuthSchemeBearer = "Bearer"
+)
+
+// tokenExpiryDelta is how early a token is considered expired, to account for clock skew
+// and the time taken by the request to reach the server.
+const tokenExpiryDelta = 10 * time.Second
+
+// Token is a credential sent in the Authorization header of requests.
+type Token struct {
+	// Scheme is the authorization scheme, e.g. "Bearer". If empty, "Bearer" is used.
+	Scheme string
+
+	// Value is the credential itself.
+	Value string
+
+	// Expiry is the time after which the token is no longer valid. A zero value means no expiry.
+	Expiry time.Time
+}
+
+// Valid returns whether t is non-nil, has a value and is not about to expire.
+func (t *Token) Valid() bool {
+	return t != nil && t.Value != "" && (t.Expiry.IsZero() || time.Now().Add(tokenExpiryDelta).Before(t.Expiry))
+}
+
+// String returns the value of the Authorization header conveying t, e.g. "Bearer xyz".
+func (t *Token) String() string {
+	scheme := t.Scheme
+	if scheme == "" {
+		scheme = AuthSchemeBearer
+	}
+	return scheme + " " + t.Value
+}
+
+type (
+	// TokenSource provides tokens to authenticate requests.
+	// Implementations must be safe for concurrent use by multiple goroutines.
+	TokenSource interface {
+		Token(ctx context.Context) (*Token, error)
+	}
+
+	// TokenInvalidator is implemented by TokenSources caching tokens, to discard a token rejected by
+	// the server so that the next call to Token returns a new one.
+	TokenInvalidator interface {
+		InvalidateToken(t *Token)
+	}
+
+	// TokenSourceFunc is an adapter to allow the use of ordinary functions as TokenSource.
+	TokenSourceFunc func(ctx context.Context) (*Token, error)
+)
+
+// Token calls f(ctx).
+func (f TokenSourceFunc) Token(ctx context.Context) (*Token, error) {
+	return f(ctx)
+}
+
+// StaticTokenSource returns a TokenSource always returning t.
+func StaticTokenSource(t *Token) TokenSource {
+	return TokenSourceFunc(func(context.Context) (*Token, error) {
+		return t, nil
+	})
+}
+
+// BasicAuthTokenSource returns a TokenSource always returning a token for the Basic authorization
+// scheme with the username and password passed in input.
+func BasicAuthTokenSource(username, password string) TokenSource {
+	return StaticTokenSource(&Token{
+		Scheme: AuthSchemeBasic,
+		Value:  base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+	})
+}
+
+// refreshTokenSource is a TokenSource caching the token fetched by a TokenSource until it expires.
+type refreshTokenSource struct {
+	src TokenSource
+
+	mu     sync.Mutex
+	token  *Token
+	flight *tokenFlight
+}
+
+// tokenFlight is an in-flight token fetch.
+type tokenFlight struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+// NewRefreshTokenSource returns a TokenSource caching the token returned by src until it expires,
+// e.g. to fetch an OAuth2 access token only when needed. Concurrent calls needing a new token share
+// a single call to src, which is not canceled when a caller gives up. The returned TokenSource
+// implements TokenInvalidator. Src must not be nil, otherwise it panics.
+func NewRefreshTokenSource(src TokenSource) TokenSource {
+	if src == nil {
+		panic("token source is nil")
+	}
+	return &refreshTokenSource{
+		src: src,
+	}
+}
+
+// InvalidateToken makes refreshTokenSource implement the TokenInvalidator interface.
+// It discards t if it is the cached token.
+func (s *refreshTokenSource) InvalidateToken(t *Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == t {
+		s.token = nil
+	}
+}
+
+// Token makes refreshTokenSource implement the TokenSource interface.
+func (s *refreshTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	if s.token.Valid() {
+		t := s.token
+		s.mu.Unlock()
+		return t, nil
+	}
+
+	f := s.flight
+	if f == nil {
+		f = &tokenFlight{done: make(chan struct{})}
+		s.flight = f
+
+		go func() {
+			t, err := s.src.Token(context.WithoutCancel(ctx))
+
+			s.mu.Lock()
+			f.token, f.err = t, err
+			if err == nil {
+				s.token = t
+			}
+			s.flight = nil
+			s.mu.Unlock()
+
+			close(f.done)
+		}()
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.token, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+func TestToken_Valid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		token    *xhttp.Token
+		expected bool
+	}{
+		{
+			name:     "nil",
+			token:    nil,
+			expected: false,
+		},
+		{
+			name:     "empty value",
+			token:    &xhttp.Token{},
+			expected: false,
+		},
+		{
+			name:     "no expiry",
+			token:    &xhttp.Token{Value: "token"},
+			expected: true,
+		},
+		{
+			name:     "not expired",
+			token:    &xhttp.Token{Value: "token", Expiry: time.Now().Add(time.Hour)},
+			expected: true,
+		},
+		{
+			name:     "about to expire",
+			token:    &xhttp.Token{Value: "token", Expiry: time.Now().Add(time.Second)},
+			expected: false,
+		},
+		{
+			name:     "expired",
+			token:    &xhttp.Token{Value: "token", Expiry: time.Now().Add(-time.Hour)},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.token.Valid(); got != tc.expected {
+				t.Errorf("expected %t; got %t", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestToken_String(t *testing.T) {
+	testCases := []struct {
+		name     string
+		token    *xhttp.Token
+		expected string
+	}{
+		{
+			name:     "default scheme",
+			token:    &xhttp.Token{Value: "token"},
+			expected: "Bearer token",
+		},
+		{
+			name:     "scheme",
+			token:    &xhttp.Token{Scheme: "Custom", Value: "token"},
+			expected: "Custom token",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.token.String(); got != tc.expected {
+				t.Errorf("expected %q; got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestBasicAuthTokenSource(t *testing.T) {
+	token, err := xhttp.BasicAuthTokenSource("Aladdin", "open sesame").Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// https://datatracker.ietf.org/doc/html/rfc7617#section-2
+	if expected := "Basic QWxhZGRpbjpvcGVuIHNlc2FtZQ=="; token.String() != expected {
+		t.Errorf("expected %q; got %q", expected, token.String())
+	}
+}
+
+func TestRefreshTokenSource(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	src := xhttp.NewRefreshTokenSource(xhttp.TokenSourceFunc(func(context.Context) (*xhttp.Token, error) {
+		<-release
+		n := calls.Add(1)
+		return &xhttp.Token{Value: "token" + strconv.Itoa(int(n)), Expiry: time.Now().Add(time.Hour)}, nil
+	}))
+
+	var wg sync.WaitGroup
+	tokens := make([]*xhttp.Token, 10)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			token, err := src.Token(context.Background())
+			if err != nil {
+				t.Error(err)
+			}
+			tokens[i] = token
+		}(i)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected a single fetch; got %d", n)
+	}
+	for _, token := range tokens {
+		if token != tokens[0] {
+			t.Fatalf("expected tokens to be shared; got %v and %v", token, tokens[0])
+		}
+	}
+
+	token, err := src.Token(context.Background())
+	if err != nil || token != tokens[0] {
+		t.Fatalf("expected cached token; got %v, %v", token, err)
+	}
+
+	src.(xhttp.TokenInvalidator).InvalidateToken(&xhttp.Token{Value: "other"})
+	if token, _ = src.Token(context.Background()); token != tokens[0] {
+		t.Fatalf("expected cached token after invalidating another token; got %v", token)
+	}
+
+	src.(xhttp.TokenInvalidator).InvalidateToken(token)
+	if token, _ = src.Token(context.Background()); token.Value != "token2" {
+		t.Fatalf("expected new token after invalidation; got %v", token)
+	}
+}
+
+func TestRefreshTokenSource_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+
+	xhttp.NewRefreshTokenSource(nil)
+}
+
+func TestRefreshTokenSource_Expiry(t *testing.T) {
+	var calls int
+	src := xhttp.NewRefreshTokenSource(xhttp.TokenSourceFunc(func(context.Context) (*xhttp.Token, error) {
+		calls++
+		return &xhttp.Token{Value: "token", Expiry: time.Now()}, nil
+	}))
+
+	for i := 0; i < 3; i++ {
+		if _, err := src.Token(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if calls != 3 {
+		t.Errorf("expected expired tokens to be refreshed; got %d fetches", calls)
+	}
+}
+
+func TestRefreshTokenSource_Error(t *testing.T) {
+	errFetch := errors.New("fetch error")
+	src := xhttp.NewRefreshTokenSource(xhttp.TokenSourceFunc(func(context.Context) (*xhttp.Token, error) {
+		return nil, errFetch
+	}))
+
+	if _, err := src.Token(context.Background()); err != errFetch {
+		t.Errorf("error mismatch: expected %v; got %v", errFetch, err)
+	}
+}
+
+func TestRefreshTokenSource_ContextDone(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	src := xhttp.NewRefreshTokenSource(xhttp.TokenSourceFunc(func(context.Context) (*xhttp.Token, error) {
+		<-release
+		return &xhttp.Token{Value: "token"}, nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := src.Token(ctx); err != context.Canceled {
+		t.Errorf("error mismatch: expected %v; got %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,880 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // SHA-1 is mandated by RFC 6455 for the handshake.
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jlourenc/xgo/xerrors"
+	"github.com/jlourenc/xgo/xnet"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+const (
+	websocketGUID                  = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	websocketVersion               = "13"
+	websocketKeySize               = 16
+	websocketDefaultMaxMessageSize = 32 * xunit.MiB
+	websocketCloseTimeout          = 5 * time.Second
+	websocketMaxErrorBodySize      = 64 << 10
+
+	// Frame format: https://datatracker.ietf.org/doc/html/rfc6455#section-5.2
+	websocketFinBit            = 0x80
+	websocketRsvBits           = 0x70
+	websocketOpcodeBits        = 0x0f
+	websocketMaskBit           = 0x80
+	websocketLenBits           = 0x7f
+	websocketLen16             = 126
+	websocketLen64             = 127
+	websocketMaxControlPayload = 125
+	websocketMaxCloseReason    = websocketMaxControlPayload - 2
+
+	websocketOpContinuation = 0x0
+	websocketOpText         = 0x1
+	websocketOpBinary       = 0x2
+	websocketOpClose        = 0x8
+	websocketOpPing         = 0x9
+	websocketOpPong         = 0xa
+)
+
+// WebSocketMessageType is the type of a WebSocket data message.
+type WebSocketMessageType int
+
+// Enumeration of WebSocket message types.
+const (
+	WebSocketText   WebSocketMessageType = websocketOpText
+	WebSocketBinary WebSocketMessageType = websocketOpBinary
+)
+
+// Enumeration of WebSocket close status codes.
+// https://datatracker.ietf.org/doc/html/rfc6455#section-7.4.1
+const (
+	WebSocketCloseNormal          = 1000
+	WebSocketCloseGoingAway       = 1001
+	WebSocketCloseProtocolError   = 1002
+	WebSocketCloseUnsupportedData = 1003
+	WebSocketCloseNoStatus        = 1005
+	WebSocketCloseInvalidPayload  = 1007
+	WebSocketClosePolicyViolation = 1008
+	WebSocketCloseMessageTooBig   = 1009
+	WebSocketCloseInternalError   = 1011
+)
+
+var (
+	// ErrWebSocketClosed is returned when using a WebSocketConn whose close handshake has started.
+	ErrWebSocketClosed = errors.New("websocket closed")
+
+	// ErrWebSocketHandshake is returned by UpgradeWebSocket and DialWebSocket when the opening handshake fails.
+	ErrWebSocketHandshake = errors.New("websocket handshake failed")
+
+	// ErrWebSocketMessageTooLarge is returned by WebSocketConn.ReadMessage when a message exceeds
+	// the max message size.
+	ErrWebSocketMessageTooLarge = errors.New("websocket message too large")
+
+	// ErrWebSocketProtocol is returned by WebSocketConn.ReadMessage when the peer violates the protocol.
+	ErrWebSocketProtocol = errors.New("websocket protocol error")
+
+	websocketLongTimeAgo = time.Unix(1, 0)
+)
+
+// WebSocketCloseError is returned by WebSocketConn.ReadMessage when the peer closes the connection.
+// It matches ErrWebSocketClosed.
+type WebSocketCloseError struct {
+	// Code is the close status code sent by the peer, or WebSocketCloseNoStatus if none.
+	Code int
+
+	// Reason is the close reason sent by the peer, if any.
+	Reason string
+}
+
+// Error makes WebSocketCloseError implement the error interface.
+func (e *WebSocketCloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket closed: %d", e.Code)
+	}
+	return fmt.Sprintf("websocket closed: %d %s", e.Code, e.Reason)
+}
+
+// Is reports whether target is ErrWebSocketClosed.
+func (e *WebSocketCloseError) Is(target error) bool {
+	return target == ErrWebSocketClosed
+}
+
+// WebSocketConn is a message-based WebSocket connection, as defined by RFC 6455.
+// Extensions, such as compression, are not supported.
+//
+// Control frames are handled while reading: pings are answered with pongs and close frames
+// are echoed to complete the close handshake. Hence, a goroutine should keep reading
+// messages for the connection to remain responsive.
+//
+// It supports one concurrent reader and multiple concurrent writers.
+type WebSocketConn struct {
+	conn           net.Conn
+	br             *bufio.Reader
+	client         bool
+	subprotocol    string
+	maxMessageSize xunit.Byte
+
+	readMu        sync.Mutex
+	closeReceived chan struct{}
+	closeOnce     sync.Once
+
+	writeMu   sync.Mutex
+	closeSent bool
+
+	netCloseOnce sync.Once
+	netCloseErr  error
+}
+
+func newWebSocketConn(conn net.Conn, br *bufio.Reader, client bool, subprotocol string, maxMessageSize xunit.Byte) *WebSocketConn {
+	return &WebSocketConn{
+		conn:           conn,
+		br:             br,
+		client:         client,
+		subprotocol:    subprotocol,
+		maxMessageSize: maxMessageSize,
+		closeReceived:  make(chan struct{}),
+	}
+}
+
+// Subprotocol returns the subprotocol negotiated during the opening handshake, if any.
+func (c *WebSocketConn) Subprotocol() string {
+	return c.subprotocol
+}
+
+// LocalAddr returns the local network address.
+func (c *WebSocketConn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+// RemoteAddr returns the remote network address.
+func (c *WebSocketConn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// SetReadDeadline sets the deadline for future ReadMessage calls.
+//
+// See net.Conn.SetReadDeadline for more information.
+func (c *WebSocketConn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline sets the deadline for future write calls.
+//
+// See net.Conn.SetWriteDeadline for more information.
+func (c *WebSocketConn) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+// ReadMessage reads the next data message, reassembling fragmented ones.
+//
+// It returns a *WebSocketCloseError once the peer closes the connection. On protocol violations,
+// or messages larger than the max message size, the connection is closed with the appropriate
+// close status code and an error matching ErrWebSocketProtocol or ErrWebSocketMessageTooLarge is returned.
+func (c *WebSocketConn) ReadMessage() (WebSocketMessageType, []byte, error) {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+
+	return c.readMessage()
+}
+
+func (c *WebSocketConn) readMessage() (WebSocketMessageType, []byte, error) {
+	select {
+	case <-c.closeReceived:
+		return 0, nil, ErrWebSocketClosed
+	default:
+	}
+
+	var (
+		typ WebSocketMessageType
+		msg []byte
+	)
+	for {
+		f, err := c.readFrame(int64(c.maxMessageSize) - int64(len(msg)))
+		if err != nil {
+			return 0, nil, c.fail(err)
+		}
+
+		switch f.opcode {
+		case websocketOpPing:
+			if err := c.writeFrame(websocketOpPong, f.payload); err != nil && !errors.Is(err, ErrWebSocketClosed) {
+				return 0, nil, c.fail(err)
+			}
+			continue
+		case websocketOpPong:
+			continue
+		case websocketOpClose:
+			return 0, nil, c.handleClose(f.payload)
+		case websocketOpText, websocketOpBinary:
+			if typ != 0 {
+				return 0, nil, c.fail(fmt.Errorf("%w: unfinished fragmented message", ErrWebSocketProtocol))
+			}
+			typ = WebSocketMessageType(f.opcode)
+		case websocketOpContinuation:
+			if typ == 0 {
+				return 0, nil, c.fail(fmt.Errorf("%w: unexpected continuation frame", ErrWebSocketProtocol))
+			}
+		default:
+			return 0, nil, c.fail(fmt.Errorf("%w: unknown opcode %#x", ErrWebSocketProtocol, f.opcode))
+		}
+
+		msg = append(msg, f.payload...)
+		if !f.fin {
+			continue
+		}
+
+		if typ == WebSocketText && !utf8.Valid(msg) {
+			return 0, nil, c.failWithCode(WebSocketCloseInvalidPayload, fmt.Errorf("%w: invalid UTF-8 text message", ErrWebSocketProtocol))
+		}
+		return typ, msg, nil
+	}
+}
+
+type websocketFrame struct {
+	fin     bool
+	opcode  byte
+	payload []byte
+}
+
+// readFrame reads the next frame, failing with ErrWebSocketMessageTooLarge if it is a data frame
+// whose payload is larger than limit.
+func (c *WebSocketConn) readFrame(limit int64) (websocketFrame, error) {
+	var (
+		f websocketFrame
+		h [8]byte
+	)
+
+	if _, err := io.ReadFull(c.br, h[:2]); err != nil {
+		return f, err
+	}
+
+	f.fin = h[0]&websocketFinBit != 0
+	f.opcode = h[0] & websocketOpcodeBits
+	if h[0]&websocketRsvBits != 0 {
+		return f, fmt.Errorf("%w: reserved bits set", ErrWebSocketProtocol)
+	}
+
+	// Clients must mask frames, servers must not.
+	masked := h[1]&websocketMaskBit != 0
+	if masked == c.client {
+		return f, fmt.Errorf("%w: unexpected masking", ErrWebSocketProtocol)
+	}
+
+	n := int64(h[1] & websocketLenBits)
+	switch n {
+	case websocketLen16:
+		if _, err := io.ReadFull(c.br, h[:2]); err != nil {
+			return f, err
+		}
+		n = int64(binary.BigEndian.Uint16(h[:2]))
+	case websocketLen64:
+		if _, err := io.ReadFull(c.br, h[:8]); err != nil {
+			return f, err
+		}
+		u := binary.BigEndian.Uint64(h[:8])
+		if u > math.MaxInt64 {
+			return f, fmt.Errorf("%w: invalid payload length", ErrWebSocketProtocol)
+		}
+		n = int64(u)
+	}
+
+	if f.opcode >= websocketOpClose {
+		if !f.fin || n > websocketMaxControlPayload {
+			return f, fmt.Errorf("%w: invalid control frame", ErrWebSocketProtocol)
+		}
+	} else if n > limit {
+		return f, ErrWebSocketMessageTooLarge
+	}
+
+	var key [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, key[:]); err != nil {
+			return f, err
+		}
+	}
+
+	f.payload = make([]byte, n)
+	if _, err := io.ReadFull(c.br, f.payload); err != nil {
+		return f, err
+	}
+	if masked {
+		maskWebSocketPayload(key, f.payload)
+	}
+
+	return f, nil
+}
+
+// handleClose completes the close handshake initiated by the peer, or by a previous call to CloseWithCode.
+func (c *WebSocketConn) handleClose(payload []byte) error {
+	closeErr := &WebSocketCloseError{Code: WebSocketCloseNoStatus}
+
+	if len(payload) > 0 {
+		if len(payload) < 2 {
+			return c.fail(fmt.Errorf("%w: invalid close frame", ErrWebSocketProtocol))
+		}
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Reason = string(payload[2:])
+		if !isValidWebSocketCloseCode(closeErr.Code) || !utf8.ValidString(closeErr.Reason) {
+			return c.fail(fmt.Errorf("%w: invalid close frame", ErrWebSocketProtocol))
+		}
+	}
+
+	c.closeOnce.Do(func() { close(c.closeReceived) })
+
+	// Echo the status code, unless the close handshake was initiated locally.
+	var echo []byte
+	if closeErr.Code != WebSocketCloseNoStatus {
+		echo = payload[:2]
+	}
+	c.writeFrame(websocketOpClose, echo) //nolint:errcheck // the connection is closed anyway.
+	c.closeConn()                        //nolint:errcheck // the close handshake is complete.
+
+	return closeErr
+}
+
+// fail closes the connection because of err, sending a close frame first if err is a protocol error.
+func (c *WebSocketConn) fail(err error) error {
+	switch {
+	case errors.Is(err, ErrWebSocketMessageTooLarge):
+		return c.failWithCode(WebSocketCloseMessageTooBig, err)
+	case errors.Is(err, ErrWebSocketProtocol):
+		return c.failWithCode(WebSocketCloseProtocolError, err)
+	default:
+		c.closeConn() //nolint:errcheck // err is more relevant.
+		return err
+	}
+}
+
+func (c *WebSocketConn) failWithCode(code int, err error) error {
+	c.writeFrame(websocketOpClose, websocketClosePayload(code, "")) //nolint:errcheck // err is more relevant.
+	c.closeConn()                                                   //nolint:errcheck // err is more relevant.
+	return err
+}
+
+// WriteMessage writes a data message of type typ in a single frame.
+func (c *WebSocketConn) WriteMessage(typ WebSocketMessageType, data []byte) error {
+	if typ != WebSocketText && typ != WebSocketBinary {
+		return fmt.Errorf("invalid message type %d", typ)
+	}
+	return c.writeFrame(byte(typ), data)
+}
+
+// Ping writes a ping control frame with data as payload, which must not exceed 125 bytes.
+// The peer replies with a pong, consumed by ReadMessage.
+func (c *WebSocketConn) Ping(data []byte) error {
+	if len(data) > websocketMaxControlPayload {
+		return fmt.Errorf("ping payload too large: %d bytes", len(data))
+	}
+	return c.writeFrame(websocketOpPing, data)
+}
+
+// Close closes the connection with the WebSocketCloseNormal status code.
+//
+// See CloseWithCode for more information.
+func (c *WebSocketConn) Close() error {
+	return c.CloseWithCode(WebSocketCloseNormal, "")
+}
+
+// CloseWithCode starts the close handshake by sending a close frame with the given status code
+// and reason, truncated to 123 bytes. It then waits up to 5s for the peer to echo it, reading
+// and discarding messages unless another goroutine is reading, and closes the underlying connection.
+func (c *WebSocketConn) CloseWithCode(code int, reason string) error {
+	if len(reason) > websocketMaxCloseReason {
+		reason = reason[:websocketMaxCloseReason]
+	}
+
+	if err := c.writeFrame(websocketOpClose, websocketClosePayload(code, reason)); err == nil {
+		c.waitCloseReceived()
+	}
+
+	return c.closeConn()
+}
+
+func (c *WebSocketConn) waitCloseReceived() {
+	if c.readMu.TryLock() {
+		defer c.readMu.Unlock()
+
+		c.conn.SetReadDeadline(time.Now().Add(websocketCloseTimeout)) //nolint:errcheck // reads fail anyway.
+		for {
+			if _, _, err := c.readMessage(); err != nil {
+				return
+			}
+		}
+	}
+
+	timer := time.NewTimer(websocketCloseTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-c.closeReceived:
+	case <-timer.C:
+	}
+}
+
+func (c *WebSocketConn) closeConn() error {
+	c.netCloseOnce.Do(func() {
+		c.netCloseErr = c.conn.Close()
+	})
+	return c.netCloseErr
+}
+
+func (c *WebSocketConn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closeSent {
+		return ErrWebSocketClosed
+	}
+	if opcode == websocketOpClose {
+		c.closeSent = true
+	}
+
+	var maskBit byte
+	if c.client {
+		maskBit = websocketMaskBit
+	}
+
+	buf := make([]byte, 0, 14+len(payload)) //nolint:gomnd // max header size.
+	buf = append(buf, websocketFinBit|opcode)
+	switch n := len(payload); {
+	case n < websocketLen16:
+		buf = append(buf, maskBit|byte(n))
+	case n <= math.MaxUint16:
+		buf = append(buf, maskBit|websocketLen16)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(n))
+	default:
+		buf = append(buf, maskBit|websocketLen64)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(n))
+	}
+
+	if c.client {
+		var key [4]byte
+		if _, err := rand.Read(key[:]); err != nil {
+			return err
+		}
+		buf = append(buf, key[:]...)
+		start := len(buf)
+		buf = append(buf, payload...)
+		maskWebSocketPayload(key, buf[start:])
+	} else {
+		buf = append(buf, payload...)
+	}
+
+	_, err := c.conn.Write(buf)
+	return err
+}
+
+func maskWebSocketPayload(key [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= key[i%len(key)]
+	}
+}
+
+func websocketClosePayload(code int, reason string) []byte {
+	return append(binary.BigEndian.AppendUint16(nil, uint16(code)), reason...)
+}
+
+// isValidWebSocketCloseCode reports whether code may be sent in a close frame.
+// https://datatracker.ietf.org/doc/html/rfc6455#section-7.4
+func isValidWebSocketCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1011:
+		return true
+	default:
+		return code >= 3000 && code <= 4999
+	}
+}
+
+func websocketAcceptKey(key string) string {
+	h := sha1.New() //nolint:gosec // SHA-1 is mandated by RFC 6455 for the handshake.
+	h.Write([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func headerHasToken(headers http.Header, key, token string) bool {
+	for _, v := range HeaderValues(headers, key) {
+		if strings.EqualFold(v, token) {
+			return true
+		}
+	}
+	return false
+}
+
+type websocketUpgrader struct {
+	checkOrigin    func(r *http.Request) bool
+	maxMessageSize xunit.Byte
+	subprotocols   []string
+}
+
+// UpgradeWebSocket upgrades the HTTP connection of the request to the WebSocket protocol, configured
+// with the options passed in input. By default, only same-origin requests are accepted and messages
+// are limited to 32 MiB.
+//
+// If the opening handshake fails, the request is replied to with the appropriate status code written
+// with WriteError and an error matching ErrWebSocketHandshake is returned. Once upgraded, the response
+// writer must no longer be used.
+func UpgradeWebSocket(w http.ResponseWriter, r *http.Request, options ...UpgradeWebSocketOption) (*WebSocketConn, error) {
+	u := &websocketUpgrader{
+		checkOrigin:    isSameOrigin,
+		maxMessageSize: websocketDefaultMaxMessageSize,
+	}
+
+	for _, opt := range options {
+		opt.apply(u)
+	}
+
+	fail := func(code int, reason string) (*WebSocketConn, error) {
+		err := fmt.Errorf("%w: %s", ErrWebSocketHandshake, reason)
+		WriteError(w, xerrors.WithHTTPStatus(err, code))
+		return nil, err
+	}
+
+	if r.Method != http.MethodGet {
+		w.Header().Set(HeaderAllow, http.MethodGet)
+		return fail(http.StatusMethodNotAllowed, "method not GET")
+	}
+	if !headerHasToken(r.Header, HeaderConnection, "upgrade") || !headerHasToken(r.Header, HeaderUpgrade, "websocket") {
+		return fail(http.StatusBadRequest, "not a websocket upgrade request")
+	}
+	if r.Header.Get(HeaderSecWebSocketVersion) != websocketVersion {
+		w.Header().Set(HeaderSecWebSocketVersion, websocketVersion)
+		return fail(http.StatusUpgradeRequired, "unsupported version")
+	}
+	key := r.Header.Get(HeaderSecWebSocketKey)
+	if b, err := base64.StdEncoding.DecodeString(key); err != nil || len(b) != websocketKeySize {
+		return fail(http.StatusBadRequest, "invalid key")
+	}
+	if !u.checkOrigin(r) {
+		return fail(http.StatusForbidden, "origin not allowed")
+	}
+
+	var subprotocol string
+	for _, p := range HeaderValues(r.Header, HeaderSecWebSocketProtocol) {
+		if slices.Contains(u.subprotocols, p) {
+			subprotocol = p
+			break
+		}
+	}
+
+	conn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return fail(http.StatusInternalServerError, err.Error())
+	}
+
+	// Clear the deadlines possibly set by the server.
+	conn.SetDeadline(time.Time{}) //nolint:errcheck // best effort.
+
+	if brw.Reader.Buffered() > 0 {
+		conn.Close()
+		return nil, fmt.Errorf("%w: data sent before handshake completion", ErrWebSocketHandshake)
+	}
+
+	brw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	brw.WriteString(HeaderUpgrade + ": websocket\r\n")
+	brw.WriteString(HeaderConnection + ": Upgrade\r\n")
+	brw.WriteString(HeaderSecWebSocketAccept + ": " + websocketAcceptKey(key) + "\r\n")
+	if subprotocol != "" {
+		brw.WriteString(HeaderSecWebSocketProtocol + ": " + subprotocol + "\r\n")
+	}
+	brw.WriteString("\r\n")
+	if err := brw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return newWebSocketConn(conn, brw.Reader, false, subprotocol, u.maxMessageSize), nil
+}
+
+// isSameOrigin reports whether the request has no Origin header or an origin whose host is the request one.
+func isSameOrigin(r *http.Request) bool {
+	origin := r.Header.Get(HeaderOrigin)
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+type websocketDialer struct {
+	dialOptions    []xnet.DialOption
+	header         http.Header
+	maxMessageSize xunit.Byte
+	subprotocols   []string
+	tlsConfig      *tls.Config
+}
+
+// DialWebSocket opens a WebSocket connection to the ws:// or wss:// URL, configured with the options
+// passed in input. The connection is made with xnet.DialContext, so that its timeouts can be configured
+// with DialWebSocketDialOptions, and ctx bounds the opening handshake. By default, messages are limited to 32 MiB.
+//
+// The handshake response is returned along with the connection. If the handshake fails, it is returned,
+// if any, along with an error matching ErrWebSocketHandshake.
+func DialWebSocket(ctx context.Context, rawURL string, options ...DialWebSocketOption) (*WebSocketConn, *http.Response, error) {
+	d := &websocketDialer{
+		header:         http.Header{},
+		maxMessageSize: websocketDefaultMaxMessageSize,
+	}
+
+	for _, opt := range options {
+		opt.apply(d)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var secure bool
+	port := "80"
+	switch u.Scheme {
+	case "ws":
+	case "wss":
+		secure, port = true, "443"
+	default:
+		return nil, nil, fmt.Errorf("unsupported websocket scheme %q", u.Scheme)
+	}
+
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	conn, err := xnet.DialContext(ctx, xnet.NetworkTCP, addr, d.dialOptions...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ws, resp, err := d.handshake(ctx, conn, u, secure)
+	if err != nil {
+		conn.Close()
+		return nil, resp, err
+	}
+	return ws, resp, nil
+}
+
+func (d *websocketDialer) handshake(ctx context.Context, conn net.Conn, u *url.URL, secure bool) (*WebSocketConn, *http.Response, error) {
+	// Abort the handshake once ctx is done.
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(websocketLongTimeAgo) //nolint:errcheck // best effort.
+	})
+	defer stop()
+
+	if secure {
+		cfg := &tls.Config{} //nolint:gosec // the minimum version is the default one.
+		if d.tlsConfig != nil {
+			cfg = d.tlsConfig.Clone()
+		}
+		if cfg.ServerName == "" {
+			cfg.ServerName = u.Hostname()
+		}
+		tlsConn := tls.Client(conn, cfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return nil, nil, err
+		}
+		conn = tlsConn
+	}
+
+	var key [websocketKeySize]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		return nil, nil, err
+	}
+	encodedKey := base64.StdEncoding.EncodeToString(key[:])
+
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        u,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     d.header.Clone(),
+		Host:       u.Host,
+	}
+	req.Header.Set(HeaderUpgrade, "websocket")
+	req.Header.Set(HeaderConnection, "Upgrade")
+	req.Header.Set(HeaderSecWebSocketKey, encodedKey)
+	req.Header.Set(HeaderSecWebSocketVersion, websocketVersion)
+	if len(d.subprotocols) > 0 {
+		req.Header.Set(HeaderSecWebSocketProtocol, strings.Join(d.subprotocols, ", "))
+	}
+
+	if err := req.Write(conn); err != nil {
+		return nil, nil, ctxErrOr(ctx, err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, nil, ctxErrOr(ctx, err)
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, websocketMaxErrorBodySize))
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return nil, resp, fmt.Errorf("%w: unexpected status code %d", ErrWebSocketHandshake, resp.StatusCode)
+	}
+	if !headerHasToken(resp.Header, HeaderConnection, "upgrade") || !headerHasToken(resp.Header, HeaderUpgrade, "websocket") ||
+		resp.Header.Get(HeaderSecWebSocketAccept) != websocketAcceptKey(encodedKey) {
+		return nil, resp, fmt.Errorf("%w: invalid upgrade response", ErrWebSocketHandshake)
+	}
+	subprotocol := resp.Header.Get(HeaderSecWebSocketProtocol)
+	if subprotocol != "" && !slices.Contains(d.subprotocols, subprotocol) {
+		return nil, resp, fmt.Errorf("%w: unexpected subprotocol %q", ErrWebSocketHandshake, subprotocol)
+	}
+
+	if !stop() {
+		return nil, resp, ctx.Err()
+	}
+	conn.SetDeadline(time.Time{}) //nolint:errcheck // best effort.
+
+	return newWebSocketConn(conn, br, true, subprotocol, d.maxMessageSize), resp, nil
+}
+
+// ctxErrOr returns the error of ctx if it is done, err otherwise.
+func ctxErrOr(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+type (
+	// UpgradeWebSocketOption configures the UpgradeWebSocket options when calling UpgradeWebSocket.
+	UpgradeWebSocketOption interface {
+		apply(u *websocketUpgrader)
+	}
+
+	funcUpgradeWebSocketOption struct {
+		fn func(*websocketUpgrader)
+	}
+)
+
+func newFuncUpgradeWebSocketOption(fn func(*websocketUpgrader)) funcUpgradeWebSocketOption {
+	return funcUpgradeWebSocketOption{
+		fn: fn,
+	}
+}
+
+func (o funcUpgradeWebSocketOption) apply(u *websocketUpgrader) {
+	o.fn(u)
+}
+
+// UpgradeWebSocketCheckOrigin returns an UpgradeWebSocketOption that configures the function reporting
+// whether the origin of the request is allowed. If not used, only same-origin requests are allowed.
+func UpgradeWebSocketCheckOrigin(fn func(r *http.Request) bool) UpgradeWebSocketOption {
+	if fn == nil {
+		panic("check origin function is nil")
+	}
+	return newFuncUpgradeWebSocketOption(func(u *websocketUpgrader) {
+		u.checkOrigin = fn
+	})
+}
+
+// UpgradeWebSocketMaxMessageSize returns an UpgradeWebSocketOption that configures the max size
+// of the messages read. Value must be > 0, otherwise it panics.
+func UpgradeWebSocketMaxMessageSize(size xunit.Byte) UpgradeWebSocketOption {
+	if size <= 0 {
+		panic("invalid max message size value")
+	}
+	return newFuncUpgradeWebSocketOption(func(u *websocketUpgrader) {
+		u.maxMessageSize = size
+	})
+}
+
+// UpgradeWebSocketSubprotocols returns an UpgradeWebSocketOption that configures the subprotocols
+// supported by the server. The first one offered by the client is selected.
+func UpgradeWebSocketSubprotocols(subprotocols ...string) UpgradeWebSocketOption {
+	return newFuncUpgradeWebSocketOption(func(u *websocketUpgrader) {
+		u.subprotocols = subprotocols
+	})
+}
+
+type (
+	// DialWebSocketOption configures the DialWebSocket options when calling DialWebSocket.
+	DialWebSocketOption interface {
+		apply(d *websocketDialer)
+	}
+
+	funcDialWebSocketOption struct {
+		fn func(*websocketDialer)
+	}
+)
+
+func newFuncDialWebSocketOption(fn func(*websocketDialer)) funcDialWebSocketOption {
+	return funcDialWebSocketOption{
+		fn: fn,
+	}
+}
+
+func (o funcDialWebSocketOption) apply(d *websocketDialer) {
+	o.fn(d)
+}
+
+// DialWebSocketDialOptions returns a DialWebSocketOption that configures the options
+// used to dial the connection with xnet.DialContext, e.g. connect, read or write timeouts.
+func DialWebSocketDialOptions(options ...xnet.DialOption) DialWebSocketOption {
+	return newFuncDialWebSocketOption(func(d *websocketDialer) {
+		d.dialOptions = options
+	})
+}
+
+// DialWebSocketHeader returns a DialWebSocketOption that configures additional headers
+// sent with the opening handshake request, e.g. Authorization or Origin.
+func DialWebSocketHeader(header http.Header) DialWebSocketOption {
+	if header == nil {
+		panic("header is nil")
+	}
+	return newFuncDialWebSocketOption(func(d *websocketDialer) {
+		d.header = header
+	})
+}
+
+// DialWebSocketMaxMessageSize returns a DialWebSocketOption that configures the max size
+// of the messages read. Value must be > 0, otherwise it panics.
+func DialWebSocketMaxMessageSize(size xunit.Byte) DialWebSocketOption {
+	if size <= 0 {
+		panic("invalid max message size value")
+	}
+	return newFuncDialWebSocketOption(func(d *websocketDialer) {
+		d.maxMessageSize = size
+	})
+}
+
+// DialWebSocketSubprotocols returns a DialWebSocketOption that configures the subprotocols
+// offered to the server, by order of preference.
+func DialWebSocketSubprotocols(subprotocols ...string) DialWebSocketOption {
+	return newFuncDialWebSocketOption(func(d *websocketDialer) {
+		d.subprotocols = subprotocols
+	})
+}
+
+// DialWebSocketTLSConfig returns a DialWebSocketOption that configures the TLS configuration
+// used for wss:// URLs.
+func DialWebSocketTLSConfig(cfg *tls.Config) DialWebSocketOption {
+	if cfg == nil {
+		panic("tls config is nil")
+	}
+	return newFuncDialWebSocketOption(func(d *websocketDialer) {
+		d.tlsConfig = cfg
+	})
+}
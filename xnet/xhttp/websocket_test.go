@@ -0,0 +1,416 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package xhttp_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet"
+	"github.com/jlourenc/xgo/xnet/xhttp"
+)
+
+// newWebSocketServer starts a server upgrading requests and passing the connections to fn.
+// Server side errors are reported on the returned channel.
+func newWebSocketServer(tb testing.TB, fn func(c *xhttp.WebSocketConn) error, options ...xhttp.UpgradeWebSocketOption) (string, <-chan error) {
+	tb.Helper()
+
+	errCh := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := xhttp.UpgradeWebSocket(w, r, options...)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		errCh <- fn(c)
+	}))
+	tb.Cleanup(srv.Close)
+
+	return "ws" + strings.TrimPrefix(srv.URL, "http"), errCh
+}
+
+func echoWebSocket(c *xhttp.WebSocketConn) error {
+	for {
+		typ, msg, err := c.ReadMessage()
+		if err != nil {
+			return err
+		}
+		if err := c.WriteMessage(typ, msg); err != nil {
+			return err
+		}
+	}
+}
+
+func TestWebSocket_Echo(t *testing.T) {
+	url, errCh := newWebSocketServer(t, echoWebSocket, xhttp.UpgradeWebSocketSubprotocols("v2", "v1"))
+
+	c, resp, err := xhttp.DialWebSocket(context.Background(), url,
+		xhttp.DialWebSocketSubprotocols("v1", "v2"),
+		xhttp.DialWebSocketDialOptions(xnet.DialConnectTimeout(time.Second)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("status code mismatch: expected %d; got %d", http.StatusSwitchingProtocols, resp.StatusCode)
+	}
+	if c.Subprotocol() != "v1" {
+		t.Errorf("subprotocol mismatch: expected %q; got %q", "v1", c.Subprotocol())
+	}
+
+	testCases := []struct {
+		name string
+		typ  xhttp.WebSocketMessageType
+		msg  []byte
+	}{
+		{name: "text", typ: xhttp.WebSocketText, msg: []byte("hello")},
+		{name: "empty", typ: xhttp.WebSocketText, msg: []byte{}},
+		{name: "binary 16-bit length", typ: xhttp.WebSocketBinary, msg: bytes.Repeat([]byte{0xff}, 1000)},
+		{name: "binary 64-bit length", typ: xhttp.WebSocketBinary, msg: bytes.Repeat([]byte{0x01}, 70000)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := c.Ping([]byte("ping")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := c.WriteMessage(tc.typ, tc.msg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			typ, msg, err := c.ReadMessage()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if typ != tc.typ {
+				t.Errorf("type mismatch: expected %d; got %d", tc.typ, typ)
+			}
+			if !bytes.Equal(msg, tc.msg) {
+				t.Errorf("message mismatch: expected %d bytes; got %d bytes", len(tc.msg), len(msg))
+			}
+		})
+	}
+
+	if err := c.Close(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	var closeErr *xhttp.WebSocketCloseError
+	if err := <-errCh; !errors.As(err, &closeErr) || closeErr.Code != xhttp.WebSocketCloseNormal {
+		t.Errorf("error mismatch: expected close error %d; got %v", xhttp.WebSocketCloseNormal, err)
+	}
+	if err := c.WriteMessage(xhttp.WebSocketText, []byte("late")); !errors.Is(err, xhttp.ErrWebSocketClosed) {
+		t.Errorf("error mismatch: expected %v; got %v", xhttp.ErrWebSocketClosed, err)
+	}
+}
+
+func TestWebSocket_ServerClose(t *testing.T) {
+	url, errCh := newWebSocketServer(t, func(c *xhttp.WebSocketConn) error {
+		return c.CloseWithCode(xhttp.WebSocketCloseGoingAway, "shutting down")
+	})
+
+	c, _, err := xhttp.DialWebSocket(context.Background(), url)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	_, _, err = c.ReadMessage()
+
+	var closeErr *xhttp.WebSocketCloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != xhttp.WebSocketCloseGoingAway || closeErr.Reason != "shutting down" {
+		t.Errorf("error mismatch: expected close error %d; got %v", xhttp.WebSocketCloseGoingAway, err)
+	}
+	if !errors.Is(err, xhttp.ErrWebSocketClosed) {
+		t.Errorf("error mismatch: expected %v; got %v", xhttp.ErrWebSocketClosed, err)
+	}
+	if err := <-errCh; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestWebSocket_MessageTooLarge(t *testing.T) {
+	url, errCh := newWebSocketServer(t, echoWebSocket, xhttp.UpgradeWebSocketMaxMessageSize(4))
+
+	c, _, err := xhttp.DialWebSocket(context.Background(), url)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	if err := c.WriteMessage(xhttp.WebSocketBinary, []byte("too large")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var closeErr *xhttp.WebSocketCloseError
+	if _, _, err := c.ReadMessage(); !errors.As(err, &closeErr) || closeErr.Code != xhttp.WebSocketCloseMessageTooBig {
+		t.Errorf("error mismatch: expected close error %d; got %v", xhttp.WebSocketCloseMessageTooBig, err)
+	}
+	if err := <-errCh; !errors.Is(err, xhttp.ErrWebSocketMessageTooLarge) {
+		t.Errorf("error mismatch: expected %v; got %v", xhttp.ErrWebSocketMessageTooLarge, err)
+	}
+}
+
+func TestWebSocket_ProtocolError(t *testing.T) {
+	testCases := []struct {
+		name         string
+		frame        []byte
+		expectedCode int
+	}{
+		{
+			name:         "unmasked frame",
+			frame:        []byte{0x81, 0x01, 'a'},
+			expectedCode: xhttp.WebSocketCloseProtocolError,
+		},
+		{
+			name:         "unexpected continuation frame",
+			frame:        []byte{0x80, 0x81, 0, 0, 0, 0, 'a'},
+			expectedCode: xhttp.WebSocketCloseProtocolError,
+		},
+		{
+			name:         "invalid utf-8 text",
+			frame:        []byte{0x81, 0x81, 0, 0, 0, 0, 0xff},
+			expectedCode: xhttp.WebSocketCloseInvalidPayload,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			url, errCh := newWebSocketServer(t, echoWebSocket)
+
+			conn, err := net.Dial(xnet.NetworkTCP, strings.TrimPrefix(url, "ws://"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			fmt.Fprintf(conn, "GET / HTTP/1.1\r\nHost: example.com\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+				"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n")
+
+			br := bufio.NewReader(conn)
+			resp, err := http.ReadResponse(br, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if accept := resp.Header.Get(xhttp.HeaderSecWebSocketAccept); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+				t.Errorf("accept mismatch: expected %q; got %q", "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", accept)
+			}
+
+			if _, err := conn.Write(tc.frame); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := <-errCh; !errors.Is(err, xhttp.ErrWebSocketProtocol) {
+				t.Errorf("error mismatch: expected %v; got %v", xhttp.ErrWebSocketProtocol, err)
+			}
+
+			closeFrame := make([]byte, 4)
+			if _, err := br.Read(closeFrame); err != nil {
+				t.Fatal(err)
+			}
+			if code := int(closeFrame[2])<<8 | int(closeFrame[3]); closeFrame[0] != 0x88 || code != tc.expectedCode {
+				t.Errorf("close frame mismatch: expected code %d; got %x", tc.expectedCode, closeFrame)
+			}
+		})
+	}
+}
+
+func TestUpgradeWebSocket_Error(t *testing.T) {
+	validHeader := func() http.Header {
+		h := http.Header{}
+		h.Set(xhttp.HeaderConnection, "keep-alive, Upgrade")
+		h.Set(xhttp.HeaderUpgrade, "websocket")
+		h.Set(xhttp.HeaderSecWebSocketKey, "dGhlIHNhbXBsZSBub25jZQ==")
+		h.Set(xhttp.HeaderSecWebSocketVersion, "13")
+		return h
+	}
+
+	testCases := []struct {
+		name           string
+		method         string
+		header         func(h http.Header)
+		expectedStatus int
+	}{
+		{
+			name:           "method not allowed",
+			method:         http.MethodPost,
+			header:         func(http.Header) {},
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "not an upgrade",
+			method:         http.MethodGet,
+			header:         func(h http.Header) { h.Del(xhttp.HeaderUpgrade) },
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unsupported version",
+			method:         http.MethodGet,
+			header:         func(h http.Header) { h.Set(xhttp.HeaderSecWebSocketVersion, "8") },
+			expectedStatus: http.StatusUpgradeRequired,
+		},
+		{
+			name:           "invalid key",
+			method:         http.MethodGet,
+			header:         func(h http.Header) { h.Set(xhttp.HeaderSecWebSocketKey, "short") },
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "cross origin",
+			method:         http.MethodGet,
+			header:         func(h http.Header) { h.Set(xhttp.HeaderOrigin, "https://evil.com") },
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "hijacking not supported",
+			method:         http.MethodGet,
+			header:         func(h http.Header) { h.Set(xhttp.HeaderOrigin, "http://example.com") },
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, "http://example.com/ws", http.NoBody)
+			r.Header = validHeader()
+			tc.header(r.Header)
+			w := httptest.NewRecorder()
+
+			_, err := xhttp.UpgradeWebSocket(w, r)
+
+			if !errors.Is(err, xhttp.ErrWebSocketHandshake) {
+				t.Errorf("error mismatch: expected %v; got %v", xhttp.ErrWebSocketHandshake, err)
+			}
+			if w.Code != tc.expectedStatus {
+				t.Errorf("status code mismatch: expected %d; got %d", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestDialWebSocket_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "denied", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	_, resp, err := xhttp.DialWebSocket(context.Background(), "ws"+strings.TrimPrefix(srv.URL, "http"))
+	if !errors.Is(err, xhttp.ErrWebSocketHandshake) {
+		t.Errorf("error mismatch: expected %v; got %v", xhttp.ErrWebSocketHandshake, err)
+	}
+	if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("response mismatch: expected status code %d; got %v", http.StatusUnauthorized, resp)
+	}
+
+	if _, _, err := xhttp.DialWebSocket(context.Background(), srv.URL); err == nil {
+		t.Error("expected an error for an unsupported scheme")
+	}
+}
+
+func TestUpgradeWebSocketOptions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func() xhttp.UpgradeWebSocketOption
+		panic bool
+	}{
+		{
+			name:  "check origin nil",
+			fn:    func() xhttp.UpgradeWebSocketOption { return xhttp.UpgradeWebSocketCheckOrigin(nil) },
+			panic: true,
+		},
+		{
+			name: "check origin valid",
+			fn: func() xhttp.UpgradeWebSocketOption {
+				return xhttp.UpgradeWebSocketCheckOrigin(func(*http.Request) bool { return true })
+			},
+			panic: false,
+		},
+		{
+			name:  "max message size invalid",
+			fn:    func() xhttp.UpgradeWebSocketOption { return xhttp.UpgradeWebSocketMaxMessageSize(0) },
+			panic: true,
+		},
+		{
+			name:  "max message size valid",
+			fn:    func() xhttp.UpgradeWebSocketOption { return xhttp.UpgradeWebSocketMaxMessageSize(1) },
+			panic: false,
+		},
+		{
+			name:  "subprotocols",
+			fn:    func() xhttp.UpgradeWebSocketOption { return xhttp.UpgradeWebSocketSubprotocols("v1") },
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, tc.fn)
+		})
+	}
+}
+
+func TestDialWebSocketOptions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func() xhttp.DialWebSocketOption
+		panic bool
+	}{
+		{
+			name:  "dial options",
+			fn:    func() xhttp.DialWebSocketOption { return xhttp.DialWebSocketDialOptions() },
+			panic: false,
+		},
+		{
+			name:  "header nil",
+			fn:    func() xhttp.DialWebSocketOption { return xhttp.DialWebSocketHeader(nil) },
+			panic: true,
+		},
+		{
+			name:  "header valid",
+			fn:    func() xhttp.DialWebSocketOption { return xhttp.DialWebSocketHeader(http.Header{}) },
+			panic: false,
+		},
+		{
+			name:  "max message size invalid",
+			fn:    func() xhttp.DialWebSocketOption { return xhttp.DialWebSocketMaxMessageSize(-1) },
+			panic: true,
+		},
+		{
+			name:  "max message size valid",
+			fn:    func() xhttp.DialWebSocketOption { return xhttp.DialWebSocketMaxMessageSize(1) },
+			panic: false,
+		},
+		{
+			name:  "subprotocols",
+			fn:    func() xhttp.DialWebSocketOption { return xhttp.DialWebSocketSubprotocols("v1") },
+			panic: false,
+		},
+		{
+			name:  "tls config nil",
+			fn:    func() xhttp.DialWebSocketOption { return xhttp.DialWebSocketTLSConfig(nil) },
+			panic: true,
+		},
+		{
+			name:  "tls config valid",
+			fn:    func() xhttp.DialWebSocketOption { return xhttp.DialWebSocketTLSConfig(&tls.Config{}) },
+			panic: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testOptionPanic(t, tc.panic, tc.fn)
+		})
+	}
+}
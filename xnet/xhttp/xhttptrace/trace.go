@@ -6,16 +6,122 @@ package xhttptrace
 
 import (
 	"context"
+	"time"
+)
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+// Circuit breaker states.
+const (
+	// CircuitClosed is the state of a circuit breaker letting requests through.
+	CircuitClosed CircuitState = iota
+
+	// CircuitHalfOpen is the state of a circuit breaker letting trial requests through.
+	CircuitHalfOpen
+
+	// CircuitOpen is the state of a circuit breaker failing requests fast.
+	CircuitOpen
 )
 
 type (
 	// ClientTrace is a set of hooks to run at various stages of an outgoing
 	// HTTP request. Any particular hook may be nil.
 	ClientTrace struct {
+		// AttemptDone is called when an attempt of a round trip made by a transport
+		// making several attempts, e.g. a RetryTransport, completes.
+		AttemptDone func(AttemptDoneInfo)
+
+		// AttemptStart is called when an attempt of a round trip made by a transport
+		// making several attempts, e.g. a RetryTransport, starts.
+		AttemptStart func(AttemptStartInfo)
+
+		// BackoffWait is called before waiting for the next attempt of a round trip.
+		BackoffWait func(BackoffWaitInfo)
+
+		// CacheHit is called when a response is served from a cache.
+		CacheHit func(CacheHitInfo)
+
+		// CacheMiss is called when a cacheable request cannot be served from a cache.
+		CacheMiss func(CacheMissInfo)
+
+		// CircuitStateChange is called when the state of a circuit breaker changes.
+		CircuitStateChange func(CircuitStateChangeInfo)
+
+		// Hedge is called when a hedged request is launched.
+		Hedge func(HedgeInfo)
+
 		// Retry is called before a round trip retry is made.
 		Retry func(RetryInfo)
 	}
 
+	// AttemptDoneInfo contains information about a completed attempt of an HTTP request.
+	AttemptDoneInfo struct {
+		// Attempt is the number of the attempt, starting at 1.
+		Attempt int
+
+		// StatusCode is the HTTP response code of the attempt, or 0 if it failed.
+		StatusCode int
+
+		// Err is the error returned by the attempt, if any.
+		Err error
+
+		// Duration is the duration of the attempt.
+		Duration time.Duration
+	}
+
+	// AttemptStartInfo contains information about a starting attempt of an HTTP request.
+	AttemptStartInfo struct {
+		// Attempt is the number of the attempt, starting at 1.
+		Attempt int
+	}
+
+	// BackoffWaitInfo contains information about the wait before the next attempt of an HTTP request.
+	BackoffWaitInfo struct {
+		// Attempt is the number of the attempt that completed before waiting.
+		Attempt int
+
+		// Duration is the duration of the wait.
+		Duration time.Duration
+	}
+
+	// CacheHitInfo contains information about an HTTP response served from a cache.
+	CacheHitInfo struct {
+		// Key is the cache key of the response.
+		Key string
+
+		// Revalidated reports whether the stored response was validated with the origin server.
+		Revalidated bool
+	}
+
+	// CacheMissInfo contains information about an HTTP request not served from a cache.
+	CacheMissInfo struct {
+		// Key is the cache key of the request.
+		Key string
+
+		// Stale reports whether a stored response existed but could not be used.
+		Stale bool
+	}
+
+	// CircuitStateChangeInfo contains information about the state change of a circuit breaker.
+	CircuitStateChangeInfo struct {
+		// Host is the host guarded by the circuit breaker, if any.
+		Host string
+
+		// From is the previous state of the circuit breaker.
+		From CircuitState
+
+		// To is the new state of the circuit breaker.
+		To CircuitState
+	}
+
+	// HedgeInfo contains information about the hedged HTTP request.
+	HedgeInfo struct {
+		// HedgeCount is the number of hedged requests launched so far for a given HTTP request,
+		// the original request excluded.
+		HedgeCount int
+	}
+
 	// RetryInfo contains information about the HTTP request retry.
 	RetryInfo struct {
 		// RetryCount is the retry count for a given HTTP request.
@@ -44,3 +150,17 @@ func WithClientTrace(parent context.Context, trace *ClientTrace) context.Context
 	}
 	return context.WithValue(parent, clientEventContextKey{}, trace)
 }
+
+// String returns the name of the circuit breaker state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitHalfOpen:
+		return "half-open"
+	case CircuitOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
@@ -42,3 +42,24 @@ func TestContextClientTrace(t *testing.T) {
 		})
 	}
 }
+
+func TestCircuitState_String(t *testing.T) {
+	testCases := []struct {
+		name     string
+		state    xhttptrace.CircuitState
+		expected string
+	}{
+		{name: "closed", state: xhttptrace.CircuitClosed, expected: "closed"},
+		{name: "half-open", state: xhttptrace.CircuitHalfOpen, expected: "half-open"},
+		{name: "open", state: xhttptrace.CircuitOpen, expected: "open"},
+		{name: "unknown", state: xhttptrace.CircuitState(-1), expected: "unknown"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.state.String(); got != tc.expected {
+				t.Errorf("expected %q; got %q", tc.expected, got)
+			}
+		})
+	}
+}
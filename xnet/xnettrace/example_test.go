@@ -0,0 +1,30 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnettrace_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jlourenc/xgo/xnet/xnettrace"
+)
+
+func Example() {
+	ctx := context.Background()
+	ctx = xnettrace.WithDialTrace(ctx, &xnettrace.DialTrace{
+		DialStart: func(i xnettrace.DialStartInfo) {
+			fmt.Printf("dial %s %s, attempt: %d", i.Network, i.Address, i.Attempt)
+		},
+	})
+
+	trace := xnettrace.ContextDialTrace(ctx)
+	trace.DialStart(xnettrace.DialStartInfo{
+		Network: "tcp",
+		Address: "localhost:8080",
+		Attempt: 1,
+	})
+
+	// Output: dial tcp localhost:8080, attempt: 1
+}
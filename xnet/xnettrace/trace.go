@@ -0,0 +1,119 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package xnettrace provides mechanisms to trace the events of connections established by an xnet.Dialer.
+package xnettrace
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+type (
+	// DialTrace is a set of hooks to run at various stages of a connection
+	// establishment. Any particular hook may be nil.
+	DialTrace struct {
+		// DialDone is called when an attempt to establish a connection completes.
+		DialDone func(DialDoneInfo)
+
+		// DialStart is called when an attempt to establish a connection starts.
+		DialStart func(DialStartInfo)
+
+		// ResolveDone is called when a hostname lookup made by the Dialer itself completes,
+		// i.e. when it is configured with a resolver or Happy Eyeballs.
+		ResolveDone func(ResolveDoneInfo)
+
+		// ResolveStart is called when a hostname lookup made by the Dialer itself starts.
+		ResolveStart func(ResolveStartInfo)
+
+		// RetryWait is called before waiting for the next attempt to establish a connection.
+		RetryWait func(RetryWaitInfo)
+	}
+
+	// DialDoneInfo contains information about a completed connection attempt.
+	DialDoneInfo struct {
+		// Network is the network dialed.
+		Network string
+
+		// Address is the address dialed.
+		Address string
+
+		// Attempt is the number of the attempt, starting at 1.
+		Attempt int
+
+		// RemoteAddr is the remote address of the connection, if established.
+		RemoteAddr net.Addr
+
+		// Err is the error returned by the attempt, if any.
+		Err error
+
+		// Duration is the duration of the attempt.
+		Duration time.Duration
+	}
+
+	// DialStartInfo contains information about a starting connection attempt.
+	DialStartInfo struct {
+		// Network is the network dialed.
+		Network string
+
+		// Address is the address dialed.
+		Address string
+
+		// Attempt is the number of the attempt, starting at 1.
+		Attempt int
+	}
+
+	// ResolveDoneInfo contains information about a completed hostname lookup.
+	ResolveDoneInfo struct {
+		// Host is the hostname looked up.
+		Host string
+
+		// Addrs are the IP addresses the host resolved to.
+		Addrs []net.IPAddr
+
+		// Err is the error returned by the lookup, if any.
+		Err error
+
+		// Duration is the duration of the lookup.
+		Duration time.Duration
+	}
+
+	// ResolveStartInfo contains information about a starting hostname lookup.
+	ResolveStartInfo struct {
+		// Host is the hostname looked up.
+		Host string
+	}
+
+	// RetryWaitInfo contains information about the wait before the next connection attempt.
+	RetryWaitInfo struct {
+		// Attempt is the number of the attempt that failed before waiting.
+		Attempt int
+
+		// Err is the error returned by the attempt.
+		Err error
+
+		// Duration is the duration of the wait.
+		Duration time.Duration
+	}
+
+	dialEventContextKey struct{}
+)
+
+// ContextDialTrace returns the DialTrace value stored in ctx.
+// If none, it returns nil.
+func ContextDialTrace(ctx context.Context) *DialTrace {
+	trace, _ := ctx.Value(dialEventContextKey{}).(*DialTrace) //nolint:errcheck,revive // nil returned if none.
+	return trace
+}
+
+// WithDialTrace returns a new context based on the provided parent ctx.
+// Connections established by an xnet.Dialer with the returned context will use
+// the provided trace hooks.
+func WithDialTrace(parent context.Context, trace *DialTrace) context.Context {
+	if trace == nil {
+		return parent
+	}
+	return context.WithValue(parent, dialEventContextKey{}, trace)
+}
@@ -0,0 +1,42 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xnettrace_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xnettrace"
+)
+
+func TestContextDialTrace(t *testing.T) {
+	testCases := []struct {
+		name  string
+		trace *xnettrace.DialTrace
+	}{
+		{
+			name:  "nil trace",
+			trace: nil,
+		},
+		{
+			name: "specified trace",
+			trace: &xnettrace.DialTrace{
+				DialStart: func(_ xnettrace.DialStartInfo) {},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := xnettrace.WithDialTrace(context.Background(), tc.trace)
+
+			got := xnettrace.ContextDialTrace(ctx)
+
+			if tc.trace != got {
+				t.Errorf("expected %v; got %v", tc.trace, got)
+			}
+		})
+	}
+}
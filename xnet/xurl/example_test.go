@@ -6,10 +6,21 @@ package xurl_test
 
 import (
 	"fmt"
+	"net/url"
 
 	"github.com/jlourenc/xgo/xnet/xurl"
 )
 
+func ExampleExpandTemplate() {
+	u, _ := xurl.ExpandTemplate("https://api.example.com/users/{id}/posts{?page,limit}", map[string]string{
+		"id":   "42",
+		"page": "2",
+	})
+
+	fmt.Printf("%s\n", u)
+	// Output: https://api.example.com/users/42/posts?page=2
+}
+
 func ExampleJoinBasePath() {
 	path := xurl.JoinBasePath("https://pkg.go.dev/", "github.com", "jlourenc", "xgo")
 
@@ -23,3 +34,29 @@ func ExampleJoinPath() {
 	fmt.Printf("%s\n", path)
 	// Output: github.com/jlourenc/xgo
 }
+
+func ExampleQuery() {
+	u, _ := url.Parse("https://example.com/search?lang=en")
+
+	xurl.NewQuery().
+		Set("q", "gopher").
+		Int("page", 2).
+		Bool("exact", true).
+		AppendTo(u)
+
+	fmt.Printf("%s\n", u)
+	// Output: https://example.com/search?exact=true&lang=en&page=2&q=gopher
+}
+
+func ExamplePattern() {
+	p := xurl.MustParsePattern("/users/{id}/files/{path...}")
+
+	params, ok := p.Match("/users/42/files/docs/report.pdf")
+	fmt.Println(params["id"], params["path"], ok)
+
+	path, _ := p.Build(map[string]string{"id": "43", "path": "a b.txt"})
+	fmt.Println(path)
+	// Output:
+	// 42 docs/report.pdf true
+	// /users/43/files/a%20b.txt
+}
@@ -0,0 +1,263 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xurl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"unicode/utf8"
+)
+
+// Punycode parameters (RFC 3492, section 5).
+const (
+	punycodeBase        = 36
+	punycodeDamp        = 700
+	punycodeInitialBias = 72
+	punycodeInitialN    = 128
+	punycodeSkew        = 38
+	punycodeTMax        = 26
+	punycodeTMin        = 1
+)
+
+const (
+	acePrefix           = "xn--"
+	hostLabelMaxLength  = 63
+	hostnameMaxLength   = 253
+	punycodeMaxDecoding = math.MaxInt32
+)
+
+// ErrInvalidHost is returned when a host cannot be converted to or from its ASCII form.
+var ErrInvalidHost = errors.New("invalid host")
+
+// labelSeparators are the characters separating the labels of an internationalized hostname (RFC 3490, section 3.1).
+var labelSeparators = strings.NewReplacer("。", ".", "．", ".", "｡", ".")
+
+// ToASCIIHost converts the internationalized hostname host, e.g. "bücher.example", to its ASCII form, e.g.
+// "xn--bcher-kva.example", that can be dialed and compared: labels are lowercased and those which are not ASCII
+// are encoded with Punycode (RFC 3492). IP addresses are returned as is.
+//
+// Labels are not otherwise mapped nor normalized as defined by UTS #46, hostnames being expected in their
+// canonical form, e.g. NFC. It fails with ErrInvalidHost if a label is empty or too long.
+func ToASCIIHost(host string) (string, error) {
+	if isIPHost(host) {
+		return host, nil
+	}
+
+	labels := strings.Split(labelSeparators.Replace(host), ".")
+	for i, label := range labels {
+		if label == "" && i == len(labels)-1 && i > 0 {
+			break // Trailing dot of a fully qualified domain name.
+		}
+		if label == "" {
+			return "", fmt.Errorf("%w: empty label in %q", ErrInvalidHost, host)
+		}
+
+		label = strings.ToLower(label)
+		if !isASCII(label) {
+			label = acePrefix + punycodeEncode(label)
+		}
+		if len(label) > hostLabelMaxLength {
+			return "", fmt.Errorf("%w: label too long in %q", ErrInvalidHost, host)
+		}
+		labels[i] = label
+	}
+
+	ascii := strings.Join(labels, ".")
+	if len(ascii) > hostnameMaxLength+1 || (len(ascii) > hostnameMaxLength && !strings.HasSuffix(ascii, ".")) {
+		return "", fmt.Errorf("%w: %q too long", ErrInvalidHost, host)
+	}
+	return ascii, nil
+}
+
+// ToUnicodeHost converts the hostname host in ASCII form, e.g. "xn--bcher-kva.example", to its Unicode form,
+// e.g. "bücher.example", for display purposes: labels prefixed with "xn--" are decoded with Punycode (RFC 3492).
+// IP addresses are returned as is. It fails with ErrInvalidHost if a label is not valid Punycode.
+func ToUnicodeHost(host string) (string, error) {
+	if isIPHost(host) {
+		return host, nil
+	}
+
+	labels := strings.Split(host, ".")
+	for i, label := range labels {
+		if len(label) < len(acePrefix) || !strings.EqualFold(label[:len(acePrefix)], acePrefix) {
+			continue
+		}
+
+		decoded, err := punycodeDecode(strings.ToLower(label[len(acePrefix):]))
+		if err != nil {
+			return "", fmt.Errorf("%w: label %q: %w", ErrInvalidHost, label, err)
+		}
+		labels[i] = decoded
+	}
+	return strings.Join(labels, "."), nil
+}
+
+func isIPHost(host string) bool {
+	return net.ParseIP(strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")) != nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// punycodeEncode encodes s with Punycode (RFC 3492, section 6.3).
+func punycodeEncode(s string) string {
+	runes := []rune(s)
+
+	var out []byte
+	for _, r := range runes {
+		if r < utf8.RuneSelf {
+			out = append(out, byte(r))
+		}
+	}
+	b := len(out)
+	if b > 0 {
+		out = append(out, '-')
+	}
+
+	n, delta, bias := punycodeInitialN, 0, punycodeInitialBias
+	for h := b; h < len(runes); {
+		m := math.MaxInt32
+		for _, r := range runes {
+			if int(r) >= n && int(r) < m {
+				m = int(r)
+			}
+		}
+
+		delta += (m - n) * (h + 1)
+		n = m
+		for _, r := range runes {
+			if int(r) < n {
+				delta++
+			}
+			if int(r) != n {
+				continue
+			}
+
+			q := delta
+			for k := punycodeBase; ; k += punycodeBase {
+				t := punycodeThreshold(k, bias)
+				if q < t {
+					break
+				}
+				out = append(out, punycodeDigit(t+(q-t)%(punycodeBase-t)))
+				q = (q - t) / (punycodeBase - t)
+			}
+			out = append(out, punycodeDigit(q))
+
+			bias = punycodeAdapt(delta, h+1, h == b)
+			delta = 0
+			h++
+		}
+		delta++
+		n++
+	}
+	return string(out)
+}
+
+// punycodeDecode decodes s with Punycode (RFC 3492, section 6.2).
+func punycodeDecode(s string) (string, error) {
+	var out []rune
+	start := 0
+	if pos := strings.LastIndexByte(s, '-'); pos >= 0 {
+		for i := 0; i < pos; i++ {
+			if s[i] >= utf8.RuneSelf {
+				return "", errors.New("invalid basic code point")
+			}
+			out = append(out, rune(s[i]))
+		}
+		start = pos + 1
+	}
+
+	n, i, bias := punycodeInitialN, 0, punycodeInitialBias
+	for in := start; in < len(s); {
+		oldi, w := i, 1
+		for k := punycodeBase; ; k += punycodeBase {
+			if in >= len(s) {
+				return "", errors.New("truncated input")
+			}
+			digit, ok := punycodeDigitValue(s[in])
+			in++
+			if !ok {
+				return "", fmt.Errorf("invalid digit %q", s[in-1])
+			}
+			if digit > (punycodeMaxDecoding-i)/w {
+				return "", errors.New("overflow")
+			}
+			i += digit * w
+
+			t := punycodeThreshold(k, bias)
+			if digit < t {
+				break
+			}
+			if w > punycodeMaxDecoding/(punycodeBase-t) {
+				return "", errors.New("overflow")
+			}
+			w *= punycodeBase - t
+		}
+
+		length := len(out) + 1
+		bias = punycodeAdapt(i-oldi, length, oldi == 0)
+		n += i / length
+		i %= length
+		if n > utf8.MaxRune {
+			return "", errors.New("invalid code point")
+		}
+
+		out = append(out, 0)
+		copy(out[i+1:], out[i:])
+		out[i] = rune(n)
+		i++
+	}
+	return string(out), nil
+}
+
+func punycodeAdapt(delta, numPoints int, first bool) int {
+	if first {
+		delta /= punycodeDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+
+	k := 0
+	for delta > ((punycodeBase-punycodeTMin)*punycodeTMax)/2 {
+		delta /= punycodeBase - punycodeTMin
+		k += punycodeBase
+	}
+	return k + (punycodeBase-punycodeTMin+1)*delta/(delta+punycodeSkew)
+}
+
+func punycodeThreshold(k, bias int) int {
+	return min(max(k-bias, punycodeTMin), punycodeTMax)
+}
+
+func punycodeDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
+
+func punycodeDigitValue(c byte) (int, bool) {
+	switch {
+	case 'a' <= c && c <= 'z':
+		return int(c - 'a'), true
+	case 'A' <= c && c <= 'Z':
+		return int(c - 'A'), true
+	case '0' <= c && c <= '9':
+		return int(c-'0') + 26, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xurl_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xurl"
+)
+
+func TestToASCIIHost(t *testing.T) {
+	testCases := []struct {
+		name        string
+		host        string
+		expected    string
+		expectedErr bool
+	}{
+		{name: "ascii", host: "Example.COM", expected: "example.com"},
+		{name: "fully qualified", host: "example.com.", expected: "example.com."},
+		{name: "latin", host: "Bücher.example", expected: "xn--bcher-kva.example"},
+		{name: "japanese", host: "例え.テスト", expected: "xn--r8jz45g.xn--zckzah"},
+		{name: "chinese", host: "他们为什么不说中文.example", expected: "xn--ihqwcrb4cv8a8dqg056pqjye.example"},
+		{name: "arabic", host: "ليهمابتكلموشعربي؟", expected: "xn--egbpdaj6bu4bxfgehfvwxn"},
+		{name: "mixed", host: "3年B組金八先生", expected: "xn--3b-ww4c5e180e575a65lsy2b"},
+		{name: "ideographic full stop", host: "bücher。example", expected: "xn--bcher-kva.example"},
+		{name: "ipv4", host: "192.0.2.1", expected: "192.0.2.1"},
+		{name: "ipv6", host: "[2001:db8::1]", expected: "[2001:db8::1]"},
+		{name: "empty label", host: "example..com", expectedErr: true},
+		{name: "label too long", host: strings.Repeat("ü", 60) + ".example", expectedErr: true},
+		{name: "host too long", host: strings.Repeat("a.", 127) + "ab", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := xurl.ToASCIIHost(tc.host)
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error is %t, got %v", tc.expectedErr, err)
+			}
+			if err != nil && !errors.Is(err, xurl.ErrInvalidHost) {
+				t.Errorf("error mismatch: expected %v; got %v", xurl.ErrInvalidHost, err)
+			}
+			if got != tc.expected {
+				t.Errorf("expected %v; got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestToUnicodeHost(t *testing.T) {
+	testCases := []struct {
+		name        string
+		host        string
+		expected    string
+		expectedErr bool
+	}{
+		{name: "ascii", host: "example.com", expected: "example.com"},
+		{name: "latin", host: "xn--bcher-kva.example", expected: "bücher.example"},
+		{name: "uppercase prefix", host: "XN--BCHER-KVA.example", expected: "bücher.example"},
+		{name: "japanese", host: "xn--r8jz45g.xn--zckzah", expected: "例え.テスト"},
+		{name: "chinese", host: "xn--ihqwcrb4cv8a8dqg056pqjye", expected: "他们为什么不说中文"},
+		{name: "mixed", host: "xn--3b-ww4c5e180e575a65lsy2b", expected: "3年b組金八先生"},
+		{name: "ipv6", host: "[2001:db8::1]", expected: "[2001:db8::1]"},
+		{name: "invalid digit", host: "xn--bcher-kv!.example", expectedErr: true},
+		{name: "truncated", host: "xn--bcher-k.example", expectedErr: true},
+		{name: "overflow", host: "xn--99999999999999", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := xurl.ToUnicodeHost(tc.host)
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error is %t, got %v", tc.expectedErr, err)
+			}
+			if err != nil && !errors.Is(err, xurl.ErrInvalidHost) {
+				t.Errorf("error mismatch: expected %v; got %v", xurl.ErrInvalidHost, err)
+			}
+			if got != tc.expected {
+				t.Errorf("expected %v; got %v", tc.expected, got)
+			}
+		})
+	}
+}
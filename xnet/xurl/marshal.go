@@ -0,0 +1,282 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xurl
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jlourenc/xgo/xtime"
+)
+
+const (
+	queryLayoutTagName = "layout"
+	queryTagName       = "url"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timeType            = reflect.TypeOf(time.Time{})
+)
+
+type queryField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	layout    string
+}
+
+// MarshalQuery returns the query values encoding v, a struct or a pointer to a struct.
+//
+// Each exported field is encoded as the value of a query parameter named after its `url` struct tag, e.g.
+// `url:"page"`, or the field name if it has none. The "omitempty" option, e.g. `url:"page,omitempty"`,
+// skips the field if it has a zero value, and the "-" name skips the field. Fields of embedded structs
+// are encoded as if they were fields of v.
+//
+// Supported field types are strings, booleans, numbers, time.Duration, time.Time, types implementing
+// encoding.TextMarshaler, e.g. xunit.Byte, as well as pointers to and slices of these types, encoded as
+// multiple values. Nil pointers are skipped. Times are formatted according to the layout of the `layout`
+// struct tag, e.g. `layout:"2006-01-02"`, or xtime.RFC3339Milli.
+func MarshalQuery(v any) (url.Values, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return url.Values{}, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported type %T: struct expected", v)
+	}
+
+	values := url.Values{}
+	for _, f := range queryFields(rv.Type(), nil) {
+		fv := rv.FieldByIndex(f.index)
+		if f.omitEmpty && (fv.IsZero() || (fv.Kind() == reflect.Slice && fv.Len() == 0)) {
+			continue
+		}
+
+		vals, err := appendQueryValues(nil, fv, f.layout)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.name, err)
+		}
+		if len(vals) > 0 {
+			values[f.name] = vals
+		}
+	}
+	return values, nil
+}
+
+// UnmarshalQuery decodes values into v, a non-nil pointer to a struct, following the rules of MarshalQuery.
+// Fields without values are left unchanged, values without fields are ignored. Times are parsed according to
+// the layout of the `layout` struct tag, or as RFC 3339 times, fractional seconds included. Fields which are
+// not slices are set from the first value of their parameter.
+func UnmarshalQuery(values url.Values, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("unsupported type %T: non-nil pointer to struct expected", v)
+	}
+	rv = rv.Elem()
+
+	for _, f := range queryFields(rv.Type(), nil) {
+		vals, ok := values[f.name]
+		if !ok || len(vals) == 0 {
+			continue
+		}
+		if err := setQueryValues(rv.FieldByIndex(f.index), vals, f.layout); err != nil {
+			return fmt.Errorf("field %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// queryFields returns the fields of the struct type t to encode, flattening embedded structs.
+func queryFields(t reflect.Type, index []int) []queryField {
+	var fields []queryField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, hasTag := sf.Tag.Lookup(queryTagName)
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		fieldIndex := append(append([]int(nil), index...), i)
+		if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct && !isQueryScalar(sf.Type) {
+			fields = append(fields, queryFields(sf.Type, fieldIndex)...)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		if !hasTag || name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, queryField{
+			name:      name,
+			index:     fieldIndex,
+			omitEmpty: opts == "omitempty",
+			layout:    sf.Tag.Get(queryLayoutTagName),
+		})
+	}
+	return fields
+}
+
+// isQueryScalar reports whether t, which is not a pointer, is encoded as a single value despite its kind.
+func isQueryScalar(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return t.Kind() != reflect.Pointer && (t == timeType || t == durationType ||
+		t.Implements(textMarshalerType) || pt.Implements(textMarshalerType) || pt.Implements(textUnmarshalerType))
+}
+
+func appendQueryValues(vals []string, fv reflect.Value, layout string) ([]string, error) {
+	switch {
+	case fv.Kind() == reflect.Pointer:
+		if fv.IsNil() {
+			return vals, nil
+		}
+		return appendQueryValues(vals, fv.Elem(), layout)
+	case fv.Kind() == reflect.Slice && !isQueryScalar(fv.Type()):
+		for i := 0; i < fv.Len(); i++ {
+			var err error
+			if vals, err = appendQueryValues(vals, fv.Index(i), layout); err != nil {
+				return nil, err
+			}
+		}
+		return vals, nil
+	}
+
+	s, err := formatQueryValue(fv, layout)
+	if err != nil {
+		return nil, err
+	}
+	return append(vals, s), nil
+}
+
+func formatQueryValue(fv reflect.Value, layout string) (string, error) {
+	switch fv.Type() {
+	case timeType:
+		if layout == "" {
+			layout = xtime.RFC3339Milli
+		}
+		return fv.Interface().(time.Time).Format(layout), nil
+	case durationType:
+		return fv.Interface().(time.Duration).String(), nil
+	}
+
+	if fv.Type().Implements(textMarshalerType) {
+		b, err := fv.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	if fv.CanAddr() && fv.Addr().Type().Implements(textMarshalerType) {
+		b, err := fv.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		return fv.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(fv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(fv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(fv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(fv.Float(), 'g', -1, fv.Type().Bits()), nil
+	default:
+		return "", fmt.Errorf("unsupported type %s", fv.Type())
+	}
+}
+
+func setQueryValues(fv reflect.Value, vals []string, layout string) error {
+	switch {
+	case fv.Kind() == reflect.Pointer:
+		elem := reflect.New(fv.Type().Elem())
+		if err := setQueryValues(elem.Elem(), vals, layout); err != nil {
+			return err
+		}
+		fv.Set(elem)
+		return nil
+	case fv.Kind() == reflect.Slice && !isQueryScalar(fv.Type()):
+		slice := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+		for i, s := range vals {
+			if err := setQueryValues(slice.Index(i), []string{s}, layout); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+
+	return parseQueryValue(fv, vals[0], layout)
+}
+
+func parseQueryValue(fv reflect.Value, s, layout string) error {
+	switch fv.Type() {
+	case timeType:
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	if reflect.PointerTo(fv.Type()).Implements(textUnmarshalerType) {
+		return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	default:
+		return errors.New("unsupported type " + fv.Type().String())
+	}
+	return nil
+}
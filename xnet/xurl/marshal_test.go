@@ -0,0 +1,237 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xurl_test
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet/xurl"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+type Pagination struct {
+	Page  int `url:"page,omitempty"`
+	Limit int `url:"limit,omitempty"`
+}
+
+type searchParams struct {
+	Pagination
+
+	Query    string        `url:"q"`
+	Tags     []string      `url:"tag,omitempty"`
+	Exact    bool          `url:"exact"`
+	Score    *float64      `url:"score"`
+	Since    time.Time     `url:"since"`
+	Until    time.Time     `url:"until" layout:"2006-01-02"`
+	Timeout  time.Duration `url:"timeout,omitempty"`
+	MaxSize  xunit.Byte    `url:"max_size,omitempty"`
+	Untagged uint8
+	Ignored  string `url:"-"`
+	internal string
+}
+
+func TestMarshalQuery(t *testing.T) {
+	score := 0.5
+	date := time.Date(2024, time.March, 1, 10, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name        string
+		v           any
+		expected    url.Values
+		expectedErr bool
+	}{
+		{
+			name: "all fields",
+			v: &searchParams{
+				Pagination: Pagination{Page: 2, Limit: 10},
+				Query:      "gopher",
+				Tags:       []string{"go", "net"},
+				Exact:      true,
+				Score:      &score,
+				Since:      date,
+				Until:      date,
+				Timeout:    1500 * time.Millisecond,
+				MaxSize:    10 * xunit.MB,
+				Untagged:   7,
+				Ignored:    "ignored",
+				internal:   "internal",
+			},
+			expected: url.Values{
+				"page":     {"2"},
+				"limit":    {"10"},
+				"q":        {"gopher"},
+				"tag":      {"go", "net"},
+				"exact":    {"true"},
+				"score":    {"0.5"},
+				"since":    {"2024-03-01T10:30:00.000Z"},
+				"until":    {"2024-03-01"},
+				"timeout":  {"1.5s"},
+				"max_size": {"10MB"},
+				"Untagged": {"7"},
+			},
+		},
+		{
+			name: "empty fields",
+			v:    searchParams{},
+			expected: url.Values{
+				"q":        {""},
+				"exact":    {"false"},
+				"since":    {"0001-01-01T00:00:00.000Z"},
+				"until":    {"0001-01-01"},
+				"Untagged": {"0"},
+			},
+		},
+		{
+			name:     "nil pointer",
+			v:        (*searchParams)(nil),
+			expected: url.Values{},
+		},
+		{
+			name:        "not a struct",
+			v:           "q=gopher",
+			expectedErr: true,
+		},
+		{
+			name:        "unsupported field",
+			v:           struct{ M map[string]string }{M: map[string]string{}},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := xurl.MarshalQuery(tc.v)
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error is %t, got %v", tc.expectedErr, err)
+			}
+			if !reflect.DeepEqual(tc.expected, got) {
+				t.Errorf("expected %v; got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestUnmarshalQuery(t *testing.T) {
+	score := 0.5
+
+	testCases := []struct {
+		name        string
+		values      url.Values
+		v           any
+		expected    any
+		expectedErr bool
+	}{
+		{
+			name: "all fields",
+			values: url.Values{
+				"page":     {"2"},
+				"limit":    {"10"},
+				"q":        {"gopher", "ignored"},
+				"tag":      {"go", "net"},
+				"exact":    {"true"},
+				"score":    {"0.5"},
+				"since":    {"2024-03-01T10:30:00.250Z"},
+				"until":    {"2024-03-01"},
+				"timeout":  {"1.5s"},
+				"max_size": {"10MB"},
+				"Untagged": {"7"},
+				"Ignored":  {"ignored"},
+				"unknown":  {"unknown"},
+			},
+			v: &searchParams{},
+			expected: &searchParams{
+				Pagination: Pagination{Page: 2, Limit: 10},
+				Query:      "gopher",
+				Tags:       []string{"go", "net"},
+				Exact:      true,
+				Score:      &score,
+				Since:      time.Date(2024, time.March, 1, 10, 30, 0, 250e6, time.UTC),
+				Until:      time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+				Timeout:    1500 * time.Millisecond,
+				MaxSize:    10 * xunit.MB,
+				Untagged:   7,
+			},
+		},
+		{
+			name:     "missing fields unchanged",
+			values:   url.Values{"q": {"gopher"}},
+			v:        &searchParams{Pagination: Pagination{Page: 3}},
+			expected: &searchParams{Pagination: Pagination{Page: 3}, Query: "gopher"},
+		},
+		{
+			name:        "invalid value",
+			values:      url.Values{"page": {"two"}},
+			v:           &searchParams{},
+			expected:    &searchParams{},
+			expectedErr: true,
+		},
+		{
+			name:        "out of range value",
+			values:      url.Values{"Untagged": {"256"}},
+			v:           &searchParams{},
+			expected:    &searchParams{},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid time",
+			values:      url.Values{"until": {"2024-03-01T10:30:00Z"}},
+			v:           &searchParams{},
+			expected:    &searchParams{},
+			expectedErr: true,
+		},
+		{
+			name:        "not a pointer",
+			values:      url.Values{},
+			v:           searchParams{},
+			expected:    searchParams{},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := xurl.UnmarshalQuery(tc.values, tc.v)
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error is %t, got %v", tc.expectedErr, err)
+			}
+			if !reflect.DeepEqual(tc.expected, tc.v) {
+				t.Errorf("expected %+v; got %+v", tc.expected, tc.v)
+			}
+		})
+	}
+}
+
+func TestMarshalQuery_RoundTrip(t *testing.T) {
+	score := 0.25
+	expected := searchParams{
+		Pagination: Pagination{Page: 1},
+		Query:      "a&b=c",
+		Tags:       []string{"x"},
+		Score:      &score,
+		Since:      time.Date(2024, time.March, 1, 10, 30, 0, 0, time.UTC),
+		Until:      time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC),
+		MaxSize:    1536 * xunit.KiB,
+	}
+
+	values, err := xurl.MarshalQuery(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err = url.ParseQuery(values.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got searchParams
+	if err = xurl.UnmarshalQuery(values, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %+v; got %+v", expected, got)
+	}
+}
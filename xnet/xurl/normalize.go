@@ -0,0 +1,222 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xurl
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// NormalizeFlag is a normalization applied by Normalize. Flags can be combined with the | operator.
+type NormalizeFlag uint
+
+// Enumeration of normalization flags.
+const (
+	// NormalizeLowercaseScheme lowercases the scheme, e.g. "HTTP://example.com" to "http://example.com".
+	NormalizeLowercaseScheme NormalizeFlag = 1 << iota
+	// NormalizeLowercaseHost lowercases the host, e.g. "http://EXAMPLE.com" to "http://example.com".
+	NormalizeLowercaseHost
+	// NormalizeRemoveDefaultPort removes the port if it is the default one of the scheme,
+	// e.g. "http://example.com:80/" to "http://example.com/", as well as an empty port.
+	NormalizeRemoveDefaultPort
+	// NormalizeRemoveDotSegments resolves the "." and ".." segments of the path,
+	// e.g. "http://example.com/a/./b/../c" to "http://example.com/a/c".
+	NormalizeRemoveDotSegments
+	// NormalizePercentEncoding uppercases the hexadecimal digits of percent-encoded octets and decodes
+	// those of unreserved characters, e.g. "http://example.com/%7euser%2f" to "http://example.com/~user%2F".
+	NormalizePercentEncoding
+	// NormalizeSortQuery sorts the query parameters by key, the values of a key keeping their order,
+	// e.g. "http://example.com/?b=2&a=1" to "http://example.com/?a=1&b=2". It may change the meaning
+	// of the URL for servers relying on the order of the parameters.
+	NormalizeSortQuery
+	// NormalizePunycodeHost converts internationalized hostnames to their ASCII form with ToASCIIHost,
+	// e.g. "http://bücher.example/" to "http://xn--bcher-kva.example/".
+	NormalizePunycodeHost
+	// NormalizeEmptyPath sets the empty path of http, https, ws and wss URLs with an authority to "/",
+	// e.g. "http://example.com" to "http://example.com/".
+	NormalizeEmptyPath
+
+	// NormalizeRFC3986 combines the normalizations defined by RFC 3986 which preserve the semantics of URLs.
+	NormalizeRFC3986 = NormalizeLowercaseScheme | NormalizeLowercaseHost | NormalizeRemoveDefaultPort |
+		NormalizeRemoveDotSegments | NormalizePercentEncoding | NormalizeEmptyPath
+)
+
+var defaultPorts = map[string]string{
+	"ftp":   "21",
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+}
+
+// Normalize parses rawURL and returns its normalized form according to flags, e.g. for cache keys
+// or to deduplicate URLs. Equivalent URLs have the same normalized form.
+func Normalize(rawURL string, flags NormalizeFlag) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	if flags&NormalizeLowercaseScheme != 0 {
+		u.Scheme = strings.ToLower(u.Scheme)
+	}
+	if flags&NormalizeLowercaseHost != 0 {
+		u.Host = strings.ToLower(u.Host)
+	}
+	if flags&NormalizePunycodeHost != 0 {
+		if hostname := u.Hostname(); hostname != "" {
+			ascii, err := ToASCIIHost(hostname)
+			if err != nil {
+				return "", err
+			}
+			u.Host = strings.Replace(u.Host, hostname, ascii, 1)
+		}
+	}
+	if flags&NormalizeRemoveDefaultPort != 0 {
+		if port := u.Port(); port == "" || port == defaultPorts[strings.ToLower(u.Scheme)] {
+			u.Host = strings.TrimSuffix(u.Host, ":"+port)
+		}
+	}
+
+	if u.Opaque == "" {
+		path := u.EscapedPath()
+		if flags&NormalizePercentEncoding != 0 {
+			path = normalizePercentEncoding(path)
+		}
+		if flags&NormalizeRemoveDotSegments != 0 {
+			path = removeDotSegments(path)
+		}
+		if flags&NormalizeEmptyPath != 0 && path == "" && u.Host != "" {
+			// https://datatracker.ietf.org/doc/html/rfc3986#section-6.2.3
+			switch strings.ToLower(u.Scheme) {
+			case "http", "https", "ws", "wss":
+				path = "/"
+			}
+		}
+		if err := setEscapedPath(u, path); err != nil {
+			return "", err
+		}
+	}
+
+	if flags&NormalizePercentEncoding != 0 {
+		u.RawQuery = normalizePercentEncoding(u.RawQuery)
+		if u.Fragment != "" {
+			if err := setEscapedFragment(u, normalizePercentEncoding(u.EscapedFragment())); err != nil {
+				return "", err
+			}
+		}
+	}
+	if flags&NormalizeSortQuery != 0 && u.RawQuery != "" {
+		u.RawQuery = sortQuery(u.RawQuery)
+	}
+
+	return u.String(), nil
+}
+
+// normalizePercentEncoding uppercases the hexadecimal digits of the percent-encoded octets of s
+// and decodes those of unreserved characters.
+func normalizePercentEncoding(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if !isPctEncoded(s[i:]) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		c := unhex(s[i+1])<<4 | unhex(s[i+2])
+		if isUnreserved(c) {
+			sb.WriteByte(c)
+		} else {
+			sb.WriteString(pctEncode(c))
+		}
+		i += 2
+	}
+	return sb.String()
+}
+
+// removeDotSegments resolves the "." and ".." segments of path (RFC 3986, section 5.2.4).
+func removeDotSegments(path string) string {
+	var out strings.Builder
+	removeLast := func() {
+		s := out.String()
+		out.Reset()
+		if i := strings.LastIndexByte(s, '/'); i >= 0 {
+			out.WriteString(s[:i])
+		}
+	}
+
+	for in := path; in != ""; {
+		switch {
+		case strings.HasPrefix(in, "../"):
+			in = in[3:]
+		case strings.HasPrefix(in, "./"):
+			in = in[2:]
+		case strings.HasPrefix(in, "/./"):
+			in = in[2:]
+		case in == "/.":
+			in = "/"
+		case strings.HasPrefix(in, "/../"):
+			in = in[3:]
+			removeLast()
+		case in == "/..":
+			in = "/"
+			removeLast()
+		case in == "." || in == "..":
+			in = ""
+		default:
+			end := len(in)
+			if i := strings.IndexByte(in[1:], '/'); i >= 0 {
+				end = i + 1
+			}
+			out.WriteString(in[:end])
+			in = in[end:]
+		}
+	}
+	return out.String()
+}
+
+// sortQuery sorts the parameters of the raw query by key, the values of a key keeping their order.
+func sortQuery(rawQuery string) string {
+	params := strings.Split(rawQuery, "&")
+	sort.SliceStable(params, func(i, j int) bool {
+		ki, _, _ := strings.Cut(params[i], "=")
+		kj, _, _ := strings.Cut(params[j], "=")
+		return ki < kj
+	})
+	return strings.Join(params, "&")
+}
+
+func setEscapedPath(u *url.URL, escaped string) error {
+	path, err := url.PathUnescape(escaped)
+	if err != nil {
+		return err
+	}
+	u.Path, u.RawPath = path, escaped
+	return nil
+}
+
+func setEscapedFragment(u *url.URL, escaped string) error {
+	fragment, err := url.PathUnescape(escaped)
+	if err != nil {
+		return err
+	}
+	u.Fragment, u.RawFragment = fragment, escaped
+	return nil
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xurl_test
+
+import (
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xurl"
+)
+
+func TestNormalize(t *testing.T) {
+	testCases := []struct {
+		name        string
+		rawURL      string
+		flags       xurl.NormalizeFlag
+		expected    string
+		expectedErr bool
+	}{
+		{
+			name:     "no flag",
+			rawURL:   "http://EXAMPLE.com:80/a/./b?b=2&a=1",
+			flags:    0,
+			expected: "http://EXAMPLE.com:80/a/./b?b=2&a=1",
+		},
+		{
+			name:     "lowercase scheme",
+			rawURL:   "HTTP://example.com/",
+			flags:    xurl.NormalizeLowercaseScheme,
+			expected: "http://example.com/",
+		},
+		{
+			name:     "lowercase host",
+			rawURL:   "http://User@EXAMPLE.com/Path",
+			flags:    xurl.NormalizeLowercaseHost,
+			expected: "http://User@example.com/Path",
+		},
+		{
+			name:     "remove default http port",
+			rawURL:   "http://example.com:80/",
+			flags:    xurl.NormalizeRemoveDefaultPort,
+			expected: "http://example.com/",
+		},
+		{
+			name:     "remove default https port",
+			rawURL:   "https://[::1]:443/",
+			flags:    xurl.NormalizeRemoveDefaultPort,
+			expected: "https://[::1]/",
+		},
+		{
+			name:     "remove empty port",
+			rawURL:   "http://example.com:/",
+			flags:    xurl.NormalizeRemoveDefaultPort,
+			expected: "http://example.com/",
+		},
+		{
+			name:     "keep other port",
+			rawURL:   "http://example.com:8080/",
+			flags:    xurl.NormalizeRemoveDefaultPort,
+			expected: "http://example.com:8080/",
+		},
+		{
+			name:     "remove dot segments",
+			rawURL:   "http://example.com/a/b/c/./../../g",
+			flags:    xurl.NormalizeRemoveDotSegments,
+			expected: "http://example.com/a/g",
+		},
+		{
+			name:     "remove trailing dot segment",
+			rawURL:   "http://example.com/a/b/..",
+			flags:    xurl.NormalizeRemoveDotSegments,
+			expected: "http://example.com/a/",
+		},
+		{
+			name:     "remove dot segments above root",
+			rawURL:   "http://example.com/../../a",
+			flags:    xurl.NormalizeRemoveDotSegments,
+			expected: "http://example.com/a",
+		},
+		{
+			name:     "remove relative dot segments",
+			rawURL:   "mid/content=5/../6",
+			flags:    xurl.NormalizeRemoveDotSegments,
+			expected: "mid/6",
+		},
+		{
+			name:     "percent-encoding",
+			rawURL:   "http://example.com/%7euser/a%2fb?q=%3d%41#%7efrag",
+			flags:    xurl.NormalizePercentEncoding,
+			expected: "http://example.com/~user/a%2Fb?q=%3DA#~frag",
+		},
+		{
+			name:     "sort query",
+			rawURL:   "http://example.com/?b=2&a=1&c&a=0",
+			flags:    xurl.NormalizeSortQuery,
+			expected: "http://example.com/?a=1&a=0&b=2&c",
+		},
+		{
+			name:     "punycode host",
+			rawURL:   "http://user@Bücher.example:8080/",
+			flags:    xurl.NormalizePunycodeHost,
+			expected: "http://user@xn--bcher-kva.example:8080/",
+		},
+		{
+			name:     "empty path",
+			rawURL:   "https://example.com?q=1",
+			flags:    xurl.NormalizeEmptyPath,
+			expected: "https://example.com/?q=1",
+		},
+		{
+			name:     "empty path without authority",
+			rawURL:   "http:?q=1",
+			flags:    xurl.NormalizeEmptyPath,
+			expected: "http:?q=1",
+		},
+		{
+			name:     "empty path of other scheme",
+			rawURL:   "ftp://example.com",
+			flags:    xurl.NormalizeEmptyPath,
+			expected: "ftp://example.com",
+		},
+		{
+			name:        "invalid punycode host",
+			rawURL:      "http://a..example/",
+			flags:       xurl.NormalizePunycodeHost,
+			expectedErr: true,
+		},
+		{
+			name:     "rfc 3986",
+			rawURL:   "HTTP://Example.COM:80/a/%7Eb/../c?q=%7a",
+			flags:    xurl.NormalizeRFC3986,
+			expected: "http://example.com/a/c?q=z",
+		},
+		{
+			name:     "rfc 3986 equivalent empty path",
+			rawURL:   "HTTPS://example.com:443",
+			flags:    xurl.NormalizeRFC3986,
+			expected: "https://example.com/",
+		},
+		{
+			name:     "rfc 3986 keeps query order",
+			rawURL:   "http://example.com/?b=2&a=1",
+			flags:    xurl.NormalizeRFC3986,
+			expected: "http://example.com/?b=2&a=1",
+		},
+		{
+			name:        "invalid url",
+			rawURL:      "http://[::1",
+			flags:       xurl.NormalizeRFC3986,
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := xurl.Normalize(tc.rawURL, tc.flags)
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error is %t, got %v", tc.expectedErr, err)
+			}
+			if got != tc.expected {
+				t.Errorf("expected %v; got %v", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xurl
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+const patternRemainderSuffix = "..."
+
+// ErrInvalidPattern is returned when a path pattern is not valid.
+var ErrInvalidPattern = errors.New("invalid path pattern")
+
+// Pattern is a path pattern, e.g. "/users/{id}/files/{path...}", made of slash-separated segments which are either
+// literals or wildcards: "{name}" matches a single non-empty segment and "{name...}", which must be the last one,
+// matches the remainder of the path, possibly empty. A trailing slash is significant, "/users/" only matching
+// paths ending with a slash.
+//
+// It is used for lightweight routing with Match and for reverse URL construction with Build.
+type Pattern struct {
+	raw      string
+	segments []patternSegment
+}
+
+type patternSegment struct {
+	literal   string
+	name      string
+	remainder bool
+}
+
+// ParsePattern parses a path pattern. It fails with ErrInvalidPattern if s does not start with a slash,
+// has an invalid or duplicate wildcard name, a "{name...}" wildcard which is not the last segment or braces
+// which do not enclose a whole segment.
+func ParsePattern(s string) (*Pattern, error) {
+	if !strings.HasPrefix(s, slash) {
+		return nil, fmt.Errorf("%w %q: missing leading slash", ErrInvalidPattern, s)
+	}
+
+	parts := strings.Split(s[1:], slash)
+	p := &Pattern{
+		raw:      s,
+		segments: make([]patternSegment, len(parts)),
+	}
+
+	names := make(map[string]bool)
+	for i, part := range parts {
+		if !strings.HasPrefix(part, "{") || !strings.HasSuffix(part, "}") {
+			if strings.ContainsAny(part, "{}") {
+				return nil, fmt.Errorf("%w %q: wildcard %q must be a whole segment", ErrInvalidPattern, s, part)
+			}
+			p.segments[i] = patternSegment{literal: part}
+			continue
+		}
+
+		name := part[1 : len(part)-1]
+		remainder := strings.HasSuffix(name, patternRemainderSuffix)
+		if remainder {
+			name = strings.TrimSuffix(name, patternRemainderSuffix)
+			if i != len(parts)-1 {
+				return nil, fmt.Errorf("%w %q: wildcard %q must be the last segment", ErrInvalidPattern, s, part)
+			}
+		}
+		if !isPatternName(name) {
+			return nil, fmt.Errorf("%w %q: invalid wildcard name %q", ErrInvalidPattern, s, name)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("%w %q: duplicate wildcard name %q", ErrInvalidPattern, s, name)
+		}
+		names[name] = true
+
+		p.segments[i] = patternSegment{name: name, remainder: remainder}
+	}
+
+	return p, nil
+}
+
+// MustParsePattern is like ParsePattern but panics if s is not a valid pattern.
+// It simplifies the initialization of global variables holding patterns.
+func MustParsePattern(s string) *Pattern {
+	p, err := ParsePattern(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Match reports whether the escaped path, e.g. as returned by url.URL.EscapedPath, matches the pattern and returns
+// the unescaped values of its wildcards, by name, if it does.
+func (p *Pattern) Match(path string) (params map[string]string, ok bool) {
+	if !strings.HasPrefix(path, slash) {
+		return nil, false
+	}
+
+	parts := strings.Split(path[1:], slash)
+	params = make(map[string]string)
+
+	for i, seg := range p.segments {
+		if seg.remainder {
+			if i >= len(parts) {
+				return nil, false
+			}
+			value, err := url.PathUnescape(strings.Join(parts[i:], slash))
+			if err != nil {
+				return nil, false
+			}
+			params[seg.name] = value
+			return params, true
+		}
+
+		if i >= len(parts) {
+			return nil, false
+		}
+		if seg.name == "" {
+			if parts[i] != seg.literal {
+				return nil, false
+			}
+			continue
+		}
+
+		value, err := url.PathUnescape(parts[i])
+		if err != nil || value == "" {
+			return nil, false
+		}
+		params[seg.name] = value
+	}
+
+	if len(parts) != len(p.segments) {
+		return nil, false
+	}
+	return params, true
+}
+
+// Build returns the path made of the pattern whose wildcards are replaced with the escaped values of params,
+// by name. The slashes of the value of a "{name...}" wildcard are kept as separators. It fails if the value of
+// a wildcard is missing or, unless it is a "{name...}" one, empty, or if it has a "." or ".." segment, which
+// would make the path escape the pattern once resolved.
+func (p *Pattern) Build(params map[string]string) (string, error) {
+	var sb strings.Builder
+	for _, seg := range p.segments {
+		sb.WriteString(slash)
+
+		if seg.name == "" {
+			sb.WriteString(seg.literal)
+			continue
+		}
+
+		value, ok := params[seg.name]
+		if !ok || (value == "" && !seg.remainder) {
+			return "", fmt.Errorf("missing value for wildcard %q of pattern %q", seg.name, p.raw)
+		}
+
+		if !seg.remainder {
+			if isDotSegment(value) {
+				return "", fmt.Errorf("dot segment value for wildcard %q of pattern %q", seg.name, p.raw)
+			}
+			sb.WriteString(url.PathEscape(value))
+			continue
+		}
+		for i, part := range strings.Split(value, slash) {
+			if isDotSegment(part) {
+				return "", fmt.Errorf("dot segment value for wildcard %q of pattern %q", seg.name, p.raw)
+			}
+			if i > 0 {
+				sb.WriteString(slash)
+			}
+			sb.WriteString(url.PathEscape(part))
+		}
+	}
+	return sb.String(), nil
+}
+
+// String returns the pattern as parsed.
+func (p *Pattern) String() string {
+	return p.raw
+}
+
+func isDotSegment(s string) bool {
+	return s == "." || s == ".."
+}
+
+// isPatternName reports whether name is a valid Go identifier.
+func isPatternName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
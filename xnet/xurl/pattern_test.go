@@ -0,0 +1,180 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xurl_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xurl"
+)
+
+func TestParsePattern(t *testing.T) {
+	testCases := []struct {
+		name        string
+		pattern     string
+		expectedErr bool
+	}{
+		{name: "root", pattern: "/", expectedErr: false},
+		{name: "literals", pattern: "/users/", expectedErr: false},
+		{name: "wildcards", pattern: "/users/{id}/files/{path...}", expectedErr: false},
+		{name: "missing leading slash", pattern: "users/{id}", expectedErr: true},
+		{name: "partial segment wildcard", pattern: "/users/id-{id}", expectedErr: true},
+		{name: "unclosed wildcard", pattern: "/users/{id", expectedErr: true},
+		{name: "empty wildcard name", pattern: "/users/{}", expectedErr: true},
+		{name: "invalid wildcard name", pattern: "/users/{user-id}", expectedErr: true},
+		{name: "duplicate wildcard name", pattern: "/users/{id}/friends/{id}", expectedErr: true},
+		{name: "remainder not last", pattern: "/files/{path...}/meta", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := xurl.ParsePattern(tc.pattern)
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error is %t, got %v", tc.expectedErr, err)
+			}
+			if err != nil && !errors.Is(err, xurl.ErrInvalidPattern) {
+				t.Errorf("error mismatch: expected %v; got %v", xurl.ErrInvalidPattern, err)
+			}
+			if err == nil && p.String() != tc.pattern {
+				t.Errorf("expected %v; got %v", tc.pattern, p)
+			}
+		})
+	}
+}
+
+func TestPattern_Match(t *testing.T) {
+	testCases := []struct {
+		name           string
+		pattern        string
+		path           string
+		expectedParams map[string]string
+		expectedOK     bool
+	}{
+		{name: "root", pattern: "/", path: "/", expectedParams: map[string]string{}, expectedOK: true},
+		{name: "literal", pattern: "/users", path: "/users", expectedParams: map[string]string{}, expectedOK: true},
+		{name: "literal mismatch", pattern: "/users", path: "/groups", expectedOK: false},
+		{name: "trailing slash mismatch", pattern: "/users/", path: "/users", expectedOK: false},
+		{name: "missing trailing slash", pattern: "/users", path: "/users/", expectedOK: false},
+		{name: "relative path", pattern: "/users", path: "users", expectedOK: false},
+		{
+			name:           "wildcard",
+			pattern:        "/users/{id}",
+			path:           "/users/42",
+			expectedParams: map[string]string{"id": "42"},
+			expectedOK:     true,
+		},
+		{
+			name:           "escaped wildcard",
+			pattern:        "/users/{name}",
+			path:           "/users/john%20doe%2Fjr",
+			expectedParams: map[string]string{"name": "john doe/jr"},
+			expectedOK:     true,
+		},
+		{name: "empty wildcard", pattern: "/users/{id}", path: "/users/", expectedOK: false},
+		{name: "too many segments", pattern: "/users/{id}", path: "/users/42/files", expectedOK: false},
+		{name: "too few segments", pattern: "/users/{id}/files", path: "/users/42", expectedOK: false},
+		{
+			name:           "remainder",
+			pattern:        "/users/{id}/files/{path...}",
+			path:           "/users/42/files/docs/a%20b.txt",
+			expectedParams: map[string]string{"id": "42", "path": "docs/a b.txt"},
+			expectedOK:     true,
+		},
+		{
+			name:           "empty remainder",
+			pattern:        "/files/{path...}",
+			path:           "/files/",
+			expectedParams: map[string]string{"path": ""},
+			expectedOK:     true,
+		},
+		{name: "missing remainder", pattern: "/files/{path...}", path: "/files", expectedOK: false},
+		{name: "invalid escaping", pattern: "/users/{id}", path: "/users/%zz", expectedOK: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			params, ok := xurl.MustParsePattern(tc.pattern).Match(tc.path)
+			if ok != tc.expectedOK {
+				t.Errorf("match mismatch: expected %t; got %t", tc.expectedOK, ok)
+			}
+			if !reflect.DeepEqual(tc.expectedParams, params) {
+				t.Errorf("params mismatch: expected %v; got %v", tc.expectedParams, params)
+			}
+		})
+	}
+}
+
+func TestPattern_Build(t *testing.T) {
+	testCases := []struct {
+		name        string
+		pattern     string
+		params      map[string]string
+		expected    string
+		expectedErr bool
+	}{
+		{name: "root", pattern: "/", expected: "/"},
+		{name: "literals", pattern: "/users/", expected: "/users/"},
+		{
+			name:     "wildcards",
+			pattern:  "/users/{id}/files/{path...}",
+			params:   map[string]string{"id": "john doe/jr", "path": "docs/a b.txt"},
+			expected: "/users/john%20doe%2Fjr/files/docs/a%20b.txt",
+		},
+		{
+			name:     "empty remainder",
+			pattern:  "/files/{path...}",
+			params:   map[string]string{"path": ""},
+			expected: "/files/",
+		},
+		{name: "missing wildcard", pattern: "/users/{id}", params: map[string]string{}, expectedErr: true},
+		{name: "empty wildcard", pattern: "/users/{id}", params: map[string]string{"id": ""}, expectedErr: true},
+		{name: "dot wildcard", pattern: "/users/{id}", params: map[string]string{"id": "."}, expectedErr: true},
+		{name: "dot-dot wildcard", pattern: "/users/{id}/files", params: map[string]string{"id": ".."}, expectedErr: true},
+		{
+			name:        "dot-dot remainder",
+			pattern:     "/files/{path...}",
+			params:      map[string]string{"path": "../../etc"},
+			expectedErr: true,
+		},
+		{name: "dot remainder", pattern: "/files/{path...}", params: map[string]string{"path": "a/./b"}, expectedErr: true},
+		{
+			name:     "dots within segments",
+			pattern:  "/files/{path...}",
+			params:   map[string]string{"path": "a/..b/.c"},
+			expected: "/files/a/..b/.c",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := xurl.MustParsePattern(tc.pattern)
+
+			got, err := p.Build(tc.params)
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error is %t, got %v", tc.expectedErr, err)
+			}
+			if got != tc.expected {
+				t.Errorf("expected %v; got %v", tc.expected, got)
+			}
+
+			if err == nil {
+				if params, ok := p.Match(got); !ok || (len(tc.params) > 0 && !reflect.DeepEqual(tc.params, params)) {
+					t.Errorf("round trip mismatch: expected %v; got %v, %t", tc.params, params, ok)
+				}
+			}
+		})
+	}
+}
+
+func TestMustParsePattern_Panic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("panic mismatch: expected %t; got %v", true, r)
+		}
+	}()
+	xurl.MustParsePattern("users")
+}
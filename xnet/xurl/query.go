@@ -0,0 +1,115 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xurl
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jlourenc/xgo/xtime"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+// Query is a builder of URL query strings, whose methods can be chained, e.g.
+//
+//	q := xurl.NewQuery().Set("q", "gopher").Int("page", 2).Bool("exact", true)
+//
+// Its encoding is sorted by key, the values of a key keeping their order, so that it is stable.
+// The zero value is an empty query ready to use.
+type Query struct {
+	values url.Values
+}
+
+// NewQuery returns a new empty Query.
+func NewQuery() *Query {
+	return &Query{}
+}
+
+// Add adds value to key. It appends to any existing value associated with key.
+func (q *Query) Add(key, value string) *Query {
+	if q.values == nil {
+		q.values = url.Values{}
+	}
+	q.values.Add(key, value)
+	return q
+}
+
+// Set sets key to value. It replaces any existing value.
+func (q *Query) Set(key, value string) *Query {
+	if q.values == nil {
+		q.values = url.Values{}
+	}
+	q.values.Set(key, value)
+	return q
+}
+
+// Del deletes the values associated with key.
+func (q *Query) Del(key string) *Query {
+	q.values.Del(key)
+	return q
+}
+
+// Bool sets key to the "true" or "false" value of b.
+func (q *Query) Bool(key string, b bool) *Query {
+	return q.Set(key, strconv.FormatBool(b))
+}
+
+// Byte sets key to the string representation of b, e.g. "10MB", as parsed by xunit.ParseByte.
+func (q *Query) Byte(key string, b xunit.Byte) *Query {
+	return q.Set(key, b.String())
+}
+
+// Int sets key to the decimal representation of i.
+func (q *Query) Int(key string, i int) *Query {
+	return q.Set(key, strconv.Itoa(i))
+}
+
+// Time sets key to t formatted according to layout, e.g. time.RFC3339.
+// If layout is empty, xtime.RFC3339Milli is used.
+func (q *Query) Time(key string, t time.Time, layout string) *Query {
+	if layout == "" {
+		layout = xtime.RFC3339Milli
+	}
+	return q.Set(key, t.Format(layout))
+}
+
+// Has checks whether key is set.
+func (q *Query) Has(key string) bool {
+	return q.values.Has(key)
+}
+
+// Get gets the first value associated with key, or an empty string if there is none.
+func (q *Query) Get(key string) string {
+	return q.values.Get(key)
+}
+
+// Values returns a copy of the values of the query.
+func (q *Query) Values() url.Values {
+	values := make(url.Values, len(q.values))
+	for k, v := range q.values {
+		values[k] = append([]string(nil), v...)
+	}
+	return values
+}
+
+// Encode encodes the query into "URL encoded" form, e.g. "exact=true&page=2&q=gopher", sorted by key.
+func (q *Query) Encode() string {
+	return q.values.Encode()
+}
+
+// String returns the encoded query.
+func (q *Query) String() string {
+	return q.Encode()
+}
+
+// AppendTo adds the values of the query to those of the query string of u, which is re-encoded.
+func (q *Query) AppendTo(u *url.URL) {
+	values := u.Query()
+	for k, v := range q.values {
+		values[k] = append(values[k], v...)
+	}
+	u.RawQuery = values.Encode()
+}
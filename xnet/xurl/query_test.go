@@ -0,0 +1,130 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xurl_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xnet/xurl"
+	"github.com/jlourenc/xgo/xunit"
+)
+
+func TestQuery_Encode(t *testing.T) {
+	date := time.Date(2024, time.March, 1, 10, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		query    *xurl.Query
+		expected string
+	}{
+		{
+			name:     "zero value",
+			query:    &xurl.Query{},
+			expected: "",
+		},
+		{
+			name:     "sorted keys",
+			query:    xurl.NewQuery().Set("q", "gopher").Set("a", "1"),
+			expected: "a=1&q=gopher",
+		},
+		{
+			name:     "added values keep their order",
+			query:    xurl.NewQuery().Add("tag", "b").Add("tag", "a"),
+			expected: "tag=b&tag=a",
+		},
+		{
+			name:     "set replaces values",
+			query:    xurl.NewQuery().Add("tag", "b").Add("tag", "a").Set("tag", "c"),
+			expected: "tag=c",
+		},
+		{
+			name:     "deleted key",
+			query:    xurl.NewQuery().Set("a", "1").Set("b", "2").Del("a"),
+			expected: "b=2",
+		},
+		{
+			name:     "escaped values",
+			query:    xurl.NewQuery().Set("q", "a&b=c d"),
+			expected: "q=a%26b%3Dc+d",
+		},
+		{
+			name: "typed values",
+			query: xurl.NewQuery().
+				Bool("exact", true).
+				Byte("size", 10*xunit.MB).
+				Int("page", 2).
+				Time("since", date, "").
+				Time("until", date, time.DateOnly),
+			expected: "exact=true&page=2&since=2024-03-01T10%3A30%3A00.000Z&size=10MB&until=2024-03-01",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.query.Encode(); got != tc.expected {
+				t.Errorf("expected %v; got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestQuery_AppendTo(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rawURL   string
+		query    *xurl.Query
+		expected string
+	}{
+		{
+			name:     "no existing query",
+			rawURL:   "https://example.com/search",
+			query:    xurl.NewQuery().Set("q", "gopher"),
+			expected: "https://example.com/search?q=gopher",
+		},
+		{
+			name:     "existing query",
+			rawURL:   "https://example.com/search?q=go&page=1",
+			query:    xurl.NewQuery().Set("q", "gopher"),
+			expected: "https://example.com/search?page=1&q=go&q=gopher",
+		},
+		{
+			name:     "empty query",
+			rawURL:   "https://example.com/search",
+			query:    xurl.NewQuery(),
+			expected: "https://example.com/search",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			u, err := url.Parse(tc.rawURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tc.query.AppendTo(u)
+
+			if got := u.String(); got != tc.expected {
+				t.Errorf("expected %v; got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestQuery_Values(t *testing.T) {
+	q := xurl.NewQuery().Add("tag", "a")
+
+	values := q.Values()
+	values.Add("tag", "b")
+
+	if got := q.Encode(); got != "tag=a" {
+		t.Errorf("expected %v; got %v", "tag=a", got)
+	}
+	if !q.Has("tag") || q.Get("tag") != "a" {
+		t.Errorf("expected tag to be a; got %v", q.Get("tag"))
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xurl
+
+import (
+	"net/url"
+	"strings"
+)
+
+const redactedMask = "xxxxx"
+
+var redactDefaultQueryParams = []string{"api_key", "signature", "token"}
+
+// Redact returns a string representation of u, either a *url.URL or a raw URL string, safe for logging:
+// the password of its userinfo, if any, and the values of the query parameters holding secrets, by default
+// api_key, signature and token, are replaced with "xxxxx". The rest of the URL is left as is and u is not
+// modified. If u is a string which cannot be parsed as a URL, it is entirely masked as it may hold secrets.
+func Redact[T *url.URL | string](u T, options ...RedactOption) string {
+	cfg := redactConfig{
+		queryParams: redactDefaultQueryParams,
+	}
+
+	for _, opt := range options {
+		opt.apply(&cfg)
+	}
+
+	var parsed *url.URL
+	switch v := any(u).(type) {
+	case *url.URL:
+		if v == nil {
+			return ""
+		}
+		parsed = v
+	case string:
+		var err error
+		if parsed, err = url.Parse(v); err != nil {
+			return redactedMask
+		}
+	}
+
+	redacted := *parsed
+	if redacted.RawQuery != "" {
+		redacted.RawQuery = redactQuery(redacted.RawQuery, cfg.queryParams)
+	}
+	return redacted.Redacted()
+}
+
+// redactQuery masks the values of params in rawQuery, matched case-insensitively,
+// keeping the order and the encoding of the other parameters.
+func redactQuery(rawQuery string, params []string) string {
+	pairs := strings.Split(rawQuery, "&")
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+
+		for _, p := range params {
+			if strings.EqualFold(key, p) {
+				pairs[i] = url.QueryEscape(key) + "=" + redactedMask
+				break
+			}
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+type redactConfig struct {
+	queryParams []string
+}
+
+type (
+	// RedactOption configures how a URL is redacted when calling Redact.
+	RedactOption interface {
+		apply(c *redactConfig)
+	}
+
+	funcRedactOption struct {
+		fn func(*redactConfig)
+	}
+)
+
+func newFuncRedactOption(fn func(*redactConfig)) funcRedactOption {
+	return funcRedactOption{
+		fn: fn,
+	}
+}
+
+func (o funcRedactOption) apply(c *redactConfig) {
+	o.fn(c)
+}
+
+// RedactQueryParams returns a RedactOption that configures the query parameters whose values are masked,
+// matched case-insensitively, replacing the default ones.
+func RedactQueryParams(params ...string) RedactOption {
+	return newFuncRedactOption(func(c *redactConfig) {
+		c.queryParams = params
+	})
+}
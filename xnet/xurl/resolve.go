@@ -0,0 +1,100 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xurl
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrRelativeBase is returned by Resolve when the base URL is not absolute.
+var ErrRelativeBase = errors.New("base url is not absolute")
+
+type resolveConfig struct {
+	baseAsDirectory bool
+}
+
+// Resolve resolves the URI reference ref, absolute or relative, e.g. a link of a crawled page or of a
+// HATEOAS response, against the absolute URL base, as defined by RFC 3986, section 5.2.
+//
+// As defined by RFC 3986, the last segment of the base path is replaced by a relative reference unless it
+// ends with a slash, i.e. "users" resolved against "https://example.com/v1" is "https://example.com/users".
+// See ResolveBaseAsDirectory to always resolve relative references under the base path. A trailing slash
+// of ref is preserved.
+func Resolve(base, ref string, options ...ResolveOption) (string, error) {
+	var cfg resolveConfig
+	for _, opt := range options {
+		opt.apply(&cfg)
+	}
+
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("parsing base url: %w", err)
+	}
+	if !baseURL.IsAbs() {
+		return "", fmt.Errorf("%w: %q", ErrRelativeBase, base)
+	}
+
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("parsing reference: %w", err)
+	}
+
+	if cfg.baseAsDirectory && !strings.HasSuffix(baseURL.Path, slash) {
+		if err = setEscapedPath(baseURL, baseURL.EscapedPath()+slash); err != nil {
+			return "", fmt.Errorf("parsing base url: %w", err)
+		}
+	}
+
+	resolved := baseURL.ResolveReference(refURL)
+	// Unlike url.URL.ResolveReference, RFC 3986 does not inherit the fragment of the base URL.
+	resolved.Fragment, resolved.RawFragment = refURL.Fragment, refURL.RawFragment
+	return resolved.String(), nil
+}
+
+// IsAbs reports whether rawURL is a valid absolute URL, i.e. with a scheme.
+func IsAbs(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && u.IsAbs()
+}
+
+// IsRelative reports whether rawURL is a valid relative reference, i.e. without a scheme,
+// e.g. "/users/42", "../users" or "//example.com/users".
+func IsRelative(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && !u.IsAbs()
+}
+
+type (
+	// ResolveOption configures how a reference is resolved when calling Resolve.
+	ResolveOption interface {
+		apply(c *resolveConfig)
+	}
+
+	funcResolveOption struct {
+		fn func(*resolveConfig)
+	}
+)
+
+func newFuncResolveOption(fn func(*resolveConfig)) funcResolveOption {
+	return funcResolveOption{
+		fn: fn,
+	}
+}
+
+func (o funcResolveOption) apply(c *resolveConfig) {
+	o.fn(c)
+}
+
+// ResolveBaseAsDirectory returns a ResolveOption that considers the base path as a directory, as if it ended
+// with a slash, so that relative references are resolved under it, i.e. "users" resolved against
+// "https://example.com/v1" is "https://example.com/v1/users".
+func ResolveBaseAsDirectory() ResolveOption {
+	return newFuncResolveOption(func(c *resolveConfig) {
+		c.baseAsDirectory = true
+	})
+}
@@ -0,0 +1,105 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xurl_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xurl"
+)
+
+func TestResolve(t *testing.T) {
+	testCases := []struct {
+		name        string
+		base        string
+		ref         string
+		options     []xurl.ResolveOption
+		expected    string
+		expectedErr error
+	}{
+		{name: "relative path", base: "https://example.com/v1/users", ref: "42", expected: "https://example.com/v1/42"},
+		{name: "relative path under directory", base: "https://example.com/v1/", ref: "users", expected: "https://example.com/v1/users"},
+		{name: "parent path", base: "https://example.com/v1/users/42", ref: "../groups/", expected: "https://example.com/v1/groups/"},
+		{name: "absolute path", base: "https://example.com/v1/users", ref: "/v2/users", expected: "https://example.com/v2/users"},
+		{name: "network path", base: "https://example.com/v1/", ref: "//cdn.example.com/a.js", expected: "https://cdn.example.com/a.js"},
+		{name: "absolute url", base: "https://example.com/v1/", ref: "http://example.org/", expected: "http://example.org/"},
+		{name: "query only", base: "https://example.com/v1/users?page=1", ref: "?page=2", expected: "https://example.com/v1/users?page=2"},
+		{name: "fragment only", base: "https://example.com/v1/users", ref: "#top", expected: "https://example.com/v1/users#top"},
+		{name: "empty reference", base: "https://example.com/v1/users#top", ref: "", expected: "https://example.com/v1/users"},
+		{
+			name:     "base as directory",
+			base:     "https://example.com/v1",
+			ref:      "users",
+			options:  []xurl.ResolveOption{xurl.ResolveBaseAsDirectory()},
+			expected: "https://example.com/v1/users",
+		},
+		{
+			name:     "base as directory with trailing slash",
+			base:     "https://example.com/v1/",
+			ref:      "users/",
+			options:  []xurl.ResolveOption{xurl.ResolveBaseAsDirectory()},
+			expected: "https://example.com/v1/users/",
+		},
+		{name: "relative base", base: "/v1/", ref: "users", expectedErr: xurl.ErrRelativeBase},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := xurl.Resolve(tc.base, tc.ref, tc.options...)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("error mismatch: expected %v; got %v", tc.expectedErr, err)
+			}
+			if got != tc.expected {
+				t.Errorf("expected %v; got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestResolve_ParseError(t *testing.T) {
+	testCases := []struct {
+		name string
+		base string
+		ref  string
+	}{
+		{name: "invalid base", base: "https://[::1", ref: "users"},
+		{name: "invalid reference", base: "https://example.com/", ref: "%zz"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := xurl.Resolve(tc.base, tc.ref); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestIsAbs(t *testing.T) {
+	testCases := []struct {
+		name             string
+		rawURL           string
+		expectedAbs      bool
+		expectedRelative bool
+	}{
+		{name: "absolute", rawURL: "https://example.com/users", expectedAbs: true, expectedRelative: false},
+		{name: "absolute path", rawURL: "/users", expectedAbs: false, expectedRelative: true},
+		{name: "relative path", rawURL: "../users", expectedAbs: false, expectedRelative: true},
+		{name: "network path", rawURL: "//example.com/users", expectedAbs: false, expectedRelative: true},
+		{name: "invalid", rawURL: "https://[::1", expectedAbs: false, expectedRelative: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := xurl.IsAbs(tc.rawURL); got != tc.expectedAbs {
+				t.Errorf("IsAbs mismatch: expected %t; got %t", tc.expectedAbs, got)
+			}
+			if got := xurl.IsRelative(tc.rawURL); got != tc.expectedRelative {
+				t.Errorf("IsRelative mismatch: expected %t; got %t", tc.expectedRelative, got)
+			}
+		})
+	}
+}
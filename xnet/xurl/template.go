@@ -0,0 +1,231 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xurl
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const upperHex = "0123456789ABCDEF"
+
+// ErrInvalidTemplate is returned when a URL template is not valid.
+var ErrInvalidTemplate = errors.New("invalid url template")
+
+// templateOperator defines how the variables of an expression are expanded (RFC 6570, appendix A).
+type templateOperator struct {
+	first         string
+	sep           string
+	named         bool
+	ifEmpty       string
+	allowReserved bool
+}
+
+var templateOperators = map[byte]templateOperator{
+	0:   {first: "", sep: ","},
+	'+': {first: "", sep: ",", allowReserved: true},
+	'#': {first: "#", sep: ",", allowReserved: true},
+	'.': {first: ".", sep: "."},
+	'/': {first: "/", sep: "/"},
+	';': {first: ";", sep: ";", named: true},
+	'?': {first: "?", sep: "&", named: true, ifEmpty: "="},
+	'&': {first: "&", sep: "&", named: true, ifEmpty: "="},
+}
+
+type templatePart struct {
+	literal string
+	op      templateOperator
+	vars    []string
+}
+
+// ExpandTemplate expands the URL template tmpl as defined by RFC 6570, up to level 3, with vars, e.g.
+//
+//	xurl.ExpandTemplate("https://api.example.com/users/{id}/posts{?page,limit}", map[string]string{"id": "42", "page": "2"})
+//
+// returns "https://api.example.com/users/42/posts?page=2". Variables missing from vars are undefined
+// and skipped. Values are percent-encoded, reserved characters included unless the expression uses
+// the "+" or "#" operators. It fails with ErrInvalidTemplate if tmpl is not a valid template.
+func ExpandTemplate(tmpl string, vars map[string]string) (string, error) {
+	parts, err := parseTemplate(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for _, p := range parts {
+		if p.vars == nil {
+			sb.WriteString(p.literal)
+			continue
+		}
+
+		first := true
+		for _, name := range p.vars {
+			value, ok := vars[name]
+			if !ok {
+				continue
+			}
+
+			if first {
+				sb.WriteString(p.op.first)
+				first = false
+			} else {
+				sb.WriteString(p.op.sep)
+			}
+
+			if p.op.named {
+				sb.WriteString(name)
+				if value == "" {
+					sb.WriteString(p.op.ifEmpty)
+					continue
+				}
+				sb.WriteByte('=')
+			}
+			writeTemplateValue(&sb, value, p.op.allowReserved)
+		}
+	}
+	return sb.String(), nil
+}
+
+// ValidateTemplate checks that tmpl is a valid URL template as defined by RFC 6570, up to level 3.
+// It fails with ErrInvalidTemplate otherwise.
+func ValidateTemplate(tmpl string) error {
+	_, err := parseTemplate(tmpl)
+	return err
+}
+
+func parseTemplate(tmpl string) ([]templatePart, error) {
+	var parts []templatePart
+	var literal strings.Builder
+
+	for i := 0; i < len(tmpl); i++ {
+		c := tmpl[i]
+		switch {
+		case c == '{':
+			end := strings.IndexByte(tmpl[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unclosed expression at offset %d", ErrInvalidTemplate, i)
+			}
+			p, err := parseTemplateExpression(tmpl[i+1 : i+end])
+			if err != nil {
+				return nil, fmt.Errorf("%w at offset %d", err, i)
+			}
+			if literal.Len() > 0 {
+				parts = append(parts, templatePart{literal: literal.String()})
+				literal.Reset()
+			}
+			parts = append(parts, p)
+			i += end
+		case c == '%':
+			if !isPctEncoded(tmpl[i:]) {
+				return nil, fmt.Errorf("%w: invalid percent-encoding at offset %d", ErrInvalidTemplate, i)
+			}
+			literal.WriteString(tmpl[i : i+3])
+			i += 2
+		case c >= 0x80:
+			literal.WriteString(pctEncode(c))
+		case !isTemplateLiteral(c):
+			return nil, fmt.Errorf("%w: invalid character %q at offset %d", ErrInvalidTemplate, c, i)
+		default:
+			literal.WriteByte(c)
+		}
+	}
+
+	if literal.Len() > 0 {
+		parts = append(parts, templatePart{literal: literal.String()})
+	}
+	return parts, nil
+}
+
+func parseTemplateExpression(expr string) (templatePart, error) {
+	var opChar byte
+	if expr != "" {
+		if _, ok := templateOperators[expr[0]]; ok && expr[0] != 0 {
+			opChar = expr[0]
+			expr = expr[1:]
+		} else if strings.IndexByte("=,!@|", expr[0]) >= 0 {
+			return templatePart{}, fmt.Errorf("%w: reserved operator %q", ErrInvalidTemplate, expr[0])
+		}
+	}
+
+	vars := strings.Split(expr, ",")
+	for _, name := range vars {
+		if strings.HasSuffix(name, "*") || strings.IndexByte(name, ':') >= 0 {
+			return templatePart{}, fmt.Errorf("%w: unsupported modifier in variable %q", ErrInvalidTemplate, name)
+		}
+		if !isTemplateVarName(name) {
+			return templatePart{}, fmt.Errorf("%w: invalid variable name %q", ErrInvalidTemplate, name)
+		}
+	}
+
+	return templatePart{op: templateOperators[opChar], vars: vars}, nil
+}
+
+// isTemplateVarName reports whether name is made of dot-separated ALPHA / DIGIT / "_" / pct-encoded characters.
+func isTemplateVarName(name string) bool {
+	if name == "" || name[0] == '.' || name[len(name)-1] == '.' || strings.Contains(name, "..") {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '%':
+			if !isPctEncoded(name[i:]) {
+				return false
+			}
+			i += 2
+		case c != '.' && c != '_' && !isAlphaNum(c):
+			return false
+		}
+	}
+	return true
+}
+
+// isTemplateLiteral reports whether c is an ASCII character allowed as is outside of expressions.
+func isTemplateLiteral(c byte) bool {
+	return c > ' ' && c < 0x7f && strings.IndexByte("\"'%<>\\^`{|}", c) < 0
+}
+
+func writeTemplateValue(sb *strings.Builder, value string, allowReserved bool) {
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case isUnreserved(c):
+			sb.WriteByte(c)
+		case allowReserved && isReserved(c):
+			sb.WriteByte(c)
+		case allowReserved && c == '%' && isPctEncoded(value[i:]):
+			sb.WriteString(value[i : i+3])
+			i += 2
+		default:
+			sb.WriteString(pctEncode(c))
+		}
+	}
+}
+
+func isAlphaNum(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+func isUnreserved(c byte) bool {
+	return isAlphaNum(c) || c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func isReserved(c byte) bool {
+	return strings.IndexByte(":/?#[]@!$&'()*+,;=", c) >= 0
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// isPctEncoded reports whether s starts with a percent-encoded octet.
+func isPctEncoded(s string) bool {
+	return len(s) >= 3 && s[0] == '%' && isHex(s[1]) && isHex(s[2])
+}
+
+func pctEncode(c byte) string {
+	return string([]byte{'%', upperHex[c>>4], upperHex[c&0xf]})
+}
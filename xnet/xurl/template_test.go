@@ -0,0 +1,113 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xurl_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jlourenc/xgo/xnet/xurl"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	// Variables and expectations from the examples of RFC 6570, section 1.2.
+	vars := map[string]string{
+		"var":   "value",
+		"hello": "Hello World!",
+		"path":  "/foo/bar",
+		"empty": "",
+		"x":     "1024",
+		"y":     "768",
+	}
+
+	testCases := []struct {
+		name     string
+		tmpl     string
+		expected string
+	}{
+		{name: "no expression", tmpl: "https://example.com/users", expected: "https://example.com/users"},
+		{name: "simple", tmpl: "{var}", expected: "value"},
+		{name: "simple escaped", tmpl: "{hello}", expected: "Hello%20World%21"},
+		{name: "simple empty", tmpl: "O{empty}X", expected: "OX"},
+		{name: "simple undefined", tmpl: "O{undef}X", expected: "OX"},
+		{name: "reserved", tmpl: "{+var}", expected: "value"},
+		{name: "reserved escaped", tmpl: "{+hello}", expected: "Hello%20World!"},
+		{name: "reserved path", tmpl: "{+path}/here", expected: "/foo/bar/here"},
+		{name: "reserved in query", tmpl: "here?ref={+path}", expected: "here?ref=/foo/bar"},
+		{name: "fragment", tmpl: "X{#var}", expected: "X#value"},
+		{name: "fragment escaped", tmpl: "X{#hello}", expected: "X#Hello%20World!"},
+		{name: "multiple simple", tmpl: "map?{x,y}", expected: "map?1024,768"},
+		{name: "multiple simple escaped", tmpl: "{x,hello,y}", expected: "1024,Hello%20World%21,768"},
+		{name: "multiple reserved", tmpl: "{+x,hello,y}", expected: "1024,Hello%20World!,768"},
+		{name: "multiple reserved path", tmpl: "{+path,x}/here", expected: "/foo/bar,1024/here"},
+		{name: "multiple fragment", tmpl: "{#x,hello,y}", expected: "#1024,Hello%20World!,768"},
+		{name: "multiple fragment path", tmpl: "{#path,x}/here", expected: "#/foo/bar,1024/here"},
+		{name: "label", tmpl: "X{.var}", expected: "X.value"},
+		{name: "multiple label", tmpl: "X{.x,y}", expected: "X.1024.768"},
+		{name: "path segment", tmpl: "{/var}", expected: "/value"},
+		{name: "multiple path segments", tmpl: "{/var,x}/here", expected: "/value/1024/here"},
+		{name: "path parameters", tmpl: "{;x,y}", expected: ";x=1024;y=768"},
+		{name: "path parameters empty", tmpl: "{;x,y,empty}", expected: ";x=1024;y=768;empty"},
+		{name: "query", tmpl: "{?x,y}", expected: "?x=1024&y=768"},
+		{name: "query empty", tmpl: "{?x,y,empty}", expected: "?x=1024&y=768&empty="},
+		{name: "query undefined", tmpl: "/search{?undef}", expected: "/search"},
+		{name: "query partially undefined", tmpl: "{?undef,x}", expected: "?x=1024"},
+		{name: "query continuation", tmpl: "?fixed=yes{&x}", expected: "?fixed=yes&x=1024"},
+		{name: "multiple query continuation", tmpl: "{&x,y,empty}", expected: "&x=1024&y=768&empty="},
+		{name: "non ascii literal", tmpl: "/café/{var}", expected: "/caf%C3%A9/value"},
+		{name: "pct-encoded literal", tmpl: "/a%20b/{var}", expected: "/a%20b/value"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := xurl.ExpandTemplate(tc.tmpl, vars)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("expected %v; got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		tmpl        string
+		expectedErr bool
+	}{
+		{name: "valid", tmpl: "https://api.example.com/users/{id}/posts{?page,limit}", expectedErr: false},
+		{name: "dotted variable", tmpl: "{user.id}", expectedErr: false},
+		{name: "pct-encoded variable", tmpl: "{user%20id}", expectedErr: false},
+		{name: "unclosed expression", tmpl: "/users/{id", expectedErr: true},
+		{name: "empty expression", tmpl: "/users/{}", expectedErr: true},
+		{name: "empty variable", tmpl: "{a,,b}", expectedErr: true},
+		{name: "invalid variable", tmpl: "{a-b}", expectedErr: true},
+		{name: "invalid dotted variable", tmpl: "{a..b}", expectedErr: true},
+		{name: "reserved operator", tmpl: "{=var}", expectedErr: true},
+		{name: "explode modifier", tmpl: "{var*}", expectedErr: true},
+		{name: "prefix modifier", tmpl: "{var:3}", expectedErr: true},
+		{name: "space literal", tmpl: "/a b", expectedErr: true},
+		{name: "closing brace literal", tmpl: "/a}", expectedErr: true},
+		{name: "invalid percent-encoding", tmpl: "/a%zz", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := xurl.ValidateTemplate(tc.tmpl)
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error is %t, got %v", tc.expectedErr, err)
+			}
+			if err != nil && !errors.Is(err, xurl.ErrInvalidTemplate) {
+				t.Errorf("error mismatch: expected %v; got %v", xurl.ErrInvalidTemplate, err)
+			}
+
+			if _, err = xurl.ExpandTemplate(tc.tmpl, nil); (err != nil) != tc.expectedErr {
+				t.Errorf("expected expansion error is %t, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
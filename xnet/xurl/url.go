@@ -19,6 +19,9 @@ const (
 // JoinBasePath joins a base path and any number of path elements into a single path, escaping path elements and
 // separating them as well as the base with slashes. Empty elements are ignored. If the argument list is empty or
 // all its elements are empty, JoinBasePath returns the base path only.
+//
+// The base is not parsed, its query string or fragment, if any, ending up before the path elements.
+// See JoinBasePathE to join them to a base URL.
 func JoinBasePath(base string, elems ...string) string {
 	if !strings.HasSuffix(base, slash) {
 		base += slash
@@ -27,6 +30,48 @@ func JoinBasePath(base string, elems ...string) string {
 	return base + JoinPath(elems...)
 }
 
+// JoinBasePathE acts like JoinBasePath but parses base as a URL, returning an error if it is not valid,
+// and joins the path elements to its path. By default, the query string and the fragment of base are removed,
+// unless configured otherwise with the options passed in input.
+func JoinBasePathE(base string, elems []string, options ...JoinOption) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+
+	var cfg joinConfig
+	for _, opt := range options {
+		opt.apply(&cfg)
+	}
+
+	path := u.EscapedPath()
+	if !strings.HasSuffix(path, slash) {
+		path += slash
+	}
+	path += JoinPath(elems...)
+
+	if u.Path, err = url.PathUnescape(path); err != nil {
+		return "", err
+	}
+	u.RawPath = path
+
+	if !cfg.preserveQuery {
+		u.RawQuery, u.ForceQuery = "", false
+	}
+	if len(cfg.query) > 0 {
+		values := u.Query()
+		for k, v := range cfg.query {
+			values[k] = append(values[k], v...)
+		}
+		u.RawQuery = values.Encode()
+	}
+	if !cfg.preserveFragment {
+		u.Fragment, u.RawFragment = "", ""
+	}
+
+	return u.String(), nil
+}
+
 // JoinPath joins any number of path elements into a single path, escaping and separating them with slashes.
 // Empty elements are ignored. If the argument list is empty or all its elements are empty,
 // JoinPath returns an empty string.
@@ -38,3 +83,53 @@ func JoinPath(elems ...string) string {
 
 	return path.Join(escapedElems...)
 }
+
+type joinConfig struct {
+	preserveFragment bool
+	preserveQuery    bool
+	query            url.Values
+}
+
+type (
+	// JoinOption configures how a base URL is joined with path elements when calling JoinBasePathE.
+	JoinOption interface {
+		apply(c *joinConfig)
+	}
+
+	funcJoinOption struct {
+		fn func(*joinConfig)
+	}
+)
+
+func newFuncJoinOption(fn func(*joinConfig)) funcJoinOption {
+	return funcJoinOption{
+		fn: fn,
+	}
+}
+
+func (o funcJoinOption) apply(c *joinConfig) {
+	o.fn(c)
+}
+
+// JoinMergeQuery returns a JoinOption that preserves the query string of the base URL
+// and adds the values of query to it.
+func JoinMergeQuery(query url.Values) JoinOption {
+	return newFuncJoinOption(func(c *joinConfig) {
+		c.preserveQuery = true
+		c.query = query
+	})
+}
+
+// JoinPreserveFragment returns a JoinOption that preserves the fragment of the base URL.
+func JoinPreserveFragment() JoinOption {
+	return newFuncJoinOption(func(c *joinConfig) {
+		c.preserveFragment = true
+	})
+}
+
+// JoinPreserveQuery returns a JoinOption that preserves the query string of the base URL.
+func JoinPreserveQuery() JoinOption {
+	return newFuncJoinOption(func(c *joinConfig) {
+		c.preserveQuery = true
+	})
+}
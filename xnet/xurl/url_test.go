@@ -5,6 +5,7 @@
 package xurl_test
 
 import (
+	"net/url"
 	"testing"
 
 	"github.com/jlourenc/xgo/xnet/xurl"
@@ -54,6 +55,82 @@ func TestJoinBasePath(t *testing.T) {
 	}
 }
 
+func TestJoinBasePathE(t *testing.T) {
+	testCases := []struct {
+		name        string
+		base        string
+		elems       []string
+		options     []xurl.JoinOption
+		expected    string
+		expectedErr bool
+	}{
+		{
+			name:     "nil elements",
+			base:     "http://localhost:80",
+			elems:    nil,
+			expected: "http://localhost:80/",
+		},
+		{
+			name:     "multiple elements",
+			base:     "http://localhost:80/api/",
+			elems:    []string{"segment1", "segment_to_%escape"},
+			expected: "http://localhost:80/api/segment1/segment_to_%25escape",
+		},
+		{
+			name:     "escaped base path",
+			base:     "http://localhost:80/a%2Fb",
+			elems:    []string{"c"},
+			expected: "http://localhost:80/a%2Fb/c",
+		},
+		{
+			name:     "query and fragment removed",
+			base:     "http://localhost:80/api?v=1#top",
+			elems:    []string{"segment"},
+			expected: "http://localhost:80/api/segment",
+		},
+		{
+			name:     "query preserved",
+			base:     "http://localhost:80/api?v=1#top",
+			elems:    []string{"segment"},
+			options:  []xurl.JoinOption{xurl.JoinPreserveQuery()},
+			expected: "http://localhost:80/api/segment?v=1",
+		},
+		{
+			name:     "fragment preserved",
+			base:     "http://localhost:80/api?v=1#top",
+			elems:    []string{"segment"},
+			options:  []xurl.JoinOption{xurl.JoinPreserveFragment()},
+			expected: "http://localhost:80/api/segment#top",
+		},
+		{
+			name:     "query merged",
+			base:     "http://localhost:80/api?v=1",
+			elems:    []string{"segment"},
+			options:  []xurl.JoinOption{xurl.JoinMergeQuery(url.Values{"v": {"2"}, "a": {"b"}})},
+			expected: "http://localhost:80/api/segment?a=b&v=1&v=2",
+		},
+		{
+			name:        "invalid base",
+			base:        "http://[::1",
+			elems:       []string{"segment"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := xurl.JoinBasePathE(tc.base, tc.elems, tc.options...)
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error is %t, got %v", tc.expectedErr, err)
+			}
+
+			if tc.expected != got {
+				t.Errorf("expected %v; got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
 func TestJoinPath(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -0,0 +1,200 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xunit
+
+import (
+	"math"
+	"time"
+)
+
+// Enumeration of bit rate units.
+const (
+	Bps BitRate = 1 // bit per second
+
+	// Decimal.
+	Kbps = Bps * 1000  // 10^3 bits per second
+	Mbps = Kbps * 1000 // 10^6 bits per second
+	Gbps = Mbps * 1000 // 10^9 bits per second
+	Tbps = Gbps * 1000 // 10^12 bits per second
+	Pbps = Tbps * 1000 // 10^15 bits per second
+	Ebps = Pbps * 1000 // 10^18 bits per second
+
+	// Binary.
+	Kibps = Bps << 10   // 2^10 bits per second
+	Mibps = Kibps << 10 // 2^20 bits per second
+	Gibps = Mibps << 10 // 2^30 bits per second
+	Tibps = Gibps << 10 // 2^40 bits per second
+	Pibps = Tibps << 10 // 2^50 bits per second
+	Eibps = Pibps << 10 // 2^60 bits per second
+)
+
+// BitRate is a count of bits per second.
+type BitRate int64
+
+var bitRateUnits = newUnitSystem(
+	"bit rate",
+	Bps,
+	map[BitRate]string{
+		Bps:   "bps",
+		Kbps:  "Kbps",
+		Kibps: "Kibps",
+		Mbps:  "Mbps",
+		Mibps: "Mibps",
+		Gbps:  "Gbps",
+		Gibps: "Gibps",
+		Tbps:  "Tbps",
+		Tibps: "Tibps",
+		Pbps:  "Pbps",
+		Pibps: "Pibps",
+		Ebps:  "Ebps",
+		Eibps: "Eibps",
+	},
+	[]BitRate{Eibps, Ebps, Pibps, Pbps, Tibps, Tbps, Gibps, Gbps, Mibps, Mbps, Kibps, Kbps},
+	false,
+)
+
+// ParseBitRate parses a bit rate string which is a number followed by a bit rate unit suffix
+// (e.g. '100Mbps' or '1.5Gibps'). The following units are available:
+//
+//	bps:   Bit per second
+//	Kbps:  Kilobit per second
+//	Kibps: Kibibit per second
+//	Mbps:  Megabit per second
+//	Mibps: Mebibit per second
+//	Gbps:  Gigabit per second
+//	Gibps: Gibibit per second
+//	Tbps:  Terabit per second
+//	Tibps: Tebibit per second
+//	Pbps:  Petabit per second
+//	Pibps: Pebibit per second
+//	Ebps:  Exabit per second
+//	Eibps: Exbibit per second
+func ParseBitRate(s string) (BitRate, error) {
+	return bitRateUnits.parse(s)
+}
+
+// BitRateOf returns the bit rate at which b bytes are transferred in d, clamped to the range of BitRate
+// if it overflows. Duration must be > 0, otherwise it panics.
+func BitRateOf(b Byte, d time.Duration) BitRate {
+	if d <= 0 {
+		panic("invalid duration value")
+	}
+	return clampFloat[BitRate](math.Round(float64(b) * 8 * float64(time.Second) / float64(d)))
+}
+
+// Bytes returns the number of bytes transferred in d at rate r, clamped to the range of Byte if it overflows.
+func (r BitRate) Bytes(d time.Duration) Byte {
+	return clampFloat[Byte](float64(r) / 8 * d.Seconds())
+}
+
+// Duration returns the time needed to transfer b bytes at rate r, clamped to the range of time.Duration
+// if it overflows. Rate must be > 0, otherwise it panics.
+func (r BitRate) Duration(b Byte) time.Duration {
+	if r <= 0 {
+		panic("invalid bit rate value")
+	}
+	return clampFloat[time.Duration](math.Round(float64(b) * 8 * float64(time.Second) / float64(r)))
+}
+
+// Get returns the BitRate value.
+// It makes BitRate implement the flag package Getter interface.
+func (r BitRate) Get() any { return r }
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// The encoding is the same as returned by String.
+func (r BitRate) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// Set parses the string in input and assign it to r if valid, otherwise an error is returned.
+// It makes BitRate implement the flag package Value interface.
+func (r *BitRate) Set(s string) error {
+	br, err := ParseBitRate(s)
+	if err != nil {
+		return err
+	}
+	*r = br
+	return nil
+}
+
+// String returns a string representation of BitRate with the most suitable unit.
+func (r BitRate) String() string {
+	return bitRateUnits.format(r)
+}
+
+// Type returns a string representation of BitRate type.
+// It makes BitRate implement the pflag Value interface.
+func (BitRate) Type() string { return "xunit_bit_rate" }
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The text is expected in a form accepted by ParseBitRate.
+func (r *BitRate) UnmarshalText(text []byte) error {
+	return r.Set(string(text))
+}
+
+// Bps returns the value in bits per second.
+func (r BitRate) Bps() int64 {
+	return int64(r)
+}
+
+// Kbps returns the value in kilobits per second (10^3).
+func (r BitRate) Kbps() float64 {
+	return toUnit(r, Kbps)
+}
+
+// Mbps returns the value in megabits per second (10^6).
+func (r BitRate) Mbps() float64 {
+	return toUnit(r, Mbps)
+}
+
+// Gbps returns the value in gigabits per second (10^9).
+func (r BitRate) Gbps() float64 {
+	return toUnit(r, Gbps)
+}
+
+// Tbps returns the value in terabits per second (10^12).
+func (r BitRate) Tbps() float64 {
+	return toUnit(r, Tbps)
+}
+
+// Pbps returns the value in petabits per second (10^15).
+func (r BitRate) Pbps() float64 {
+	return toUnit(r, Pbps)
+}
+
+// Ebps returns the value in exabits per second (10^18).
+func (r BitRate) Ebps() float64 {
+	return toUnit(r, Ebps)
+}
+
+// Kibps returns the value in kibibits per second (2^10).
+func (r BitRate) Kibps() float64 {
+	return toUnit(r, Kibps)
+}
+
+// Mibps returns the value in mebibits per second (2^20).
+func (r BitRate) Mibps() float64 {
+	return toUnit(r, Mibps)
+}
+
+// Gibps returns the value in gibibits per second (2^30).
+func (r BitRate) Gibps() float64 {
+	return toUnit(r, Gibps)
+}
+
+// Tibps returns the value in tebibits per second (2^40).
+func (r BitRate) Tibps() float64 {
+	return toUnit(r, Tibps)
+}
+
+// Pibps returns the value in pebibits per second (2^50).
+func (r BitRate) Pibps() float64 {
+	return toUnit(r, Pibps)
+}
+
+// Eibps returns the value in exbibits per second (2^60).
+func (r BitRate) Eibps() float64 {
+	return toUnit(r, Eibps)
+}
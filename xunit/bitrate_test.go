@@ -0,0 +1,292 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xunit_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xunit"
+)
+
+func TestParseBitRate(t *testing.T) {
+	testCases := []struct {
+		input           string
+		expectedBitRate xunit.BitRate
+		expectedErr     error
+	}{
+		{"", 0, errors.New("empty bit rate representation")},
+		{"0.1.2Kbps", 0, errors.New("invalid bit rate representation: 0.1.2Kbps")},
+		{"X", 0, errors.New("invalid bit rate representation: X")},
+		{"1MB", 0, errors.New("invalid bit rate representation: 1MB")},
+		{"9223372036854775808", 0, errors.New("invalid bit rate representation: 9223372036854775808")},
+		{"-1Mbps", -xunit.Mbps, nil},
+		{"0", 0, nil},
+		{"1", xunit.Bps, nil},
+		{"1bps", xunit.Bps, nil},
+		{"10Kbps", 10 * xunit.Kbps, nil},
+		{"10Kibps", 10 * xunit.Kibps, nil},
+		{"100Mbps", 100 * xunit.Mbps, nil},
+		{"100mbps", 100 * xunit.Mbps, nil},
+		{"1.5Mibps", 1572864, nil},
+		{"2.5Gbps", 2500000000, nil},
+		{"1Gibps", xunit.Gibps, nil},
+		{"1Tbps", xunit.Tbps, nil},
+		{"1Tibps", xunit.Tibps, nil},
+		{"2Pbps", 2 * xunit.Pbps, nil},
+		{"2Pibps", 2 * xunit.Pibps, nil},
+		{"1Ebps", xunit.Ebps, nil},
+		{"1Eibps", xunit.Eibps, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			qty, err := xunit.ParseBitRate(tc.input)
+
+			if tc.expectedBitRate != qty {
+				t.Errorf("expected %s; got %s", tc.expectedBitRate, qty)
+			}
+
+			if (tc.expectedErr == nil && err != nil) || (tc.expectedErr != nil && err == nil) ||
+				(tc.expectedErr != nil && tc.expectedErr.Error() != err.Error()) {
+				t.Errorf("expected error %s; got %s", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestBitRateOf(t *testing.T) {
+	testCases := []struct {
+		name     string
+		bytes    xunit.Byte
+		duration time.Duration
+		expected xunit.BitRate
+	}{
+		{"zero", 0, time.Second, 0},
+		{"1B/s", xunit.B, time.Second, 8 * xunit.Bps},
+		{"1MB/s", xunit.MB, time.Second, 8 * xunit.Mbps},
+		{"1MiB/s", xunit.MiB, time.Second, 8 * xunit.Mibps},
+		{"125MB/s", 125 * xunit.MB, time.Second, xunit.Gbps},
+		{"1MB/100ms", xunit.MB, 100 * time.Millisecond, 80 * xunit.Mbps},
+		{"1GB/min", xunit.GB, time.Minute, 133333333},
+		{"overflow", xunit.EiB, time.Nanosecond, math.MaxInt64},
+		{"negative overflow", -xunit.EiB, time.Nanosecond, math.MinInt64},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xunit.BitRateOf(tc.bytes, tc.duration)
+
+			if tc.expected != got {
+				t.Errorf("expected %s; got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestBitRateOf_Panic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("panic mismatch: expected %t; got %v", true, r)
+		}
+	}()
+	xunit.BitRateOf(xunit.MB, 0)
+}
+
+func TestBitRate_Bytes_Duration(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rate     xunit.BitRate
+		duration time.Duration
+		bytes    xunit.Byte
+	}{
+		{"8bps", 8 * xunit.Bps, time.Second, xunit.B},
+		{"1Gbps", xunit.Gbps, time.Second, 125 * xunit.MB},
+		{"8Mibps", 8 * xunit.Mibps, time.Minute, 60 * xunit.MiB},
+		{"80Mbps", 80 * xunit.Mbps, 100 * time.Millisecond, xunit.MB},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name+"_bytes", func(t *testing.T) {
+			got := tc.rate.Bytes(tc.duration)
+
+			if tc.bytes != got {
+				t.Errorf("expected %s; got %s", tc.bytes, got)
+			}
+		})
+		t.Run(tc.name+"_duration", func(t *testing.T) {
+			got := tc.rate.Duration(tc.bytes)
+
+			if tc.duration != got {
+				t.Errorf("expected %s; got %s", tc.duration, got)
+			}
+		})
+	}
+}
+
+func TestBitRate_Duration_Panic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("panic mismatch: expected %t; got %v", true, r)
+		}
+	}()
+	xunit.BitRate(0).Duration(xunit.MB)
+}
+
+func TestBitRate_Bytes_Duration_Overflow(t *testing.T) {
+	if got := xunit.BitRate(math.MaxInt64).Bytes(math.MaxInt64); got != math.MaxInt64 {
+		t.Errorf("expected %s; got %s", xunit.Byte(math.MaxInt64), got)
+	}
+	if got := xunit.BitRate(math.MinInt64).Bytes(math.MaxInt64); got != math.MinInt64 {
+		t.Errorf("expected %s; got %s", xunit.Byte(math.MinInt64), got)
+	}
+	if got := xunit.Bps.Duration(xunit.EiB); got != math.MaxInt64 {
+		t.Errorf("expected %s; got %s", time.Duration(math.MaxInt64), got)
+	}
+}
+
+func TestBitRate_Get(t *testing.T) {
+	r := 100 * xunit.Mbps
+
+	got := r.Get()
+
+	if got != r {
+		t.Errorf("expected %s; got %s", r, got)
+	}
+}
+
+func TestBitRate_Set_UnmarshalText(t *testing.T) {
+	testCases := []struct {
+		name            string
+		input           string
+		expectedBitRate xunit.BitRate
+		expectedErr     error
+	}{
+		{
+			name:        "empty bit rate representation",
+			input:       "",
+			expectedErr: errors.New("empty bit rate representation"),
+		},
+		{
+			name:        "invalid bit rate representation",
+			input:       "2X",
+			expectedErr: errors.New("invalid bit rate representation: 2X"),
+		},
+		{
+			name:            "valid bit rate representation",
+			input:           "2Mbps",
+			expectedBitRate: 2 * xunit.Mbps,
+		},
+	}
+
+	for _, tc := range testCases {
+		for name, set := range map[string]func(r *xunit.BitRate, s string) error{
+			"set":            (*xunit.BitRate).Set,
+			"unmarshal_text": func(r *xunit.BitRate, s string) error { return r.UnmarshalText([]byte(s)) },
+		} {
+			t.Run(tc.name+"_"+name, func(t *testing.T) {
+				var r xunit.BitRate
+
+				err := set(&r, tc.input)
+
+				if tc.expectedBitRate != r {
+					t.Errorf("expected %s; got %s", tc.expectedBitRate, r)
+				}
+
+				if (tc.expectedErr == nil && err != nil) || (tc.expectedErr != nil && err == nil) ||
+					(tc.expectedErr != nil && tc.expectedErr.Error() != err.Error()) {
+					t.Errorf("expected error %s; got %s", tc.expectedErr, err)
+				}
+			})
+		}
+	}
+}
+
+func TestBitRate_MarshalText_String(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    xunit.BitRate
+		expected string
+	}{
+		{"-1Mbps", -xunit.Mbps, "-1Mbps"},
+		{"0", 0, "0bps"},
+		{"1bps", xunit.Bps, "1bps"},
+		{"1234bps", 1234 * xunit.Bps, "1234bps"},
+		{"1Kbps", xunit.Kbps, "1Kbps"},
+		{"1Kibps", xunit.Kibps, "1Kibps"},
+		{"100Mbps", 100 * xunit.Mbps, "100Mbps"},
+		{"1.5Mibps", 1572864, "1.5Mibps"},
+		{"2.5Gbps", 2500000000, "2.5Gbps"},
+		{"1Tbps", xunit.Tbps, "1Tbps"},
+		{"1Eibps", xunit.Eibps, "1Eibps"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name+"_string", func(t *testing.T) {
+			got := tc.input.String()
+
+			if tc.expected != got {
+				t.Errorf("expected %s; got %s", tc.expected, got)
+			}
+		})
+		t.Run(tc.name+"_marshal_text", func(t *testing.T) {
+			got, err := tc.input.MarshalText()
+
+			if tc.expected != string(got) {
+				t.Errorf("expected %s; got %s", tc.expected, got)
+			}
+
+			if err != nil {
+				t.Errorf("no error expected; got %s", err)
+			}
+		})
+	}
+}
+
+func TestBitRate_Type(t *testing.T) {
+	var r xunit.BitRate
+	expected := "xunit_bit_rate"
+
+	got := r.Type()
+
+	if expected != got {
+		t.Errorf("expected %s; got %s", expected, got)
+	}
+}
+
+func TestBitRate_Units(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fn       func(xunit.BitRate) float64
+		input    xunit.BitRate
+		expected float64
+	}{
+		{"bps", func(r xunit.BitRate) float64 { return float64(r.Bps()) }, xunit.Kbps, 1000},
+		{"Kbps", xunit.BitRate.Kbps, 1500 * xunit.Bps, 1.5},
+		{"Mbps", xunit.BitRate.Mbps, xunit.Gbps, 1000},
+		{"Gbps", xunit.BitRate.Gbps, 2500 * xunit.Mbps, 2.5},
+		{"Tbps", xunit.BitRate.Tbps, xunit.Pbps, 1000},
+		{"Pbps", xunit.BitRate.Pbps, xunit.Ebps, 1000},
+		{"Ebps", xunit.BitRate.Ebps, xunit.Ebps, 1},
+		{"Kibps", xunit.BitRate.Kibps, 512 * xunit.Bps, 0.5},
+		{"Mibps", xunit.BitRate.Mibps, xunit.Gibps, 1024},
+		{"Gibps", xunit.BitRate.Gibps, 1536 * xunit.Mibps, 1.5},
+		{"Tibps", xunit.BitRate.Tibps, xunit.Pibps, 1024},
+		{"Pibps", xunit.BitRate.Pibps, xunit.Eibps, 1024},
+		{"Eibps", xunit.BitRate.Eibps, xunit.Eibps, 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.fn(tc.input)
+
+			if tc.expected != got {
+				t.Errorf("expected %f; got %f", tc.expected, got)
+			}
+		})
+	}
+}
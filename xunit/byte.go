@@ -7,19 +7,16 @@
 package xunit
 
 import (
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"math"
 	"strconv"
 	"strings"
 	"unicode"
 )
 
-func init() {
-	for k, v := range byteUnits {
-		byteUnitsString[strings.ToLower(v)] = k
-	}
-}
-
 // Enumeration of byte units.
 const (
 	B Byte = 1
@@ -41,16 +38,16 @@ const (
 	EiB = PiB << 10 // 2^60 bytes
 )
 
-const (
-	errByteEmptyMsg   = "empty byte representation"
-	errByteInvalidMsg = "invalid byte representation: "
-)
+// ErrOverflow is returned by checked arithmetic operations when the result does not fit in an int64.
+var ErrOverflow = errors.New("integer overflow")
 
 // Byte is a count of bytes.
 type Byte int64
 
-var (
-	byteUnits = map[Byte]string{
+var byteUnits = newUnitSystem(
+	"byte",
+	B,
+	map[Byte]string{
 		B:   "B",
 		KB:  "KB",
 		KiB: "KiB",
@@ -64,14 +61,40 @@ var (
 		PiB: "PiB",
 		EB:  "EB",
 		EiB: "EiB",
-	}
-	byteUnitsString = map[string]Byte{
-		"": B,
-	}
-	bytesUnitsDescOrder = []Byte{EiB, EB, PiB, PB, TiB, TB, GiB, GB, MiB, MB, KiB, KB}
+	},
+	[]Byte{EiB, EB, PiB, PB, TiB, TB, GiB, GB, MiB, MB, KiB, KB},
+	false,
+)
+
+var (
+	byteBinaryUnitsDesc  = []Byte{EiB, PiB, TiB, GiB, MiB, KiB}
+	byteDecimalUnitsDesc = []Byte{EB, PB, TB, GB, MB, KB}
 )
 
-// Parse parses a byte string which is a number followed by a byte unit suffix (e.g. '1024MB' or '1GiB').
+var byteUnitWords = func() map[string]Byte {
+	words := make(map[string]Byte)
+	for unit, word := range map[Byte]string{
+		B:   "byte",
+		KB:  "kilobyte",
+		KiB: "kibibyte",
+		MB:  "megabyte",
+		MiB: "mebibyte",
+		GB:  "gigabyte",
+		GiB: "gibibyte",
+		TB:  "terabyte",
+		TiB: "tebibyte",
+		PB:  "petabyte",
+		PiB: "pebibyte",
+		EB:  "exabyte",
+		EiB: "exbibyte",
+	} {
+		words[word] = unit
+		words[word+"s"] = unit
+	}
+	return words
+}()
+
+// ParseByte parses a byte string which is a number followed by a byte unit suffix (e.g. '1024MB' or '1GiB').
 // The following units are available:
 //
 //	B:   Byte
@@ -87,59 +110,137 @@ var (
 //	PiB: Pebibbyte
 //	EB:  Exabyte
 //	EiB: Exbibyte
-func ParseByte(s string) (Byte, error) {
-	s = strings.TrimSpace(s)
+//
+// By default, parsing is strict. Options passed in input accept more lenient forms, e.g. '2 MiB' or '1,024 bytes'.
+// It returns ErrOverflow if the number of bytes does not fit in a Byte.
+func ParseByte(s string, options ...ParseByteOption) (Byte, error) {
+	if len(options) == 0 {
+		return byteUnits.parse(s)
+	}
 
-	if s == "" {
-		return 0, errors.New(errByteEmptyMsg)
+	var opts parseOptions[Byte]
+	for _, opt := range options {
+		opt.apply(&opts)
 	}
+	return byteUnits.parseWith(s, opts)
+}
 
-	isFloat := false
-	i := 0
+// AddByte returns a + b, or ErrOverflow if the result overflows.
+func AddByte(a, b Byte) (Byte, error) {
+	s := a + b
+	if (b > 0 && s < a) || (b < 0 && s > a) {
+		return 0, ErrOverflow
+	}
+	return s, nil
+}
 
-strLoop:
-	for _, r := range s {
-		switch {
-		case r == '.':
-			isFloat = true
-		case !unicode.IsDigit(r) && r != '-':
-			break strLoop
-		}
-		i++
+// SubByte returns a - b, or ErrOverflow if the result overflows.
+func SubByte(a, b Byte) (Byte, error) {
+	s := a - b
+	if (b > 0 && s > a) || (b < 0 && s < a) {
+		return 0, ErrOverflow
 	}
+	return s, nil
+}
 
-	unit, ok := byteUnitsString[strings.ToLower(s[i:])]
+// MulByte returns b * n, or ErrOverflow if the result overflows.
+func MulByte(b Byte, n int64) (Byte, error) {
+	p, ok := mulInt64(b, n)
 	if !ok {
-		return 0, errors.New(errByteInvalidMsg + s)
+		return 0, ErrOverflow
 	}
+	return p, nil
+}
 
-	if !isFloat { // no fractional floating-point numbers
-		qty, err := strconv.ParseInt(s[:i], 10, 64)
-		if err != nil {
-			return 0, errors.New(errByteInvalidMsg + s)
-		}
-		return Byte(qty) * unit, nil
+// ScaleByte returns b * f truncated toward zero, or ErrOverflow if the result overflows or is not a number.
+func ScaleByte(b Byte, f float64) (Byte, error) {
+	s := float64(b) * f
+	if math.IsNaN(s) || s >= math.MaxInt64 || s < math.MinInt64 {
+		return 0, ErrOverflow
 	}
+	return Byte(s), nil
+}
 
-	qty, err := strconv.ParseFloat(s[:i], 64)
+// SaturatingAddByte returns a + b, clamped to the range of Byte if the result overflows.
+func SaturatingAddByte(a, b Byte) Byte {
+	s, err := AddByte(a, b)
 	if err != nil {
-		return 0, errors.New(errByteInvalidMsg + s)
+		return saturate(b > 0)
 	}
+	return s
+}
 
-	whole, frac := math.Modf(qty)
-	return Byte((whole * float64(unit)) + (frac * float64(unit))), nil
+// SaturatingSubByte returns a - b, clamped to the range of Byte if the result overflows.
+func SaturatingSubByte(a, b Byte) Byte {
+	s, err := SubByte(a, b)
+	if err != nil {
+		return saturate(b < 0)
+	}
+	return s
+}
+
+// SaturatingMulByte returns b * n, clamped to the range of Byte if the result overflows.
+func SaturatingMulByte(b Byte, n int64) Byte {
+	p, err := MulByte(b, n)
+	if err != nil {
+		return saturate((b > 0) == (n > 0))
+	}
+	return p
+}
+
+// SaturatingScaleByte returns b * f truncated toward zero, clamped to the range of Byte if the result overflows.
+// It returns 0 if the result is not a number.
+func SaturatingScaleByte(b Byte, f float64) Byte {
+	return clampFloat[Byte](float64(b) * f)
+}
+
+func saturate(positive bool) Byte {
+	if positive {
+		return math.MaxInt64
+	}
+	return math.MinInt64
 }
 
 // Get returns the Byte value.
 // It makes Byte implement the flag package Getter interface.
 func (b Byte) Get() any { return b }
 
+// MarshalJSON implements the json.Marshaler interface.
+// The encoding is a quoted string, the same as returned by String. Use ByteNumber to encode a number of bytes instead.
+func (b Byte) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(b.String())), nil
+}
+
 // MarshalText implements the encoding.TextMarshaler interface.
 // The encoding is the same as returned by String.
 func (b Byte) MarshalText() ([]byte, error) {
 	return []byte(b.String()), nil
 }
 
+// Scan implements the sql.Scanner interface.
+// The value is expected to be either
+// 1) an integer number of bytes, or
+// 2) a string in a form accepted by ParseByte.
+func (b *Byte) Scan(src any) error {
+	switch v := src.(type) {
+	case int64:
+		*b = Byte(v)
+		return nil
+	case float64:
+		if v != math.Trunc(v) || v >= math.MaxInt64 || v < math.MinInt64 {
+			return fmt.Errorf("cannot scan %v into xunit.Byte", v)
+		}
+		*b = Byte(v)
+		return nil
+	case []byte:
+		return b.Set(string(v))
+	case string:
+		return b.Set(v)
+	default:
+		return fmt.Errorf("cannot scan %T into xunit.Byte", src)
+	}
+}
+
 // Set parses the string in input and assign it to b if valid, otherwise an error is returned.
 // It makes Byte implement the flag package Value interface.
 func (b *Byte) Set(s string) error {
@@ -153,39 +254,120 @@ func (b *Byte) Set(s string) error {
 
 // String returns a string representation of Byte with the most suitable unit.
 func (b Byte) String() string {
-	if b == 0 {
-		return "0B"
+	return byteUnits.format(b)
+}
+
+// FormatByte returns a string representation of b formatted with the options passed in input.
+// Without options, it is the same as returned by String. Otherwise, b is formatted in the largest binary unit,
+// or decimal if FormatByteDecimal is used, in which its absolute value is >= 1, with up to 2 decimal places.
+func FormatByte(b Byte, options ...FormatByteOption) string {
+	if len(options) == 0 {
+		return b.String()
 	}
 
-	for _, unit := range bytesUnitsDescOrder {
-		qty := b.toUnit(unit)
+	opts := formatOptions{
+		precision: -1,
+		units:     byteBinaryUnitsDesc,
+	}
+	for _, opt := range options {
+		opt.apply(&opts)
+	}
 
-		if math.Abs(qty) < 1 {
-			continue
+	unit := opts.unit
+	if unit == 0 {
+		unit = B
+		for _, u := range opts.units {
+			if math.Abs(b.toUnit(u)) >= 1 {
+				unit = u
+				break
+			}
 		}
+	}
 
-		if checkDecimalPlaces(0, qty) {
-			return strconv.FormatInt(int64(qty), 10) + byteUnits[unit]
+	var qty string
+	if opts.precision >= 0 {
+		qty = strconv.FormatFloat(b.toUnit(unit), 'f', opts.precision, 64)
+	} else {
+		qty = strconv.FormatFloat(b.toUnit(unit), 'f', 2, 64)
+		qty = strings.TrimSuffix(strings.TrimRight(qty, "0"), ".")
+		if qty == "-0" {
+			qty = "0"
 		}
+	}
 
-		if checkDecimalPlaces(2, qty) {
-			return strconv.FormatFloat(qty, 'g', 53, 64) + byteUnits[unit]
-		}
+	if opts.space {
+		return qty + " " + byteUnits.symbols[unit]
 	}
+	return qty + byteUnits.symbols[unit]
+}
 
-	return strconv.FormatInt(int64(b), 10) + byteUnits[B]
+// Format returns a string representation of b formatted with the options passed in input.
+//
+// See FormatByte for more information.
+func (b Byte) Format(options ...FormatByteOption) string {
+	return FormatByte(b, options...)
 }
 
 // Type returns a string representation of Byte type.
 // It makes Byte implement the pflag Value interface.
 func (Byte) Type() string { return "xunit_byte" }
 
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The value is expected to be either
+// 1) a number of bytes, or
+// 2) a quoted string in a form accepted by ParseByte.
+//
+// A null value is a no-op.
+func (b *Byte) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+	return b.Set(s)
+}
+
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
 // The text is expected in a form accepted by ParseByte.
 func (b *Byte) UnmarshalText(text []byte) error {
 	return b.Set(string(text))
 }
 
+// Value implements the driver.Valuer interface.
+// The value is stored as an int64 number of bytes.
+func (b Byte) Value() (driver.Value, error) {
+	return int64(b), nil
+}
+
+// ByteNumber is a Byte encoded to JSON as a number of bytes rather than a string,
+// e.g. for fields of config structs consumed by other programs.
+type ByteNumber Byte
+
+// MarshalJSON implements the json.Marshaler interface.
+// The encoding is a number of bytes.
+func (b ByteNumber) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(int64(b), 10)), nil
+}
+
+// String returns a string representation of ByteNumber with the most suitable unit.
+//
+// See Byte.String for more information.
+func (b ByteNumber) String() string {
+	return Byte(b).String()
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+//
+// See Byte.UnmarshalJSON for more information.
+func (b *ByteNumber) UnmarshalJSON(data []byte) error {
+	return (*Byte)(b).UnmarshalJSON(data)
+}
+
 // B returns the value in bytes.
 func (b Byte) B() int64 {
 	return int64(b)
@@ -252,13 +434,132 @@ func (b Byte) EiB() float64 {
 }
 
 func (b Byte) toUnit(unit Byte) float64 {
-	whole := b / unit
-	remainder := b - (whole * unit)
-	return float64(whole) + float64(remainder)/float64(unit)
+	return toUnit(b, unit)
+}
+
+type (
+	// ParseByteOption configures the syntax accepted when calling ParseByte.
+	ParseByteOption interface {
+		apply(opts *parseOptions[Byte])
+	}
+
+	funcParseByteOption struct {
+		fn func(*parseOptions[Byte])
+	}
+)
+
+func newFuncParseByteOption(fn func(*parseOptions[Byte])) funcParseByteOption {
+	return funcParseByteOption{
+		fn: fn,
+	}
+}
+
+func (o funcParseByteOption) apply(opts *parseOptions[Byte]) {
+	o.fn(opts)
+}
+
+// ParseByteExponent returns a ParseByteOption that accepts numbers in exponent notation, e.g. '1.5e3KB'.
+func ParseByteExponent() ParseByteOption {
+	return newFuncParseByteOption(func(opts *parseOptions[Byte]) {
+		opts.exponent = true
+	})
+}
+
+// ParseByteSpace returns a ParseByteOption that accepts whitespace between the number and the unit, e.g. '2 MiB'.
+func ParseByteSpace() ParseByteOption {
+	return newFuncParseByteOption(func(opts *parseOptions[Byte]) {
+		opts.space = true
+	})
 }
 
-func checkDecimalPlaces(i int, value float64) bool {
-	value *= math.Pow(10.0, float64(i))
-	extra := value - float64(int64(value))
-	return extra == 0
+// ParseByteThousandsSeparator returns a ParseByteOption that accepts sep between the digits of the number,
+// e.g. ',' in '1,024KB'. Separator must not be a digit, a sign, a dot or a letter, otherwise it panics.
+func ParseByteThousandsSeparator(sep rune) ParseByteOption {
+	if sep == 0 || sep == '.' || sep == '-' || sep == '+' || unicode.IsDigit(sep) || unicode.IsLetter(sep) {
+		panic("invalid thousands separator value")
+	}
+	return newFuncParseByteOption(func(opts *parseOptions[Byte]) {
+		opts.separator = sep
+	})
+}
+
+// ParseByteUnitWords returns a ParseByteOption that accepts full unit names, singular or plural,
+// e.g. '2 megabytes' when combined with ParseByteSpace.
+func ParseByteUnitWords() ParseByteOption {
+	return newFuncParseByteOption(func(opts *parseOptions[Byte]) {
+		opts.words = byteUnitWords
+	})
+}
+
+type formatOptions struct {
+	precision int
+	space     bool
+	unit      Byte
+	units     []Byte
+}
+
+type (
+	// FormatByteOption configures the formatting of a Byte when calling FormatByte or Byte.Format.
+	FormatByteOption interface {
+		apply(opts *formatOptions)
+	}
+
+	funcFormatByteOption struct {
+		fn func(*formatOptions)
+	}
+)
+
+func newFuncFormatByteOption(fn func(*formatOptions)) funcFormatByteOption {
+	return funcFormatByteOption{
+		fn: fn,
+	}
+}
+
+func (o funcFormatByteOption) apply(opts *formatOptions) {
+	o.fn(opts)
+}
+
+// FormatByteBinary returns a FormatByteOption that formats a Byte in binary units, e.g. 'MiB'.
+// It is the default, unless FormatByteDecimal is used.
+func FormatByteBinary() FormatByteOption {
+	return newFuncFormatByteOption(func(opts *formatOptions) {
+		opts.units = byteBinaryUnitsDesc
+	})
+}
+
+// FormatByteDecimal returns a FormatByteOption that formats a Byte in decimal units, e.g. 'MB'.
+func FormatByteDecimal() FormatByteOption {
+	return newFuncFormatByteOption(func(opts *formatOptions) {
+		opts.units = byteDecimalUnitsDesc
+	})
+}
+
+// FormatBytePrecision returns a FormatByteOption that configures the exact number of decimal places,
+// e.g. '1.50GiB' for a precision of 2. Value must be >= 0, otherwise it panics.
+// If not used, up to 2 decimal places are formatted, without trailing zeros.
+func FormatBytePrecision(precision int) FormatByteOption {
+	if precision < 0 {
+		panic("invalid precision value")
+	}
+	return newFuncFormatByteOption(func(opts *formatOptions) {
+		opts.precision = precision
+	})
+}
+
+// FormatByteSpace returns a FormatByteOption that separates the number from the unit with a space, e.g. '1.5 GiB'.
+func FormatByteSpace() FormatByteOption {
+	return newFuncFormatByteOption(func(opts *formatOptions) {
+		opts.space = true
+	})
+}
+
+// FormatByteUnit returns a FormatByteOption that forces the unit a Byte is formatted in, whatever its value,
+// e.g. '0.5MiB' or '2048MiB' for MiB. Unit must be one of the Byte units, otherwise it panics.
+func FormatByteUnit(unit Byte) FormatByteOption {
+	if _, ok := byteUnits.symbols[unit]; !ok {
+		panic("invalid unit value")
+	}
+	return newFuncFormatByteOption(func(opts *formatOptions) {
+		opts.unit = unit
+	})
 }
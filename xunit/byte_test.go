@@ -5,7 +5,11 @@
 package xunit_test
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
+	"math"
 	"testing"
 
 	"github.com/jlourenc/xgo/xunit"
@@ -24,6 +28,10 @@ func TestParseByte(t *testing.T) {
 		{"9223372036854775808", 0, errors.New("invalid byte representation: 9223372036854775808")},
 		{"-9223372036854775809", 0, errors.New("invalid byte representation: -9223372036854775809")},
 		{"-9223372036854775808", -9223372036854775808, nil},
+		{"9999999999999EiB", 0, xunit.ErrOverflow},
+		{"8EiB", 0, xunit.ErrOverflow},
+		{"-8EiB", -8 * xunit.EiB, nil},
+		{"8.5EiB", 0, xunit.ErrOverflow},
 		{"-2PB", -2 * xunit.PB, nil},
 		{"-2pb", -2 * xunit.PB, nil},
 		{"-2Pb", -2 * xunit.PB, nil},
@@ -610,3 +618,461 @@ func TestByte_EiB(t *testing.T) {
 		})
 	}
 }
+
+func TestByte_Arithmetic(t *testing.T) {
+	const (
+		maxByte xunit.Byte = math.MaxInt64
+		minByte xunit.Byte = math.MinInt64
+	)
+
+	testCases := []struct {
+		name              string
+		checked           func() (xunit.Byte, error)
+		saturating        func() xunit.Byte
+		expected          xunit.Byte
+		expectedSaturated xunit.Byte
+		expectedErr       error
+	}{
+		{
+			name:              "add",
+			checked:           func() (xunit.Byte, error) { return xunit.AddByte(xunit.GiB, xunit.MiB) },
+			saturating:        func() xunit.Byte { return xunit.SaturatingAddByte(xunit.GiB, xunit.MiB) },
+			expected:          xunit.GiB + xunit.MiB,
+			expectedSaturated: xunit.GiB + xunit.MiB,
+		},
+		{
+			name:              "add overflow",
+			checked:           func() (xunit.Byte, error) { return xunit.AddByte(maxByte, xunit.B) },
+			saturating:        func() xunit.Byte { return xunit.SaturatingAddByte(maxByte, xunit.B) },
+			expectedSaturated: maxByte,
+			expectedErr:       xunit.ErrOverflow,
+		},
+		{
+			name:              "add underflow",
+			checked:           func() (xunit.Byte, error) { return xunit.AddByte(minByte, -xunit.B) },
+			saturating:        func() xunit.Byte { return xunit.SaturatingAddByte(minByte, -xunit.B) },
+			expectedSaturated: minByte,
+			expectedErr:       xunit.ErrOverflow,
+		},
+		{
+			name:              "sub",
+			checked:           func() (xunit.Byte, error) { return xunit.SubByte(xunit.GiB, xunit.MiB) },
+			saturating:        func() xunit.Byte { return xunit.SaturatingSubByte(xunit.GiB, xunit.MiB) },
+			expected:          xunit.GiB - xunit.MiB,
+			expectedSaturated: xunit.GiB - xunit.MiB,
+		},
+		{
+			name:              "sub overflow",
+			checked:           func() (xunit.Byte, error) { return xunit.SubByte(maxByte, -xunit.B) },
+			saturating:        func() xunit.Byte { return xunit.SaturatingSubByte(maxByte, -xunit.B) },
+			expectedSaturated: maxByte,
+			expectedErr:       xunit.ErrOverflow,
+		},
+		{
+			name:              "sub underflow",
+			checked:           func() (xunit.Byte, error) { return xunit.SubByte(minByte, xunit.B) },
+			saturating:        func() xunit.Byte { return xunit.SaturatingSubByte(minByte, xunit.B) },
+			expectedSaturated: minByte,
+			expectedErr:       xunit.ErrOverflow,
+		},
+		{
+			name:              "mul",
+			checked:           func() (xunit.Byte, error) { return xunit.MulByte(xunit.GiB, -3) },
+			saturating:        func() xunit.Byte { return xunit.SaturatingMulByte(xunit.GiB, -3) },
+			expected:          -3 * xunit.GiB,
+			expectedSaturated: -3 * xunit.GiB,
+		},
+		{
+			name:              "mul by zero",
+			checked:           func() (xunit.Byte, error) { return xunit.MulByte(maxByte, 0) },
+			saturating:        func() xunit.Byte { return xunit.SaturatingMulByte(maxByte, 0) },
+			expected:          0,
+			expectedSaturated: 0,
+		},
+		{
+			name:              "mul overflow",
+			checked:           func() (xunit.Byte, error) { return xunit.MulByte(4*xunit.EiB, 2) },
+			saturating:        func() xunit.Byte { return xunit.SaturatingMulByte(4*xunit.EiB, 2) },
+			expectedSaturated: maxByte,
+			expectedErr:       xunit.ErrOverflow,
+		},
+		{
+			name:              "mul negative overflow",
+			checked:           func() (xunit.Byte, error) { return xunit.MulByte(minByte, -1) },
+			saturating:        func() xunit.Byte { return xunit.SaturatingMulByte(minByte, -1) },
+			expectedSaturated: maxByte,
+			expectedErr:       xunit.ErrOverflow,
+		},
+		{
+			name:              "mul underflow",
+			checked:           func() (xunit.Byte, error) { return xunit.MulByte(-xunit.EiB, 9) },
+			saturating:        func() xunit.Byte { return xunit.SaturatingMulByte(-xunit.EiB, 9) },
+			expectedSaturated: minByte,
+			expectedErr:       xunit.ErrOverflow,
+		},
+		{
+			name:              "scale",
+			checked:           func() (xunit.Byte, error) { return xunit.ScaleByte(xunit.GiB, 1.5) },
+			saturating:        func() xunit.Byte { return xunit.SaturatingScaleByte(xunit.GiB, 1.5) },
+			expected:          1536 * xunit.MiB,
+			expectedSaturated: 1536 * xunit.MiB,
+		},
+		{
+			name:              "scale truncated",
+			checked:           func() (xunit.Byte, error) { return xunit.ScaleByte(3*xunit.B, -0.5) },
+			saturating:        func() xunit.Byte { return xunit.SaturatingScaleByte(3*xunit.B, -0.5) },
+			expected:          -xunit.B,
+			expectedSaturated: -xunit.B,
+		},
+		{
+			name:              "scale overflow",
+			checked:           func() (xunit.Byte, error) { return xunit.ScaleByte(xunit.EiB, 8) },
+			saturating:        func() xunit.Byte { return xunit.SaturatingScaleByte(xunit.EiB, 8) },
+			expectedSaturated: maxByte,
+			expectedErr:       xunit.ErrOverflow,
+		},
+		{
+			name:              "scale underflow",
+			checked:           func() (xunit.Byte, error) { return xunit.ScaleByte(xunit.EiB, math.Inf(-1)) },
+			saturating:        func() xunit.Byte { return xunit.SaturatingScaleByte(xunit.EiB, math.Inf(-1)) },
+			expectedSaturated: minByte,
+			expectedErr:       xunit.ErrOverflow,
+		},
+		{
+			name:              "scale not a number",
+			checked:           func() (xunit.Byte, error) { return xunit.ScaleByte(xunit.EiB, math.NaN()) },
+			saturating:        func() xunit.Byte { return xunit.SaturatingScaleByte(xunit.EiB, math.NaN()) },
+			expectedSaturated: 0,
+			expectedErr:       xunit.ErrOverflow,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name+"_checked", func(t *testing.T) {
+			got, err := tc.checked()
+
+			if tc.expected != got {
+				t.Errorf("expected %s; got %s", tc.expected, got)
+			}
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected error %v; got %v", tc.expectedErr, err)
+			}
+		})
+		t.Run(tc.name+"_saturating", func(t *testing.T) {
+			got := tc.saturating()
+
+			if tc.expectedSaturated != got {
+				t.Errorf("expected %d; got %d", tc.expectedSaturated, got)
+			}
+		})
+	}
+}
+
+func TestByte_MarshalJSON(t *testing.T) {
+	type config struct {
+		MaxSize    xunit.Byte         `json:"max_size"`
+		BufferSize xunit.ByteNumber   `json:"buffer_size"`
+		Limit      *xunit.Byte        `json:"limit,omitempty"`
+		Sizes      []xunit.ByteNumber `json:"sizes,omitempty"`
+	}
+
+	limit := 2 * xunit.GB
+
+	testCases := []struct {
+		name     string
+		input    config
+		expected string
+	}{
+		{
+			name:     "zero",
+			input:    config{},
+			expected: `{"max_size":"0B","buffer_size":0}`,
+		},
+		{
+			name: "values",
+			input: config{
+				MaxSize:    512 * xunit.MiB,
+				BufferSize: xunit.ByteNumber(4 * xunit.KiB),
+				Limit:      &limit,
+				Sizes:      []xunit.ByteNumber{1, xunit.ByteNumber(xunit.KB)},
+			},
+			expected: `{"max_size":"512MiB","buffer_size":4096,"limit":"2GB","sizes":[1,1000]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := json.Marshal(tc.input)
+
+			if tc.expected != string(got) {
+				t.Errorf("expected %s; got %s", tc.expected, got)
+			}
+
+			if err != nil {
+				t.Errorf("no error expected; got %s", err)
+			}
+		})
+	}
+}
+
+func TestByte_UnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		name         string
+		input        string
+		expectedByte xunit.Byte
+		expectedErr  bool
+	}{
+		{name: "null", input: `null`, expectedByte: xunit.KiB},
+		{name: "number", input: `1048576`, expectedByte: xunit.MiB},
+		{name: "negative number", input: `-1024`, expectedByte: -xunit.KiB},
+		{name: "decimal number", input: `1024.0`, expectedByte: xunit.KiB},
+		{name: "string", input: `"512MiB"`, expectedByte: 512 * xunit.MiB},
+		{name: "string without unit", input: `"1024"`, expectedByte: xunit.KiB},
+		{name: "escaped string", input: `"2\u0047B"`, expectedByte: 2 * xunit.GB},
+		{name: "empty string", input: `""`, expectedByte: xunit.KiB, expectedErr: true},
+		{name: "invalid string", input: `"2X"`, expectedByte: xunit.KiB, expectedErr: true},
+		{name: "number overflow", input: `9223372036854775808`, expectedByte: xunit.KiB, expectedErr: true},
+		{name: "boolean", input: `true`, expectedByte: xunit.KiB, expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := xunit.KiB
+			n := xunit.ByteNumber(xunit.KiB)
+
+			err := json.Unmarshal([]byte(tc.input), &b)
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error is %t, got %v", tc.expectedErr, err)
+			}
+			if tc.expectedByte != b {
+				t.Errorf("expected %s; got %s", tc.expectedByte, b)
+			}
+
+			err = json.Unmarshal([]byte(tc.input), &n)
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error is %t, got %v", tc.expectedErr, err)
+			}
+			if xunit.ByteNumber(tc.expectedByte) != n {
+				t.Errorf("expected %s; got %s", xunit.ByteNumber(tc.expectedByte), n)
+			}
+		})
+	}
+}
+
+func TestParseByte_Options(t *testing.T) {
+	lenient := []xunit.ParseByteOption{
+		xunit.ParseByteExponent(),
+		xunit.ParseByteSpace(),
+		xunit.ParseByteThousandsSeparator(','),
+		xunit.ParseByteUnitWords(),
+	}
+
+	testCases := []struct {
+		name         string
+		input        string
+		options      []xunit.ParseByteOption
+		expectedByte xunit.Byte
+		expectedErr  error
+	}{
+		{"strict space", "2 MiB", nil, 0, errors.New("invalid byte representation: 2 MiB")},
+		{"strict word", "2megabytes", nil, 0, errors.New("invalid byte representation: 2megabytes")},
+		{"strict separator", "1,024KB", nil, 0, errors.New("invalid byte representation: 1,024KB")},
+		{"strict exponent", "1e3KB", nil, 0, errors.New("invalid byte representation: 1e3KB")},
+		{"empty", " ", lenient, 0, errors.New("empty byte representation")},
+		{"space", "2 MiB", []xunit.ParseByteOption{xunit.ParseByteSpace()}, 2 * xunit.MiB, nil},
+		{"spaces", " 2 \t MiB ", []xunit.ParseByteOption{xunit.ParseByteSpace()}, 2 * xunit.MiB, nil},
+		{"space not allowed", "2 MiB", []xunit.ParseByteOption{xunit.ParseByteUnitWords()}, 0, errors.New("invalid byte representation: 2 MiB")},
+		{"word", "2megabytes", []xunit.ParseByteOption{xunit.ParseByteUnitWords()}, 2 * xunit.MB, nil},
+		{"singular word", "1Gibibyte", []xunit.ParseByteOption{xunit.ParseByteUnitWords()}, xunit.GiB, nil},
+		{"word with space", "2 megabytes", lenient, 2 * xunit.MB, nil},
+		{"bytes", "512 bytes", lenient, 512, nil},
+		{"unknown word", "2 megabits", lenient, 0, errors.New("invalid byte representation: 2 megabits")},
+		{"separator", "1,024KB", []xunit.ParseByteOption{xunit.ParseByteThousandsSeparator(',')}, 1024 * xunit.KB, nil},
+		{"separators", "-1,048,576", []xunit.ParseByteOption{xunit.ParseByteThousandsSeparator(',')}, -xunit.MiB, nil},
+		{"separator with decimals", "1,024.5 KiB", lenient, 1049088, nil},
+		{"unicode separator", "1\u202f024KB", []xunit.ParseByteOption{xunit.ParseByteThousandsSeparator('\u202f')}, 1024 * xunit.KB, nil},
+		{"space separator", "1 024 KB", []xunit.ParseByteOption{xunit.ParseByteSpace(), xunit.ParseByteThousandsSeparator(' ')}, 1024 * xunit.KB, nil},
+		{"leading separator", ",024KB", lenient, 0, errors.New("invalid byte representation: ,024KB")},
+		{"trailing separator", "1,KB", lenient, 0, errors.New("invalid byte representation: 1,KB")},
+		{"exponent", "1e3KB", []xunit.ParseByteOption{xunit.ParseByteExponent()}, xunit.MB, nil},
+		{"signed exponent", "+1.5E+3 KB", lenient, 1500 * xunit.KB, nil},
+		{"negative exponent", "1024e-3KB", lenient, xunit.KiB, nil},
+		{"exabyte not exponent", "1EB", lenient, xunit.EB, nil},
+		{"exabyte with space", "1 EiB", lenient, xunit.EiB, nil},
+		{"exponent overflow", "1e30KB", lenient, 0, xunit.ErrOverflow},
+		{"missing number", "KB", lenient, 0, errors.New("invalid byte representation: KB")},
+		{"overflow", "9,223,372,036,854,775,808", lenient, 0, errors.New("invalid byte representation: 9,223,372,036,854,775,808")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			qty, err := xunit.ParseByte(tc.input, tc.options...)
+
+			if tc.expectedByte != qty {
+				t.Errorf("expected %s; got %s", tc.expectedByte, qty)
+			}
+
+			if (tc.expectedErr == nil && err != nil) || (tc.expectedErr != nil && err == nil) ||
+				(tc.expectedErr != nil && tc.expectedErr.Error() != err.Error()) {
+				t.Errorf("expected error %s; got %s", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestParseByteThousandsSeparator(t *testing.T) {
+	testCases := []struct {
+		name  string
+		sep   rune
+		panic bool
+	}{
+		{"zero", 0, true},
+		{"digit", '1', true},
+		{"dot", '.', true},
+		{"minus", '-', true},
+		{"plus", '+', true},
+		{"letter", 'k', true},
+		{"comma", ',', false},
+		{"underscore", '_', false},
+		{"space", ' ', false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tc.panic {
+					t.Errorf("panic mismatch: expected %t; got %v", tc.panic, r)
+				}
+			}()
+			xunit.ParseByteThousandsSeparator(tc.sep)
+		})
+	}
+}
+
+func TestFormatByte(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    xunit.Byte
+		options  []xunit.FormatByteOption
+		expected string
+	}{
+		{"no options", 1500 * xunit.MB, nil, "1.5GB"},
+		{"zero", 0, []xunit.FormatByteOption{xunit.FormatByteSpace()}, "0 B"},
+		{"bytes", 512, []xunit.FormatByteOption{xunit.FormatByteBinary()}, "512B"},
+		{"binary", 1536 * xunit.MiB, []xunit.FormatByteOption{xunit.FormatByteBinary()}, "1.5GiB"},
+		{"binary default", 1500 * xunit.MB, []xunit.FormatByteOption{xunit.FormatByteSpace()}, "1.4 GiB"},
+		{"binary rounded", 1234567, []xunit.FormatByteOption{xunit.FormatByteBinary()}, "1.18MiB"},
+		{"negative binary", -1536 * xunit.MiB, []xunit.FormatByteOption{xunit.FormatByteBinary()}, "-1.5GiB"},
+		{"decimal", 1536 * xunit.MiB, []xunit.FormatByteOption{xunit.FormatByteDecimal()}, "1.61GB"},
+		{"decimal exact", 1500 * xunit.MB, []xunit.FormatByteOption{xunit.FormatByteDecimal()}, "1.5GB"},
+		{"decimal after binary", xunit.MB, []xunit.FormatByteOption{xunit.FormatByteBinary(), xunit.FormatByteDecimal()}, "1MB"},
+		{"precision", 1536 * xunit.MiB, []xunit.FormatByteOption{xunit.FormatBytePrecision(2)}, "1.50GiB"},
+		{"precision zero", 1536 * xunit.MiB, []xunit.FormatByteOption{xunit.FormatBytePrecision(0)}, "2GiB"},
+		{"precision bytes", 512, []xunit.FormatByteOption{xunit.FormatBytePrecision(1)}, "512.0B"},
+		{"space", 1536 * xunit.MiB, []xunit.FormatByteOption{xunit.FormatBytePrecision(2), xunit.FormatByteSpace()}, "1.50 GiB"},
+		{"unit larger", 512 * xunit.KiB, []xunit.FormatByteOption{xunit.FormatByteUnit(xunit.MiB)}, "0.5MiB"},
+		{"unit smaller", 2 * xunit.GiB, []xunit.FormatByteOption{xunit.FormatByteUnit(xunit.MiB)}, "2048MiB"},
+		{"unit bytes", 2 * xunit.KiB, []xunit.FormatByteOption{xunit.FormatByteUnit(xunit.B)}, "2048B"},
+		{"unit tiny", 1, []xunit.FormatByteOption{xunit.FormatByteUnit(xunit.MiB)}, "0MiB"},
+		{"unit negative tiny", -1, []xunit.FormatByteOption{xunit.FormatByteUnit(xunit.MiB)}, "0MiB"},
+		{"unit decimal", 1536 * xunit.MiB, []xunit.FormatByteOption{xunit.FormatByteUnit(xunit.GB), xunit.FormatBytePrecision(3)}, "1.611GB"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name+"_func", func(t *testing.T) {
+			got := xunit.FormatByte(tc.input, tc.options...)
+
+			if tc.expected != got {
+				t.Errorf("expected %s; got %s", tc.expected, got)
+			}
+		})
+		t.Run(tc.name+"_method", func(t *testing.T) {
+			got := tc.input.Format(tc.options...)
+
+			if tc.expected != got {
+				t.Errorf("expected %s; got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestFormatByte_Panic(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func()
+		panic bool
+	}{
+		{"negative precision", func() { xunit.FormatBytePrecision(-1) }, true},
+		{"zero precision", func() { xunit.FormatBytePrecision(0) }, false},
+		{"invalid unit", func() { xunit.FormatByteUnit(3 * xunit.KiB) }, true},
+		{"zero unit", func() { xunit.FormatByteUnit(0) }, true},
+		{"valid unit", func() { xunit.FormatByteUnit(xunit.TiB) }, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tc.panic {
+					t.Errorf("panic mismatch: expected %t; got %v", tc.panic, r)
+				}
+			}()
+			tc.fn()
+		})
+	}
+}
+
+func TestByte_Scan(t *testing.T) {
+	testCases := []struct {
+		name         string
+		input        any
+		expectedByte xunit.Byte
+		expectedErr  bool
+	}{
+		{name: "int64", input: int64(1048576), expectedByte: xunit.MiB},
+		{name: "negative int64", input: int64(-1024), expectedByte: -xunit.KiB},
+		{name: "float64", input: float64(1024), expectedByte: xunit.KiB},
+		{name: "fractional float64", input: 1.5, expectedByte: xunit.KiB, expectedErr: true},
+		{name: "float64 overflow", input: math.MaxFloat64, expectedByte: xunit.KiB, expectedErr: true},
+		{name: "bytes", input: []byte("512MiB"), expectedByte: 512 * xunit.MiB},
+		{name: "string", input: "2GB", expectedByte: 2 * xunit.GB},
+		{name: "string number", input: "1024", expectedByte: xunit.KiB},
+		{name: "invalid string", input: "2X", expectedByte: xunit.KiB, expectedErr: true},
+		{name: "bool", input: true, expectedByte: xunit.KiB, expectedErr: true},
+		{name: "nil", input: nil, expectedByte: xunit.KiB, expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := xunit.KiB
+
+			err := b.Scan(tc.input)
+
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("expected error is %t, got %v", tc.expectedErr, err)
+			}
+			if tc.expectedByte != b {
+				t.Errorf("expected %s; got %s", tc.expectedByte, b)
+			}
+		})
+	}
+}
+
+func TestByte_Value(t *testing.T) {
+	var v driver.Valuer = 512 * xunit.MiB
+
+	got, err := v.Value()
+
+	if got != int64(536870912) {
+		t.Errorf("expected %d; got %v", 536870912, got)
+	}
+	if err != nil {
+		t.Errorf("no error expected; got %s", err)
+	}
+
+	var b xunit.Byte
+	var s sql.Scanner = &b
+	if err := s.Scan(got); err != nil || b != 512*xunit.MiB {
+		t.Errorf("expected %s; got %s, %v", 512*xunit.MiB, b, err)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xunit
+
+// Enumeration of count metric prefixes.
+const (
+	CountOne  Count = 1
+	CountKilo       = CountOne * 1000  // 10^3
+	CountMega       = CountKilo * 1000 // 10^6
+	CountGiga       = CountMega * 1000 // 10^9
+	CountTera       = CountGiga * 1000 // 10^12
+	CountPeta       = CountTera * 1000 // 10^15
+	CountExa        = CountPeta * 1000 // 10^18
+)
+
+// Count is a plain quantity, e.g. a number of requests or the size of a queue.
+type Count int64
+
+var countUnits = newUnitSystem(
+	"count",
+	CountOne,
+	map[Count]string{
+		CountOne:  "",
+		CountKilo: "K",
+		CountMega: "M",
+		CountGiga: "G",
+		CountTera: "T",
+		CountPeta: "P",
+		CountExa:  "E",
+	},
+	[]Count{CountExa, CountPeta, CountTera, CountGiga, CountMega, CountKilo},
+	false,
+)
+
+// ParseCount parses a count string which is a number optionally followed by a metric prefix
+// (e.g. '1500', '1.5K' or '2M'). Prefixes are decimal and case-insensitive. The following prefixes are available:
+//
+//	K: Kilo (10^3)
+//	M: Mega (10^6)
+//	G: Giga (10^9)
+//	T: Tera (10^12)
+//	P: Peta (10^15)
+//	E: Exa  (10^18)
+func ParseCount(s string) (Count, error) {
+	return countUnits.parse(s)
+}
+
+// Get returns the Count value.
+// It makes Count implement the flag package Getter interface.
+func (c Count) Get() any { return c }
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// The encoding is the same as returned by String.
+func (c Count) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// Set parses the string in input and assign it to c if valid, otherwise an error is returned.
+// It makes Count implement the flag package Value interface.
+func (c *Count) Set(s string) error {
+	cs, err := ParseCount(s)
+	if err != nil {
+		return err
+	}
+	*c = cs
+	return nil
+}
+
+// String returns a string representation of Count with the most suitable prefix.
+func (c Count) String() string {
+	return countUnits.format(c)
+}
+
+// Type returns a string representation of Count type.
+// It makes Count implement the pflag Value interface.
+func (Count) Type() string { return "xunit_count" }
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The text is expected in a form accepted by ParseCount.
+func (c *Count) UnmarshalText(text []byte) error {
+	return c.Set(string(text))
+}
+
+// Int64 returns the value as an int64.
+func (c Count) Int64() int64 {
+	return int64(c)
+}
+
+// Kilo returns the value in thousands (10^3).
+func (c Count) Kilo() float64 {
+	return toUnit(c, CountKilo)
+}
+
+// Mega returns the value in millions (10^6).
+func (c Count) Mega() float64 {
+	return toUnit(c, CountMega)
+}
+
+// Giga returns the value in billions (10^9).
+func (c Count) Giga() float64 {
+	return toUnit(c, CountGiga)
+}
+
+// Tera returns the value in trillions (10^12).
+func (c Count) Tera() float64 {
+	return toUnit(c, CountTera)
+}
+
+// Peta returns the value in quadrillions (10^15).
+func (c Count) Peta() float64 {
+	return toUnit(c, CountPeta)
+}
+
+// Exa returns the value in quintillions (10^18).
+func (c Count) Exa() float64 {
+	return toUnit(c, CountExa)
+}
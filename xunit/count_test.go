@@ -0,0 +1,191 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xunit_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jlourenc/xgo/xunit"
+)
+
+func TestParseCount(t *testing.T) {
+	testCases := []struct {
+		input         string
+		expectedCount xunit.Count
+		expectedErr   error
+	}{
+		{"", 0, errors.New("empty count representation")},
+		{"0.1.2K", 0, errors.New("invalid count representation: 0.1.2K")},
+		{"X", 0, errors.New("invalid count representation: X")},
+		{"1KB", 0, errors.New("invalid count representation: 1KB")},
+		{"9223372036854775808", 0, errors.New("invalid count representation: 9223372036854775808")},
+		{"-2K", -2 * xunit.CountKilo, nil},
+		{"0", 0, nil},
+		{"1500", 1500, nil},
+		{"1.5K", 1500, nil},
+		{"1.5k", 1500, nil},
+		{"2M", 2 * xunit.CountMega, nil},
+		{"2.25m", 2250000, nil},
+		{"3G", 3 * xunit.CountGiga, nil},
+		{"1T", xunit.CountTera, nil},
+		{"1P", xunit.CountPeta, nil},
+		{"1E", xunit.CountExa, nil},
+		{"9223372036854775807", 9223372036854775807, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			qty, err := xunit.ParseCount(tc.input)
+
+			if tc.expectedCount != qty {
+				t.Errorf("expected %s; got %s", tc.expectedCount, qty)
+			}
+
+			if (tc.expectedErr == nil && err != nil) || (tc.expectedErr != nil && err == nil) ||
+				(tc.expectedErr != nil && tc.expectedErr.Error() != err.Error()) {
+				t.Errorf("expected error %s; got %s", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestCount_Get(t *testing.T) {
+	c := 2 * xunit.CountKilo
+
+	got := c.Get()
+
+	if got != c {
+		t.Errorf("expected %s; got %s", c, got)
+	}
+}
+
+func TestCount_Set_UnmarshalText(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         string
+		expectedCount xunit.Count
+		expectedErr   error
+	}{
+		{
+			name:        "empty count representation",
+			input:       "",
+			expectedErr: errors.New("empty count representation"),
+		},
+		{
+			name:        "invalid count representation",
+			input:       "2X",
+			expectedErr: errors.New("invalid count representation: 2X"),
+		},
+		{
+			name:          "valid count representation",
+			input:         "2M",
+			expectedCount: 2 * xunit.CountMega,
+		},
+	}
+
+	for _, tc := range testCases {
+		for name, set := range map[string]func(c *xunit.Count, s string) error{
+			"set":            (*xunit.Count).Set,
+			"unmarshal_text": func(c *xunit.Count, s string) error { return c.UnmarshalText([]byte(s)) },
+		} {
+			t.Run(tc.name+"_"+name, func(t *testing.T) {
+				var c xunit.Count
+
+				err := set(&c, tc.input)
+
+				if tc.expectedCount != c {
+					t.Errorf("expected %s; got %s", tc.expectedCount, c)
+				}
+
+				if (tc.expectedErr == nil && err != nil) || (tc.expectedErr != nil && err == nil) ||
+					(tc.expectedErr != nil && tc.expectedErr.Error() != err.Error()) {
+					t.Errorf("expected error %s; got %s", tc.expectedErr, err)
+				}
+			})
+		}
+	}
+}
+
+func TestCount_MarshalText_String(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    xunit.Count
+		expected string
+	}{
+		{"-1.5K", -1500, "-1.5K"},
+		{"0", 0, "0"},
+		{"1", 1, "1"},
+		{"999", 999, "999"},
+		{"1K", xunit.CountKilo, "1K"},
+		{"1.5K", 1500, "1.5K"},
+		{"1234", 1234, "1234"},
+		{"2M", 2 * xunit.CountMega, "2M"},
+		{"2.25M", 2250000, "2.25M"},
+		{"3G", 3 * xunit.CountGiga, "3G"},
+		{"1T", xunit.CountTera, "1T"},
+		{"1P", xunit.CountPeta, "1P"},
+		{"1E", xunit.CountExa, "1E"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name+"_string", func(t *testing.T) {
+			got := tc.input.String()
+
+			if tc.expected != got {
+				t.Errorf("expected %s; got %s", tc.expected, got)
+			}
+		})
+		t.Run(tc.name+"_marshal_text", func(t *testing.T) {
+			got, err := tc.input.MarshalText()
+
+			if tc.expected != string(got) {
+				t.Errorf("expected %s; got %s", tc.expected, got)
+			}
+
+			if err != nil {
+				t.Errorf("no error expected; got %s", err)
+			}
+		})
+	}
+}
+
+func TestCount_Type(t *testing.T) {
+	var c xunit.Count
+	expected := "xunit_count"
+
+	got := c.Type()
+
+	if expected != got {
+		t.Errorf("expected %s; got %s", expected, got)
+	}
+}
+
+func TestCount_Units(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fn       func(xunit.Count) float64
+		input    xunit.Count
+		expected float64
+	}{
+		{"int64", func(c xunit.Count) float64 { return float64(c.Int64()) }, xunit.CountKilo, 1000},
+		{"kilo", xunit.Count.Kilo, 1500, 1.5},
+		{"mega", xunit.Count.Mega, xunit.CountGiga, 1000},
+		{"giga", xunit.Count.Giga, 2500 * xunit.CountMega, 2.5},
+		{"tera", xunit.Count.Tera, xunit.CountPeta, 1000},
+		{"peta", xunit.Count.Peta, xunit.CountExa, 1000},
+		{"exa", xunit.Count.Exa, xunit.CountExa, 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.fn(tc.input)
+
+			if tc.expected != got {
+				t.Errorf("expected %f; got %f", tc.expected, got)
+			}
+		})
+	}
+}
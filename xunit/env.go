@@ -0,0 +1,41 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xunit
+
+import (
+	"fmt"
+	"os"
+)
+
+// Parser is the constraint satisfied by pointers to the types of this package,
+// e.g. *Byte, which parse their string representation with Set.
+type Parser[T any] interface {
+	*T
+	Set(s string) error
+}
+
+// ParseEnv returns the value of the environment variable named by key, parsed as a T,
+// e.g. ParseEnv[Byte]("MAX_BODY_SIZE", 64*MiB) for MAX_BODY_SIZE=64MiB.
+// If the variable is unset or empty, def is returned. If the variable cannot be parsed,
+// def is returned along with an error naming the variable.
+func ParseEnv[T any, P Parser[T]](key string, def T) (T, error) {
+	s, ok := os.LookupEnv(key)
+	if !ok || s == "" {
+		return def, nil
+	}
+
+	var v T
+	if err := P(&v).Set(s); err != nil {
+		return def, fmt.Errorf("environment variable %s: %w", key, err)
+	}
+	return v, nil
+}
+
+// ByteFromEnv returns the value of the environment variable named by key parsed as a Byte.
+//
+// See ParseEnv for more information.
+func ByteFromEnv(key string, def Byte) (Byte, error) {
+	return ParseEnv[Byte](key, def)
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xunit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xunit"
+)
+
+func TestByteFromEnv(t *testing.T) {
+	testCases := []struct {
+		name         string
+		value        *string
+		expectedByte xunit.Byte
+		expectedErr  string
+	}{
+		{name: "unset", value: nil, expectedByte: xunit.MiB},
+		{name: "empty", value: ptr(""), expectedByte: xunit.MiB},
+		{name: "valid", value: ptr("64MiB"), expectedByte: 64 * xunit.MiB},
+		{name: "valid with spaces", value: ptr(" 2GB "), expectedByte: 2 * xunit.GB},
+		{
+			name:         "invalid",
+			value:        ptr("64X"),
+			expectedByte: xunit.MiB,
+			expectedErr:  "environment variable XUNIT_TEST_SIZE: invalid byte representation: 64X",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.value != nil {
+				t.Setenv("XUNIT_TEST_SIZE", *tc.value)
+			}
+
+			got, err := xunit.ByteFromEnv("XUNIT_TEST_SIZE", xunit.MiB)
+
+			if tc.expectedByte != got {
+				t.Errorf("expected %s; got %s", tc.expectedByte, got)
+			}
+
+			if (err == nil && tc.expectedErr != "") || (err != nil && err.Error() != tc.expectedErr) {
+				t.Errorf("expected error %q; got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestParseEnv(t *testing.T) {
+	t.Setenv("XUNIT_TEST_RATE", "100Mbps")
+	t.Setenv("XUNIT_TEST_FREQUENCY", "0.5Hz")
+	t.Setenv("XUNIT_TEST_COUNT", "1.5K")
+	t.Setenv("XUNIT_TEST_TEMPERATURE", "70F")
+
+	if got, err := xunit.ParseEnv[xunit.BitRate]("XUNIT_TEST_RATE", 0); got != 100*xunit.Mbps || err != nil {
+		t.Errorf("expected %s; got %s, %v", 100*xunit.Mbps, got, err)
+	}
+
+	if got, err := xunit.ParseEnv[xunit.Frequency]("XUNIT_TEST_FREQUENCY", 0); got.Period() != 2*time.Second || err != nil {
+		t.Errorf("expected %s; got %s, %v", 2*time.Second, got.Period(), err)
+	}
+
+	if got, err := xunit.ParseEnv[xunit.Count]("XUNIT_TEST_COUNT", 0); got != 1500 || err != nil {
+		t.Errorf("expected %d; got %d, %v", 1500, got, err)
+	}
+
+	if got, err := xunit.ParseEnv[xunit.Temperature]("XUNIT_TEST_TEMPERATURE", 0); got != xunit.Fahrenheit(70) || err != nil {
+		t.Errorf("expected %s; got %s, %v", xunit.Fahrenheit(70), got, err)
+	}
+
+	if got, err := xunit.ParseEnv[xunit.Count]("XUNIT_TEST_UNSET", 42); got != 42 || err != nil {
+		t.Errorf("expected %d; got %d, %v", 42, got, err)
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
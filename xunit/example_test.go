@@ -6,6 +6,7 @@ package xunit_test
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/jlourenc/xgo/xunit"
 )
@@ -19,6 +20,57 @@ func ExampleParseByte() {
 	// Output: 2GiB
 }
 
+func ExampleFormatByte() {
+	b := 1536 * xunit.MiB
+	fmt.Println(xunit.FormatByte(b, xunit.FormatBytePrecision(2), xunit.FormatByteSpace()))
+	fmt.Println(xunit.FormatByte(b, xunit.FormatByteUnit(xunit.MiB)))
+	// Output:
+	// 1.50 GiB
+	// 1536MiB
+}
+
+func ExampleParseBitRate() {
+	r, err := xunit.ParseBitRate("100Mbps")
+	if err != nil {
+		fmt.Printf("%s\n", err)
+	}
+	fmt.Printf("%s %s\n", r, r.Bytes(time.Second))
+	// Output: 100Mbps 12.5MB
+}
+
+func ExampleBitRateOf() {
+	r := xunit.BitRateOf(xunit.MiB, time.Second)
+	fmt.Printf("%s %s\n", r, r.Duration(xunit.GiB))
+	// Output: 8Mibps 17m4s
+}
+
+func ExampleParseCount() {
+	c, err := xunit.ParseCount("1.5K")
+	if err != nil {
+		fmt.Printf("%s\n", err)
+	}
+	fmt.Printf("%d %s\n", c, c*1000)
+	// Output: 1500 1.5M
+}
+
+func ExampleParseFrequency() {
+	f, err := xunit.ParseFrequency("0.5Hz")
+	if err != nil {
+		fmt.Printf("%s\n", err)
+	}
+	fmt.Printf("%s %s\n", f, f.Period())
+	// Output: 0.5Hz 2s
+}
+
+func ExampleParseTemperature() {
+	t, err := xunit.ParseTemperature("70F")
+	if err != nil {
+		fmt.Printf("%s\n", err)
+	}
+	fmt.Printf("%s %.2fK\n", t, t.Kelvin())
+	// Output: 21.111°C 294.26K
+}
+
 func ExampleByte_MarshalText() {
 	b := xunit.TiB + 512*xunit.GiB
 	bytes, err := b.MarshalText()
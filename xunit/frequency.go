@@ -0,0 +1,126 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xunit
+
+import (
+	"math"
+	"time"
+)
+
+// millihertz is the unit Frequency is stored in.
+const millihertz Frequency = 1
+
+// Enumeration of frequency units.
+const (
+	Hz  Frequency = 1000       // hertz
+	KHz           = Hz * 1000  // 10^3 hertz
+	MHz           = KHz * 1000 // 10^6 hertz
+	GHz           = MHz * 1000 // 10^9 hertz
+)
+
+// Frequency is a frequency stored in millihertz, so that frequencies below 1Hz,
+// e.g. 0.1Hz for an event occurring every 10s, can be represented.
+type Frequency int64
+
+var frequencyUnits = newUnitSystem(
+	"frequency",
+	Hz,
+	map[Frequency]string{
+		millihertz: "mHz",
+		Hz:         "Hz",
+		KHz:        "kHz",
+		MHz:        "MHz",
+		GHz:        "GHz",
+	},
+	[]Frequency{GHz, MHz, KHz},
+	true,
+)
+
+// ParseFrequency parses a frequency string which is a number followed by a frequency unit suffix
+// (e.g. '50Hz', '0.5Hz' or '2.4GHz'). A number without suffix is in hertz. Suffixes are case-sensitive,
+// so that millihertz and megahertz can be told apart, and the following units are available:
+//
+//	mHz: Millihertz
+//	Hz:  Hertz
+//	kHz: Kilohertz
+//	MHz: Megahertz
+//	GHz: Gigahertz
+func ParseFrequency(s string) (Frequency, error) {
+	return frequencyUnits.parse(s)
+}
+
+// FrequencyOf returns the frequency of an event occurring every d.
+// Duration must be > 0, otherwise it panics.
+func FrequencyOf(d time.Duration) Frequency {
+	if d <= 0 {
+		panic("invalid duration value")
+	}
+	return Frequency(math.Round(float64(Hz) * float64(time.Second) / float64(d)))
+}
+
+// Period returns the duration between two occurrences of an event at frequency f.
+// Frequency must be > 0, otherwise it panics.
+func (f Frequency) Period() time.Duration {
+	if f <= 0 {
+		panic("invalid frequency value")
+	}
+	return time.Duration(math.Round(float64(Hz) * float64(time.Second) / float64(f)))
+}
+
+// Get returns the Frequency value.
+// It makes Frequency implement the flag package Getter interface.
+func (f Frequency) Get() any { return f }
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// The encoding is the same as returned by String.
+func (f Frequency) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// Set parses the string in input and assign it to f if valid, otherwise an error is returned.
+// It makes Frequency implement the flag package Value interface.
+func (f *Frequency) Set(s string) error {
+	fs, err := ParseFrequency(s)
+	if err != nil {
+		return err
+	}
+	*f = fs
+	return nil
+}
+
+// String returns a string representation of Frequency with the most suitable unit.
+func (f Frequency) String() string {
+	return frequencyUnits.format(f)
+}
+
+// Type returns a string representation of Frequency type.
+// It makes Frequency implement the pflag Value interface.
+func (Frequency) Type() string { return "xunit_frequency" }
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The text is expected in a form accepted by ParseFrequency.
+func (f *Frequency) UnmarshalText(text []byte) error {
+	return f.Set(string(text))
+}
+
+// Hz returns the value in hertz.
+func (f Frequency) Hz() float64 {
+	return toUnit(f, Hz)
+}
+
+// KHz returns the value in kilohertz (10^3).
+func (f Frequency) KHz() float64 {
+	return toUnit(f, KHz)
+}
+
+// MHz returns the value in megahertz (10^6).
+func (f Frequency) MHz() float64 {
+	return toUnit(f, MHz)
+}
+
+// GHz returns the value in gigahertz (10^9).
+func (f Frequency) GHz() float64 {
+	return toUnit(f, GHz)
+}
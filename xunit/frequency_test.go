@@ -0,0 +1,247 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xunit_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jlourenc/xgo/xunit"
+)
+
+func TestParseFrequency(t *testing.T) {
+	testCases := []struct {
+		input             string
+		expectedFrequency xunit.Frequency
+		expectedErr       error
+	}{
+		{"", 0, errors.New("empty frequency representation")},
+		{"0.1.2Hz", 0, errors.New("invalid frequency representation: 0.1.2Hz")},
+		{"X", 0, errors.New("invalid frequency representation: X")},
+		{"1s", 0, errors.New("invalid frequency representation: 1s")},
+		{"-50Hz", -50 * xunit.Hz, nil},
+		{"0", 0, nil},
+		{"1", xunit.Hz, nil},
+		{"0.1Hz", xunit.Hz / 10, nil},
+		{"0.5hz", 0, errors.New("invalid frequency representation: 0.5hz")},
+		{"500mHz", xunit.Hz / 2, nil},
+		{"500mhz", 0, errors.New("invalid frequency representation: 500mhz")},
+		{"50Hz", 50 * xunit.Hz, nil},
+		{"44.1kHz", 44100 * xunit.Hz, nil},
+		{"44.1KHZ", 0, errors.New("invalid frequency representation: 44.1KHZ")},
+		{"100MHz", 100 * xunit.MHz, nil},
+		{"100MHZ", 0, errors.New("invalid frequency representation: 100MHZ")},
+		{"2.4GHz", 2400 * xunit.MHz, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			qty, err := xunit.ParseFrequency(tc.input)
+
+			if tc.expectedFrequency != qty {
+				t.Errorf("expected %s; got %s", tc.expectedFrequency, qty)
+			}
+
+			if (tc.expectedErr == nil && err != nil) || (tc.expectedErr != nil && err == nil) ||
+				(tc.expectedErr != nil && tc.expectedErr.Error() != err.Error()) {
+				t.Errorf("expected error %s; got %s", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestFrequencyOf_Period(t *testing.T) {
+	testCases := []struct {
+		name      string
+		frequency xunit.Frequency
+		period    time.Duration
+	}{
+		{"0.1Hz", xunit.Hz / 10, 10 * time.Second},
+		{"0.5Hz", xunit.Hz / 2, 2 * time.Second},
+		{"1Hz", xunit.Hz, time.Second},
+		{"4Hz", 4 * xunit.Hz, 250 * time.Millisecond},
+		{"1kHz", xunit.KHz, time.Millisecond},
+		{"1MHz", xunit.MHz, time.Microsecond},
+		{"1GHz", xunit.GHz, time.Nanosecond},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name+"_frequency_of", func(t *testing.T) {
+			got := xunit.FrequencyOf(tc.period)
+
+			if tc.frequency != got {
+				t.Errorf("expected %s; got %s", tc.frequency, got)
+			}
+		})
+		t.Run(tc.name+"_period", func(t *testing.T) {
+			got := tc.frequency.Period()
+
+			if tc.period != got {
+				t.Errorf("expected %s; got %s", tc.period, got)
+			}
+		})
+	}
+}
+
+func TestFrequency_Panic(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func()
+		panic bool
+	}{
+		{"frequency of zero duration", func() { xunit.FrequencyOf(0) }, true},
+		{"frequency of negative duration", func() { xunit.FrequencyOf(-time.Second) }, true},
+		{"period of zero frequency", func() { xunit.Frequency(0).Period() }, true},
+		{"period of negative frequency", func() { (-xunit.Hz).Period() }, true},
+		{"period of valid frequency", func() { xunit.Hz.Period() }, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tc.panic {
+					t.Errorf("panic mismatch: expected %t; got %v", tc.panic, r)
+				}
+			}()
+			tc.fn()
+		})
+	}
+}
+
+func TestFrequency_Get(t *testing.T) {
+	f := 50 * xunit.Hz
+
+	got := f.Get()
+
+	if got != f {
+		t.Errorf("expected %s; got %s", f, got)
+	}
+}
+
+func TestFrequency_Set_UnmarshalText(t *testing.T) {
+	testCases := []struct {
+		name              string
+		input             string
+		expectedFrequency xunit.Frequency
+		expectedErr       error
+	}{
+		{
+			name:        "empty frequency representation",
+			input:       "",
+			expectedErr: errors.New("empty frequency representation"),
+		},
+		{
+			name:        "invalid frequency representation",
+			input:       "2X",
+			expectedErr: errors.New("invalid frequency representation: 2X"),
+		},
+		{
+			name:              "valid frequency representation",
+			input:             "2kHz",
+			expectedFrequency: 2 * xunit.KHz,
+		},
+	}
+
+	for _, tc := range testCases {
+		for name, set := range map[string]func(f *xunit.Frequency, s string) error{
+			"set":            (*xunit.Frequency).Set,
+			"unmarshal_text": func(f *xunit.Frequency, s string) error { return f.UnmarshalText([]byte(s)) },
+		} {
+			t.Run(tc.name+"_"+name, func(t *testing.T) {
+				var f xunit.Frequency
+
+				err := set(&f, tc.input)
+
+				if tc.expectedFrequency != f {
+					t.Errorf("expected %s; got %s", tc.expectedFrequency, f)
+				}
+
+				if (tc.expectedErr == nil && err != nil) || (tc.expectedErr != nil && err == nil) ||
+					(tc.expectedErr != nil && tc.expectedErr.Error() != err.Error()) {
+					t.Errorf("expected error %s; got %s", tc.expectedErr, err)
+				}
+			})
+		}
+	}
+}
+
+func TestFrequency_MarshalText_String(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    xunit.Frequency
+		expected string
+	}{
+		{"-50Hz", -50 * xunit.Hz, "-50Hz"},
+		{"0", 0, "0Hz"},
+		{"0.001Hz", 1, "0.001Hz"},
+		{"0.5Hz", xunit.Hz / 2, "0.5Hz"},
+		{"1Hz", xunit.Hz, "1Hz"},
+		{"1.234Hz", 1234, "1.234Hz"},
+		{"999Hz", 999 * xunit.Hz, "999Hz"},
+		{"1kHz", xunit.KHz, "1kHz"},
+		{"44.1kHz", 44100 * xunit.Hz, "44.1kHz"},
+		{"44.25kHz", 44250 * xunit.Hz, "44.25kHz"},
+		{"100MHz", 100 * xunit.MHz, "100MHz"},
+		{"2.4GHz", 2400 * xunit.MHz, "2.4GHz"},
+		{"2.5GHz", 2500 * xunit.MHz, "2.5GHz"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name+"_string", func(t *testing.T) {
+			got := tc.input.String()
+
+			if tc.expected != got {
+				t.Errorf("expected %s; got %s", tc.expected, got)
+			}
+		})
+		t.Run(tc.name+"_marshal_text", func(t *testing.T) {
+			got, err := tc.input.MarshalText()
+
+			if tc.expected != string(got) {
+				t.Errorf("expected %s; got %s", tc.expected, got)
+			}
+
+			if err != nil {
+				t.Errorf("no error expected; got %s", err)
+			}
+		})
+	}
+}
+
+func TestFrequency_Type(t *testing.T) {
+	var f xunit.Frequency
+	expected := "xunit_frequency"
+
+	got := f.Type()
+
+	if expected != got {
+		t.Errorf("expected %s; got %s", expected, got)
+	}
+}
+
+func TestFrequency_Units(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fn       func(xunit.Frequency) float64
+		input    xunit.Frequency
+		expected float64
+	}{
+		{"Hz", xunit.Frequency.Hz, xunit.Hz / 4, 0.25},
+		{"kHz", xunit.Frequency.KHz, 1500 * xunit.Hz, 1.5},
+		{"MHz", xunit.Frequency.MHz, xunit.GHz, 1000},
+		{"GHz", xunit.Frequency.GHz, 2500 * xunit.MHz, 2.5},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.fn(tc.input)
+
+			if tc.expected != got {
+				t.Errorf("expected %f; got %f", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xunit
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// celsiusOffset is 0°C in millikelvin.
+const celsiusOffset = 273150
+
+// Temperature is a thermodynamic temperature stored in millikelvin.
+// Its zero value is the absolute zero.
+type Temperature int64
+
+// Celsius returns the Temperature of c degrees Celsius, rounded to the nearest millikelvin.
+func Celsius(c float64) Temperature {
+	return Temperature(math.Round(c*1000)) + celsiusOffset
+}
+
+// Fahrenheit returns the Temperature of f degrees Fahrenheit, rounded to the nearest millikelvin.
+func Fahrenheit(f float64) Temperature {
+	return Temperature(math.Round((f-32)*5000/9)) + celsiusOffset
+}
+
+// Kelvin returns the Temperature of k kelvins, rounded to the nearest millikelvin.
+func Kelvin(k float64) Temperature {
+	return Temperature(math.Round(k * 1000))
+}
+
+// ParseTemperature parses a temperature string which is a number followed by a scale suffix,
+// optionally preceded by a degree sign (e.g. '21.5°C', '70F' or '300K').
+// Suffixes are case-insensitive and the following scales are available:
+//
+//	C: Celsius
+//	F: Fahrenheit
+//	K: Kelvin
+//
+// Temperatures below the absolute zero are invalid.
+func ParseTemperature(s string) (Temperature, error) {
+	s = strings.TrimSpace(s)
+
+	if s == "" {
+		return 0, errors.New("empty temperature representation")
+	}
+
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.' && r != '-' && r != '+'
+	})
+	if i <= 0 {
+		return 0, errInvalidTemperature(s)
+	}
+
+	qty, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, errInvalidTemperature(s)
+	}
+
+	var t Temperature
+	switch strings.ToUpper(strings.TrimPrefix(s[i:], "°")) {
+	case "C":
+		t = Celsius(qty)
+	case "F":
+		t = Fahrenheit(qty)
+	case "K":
+		t = Kelvin(qty)
+	default:
+		return 0, errInvalidTemperature(s)
+	}
+
+	if t < 0 {
+		return 0, errInvalidTemperature(s)
+	}
+	return t, nil
+}
+
+func errInvalidTemperature(s string) error {
+	return errors.New("invalid temperature representation: " + s)
+}
+
+// Celsius returns the value in degrees Celsius.
+func (t Temperature) Celsius() float64 {
+	return float64(t-celsiusOffset) / 1000
+}
+
+// Fahrenheit returns the value in degrees Fahrenheit.
+func (t Temperature) Fahrenheit() float64 {
+	return float64(t-celsiusOffset)*9/5000 + 32
+}
+
+// Kelvin returns the value in kelvins.
+func (t Temperature) Kelvin() float64 {
+	return float64(t) / 1000
+}
+
+// Get returns the Temperature value.
+// It makes Temperature implement the flag package Getter interface.
+func (t Temperature) Get() any { return t }
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// The encoding is the same as returned by String.
+func (t Temperature) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// Set parses the string in input and assign it to t if valid, otherwise an error is returned.
+// It makes Temperature implement the flag package Value interface.
+func (t *Temperature) Set(s string) error {
+	ts, err := ParseTemperature(s)
+	if err != nil {
+		return err
+	}
+	*t = ts
+	return nil
+}
+
+// String returns a string representation of Temperature in degrees Celsius, e.g. '21.5°C'.
+func (t Temperature) String() string {
+	mc := int64(t - celsiusOffset)
+
+	sign := ""
+	if mc < 0 {
+		sign = "-"
+		mc = -mc
+	}
+
+	s := strconv.FormatInt(mc/1000, 10)
+	if frac := mc % 1000; frac != 0 {
+		s += strings.TrimRight("."+strconv.FormatInt(1000+frac, 10)[1:], "0")
+	}
+	return sign + s + "°C"
+}
+
+// Type returns a string representation of Temperature type.
+// It makes Temperature implement the pflag Value interface.
+func (Temperature) Type() string { return "xunit_temperature" }
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The text is expected in a form accepted by ParseTemperature.
+func (t *Temperature) UnmarshalText(text []byte) error {
+	return t.Set(string(text))
+}
@@ -0,0 +1,214 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xunit_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/jlourenc/xgo/xunit"
+)
+
+func TestParseTemperature(t *testing.T) {
+	testCases := []struct {
+		input               string
+		expectedTemperature xunit.Temperature
+		expectedErr         error
+	}{
+		{"", 0, errors.New("empty temperature representation")},
+		{"C", 0, errors.New("invalid temperature representation: C")},
+		{"21", 0, errors.New("invalid temperature representation: 21")},
+		{"21X", 0, errors.New("invalid temperature representation: 21X")},
+		{"21°", 0, errors.New("invalid temperature representation: 21°")},
+		{"0.1.2C", 0, errors.New("invalid temperature representation: 0.1.2C")},
+		{"-1K", 0, errors.New("invalid temperature representation: -1K")},
+		{"-274°C", 0, errors.New("invalid temperature representation: -274°C")},
+		{"0K", 0, nil},
+		{"-273.15°C", 0, nil},
+		{"-459.67F", 0, nil},
+		{"0°C", 273150, nil},
+		{"0c", 273150, nil},
+		{"32°F", 273150, nil},
+		{"21.5°C", 294650, nil},
+		{"21.5C", 294650, nil},
+		{"+21.5c", 294650, nil},
+		{"70F", 294261, nil},
+		{"70°f", 294261, nil},
+		{"-40°C", 233150, nil},
+		{"-40°F", 233150, nil},
+		{"300K", 300000, nil},
+		{"300.5k", 300500, nil},
+		{"300°K", 300000, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			qty, err := xunit.ParseTemperature(tc.input)
+
+			if tc.expectedTemperature != qty {
+				t.Errorf("expected %d; got %d", tc.expectedTemperature, qty)
+			}
+
+			if (tc.expectedErr == nil && err != nil) || (tc.expectedErr != nil && err == nil) ||
+				(tc.expectedErr != nil && tc.expectedErr.Error() != err.Error()) {
+				t.Errorf("expected error %s; got %s", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestTemperature_Conversions(t *testing.T) {
+	testCases := []struct {
+		name       string
+		celsius    float64
+		fahrenheit float64
+		kelvin     float64
+	}{
+		{"absolute zero", -273.15, -459.67, 0},
+		{"equal scales", -40, -40, 233.15},
+		{"freezing point", 0, 32, 273.15},
+		{"room temperature", 21.5, 70.7, 294.65},
+		{"boiling point", 100, 212, 373.15},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for name, temp := range map[string]xunit.Temperature{
+				"celsius":    xunit.Celsius(tc.celsius),
+				"fahrenheit": xunit.Fahrenheit(tc.fahrenheit),
+				"kelvin":     xunit.Kelvin(tc.kelvin),
+			} {
+				if got := temp.Celsius(); !almostEqual(tc.celsius, got) {
+					t.Errorf("%s: expected %f°C; got %f°C", name, tc.celsius, got)
+				}
+				if got := temp.Fahrenheit(); !almostEqual(tc.fahrenheit, got) {
+					t.Errorf("%s: expected %f°F; got %f°F", name, tc.fahrenheit, got)
+				}
+				if got := temp.Kelvin(); !almostEqual(tc.kelvin, got) {
+					t.Errorf("%s: expected %fK; got %fK", name, tc.kelvin, got)
+				}
+			}
+		})
+	}
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestTemperature_Get(t *testing.T) {
+	temp := xunit.Celsius(21.5)
+
+	got := temp.Get()
+
+	if got != temp {
+		t.Errorf("expected %s; got %s", temp, got)
+	}
+}
+
+func TestTemperature_Set_UnmarshalText(t *testing.T) {
+	testCases := []struct {
+		name                string
+		input               string
+		expectedTemperature xunit.Temperature
+		expectedErr         error
+	}{
+		{
+			name:        "empty temperature representation",
+			input:       "",
+			expectedErr: errors.New("empty temperature representation"),
+		},
+		{
+			name:        "invalid temperature representation",
+			input:       "2X",
+			expectedErr: errors.New("invalid temperature representation: 2X"),
+		},
+		{
+			name:                "valid temperature representation",
+			input:               "21.5°C",
+			expectedTemperature: xunit.Celsius(21.5),
+		},
+	}
+
+	for _, tc := range testCases {
+		for name, set := range map[string]func(temp *xunit.Temperature, s string) error{
+			"set":            (*xunit.Temperature).Set,
+			"unmarshal_text": func(temp *xunit.Temperature, s string) error { return temp.UnmarshalText([]byte(s)) },
+		} {
+			t.Run(tc.name+"_"+name, func(t *testing.T) {
+				var temp xunit.Temperature
+
+				err := set(&temp, tc.input)
+
+				if tc.expectedTemperature != temp {
+					t.Errorf("expected %s; got %s", tc.expectedTemperature, temp)
+				}
+
+				if (tc.expectedErr == nil && err != nil) || (tc.expectedErr != nil && err == nil) ||
+					(tc.expectedErr != nil && tc.expectedErr.Error() != err.Error()) {
+					t.Errorf("expected error %s; got %s", tc.expectedErr, err)
+				}
+			})
+		}
+	}
+}
+
+func TestTemperature_MarshalText_String(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    xunit.Temperature
+		expected string
+	}{
+		{"absolute zero", 0, "-273.15°C"},
+		{"-40°C", xunit.Celsius(-40), "-40°C"},
+		{"-0.5°C", xunit.Celsius(-0.5), "-0.5°C"},
+		{"-0.001°C", xunit.Celsius(-0.001), "-0.001°C"},
+		{"0°C", xunit.Celsius(0), "0°C"},
+		{"0.05°C", xunit.Celsius(0.05), "0.05°C"},
+		{"21.5°C", xunit.Celsius(21.5), "21.5°C"},
+		{"70F", xunit.Fahrenheit(70), "21.111°C"},
+		{"100°C", xunit.Celsius(100), "100°C"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name+"_string", func(t *testing.T) {
+			got := tc.input.String()
+
+			if tc.expected != got {
+				t.Errorf("expected %s; got %s", tc.expected, got)
+			}
+		})
+		t.Run(tc.name+"_marshal_text", func(t *testing.T) {
+			got, err := tc.input.MarshalText()
+
+			if tc.expected != string(got) {
+				t.Errorf("expected %s; got %s", tc.expected, got)
+			}
+
+			if err != nil {
+				t.Errorf("no error expected; got %s", err)
+			}
+		})
+		t.Run(tc.name+"_round_trip", func(t *testing.T) {
+			got, err := xunit.ParseTemperature(tc.input.String())
+
+			if tc.input != got || err != nil {
+				t.Errorf("expected %d; got %d, %v", tc.input, got, err)
+			}
+		})
+	}
+}
+
+func TestTemperature_Type(t *testing.T) {
+	var temp xunit.Temperature
+	expected := "xunit_temperature"
+
+	got := temp.Type()
+
+	if expected != got {
+		t.Errorf("expected %s; got %s", expected, got)
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright 2024 Jérémy Lourenço. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xunit
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// unitSystem describes the units of a quantity stored as an integer count of its smallest unit.
+type unitSystem[T ~int64] struct {
+	name          string       // Name of the quantity, used in error messages.
+	base          T            // Unit of a number without suffix.
+	symbols       map[T]string // Symbol of each unit.
+	lookup        map[string]T // Units by symbol, lowercased unless case-sensitive.
+	desc          []T          // Units tried by format, in descending order.
+	caseSensitive bool         // Whether symbols are case-sensitive, e.g. to tell 'mHz' from 'MHz'.
+}
+
+func newUnitSystem[T ~int64](name string, base T, symbols map[T]string, desc []T, caseSensitive bool) *unitSystem[T] {
+	us := &unitSystem[T]{
+		name:          name,
+		base:          base,
+		symbols:       symbols,
+		lookup:        map[string]T{"": base},
+		desc:          desc,
+		caseSensitive: caseSensitive,
+	}
+	for k, v := range symbols {
+		us.lookup[us.fold(v)] = k
+	}
+	return us
+}
+
+// fold returns the key of symbol in the lookup table.
+func (us *unitSystem[T]) fold(symbol string) string {
+	if us.caseSensitive {
+		return symbol
+	}
+	return strings.ToLower(symbol)
+}
+
+// parse parses a number followed by a unit symbol, case-insensitive unless the unit system is case-sensitive.
+func (us *unitSystem[T]) parse(s string) (T, error) {
+	s = strings.TrimSpace(s)
+
+	if s == "" {
+		return 0, errors.New("empty " + us.name + " representation")
+	}
+
+	isFloat := false
+	i := 0
+
+strLoop:
+	for _, r := range s {
+		switch {
+		case r == '.':
+			isFloat = true
+		case !unicode.IsDigit(r) && r != '-':
+			break strLoop
+		}
+		i++
+	}
+
+	unit, ok := us.lookup[us.fold(s[i:])]
+	if !ok {
+		return 0, us.invalid(s)
+	}
+
+	return us.quantity(s, s[:i], isFloat, unit)
+}
+
+// parseOptions relaxes the syntax accepted when parsing a quantity.
+type parseOptions[T ~int64] struct {
+	exponent  bool         // Exponent notation, e.g. '1.5e3'.
+	separator rune         // Thousands separator, e.g. ',' in '1,024', if not 0.
+	space     bool         // Whitespace between the number and the unit.
+	words     map[string]T // Additional units by lowercased name, e.g. 'megabytes'.
+}
+
+// parseWith parses a number followed by a case-insensitive unit symbol with the syntax relaxed by opts.
+func (us *unitSystem[T]) parseWith(s string, opts parseOptions[T]) (T, error) {
+	s = strings.TrimSpace(s)
+
+	if s == "" {
+		return 0, errors.New("empty " + us.name + " representation")
+	}
+
+	var num strings.Builder
+	isFloat := false
+	digits := false
+	i := 0
+
+	if s[0] == '-' || s[0] == '+' {
+		num.WriteByte(s[0])
+		i++
+	}
+
+numLoop:
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r >= '0' && r <= '9':
+			num.WriteRune(r)
+			digits = true
+		case r == '.':
+			num.WriteRune(r)
+			isFloat = true
+		case opts.separator != 0 && r == opts.separator:
+			// Separators must be surrounded by digits, unless a space separates the unit.
+			if digits && !isDigitAt(s, i+size) && opts.space && unicode.IsSpace(r) {
+				break numLoop
+			}
+			if !digits || !isDigitAt(s, i+size) {
+				return 0, us.invalid(s)
+			}
+		case opts.exponent && (r == 'e' || r == 'E') && digits && isExponentAt(s, i+size):
+			num.WriteByte('e')
+			if s[i+size] == '-' || s[i+size] == '+' {
+				num.WriteByte(s[i+size])
+				size++
+			}
+			isFloat = true
+		default:
+			break numLoop
+		}
+		i += size
+	}
+
+	suffix := s[i:]
+	if opts.space {
+		suffix = strings.TrimLeftFunc(suffix, unicode.IsSpace)
+	}
+	unit, ok := us.lookup[us.fold(suffix)]
+	if !ok {
+		if unit, ok = opts.words[strings.ToLower(suffix)]; !ok {
+			return 0, us.invalid(s)
+		}
+	}
+
+	return us.quantity(s, num.String(), isFloat, unit)
+}
+
+func isDigitAt(s string, i int) bool {
+	return i < len(s) && s[i] >= '0' && s[i] <= '9'
+}
+
+func isExponentAt(s string, i int) bool {
+	if i < len(s) && (s[i] == '-' || s[i] == '+') {
+		i++
+	}
+	return isDigitAt(s, i)
+}
+
+// quantity returns num units, s being the whole representation reported in errors.
+func (us *unitSystem[T]) quantity(s, num string, isFloat bool, unit T) (T, error) {
+	if !isFloat { // no fractional floating-point numbers
+		qty, err := strconv.ParseInt(num, 10, 64)
+		if err != nil {
+			return 0, us.invalid(s)
+		}
+		p, ok := mulInt64(T(qty), int64(unit))
+		if !ok {
+			return 0, ErrOverflow
+		}
+		return p, nil
+	}
+
+	qty, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, us.invalid(s)
+	}
+
+	whole, frac := math.Modf(qty)
+	v := (whole * float64(unit)) + (frac * float64(unit))
+	if v >= math.MaxInt64 || v < math.MinInt64 {
+		return 0, ErrOverflow
+	}
+	return T(v), nil
+}
+
+// clampFloat converts v to T, clamped to the range of T if it overflows. It returns 0 if v is not a number.
+func clampFloat[T ~int64](v float64) T {
+	switch {
+	case math.IsNaN(v):
+		return 0
+	case v >= math.MaxInt64:
+		return math.MaxInt64
+	case v < math.MinInt64:
+		return math.MinInt64
+	}
+	return T(v)
+}
+
+// mulInt64 returns a * n, and false if the result overflows.
+func mulInt64[T ~int64](a T, n int64) (T, bool) {
+	if a == 0 || n == 0 {
+		return 0, true
+	}
+	p := a * T(n)
+	if p/T(n) != a || (a == math.MinInt64 && n == -1) || (a == -1 && n == math.MinInt64) {
+		return 0, false
+	}
+	return p, true
+}
+
+func (us *unitSystem[T]) invalid(s string) error {
+	return errors.New("invalid " + us.name + " representation: " + s)
+}
+
+// format returns a string representation of v with the most suitable unit.
+func (us *unitSystem[T]) format(v T) string {
+	if v == 0 {
+		return "0" + us.symbols[us.base]
+	}
+
+	for _, unit := range us.desc {
+		qty := toUnit(v, unit)
+
+		if math.Abs(qty) < 1 {
+			continue
+		}
+
+		if checkDecimalPlaces(0, qty) {
+			return strconv.FormatInt(int64(qty), 10) + us.symbols[unit]
+		}
+
+		if checkDecimalPlaces(2, qty) {
+			return strconv.FormatFloat(qty, 'f', -1, 64) + us.symbols[unit]
+		}
+	}
+
+	if v%us.base == 0 {
+		return strconv.FormatInt(int64(v/us.base), 10) + us.symbols[us.base]
+	}
+	return strconv.FormatFloat(toUnit(v, us.base), 'f', -1, 64) + us.symbols[us.base]
+}
+
+func toUnit[T ~int64](v, unit T) float64 {
+	whole := v / unit
+	remainder := v - (whole * unit)
+	return float64(whole) + float64(remainder)/float64(unit)
+}
+
+func checkDecimalPlaces(i int, value float64) bool {
+	value *= math.Pow(10.0, float64(i))
+	extra := value - float64(int64(value))
+	return extra == 0
+}